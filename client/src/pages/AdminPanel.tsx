@@ -2,6 +2,7 @@ import { useState, useEffect } from 'react'
 import { useAuth } from '../context/AuthContext'
 import { productService, adminService } from '../services/api'
 import type { Product, CreateProductRequest, UpdateInventoryRequest } from '../types'
+import { formatMoney } from '../utils/money'
 import '../App.css'
 
 const AdminPanel = () => {
@@ -16,7 +17,7 @@ const AdminPanel = () => {
   const [newProduct, setNewProduct] = useState<CreateProductRequest>({
     sku: '',
     name: '',
-    price: 0,
+    price: '',
     metadata: {},
   })
 
@@ -48,7 +49,7 @@ const AdminPanel = () => {
     e.preventDefault()
     setMessage(null)
 
-    if (!newProduct.sku || !newProduct.name || newProduct.price <= 0) {
+    if (!newProduct.sku || !newProduct.name || !(parseFloat(newProduct.price) > 0)) {
       setMessage('Please fill in all required fields (SKU, Name, Price > 0)')
       return
     }
@@ -56,7 +57,7 @@ const AdminPanel = () => {
     try {
       await adminService.createProduct(newProduct)
       setMessage('✅ Product created successfully!')
-      setNewProduct({ sku: '', name: '', price: 0, metadata: {} })
+      setNewProduct({ sku: '', name: '', price: '', metadata: {} })
       loadProducts()
       // Refresh products on Dashboard
       window.dispatchEvent(new CustomEvent('refresh-products'))
@@ -190,7 +191,7 @@ const AdminPanel = () => {
                 step="0.01"
                 min="0"
                 value={newProduct.price}
-                onChange={(e) => setNewProduct({ ...newProduct, price: parseFloat(e.target.value) || 0 })}
+                onChange={(e) => setNewProduct({ ...newProduct, price: e.target.value })}
                 required
                 style={{
                   width: '100%',
@@ -353,7 +354,7 @@ const AdminPanel = () => {
                         WebkitTextFillColor: 'transparent',
                         marginBottom: '12px'
                       }}>
-                        ${product.price.toFixed(2)}
+                        {formatMoney(product.price)}
                       </div>
                       {product.inventory !== undefined && (
                         <div style={{ 
@@ -1,6 +1,7 @@
 import { useEffect, useState } from 'react'
 import { productService } from '../services/api'
 import type { Product } from '../types'
+import { formatMoney } from '../utils/money'
 import '../App.css'
 
 const Dashboard = () => {
@@ -90,7 +91,7 @@ const Dashboard = () => {
                 </p>
                 <p style={{ margin: '5px 0', color: '#666' }}>SKU: {product.sku}</p>
                 <p style={{ margin: '5px 0', fontSize: '1.2em', fontWeight: 'bold', color: '#2c3e50' }}>
-                  ${product.price.toFixed(2)}
+                  {formatMoney(product.price)}
                 </p>
                 {product.inventory !== undefined && (
                   <p style={{ 
@@ -1,7 +1,7 @@
 import { useState, useEffect } from 'react'
 import { useAuth } from '../context/AuthContext'
 import { adminService } from '../services/api'
-import type { SystemMetrics, DockerMetrics, PostgreSQLMetrics } from '../types'
+import type { SystemMetrics, DockerMetrics, PostgreSQLMetrics, SalesReport, ProductSales, OrderFunnel, FulfillmentTimes } from '../types'
 import '../App.css'
 
 const Metrics = () => {
@@ -14,6 +14,39 @@ const Metrics = () => {
   const [autoRefresh, setAutoRefresh] = useState(false)
   const [refreshInterval, setRefreshInterval] = useState<NodeJS.Timeout | null>(null)
 
+  // Sales analytics for the last 30 days, in the browser's time zone
+  const [salesInterval, setSalesInterval] = useState<SalesReport['interval']>('day')
+  const [sales, setSales] = useState<SalesReport | null>(null)
+  const [topProducts, setTopProducts] = useState<ProductSales[]>([])
+  const [funnel, setFunnel] = useState<OrderFunnel | null>(null)
+  const [fulfillment, setFulfillment] = useState<FulfillmentTimes | null>(null)
+  const [salesError, setSalesError] = useState<string | null>(null)
+
+  const loadSales = async () => {
+    const query = { tz: Intl.DateTimeFormat().resolvedOptions().timeZone }
+    try {
+      const [report, products, orderFunnel, times] = await Promise.all([
+        adminService.getSales(query, salesInterval),
+        adminService.getTopProducts(query, 5),
+        adminService.getOrderFunnel(query),
+        adminService.getFulfillmentTimes(query),
+      ])
+      setSales(report)
+      setTopProducts(products)
+      setFunnel(orderFunnel)
+      setFulfillment(times)
+      setSalesError(null)
+    } catch (err: any) {
+      setSalesError(`Failed to load sales analytics: ${err?.response?.data?.message || err?.message}`)
+    }
+  }
+
+  useEffect(() => {
+    if (isAdmin) {
+      loadSales()
+    }
+  }, [isAdmin, salesInterval])
+
   const loadMetrics = async () => {
     try {
       setLoading(true)
@@ -101,7 +134,7 @@ const Metrics = () => {
                 📊 System Metrics
               </h1>
               <p style={{ margin: '8px 0 0', color: 'var(--gray)', fontSize: '14px' }}>
-                Monitor Docker and PostgreSQL performance metrics
+                Sales analytics and Docker and PostgreSQL performance metrics
               </p>
             </div>
             <div style={{ display: 'flex', gap: '12px', alignItems: 'center' }}>
@@ -133,6 +166,81 @@ const Metrics = () => {
           </div>
         )}
 
+        <div className="card mb-3" style={{ background: 'rgba(255, 255, 255, 0.95)', backdropFilter: 'blur(10px)' }}>
+          <div className="flex-between" style={{ marginBottom: '16px' }}>
+            <h2 style={{ margin: 0, fontSize: '1.5rem', fontWeight: '700', color: 'var(--dark)' }}>💰 Sales (last 30 days)</h2>
+            <select value={salesInterval} onChange={(e) => setSalesInterval(e.target.value as SalesReport['interval'])}>
+              <option value="day">By day</option>
+              <option value="week">By week</option>
+              <option value="month">By month</option>
+            </select>
+          </div>
+
+          {salesError && (
+            <div className="alert alert-error">
+              <span>⚠️</span>
+              <span>{salesError}</span>
+            </div>
+          )}
+
+          {sales && funnel && fulfillment && (
+            <div style={{ display: 'flex', flexDirection: 'column', gap: '16px' }}>
+              <div className="grid" style={{ gridTemplateColumns: 'repeat(auto-fit, minmax(160px, 1fr))', gap: '16px' }}>
+                <div>
+                  <div style={{ fontSize: '12px', color: 'var(--gray)', fontWeight: '600' }}>Orders</div>
+                  <div style={{ fontSize: '24px', fontWeight: '700', color: 'var(--primary)' }}>{sales.totals.orders.toLocaleString()}</div>
+                </div>
+                <div>
+                  <div style={{ fontSize: '12px', color: 'var(--gray)', fontWeight: '600' }}>Units</div>
+                  <div style={{ fontSize: '24px', fontWeight: '700', color: 'var(--primary)' }}>{sales.totals.units.toLocaleString()}</div>
+                </div>
+                <div>
+                  <div style={{ fontSize: '12px', color: 'var(--gray)', fontWeight: '600' }}>Revenue</div>
+                  <div style={{ fontSize: '18px', fontWeight: '700', color: 'var(--success)' }}>{sales.totals.revenue.join(' • ') || '0'}</div>
+                </div>
+                <div>
+                  <div style={{ fontSize: '12px', color: 'var(--gray)', fontWeight: '600' }}>Cancellation rate</div>
+                  <div style={{ fontSize: '24px', fontWeight: '700', color: 'var(--danger)' }}>{(funnel.cancellation_rate * 100).toFixed(1)}%</div>
+                </div>
+                <div>
+                  <div style={{ fontSize: '12px', color: 'var(--gray)', fontWeight: '600' }}>Ordered → shipped</div>
+                  <div style={{ fontSize: '18px', fontWeight: '700', color: 'var(--dark)' }}>{fulfillment.ordered_to_shipped.average_hours.toFixed(1)} h</div>
+                </div>
+                <div>
+                  <div style={{ fontSize: '12px', color: 'var(--gray)', fontWeight: '600' }}>Shipped → delivered</div>
+                  <div style={{ fontSize: '18px', fontWeight: '700', color: 'var(--dark)' }}>{fulfillment.shipped_to_delivered.average_hours.toFixed(1)} h</div>
+                </div>
+              </div>
+
+              <div style={{ fontSize: '13px', color: 'var(--gray)' }}>
+                Funnel: {funnel.placed} placed → {funnel.paid} paid → {funnel.shipped} shipped → {funnel.delivered} delivered
+              </div>
+
+              <div className="grid" style={{ gridTemplateColumns: 'repeat(auto-fit, minmax(300px, 1fr))', gap: '16px' }}>
+                <div style={{ maxHeight: '240px', overflowY: 'auto', border: '1px solid var(--gray-lighter)', borderRadius: 'var(--radius)', padding: '8px', fontSize: '13px' }}>
+                  {sales.periods.map((period) => (
+                    <div key={period.start} className="flex-between" style={{ padding: '4px 8px' }}>
+                      <span>{new Date(period.start).toLocaleDateString()}</span>
+                      <span>{period.orders} orders • {period.units} units • {period.revenue.join(', ') || '—'}</span>
+                    </div>
+                  ))}
+                </div>
+                <div style={{ border: '1px solid var(--gray-lighter)', borderRadius: 'var(--radius)', padding: '8px', fontSize: '13px' }}>
+                  <div style={{ fontWeight: '600', marginBottom: '8px' }}>Top products</div>
+                  {topProducts.length === 0 ? (
+                    <div style={{ color: 'var(--gray)' }}>No sales yet</div>
+                  ) : topProducts.map((product) => (
+                    <div key={`${product.product_id}-${product.revenue}`} className="flex-between" style={{ padding: '4px 0' }}>
+                      <span>{product.name || product.sku || product.product_id}</span>
+                      <span>{product.units} units • {product.revenue}</span>
+                    </div>
+                  ))}
+                </div>
+              </div>
+            </div>
+          )}
+        </div>
+
         {loading && !metrics ? (
           <div className="card" style={{ textAlign: 'center', padding: '60px 20px' }}>
             <div className="loading" style={{ margin: '0 auto 20px' }}></div>
@@ -1,7 +1,8 @@
 import { useState, useEffect, useMemo } from 'react'
 import { useAuth } from '../context/AuthContext'
-import { orderService, productService } from '../services/api'
-import type { CreateOrderRequest, UpdateOrderStatusRequest, OrderStatus, Order, OrderHistory, Product } from '../types'
+import { adminService, orderService, productService, shippingService } from '../services/api'
+import type { CreateOrderRequest, UpdateOrderStatusRequest, OrderStatus, Order, OrderHistory, Product, ShippingOption, CancellationReason } from '../types'
+import { formatMoney } from '../utils/money'
 import '../App.css'
 
 // Trie Node for efficient prefix search
@@ -73,6 +74,10 @@ const Orders = () => {
   const { role } = useAuth()
   const [orderId, setOrderId] = useState('')
   const [newStatus, setNewStatus] = useState<OrderStatus>('SHIPPED')
+  const [carrier, setCarrier] = useState('')
+  const [trackingNumber, setTrackingNumber] = useState('')
+  const [cancelReason, setCancelReason] = useState<CancellationReason>('customer_request')
+  const [cancelNote, setCancelNote] = useState('')
   const [message, setMessage] = useState<string | null>(null)
 
   const [productId, setProductId] = useState('550e8400-e29b-41d4-a716-446655440000') // Sample product ID
@@ -86,6 +91,9 @@ const Orders = () => {
     zip_code: '',
     country: ''
   })
+  // Shipping options quoted for the address; the cheapest applies when none is chosen
+  const [shippingOptions, setShippingOptions] = useState<ShippingOption[]>([])
+  const [shippingOption, setShippingOption] = useState('')
 
   // Order tracking state
   const [orders, setOrders] = useState<Order[]>([])
@@ -139,13 +147,15 @@ const Orders = () => {
   useEffect(() => {
     if (selectedOrder) {
       // Set default next status based on current status and user role
-      if (selectedOrder.current_status === 'ORDERED') {
+      if (selectedOrder.current_status === 'ORDERED' || selectedOrder.current_status === 'PAID') {
         // Regular users default to CANCELLED, admin defaults to SHIPPED
         if (role === 'admin' || role === 'ADMIN') {
           setNewStatus('SHIPPED')
         } else {
           setNewStatus('CANCELLED')
         }
+      } else if (selectedOrder.current_status === 'PICKING' || selectedOrder.current_status === 'PARTIALLY_SHIPPED') {
+        setNewStatus('SHIPPED')
       } else if (selectedOrder.current_status === 'SHIPPED') {
         setNewStatus('DELIVERED')
       }
@@ -154,7 +164,7 @@ const Orders = () => {
       // If orderId is manually entered, try to find the order
       const order = orders.find(o => o.id === orderId)
       if (order) {
-        if (order.current_status === 'ORDERED') {
+        if (order.current_status === 'ORDERED' || order.current_status === 'PAID') {
           // Regular users default to CANCELLED, admin defaults to SHIPPED
           if (role === 'admin' || role === 'ADMIN') {
             setNewStatus('SHIPPED')
@@ -201,6 +211,38 @@ const Orders = () => {
     }
   }
 
+  // Build shipping address object (only include non-empty fields)
+  const buildAddress = () => {
+    const address: Record<string, string> = {}
+    if (shippingAddress.street) address.street = shippingAddress.street
+    if (shippingAddress.city) address.city = shippingAddress.city
+    if (shippingAddress.state) address.state = shippingAddress.state
+    if (shippingAddress.zip_code) address.zip_code = shippingAddress.zip_code
+    if (shippingAddress.country) address.country = shippingAddress.country
+    return address
+  }
+
+  const handleQuoteShipping = async () => {
+    if (quantity <= 0 || !shippingAddress.country) {
+      setMessage('❌ Error: Quantity and Country are required to quote shipping')
+      return
+    }
+    try {
+      const options = await shippingService.quote({
+        items: [{ product_id: productId, quantity: quantity }],
+        shipping_address: buildAddress()
+      })
+      setShippingOptions(options)
+      setShippingOption(options.length > 0 ? options[0].code : '')
+      if (options.length === 0) {
+        setMessage('No shipping options for this address; the order ships free')
+      }
+    } catch (err: any) {
+      const errorMsg = err?.response?.data?.message || err?.message || 'Failed to quote shipping'
+      setMessage(`❌ Error: ${errorMsg}`)
+    }
+  }
+
   const handleCreateOrder = async () => {
     // Validate quantity
     if (quantity <= 0 || quantity > 100000000) {
@@ -215,18 +257,11 @@ const Orders = () => {
     }
     
     try {
-      // Build shipping address object (only include non-empty fields)
-      const address: Record<string, string> = {}
-      if (shippingAddress.street) address.street = shippingAddress.street
-      if (shippingAddress.city) address.city = shippingAddress.city
-      if (shippingAddress.state) address.state = shippingAddress.state
-      if (shippingAddress.zip_code) address.zip_code = shippingAddress.zip_code
-      if (shippingAddress.country) address.country = shippingAddress.country
-      
       const request: CreateOrderRequest = {
         product_id: productId,
         quantity: quantity,
-        shipping_address: address
+        shipping_address: buildAddress(),
+        shipping_option: shippingOption || undefined
       }
       const response = await orderService.createOrder(request)
       setMessage(`✅ Order created successfully! Order ID: ${response.order_id}`)
@@ -239,6 +274,8 @@ const Orders = () => {
         zip_code: '',
         country: ''
       })
+      setShippingOptions([])
+      setShippingOption('')
       // Reload orders list
       loadOrders()
       // Trigger product refresh event for Dashboard
@@ -260,9 +297,25 @@ const Orders = () => {
       const request: UpdateOrderStatusRequest = {
         current_status: newStatus,
       }
+      // Shipping requires the carrier and tracking number
+      if (newStatus === 'SHIPPED') {
+        if (!carrier || !trackingNumber) {
+          setMessage('Please enter the carrier and tracking number')
+          return
+        }
+        request.shipment = { carrier, tracking_number: trackingNumber }
+      }
+      // Cancelling requires a reason
+      if (newStatus === 'CANCELLED') {
+        request.reason = cancelReason
+        request.note = cancelNote || undefined
+      }
       const response = await orderService.updateOrderStatus(orderId, request)
       setMessage(`✅ Order ${response.order_id} updated from ${response.previous_status} to ${response.current_status}`)
       setOrderId('')
+      setCarrier('')
+      setTrackingNumber('')
+      setCancelNote('')
       // Reload orders list and history if viewing this order
       loadOrders()
       if (selectedOrder?.id === orderId) {
@@ -279,6 +332,36 @@ const Orders = () => {
     }
   }
 
+  // Opens a document fetched with the JWT in a new tab, where it can be printed
+  const openDocument = (blob: Blob) => {
+    const url = URL.createObjectURL(blob)
+    window.open(url, '_blank')
+    setTimeout(() => URL.revokeObjectURL(url), 60000)
+  }
+
+  const handlePrintPickList = async () => {
+    try {
+      // Picks every ORDERED and PAID order and moves them to PICKING
+      openDocument(await adminService.getPickListHtml({ start_picking: true }))
+      setMessage('✅ Pick list generated; its orders are now PICKING')
+      loadOrders()
+    } catch (err: any) {
+      const errorMsg = err?.response?.data?.message || err?.message || 'Failed to generate pick list'
+      setMessage(`❌ Error: ${errorMsg}`)
+      console.error(err)
+    }
+  }
+
+  const handlePackingSlip = async (id: string) => {
+    try {
+      openDocument(await adminService.getPackingSlip(id))
+    } catch (err: any) {
+      const errorMsg = err?.response?.data?.message || err?.message || 'Failed to generate packing slip'
+      setMessage(`❌ Error: ${errorMsg}`)
+      console.error(err)
+    }
+  }
+
   const handleViewOrder = async (order: Order) => {
     setSelectedOrder(order)
     setOrderId(order.id) // Pre-fill order ID for status update
@@ -288,9 +371,15 @@ const Orders = () => {
   const getStatusColor = (status: OrderStatus) => {
     switch (status) {
       case 'ORDERED': return 'var(--info)'
+      case 'PAID': return 'var(--primary)'
+      case 'PICKING':
+      case 'PARTIALLY_SHIPPED':
       case 'SHIPPED': return 'var(--warning)'
       case 'DELIVERED': return 'var(--success)'
       case 'CANCELLED': return 'var(--danger)'
+      case 'RETURN_REQUESTED':
+      case 'PARTIALLY_RETURNED':
+      case 'RETURNED': return 'var(--gray)'
       default: return 'var(--gray)'
     }
   }
@@ -298,9 +387,15 @@ const Orders = () => {
   const getStatusBadge = (status: OrderStatus) => {
     const colors: Record<OrderStatus, string> = {
       'ORDERED': 'badge-info',
+      'PAID': 'badge-primary',
+      'PICKING': 'badge-warning',
+      'PARTIALLY_SHIPPED': 'badge-warning',
       'SHIPPED': 'badge-warning',
       'DELIVERED': 'badge-success',
-      'CANCELLED': 'badge-danger'
+      'CANCELLED': 'badge-danger',
+      'RETURN_REQUESTED': 'badge-warning',
+      'PARTIALLY_RETURNED': 'badge-info',
+      'RETURNED': 'badge-info'
     }
     return colors[status] || 'badge-info'
   }
@@ -529,6 +624,24 @@ const Orders = () => {
                 <p style={{ margin: 0, fontSize: '12px', color: 'var(--gray)', fontStyle: 'italic' }}>
                   * Required fields. All orders ship from our single warehouse.
                 </p>
+                <div style={{ marginTop: '12px' }}>
+                  <button onClick={handleQuoteShipping} className="btn btn-secondary" style={{ fontSize: '14px', padding: '8px 16px' }}>
+                    🚚 Get Shipping Options
+                  </button>
+                  {shippingOptions.map(option => (
+                    <label key={option.code} style={{ display: 'block', marginTop: '8px', fontSize: '14px' }}>
+                      <input
+                        type="radio"
+                        name="shipping_option"
+                        checked={shippingOption === option.code}
+                        onChange={() => setShippingOption(option.code)}
+                        style={{ marginRight: '8px' }}
+                      />
+                      {option.name} - <strong>{formatMoney(option.amount)}</strong>
+                      {option.estimated_days ? ` (${option.estimated_days} days)` : ''}
+                    </label>
+                  ))}
+                </div>
               </div>
             </div>
             
@@ -549,13 +662,24 @@ const Orders = () => {
             <h2 style={{ margin: 0, fontSize: '1.5rem', fontWeight: '700', color: 'var(--dark)' }}>
               {role === 'admin' || role === 'ADMIN' ? '📋 All Orders' : '📋 My Orders'}
             </h2>
-            <button
-              onClick={loadOrders}
-              className="btn btn-primary"
-              style={{ fontSize: '14px', padding: '10px 20px' }}
-            >
-              🔄 Refresh
-            </button>
+            <div style={{ display: 'flex', gap: '10px' }}>
+              {(role === 'admin' || role === 'ADMIN') && (
+                <button
+                  onClick={handlePrintPickList}
+                  className="btn btn-secondary"
+                  style={{ fontSize: '14px', padding: '10px 20px' }}
+                >
+                  🧺 Print Pick List
+                </button>
+              )}
+              <button
+                onClick={loadOrders}
+                className="btn btn-primary"
+                style={{ fontSize: '14px', padding: '10px 20px' }}
+              >
+                🔄 Refresh
+              </button>
+            </div>
           </div>
           
           {/* Search Bar and Status Filter */}
@@ -662,9 +786,15 @@ const Orders = () => {
                   >
                     <option value="ALL">📋 All Statuses</option>
                     <option value="ORDERED">📦 Ordered</option>
+                    <option value="PAID">💳 Paid</option>
+                    <option value="PICKING">🧺 Picking</option>
+                    <option value="PARTIALLY_SHIPPED">🚚 Partially Shipped</option>
                     <option value="SHIPPED">🚚 Shipped</option>
                     <option value="DELIVERED">✅ Delivered</option>
                     <option value="CANCELLED">❌ Cancelled</option>
+                    <option value="RETURN_REQUESTED">↩️ Return Requested</option>
+                    <option value="PARTIALLY_RETURNED">↩️ Partially Returned</option>
+                    <option value="RETURNED">↩️ Returned</option>
                   </select>
                 </div>
               </div>
@@ -806,12 +936,18 @@ const Orders = () => {
                           </div>
                         ) : null}
                         <div style={{ fontSize: '0.9em', color: 'var(--gray)', marginBottom: '6px' }}>
-                          📦 Product: {product ? (
-                            <span style={{ fontWeight: '600', color: 'var(--primary)' }}>{product.name}</span>
+                          📦 Product: {order.product_name || product ? (
+                            <span style={{ fontWeight: '600', color: 'var(--primary)' }}>{order.product_name || product?.name}</span>
                           ) : (
                             <code style={{ fontSize: '0.85em', background: 'var(--gray-lighter)', padding: '2px 6px', borderRadius: '3px' }}>{order.product_id.slice(0, 8)}...</code>
                           )} | 
                           Quantity: <strong>{order.quantity}</strong>
+                          {order.sku && (
+                            <> | Total: <strong>{formatMoney(order.total)}</strong>{order.price_estimated ? ' (estimated)' : ''}</>
+                          )}
+                          {order.shipping_option && (
+                            <> | 🚚 {order.shipping_option.name}: <strong>{formatMoney(order.shipping)}</strong></>
+                          )}
                         </div>
                         {order.metadata && order.metadata.shipping_address && (
                           <div style={{ fontSize: '0.85em', color: 'var(--gray)', marginBottom: '6px', marginTop: '4px' }}>
@@ -921,11 +1057,40 @@ const Orders = () => {
                     </div>
                     <div style={{ fontSize: '0.85em', color: 'var(--gray)', display: 'flex', alignItems: 'center', gap: '8px' }}>
                       <span>👤</span>
-                      <span>Updated by User #{history.updated_by}</span>
+                      <span>
+                        {history.actor.type === 'user'
+                          ? `Updated by User #${history.updated_by}`
+                          : `Updated by ${history.actor.type} ${history.actor.id}`}
+                      </span>
                       <span style={{ margin: '0 4px' }}>•</span>
                       <span>🕒</span>
                       <span>{new Date(history.updated_at).toLocaleString()}</span>
                     </div>
+                    {history.reason_code && (
+                      <div style={{ fontSize: '0.85em', color: 'var(--dark)', marginTop: '6px' }}>
+                        Reason: <strong>{history.reason_code.replace(/_/g, ' ')}</strong>
+                      </div>
+                    )}
+                    {history.note && (
+                      <div style={{ fontSize: '0.85em', color: 'var(--dark)', marginTop: '6px' }}>{history.note}</div>
+                    )}
+                    {history.request_id && (
+                      <div style={{ fontSize: '0.75em', color: 'var(--gray)', marginTop: '4px' }}>
+                        Request {history.request_id}{history.client_ip && ` from ${history.client_ip}`}
+                      </div>
+                    )}
+                    {history.shipment && (
+                      <div style={{ fontSize: '0.85em', color: 'var(--gray)', marginTop: '6px' }}>
+                        <span>🚚 {history.shipment.carrier} {history.shipment.tracking_number}: {history.shipment.status.replace(/_/g, ' ')}</span>
+                        {history.shipment.events.map((event, i) => (
+                          <div key={i} style={{ marginLeft: '20px' }}>
+                            {new Date(event.occurred_at).toLocaleString()} · {event.status.replace(/_/g, ' ')}
+                            {event.location && ` · ${event.location}`}
+                            {event.description && ` · ${event.description}`}
+                          </div>
+                        ))}
+                      </div>
+                    )}
                   </div>
                 ))}
               </div>
@@ -945,12 +1110,12 @@ const Orders = () => {
           </h2>
           {role === 'admin' && (
             <p style={{ fontSize: '0.9em', color: '#666', marginBottom: '15px' }}>
-              As admin, you can update any order's status to SHIPPED or DELIVERED. Regular users can only cancel ORDERED orders (cannot update to SHIPPED).
+              As admin, you can update any order's status to SHIPPED or DELIVERED. Regular users can only cancel ORDERED or PAID orders (cannot update to SHIPPED). Shipping captures the payment.
             </p>
           )}
           {role !== 'admin' && (
             <p style={{ fontSize: '0.9em', color: '#666', marginBottom: '15px' }}>
-              You can only cancel ORDERED orders. Only admin can update orders to SHIPPED or DELIVERED.
+              You can only cancel ORDERED or PAID orders; your payment is voided or refunded. Only admin can update orders to SHIPPED or DELIVERED.
             </p>
           )}
           <div style={{ display: 'flex', flexDirection: 'column', gap: '15px', maxWidth: '400px' }}>
@@ -993,7 +1158,10 @@ const Orders = () => {
                   const currentStatus = currentOrder?.current_status || 'ORDERED'
                   
                   // FSM Rules:
-                  // ORDERED → SHIPPED or CANCELLED
+                  // ORDERED → PAID (set by the payment provider), SHIPPED or CANCELLED
+                  // PAID → PICKING (with a pick list), PARTIALLY_SHIPPED, SHIPPED or CANCELLED
+                  // PICKING → PARTIALLY_SHIPPED, SHIPPED or CANCELLED
+                  // PARTIALLY_SHIPPED → SHIPPED
                   // SHIPPED → DELIVERED
                   // DELIVERED → (no transitions)
                   // CANCELLED → (no transitions)
@@ -1004,8 +1172,9 @@ const Orders = () => {
                   // Regular users can cancel ORDERED orders, but SHIPPED orders cannot be cancelled
                   if (role === 'admin' || role === 'ADMIN') {
                     // Admin restrictions: only SHIPPED or DELIVERED
-                    if (currentStatus === 'ORDERED') {
-                      options.push(<option key="SHIPPED" value="SHIPPED">Shipped (ORDERED → SHIPPED)</option>)
+                    if (currentStatus === 'ORDERED' || currentStatus === 'PAID' || currentStatus === 'PICKING' || currentStatus === 'PARTIALLY_SHIPPED') {
+                      // Ships every open unit; partial shipments go through the shipments endpoint
+                      options.push(<option key="SHIPPED" value="SHIPPED">Shipped ({currentStatus} → SHIPPED)</option>)
                     } else if (currentStatus === 'SHIPPED') {
                       options.push(<option key="DELIVERED" value="DELIVERED">Delivered (SHIPPED → DELIVERED)</option>)
                     } else {
@@ -1013,8 +1182,8 @@ const Orders = () => {
                     }
                   } else {
                     // Regular user: can ONLY cancel ORDERED orders (cannot update to SHIPPED or DELIVERED)
-                    if (currentStatus === 'ORDERED') {
-                      options.push(<option key="CANCELLED" value="CANCELLED">Cancelled (ORDERED → CANCELLED)</option>)
+                    if (currentStatus === 'ORDERED' || currentStatus === 'PAID') {
+                      options.push(<option key="CANCELLED" value="CANCELLED">Cancelled ({currentStatus} → CANCELLED)</option>)
                     } else if (currentStatus === 'SHIPPED') {
                       // SHIPPED orders cannot be changed by regular users - only admin can update to DELIVERED
                       options.push(<option key="DELIVERED" value="DELIVERED" disabled>Delivered (SHIPPED → DELIVERED) - Admin only</option>)
@@ -1040,16 +1209,16 @@ const Orders = () => {
                   const currentStatus = currentOrder?.current_status || 'ORDERED'
                   
                   if (role === 'admin' || role === 'ADMIN') {
-                    if (currentStatus === 'ORDERED') {
-                      return 'Admin: ORDERED → Can update to: SHIPPED only'
+                    if (currentStatus === 'ORDERED' || currentStatus === 'PAID') {
+                      return `Admin: ${currentStatus} → Can update to: SHIPPED only`
                     } else if (currentStatus === 'SHIPPED') {
                       return 'Admin: SHIPPED → Can update to: DELIVERED only'
                     } else {
                       return `Admin: ${currentStatus} → Final state (no transitions allowed)`
                     }
                   } else {
-                    if (currentStatus === 'ORDERED') {
-                      return 'Current: ORDERED → Can only cancel (CANCELLED). Only admin can update to SHIPPED'
+                    if (currentStatus === 'ORDERED' || currentStatus === 'PAID') {
+                      return `Current: ${currentStatus} → Can only cancel (CANCELLED). Only admin can update to SHIPPED`
                     } else if (currentStatus === 'SHIPPED') {
                       return 'Current: SHIPPED → Cannot be changed. Only admin can update to DELIVERED'
                     } else if (currentStatus === 'DELIVERED' || currentStatus === 'CANCELLED') {
@@ -1060,6 +1229,47 @@ const Orders = () => {
                 })()}
               </small>
             </div>
+            {newStatus === 'SHIPPED' && (
+              <div style={{ display: 'flex', gap: '10px' }}>
+                <input
+                  type="text"
+                  placeholder="Carrier (e.g. ups)"
+                  value={carrier}
+                  onChange={(e) => setCarrier(e.target.value)}
+                  style={{ flex: 1, padding: '8px', border: '1px solid #ddd', borderRadius: '4px' }}
+                />
+                <input
+                  type="text"
+                  placeholder="Tracking number"
+                  value={trackingNumber}
+                  onChange={(e) => setTrackingNumber(e.target.value)}
+                  style={{ flex: 2, padding: '8px', border: '1px solid #ddd', borderRadius: '4px' }}
+                />
+              </div>
+            )}
+            {newStatus === 'CANCELLED' && (
+              <div style={{ display: 'flex', gap: '10px' }}>
+                <select
+                  value={cancelReason}
+                  onChange={(e) => setCancelReason(e.target.value as CancellationReason)}
+                  style={{ flex: 1, padding: '8px', border: '1px solid #ddd', borderRadius: '4px' }}
+                >
+                  <option value="customer_request">Changed my mind</option>
+                  <option value="duplicate_order">Duplicate order</option>
+                  <option value="address_issue">Wrong address</option>
+                  <option value="out_of_stock">Out of stock</option>
+                  <option value="fraud_suspected">Suspected fraud</option>
+                  <option value="other">Other</option>
+                </select>
+                <input
+                  type="text"
+                  placeholder="Note (optional)"
+                  value={cancelNote}
+                  onChange={(e) => setCancelNote(e.target.value)}
+                  style={{ flex: 2, padding: '8px', border: '1px solid #ddd', borderRadius: '4px' }}
+                />
+              </div>
+            )}
             <button
               onClick={handleUpdateStatus}
               className="btn btn-success"
@@ -1067,6 +1277,15 @@ const Orders = () => {
             >
               ✅ Update Status
             </button>
+            {(role === 'admin' || role === 'ADMIN') && orderId && (
+              <button
+                onClick={() => handlePackingSlip(orderId)}
+                className="btn btn-secondary"
+                style={{ fontSize: '14px', padding: '10px 20px' }}
+              >
+                📄 Packing Slip (PDF)
+              </button>
+            )}
           </div>
         </section>
 
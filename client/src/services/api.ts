@@ -7,6 +7,13 @@ import type {
   Product,
   Order,
   OrderHistory,
+  Shipment,
+  CreateShipmentRequest,
+  CancelOrderItemsRequest,
+  Payment,
+  ReturnRequest,
+  CreateReturnRequest,
+  UpdateReturnRequest,
   LoginResponse,
   SignupRequest,
   SignupResponse,
@@ -15,9 +22,35 @@ import type {
   UpdateProductRequest,
   UpdateInventoryRequest,
   UpdateInventoryResponse,
+  UpdateReorderPointRequest,
+  UpdateReorderPointResponse,
+  StockAlert,
+  StockAlertFilter,
+  LowStockItem,
   SystemMetrics,
   DockerMetrics,
   PostgreSQLMetrics,
+  Cart,
+  AddCartLineRequest,
+  CheckoutRequest,
+  CheckoutResponse,
+  ShippingOption,
+  ShippingQuoteRequest,
+  ShippingQuoteResponse,
+  PickList,
+  PickListRequest,
+  AuditEntry,
+  AuditFilter,
+  AnalyticsQuery,
+  SalesReport,
+  ProductSales,
+  OrderFunnel,
+  FulfillmentTimes,
+  OrderFilter,
+  OrderExportQuery,
+  ExportJob,
+  ProductImportOptions,
+  ImportReport,
 } from '../types'
 
 // Use Vite proxy in development - MUST use relative path for proxy to work
@@ -73,6 +106,11 @@ apiClient.interceptors.request.use((config) => {
   if (token) {
     config.headers.Authorization = `Bearer ${token}`
   }
+  // Anonymous cart; the server merges it into the user's cart after login
+  const cartToken = localStorage.getItem('cart_token')
+  if (cartToken) {
+    config.headers['X-Cart-Token'] = cartToken
+  }
   return config
 })
 
@@ -118,13 +156,96 @@ export const orderService = {
     return response.data
   },
 
-  getOrders: async (): Promise<Order[]> => {
-    const response = await apiClient.get<Order[]>('/orders')
+  getOrders: async (filter: OrderFilter = {}): Promise<Order[]> => {
+    const response = await apiClient.get<Order[]>('/orders', { params: filter })
+    return response.data
+  },
+
+  // reason narrows the history to a cancellation or return reason code
+  getOrderHistory: async (orderId: string, reason?: string): Promise<OrderHistory[]> => {
+    const response = await apiClient.get<OrderHistory[]>(`/orders/${orderId}/history`, {
+      params: reason ? { reason } : undefined,
+    })
+    return response.data
+  },
+
+  getOrderShipments: async (orderId: string): Promise<Shipment[]> => {
+    const response = await apiClient.get<Shipment[]>(`/orders/${orderId}/shipments`)
+    return response.data
+  },
+
+  createShipment: async (orderId: string, data: CreateShipmentRequest): Promise<Shipment> => {
+    const response = await apiClient.post<Shipment>(`/orders/${orderId}/shipments`, data)
+    return response.data
+  },
+
+  cancelOrderItems: async (orderId: string, data: CancelOrderItemsRequest): Promise<Order> => {
+    const response = await apiClient.post<Order>(`/orders/${orderId}/cancellations`, data)
+    return response.data
+  },
+
+  getOrderPayments: async (orderId: string): Promise<Payment[]> => {
+    const response = await apiClient.get<Payment[]>(`/orders/${orderId}/payments`)
+    return response.data
+  },
+
+  getOrderReturns: async (orderId: string): Promise<ReturnRequest[]> => {
+    const response = await apiClient.get<ReturnRequest[]>(`/orders/${orderId}/returns`)
+    return response.data
+  },
+
+  requestReturn: async (orderId: string, data: CreateReturnRequest): Promise<ReturnRequest> => {
+    const response = await apiClient.post<ReturnRequest>(`/orders/${orderId}/returns`, data)
+    return response.data
+  },
+
+  updateReturn: async (orderId: string, returnId: string, data: UpdateReturnRequest): Promise<ReturnRequest> => {
+    const response = await apiClient.patch<ReturnRequest>(`/orders/${orderId}/returns/${returnId}`, data)
     return response.data
   },
+}
+
+// Shipping quotes need no sign-in
+export const shippingService = {
+  quote: async (data: ShippingQuoteRequest): Promise<ShippingOption[]> => {
+    const response = await apiClient.post<ShippingQuoteResponse>('/shipping/quote', data)
+    return response.data.options
+  },
+}
+
+// Cart service functions; anonymous carts are remembered by their token until they are merged
+const rememberCart = (cart: Cart): Cart => {
+  if (cart.token) {
+    localStorage.setItem('cart_token', cart.token)
+  } else if (cart.id) {
+    localStorage.removeItem('cart_token')
+  }
+  return cart
+}
+
+export const cartService = {
+  getCart: async (): Promise<Cart> => {
+    const response = await apiClient.get<Cart>('/cart')
+    return rememberCart(response.data)
+  },
+
+  addLine: async (data: AddCartLineRequest): Promise<Cart> => {
+    const response = await apiClient.post<Cart>('/cart/lines', data)
+    return rememberCart(response.data)
+  },
+
+  updateLine: async (lineId: string, quantity: number): Promise<Cart> => {
+    const response = await apiClient.put<Cart>(`/cart/lines/${lineId}`, { quantity })
+    return rememberCart(response.data)
+  },
 
-  getOrderHistory: async (orderId: string): Promise<OrderHistory[]> => {
-    const response = await apiClient.get<OrderHistory[]>(`/orders/${orderId}/history`)
+  removeLine: async (lineId: string): Promise<Cart> => {
+    const response = await apiClient.delete<Cart>(`/cart/lines/${lineId}`)
+    return rememberCart(response.data)
+  },
+
+  checkout: async (data: CheckoutRequest): Promise<CheckoutResponse> => {
+    const response = await apiClient.post<CheckoutResponse>('/cart/checkout', data)
     return response.data
   },
 }
@@ -162,6 +283,121 @@ export const adminService = {
     return response.data
   },
 
+  updateReorderPoint: async (data: UpdateReorderPointRequest): Promise<UpdateReorderPointResponse> => {
+    const response = await apiClient.put<UpdateReorderPointResponse>('/admin/inventory/reorder-point', data)
+    return response.data
+  },
+
+  getLowStock: async (): Promise<LowStockItem[]> => {
+    const response = await apiClient.get<LowStockItem[]>('/admin/inventory/low-stock')
+    return response.data
+  },
+
+  getStockAlerts: async (filter: StockAlertFilter = {}): Promise<StockAlert[]> => {
+    const response = await apiClient.get<StockAlert[]>('/admin/stock-alerts', { params: filter })
+    return response.data
+  },
+
+  acknowledgeStockAlert: async (alertId: string): Promise<StockAlert> => {
+    const response = await apiClient.post<StockAlert>(`/admin/stock-alerts/${alertId}/acknowledge`)
+    return response.data
+  },
+
+  resolveStockAlert: async (alertId: string): Promise<StockAlert> => {
+    const response = await apiClient.post<StockAlert>(`/admin/stock-alerts/${alertId}/resolve`)
+    return response.data
+  },
+
+  generatePickList: async (data: PickListRequest): Promise<PickList> => {
+    const response = await apiClient.post<PickList>('/admin/pick-lists', data)
+    return response.data
+  },
+
+  // Printable pick list page; fetched with the JWT, then opened from a blob URL
+  getPickListHtml: async (data: PickListRequest): Promise<Blob> => {
+    const response = await apiClient.post('/admin/pick-lists', data, {
+      params: { format: 'html' },
+      responseType: 'blob',
+    })
+    return response.data
+  },
+
+  getPackingSlip: async (orderId: string, format: 'pdf' | 'html' = 'pdf'): Promise<Blob> => {
+    const response = await apiClient.get(`/orders/${orderId}/packing-slip`, {
+      params: { format },
+      responseType: 'blob',
+    })
+    return response.data
+  },
+
+  getSales: async (query: AnalyticsQuery, interval: SalesReport['interval'] = 'day'): Promise<SalesReport> => {
+    const response = await apiClient.get<SalesReport>('/admin/analytics/sales', { params: { ...query, interval } })
+    return response.data
+  },
+
+  getTopProducts: async (query: AnalyticsQuery, limit = 10): Promise<ProductSales[]> => {
+    const response = await apiClient.get<ProductSales[]>('/admin/analytics/top-products', { params: { ...query, limit } })
+    return response.data
+  },
+
+  getOrderFunnel: async (query: AnalyticsQuery): Promise<OrderFunnel> => {
+    const response = await apiClient.get<OrderFunnel>('/admin/analytics/funnel', { params: query })
+    return response.data
+  },
+
+  getFulfillmentTimes: async (query: AnalyticsQuery): Promise<FulfillmentTimes> => {
+    const response = await apiClient.get<FulfillmentTimes>('/admin/analytics/fulfillment', { params: query })
+    return response.data
+  },
+
+  getAuditLog: async (filter: AuditFilter = {}): Promise<AuditEntry[]> => {
+    const response = await apiClient.get<AuditEntry[]>('/admin/audit', { params: filter })
+    return response.data
+  },
+
+  // Every matching entry as CSV, fetched with the JWT like the warehouse documents
+  exportAuditLog: async (filter: AuditFilter = {}): Promise<Blob> => {
+    const response = await apiClient.get('/admin/audit', {
+      params: { ...filter, format: 'csv' },
+      responseType: 'blob',
+    })
+    return response.data
+  },
+
+  // The matching orders as CSV or NDJSON, streamed by the server
+  exportOrders: async (query: OrderExportQuery = {}): Promise<Blob> => {
+    const response = await apiClient.get('/admin/exports/orders', {
+      params: query,
+      responseType: 'blob',
+    })
+    return response.data
+  },
+
+  // Large ranges: poll getExportJob until it completes, then downloadExport
+  startOrderExport: async (query: OrderExportQuery = {}): Promise<ExportJob> => {
+    const response = await apiClient.post<ExportJob>('/admin/exports/orders', null, { params: query })
+    return response.data
+  },
+
+  getExportJob: async (exportId: string): Promise<ExportJob> => {
+    const response = await apiClient.get<ExportJob>(`/admin/exports/${exportId}`)
+    return response.data
+  },
+
+  downloadExport: async (exportId: string): Promise<Blob> => {
+    const response = await apiClient.get(`/admin/exports/${exportId}/download`, { responseType: 'blob' })
+    return response.data
+  },
+
+  importProducts: async (file: Blob, options: ProductImportOptions = {}): Promise<ImportReport> => {
+    const ndjson = options.format === 'ndjson' || (!options.format && file.type === 'application/x-ndjson')
+    const response = await apiClient.post<ImportReport>('/admin/imports/products', file, {
+      params: options,
+      headers: { 'Content-Type': ndjson ? 'application/x-ndjson' : 'text/csv' },
+    })
+    return response.data
+  },
+
   getMetrics: async (): Promise<SystemMetrics> => {
     const response = await apiClient.get<SystemMetrics>('/admin/metrics')
     return response.data
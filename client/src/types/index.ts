@@ -1,24 +1,164 @@
 // Order types
-export type OrderStatus = 'ORDERED' | 'SHIPPED' | 'DELIVERED' | 'CANCELLED'
+export type OrderStatus = 'ORDERED' | 'PAID' | 'PICKING' | 'PARTIALLY_SHIPPED' | 'SHIPPED' | 'DELIVERED' | 'CANCELLED' | 'RETURN_REQUESTED' | 'PARTIALLY_RETURNED' | 'RETURNED'
 
 export interface Order {
   id: string
   user_id: number
   product_id: string
+  variant_id?: string
   quantity: number
+  cancelled_quantity: number // Units cancelled before they shipped
+  shipped_quantity: number // Units sent in shipments; the rest are open
   current_status: OrderStatus
   metadata?: Record<string, any> // Shipping address and other order metadata
+  sku: string // Snapshot at order time
+  product_name: string
+  unit_price: Money
+  subtotal: Money
+  discount: Money
+  shipping: Money
+  tax: Money
+  tax_included: Money // Tax already contained in inclusive prices; not added to total
+  total: Money
+  price_estimated: boolean // Snapshot backfilled from a later catalog price
+  checkout_id?: string // Shared by the orders placed from one cart checkout
+  discounts?: OrderDiscount[]
+  tax_lines?: OrderTaxLine[]
+  shipping_option?: OrderShippingOption // Absent when shipping was not quoted
   created_at: string
   updated_at: string
 }
 
+// OrderShippingOption is the shipping option an order is charged for in shipping
+export interface OrderShippingOption {
+  code: string
+  name: string
+  carrier?: string
+  service_level?: string
+}
+
+// ShippingOption is a quoted way to ship items to an address
+export interface ShippingOption extends OrderShippingOption {
+  amount: Money
+  estimated_days?: number
+}
+
+export interface ShippingQuoteRequest {
+  items: { product_id: string; variant_id?: string; quantity: number }[]
+  shipping_address?: CreateOrderRequest['shipping_address']
+}
+
+export interface ShippingQuoteResponse {
+  options: ShippingOption[] // Cheapest first
+}
+
+// OrderDiscount is a discount line granted by a promotion
+export interface OrderDiscount {
+  promotion_id: string
+  code?: string // Coupon code; absent for automatic promotions
+  description: string
+  amount: Money
+  free_shipping: boolean
+}
+
+// OrderTaxLine is the tax one rule charged on the product or on shipping
+export interface OrderTaxLine {
+  name: string
+  tax_class: string
+  rate: string // Percentage, e.g. "8.25"
+  inclusive: boolean
+  taxable: Money
+  amount: Money
+}
+
+// Payment types
+export type PaymentStatus = 'pending' | 'authorized' | 'declined' | 'captured' | 'voided' | 'partially_refunded' | 'refunded' | 'failed'
+
+export interface Payment {
+  id: string
+  order_id: string
+  provider: string
+  reference?: string
+  status: PaymentStatus
+  amount: Money
+  captured: Money
+  refunded: Money
+  failure_reason?: string
+  history: PaymentHistory[]
+  created_at: string
+  updated_at: string
+}
+
+export interface PaymentHistory {
+  previous_status?: PaymentStatus // Absent for the initial status
+  new_status: PaymentStatus
+  amount: Money
+  message?: string
+  created_at: string
+}
+
+// Return types
+export type ReturnStatus = 'requested' | 'approved' | 'rejected' | 'received' | 'refunded'
+
+export type ReturnReason = 'damaged' | 'defective' | 'wrong_item' | 'not_as_described' | 'no_longer_needed' | 'other'
+
+export interface ReturnRequest {
+  id: string
+  order_id: string
+  user_id: number
+  quantity: number
+  reason: ReturnReason
+  note?: string
+  status: ReturnStatus
+  restocked_quantity: number
+  damaged_quantity: number // Written off instead of restocked
+  refund_amount: Money // Set when the goods are received
+  resolution_note?: string
+  handled_by?: number
+  created_at: string
+  updated_at: string
+}
+
+export interface CreateReturnRequest {
+  quantity: number
+  reason: ReturnReason
+  note?: string
+}
+
+export interface UpdateReturnRequest {
+  status: Exclude<ReturnStatus, 'requested'> // 'refunded' retries a failed refund
+  note?: string
+  restocked_quantity?: number // Required with damaged_quantity for 'received'
+  damaged_quantity?: number
+}
+
+// Money is an exact amount with its ISO 4217 currency, e.g. "1299.99 USD"
+export type Money = string
+
 export interface Product {
   id: string
   sku: string
   name: string
-  price: number
-  inventory?: number // Stock quantity
+  price: Money
+  tax_class: string
+  inventory?: number // Stock quantity, summed over variants
   metadata: Record<string, any>
+  options?: ProductOption[]
+  variants?: ProductVariant[]
+}
+
+export interface ProductOption {
+  name: string
+  values: string[]
+}
+
+export interface ProductVariant {
+  id: string
+  sku: string
+  options: Record<string, string>
+  price: Money
+  price_override: boolean
+  inventory?: number
 }
 
 export interface Inventory {
@@ -30,6 +170,7 @@ export interface Inventory {
 // API Request/Response types
 export interface CreateOrderRequest {
   product_id: string // UUID as string
+  variant_id?: string // Required for products with variants
   quantity: number
   shipping_address?: {
     street?: string
@@ -39,16 +180,96 @@ export interface CreateOrderRequest {
     country?: string
     [key: string]: any
   }
+  coupon_codes?: string[]
+  shipping_option?: string // Code of a quoted option; the cheapest when omitted
+  payment_method?: string // Provider token; the fake provider also accepts fake_decline, fake_timeout, ...
+}
+
+// Cart types
+export type CartLineIssue = 'unavailable' | 'variant_required' | 'insufficient_stock' | 'currency_mismatch'
+
+export interface Cart {
+  id?: string // Absent until something is added
+  token?: string // Anonymous carts only; sent back as X-Cart-Token
+  lines: CartLine[]
+  subtotal: Money
+  valid: boolean // Every line can be checked out as it is
+  expires_at?: string
+}
+
+export interface CartLine {
+  id: string
+  product_id: string
+  variant_id?: string
+  sku: string
+  product_name: string
+  quantity: number
+  unit_price: Money // Current catalog price
+  line_total: Money
+  available: number // Current stock
+  issue?: CartLineIssue
+}
+
+export interface AddCartLineRequest {
+  product_id: string
+  variant_id?: string // Required for products with variants
+  quantity: number
+}
+
+export interface CheckoutRequest {
+  shipping_address?: CreateOrderRequest['shipping_address']
+  shipping_option?: string // Code of a quoted option; the cheapest when omitted
+  payment_method?: string
+}
+
+export interface CheckoutResponse {
+  checkout_id: string
+  orders: Order[]
+  total: Money
+  message: string
 }
 
 export interface CreateOrderResponse {
   order_id: string
   current_status: OrderStatus
+  total: Money
   message: string
 }
 
 export interface UpdateOrderStatusRequest {
   current_status: OrderStatus
+  shipment?: ShipmentDetails // Required to ship an order
+  reason?: CancellationReason // Required to cancel an order
+  note?: string
+}
+
+// CancellationReason is why an order, or some of its units, was cancelled. Payment reasons are
+// only set by the payment provider and the stale order job.
+export type CancellationReason =
+  | 'customer_request'
+  | 'out_of_stock'
+  | 'address_issue'
+  | 'duplicate_order'
+  | 'fraud_suspected'
+  | 'other'
+  | 'payment_declined'
+  | 'payment_timeout'
+
+export type ActorType = 'user' | 'system' | 'api_key' | 'integration'
+
+// Actor identifies who made a change
+export interface Actor {
+  type: ActorType
+  id: string // User ID, job name, API key ID, or integration such as "carrier:ups"
+}
+
+// ShipmentDetails describes how a shipment was sent
+export interface ShipmentDetails {
+  carrier: string // Carrier code, such as "ups"
+  tracking_number: string
+  service_level?: string
+  label_reference?: string
+  shipped_at?: string // Defaults to now
 }
 
 export interface UpdateOrderStatusResponse {
@@ -63,10 +284,216 @@ export interface OrderHistory {
   order_id: string
   previous_status: OrderStatus
   new_status: OrderStatus
-  updated_by: number
+  updated_by: number // 0 when the actor is not a user
+  actor: Actor
+  reason_code?: string // For cancellations and returns, e.g. "customer_request" or "defective"
+  note?: string // Describes partial steps, such as a shipment of some units
+  shipment?: Shipment // The shipment that shipped or delivered the order, with its tracking
+  request_id?: string // The API request that made the change
+  client_ip?: string
   updated_at: string
 }
 
+// Sales analytics cover the orders placed from `from` (inclusive) to `to` (exclusive)
+export interface AnalyticsQuery {
+  from?: string // Date such as 2025-01-31 or RFC 3339 timestamp; defaults to 30 days before to
+  to?: string // Defaults to the end of today
+  tz?: string // IANA time zone for dates and periods; defaults to UTC
+}
+
+// Sales are net of cancelled orders and units; revenue has one amount per currency
+export interface Sales {
+  orders: number
+  units: number
+  revenue: string[] // e.g. ["1250.00 USD", "80.00 EUR"]
+}
+
+export interface SalesPeriod extends Sales {
+  start: string
+}
+
+export interface SalesReport {
+  interval: 'day' | 'week' | 'month'
+  time_zone: string
+  from: string
+  to: string
+  periods: SalesPeriod[]
+  totals: Sales
+}
+
+export interface ProductSales {
+  product_id: string
+  sku: string
+  name: string
+  orders: number
+  units: number
+  revenue: string
+}
+
+// OrderFunnel counts orders by the furthest steps they reached
+export interface OrderFunnel {
+  from: string
+  to: string
+  placed: number
+  paid: number
+  picking: number
+  shipped: number
+  delivered: number
+  cancelled: number
+  cancellation_rate: number // 0 to 1
+  statuses: Record<string, number> // By current status
+}
+
+export interface FulfillmentStepTime {
+  average_seconds: number
+  average_hours: number
+  orders: number
+}
+
+export interface FulfillmentTimes {
+  from: string
+  to: string
+  ordered_to_shipped: FulfillmentStepTime
+  shipped_to_delivered: FulfillmentStepTime
+}
+
+// AuditEntry is one change an admin made to the catalog, inventory, pricing rules or users
+export interface AuditEntry {
+  id: string
+  actor: Actor
+  action: 'create' | 'update' | 'delete'
+  entity_type: string // Such as "product", "inventory" or "promotion"
+  entity_id: string
+  before?: Record<string, unknown> // Omitted for creations
+  after?: Record<string, unknown> // Omitted for deletions
+  changes: Record<string, { before: unknown; after: unknown }>
+  request_id?: string
+  client_ip?: string
+  created_at: string
+}
+
+export interface AuditFilter {
+  entity_type?: string
+  entity_id?: string
+  actor_type?: ActorType
+  actor_id?: string
+  from?: string // RFC 3339 timestamp or date, inclusive
+  to?: string // Exclusive
+  limit?: number
+}
+
+// OrderFilter narrows the order list and order exports
+export interface OrderFilter {
+  status?: string // Comma separated, e.g. "PAID,PICKING"
+  product_id?: string
+  user_id?: number // Admin only
+  from?: string // RFC 3339 timestamp or date, inclusive
+  to?: string // Exclusive
+}
+
+export interface OrderExportQuery extends OrderFilter {
+  format?: 'csv' | 'ndjson'
+  columns?: string // Comma separated, in order; all of them by default
+  include?: string // "product", "history" or both, comma separated
+}
+
+// ExportJob is an export written to a file in the background
+export interface ExportJob {
+  id: string
+  kind: 'orders'
+  status: 'queued' | 'running' | 'completed' | 'failed'
+  format: 'csv' | 'ndjson'
+  params: Record<string, unknown>
+  requested_by: number
+  row_count: number
+  size: number // Bytes
+  error?: string
+  download_url?: string // Set once completed
+  created_at: string
+  started_at?: string
+  completed_at?: string
+  expires_at?: string // When the file is deleted
+}
+
+// ProductImportOptions are the query parameters of a product import
+export interface ProductImportOptions {
+  format?: 'csv' | 'ndjson' // By default from the file's type, else csv
+  dry_run?: boolean
+  atomic?: boolean
+  batch_size?: number
+}
+
+// ImportError is why a row of an import failed; line 0 is the file as a whole
+export interface ImportError {
+  line: number
+  sku?: string
+  field?: string // meta.<key> for metadata
+  message: string
+}
+
+export interface ImportBatch {
+  number: number
+  first_line: number
+  last_line: number
+  created: number
+  updated: number
+  unchanged: number
+  failed: number
+  saved: boolean
+}
+
+// ImportReport is the outcome of an import; rows that failed are listed in errors
+export interface ImportReport {
+  dry_run: boolean
+  atomic: boolean
+  saved: boolean
+  rows: number
+  created: number
+  updated: number
+  unchanged: number
+  failed: number
+  errors: ImportError[]
+  errors_omitted?: number
+  batches: ImportBatch[]
+}
+
+// Shipment is one parcel of an order; an order may ship in several
+export interface Shipment {
+  id: string
+  order_id: string
+  quantity: number
+  carrier: string
+  service_level?: string
+  tracking_number: string
+  label_reference?: string
+  status: TrackingStatus
+  shipped_by: number
+  shipped_at: string
+  delivered_at?: string
+  events: TrackingEvent[] // In the order they occurred
+  created_at: string
+}
+
+export type TrackingStatus = 'shipped' | 'in_transit' | 'out_for_delivery' | 'delivered' | 'exception'
+
+// TrackingEvent is one scan of a shipment reported by its carrier
+export interface TrackingEvent {
+  status: TrackingStatus
+  description?: string
+  location?: string
+  occurred_at: string
+}
+
+export interface CreateShipmentRequest extends ShipmentDetails {
+  quantity: number
+}
+
+export interface CancelOrderItemsRequest {
+  quantity: number
+  reason: CancellationReason
+  note?: string
+}
+
 // Auth types
 export interface LoginRequest {
   username: string
@@ -93,7 +520,7 @@ export interface SignupResponse {
 export interface CreateProductRequest {
   sku: string
   name: string
-  price: number
+  price: Money // A bare amount such as "19.99" is taken as USD
   metadata?: Record<string, any>
 }
 
@@ -105,21 +532,107 @@ export interface CreateProductResponse {
 export interface UpdateProductRequest {
   sku?: string
   name?: string
-  price?: number
+  price?: Money
   metadata?: Record<string, any>
 }
 
 export interface UpdateInventoryRequest {
   product_id: string
+  variant_id?: string
   quantity: number
+  bin_location?: string
 }
 
 export interface UpdateInventoryResponse {
   product_id: string
   quantity: number
+  bin_location?: string
   message: string
 }
 
+// Null clears a value; the target level must be above the reorder point
+export interface UpdateReorderPointRequest {
+  product_id: string
+  variant_id?: string
+  reorder_point: number | null
+  target_level?: number | null
+}
+
+export interface UpdateReorderPointResponse {
+  product_id: string
+  variant_id?: string
+  reorder_point: number | null
+  target_level: number | null
+  message: string
+}
+
+export type StockAlertStatus = 'open' | 'acknowledged' | 'resolved'
+
+// StockAlert is opened when a stock unit falls to its reorder point; quantities are as they were then
+export interface StockAlert {
+  id: string
+  stock_unit_id: string // The product or variant stocked
+  status: StockAlertStatus
+  quantity: number
+  reorder_point: number
+  target_level?: number
+  acknowledged_by?: number
+  created_at: string
+  acknowledged_at?: string
+  resolved_at?: string
+}
+
+export interface StockAlertFilter {
+  status?: string // One or more StockAlertStatus values, comma separated
+  product_id?: string
+  limit?: number
+}
+
+// LowStockItem is a stock unit at or below its reorder point
+export interface LowStockItem {
+  stock_unit_id: string
+  product_id: string
+  variant_id?: string
+  sku: string
+  name: string
+  quantity: number
+  reorder_point: number
+  target_level?: number
+  reorder_quantity: number // Units to order to reach the target level, 0 without one
+  bin_location?: string
+  alert_id?: string
+  alert_status?: StockAlertStatus
+}
+
+// Warehouse pick lists
+export interface PickListRequest {
+  order_ids?: string[]
+  start_picking?: boolean
+}
+
+export interface PickListOrder {
+  order_id: string
+  quantity: number
+}
+
+export interface PickListLine {
+  bin_location: string
+  product_id: string
+  variant_id?: string
+  sku: string
+  product_name: string
+  quantity: number
+  orders: PickListOrder[]
+}
+
+export interface PickList {
+  id: string
+  generated_at: string
+  lines: PickListLine[]
+  order_ids: string[]
+  total_units: number
+}
+
 // Metrics types
 export interface DockerMetrics {
   container_name?: string
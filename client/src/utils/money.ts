@@ -0,0 +1,17 @@
+import type { Money } from '../types'
+
+// formatMoney renders an API amount such as "1299.99 USD" for display, e.g. "$1,299.99"
+export const formatMoney = (value: Money): string => {
+  const [amount, currency = 'USD'] = value.split(' ')
+  const exponent = amount.includes('.') ? amount.split('.')[1].length : 0
+  try {
+    return new Intl.NumberFormat(undefined, {
+      style: 'currency',
+      currency,
+      minimumFractionDigits: exponent,
+      maximumFractionDigits: exponent,
+    }).format(Number(amount))
+  } catch {
+    return value
+  }
+}
@@ -2,27 +2,43 @@ package controllers
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
+	"strings"
 
 	"github.com/google/uuid"
 	"github.com/gorilla/mux"
 	"oms/server/api/v1/helpers"
 	apitypes "oms/server/api/v1/types"
 	"oms/server/core/model"
+	"oms/server/core/money"
+	"oms/server/core/services"
 	"oms/server/core/types"
 )
 
-// AdminController handles admin-only operations (products and inventory management)
+// AdminController handles admin-only operations (products, variants and inventory management)
 type AdminController struct {
 	productStore   types.ProductStore
 	inventoryStore types.InventoryStore
+	variantStore   types.ProductVariantStore
+	variantService services.VariantService
+	schemaService  services.MetadataSchemaService
 }
 
 // NewAdminController creates a new AdminController
-func NewAdminController(productStore types.ProductStore, inventoryStore types.InventoryStore) *AdminController {
+func NewAdminController(
+	productStore types.ProductStore,
+	inventoryStore types.InventoryStore,
+	variantStore types.ProductVariantStore,
+	variantService services.VariantService,
+	schemaService services.MetadataSchemaService,
+) *AdminController {
 	return &AdminController{
 		productStore:   productStore,
 		inventoryStore: inventoryStore,
+		variantStore:   variantStore,
+		variantService: variantService,
+		schemaService:  schemaService,
 	}
 }
 
@@ -39,7 +55,7 @@ func (ac *AdminController) CreateProduct(w http.ResponseWriter, r *http.Request)
 
 	var req apitypes.CreateProductRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		helpers.WriteErrorResponse(w, http.StatusBadRequest, "invalid_request", "Invalid request body")
+		writeDecodeError(w, err)
 		return
 	}
 
@@ -48,7 +64,11 @@ func (ac *AdminController) CreateProduct(w http.ResponseWriter, r *http.Request)
 		helpers.WriteErrorResponse(w, http.StatusBadRequest, "invalid_request", "SKU and Name are required")
 		return
 	}
-	if req.Price < 0 {
+	if req.Price.Currency == "" {
+		helpers.WriteErrorResponse(w, http.StatusBadRequest, "invalid_request", "Price is required")
+		return
+	}
+	if req.Price.IsNegative() {
 		helpers.WriteErrorResponse(w, http.StatusBadRequest, "invalid_request", "Price cannot be negative")
 		return
 	}
@@ -59,13 +79,24 @@ func (ac *AdminController) CreateProduct(w http.ResponseWriter, r *http.Request)
 		Name:     req.Name,
 		Price:    req.Price,
 		Metadata: model.JSONB(req.Metadata),
+		TaxClass: normalizeTaxClass(req.TaxClass),
+	}
+
+	// Validate metadata against the registered product schemas
+	if ac.schemaService != nil {
+		if err := ac.schemaService.ValidateProductMetadata(ctx, nil, product.Metadata); err != nil {
+			if !writeMetadataValidationError(w, err) {
+				helpers.WriteErrorResponse(w, http.StatusInternalServerError, "internal_error", "Failed to validate metadata: "+err.Error())
+			}
+			return
+		}
 	}
 
 	err := ac.productStore.Create(ctx, product)
 	if err != nil {
 		// Check for duplicate SKU error
-		if err.Error() == "duplicate key value violates unique constraint" || 
-		   err.Error() == "UNIQUE constraint failed" {
+		if err.Error() == "duplicate key value violates unique constraint" ||
+			err.Error() == "UNIQUE constraint failed" {
 			helpers.WriteErrorResponse(w, http.StatusConflict, "conflict", "Product with this SKU already exists")
 			return
 		}
@@ -104,7 +135,7 @@ func (ac *AdminController) UpdateProduct(w http.ResponseWriter, r *http.Request)
 
 	var req apitypes.UpdateProductRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		helpers.WriteErrorResponse(w, http.StatusBadRequest, "invalid_request", "Invalid request body")
+		writeDecodeError(w, err)
 		return
 	}
 
@@ -122,11 +153,42 @@ func (ac *AdminController) UpdateProduct(w http.ResponseWriter, r *http.Request)
 	if req.Name != "" {
 		existingProduct.Name = req.Name
 	}
-	if req.Price >= 0 {
-		existingProduct.Price = req.Price
+	if req.Price != nil {
+		if req.Price.IsNegative() {
+			helpers.WriteErrorResponse(w, http.StatusBadRequest, "invalid_request", "Price cannot be negative")
+			return
+		}
+		// Variant price overrides are in the product currency, so it cannot change under them
+		if req.Price.Currency != existingProduct.Price.Currency && ac.variantStore != nil {
+			variants, err := ac.variantStore.GetByProductID(ctx, productID)
+			if err != nil {
+				helpers.WriteErrorResponse(w, http.StatusInternalServerError, "internal_error", "Failed to fetch variants")
+				return
+			}
+			for _, variant := range variants {
+				if variant.Price != nil {
+					helpers.WriteErrorResponse(w, http.StatusBadRequest, "invalid_request", "Cannot change the currency of a product whose variants have price overrides")
+					return
+				}
+			}
+		}
+		existingProduct.Price = *req.Price
+	}
+	if req.TaxClass != "" {
+		existingProduct.TaxClass = normalizeTaxClass(req.TaxClass)
 	}
 	if req.Metadata != nil {
 		existingProduct.Metadata = model.JSONB(req.Metadata)
+
+		// Validate metadata against the schemas for the product's type and categories
+		if ac.schemaService != nil {
+			if err := ac.schemaService.ValidateProductMetadata(ctx, &productID, existingProduct.Metadata); err != nil {
+				if !writeMetadataValidationError(w, err) {
+					helpers.WriteErrorResponse(w, http.StatusInternalServerError, "internal_error", "Failed to validate metadata: "+err.Error())
+				}
+				return
+			}
+		}
 	}
 
 	err = ac.productStore.Update(ctx, productID, existingProduct)
@@ -141,6 +203,7 @@ func (ac *AdminController) UpdateProduct(w http.ResponseWriter, r *http.Request)
 		Name:      existingProduct.Name,
 		Price:     existingProduct.Price,
 		Metadata:  map[string]interface{}(existingProduct.Metadata),
+		TaxClass:  existingProduct.TaxClass,
 		CreatedAt: existingProduct.CreatedAt,
 		UpdatedAt: existingProduct.UpdatedAt,
 	})
@@ -168,35 +231,140 @@ func (ac *AdminController) UpdateInventory(w http.ResponseWriter, r *http.Reques
 		helpers.WriteErrorResponse(w, http.StatusBadRequest, "invalid_request", "Quantity cannot be negative")
 		return
 	}
-
-	// Parse product ID
-	productID, err := uuid.Parse(req.ProductID)
-	if err != nil {
-		helpers.WriteErrorResponse(w, http.StatusBadRequest, "invalid_request", "Invalid product ID format")
+	if req.BinLocation != nil && len(strings.TrimSpace(*req.BinLocation)) > 50 {
+		helpers.WriteErrorResponse(w, http.StatusBadRequest, "invalid_request", "Bin location must be at most 50 characters")
 		return
 	}
 
-	// Verify product exists
-	_, err = ac.productStore.GetByID(ctx, productID)
-	if err != nil {
-		helpers.WriteErrorResponse(w, http.StatusNotFound, "not_found", "Product not found")
+	productID, stockUnitID, ok := ac.resolveStockUnit(w, r, req.ProductID, req.VariantID)
+	if !ok {
 		return
 	}
 
 	// Update inventory
-	err = ac.inventoryStore.UpdateQuantity(ctx, productID, req.Quantity)
+	err := ac.inventoryStore.UpdateQuantity(ctx, stockUnitID, req.Quantity)
 	if err != nil {
 		helpers.WriteErrorResponse(w, http.StatusInternalServerError, "internal_error", "Failed to update inventory: "+err.Error())
 		return
 	}
 
+	// Bins are only changed when given, so stock counts can be updated without knowing them
+	binLocation := ""
+	if req.BinLocation != nil {
+		binLocation = strings.TrimSpace(*req.BinLocation)
+		if err := ac.inventoryStore.UpdateBinLocation(ctx, stockUnitID, binLocation); err != nil {
+			helpers.WriteErrorResponse(w, http.StatusInternalServerError, "internal_error", "Failed to update bin location: "+err.Error())
+			return
+		}
+	}
+
 	helpers.WriteJSONResponse(w, http.StatusOK, apitypes.UpdateInventoryResponse{
-		ProductID: productID.String(),
-		Quantity:  req.Quantity,
-		Message:   "Inventory updated successfully",
+		ProductID:   productID.String(),
+		VariantID:   req.VariantID,
+		Quantity:    req.Quantity,
+		BinLocation: binLocation,
+		Message:     "Inventory updated successfully",
+	})
+}
+
+// UpdateReorderPoint handles PUT /api/v1/admin/inventory/reorder-point - Set when a stock unit is low and what to restock it to (admin only)
+// A stock unit at or below its reorder point has a stock alert open until it is restocked above it.
+func (ac *AdminController) UpdateReorderPoint(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	// Verify admin role
+	role := getUserRoleFromContext(ctx)
+	if role != "admin" {
+		helpers.WriteErrorResponse(w, http.StatusForbidden, "forbidden", "Admin access required")
+		return
+	}
+
+	var req apitypes.UpdateReorderPointRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		helpers.WriteErrorResponse(w, http.StatusBadRequest, "invalid_request", "Invalid request body")
+		return
+	}
+
+	// Validate request
+	if req.ReorderPoint != nil && *req.ReorderPoint < 0 {
+		helpers.WriteErrorResponse(w, http.StatusBadRequest, "invalid_request", "Reorder point cannot be negative")
+		return
+	}
+	if req.TargetLevel != nil {
+		if req.ReorderPoint == nil {
+			helpers.WriteErrorResponse(w, http.StatusBadRequest, "invalid_request", "Target level requires a reorder point")
+			return
+		}
+		if *req.TargetLevel <= *req.ReorderPoint {
+			helpers.WriteErrorResponse(w, http.StatusBadRequest, "invalid_request", "Target level must be above the reorder point")
+			return
+		}
+	}
+
+	productID, stockUnitID, ok := ac.resolveStockUnit(w, r, req.ProductID, req.VariantID)
+	if !ok {
+		return
+	}
+
+	if err := ac.inventoryStore.UpdateReorderPoint(ctx, stockUnitID, req.ReorderPoint, req.TargetLevel); err != nil {
+		helpers.WriteErrorResponse(w, http.StatusInternalServerError, "internal_error", "Failed to update reorder point: "+err.Error())
+		return
+	}
+
+	helpers.WriteJSONResponse(w, http.StatusOK, apitypes.UpdateReorderPointResponse{
+		ProductID:    productID.String(),
+		VariantID:    req.VariantID,
+		ReorderPoint: req.ReorderPoint,
+		TargetLevel:  req.TargetLevel,
+		Message:      "Reorder point updated successfully",
 	})
 }
 
+// resolveStockUnit finds the stock unit of an inventory request: the variant when one is given,
+// otherwise the product, which must not have variants since they are stocked per variant. It writes
+// the error response and returns false when the product or variant is invalid.
+func (ac *AdminController) resolveStockUnit(w http.ResponseWriter, r *http.Request, productIDStr, variantIDStr string) (uuid.UUID, uuid.UUID, bool) {
+	ctx := r.Context()
+
+	// Parse product ID
+	productID, err := uuid.Parse(productIDStr)
+	if err != nil {
+		helpers.WriteErrorResponse(w, http.StatusBadRequest, "invalid_request", "Invalid product ID format")
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	// Verify product exists
+	product, err := ac.productStore.GetByID(ctx, productID)
+	if err != nil {
+		helpers.WriteErrorResponse(w, http.StatusNotFound, "not_found", "Product not found")
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	if variantIDStr == "" {
+		if len(product.Options) > 0 {
+			helpers.WriteErrorResponse(w, http.StatusBadRequest, "invalid_request", "Product has variants; variant_id is required")
+			return uuid.Nil, uuid.Nil, false
+		}
+		return productID, productID, true
+	}
+
+	variantID, err := uuid.Parse(variantIDStr)
+	if err != nil {
+		helpers.WriteErrorResponse(w, http.StatusBadRequest, "invalid_request", "Invalid variant ID format")
+		return uuid.Nil, uuid.Nil, false
+	}
+	if ac.variantStore == nil {
+		helpers.WriteErrorResponse(w, http.StatusBadRequest, "invalid_request", "Variants are not supported")
+		return uuid.Nil, uuid.Nil, false
+	}
+	variant, err := ac.variantStore.GetByID(ctx, variantID)
+	if err != nil || variant.ProductID != productID {
+		helpers.WriteErrorResponse(w, http.StatusNotFound, "not_found", "Variant not found for this product")
+		return uuid.Nil, uuid.Nil, false
+	}
+	return productID, variantID, true
+}
+
 // DeleteProduct handles DELETE /api/v1/admin/products/{productId} - Delete a product (admin only)
 func (ac *AdminController) DeleteProduct(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
@@ -232,8 +400,166 @@ func (ac *AdminController) DeleteProduct(w http.ResponseWriter, r *http.Request)
 	}
 
 	helpers.WriteJSONResponse(w, http.StatusOK, map[string]string{
-		"message":   "Product deleted successfully",
+		"message":    "Product deleted successfully",
 		"product_id": productID.String(),
 	})
 }
 
+// GenerateVariants handles POST /api/v1/admin/products/{productId}/variants - Generate the variant matrix (admin only)
+// Existing variants whose option combination is still defined keep their SKU, price and stock.
+func (ac *AdminController) GenerateVariants(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	vars := mux.Vars(r)
+
+	// Verify admin role
+	role := getUserRoleFromContext(ctx)
+	if role != "admin" {
+		helpers.WriteErrorResponse(w, http.StatusForbidden, "forbidden", "Admin access required")
+		return
+	}
+
+	// Parse product ID
+	productID, err := uuid.Parse(vars["productId"])
+	if err != nil {
+		helpers.WriteErrorResponse(w, http.StatusBadRequest, "invalid_request", "Invalid product ID format")
+		return
+	}
+
+	var req apitypes.GenerateVariantsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		helpers.WriteErrorResponse(w, http.StatusBadRequest, "invalid_request", "Invalid request body")
+		return
+	}
+
+	// Verify product exists
+	product, err := ac.productStore.GetByID(ctx, productID)
+	if err != nil {
+		helpers.WriteErrorResponse(w, http.StatusNotFound, "not_found", "Product not found")
+		return
+	}
+
+	options := make(model.ProductOptions, len(req.Options))
+	for i, option := range req.Options {
+		options[i] = model.ProductOption{Name: option.Name, Values: option.Values}
+	}
+
+	variants, err := ac.variantService.GenerateVariants(ctx, productID, options)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidVariantOptions) {
+			helpers.WriteErrorResponse(w, http.StatusBadRequest, "invalid_request", err.Error())
+			return
+		}
+		helpers.WriteErrorResponse(w, http.StatusInternalServerError, "internal_error", "Failed to generate variants: "+err.Error())
+		return
+	}
+
+	variantResponses := make([]apitypes.ProductVariantResponse, len(variants))
+	for i, variant := range variants {
+		variantResponses[i] = toVariantResponse(variant, product.Price, nil)
+	}
+
+	helpers.WriteJSONResponse(w, http.StatusOK, apitypes.GenerateVariantsResponse{
+		ProductID: productID.String(),
+		Variants:  variantResponses,
+		Message:   "Variants generated successfully",
+	})
+}
+
+// UpdateVariant handles PUT /api/v1/admin/variants/{variantId} - Update a variant's SKU or price override (admin only)
+func (ac *AdminController) UpdateVariant(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	vars := mux.Vars(r)
+
+	// Verify admin role
+	role := getUserRoleFromContext(ctx)
+	if role != "admin" {
+		helpers.WriteErrorResponse(w, http.StatusForbidden, "forbidden", "Admin access required")
+		return
+	}
+
+	// Parse variant ID
+	variantID, err := uuid.Parse(vars["variantId"])
+	if err != nil {
+		helpers.WriteErrorResponse(w, http.StatusBadRequest, "invalid_request", "Invalid variant ID format")
+		return
+	}
+
+	var req apitypes.UpdateVariantRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeDecodeError(w, err)
+		return
+	}
+
+	// Verify variant exists
+	if _, err := ac.variantStore.GetByID(ctx, variantID); err != nil {
+		helpers.WriteErrorResponse(w, http.StatusNotFound, "not_found", "Variant not found")
+		return
+	}
+
+	variant, err := ac.variantService.UpdateVariant(ctx, variantID, req.SKU, req.Price, req.ResetPrice)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidVariantOptions) {
+			helpers.WriteErrorResponse(w, http.StatusBadRequest, "invalid_request", err.Error())
+			return
+		}
+		helpers.WriteErrorResponse(w, http.StatusInternalServerError, "internal_error", "Failed to update variant: "+err.Error())
+		return
+	}
+
+	product, err := ac.productStore.GetByID(ctx, variant.ProductID)
+	if err != nil {
+		helpers.WriteErrorResponse(w, http.StatusNotFound, "not_found", "Product not found")
+		return
+	}
+
+	helpers.WriteJSONResponse(w, http.StatusOK, toVariantResponse(variant, product.Price, nil))
+}
+
+// DeleteVariant handles DELETE /api/v1/admin/variants/{variantId} - Delete a variant (admin only)
+func (ac *AdminController) DeleteVariant(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	vars := mux.Vars(r)
+
+	// Verify admin role
+	role := getUserRoleFromContext(ctx)
+	if role != "admin" {
+		helpers.WriteErrorResponse(w, http.StatusForbidden, "forbidden", "Admin access required")
+		return
+	}
+
+	// Parse variant ID
+	variantID, err := uuid.Parse(vars["variantId"])
+	if err != nil {
+		helpers.WriteErrorResponse(w, http.StatusBadRequest, "invalid_request", "Invalid variant ID format")
+		return
+	}
+
+	if err := ac.variantService.DeleteVariant(ctx, variantID); err != nil {
+		helpers.WriteErrorResponse(w, http.StatusNotFound, "not_found", "Variant not found")
+		return
+	}
+
+	helpers.WriteJSONResponse(w, http.StatusOK, map[string]string{
+		"message":    "Variant deleted successfully",
+		"variant_id": variantID.String(),
+	})
+}
+
+// normalizeTaxClass lower-cases a product tax class, defaulting to model.DefaultTaxClass
+func normalizeTaxClass(taxClass string) string {
+	taxClass = strings.ToLower(strings.TrimSpace(taxClass))
+	if taxClass == "" {
+		return model.DefaultTaxClass
+	}
+	return taxClass
+}
+
+// writeDecodeError writes a 400 response for a request body that failed to decode,
+// naming the problem when it is a malformed amount or currency
+func writeDecodeError(w http.ResponseWriter, err error) {
+	if errors.Is(err, money.ErrInvalidAmount) || errors.Is(err, money.ErrUnknownCurrency) {
+		helpers.WriteErrorResponse(w, http.StatusBadRequest, "invalid_request", "Invalid price: "+err.Error())
+		return
+	}
+	helpers.WriteErrorResponse(w, http.StatusBadRequest, "invalid_request", "Invalid request body")
+}
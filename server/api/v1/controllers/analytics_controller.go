@@ -0,0 +1,270 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"oms/server/api/v1/helpers"
+	"oms/server/api/v1/types"
+	"oms/server/core/model"
+	"oms/server/core/services"
+)
+
+const (
+	defaultAnalyticsDays    = 30
+	defaultTopProductsLimit = 10
+	maxTopProductsLimit     = 100
+)
+
+// AnalyticsController handles the admin sales analytics
+//
+// Every report covers the orders placed in a range, selected with the query parameters:
+//   - from, to: dates (2025-01-31) or RFC 3339 timestamps; from is inclusive, to exclusive.
+//     Defaults to the 30 days up to the end of today.
+//   - tz: IANA time zone for dates and periods, e.g. "America/New_York" (default UTC)
+type AnalyticsController struct {
+	analyticsService services.AnalyticsService
+}
+
+// NewAnalyticsController creates a new AnalyticsController
+func NewAnalyticsController(analyticsService services.AnalyticsService) *AnalyticsController {
+	return &AnalyticsController{
+		analyticsService: analyticsService,
+	}
+}
+
+// GetSales handles GET /api/v1/admin/analytics/sales - Orders, units and revenue per period (admin only)
+// interval is day (default), week (starting Monday) or month
+func (ac *AnalyticsController) GetSales(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	// Verify admin role
+	role := getUserRoleFromContext(ctx)
+	if role != "admin" {
+		helpers.WriteErrorResponse(w, http.StatusForbidden, "forbidden", "Admin access required")
+		return
+	}
+
+	rng, err := parseAnalyticsRange(r)
+	if err != nil {
+		helpers.WriteErrorResponse(w, http.StatusBadRequest, "invalid_request", err.Error())
+		return
+	}
+	interval := model.AnalyticsInterval(r.URL.Query().Get("interval"))
+	if interval == "" {
+		interval = model.IntervalDay
+	}
+
+	periods, totals, err := ac.analyticsService.Sales(ctx, rng, interval)
+	if err != nil {
+		writeAnalyticsError(w, err)
+		return
+	}
+
+	response := types.SalesReportResponse{
+		Interval: string(interval),
+		TimeZone: rng.Location.String(),
+		From:     rng.From,
+		To:       rng.To,
+		Periods:  make([]types.SalesPeriodResponse, len(periods)),
+		Totals:   toSalesResponse(totals),
+	}
+	for i, period := range periods {
+		response.Periods[i] = types.SalesPeriodResponse{Start: period.Start, SalesResponse: toSalesResponse(&period.Sales)}
+	}
+	helpers.WriteJSONResponse(w, http.StatusOK, response)
+}
+
+// GetTopProducts handles GET /api/v1/admin/analytics/top-products - Best sellers by units (admin only)
+// limit is how many products to return (default 10, max 100)
+func (ac *AnalyticsController) GetTopProducts(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	// Verify admin role
+	role := getUserRoleFromContext(ctx)
+	if role != "admin" {
+		helpers.WriteErrorResponse(w, http.StatusForbidden, "forbidden", "Admin access required")
+		return
+	}
+
+	rng, err := parseAnalyticsRange(r)
+	if err != nil {
+		helpers.WriteErrorResponse(w, http.StatusBadRequest, "invalid_request", err.Error())
+		return
+	}
+	limit := defaultTopProductsLimit
+	if value := r.URL.Query().Get("limit"); value != "" {
+		limit, err = strconv.Atoi(value)
+		if err != nil || limit < 1 {
+			helpers.WriteErrorResponse(w, http.StatusBadRequest, "invalid_request", "limit must be a positive integer")
+			return
+		}
+		if limit > maxTopProductsLimit {
+			limit = maxTopProductsLimit
+		}
+	}
+
+	products, err := ac.analyticsService.TopProducts(ctx, rng, limit)
+	if err != nil {
+		writeAnalyticsError(w, err)
+		return
+	}
+
+	responses := make([]types.ProductSalesResponse, len(products))
+	for i, product := range products {
+		responses[i] = types.ProductSalesResponse{
+			ProductID: product.ProductID.String(),
+			SKU:       product.SKU,
+			Name:      product.Name,
+			Orders:    product.Orders,
+			Units:     product.Units,
+			Revenue:   product.Revenue,
+		}
+	}
+	helpers.WriteJSONResponse(w, http.StatusOK, responses)
+}
+
+// GetFunnel handles GET /api/v1/admin/analytics/funnel - Orders by lifecycle step and status, with the cancellation rate (admin only)
+func (ac *AnalyticsController) GetFunnel(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	// Verify admin role
+	role := getUserRoleFromContext(ctx)
+	if role != "admin" {
+		helpers.WriteErrorResponse(w, http.StatusForbidden, "forbidden", "Admin access required")
+		return
+	}
+
+	rng, err := parseAnalyticsRange(r)
+	if err != nil {
+		helpers.WriteErrorResponse(w, http.StatusBadRequest, "invalid_request", err.Error())
+		return
+	}
+
+	funnel, err := ac.analyticsService.Funnel(ctx, rng)
+	if err != nil {
+		writeAnalyticsError(w, err)
+		return
+	}
+
+	response := types.OrderFunnelResponse{
+		From:             rng.From,
+		To:               rng.To,
+		Placed:           funnel.Placed,
+		Paid:             funnel.Paid,
+		Picking:          funnel.Picking,
+		Shipped:          funnel.Shipped,
+		Delivered:        funnel.Delivered,
+		Cancelled:        funnel.Statuses[model.OrderStatusCancelled],
+		CancellationRate: funnel.CancellationRate(),
+		Statuses:         make(map[string]int64, len(funnel.Statuses)),
+	}
+	for status, count := range funnel.Statuses {
+		response.Statuses[string(status)] = count
+	}
+	helpers.WriteJSONResponse(w, http.StatusOK, response)
+}
+
+// GetFulfillmentTimes handles GET /api/v1/admin/analytics/fulfillment - Average time from ORDERED to SHIPPED to DELIVERED (admin only)
+func (ac *AnalyticsController) GetFulfillmentTimes(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	// Verify admin role
+	role := getUserRoleFromContext(ctx)
+	if role != "admin" {
+		helpers.WriteErrorResponse(w, http.StatusForbidden, "forbidden", "Admin access required")
+		return
+	}
+
+	rng, err := parseAnalyticsRange(r)
+	if err != nil {
+		helpers.WriteErrorResponse(w, http.StatusBadRequest, "invalid_request", err.Error())
+		return
+	}
+
+	times, err := ac.analyticsService.FulfillmentTimes(ctx, rng)
+	if err != nil {
+		writeAnalyticsError(w, err)
+		return
+	}
+
+	helpers.WriteJSONResponse(w, http.StatusOK, types.FulfillmentTimesResponse{
+		From:               rng.From,
+		To:                 rng.To,
+		OrderedToShipped:   toFulfillmentStepTime(times.ToShipped, times.ShippedOrders),
+		ShippedToDelivered: toFulfillmentStepTime(times.ToDelivered, times.DeliveredOrders),
+	})
+}
+
+// parseAnalyticsRange reads the report range and time zone from the query string
+func parseAnalyticsRange(r *http.Request) (model.AnalyticsRange, error) {
+	params := r.URL.Query()
+	rng := model.AnalyticsRange{Location: time.UTC}
+	if tz := params.Get("tz"); tz != "" {
+		location, err := time.LoadLocation(tz)
+		if err != nil || tz == "Local" {
+			return rng, fmt.Errorf("unknown time zone %q; use an IANA name such as Europe/London", tz)
+		}
+		rng.Location = location
+	}
+
+	now := time.Now().In(rng.Location)
+	rng.To = model.IntervalDay.Next(model.IntervalDay.Start(now))
+	if value := params.Get("to"); value != "" {
+		to, err := parseAnalyticsTime(value, "to", rng.Location)
+		if err != nil {
+			return rng, err
+		}
+		rng.To = to
+	}
+	rng.From = rng.To.In(rng.Location).AddDate(0, 0, -defaultAnalyticsDays)
+	if value := params.Get("from"); value != "" {
+		from, err := parseAnalyticsTime(value, "from", rng.Location)
+		if err != nil {
+			return rng, err
+		}
+		rng.From = from
+	}
+	if !rng.From.Before(rng.To) {
+		return rng, errors.New("from must be before to")
+	}
+	return rng, nil
+}
+
+// parseAnalyticsTime parses an RFC 3339 timestamp, or a date at midnight in location
+func parseAnalyticsTime(value, name string, location *time.Location) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t.In(location), nil
+	}
+	t, err := time.ParseInLocation("2006-01-02", value, location)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%s must be a date such as 2025-01-31 or an RFC 3339 timestamp", name)
+	}
+	return t, nil
+}
+
+// writeAnalyticsError maps analytics service errors to HTTP responses
+func writeAnalyticsError(w http.ResponseWriter, err error) {
+	if errors.Is(err, services.ErrInvalidAnalyticsQuery) {
+		helpers.WriteErrorResponse(w, http.StatusBadRequest, "invalid_request", err.Error())
+		return
+	}
+	helpers.WriteErrorResponse(w, http.StatusInternalServerError, "internal_error", "Failed to compute analytics")
+}
+
+// toSalesResponse converts sales to their API representation
+func toSalesResponse(sales *model.Sales) types.SalesResponse {
+	return types.SalesResponse{Orders: sales.Orders, Units: sales.Units, Revenue: sales.Revenue}
+}
+
+// toFulfillmentStepTime converts an average step duration to its API representation
+func toFulfillmentStepTime(average time.Duration, orders int64) types.FulfillmentStepTime {
+	return types.FulfillmentStepTime{
+		AverageSeconds: average.Seconds(),
+		AverageHours:   average.Hours(),
+		Orders:         orders,
+	}
+}
@@ -0,0 +1,185 @@
+package controllers
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"oms/server/api/v1/helpers"
+	"oms/server/api/v1/types"
+	"oms/server/core/audit"
+	"oms/server/core/model"
+)
+
+const (
+	defaultAuditLimit = 100
+	maxAuditLimit     = 1000
+)
+
+// auditCSVHeader is the first row of an audit log export
+var auditCSVHeader = []string{"created_at", "actor_type", "actor_id", "action", "entity_type", "entity_id", "changes", "request_id", "client_ip"}
+
+// AuditController handles the admin audit log
+type AuditController struct {
+	auditLog audit.Log
+}
+
+// NewAuditController creates a new AuditController
+func NewAuditController(auditLog audit.Log) *AuditController {
+	return &AuditController{
+		auditLog: auditLog,
+	}
+}
+
+// GetAuditLog handles GET /api/v1/admin/audit - Search the admin audit log, newest first (admin only)
+// Query parameters:
+//   - entity_type, entity_id: the changed entity, e.g. entity_type=product&entity_id=...
+//   - actor_type, actor_id: who made the change; actor_id alone matches a user ID
+//   - from, to: RFC 3339 timestamps or dates (2025-01-31); from is inclusive, to exclusive
+//   - limit: at most this many entries (default 100, max 1000)
+//   - format=csv: download every matching entry as CSV instead; limit applies only when given
+func (ac *AuditController) GetAuditLog(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	// Verify admin role
+	role := getUserRoleFromContext(ctx)
+	if role != "admin" {
+		helpers.WriteErrorResponse(w, http.StatusForbidden, "forbidden", "Admin access required")
+		return
+	}
+
+	format := r.URL.Query().Get("format")
+	if format != "" && format != "json" && format != "csv" {
+		helpers.WriteErrorResponse(w, http.StatusBadRequest, "invalid_request", "Format must be json or csv")
+		return
+	}
+
+	filter, err := parseAuditFilter(r, format == "csv")
+	if err != nil {
+		helpers.WriteErrorResponse(w, http.StatusBadRequest, "invalid_request", err.Error())
+		return
+	}
+
+	if format == "csv" {
+		ac.exportCSV(w, r, filter)
+		return
+	}
+
+	entries, err := ac.auditLog.Search(ctx, filter)
+	if err != nil {
+		helpers.WriteErrorResponse(w, http.StatusInternalServerError, "internal_error", "Failed to fetch audit log")
+		return
+	}
+
+	responses := make([]types.AuditEntryResponse, len(entries))
+	for i, entry := range entries {
+		responses[i] = toAuditEntryResponse(entry)
+	}
+	helpers.WriteJSONResponse(w, http.StatusOK, responses)
+}
+
+// exportCSV streams the matching entries as a CSV download. Once rows are written the status
+// can't change, so a failure part way through ends the file early and is logged.
+func (ac *AuditController) exportCSV(w http.ResponseWriter, r *http.Request, filter model.AuditFilter) {
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", "audit-"+time.Now().UTC().Format("20060102-150405")+".csv"))
+	w.WriteHeader(http.StatusOK)
+
+	writer := csv.NewWriter(w)
+	writer.Write(auditCSVHeader)
+	err := ac.auditLog.Export(r.Context(), filter, func(entry *model.AuditEntry) error {
+		changes, err := json.Marshal(entry.Changes)
+		if err != nil {
+			return err
+		}
+		writer.Write([]string{
+			entry.CreatedAt.UTC().Format(time.RFC3339),
+			string(entry.ActorType),
+			entry.ActorID,
+			string(entry.Action),
+			entry.EntityType,
+			entry.EntityID,
+			string(changes),
+			entry.RequestID,
+			entry.ClientIP,
+		})
+		return writer.Error()
+	})
+	writer.Flush()
+	if err == nil {
+		err = writer.Error()
+	}
+	if err != nil {
+		log.Printf("Warning: audit log export ended early: %v", err)
+	}
+}
+
+// parseAuditFilter reads the audit log filters from the query string. Exports are unlimited
+// unless a limit is given.
+func parseAuditFilter(r *http.Request, export bool) (model.AuditFilter, error) {
+	params := r.URL.Query()
+	filter := model.AuditFilter{
+		EntityType: params.Get("entity_type"),
+		EntityID:   params.Get("entity_id"),
+		ActorType:  model.ActorType(params.Get("actor_type")),
+		ActorID:    params.Get("actor_id"),
+	}
+	if filter.ActorType == "" && filter.ActorID != "" {
+		filter.ActorType = model.ActorUser
+	}
+	if filter.ActorType != "" && !filter.ActorType.IsValid() {
+		return filter, errors.New("actor_type must be user, system, api_key or integration")
+	}
+
+	var err error
+	if filter.From, err = parseTimeParam(params.Get("from"), "from"); err != nil {
+		return filter, err
+	}
+	if filter.To, err = parseTimeParam(params.Get("to"), "to"); err != nil {
+		return filter, err
+	}
+	if filter.From != nil && filter.To != nil && !filter.From.Before(*filter.To) {
+		return filter, errors.New("from must be before to")
+	}
+
+	if !export {
+		filter.Limit = defaultAuditLimit
+	}
+	if value := params.Get("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit < 1 {
+			return filter, errors.New("limit must be a positive integer")
+		}
+		if limit > maxAuditLimit && !export {
+			limit = maxAuditLimit
+		}
+		filter.Limit = limit
+	}
+	return filter, nil
+}
+
+// toAuditEntryResponse converts an audit entry to its API representation
+func toAuditEntryResponse(entry *model.AuditEntry) types.AuditEntryResponse {
+	changes := map[string]interface{}(entry.Changes)
+	if changes == nil {
+		changes = map[string]interface{}{}
+	}
+	return types.AuditEntryResponse{
+		ID:         entry.ID.String(),
+		Actor:      types.ActorResponse{Type: string(entry.ActorType), ID: entry.ActorID},
+		Action:     string(entry.Action),
+		EntityType: entry.EntityType,
+		EntityID:   entry.EntityID,
+		Before:     entry.Before,
+		After:      entry.After,
+		Changes:    changes,
+		RequestID:  entry.RequestID,
+		ClientIP:   entry.ClientIP,
+		CreatedAt:  entry.CreatedAt,
+	}
+}
@@ -0,0 +1,217 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"oms/server/api/v1/helpers"
+	"oms/server/api/v1/types"
+	"oms/server/core/model"
+	"oms/server/core/money"
+	"oms/server/core/services"
+)
+
+// CartTokenHeader carries the token of an anonymous cart
+const CartTokenHeader = "X-Cart-Token"
+
+// CartController handles shopping cart HTTP requests. Signed-in customers use their own cart;
+// anonymous visitors are identified by the token returned when their cart was created.
+type CartController struct {
+	cartService services.CartService
+}
+
+// NewCartController creates a new CartController
+func NewCartController(cartService services.CartService) *CartController {
+	return &CartController{
+		cartService: cartService,
+	}
+}
+
+// GetCart handles GET /api/v1/cart - The caller's cart with current prices and stock
+func (cc *CartController) GetCart(w http.ResponseWriter, r *http.Request) {
+	cart, err := cc.cartService.GetCart(r.Context(), cartOwner(r))
+	if err != nil {
+		writeCartError(w, err)
+		return
+	}
+	writeCartResponse(w, http.StatusOK, cart)
+}
+
+// AddLine handles POST /api/v1/cart/lines - Add a product to the cart, creating it if needed
+func (cc *CartController) AddLine(w http.ResponseWriter, r *http.Request) {
+	var req types.AddCartLineRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		helpers.WriteErrorResponse(w, http.StatusBadRequest, "invalid_request", "Invalid request body")
+		return
+	}
+
+	productID, err := uuid.Parse(req.ProductID)
+	if err != nil {
+		helpers.WriteErrorResponse(w, http.StatusBadRequest, "invalid_request", "Invalid product ID format")
+		return
+	}
+	var variantID *uuid.UUID
+	if req.VariantID != "" {
+		parsed, err := uuid.Parse(req.VariantID)
+		if err != nil {
+			helpers.WriteErrorResponse(w, http.StatusBadRequest, "invalid_request", "Invalid variant ID format")
+			return
+		}
+		variantID = &parsed
+	}
+
+	cart, err := cc.cartService.AddLine(r.Context(), cartOwner(r), productID, variantID, req.Quantity)
+	if err != nil {
+		writeCartError(w, err)
+		return
+	}
+	writeCartResponse(w, http.StatusOK, cart)
+}
+
+// UpdateLine handles PUT /api/v1/cart/lines/{lineId} - Change a line's quantity
+func (cc *CartController) UpdateLine(w http.ResponseWriter, r *http.Request) {
+	lineID, err := uuid.Parse(mux.Vars(r)["lineId"])
+	if err != nil {
+		helpers.WriteErrorResponse(w, http.StatusBadRequest, "invalid_request", "Invalid cart line ID format")
+		return
+	}
+
+	var req types.UpdateCartLineRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		helpers.WriteErrorResponse(w, http.StatusBadRequest, "invalid_request", "Invalid request body")
+		return
+	}
+
+	cart, err := cc.cartService.UpdateLine(r.Context(), cartOwner(r), lineID, req.Quantity)
+	if err != nil {
+		writeCartError(w, err)
+		return
+	}
+	writeCartResponse(w, http.StatusOK, cart)
+}
+
+// RemoveLine handles DELETE /api/v1/cart/lines/{lineId} - Remove a line from the cart
+func (cc *CartController) RemoveLine(w http.ResponseWriter, r *http.Request) {
+	lineID, err := uuid.Parse(mux.Vars(r)["lineId"])
+	if err != nil {
+		helpers.WriteErrorResponse(w, http.StatusBadRequest, "invalid_request", "Invalid cart line ID format")
+		return
+	}
+
+	cart, err := cc.cartService.RemoveLine(r.Context(), cartOwner(r), lineID)
+	if err != nil {
+		writeCartError(w, err)
+		return
+	}
+	writeCartResponse(w, http.StatusOK, cart)
+}
+
+// Checkout handles POST /api/v1/cart/checkout - Place the cart as orders, one per line (requires authentication)
+// An anonymous cart sent in X-Cart-Token is merged in first. Admin users cannot place orders
+func (cc *CartController) Checkout(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID := getUserIDFromContext(ctx)
+	if userID == 0 {
+		helpers.WriteErrorResponse(w, http.StatusUnauthorized, "unauthorized", "Sign in to check out")
+		return
+	}
+	if getUserRoleFromContext(ctx) == "admin" {
+		helpers.WriteErrorResponse(w, http.StatusForbidden, "forbidden", "Admin users cannot place orders")
+		return
+	}
+
+	var req types.CheckoutRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		helpers.WriteErrorResponse(w, http.StatusBadRequest, "invalid_request", "Invalid request body")
+		return
+	}
+	metadata := model.JSONB{}
+	if req.ShippingAddress != nil {
+		metadata = model.JSONB(req.ShippingAddress)
+	}
+
+	orders, err := cc.cartService.Checkout(ctx, cartOwner(r), metadata, req.ShippingOption, req.PaymentMethod)
+	if err != nil {
+		writeCartError(w, err)
+		return
+	}
+
+	responses := make([]types.OrderResponse, len(orders))
+	totals := make([]money.Money, len(orders))
+	for i, order := range orders {
+		responses[i] = toOrderResponse(order)
+		totals[i] = order.Total
+	}
+	total, err := money.Sum(totals...)
+	if err != nil {
+		helpers.WriteErrorResponse(w, http.StatusInternalServerError, "internal_error", err.Error())
+		return
+	}
+
+	helpers.WriteJSONResponse(w, http.StatusCreated, types.CheckoutResponse{
+		CheckoutID: uuidString(orders[0].CheckoutID),
+		Orders:     responses,
+		Total:      total,
+		Message:    "Orders placed successfully",
+	})
+}
+
+// cartOwner identifies the caller's cart from the JWT user, if any, and the cart token header
+func cartOwner(r *http.Request) services.CartOwner {
+	return services.CartOwner{
+		UserID: getUserIDFromContext(r.Context()),
+		Token:  r.Header.Get(CartTokenHeader),
+	}
+}
+
+// writeCartError maps cart service errors to HTTP responses
+func writeCartError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, services.ErrCartLineNotFound):
+		helpers.WriteErrorResponse(w, http.StatusNotFound, "not_found", err.Error())
+	case errors.Is(err, services.ErrInvalidCartLine):
+		helpers.WriteErrorResponse(w, http.StatusBadRequest, "invalid_request", err.Error())
+	case errors.Is(err, services.ErrEmptyCart):
+		helpers.WriteErrorResponse(w, http.StatusBadRequest, "empty_cart", err.Error())
+	case errors.Is(err, services.ErrCartNotReady):
+		helpers.WriteErrorResponse(w, http.StatusConflict, "cart_not_ready", err.Error())
+	default:
+		writeOrderError(w, err, "Cart request failed: ")
+	}
+}
+
+// writeCartResponse writes a priced cart, returning the token of anonymous carts in the header as well
+func writeCartResponse(w http.ResponseWriter, status int, cart *model.PricedCart) {
+	response := types.CartResponse{
+		Lines:    make([]types.CartLineResponse, len(cart.Lines)),
+		Subtotal: cart.Subtotal,
+		Valid:    cart.Valid(),
+	}
+	if cart.Cart != nil {
+		response.ID = cart.Cart.ID.String()
+		response.ExpiresAt = &cart.Cart.ExpiresAt
+		if cart.Cart.Token != nil {
+			response.Token = *cart.Cart.Token
+			w.Header().Set(CartTokenHeader, response.Token)
+		}
+	}
+	for i, line := range cart.Lines {
+		response.Lines[i] = types.CartLineResponse{
+			ID:          line.ID.String(),
+			ProductID:   line.ProductID.String(),
+			VariantID:   uuidString(line.VariantID),
+			SKU:         line.SKU,
+			ProductName: line.ProductName,
+			Quantity:    line.Quantity,
+			UnitPrice:   line.UnitPrice,
+			LineTotal:   line.LineTotal,
+			Available:   line.Available,
+			Issue:       string(line.Issue),
+		}
+	}
+	helpers.WriteJSONResponse(w, status, response)
+}
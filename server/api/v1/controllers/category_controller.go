@@ -0,0 +1,278 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"oms/server/api/v1/helpers"
+	"oms/server/api/v1/types"
+	"oms/server/core/model"
+	"oms/server/core/services"
+)
+
+// CategoryController handles the category tree and product categorisation
+type CategoryController struct {
+	categoryService services.CategoryService
+}
+
+// NewCategoryController creates a new CategoryController
+func NewCategoryController(categoryService services.CategoryService) *CategoryController {
+	return &CategoryController{
+		categoryService: categoryService,
+	}
+}
+
+// GetCategories handles GET /api/v1/categories - The category tree, nested and in sort order (public)
+func (cc *CategoryController) GetCategories(w http.ResponseWriter, r *http.Request) {
+	tree, err := cc.categoryService.GetTree(r.Context())
+	if err != nil {
+		helpers.WriteErrorResponse(w, http.StatusInternalServerError, "internal_error", "Failed to fetch categories")
+		return
+	}
+
+	responses := make([]types.CategoryResponse, len(tree))
+	for i, node := range tree {
+		responses[i] = toCategoryTreeResponse(node)
+	}
+	helpers.WriteJSONResponse(w, http.StatusOK, responses)
+}
+
+// CreateCategory handles POST /api/v1/admin/categories - Create a category (admin only)
+func (cc *CategoryController) CreateCategory(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	// Verify admin role
+	role := getUserRoleFromContext(ctx)
+	if role != "admin" {
+		helpers.WriteErrorResponse(w, http.StatusForbidden, "forbidden", "Admin access required")
+		return
+	}
+
+	var req types.CreateCategoryRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		helpers.WriteErrorResponse(w, http.StatusBadRequest, "invalid_request", "Invalid request body")
+		return
+	}
+
+	var parentID *uuid.UUID
+	if req.ParentID != "" {
+		parsed, err := uuid.Parse(req.ParentID)
+		if err != nil {
+			helpers.WriteErrorResponse(w, http.StatusBadRequest, "invalid_request", "Invalid parent ID format")
+			return
+		}
+		parentID = &parsed
+	}
+
+	category, err := cc.categoryService.CreateCategory(ctx, req.Name, req.Slug, parentID, req.SortOrder)
+	if err != nil {
+		writeCategoryError(w, err)
+		return
+	}
+
+	helpers.WriteJSONResponse(w, http.StatusCreated, toCategoryResponse(category))
+}
+
+// UpdateCategory handles PUT /api/v1/admin/categories/{categoryId} - Rename a category (admin only)
+func (cc *CategoryController) UpdateCategory(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	// Verify admin role
+	role := getUserRoleFromContext(ctx)
+	if role != "admin" {
+		helpers.WriteErrorResponse(w, http.StatusForbidden, "forbidden", "Admin access required")
+		return
+	}
+
+	categoryID, err := uuid.Parse(mux.Vars(r)["categoryId"])
+	if err != nil {
+		helpers.WriteErrorResponse(w, http.StatusBadRequest, "invalid_request", "Invalid category ID format")
+		return
+	}
+
+	var req types.UpdateCategoryRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		helpers.WriteErrorResponse(w, http.StatusBadRequest, "invalid_request", "Invalid request body")
+		return
+	}
+
+	category, err := cc.categoryService.UpdateCategory(ctx, categoryID, req.Name, req.Slug)
+	if err != nil {
+		writeCategoryError(w, err)
+		return
+	}
+
+	helpers.WriteJSONResponse(w, http.StatusOK, toCategoryResponse(category))
+}
+
+// MoveCategory handles POST /api/v1/admin/categories/{categoryId}/move - Reorder or reparent a category (admin only)
+func (cc *CategoryController) MoveCategory(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	// Verify admin role
+	role := getUserRoleFromContext(ctx)
+	if role != "admin" {
+		helpers.WriteErrorResponse(w, http.StatusForbidden, "forbidden", "Admin access required")
+		return
+	}
+
+	categoryID, err := uuid.Parse(mux.Vars(r)["categoryId"])
+	if err != nil {
+		helpers.WriteErrorResponse(w, http.StatusBadRequest, "invalid_request", "Invalid category ID format")
+		return
+	}
+
+	var req types.MoveCategoryRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		helpers.WriteErrorResponse(w, http.StatusBadRequest, "invalid_request", "Invalid request body")
+		return
+	}
+
+	// Without parent_id the category stays under its current parent
+	var parentID *uuid.UUID
+	if req.ParentID == nil {
+		current, err := cc.categoryService.GetCategory(ctx, categoryID)
+		if err != nil {
+			writeCategoryError(w, err)
+			return
+		}
+		parentID = current.ParentID
+	} else if *req.ParentID != "" {
+		parsed, err := uuid.Parse(*req.ParentID)
+		if err != nil {
+			helpers.WriteErrorResponse(w, http.StatusBadRequest, "invalid_request", "Invalid parent ID format")
+			return
+		}
+		parentID = &parsed
+	}
+
+	position := -1
+	if req.Position != nil {
+		position = *req.Position
+	}
+
+	category, err := cc.categoryService.MoveCategory(ctx, categoryID, parentID, position)
+	if err != nil {
+		writeCategoryError(w, err)
+		return
+	}
+
+	helpers.WriteJSONResponse(w, http.StatusOK, toCategoryResponse(category))
+}
+
+// DeleteCategory handles DELETE /api/v1/admin/categories/{categoryId} - Delete a leaf category (admin only)
+func (cc *CategoryController) DeleteCategory(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	// Verify admin role
+	role := getUserRoleFromContext(ctx)
+	if role != "admin" {
+		helpers.WriteErrorResponse(w, http.StatusForbidden, "forbidden", "Admin access required")
+		return
+	}
+
+	categoryID, err := uuid.Parse(mux.Vars(r)["categoryId"])
+	if err != nil {
+		helpers.WriteErrorResponse(w, http.StatusBadRequest, "invalid_request", "Invalid category ID format")
+		return
+	}
+
+	if err := cc.categoryService.DeleteCategory(ctx, categoryID); err != nil {
+		writeCategoryError(w, err)
+		return
+	}
+
+	helpers.WriteJSONResponse(w, http.StatusOK, map[string]string{
+		"message":     "Category deleted successfully",
+		"category_id": categoryID.String(),
+	})
+}
+
+// SetProductCategories handles PUT /api/v1/admin/products/{productId}/categories - Assign a product to categories (admin only)
+func (cc *CategoryController) SetProductCategories(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	// Verify admin role
+	role := getUserRoleFromContext(ctx)
+	if role != "admin" {
+		helpers.WriteErrorResponse(w, http.StatusForbidden, "forbidden", "Admin access required")
+		return
+	}
+
+	productID, err := uuid.Parse(mux.Vars(r)["productId"])
+	if err != nil {
+		helpers.WriteErrorResponse(w, http.StatusBadRequest, "invalid_request", "Invalid product ID format")
+		return
+	}
+
+	var req types.SetProductCategoriesRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		helpers.WriteErrorResponse(w, http.StatusBadRequest, "invalid_request", "Invalid request body")
+		return
+	}
+
+	categoryIDs := make([]uuid.UUID, len(req.CategoryIDs))
+	for i, value := range req.CategoryIDs {
+		categoryIDs[i], err = uuid.Parse(value)
+		if err != nil {
+			helpers.WriteErrorResponse(w, http.StatusBadRequest, "invalid_request", "Invalid category ID format")
+			return
+		}
+	}
+
+	categories, err := cc.categoryService.SetProductCategories(ctx, productID, categoryIDs)
+	if err != nil {
+		if errors.Is(err, services.ErrCategoryNotFound) {
+			helpers.WriteErrorResponse(w, http.StatusBadRequest, "invalid_request", err.Error())
+			return
+		}
+		helpers.WriteErrorResponse(w, http.StatusNotFound, "not_found", "Product not found")
+		return
+	}
+
+	response := types.ProductCategoriesResponse{
+		ProductID:  productID.String(),
+		Categories: make([]types.CategoryResponse, len(categories)),
+	}
+	for i, category := range categories {
+		response.Categories[i] = toCategoryResponse(category)
+	}
+	helpers.WriteJSONResponse(w, http.StatusOK, response)
+}
+
+// writeCategoryError maps category service errors to HTTP responses
+func writeCategoryError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, services.ErrCategoryNotFound):
+		helpers.WriteErrorResponse(w, http.StatusNotFound, "not_found", err.Error())
+	case errors.Is(err, services.ErrInvalidCategory):
+		helpers.WriteErrorResponse(w, http.StatusBadRequest, "invalid_request", err.Error())
+	case errors.Is(err, services.ErrCategoryCycle), errors.Is(err, services.ErrCategoryHasChildren):
+		helpers.WriteErrorResponse(w, http.StatusConflict, "conflict", err.Error())
+	default:
+		helpers.WriteErrorResponse(w, http.StatusInternalServerError, "internal_error", err.Error())
+	}
+}
+
+// toCategoryResponse converts a category to its API representation
+func toCategoryResponse(category *model.Category) types.CategoryResponse {
+	return types.CategoryResponse{
+		ID:        category.ID.String(),
+		ParentID:  uuidString(category.ParentID),
+		Name:      category.Name,
+		Slug:      category.Slug,
+		SortOrder: category.SortOrder,
+	}
+}
+
+// toCategoryTreeResponse converts a category node and its descendants to their API representation
+func toCategoryTreeResponse(node *model.CategoryNode) types.CategoryResponse {
+	response := toCategoryResponse(&node.Category)
+	for _, child := range node.Children {
+		response.Children = append(response.Children, toCategoryTreeResponse(child))
+	}
+	return response
+}
@@ -0,0 +1,225 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"oms/server/api/v1/helpers"
+	"oms/server/api/v1/types"
+	"oms/server/core/export"
+	"oms/server/core/model"
+	"oms/server/core/services"
+)
+
+// ExportController handles the admin order exports
+//
+// Exports select orders with the order list's filters (status, user_id, product_id, from, to) and
+// these query parameters:
+//   - format: csv (default) or ndjson, one JSON object per line
+//   - columns: the columns to export, comma separated, in order; all of them by default
+//   - include: product adds the "product." columns, the ordered product as it is now in the
+//     catalog; history adds each order's state log, as a JSON array in CSV files. Comma separated.
+type ExportController struct {
+	exportService services.ExportService
+}
+
+// NewExportController creates a new ExportController
+func NewExportController(exportService services.ExportService) *ExportController {
+	return &ExportController{
+		exportService: exportService,
+	}
+}
+
+// ExportOrders handles GET /api/v1/admin/exports/orders - Stream the matching orders, oldest first (admin only)
+func (ec *ExportController) ExportOrders(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	// Verify admin role
+	role := getUserRoleFromContext(ctx)
+	if role != "admin" {
+		helpers.WriteErrorResponse(w, http.StatusForbidden, "forbidden", "Admin access required")
+		return
+	}
+
+	spec, err := parseOrderExport(r)
+	if err != nil {
+		helpers.WriteErrorResponse(w, http.StatusBadRequest, "invalid_request", err.Error())
+		return
+	}
+	if err := ec.exportService.CheckOrderExport(spec); err != nil {
+		writeExportError(w, err)
+		return
+	}
+
+	// Large exports outlast the server's write timeout, which is meant for ordinary responses
+	if err := http.NewResponseController(w).SetWriteDeadline(time.Time{}); err != nil {
+		log.Printf("Warning: order export keeps the server write timeout: %v", err)
+	}
+	w.Header().Set("Content-Type", export.ContentType(spec.Format))
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", "orders-"+time.Now().UTC().Format("20060102-150405")+"."+string(spec.Format)))
+	w.WriteHeader(http.StatusOK)
+
+	// Once rows are written the status can't change, so a failure part way through ends the file
+	// early and is logged
+	if rows, err := ec.exportService.ExportOrders(ctx, w, spec); err != nil {
+		log.Printf("Warning: order export ended early after %d rows: %v", rows, err)
+	}
+}
+
+// StartOrderExport handles POST /api/v1/admin/exports/orders - Export the matching orders to a file in the background (admin only)
+// Takes the same query parameters as the streamed export and returns 202 with the export job;
+// poll it until it completes, then download it from its download_url.
+func (ec *ExportController) StartOrderExport(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	// Verify admin role
+	role := getUserRoleFromContext(ctx)
+	if role != "admin" {
+		helpers.WriteErrorResponse(w, http.StatusForbidden, "forbidden", "Admin access required")
+		return
+	}
+
+	spec, err := parseOrderExport(r)
+	if err != nil {
+		helpers.WriteErrorResponse(w, http.StatusBadRequest, "invalid_request", err.Error())
+		return
+	}
+
+	job, err := ec.exportService.StartOrderExport(ctx, spec, getUserIDFromContext(ctx))
+	if err != nil {
+		writeExportError(w, err)
+		return
+	}
+
+	w.Header().Set("Location", "/api/v1/admin/exports/"+job.ID.String())
+	helpers.WriteJSONResponse(w, http.StatusAccepted, toExportJobResponse(job))
+}
+
+// GetExport handles GET /api/v1/admin/exports/{exportId} - Get a background export's status (admin only)
+func (ec *ExportController) GetExport(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	// Verify admin role
+	role := getUserRoleFromContext(ctx)
+	if role != "admin" {
+		helpers.WriteErrorResponse(w, http.StatusForbidden, "forbidden", "Admin access required")
+		return
+	}
+
+	jobID, err := uuid.Parse(mux.Vars(r)["exportId"])
+	if err != nil {
+		helpers.WriteErrorResponse(w, http.StatusBadRequest, "invalid_request", "Invalid export ID format")
+		return
+	}
+
+	job, err := ec.exportService.GetExportJob(ctx, jobID)
+	if err != nil {
+		writeExportError(w, err)
+		return
+	}
+
+	helpers.WriteJSONResponse(w, http.StatusOK, toExportJobResponse(job))
+}
+
+// DownloadExport handles GET /api/v1/admin/exports/{exportId}/download - Download a completed background export (admin only)
+func (ec *ExportController) DownloadExport(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	// Verify admin role
+	role := getUserRoleFromContext(ctx)
+	if role != "admin" {
+		helpers.WriteErrorResponse(w, http.StatusForbidden, "forbidden", "Admin access required")
+		return
+	}
+
+	jobID, err := uuid.Parse(mux.Vars(r)["exportId"])
+	if err != nil {
+		helpers.WriteErrorResponse(w, http.StatusBadRequest, "invalid_request", "Invalid export ID format")
+		return
+	}
+
+	job, file, err := ec.exportService.OpenExportFile(ctx, jobID)
+	if err != nil {
+		writeExportError(w, err)
+		return
+	}
+	defer file.Close()
+
+	if err := http.NewResponseController(w).SetWriteDeadline(time.Time{}); err != nil {
+		log.Printf("Warning: export download keeps the server write timeout: %v", err)
+	}
+	w.Header().Set("Content-Type", export.ContentType(job.Format))
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", job.Kind+"-"+job.CreatedAt.UTC().Format("20060102-150405")+"."+string(job.Format)))
+	// ServeContent handles Range requests, so interrupted downloads of large files can resume
+	http.ServeContent(w, r, "", *job.CompletedAt, file)
+}
+
+// parseOrderExport reads an order export from the query string
+func parseOrderExport(r *http.Request) (model.OrderExport, error) {
+	params := r.URL.Query()
+	filter, err := parseOrderFilter(r)
+	if err != nil {
+		return model.OrderExport{}, err
+	}
+	spec := model.OrderExport{Filter: filter, Format: model.FormatCSV}
+	if format := params.Get("format"); format != "" {
+		spec.Format = model.FileFormat(strings.ToLower(format))
+	}
+	spec.Columns = splitParam(params.Get("columns"))
+	for _, include := range splitParam(params.Get("include")) {
+		switch include {
+		case "product":
+			spec.IncludeProduct = true
+		case "history":
+			spec.IncludeHistory = true
+		default:
+			return spec, fmt.Errorf("include must list product and/or history, got %q", include)
+		}
+	}
+	return spec, nil
+}
+
+// writeExportError maps export service errors to HTTP responses
+func writeExportError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, services.ErrInvalidExport), errors.Is(err, services.ErrInvalidOrderFilter):
+		helpers.WriteErrorResponse(w, http.StatusBadRequest, "invalid_request", err.Error())
+	case errors.Is(err, services.ErrExportNotFound):
+		helpers.WriteErrorResponse(w, http.StatusNotFound, "not_found", "Export not found")
+	case errors.Is(err, services.ErrExportNotReady):
+		helpers.WriteErrorResponse(w, http.StatusConflict, "export_not_ready", err.Error())
+	case errors.Is(err, services.ErrExportExpired):
+		helpers.WriteErrorResponse(w, http.StatusGone, "export_expired", err.Error())
+	default:
+		helpers.WriteErrorResponse(w, http.StatusInternalServerError, "internal_error", "Failed to export orders")
+	}
+}
+
+// toExportJobResponse converts an export job to its API representation
+func toExportJobResponse(job *model.ExportJob) types.ExportJobResponse {
+	response := types.ExportJobResponse{
+		ID:          job.ID.String(),
+		Kind:        job.Kind,
+		Status:      string(job.Status),
+		Format:      string(job.Format),
+		Params:      map[string]interface{}(job.Params),
+		RequestedBy: job.RequestedBy,
+		RowCount:    job.RowCount,
+		Size:        job.Size,
+		Error:       job.Error,
+		CreatedAt:   job.CreatedAt,
+		StartedAt:   job.StartedAt,
+		CompletedAt: job.CompletedAt,
+		ExpiresAt:   job.ExpiresAt,
+	}
+	if job.Status == model.ExportCompleted {
+		response.DownloadURL = "/api/v1/admin/exports/" + job.ID.String() + "/download"
+	}
+	return response
+}
@@ -0,0 +1,179 @@
+package controllers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"oms/server/api/v1/helpers"
+	"oms/server/api/v1/types"
+	"oms/server/core/document"
+	"oms/server/core/model"
+	"oms/server/core/services"
+)
+
+// FulfillmentController handles the warehouse documents: pick lists and packing slips
+type FulfillmentController struct {
+	fulfillmentService services.FulfillmentService
+}
+
+// NewFulfillmentController creates a new FulfillmentController
+func NewFulfillmentController(fulfillmentService services.FulfillmentService) *FulfillmentController {
+	return &FulfillmentController{fulfillmentService: fulfillmentService}
+}
+
+// CreatePickList handles POST /api/v1/admin/pick-lists - Generate a pick list, as JSON or with ?format=html as a printable page (admin only)
+func (fc *FulfillmentController) CreatePickList(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	// Verify admin role
+	role := getUserRoleFromContext(ctx)
+	if role != "admin" {
+		helpers.WriteErrorResponse(w, http.StatusForbidden, "forbidden", "Admin access required")
+		return
+	}
+
+	format := r.URL.Query().Get("format")
+	if format != "" && format != "json" && format != "html" {
+		helpers.WriteErrorResponse(w, http.StatusBadRequest, "invalid_request", "Format must be json or html")
+		return
+	}
+
+	// An empty body picks every order waiting to ship
+	var req types.PickListRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		helpers.WriteErrorResponse(w, http.StatusBadRequest, "invalid_request", "Invalid request body")
+		return
+	}
+	orderIDs := make([]uuid.UUID, len(req.OrderIDs))
+	for i, id := range req.OrderIDs {
+		orderID, err := uuid.Parse(id)
+		if err != nil {
+			helpers.WriteErrorResponse(w, http.StatusBadRequest, "invalid_request", "Invalid order ID format")
+			return
+		}
+		orderIDs[i] = orderID
+	}
+
+	list, err := fc.fulfillmentService.GeneratePickList(ctx, orderIDs, req.StartPicking, getUserIDFromContext(ctx))
+	if err != nil {
+		writePickingError(w, err, "Failed to generate pick list: ")
+		return
+	}
+
+	if format == "html" {
+		var page bytes.Buffer
+		if err := document.RenderPickListHTML(&page, list); err != nil {
+			helpers.WriteErrorResponse(w, http.StatusInternalServerError, "internal_error", "Failed to render pick list: "+err.Error())
+			return
+		}
+		writeDocument(w, "text/html; charset=utf-8", "", page.Bytes())
+		return
+	}
+	helpers.WriteJSONResponse(w, http.StatusOK, toPickListResponse(list))
+}
+
+// GetPackingSlip handles GET /api/v1/orders/{orderId}/packing-slip - Packing slip for the order's open units,
+// as a PDF or with ?format=html as a printable page (admin only)
+func (fc *FulfillmentController) GetPackingSlip(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	// Verify admin role
+	role := getUserRoleFromContext(ctx)
+	if role != "admin" {
+		helpers.WriteErrorResponse(w, http.StatusForbidden, "forbidden", "Admin access required")
+		return
+	}
+
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = "pdf"
+	}
+	if format != "pdf" && format != "html" {
+		helpers.WriteErrorResponse(w, http.StatusBadRequest, "invalid_request", "Format must be pdf or html")
+		return
+	}
+
+	orderID, err := uuid.Parse(mux.Vars(r)["orderId"])
+	if err != nil {
+		helpers.WriteErrorResponse(w, http.StatusBadRequest, "invalid_request", "Invalid order ID format")
+		return
+	}
+
+	slip, err := fc.fulfillmentService.GetPackingSlip(ctx, orderID)
+	if err != nil {
+		writePickingError(w, err, "Failed to generate packing slip: ")
+		return
+	}
+
+	filename := fmt.Sprintf("packing-slip-%s.%s", orderID, format)
+	if format == "html" {
+		var page bytes.Buffer
+		if err := document.RenderPackingSlipHTML(&page, slip); err != nil {
+			helpers.WriteErrorResponse(w, http.StatusInternalServerError, "internal_error", "Failed to render packing slip: "+err.Error())
+			return
+		}
+		writeDocument(w, "text/html; charset=utf-8", filename, page.Bytes())
+		return
+	}
+	writeDocument(w, "application/pdf", filename, document.RenderPackingSlipPDF(slip))
+}
+
+// writeDocument writes a rendered document, inline so browsers can print it
+func writeDocument(w http.ResponseWriter, contentType, filename string, body []byte) {
+	w.Header().Set("Content-Type", contentType)
+	if filename != "" {
+		w.Header().Set("Content-Disposition", fmt.Sprintf("inline; filename=%q", filename))
+	}
+	w.WriteHeader(http.StatusOK)
+	w.Write(body)
+}
+
+// writePickingError maps fulfillment service errors to HTTP responses
+func writePickingError(w http.ResponseWriter, err error, prefix string) {
+	switch {
+	case errors.Is(err, services.ErrOrderNotFound):
+		helpers.WriteErrorResponse(w, http.StatusNotFound, "not_found", err.Error())
+	case errors.Is(err, services.ErrNotPickable):
+		helpers.WriteErrorResponse(w, http.StatusConflict, "not_pickable", err.Error())
+	default:
+		writeFulfillmentError(w, err, prefix)
+	}
+}
+
+// toPickListResponse converts a pick list to its API representation
+func toPickListResponse(list *model.PickList) types.PickListResponse {
+	response := types.PickListResponse{
+		ID:          list.ID.String(),
+		GeneratedAt: list.GeneratedAt,
+		Lines:       make([]types.PickListLineResponse, len(list.Lines)),
+		OrderIDs:    make([]string, len(list.OrderIDs)),
+		TotalUnits:  list.TotalUnits,
+	}
+	for i, line := range list.Lines {
+		orders := make([]types.PickListOrderResponse, len(line.Orders))
+		for j, order := range line.Orders {
+			orders[j] = types.PickListOrderResponse{OrderID: order.OrderID.String(), Quantity: order.Quantity}
+		}
+		response.Lines[i] = types.PickListLineResponse{
+			BinLocation: line.BinLocation,
+			ProductID:   line.ProductID.String(),
+			SKU:         line.SKU,
+			ProductName: line.ProductName,
+			Quantity:    line.Quantity,
+			Orders:      orders,
+		}
+		if line.VariantID != nil {
+			response.Lines[i].VariantID = line.VariantID.String()
+		}
+	}
+	for i, orderID := range list.OrderIDs {
+		response.OrderIDs[i] = orderID.String()
+	}
+	return response
+}
@@ -0,0 +1,69 @@
+package controllers
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"oms/server/api/v1/helpers"
+	"oms/server/core/health"
+	"oms/server/core/worker"
+)
+
+// readinessTimeout bounds how long the readiness probe waits on its checks
+const readinessTimeout = 5 * time.Second
+
+// HealthController serves liveness and readiness probes
+type HealthController struct {
+	checker *health.Checker
+	workers *worker.Group
+}
+
+// NewHealthController creates a new HealthController. Both arguments are optional;
+// without a checker readiness is equivalent to liveness.
+func NewHealthController(checker *health.Checker, workers *worker.Group) *HealthController {
+	return &HealthController{
+		checker: checker,
+		workers: workers,
+	}
+}
+
+// readinessResponse is the JSON breakdown returned by the readiness probe
+type readinessResponse struct {
+	health.Report
+	Workers []worker.Status `json:"workers,omitempty"`
+}
+
+// Live handles GET /api/v1/health/live - the process is up and serving HTTP
+func (hc *HealthController) Live(w http.ResponseWriter, r *http.Request) {
+	helpers.WriteJSONResponse(w, http.StatusOK, map[string]string{
+		"status": string(health.StatusUp),
+	})
+}
+
+// Ready handles GET /api/v1/health/ready - the instance can accept traffic
+// Returns 503 with the failing checks if any dependency is down
+func (hc *HealthController) Ready(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), readinessTimeout)
+	defer cancel()
+
+	response := readinessResponse{
+		Report: health.Report{
+			Status:    health.StatusUp,
+			Checks:    map[string]health.CheckResult{},
+			CheckedAt: time.Now(),
+		},
+	}
+	if hc.checker != nil {
+		response.Report = hc.checker.Run(ctx)
+	}
+	if hc.workers != nil {
+		response.Workers = hc.workers.Statuses()
+	}
+
+	statusCode := http.StatusOK
+	if response.Status != health.StatusUp {
+		statusCode = http.StatusServiceUnavailable
+	}
+	helpers.WriteJSONResponse(w, statusCode, response)
+}
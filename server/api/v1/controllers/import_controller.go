@@ -0,0 +1,145 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"oms/server/api/v1/helpers"
+	"oms/server/api/v1/types"
+	"oms/server/core/model"
+	"oms/server/core/services"
+)
+
+// maxImportSize is the largest import file accepted, in bytes
+const maxImportSize = 256 << 20
+
+// ImportController handles the admin catalog imports
+//
+// Imports take the file as the request body and these query parameters:
+//   - format: csv or ndjson; by default ndjson when the Content-Type is application/x-ndjson, csv otherwise
+//   - dry_run: true checks every row and reports what the import would do, saving nothing
+//   - atomic: true saves every row or, when any is invalid, none; otherwise each batch is saved on
+//     its own and invalid rows are skipped
+//   - batch_size: how many rows are checked and saved at a time, 500 by default
+type ImportController struct {
+	importService services.ImportService
+}
+
+// NewImportController creates a new ImportController
+func NewImportController(importService services.ImportService) *ImportController {
+	return &ImportController{
+		importService: importService,
+	}
+}
+
+// ImportProducts handles POST /api/v1/admin/imports/products - Create or update products and their stock by SKU (admin only)
+// Returns the import report; rows that failed are listed in its errors by line, so a 200 doesn't
+// mean every row was saved.
+func (ic *ImportController) ImportProducts(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	// Verify admin role
+	role := getUserRoleFromContext(ctx)
+	if role != "admin" {
+		helpers.WriteErrorResponse(w, http.StatusForbidden, "forbidden", "Admin access required")
+		return
+	}
+
+	opts, err := parseImportOptions(r)
+	if err != nil {
+		helpers.WriteErrorResponse(w, http.StatusBadRequest, "invalid_request", err.Error())
+		return
+	}
+
+	// Large files take longer to upload and import than the server's timeouts allow ordinary requests
+	controller := http.NewResponseController(w)
+	if err := controller.SetReadDeadline(time.Time{}); err != nil {
+		log.Printf("Warning: product import keeps the server read timeout: %v", err)
+	}
+	if err := controller.SetWriteDeadline(time.Time{}); err != nil {
+		log.Printf("Warning: product import keeps the server write timeout: %v", err)
+	}
+
+	report, err := ic.importService.ImportProducts(ctx, http.MaxBytesReader(w, r.Body, maxImportSize), opts)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidImport) {
+			helpers.WriteErrorResponse(w, http.StatusBadRequest, "invalid_request", err.Error())
+			return
+		}
+		helpers.WriteErrorResponse(w, http.StatusInternalServerError, "internal_error", "Failed to import products: "+err.Error())
+		return
+	}
+
+	helpers.WriteJSONResponse(w, http.StatusOK, toImportReportResponse(report))
+}
+
+// parseImportOptions reads an import's options from the query string and Content-Type
+func parseImportOptions(r *http.Request) (model.ImportOptions, error) {
+	params := r.URL.Query()
+	opts := model.ImportOptions{Format: model.FormatCSV}
+	if format := params.Get("format"); format != "" {
+		opts.Format = model.FileFormat(strings.ToLower(format))
+	} else if mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type")); err == nil && mediaType == "application/x-ndjson" {
+		opts.Format = model.FormatNDJSON
+	}
+	if !opts.Format.IsValid() {
+		return opts, errors.New("format must be csv or ndjson")
+	}
+
+	var err error
+	if opts.DryRun, err = parseBoolParam(params.Get("dry_run"), "dry_run"); err != nil {
+		return opts, err
+	}
+	if opts.Atomic, err = parseBoolParam(params.Get("atomic"), "atomic"); err != nil {
+		return opts, err
+	}
+	if value := params.Get("batch_size"); value != "" {
+		opts.BatchSize, err = strconv.Atoi(value)
+		if err != nil || opts.BatchSize < 1 || opts.BatchSize > services.MaxImportBatchSize {
+			return opts, fmt.Errorf("batch_size must be between 1 and %d", services.MaxImportBatchSize)
+		}
+	}
+	return opts, nil
+}
+
+// parseBoolParam parses an optional boolean query parameter, false when empty
+func parseBoolParam(value, name string) (bool, error) {
+	if value == "" {
+		return false, nil
+	}
+	parsed, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("%s must be true or false", name)
+	}
+	return parsed, nil
+}
+
+// toImportReportResponse converts an import report to its API representation
+func toImportReportResponse(report *model.ImportReport) types.ImportReportResponse {
+	response := types.ImportReportResponse{
+		DryRun:        report.DryRun,
+		Atomic:        report.Atomic,
+		Saved:         report.Saved,
+		Rows:          report.Rows,
+		Created:       report.Created,
+		Updated:       report.Updated,
+		Unchanged:     report.Unchanged,
+		Failed:        report.Failed,
+		Errors:        make([]types.ImportErrorResponse, len(report.Errors)),
+		ErrorsOmitted: report.ErrorsOmitted,
+		Batches:       make([]types.ImportBatchResponse, len(report.Batches)),
+	}
+	for i, err := range report.Errors {
+		response.Errors[i] = types.ImportErrorResponse(err)
+	}
+	for i, batch := range report.Batches {
+		response.Batches[i] = types.ImportBatchResponse(batch)
+	}
+	return response
+}
@@ -0,0 +1,272 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"oms/server/api/v1/helpers"
+	"oms/server/api/v1/types"
+	"oms/server/core/model"
+	"oms/server/core/services"
+)
+
+// MetadataSchemaController handles registration of metadata schemas and dry-run validation
+type MetadataSchemaController struct {
+	schemaService services.MetadataSchemaService
+}
+
+// NewMetadataSchemaController creates a new MetadataSchemaController
+func NewMetadataSchemaController(schemaService services.MetadataSchemaService) *MetadataSchemaController {
+	return &MetadataSchemaController{
+		schemaService: schemaService,
+	}
+}
+
+// GetSchemas handles GET /api/v1/admin/schemas - List registered metadata schemas (admin only)
+func (mc *MetadataSchemaController) GetSchemas(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	// Verify admin role
+	role := getUserRoleFromContext(ctx)
+	if role != "admin" {
+		helpers.WriteErrorResponse(w, http.StatusForbidden, "forbidden", "Admin access required")
+		return
+	}
+
+	schemas, err := mc.schemaService.ListSchemas(ctx)
+	if err != nil {
+		helpers.WriteErrorResponse(w, http.StatusInternalServerError, "internal_error", "Failed to fetch schemas")
+		return
+	}
+
+	responses := make([]types.MetadataSchemaResponse, len(schemas))
+	for i, schema := range schemas {
+		responses[i] = toMetadataSchemaResponse(schema)
+	}
+	helpers.WriteJSONResponse(w, http.StatusOK, responses)
+}
+
+// CreateSchema handles POST /api/v1/admin/schemas - Register a metadata schema (admin only)
+func (mc *MetadataSchemaController) CreateSchema(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	// Verify admin role
+	role := getUserRoleFromContext(ctx)
+	if role != "admin" {
+		helpers.WriteErrorResponse(w, http.StatusForbidden, "forbidden", "Admin access required")
+		return
+	}
+
+	var req types.MetadataSchemaRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		helpers.WriteErrorResponse(w, http.StatusBadRequest, "invalid_request", "Invalid request body")
+		return
+	}
+
+	schema, ok := schemaFromRequest(w, req)
+	if !ok {
+		return
+	}
+	if err := mc.schemaService.CreateSchema(ctx, schema); err != nil {
+		writeSchemaError(w, err)
+		return
+	}
+
+	helpers.WriteJSONResponse(w, http.StatusCreated, toMetadataSchemaResponse(schema))
+}
+
+// UpdateSchema handles PUT /api/v1/admin/schemas/{schemaId} - Replace a metadata schema (admin only)
+func (mc *MetadataSchemaController) UpdateSchema(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	// Verify admin role
+	role := getUserRoleFromContext(ctx)
+	if role != "admin" {
+		helpers.WriteErrorResponse(w, http.StatusForbidden, "forbidden", "Admin access required")
+		return
+	}
+
+	schemaID, err := uuid.Parse(mux.Vars(r)["schemaId"])
+	if err != nil {
+		helpers.WriteErrorResponse(w, http.StatusBadRequest, "invalid_request", "Invalid schema ID format")
+		return
+	}
+
+	var req types.MetadataSchemaRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		helpers.WriteErrorResponse(w, http.StatusBadRequest, "invalid_request", "Invalid request body")
+		return
+	}
+
+	schema, ok := schemaFromRequest(w, req)
+	if !ok {
+		return
+	}
+	schema.ID = schemaID
+	if err := mc.schemaService.UpdateSchema(ctx, schema); err != nil {
+		writeSchemaError(w, err)
+		return
+	}
+
+	helpers.WriteJSONResponse(w, http.StatusOK, toMetadataSchemaResponse(schema))
+}
+
+// DeleteSchema handles DELETE /api/v1/admin/schemas/{schemaId} - Remove a metadata schema (admin only)
+func (mc *MetadataSchemaController) DeleteSchema(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	// Verify admin role
+	role := getUserRoleFromContext(ctx)
+	if role != "admin" {
+		helpers.WriteErrorResponse(w, http.StatusForbidden, "forbidden", "Admin access required")
+		return
+	}
+
+	schemaID, err := uuid.Parse(mux.Vars(r)["schemaId"])
+	if err != nil {
+		helpers.WriteErrorResponse(w, http.StatusBadRequest, "invalid_request", "Invalid schema ID format")
+		return
+	}
+
+	if err := mc.schemaService.DeleteSchema(ctx, schemaID); err != nil {
+		writeSchemaError(w, err)
+		return
+	}
+
+	helpers.WriteJSONResponse(w, http.StatusOK, map[string]string{
+		"message":   "Schema deleted successfully",
+		"schema_id": schemaID.String(),
+	})
+}
+
+// ValidateMetadata handles POST /api/v1/admin/schemas/validate - Dry-run metadata validation (admin only)
+func (mc *MetadataSchemaController) ValidateMetadata(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	// Verify admin role
+	role := getUserRoleFromContext(ctx)
+	if role != "admin" {
+		helpers.WriteErrorResponse(w, http.StatusForbidden, "forbidden", "Admin access required")
+		return
+	}
+
+	var req types.ValidateMetadataRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		helpers.WriteErrorResponse(w, http.StatusBadRequest, "invalid_request", "Invalid request body")
+		return
+	}
+	document := model.JSONB(req.Document)
+
+	var violations []model.SchemaViolation
+	var err error
+	switch {
+	case req.Schema != nil:
+		violations, err = mc.schemaService.ValidateAgainst(model.JSONB(req.Schema), document)
+	case model.SchemaTarget(req.Target) == model.SchemaTargetProduct:
+		var productID *uuid.UUID
+		if req.ProductID != "" {
+			parsed, parseErr := uuid.Parse(req.ProductID)
+			if parseErr != nil {
+				helpers.WriteErrorResponse(w, http.StatusBadRequest, "invalid_request", "Invalid product ID format")
+				return
+			}
+			productID = &parsed
+		}
+		err = mc.schemaService.ValidateProductMetadata(ctx, productID, document)
+	case model.SchemaTarget(req.Target) == model.SchemaTargetShippingAddress:
+		err = mc.schemaService.ValidateShippingAddress(ctx, document)
+	default:
+		helpers.WriteErrorResponse(w, http.StatusBadRequest, "invalid_request", "Either schema or a target of \"product\" or \"shipping_address\" is required")
+		return
+	}
+
+	var validationErr *services.MetadataValidationError
+	if errors.As(err, &validationErr) {
+		violations, err = validationErr.Violations, nil
+	}
+	if err != nil {
+		writeSchemaError(w, err)
+		return
+	}
+
+	helpers.WriteJSONResponse(w, http.StatusOK, types.ValidateMetadataResponse{
+		Valid:  len(violations) == 0,
+		Errors: toFieldErrorResponses(violations),
+	})
+}
+
+// schemaFromRequest converts a schema request to a model, writing a 400 response when the category ID is malformed
+func schemaFromRequest(w http.ResponseWriter, req types.MetadataSchemaRequest) (*model.MetadataSchema, bool) {
+	schema := &model.MetadataSchema{
+		Name:        req.Name,
+		Target:      model.SchemaTarget(req.Target),
+		ProductType: req.ProductType,
+		Schema:      model.JSONB(req.Schema),
+	}
+	if req.CategoryID != "" {
+		categoryID, err := uuid.Parse(req.CategoryID)
+		if err != nil {
+			helpers.WriteErrorResponse(w, http.StatusBadRequest, "invalid_request", "Invalid category ID format")
+			return nil, false
+		}
+		schema.CategoryID = &categoryID
+	}
+	return schema, true
+}
+
+// writeSchemaError maps metadata schema service errors to HTTP responses
+func writeSchemaError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, services.ErrSchemaNotFound):
+		helpers.WriteErrorResponse(w, http.StatusNotFound, "not_found", err.Error())
+	case errors.Is(err, services.ErrInvalidSchema):
+		helpers.WriteErrorResponse(w, http.StatusBadRequest, "invalid_request", err.Error())
+	default:
+		helpers.WriteErrorResponse(w, http.StatusInternalServerError, "internal_error", err.Error())
+	}
+}
+
+// writeMetadataValidationError writes a 422 response with field-level errors when err is a
+// *services.MetadataValidationError, and reports whether it did
+func writeMetadataValidationError(w http.ResponseWriter, err error) bool {
+	var validationErr *services.MetadataValidationError
+	if !errors.As(err, &validationErr) {
+		return false
+	}
+	helpers.WriteJSONResponse(w, http.StatusUnprocessableEntity, types.ValidationErrorResponse{
+		Error:   "validation_failed",
+		Message: validationErr.Error(),
+		Errors:  toFieldErrorResponses(validationErr.Violations),
+	})
+	return true
+}
+
+// toFieldErrorResponses converts schema violations to their API representation
+func toFieldErrorResponses(violations []model.SchemaViolation) []types.FieldErrorResponse {
+	responses := make([]types.FieldErrorResponse, len(violations))
+	for i, violation := range violations {
+		responses[i] = types.FieldErrorResponse{
+			Schema:  violation.Schema,
+			Path:    violation.Path,
+			Message: violation.Message,
+		}
+	}
+	return responses
+}
+
+// toMetadataSchemaResponse converts a metadata schema to its API representation
+func toMetadataSchemaResponse(schema *model.MetadataSchema) types.MetadataSchemaResponse {
+	return types.MetadataSchemaResponse{
+		ID:          schema.ID.String(),
+		Name:        schema.Name,
+		Target:      string(schema.Target),
+		CategoryID:  uuidString(schema.CategoryID),
+		ProductType: schema.ProductType,
+		Schema:      map[string]interface{}(schema.Schema),
+		CreatedAt:   schema.CreatedAt,
+		UpdatedAt:   schema.UpdatedAt,
+	}
+}
@@ -3,7 +3,12 @@ package controllers
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/gorilla/mux"
@@ -31,37 +36,120 @@ func NewOrderController(orderService services.OrderService) *OrderController {
 func (oc *OrderController) CreateOrder(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 
+	req, ok := decodeOrderRequest(w, r)
+	if !ok {
+		return
+	}
+
+	// Call orderService.CreateOrder with user_id from JWT token
+	order, err := oc.orderService.CreateOrder(ctx, req.userID, req.productID, req.variantID, req.quantity, req.metadata, req.couponCodes, req.shippingOption, req.paymentMethod)
+	if err != nil {
+		writeOrderError(w, err, "Failed to create order: ")
+		return
+	}
+
+	helpers.WriteJSONResponse(w, http.StatusCreated, types.CreateOrderResponse{
+		OrderID:       order.ID.String(),
+		CurrentStatus: string(order.CurrentStatus),
+		Total:         order.Total,
+		Message:       "Order placed successfully",
+	})
+}
+
+// QuoteOrder handles POST /api/v1/orders/quote - Price an order, including shipping and promotions, without placing it
+// Takes the same body as CreateOrder
+func (oc *OrderController) QuoteOrder(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	req, ok := decodeOrderRequest(w, r)
+	if !ok {
+		return
+	}
+
+	order, err := oc.orderService.PreviewOrder(ctx, req.userID, req.productID, req.variantID, req.quantity, req.metadata, req.couponCodes, req.shippingOption)
+	if err != nil {
+		writeOrderError(w, err, "Failed to quote order: ")
+		return
+	}
+
+	helpers.WriteJSONResponse(w, http.StatusOK, types.OrderQuoteResponse{
+		ProductID:      order.ProductID.String(),
+		VariantID:      uuidString(order.VariantID),
+		Quantity:       order.Quantity,
+		SKU:            order.SKU,
+		ProductName:    order.ProductName,
+		UnitPrice:      order.UnitPrice,
+		Subtotal:       order.Subtotal,
+		Discount:       order.Discount,
+		Shipping:       order.Shipping,
+		Tax:            order.Tax,
+		TaxIncluded:    order.TaxIncluded,
+		Total:          order.Total,
+		Discounts:      toOrderDiscountResponses(order.Discounts),
+		TaxLines:       toOrderTaxLineResponses(order.TaxLines),
+		ShippingOption: toOrderShippingResponse(order),
+	})
+}
+
+// orderRequest is a decoded and validated CreateOrderRequest
+type orderRequest struct {
+	userID         int
+	productID      uuid.UUID
+	variantID      *uuid.UUID
+	quantity       int
+	metadata       model.JSONB
+	couponCodes    []string
+	shippingOption string
+	paymentMethod  string
+}
+
+// decodeOrderRequest checks that the caller may place orders and parses the request body,
+// writing an error response and returning false when either fails
+func decodeOrderRequest(w http.ResponseWriter, r *http.Request) (*orderRequest, bool) {
+	ctx := r.Context()
+
 	// Extract user_id and role from JWT token (set by auth middleware)
 	userID := getUserIDFromContext(ctx)
 	role := getUserRoleFromContext(ctx)
 	if userID == 0 {
 		helpers.WriteErrorResponse(w, http.StatusUnauthorized, "unauthorized", "User ID not found in context")
-		return
+		return nil, false
 	}
 
 	// Admin cannot place orders
 	if role == "admin" {
 		helpers.WriteErrorResponse(w, http.StatusForbidden, "forbidden", "Admin users cannot place orders")
-		return
+		return nil, false
 	}
 
 	var req types.CreateOrderRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		helpers.WriteErrorResponse(w, http.StatusBadRequest, "invalid_request", "Invalid request body")
-		return
+		return nil, false
 	}
 
 	// Validate request
 	if req.Quantity <= 0 {
 		helpers.WriteErrorResponse(w, http.StatusBadRequest, "invalid_request", "Quantity must be greater than 0")
-		return
+		return nil, false
 	}
 
 	// Parse product_id as UUID
 	productID, err := uuid.Parse(req.ProductID)
 	if err != nil {
 		helpers.WriteErrorResponse(w, http.StatusBadRequest, "invalid_request", "Invalid product ID format")
-		return
+		return nil, false
+	}
+
+	// Parse optional variant_id as UUID
+	var variantID *uuid.UUID
+	if req.VariantID != "" {
+		parsed, err := uuid.Parse(req.VariantID)
+		if err != nil {
+			helpers.WriteErrorResponse(w, http.StatusBadRequest, "invalid_request", "Invalid variant ID format")
+			return nil, false
+		}
+		variantID = &parsed
 	}
 
 	// Convert shipping address to metadata JSONB
@@ -70,24 +158,53 @@ func (oc *OrderController) CreateOrder(w http.ResponseWriter, r *http.Request) {
 		metadata = model.JSONB(req.ShippingAddress)
 	}
 
-	// Call orderService.CreateOrder with user_id from JWT token
-	order, err := oc.orderService.CreateOrder(ctx, userID, productID, req.Quantity, metadata)
-	if err != nil {
-		// Check for specific error types
-		errMsg := err.Error()
-		if len(errMsg) > 20 && errMsg[:20] == "insufficient inventory" {
-			helpers.WriteErrorResponse(w, http.StatusBadRequest, "insufficient_inventory", errMsg)
-			return
-		}
-		helpers.WriteErrorResponse(w, http.StatusInternalServerError, "internal_error", "Failed to create order: "+errMsg)
+	return &orderRequest{
+		userID:         userID,
+		productID:      productID,
+		variantID:      variantID,
+		quantity:       req.Quantity,
+		metadata:       metadata,
+		couponCodes:    req.CouponCodes,
+		shippingOption: req.ShippingOption,
+		paymentMethod:  req.PaymentMethod,
+	}, true
+}
+
+// writeOrderError maps order placement errors to HTTP responses; prefix describes the failed action
+func writeOrderError(w http.ResponseWriter, err error, prefix string) {
+	// Check for specific error types
+	errMsg := err.Error()
+	if errors.Is(err, services.ErrInsufficientInventory) {
+		helpers.WriteErrorResponse(w, http.StatusBadRequest, "insufficient_inventory", errMsg)
 		return
 	}
-
-	helpers.WriteJSONResponse(w, http.StatusCreated, types.CreateOrderResponse{
-		OrderID:       order.ID.String(),
-		CurrentStatus: string(order.CurrentStatus),
-		Message:       "Order placed successfully",
-	})
+	if errors.Is(err, services.ErrInvalidVariant) {
+		helpers.WriteErrorResponse(w, http.StatusBadRequest, "invalid_variant", errMsg)
+		return
+	}
+	if errors.Is(err, services.ErrInvalidCoupon) {
+		helpers.WriteErrorResponse(w, http.StatusBadRequest, "invalid_coupon", errMsg)
+		return
+	}
+	if errors.Is(err, services.ErrInvalidShippingOption) {
+		helpers.WriteErrorResponse(w, http.StatusBadRequest, "invalid_shipping_option", errMsg)
+		return
+	}
+	if errors.Is(err, services.ErrPromotionUnavailable) {
+		helpers.WriteErrorResponse(w, http.StatusConflict, "promotion_unavailable", errMsg)
+		return
+	}
+	if errors.Is(err, services.ErrProductNotFound) {
+		helpers.WriteErrorResponse(w, http.StatusNotFound, "not_found", errMsg)
+		return
+	}
+	if writePaymentError(w, err) {
+		return
+	}
+	if writeMetadataValidationError(w, err) {
+		return
+	}
+	helpers.WriteErrorResponse(w, http.StatusInternalServerError, "internal_error", prefix+errMsg)
 }
 
 // UpdateOrderStatus handles PATCH /api/v1/orders/{orderId}
@@ -135,32 +252,66 @@ func (oc *OrderController) UpdateOrderStatus(w http.ResponseWriter, r *http.Requ
 			helpers.WriteErrorResponse(w, http.StatusForbidden, "forbidden", "Admin can only update status to SHIPPED or DELIVERED")
 			return
 		}
+		// Orders being returned follow their return request
+		if currentOrder.CurrentStatus == model.OrderStatusReturnRequested {
+			helpers.WriteErrorResponse(w, http.StatusConflict, "invalid_transition", "Order has an open return; update the return request instead")
+			return
+		}
 	} else {
-		// Regular users can only cancel ORDERED orders (cannot update to SHIPPED or DELIVERED)
+		// Regular users can only cancel orders that have not shipped (cannot update to SHIPPED or DELIVERED)
 		if newStatus != model.OrderStatusCancelled {
-			helpers.WriteErrorResponse(w, http.StatusForbidden, "forbidden", "Regular users can only cancel ORDERED orders. Only admin can update status to SHIPPED or DELIVERED")
+			helpers.WriteErrorResponse(w, http.StatusForbidden, "forbidden", "Regular users can only cancel ORDERED or PAID orders. Only admin can update status to SHIPPED or DELIVERED")
+			return
+		}
+		// Verify the order is ORDERED or PAID before allowing cancellation
+		if currentOrder.CurrentStatus != model.OrderStatusOrdered && currentOrder.CurrentStatus != model.OrderStatusPaid {
+			helpers.WriteErrorResponse(w, http.StatusForbidden, "forbidden", "Only ORDERED or PAID orders can be cancelled by regular users")
 			return
 		}
-		// Verify the order is in ORDERED status before allowing cancellation
-		if currentOrder.CurrentStatus != model.OrderStatusOrdered {
-			helpers.WriteErrorResponse(w, http.StatusForbidden, "forbidden", "Only ORDERED orders can be cancelled by regular users")
+		if !isUserCancellationReason(req.Reason) {
+			writeInvalidCancellationReason(w, req.Reason)
 			return
 		}
 	}
 
-	// Call orderService.UpdateOrderStatus with user_id from JWT token
-	order, err := oc.orderService.UpdateOrderStatus(ctx, orderUUID, newStatus, userID)
+	// Shipping records a shipment of every open unit; other changes go through orderService.UpdateOrderStatus
+	var order *model.Order
+	if newStatus == model.OrderStatusShipped && currentOrder.OpenQuantity() > 0 {
+		if req.Shipment == nil {
+			helpers.WriteErrorResponse(w, http.StatusBadRequest, "invalid_shipment", "Shipment details (carrier and tracking_number) are required to ship an order")
+			return
+		}
+		_, err = oc.orderService.ShipOrder(ctx, orderUUID, toShipment(currentOrder.OpenQuantity(), *req.Shipment), userID)
+		if err == nil {
+			order, err = oc.orderService.GetOrderByID(ctx, orderUUID)
+		}
+	} else {
+		change := model.StatusChange{Actor: model.UserActor(userID), Reason: req.Reason, Note: req.Note}
+		order, err = oc.orderService.UpdateOrderStatus(ctx, orderUUID, newStatus, change)
+	}
 	if err != nil {
 		// Check for FSM validation error (409 Conflict)
 		errMsg := err.Error()
-		if len(errMsg) > 15 && errMsg[:15] == "invalid transition" {
+		if errors.Is(err, services.ErrInvalidTransition) {
 			helpers.WriteErrorResponse(w, http.StatusConflict, "invalid_transition", errMsg)
 			return
 		}
+		if errors.Is(err, services.ErrInvalidShipment) || errors.Is(err, services.ErrInvalidQuantity) || errors.Is(err, services.ErrInvalidReason) {
+			writeFulfillmentError(w, err, "")
+			return
+		}
 		if errMsg == "order not found" || len(errMsg) > 13 && errMsg[:13] == "order not found" {
 			helpers.WriteErrorResponse(w, http.StatusNotFound, "not_found", errMsg)
 			return
 		}
+		if errors.Is(err, services.ErrStockNotRestored) {
+			helpers.WriteErrorResponse(w, http.StatusInternalServerError, "stock_not_restored", "Order cancelled, but its stock was not restored: "+errMsg)
+			return
+		}
+		// Capturing or releasing the payment failed; the order keeps its status
+		if writePaymentError(w, err) {
+			return
+		}
 		helpers.WriteErrorResponse(w, http.StatusInternalServerError, "internal_error", "Failed to update order status")
 		return
 	}
@@ -174,8 +325,101 @@ func (oc *OrderController) UpdateOrderStatus(w http.ResponseWriter, r *http.Requ
 	})
 }
 
-// GetOrders handles GET /api/v1/orders
-// Admin sees all orders, regular users see only their own orders
+// CancelOrderItems handles POST /api/v1/orders/{orderId}/cancellations - Cancel part of an order's open units (owner only)
+// Cancelling every unit of an order that has not shipped cancels the order.
+func (oc *OrderController) CancelOrderItems(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID := getUserIDFromContext(ctx)
+	if userID == 0 {
+		helpers.WriteErrorResponse(w, http.StatusUnauthorized, "unauthorized", "User ID not found in context")
+		return
+	}
+
+	orderID, err := uuid.Parse(mux.Vars(r)["orderId"])
+	if err != nil {
+		helpers.WriteErrorResponse(w, http.StatusBadRequest, "invalid_request", "Invalid order ID format")
+		return
+	}
+
+	// Like whole-order cancellation, only the customer who placed the order can cancel its units
+	order, err := oc.orderService.GetOrderByID(ctx, orderID)
+	if err != nil {
+		helpers.WriteErrorResponse(w, http.StatusNotFound, "not_found", "Order not found")
+		return
+	}
+	if order.UserID != userID {
+		helpers.WriteErrorResponse(w, http.StatusForbidden, "forbidden", "You don't have access to this order")
+		return
+	}
+
+	var req types.CancelOrderItemsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		helpers.WriteErrorResponse(w, http.StatusBadRequest, "invalid_request", "Invalid request body")
+		return
+	}
+	if req.Quantity <= 0 {
+		helpers.WriteErrorResponse(w, http.StatusBadRequest, "invalid_request", "Quantity must be greater than 0")
+		return
+	}
+	if !isUserCancellationReason(req.Reason) {
+		writeInvalidCancellationReason(w, req.Reason)
+		return
+	}
+
+	change := model.StatusChange{Actor: model.UserActor(userID), Reason: req.Reason, Note: req.Note}
+	updated, err := oc.orderService.CancelQuantity(ctx, orderID, req.Quantity, change)
+	if err != nil {
+		writeFulfillmentError(w, err, "Failed to cancel order items: ")
+		return
+	}
+	helpers.WriteJSONResponse(w, http.StatusOK, toOrderResponse(updated))
+}
+
+// isUserCancellationReason reports whether reason can be given for a cancellation made through the
+// API. The payment reasons are left to the payment webhook and the stale order job.
+func isUserCancellationReason(reason string) bool {
+	switch model.CancellationReason(reason) {
+	case model.CancellationPaymentDeclined, model.CancellationPaymentTimeout:
+		return false
+	}
+	return model.CancellationReason(reason).IsValid()
+}
+
+// writeInvalidCancellationReason rejects a cancellation without a reason it may give
+func writeInvalidCancellationReason(w http.ResponseWriter, reason string) {
+	message := "A reason is required to cancel: customer_request, out_of_stock, address_issue, duplicate_order, fraud_suspected or other"
+	if reason != "" {
+		message = fmt.Sprintf("Unknown cancellation reason %q; use customer_request, out_of_stock, address_issue, duplicate_order, fraud_suspected or other", reason)
+	}
+	helpers.WriteErrorResponse(w, http.StatusBadRequest, "invalid_reason", message)
+}
+
+// writeFulfillmentError maps shipping and cancellation errors to HTTP responses; prefix describes the failed action
+func writeFulfillmentError(w http.ResponseWriter, err error, prefix string) {
+	errMsg := err.Error()
+	switch {
+	case errors.Is(err, services.ErrInvalidQuantity):
+		helpers.WriteErrorResponse(w, http.StatusBadRequest, "invalid_quantity", errMsg)
+	case errors.Is(err, services.ErrInvalidShipment):
+		helpers.WriteErrorResponse(w, http.StatusBadRequest, "invalid_shipment", errMsg)
+	case errors.Is(err, services.ErrInvalidReason):
+		helpers.WriteErrorResponse(w, http.StatusBadRequest, "invalid_reason", errMsg)
+	case errors.Is(err, services.ErrInvalidTransition):
+		helpers.WriteErrorResponse(w, http.StatusConflict, "invalid_transition", errMsg)
+	case writePaymentError(w, err):
+	default:
+		helpers.WriteErrorResponse(w, http.StatusInternalServerError, "internal_error", prefix+errMsg)
+	}
+}
+
+// GetOrders handles GET /api/v1/orders, newest first
+// Admin sees all orders, regular users see only their own orders. Query parameters:
+//   - status: current statuses, comma separated, e.g. status=PAID,PICKING
+//   - product_id: orders of one product
+//   - user_id: orders of one user (admin only)
+//   - from, to: when the orders were placed, RFC 3339 timestamps or dates (2025-01-31);
+//     from is inclusive, to exclusive
 func (oc *OrderController) GetOrders(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 
@@ -187,17 +431,23 @@ func (oc *OrderController) GetOrders(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	var orders []*model.Order
-	var err error
+	filter, err := parseOrderFilter(r)
+	if err != nil {
+		helpers.WriteErrorResponse(w, http.StatusBadRequest, "invalid_request", err.Error())
+		return
+	}
 
 	// Admin sees all orders, regular users see only their own
-	if role == "admin" {
-		orders, err = oc.orderService.GetAllOrders(ctx)
-	} else {
-		orders, err = oc.orderService.GetOrdersByUserID(ctx, userID)
+	if role != "admin" {
+		filter.UserID = userID
 	}
 
+	orders, err := oc.orderService.ListOrders(ctx, filter)
 	if err != nil {
+		if errors.Is(err, services.ErrInvalidOrderFilter) {
+			helpers.WriteErrorResponse(w, http.StatusBadRequest, "invalid_request", err.Error())
+			return
+		}
 		helpers.WriteErrorResponse(w, http.StatusInternalServerError, "internal_error", "Failed to fetch orders")
 		return
 	}
@@ -205,25 +455,72 @@ func (oc *OrderController) GetOrders(w http.ResponseWriter, r *http.Request) {
 	// Convert to response format
 	orderResponses := make([]types.OrderResponse, len(orders))
 	for i, order := range orders {
-		// Handle metadata conversion safely
-		metadata := map[string]interface{}{}
-		if order.Metadata != nil {
-			metadata = map[string]interface{}(order.Metadata)
+		orderResponses[i] = toOrderResponse(order)
+	}
+
+	helpers.WriteJSONResponse(w, http.StatusOK, orderResponses)
+}
+
+// parseOrderFilter reads the order list filters from the query string. They are shared by the
+// order list and the order exports.
+func parseOrderFilter(r *http.Request) (model.OrderFilter, error) {
+	params := r.URL.Query()
+	var filter model.OrderFilter
+	for _, status := range splitParam(params.Get("status")) {
+		filter.Statuses = append(filter.Statuses, model.OrderStatus(strings.ToUpper(status)))
+	}
+	if value := params.Get("user_id"); value != "" {
+		userID, err := strconv.Atoi(value)
+		if err != nil || userID < 1 {
+			return filter, errors.New("user_id must be a positive integer")
 		}
-		
-		orderResponses[i] = types.OrderResponse{
-			ID:            order.ID.String(),
-			UserID:        order.UserID,
-			ProductID:     order.ProductID.String(),
-			Quantity:      order.Quantity,
-			CurrentStatus: string(order.CurrentStatus),
-			Metadata:      metadata,
-			CreatedAt:     order.CreatedAt,
-			UpdatedAt:     order.UpdatedAt,
+		filter.UserID = userID
+	}
+	if value := params.Get("product_id"); value != "" {
+		productID, err := uuid.Parse(value)
+		if err != nil {
+			return filter, errors.New("Invalid product ID format")
 		}
+		filter.ProductID = &productID
 	}
 
-	helpers.WriteJSONResponse(w, http.StatusOK, orderResponses)
+	var err error
+	if filter.From, err = parseTimeParam(params.Get("from"), "from"); err != nil {
+		return filter, err
+	}
+	if filter.To, err = parseTimeParam(params.Get("to"), "to"); err != nil {
+		return filter, err
+	}
+	if filter.From != nil && filter.To != nil && !filter.From.Before(*filter.To) {
+		return filter, errors.New("from must be before to")
+	}
+	return filter, nil
+}
+
+// splitParam splits a comma separated query parameter, dropping empty items
+func splitParam(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// parseTimeParam parses an optional RFC 3339 timestamp or a date, which is midnight UTC
+func parseTimeParam(value, name string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return &t, nil
+	}
+	t, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return nil, fmt.Errorf("%s must be an RFC 3339 timestamp or a date such as 2025-01-31", name)
+	}
+	return &t, nil
 }
 
 // GetOrderHistory handles GET /api/v1/orders/{orderId}/history - Get order state change history
@@ -260,13 +557,39 @@ func (oc *OrderController) GetOrderHistory(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
+	// Narrow the history to a reason code, such as customer_request or defective, or an actor type
+	filter := model.OrderHistoryFilter{
+		Reason:    r.URL.Query().Get("reason"),
+		ActorType: model.ActorType(r.URL.Query().Get("actor_type")),
+	}
+	if filter.Reason != "" && !model.CancellationReason(filter.Reason).IsValid() && !model.ReturnReason(filter.Reason).IsValid() {
+		helpers.WriteErrorResponse(w, http.StatusBadRequest, "invalid_request", "Unknown reason; use a cancellation or return reason code")
+		return
+	}
+	if filter.ActorType != "" && !filter.ActorType.IsValid() {
+		helpers.WriteErrorResponse(w, http.StatusBadRequest, "invalid_request", "Actor type must be user, system, api_key or integration")
+		return
+	}
+
 	// Get order history
-	history, err := oc.orderService.GetOrderHistory(ctx, orderUUID)
+	history, err := oc.orderService.GetOrderHistory(ctx, orderUUID, filter)
 	if err != nil {
 		helpers.WriteErrorResponse(w, http.StatusInternalServerError, "internal_error", "Failed to fetch order history")
 		return
 	}
 
+	// Shipping and delivery steps show their shipment's tracking
+	shipments, err := oc.orderService.GetShipments(ctx, orderUUID)
+	if err != nil {
+		helpers.WriteErrorResponse(w, http.StatusInternalServerError, "internal_error", "Failed to fetch order shipments")
+		return
+	}
+	tracking := make(map[uuid.UUID]*types.ShipmentResponse, len(shipments))
+	for _, shipment := range shipments {
+		response := toShipmentResponse(shipment)
+		tracking[shipment.ID] = &response
+	}
+
 	// Convert to response format
 	historyResponses := make([]types.OrderHistoryResponse, len(history))
 	for i, log := range history {
@@ -275,8 +598,16 @@ func (oc *OrderController) GetOrderHistory(w http.ResponseWriter, r *http.Reques
 			PreviousStatus: string(log.PreviousStatus),
 			NewStatus:      string(log.NewStatus),
 			UpdatedBy:      log.UpdatedBy,
+			Actor:          types.ActorResponse{Type: string(log.ActorType), ID: log.ActorID},
+			ReasonCode:     log.ReasonCode,
+			Note:           log.Note,
+			RequestID:      log.RequestID,
+			ClientIP:       log.ClientIP,
 			UpdatedAt:      log.UpdatedAt,
 		}
+		if log.ShipmentID != nil {
+			historyResponses[i].Shipment = tracking[*log.ShipmentID]
+		}
 	}
 
 	helpers.WriteJSONResponse(w, http.StatusOK, historyResponses)
@@ -286,9 +617,15 @@ func (oc *OrderController) GetOrderHistory(w http.ResponseWriter, r *http.Reques
 func isValidOrderStatus(status model.OrderStatus) bool {
 	validStatuses := []model.OrderStatus{
 		model.OrderStatusOrdered,
+		model.OrderStatusPaid,
+		model.OrderStatusPicking,
+		model.OrderStatusPartiallyShipped,
 		model.OrderStatusShipped,
 		model.OrderStatusDelivered,
 		model.OrderStatusCancelled,
+		model.OrderStatusReturnRequested,
+		model.OrderStatusPartiallyReturned,
+		model.OrderStatusReturned,
 	}
 	for _, valid := range validStatuses {
 		if status == valid {
@@ -316,3 +653,92 @@ func getUserRoleFromContext(ctx context.Context) string {
 	return role
 }
 
+
+// Helper function to format an optional UUID, empty when unset
+func uuidString(id *uuid.UUID) string {
+	if id == nil {
+		return ""
+	}
+	return id.String()
+}
+
+// toOrderResponse converts an order to its API representation
+func toOrderResponse(order *model.Order) types.OrderResponse {
+	// Handle metadata conversion safely
+	metadata := map[string]interface{}{}
+	if order.Metadata != nil {
+		metadata = map[string]interface{}(order.Metadata)
+	}
+
+	return types.OrderResponse{
+		ID:                order.ID.String(),
+		UserID:            order.UserID,
+		ProductID:         order.ProductID.String(),
+		VariantID:         uuidString(order.VariantID),
+		Quantity:          order.Quantity,
+		CancelledQuantity: order.CancelledQuantity,
+		ShippedQuantity:   order.ShippedQuantity,
+		CurrentStatus:     string(order.CurrentStatus),
+		Metadata:          metadata,
+		CheckoutID:        uuidString(order.CheckoutID),
+		SKU:               order.SKU,
+		ProductName:       order.ProductName,
+		UnitPrice:         order.UnitPrice,
+		Subtotal:          order.Subtotal,
+		Discount:          order.Discount,
+		Shipping:          order.Shipping,
+		Tax:               order.Tax,
+		Total:             order.Total,
+		PriceEstimated:    order.PriceEstimated,
+		Discounts:         toOrderDiscountResponses(order.Discounts),
+		TaxIncluded:       order.TaxIncluded,
+		TaxLines:          toOrderTaxLineResponses(order.TaxLines),
+		ShippingOption:    toOrderShippingResponse(order),
+		CreatedAt:         order.CreatedAt,
+		UpdatedAt:         order.UpdatedAt,
+	}
+}
+
+// toOrderShippingResponse returns the shipping option an order is charged for, or nil when it has none
+func toOrderShippingResponse(order *model.Order) *types.OrderShippingResponse {
+	if order.ShippingOption == "" {
+		return nil
+	}
+	return &types.OrderShippingResponse{
+		Code:         order.ShippingOption,
+		Name:         order.ShippingOptionName,
+		Carrier:      order.ShippingCarrier,
+		ServiceLevel: order.ShippingServiceLevel,
+	}
+}
+
+// toOrderDiscountResponses converts an order's discount lines to their API representation
+func toOrderDiscountResponses(discounts []model.OrderDiscount) []types.OrderDiscountResponse {
+	responses := make([]types.OrderDiscountResponse, len(discounts))
+	for i, discount := range discounts {
+		responses[i] = types.OrderDiscountResponse{
+			PromotionID:  discount.PromotionID.String(),
+			Code:         discount.Code,
+			Description:  discount.Description,
+			Amount:       discount.Amount,
+			FreeShipping: discount.FreeShipping,
+		}
+	}
+	return responses
+}
+
+// toOrderTaxLineResponses converts an order's tax lines to their API representation
+func toOrderTaxLineResponses(lines []model.OrderTaxLine) []types.OrderTaxLineResponse {
+	responses := make([]types.OrderTaxLineResponse, len(lines))
+	for i, line := range lines {
+		responses[i] = types.OrderTaxLineResponse{
+			Name:      line.Name,
+			TaxClass:  line.TaxClass,
+			Rate:      line.Rate,
+			Inclusive: line.Inclusive,
+			Taxable:   line.Taxable,
+			Amount:    line.Amount,
+		}
+	}
+	return responses
+}
@@ -0,0 +1,143 @@
+package controllers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"oms/server/api/v1/helpers"
+	"oms/server/api/v1/types"
+	"oms/server/core/model"
+	"oms/server/core/services"
+)
+
+// PaymentSignatureHeader carries the provider's signature of a webhook body
+const PaymentSignatureHeader = "X-Payment-Signature"
+
+// maxWebhookBytes bounds the size of a webhook body
+const maxWebhookBytes = 1 << 20
+
+// PaymentController handles order payment HTTP requests and provider webhooks
+type PaymentController struct {
+	orderService   services.OrderService
+	paymentService services.PaymentService
+}
+
+// NewPaymentController creates a new PaymentController
+func NewPaymentController(orderService services.OrderService, paymentService services.PaymentService) *PaymentController {
+	return &PaymentController{
+		orderService:   orderService,
+		paymentService: paymentService,
+	}
+}
+
+// GetOrderPayments handles GET /api/v1/orders/{orderId}/payments - An order's payments with their history, newest first
+func (pc *PaymentController) GetOrderPayments(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID := getUserIDFromContext(ctx)
+	if userID == 0 {
+		helpers.WriteErrorResponse(w, http.StatusUnauthorized, "unauthorized", "User ID not found in context")
+		return
+	}
+
+	orderID, err := uuid.Parse(mux.Vars(r)["orderId"])
+	if err != nil {
+		helpers.WriteErrorResponse(w, http.StatusBadRequest, "invalid_request", "Invalid order ID format")
+		return
+	}
+
+	// Verify order belongs to user (admin can access any order)
+	order, err := pc.orderService.GetOrderByID(ctx, orderID)
+	if err != nil {
+		helpers.WriteErrorResponse(w, http.StatusNotFound, "not_found", "Order not found")
+		return
+	}
+	if getUserRoleFromContext(ctx) != "admin" && order.UserID != userID {
+		helpers.WriteErrorResponse(w, http.StatusForbidden, "forbidden", "You don't have access to this order")
+		return
+	}
+
+	payments, err := pc.paymentService.GetPayments(ctx, orderID)
+	if err != nil {
+		helpers.WriteErrorResponse(w, http.StatusInternalServerError, "internal_error", "Failed to fetch payments")
+		return
+	}
+
+	responses := make([]types.PaymentResponse, len(payments))
+	for i, payment := range payments {
+		responses[i] = toPaymentResponse(payment)
+	}
+	helpers.WriteJSONResponse(w, http.StatusOK, responses)
+}
+
+// Webhook handles POST /api/v1/payments/webhook - Payment provider notifications (no JWT; verified by signature)
+// Redelivered events are acknowledged without being applied again.
+func (pc *PaymentController) Webhook(w http.ResponseWriter, r *http.Request) {
+	payload, err := io.ReadAll(io.LimitReader(r.Body, maxWebhookBytes))
+	if err != nil {
+		helpers.WriteErrorResponse(w, http.StatusBadRequest, "invalid_request", "Failed to read request body")
+		return
+	}
+
+	if err := pc.orderService.HandlePaymentWebhook(r.Context(), payload, r.Header.Get(PaymentSignatureHeader)); err != nil {
+		if !writePaymentError(w, err) {
+			helpers.WriteErrorResponse(w, http.StatusInternalServerError, "internal_error", "Failed to process webhook: "+err.Error())
+		}
+		return
+	}
+	helpers.WriteJSONResponse(w, http.StatusOK, map[string]string{
+		"message": "Webhook processed",
+	})
+}
+
+// writePaymentError maps payment service errors to HTTP responses and reports whether err was one
+func writePaymentError(w http.ResponseWriter, err error) bool {
+	switch {
+	case errors.Is(err, services.ErrPaymentDeclined):
+		helpers.WriteErrorResponse(w, http.StatusPaymentRequired, "payment_declined", err.Error())
+	case errors.Is(err, services.ErrPaymentNotAuthorized):
+		helpers.WriteErrorResponse(w, http.StatusConflict, "payment_not_authorized", err.Error())
+	case errors.Is(err, services.ErrPaymentFailed):
+		helpers.WriteErrorResponse(w, http.StatusBadGateway, "payment_failed", err.Error())
+	case errors.Is(err, services.ErrInvalidWebhook):
+		helpers.WriteErrorResponse(w, http.StatusBadRequest, "invalid_webhook", err.Error())
+	case errors.Is(err, services.ErrPaymentNotFound):
+		helpers.WriteErrorResponse(w, http.StatusNotFound, "not_found", err.Error())
+	default:
+		return false
+	}
+	return true
+}
+
+// toPaymentResponse converts a payment and its history to its API representation
+func toPaymentResponse(payment *model.Payment) types.PaymentResponse {
+	response := types.PaymentResponse{
+		ID:            payment.ID.String(),
+		OrderID:       payment.OrderID.String(),
+		Provider:      payment.Provider,
+		Status:        string(payment.Status),
+		Amount:        payment.Amount,
+		Captured:      payment.Captured,
+		Refunded:      payment.Refunded,
+		FailureReason: payment.FailureReason,
+		History:       make([]types.PaymentHistoryResponse, len(payment.History)),
+		CreatedAt:     payment.CreatedAt,
+		UpdatedAt:     payment.UpdatedAt,
+	}
+	if payment.Reference != nil {
+		response.Reference = *payment.Reference
+	}
+	for i, entry := range payment.History {
+		response.History[i] = types.PaymentHistoryResponse{
+			PreviousStatus: string(entry.PreviousStatus),
+			NewStatus:      string(entry.NewStatus),
+			Amount:         entry.Amount,
+			Message:        entry.Message,
+			CreatedAt:      entry.CreatedAt,
+		}
+	}
+	return response
+}
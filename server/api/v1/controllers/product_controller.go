@@ -1,14 +1,23 @@
 package controllers
 
 import (
+	"errors"
 	"net/http"
+	"strconv"
+	"strings"
 
 	"github.com/google/uuid"
 	"github.com/gorilla/mux"
 	"oms/server/api/v1/helpers"
+	"oms/server/api/v1/types"
+	"oms/server/core/model"
+	"oms/server/core/money"
 	"oms/server/core/services"
 )
 
+// NextCursorHeader carries the cursor for the next catalog page; absent on the last page
+const NextCursorHeader = "X-Next-Cursor"
+
 // ProductController handles product-related HTTP requests
 type ProductController struct {
 	productService services.ProductService
@@ -22,24 +31,47 @@ func NewProductController(productService services.ProductService) *ProductContro
 }
 
 // GetProducts handles GET /api/v1/products
+// Query parameters:
+//   - q: text search on name and SKU
+//   - min_price, max_price: inclusive price range, e.g. 25 or "25 EUR"; a bound limits results to its currency
+//   - stock: in_stock or out_of_stock
+//   - meta.<key>=<value>: metadata filter (an empty value only requires the key)
+//   - category: category slug or ID, including its subcategories
+//   - sort: created_at (default), name, price or stock; order: asc or desc
+//   - limit, cursor: page size and the cursor from the previous page's X-Next-Cursor header
 func (pc *ProductController) GetProducts(w http.ResponseWriter, r *http.Request) {
-	_ = r.Context() // TODO: Use context for logging/request tracking
-
-	// TODO: Call productService.GetAll(ctx)
-	// ctx := r.Context()
-	// products, err := pc.productService.GetAll(ctx)
-	// if err != nil {
-	//     helpers.WriteErrorResponse(w, http.StatusInternalServerError, "internal_error", "Failed to fetch products")
-	//     return
-	// }
-
-	// Placeholder response - return empty array for now
-	helpers.WriteJSONResponse(w, http.StatusOK, []interface{}{})
+	ctx := r.Context()
+
+	query, err := parseProductQuery(r)
+	if err != nil {
+		helpers.WriteErrorResponse(w, http.StatusBadRequest, "invalid_request", err.Error())
+		return
+	}
+
+	page, err := pc.productService.Search(ctx, query)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidProductQuery) {
+			helpers.WriteErrorResponse(w, http.StatusBadRequest, "invalid_request", err.Error())
+			return
+		}
+		helpers.WriteErrorResponse(w, http.StatusInternalServerError, "internal_error", "Failed to fetch products")
+		return
+	}
+
+	productResponses := make([]types.ProductResponse, len(page.Products))
+	for i, product := range page.Products {
+		productResponses[i] = toProductResponse(product)
+	}
+
+	if page.NextCursor != "" {
+		w.Header().Set(NextCursorHeader, page.NextCursor)
+	}
+	helpers.WriteJSONResponse(w, http.StatusOK, productResponses)
 }
 
 // GetProduct handles GET /api/v1/products/{productId}
 func (pc *ProductController) GetProduct(w http.ResponseWriter, r *http.Request) {
-	_ = r.Context() // TODO: Use context for logging/request tracking
+	ctx := r.Context()
 	vars := mux.Vars(r)
 	productIDStr := vars["productId"]
 
@@ -49,18 +81,124 @@ func (pc *ProductController) GetProduct(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	// TODO: Call productService.GetByID(ctx, productID)
-	// ctx := r.Context()
-	// product, err := pc.productService.GetByID(ctx, productID)
-	// if err != nil {
-	//     helpers.WriteErrorResponse(w, http.StatusNotFound, "not_found", "Product not found")
-	//     return
-	// }
+	product, err := pc.productService.GetWithStock(ctx, productID)
+	if err != nil {
+		helpers.WriteErrorResponse(w, http.StatusNotFound, "not_found", "Product not found")
+		return
+	}
 
-	// Placeholder response
-	helpers.WriteJSONResponse(w, http.StatusOK, map[string]interface{}{
-		"id":   productID,
-		"name": "Placeholder Product",
-	})
+	helpers.WriteJSONResponse(w, http.StatusOK, toProductResponse(product))
 }
 
+// parseProductQuery builds a catalog query from URL parameters
+func parseProductQuery(r *http.Request) (model.ProductQuery, error) {
+	params := r.URL.Query()
+	query := model.ProductQuery{
+		Search:      strings.TrimSpace(params.Get("q")),
+		Category:    strings.TrimSpace(params.Get("category")),
+		StockStatus: model.StockStatus(params.Get("stock")),
+		SortBy:      model.ProductSortField(params.Get("sort")),
+		Cursor:      params.Get("cursor"),
+		Metadata:    map[string]string{},
+	}
+
+	switch params.Get("order") {
+	case "":
+		// Newest first unless asked otherwise
+		query.SortDesc = query.SortBy == "" || query.SortBy == model.ProductSortCreatedAt
+	case "asc":
+	case "desc":
+		query.SortDesc = true
+	default:
+		return query, errors.New("order must be asc or desc")
+	}
+
+	var err error
+	if query.MinPrice, err = parsePriceParam(params.Get("min_price"), "min_price"); err != nil {
+		return query, err
+	}
+	if query.MaxPrice, err = parsePriceParam(params.Get("max_price"), "max_price"); err != nil {
+		return query, err
+	}
+
+	if value := params.Get("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit < 1 {
+			return query, errors.New("limit must be a positive integer")
+		}
+		query.Limit = limit
+	}
+
+	for name, values := range params {
+		if key, ok := strings.CutPrefix(name, "meta."); ok && key != "" {
+			query.Metadata[key] = values[0]
+		}
+	}
+
+	return query, nil
+}
+
+// parsePriceParam parses an optional non-negative price filter such as "25" or "25 EUR";
+// without a currency the default currency applies
+func parsePriceParam(value, name string) (*money.Money, error) {
+	if value == "" {
+		return nil, nil
+	}
+	price, err := money.Parse(value)
+	if err != nil || price.IsNegative() {
+		return nil, errors.New(name + " must be a non-negative amount, optionally followed by a currency code")
+	}
+	return &price, nil
+}
+
+// toProductResponse converts a product with stock to its API representation
+func toProductResponse(product *model.ProductWithStock) types.ProductResponse {
+	// Handle metadata conversion safely
+	metadata := map[string]interface{}{}
+	if product.Metadata != nil {
+		metadata = map[string]interface{}(product.Metadata)
+	}
+	quantity := product.Quantity
+
+	response := types.ProductResponse{
+		ID:        product.ID.String(),
+		SKU:       product.SKU,
+		Name:      product.Name,
+		Price:     product.Price,
+		Inventory: &quantity,
+		Metadata:  metadata,
+		TaxClass:  product.TaxClass,
+		Options:   toProductOptionResponses(product.Options),
+		CreatedAt: product.CreatedAt,
+		UpdatedAt: product.UpdatedAt,
+	}
+	for _, variant := range product.Variants {
+		variantQuantity := variant.Quantity
+		response.Variants = append(response.Variants, toVariantResponse(&variant.ProductVariant, product.Price, &variantQuantity))
+	}
+	return response
+}
+
+// toProductOptionResponses converts a product's option axes to their API representation
+func toProductOptionResponses(options model.ProductOptions) []types.ProductOptionResponse {
+	if len(options) == 0 {
+		return nil
+	}
+	responses := make([]types.ProductOptionResponse, len(options))
+	for i, option := range options {
+		responses[i] = types.ProductOptionResponse{Name: option.Name, Values: option.Values}
+	}
+	return responses
+}
+
+// toVariantResponse converts a variant to its API representation, resolving its price against productPrice
+func toVariantResponse(variant *model.ProductVariant, productPrice money.Money, quantity *int) types.ProductVariantResponse {
+	return types.ProductVariantResponse{
+		ID:            variant.ID.String(),
+		SKU:           variant.SKU,
+		Options:       map[string]string(variant.Options),
+		Price:         variant.EffectivePrice(productPrice),
+		PriceOverride: variant.Price != nil,
+		Inventory:     quantity,
+	}
+}
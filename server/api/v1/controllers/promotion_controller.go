@@ -0,0 +1,244 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"oms/server/api/v1/helpers"
+	"oms/server/api/v1/types"
+	"oms/server/core/model"
+	"oms/server/core/services"
+)
+
+// PromotionController handles admin management of coupons and automatic promotions
+type PromotionController struct {
+	promotionService services.PromotionService
+}
+
+// NewPromotionController creates a new PromotionController
+func NewPromotionController(promotionService services.PromotionService) *PromotionController {
+	return &PromotionController{
+		promotionService: promotionService,
+	}
+}
+
+// GetPromotions handles GET /api/v1/admin/promotions - List promotions in evaluation order (admin only)
+func (pc *PromotionController) GetPromotions(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	// Verify admin role
+	role := getUserRoleFromContext(ctx)
+	if role != "admin" {
+		helpers.WriteErrorResponse(w, http.StatusForbidden, "forbidden", "Admin access required")
+		return
+	}
+
+	promotions, err := pc.promotionService.ListPromotions(ctx)
+	if err != nil {
+		helpers.WriteErrorResponse(w, http.StatusInternalServerError, "internal_error", "Failed to fetch promotions")
+		return
+	}
+
+	responses := make([]types.PromotionResponse, len(promotions))
+	for i, promotion := range promotions {
+		responses[i] = toPromotionResponse(promotion)
+	}
+	helpers.WriteJSONResponse(w, http.StatusOK, responses)
+}
+
+// CreatePromotion handles POST /api/v1/admin/promotions - Create a coupon or automatic promotion (admin only)
+func (pc *PromotionController) CreatePromotion(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	// Verify admin role
+	role := getUserRoleFromContext(ctx)
+	if role != "admin" {
+		helpers.WriteErrorResponse(w, http.StatusForbidden, "forbidden", "Admin access required")
+		return
+	}
+
+	var req types.PromotionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeDecodeError(w, err)
+		return
+	}
+
+	promotion, ok := promotionFromRequest(w, req)
+	if !ok {
+		return
+	}
+	if err := pc.promotionService.CreatePromotion(ctx, promotion); err != nil {
+		writePromotionError(w, err)
+		return
+	}
+
+	helpers.WriteJSONResponse(w, http.StatusCreated, toPromotionResponse(promotion))
+}
+
+// UpdatePromotion handles PUT /api/v1/admin/promotions/{promotionId} - Replace a promotion's rule (admin only)
+// The redemption count is kept.
+func (pc *PromotionController) UpdatePromotion(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	// Verify admin role
+	role := getUserRoleFromContext(ctx)
+	if role != "admin" {
+		helpers.WriteErrorResponse(w, http.StatusForbidden, "forbidden", "Admin access required")
+		return
+	}
+
+	promotionID, err := uuid.Parse(mux.Vars(r)["promotionId"])
+	if err != nil {
+		helpers.WriteErrorResponse(w, http.StatusBadRequest, "invalid_request", "Invalid promotion ID format")
+		return
+	}
+
+	var req types.PromotionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeDecodeError(w, err)
+		return
+	}
+
+	promotion, ok := promotionFromRequest(w, req)
+	if !ok {
+		return
+	}
+	promotion.ID = promotionID
+	if err := pc.promotionService.UpdatePromotion(ctx, promotion); err != nil {
+		writePromotionError(w, err)
+		return
+	}
+
+	helpers.WriteJSONResponse(w, http.StatusOK, toPromotionResponse(promotion))
+}
+
+// DeletePromotion handles DELETE /api/v1/admin/promotions/{promotionId} - Remove a promotion (admin only)
+// Discount lines already recorded on orders are kept.
+func (pc *PromotionController) DeletePromotion(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	// Verify admin role
+	role := getUserRoleFromContext(ctx)
+	if role != "admin" {
+		helpers.WriteErrorResponse(w, http.StatusForbidden, "forbidden", "Admin access required")
+		return
+	}
+
+	promotionID, err := uuid.Parse(mux.Vars(r)["promotionId"])
+	if err != nil {
+		helpers.WriteErrorResponse(w, http.StatusBadRequest, "invalid_request", "Invalid promotion ID format")
+		return
+	}
+
+	if err := pc.promotionService.DeletePromotion(ctx, promotionID); err != nil {
+		writePromotionError(w, err)
+		return
+	}
+
+	helpers.WriteJSONResponse(w, http.StatusOK, map[string]string{
+		"message":      "Promotion deleted successfully",
+		"promotion_id": promotionID.String(),
+	})
+}
+
+// promotionFromRequest converts a promotion request to a model, writing a 400 response when an ID is malformed
+func promotionFromRequest(w http.ResponseWriter, req types.PromotionRequest) (*model.Promotion, bool) {
+	promotion := &model.Promotion{
+		Name:         req.Name,
+		Type:         model.PromotionType(req.Type),
+		Active:       req.Active == nil || *req.Active,
+		Priority:     req.Priority,
+		PercentOff:   req.PercentOff,
+		BuyQuantity:  req.BuyQuantity,
+		GetQuantity:  req.GetQuantity,
+		UsageLimit:   req.UsageLimit,
+		PerUserLimit: req.PerUserLimit,
+		StartsAt:     req.StartsAt,
+		EndsAt:       req.EndsAt,
+	}
+	if req.Code != "" {
+		code := req.Code
+		promotion.Code = &code
+	}
+	if req.AmountOff != nil {
+		promotion.AmountOff = *req.AmountOff
+	}
+	if req.MinSubtotal != nil {
+		promotion.MinSubtotal = *req.MinSubtotal
+	}
+
+	var ok bool
+	if promotion.ProductIDs, ok = parseUUIDList(w, req.ProductIDs, "Invalid product ID format"); !ok {
+		return nil, false
+	}
+	if promotion.CategoryIDs, ok = parseUUIDList(w, req.CategoryIDs, "Invalid category ID format"); !ok {
+		return nil, false
+	}
+	return promotion, true
+}
+
+// parseUUIDList parses a list of UUID strings, writing a 400 response with message when one is malformed
+func parseUUIDList(w http.ResponseWriter, values []string, message string) (model.UUIDList, bool) {
+	ids := model.UUIDList{}
+	for _, value := range values {
+		id, err := uuid.Parse(value)
+		if err != nil {
+			helpers.WriteErrorResponse(w, http.StatusBadRequest, "invalid_request", message)
+			return nil, false
+		}
+		if !ids.Contains(id) {
+			ids = append(ids, id)
+		}
+	}
+	return ids, true
+}
+
+// writePromotionError maps promotion service errors to HTTP responses
+func writePromotionError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, services.ErrPromotionNotFound):
+		helpers.WriteErrorResponse(w, http.StatusNotFound, "not_found", err.Error())
+	case errors.Is(err, services.ErrInvalidPromotion):
+		helpers.WriteErrorResponse(w, http.StatusBadRequest, "invalid_request", err.Error())
+	default:
+		helpers.WriteErrorResponse(w, http.StatusInternalServerError, "internal_error", err.Error())
+	}
+}
+
+// toPromotionResponse converts a promotion to its API representation
+func toPromotionResponse(promotion *model.Promotion) types.PromotionResponse {
+	response := types.PromotionResponse{
+		ID:              promotion.ID.String(),
+		Name:            promotion.Name,
+		Type:            string(promotion.Type),
+		Active:          promotion.Active,
+		Priority:        promotion.Priority,
+		PercentOff:      promotion.PercentOff,
+		AmountOff:       promotion.AmountOff,
+		BuyQuantity:     promotion.BuyQuantity,
+		GetQuantity:     promotion.GetQuantity,
+		MinSubtotal:     promotion.MinSubtotal,
+		ProductIDs:      make([]string, len(promotion.ProductIDs)),
+		CategoryIDs:     make([]string, len(promotion.CategoryIDs)),
+		UsageLimit:      promotion.UsageLimit,
+		PerUserLimit:    promotion.PerUserLimit,
+		RedemptionCount: promotion.Redemptions,
+		StartsAt:        promotion.StartsAt,
+		EndsAt:          promotion.EndsAt,
+		CreatedAt:       promotion.CreatedAt,
+		UpdatedAt:       promotion.UpdatedAt,
+	}
+	if promotion.Code != nil {
+		response.Code = *promotion.Code
+	}
+	for i, id := range promotion.ProductIDs {
+		response.ProductIDs[i] = id.String()
+	}
+	for i, id := range promotion.CategoryIDs {
+		response.CategoryIDs[i] = id.String()
+	}
+	return response
+}
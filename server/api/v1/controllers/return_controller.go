@@ -0,0 +1,197 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"oms/server/api/v1/helpers"
+	"oms/server/api/v1/types"
+	"oms/server/core/model"
+	"oms/server/core/services"
+)
+
+// ReturnController handles return (RMA) HTTP requests for delivered orders
+type ReturnController struct {
+	orderService  services.OrderService
+	returnService services.ReturnService
+}
+
+// NewReturnController creates a new ReturnController
+func NewReturnController(orderService services.OrderService, returnService services.ReturnService) *ReturnController {
+	return &ReturnController{
+		orderService:  orderService,
+		returnService: returnService,
+	}
+}
+
+// CreateReturn handles POST /api/v1/orders/{orderId}/returns - Request a return of a delivered order (owner only)
+func (rc *ReturnController) CreateReturn(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID := getUserIDFromContext(ctx)
+	if userID == 0 {
+		helpers.WriteErrorResponse(w, http.StatusUnauthorized, "unauthorized", "User ID not found in context")
+		return
+	}
+
+	orderID, err := uuid.Parse(mux.Vars(r)["orderId"])
+	if err != nil {
+		helpers.WriteErrorResponse(w, http.StatusBadRequest, "invalid_request", "Invalid order ID format")
+		return
+	}
+
+	// Only the customer who placed the order can return it
+	order, err := rc.orderService.GetOrderByID(ctx, orderID)
+	if err != nil {
+		helpers.WriteErrorResponse(w, http.StatusNotFound, "not_found", "Order not found")
+		return
+	}
+	if order.UserID != userID {
+		helpers.WriteErrorResponse(w, http.StatusForbidden, "forbidden", "You don't have access to this order")
+		return
+	}
+
+	var req types.CreateReturnRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		helpers.WriteErrorResponse(w, http.StatusBadRequest, "invalid_request", "Invalid request body")
+		return
+	}
+
+	request, err := rc.returnService.RequestReturn(ctx, orderID, userID, req.Quantity, model.ReturnReason(req.Reason), req.Note)
+	if err != nil {
+		writeReturnError(w, err, "Failed to request return: ")
+		return
+	}
+	helpers.WriteJSONResponse(w, http.StatusCreated, toReturnResponse(request))
+}
+
+// GetReturns handles GET /api/v1/orders/{orderId}/returns - An order's returns, oldest first (owner or admin)
+func (rc *ReturnController) GetReturns(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID := getUserIDFromContext(ctx)
+	if userID == 0 {
+		helpers.WriteErrorResponse(w, http.StatusUnauthorized, "unauthorized", "User ID not found in context")
+		return
+	}
+
+	orderID, err := uuid.Parse(mux.Vars(r)["orderId"])
+	if err != nil {
+		helpers.WriteErrorResponse(w, http.StatusBadRequest, "invalid_request", "Invalid order ID format")
+		return
+	}
+
+	// Verify order belongs to user (admin can access any order)
+	order, err := rc.orderService.GetOrderByID(ctx, orderID)
+	if err != nil {
+		helpers.WriteErrorResponse(w, http.StatusNotFound, "not_found", "Order not found")
+		return
+	}
+	if getUserRoleFromContext(ctx) != "admin" && order.UserID != userID {
+		helpers.WriteErrorResponse(w, http.StatusForbidden, "forbidden", "You don't have access to this order")
+		return
+	}
+
+	requests, err := rc.returnService.GetReturns(ctx, orderID)
+	if err != nil {
+		helpers.WriteErrorResponse(w, http.StatusInternalServerError, "internal_error", "Failed to fetch returns")
+		return
+	}
+
+	responses := make([]types.ReturnResponse, len(requests))
+	for i, request := range requests {
+		responses[i] = toReturnResponse(request)
+	}
+	helpers.WriteJSONResponse(w, http.StatusOK, responses)
+}
+
+// UpdateReturn handles PATCH /api/v1/orders/{orderId}/returns/{returnId} - Approve, reject, receive or refund a return (admin only)
+func (rc *ReturnController) UpdateReturn(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	if getUserRoleFromContext(ctx) != "admin" {
+		helpers.WriteErrorResponse(w, http.StatusForbidden, "forbidden", "Admin access required")
+		return
+	}
+	adminID := getUserIDFromContext(ctx)
+
+	vars := mux.Vars(r)
+	orderID, err := uuid.Parse(vars["orderId"])
+	if err != nil {
+		helpers.WriteErrorResponse(w, http.StatusBadRequest, "invalid_request", "Invalid order ID format")
+		return
+	}
+	returnID, err := uuid.Parse(vars["returnId"])
+	if err != nil {
+		helpers.WriteErrorResponse(w, http.StatusBadRequest, "invalid_request", "Invalid return ID format")
+		return
+	}
+
+	var req types.UpdateReturnRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		helpers.WriteErrorResponse(w, http.StatusBadRequest, "invalid_request", "Invalid request body")
+		return
+	}
+
+	var request *model.ReturnRequest
+	switch model.ReturnStatus(req.Status) {
+	case model.ReturnStatusApproved:
+		request, err = rc.returnService.Approve(ctx, orderID, returnID, adminID, req.Note)
+	case model.ReturnStatusRejected:
+		request, err = rc.returnService.Reject(ctx, orderID, returnID, adminID, req.Note)
+	case model.ReturnStatusReceived:
+		request, err = rc.returnService.Receive(ctx, orderID, returnID, adminID, req.RestockedQuantity, req.DamagedQuantity, req.Note)
+	case model.ReturnStatusRefunded:
+		request, err = rc.returnService.Refund(ctx, orderID, returnID, adminID)
+	default:
+		helpers.WriteErrorResponse(w, http.StatusBadRequest, "invalid_request", "Status must be approved, rejected, received or refunded")
+		return
+	}
+	if err != nil {
+		writeReturnError(w, err, "Failed to update return: ")
+		return
+	}
+	helpers.WriteJSONResponse(w, http.StatusOK, toReturnResponse(request))
+}
+
+// writeReturnError maps return service errors to HTTP responses; prefix describes the failed action.
+// A failed refund leaves the return received and is reported as a payment error.
+func writeReturnError(w http.ResponseWriter, err error, prefix string) {
+	errMsg := err.Error()
+	switch {
+	case errors.Is(err, services.ErrInvalidReturn):
+		helpers.WriteErrorResponse(w, http.StatusBadRequest, "invalid_request", errMsg)
+	case errors.Is(err, services.ErrReturnNotAllowed):
+		helpers.WriteErrorResponse(w, http.StatusConflict, "return_not_allowed", errMsg)
+	case errors.Is(err, services.ErrInvalidReturnTransition):
+		helpers.WriteErrorResponse(w, http.StatusConflict, "invalid_transition", errMsg)
+	case errors.Is(err, services.ErrReturnNotFound):
+		helpers.WriteErrorResponse(w, http.StatusNotFound, "not_found", errMsg)
+	case writePaymentError(w, err):
+	default:
+		helpers.WriteErrorResponse(w, http.StatusInternalServerError, "internal_error", prefix+errMsg)
+	}
+}
+
+// toReturnResponse converts a return request to its API representation
+func toReturnResponse(request *model.ReturnRequest) types.ReturnResponse {
+	return types.ReturnResponse{
+		ID:                request.ID.String(),
+		OrderID:           request.OrderID.String(),
+		UserID:            request.UserID,
+		Quantity:          request.Quantity,
+		Reason:            string(request.Reason),
+		Note:              request.Note,
+		Status:            string(request.Status),
+		RestockedQuantity: request.RestockedQuantity,
+		DamagedQuantity:   request.DamagedQuantity,
+		RefundAmount:      request.RefundAmount,
+		ResolutionNote:    request.ResolutionNote,
+		HandledBy:         request.HandledBy,
+		CreatedAt:         request.CreatedAt,
+		UpdatedAt:         request.UpdatedAt,
+	}
+}
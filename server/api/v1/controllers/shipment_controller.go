@@ -0,0 +1,182 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"oms/server/api/v1/helpers"
+	"oms/server/api/v1/types"
+	"oms/server/core/model"
+	"oms/server/core/services"
+)
+
+// TrackingSignatureHeader carries the signature of a tracking event body
+const TrackingSignatureHeader = "X-Tracking-Signature"
+
+// ShipmentController handles HTTP requests for the shipments an order is sent in and their tracking
+type ShipmentController struct {
+	orderService    services.OrderService
+	trackingService services.TrackingService
+}
+
+// NewShipmentController creates a new ShipmentController; a nil trackingService disables tracking events
+func NewShipmentController(orderService services.OrderService, trackingService services.TrackingService) *ShipmentController {
+	return &ShipmentController{
+		orderService:    orderService,
+		trackingService: trackingService,
+	}
+}
+
+// CreateShipment handles POST /api/v1/orders/{orderId}/shipments - Ship part or all of an order's open units (admin only)
+func (sc *ShipmentController) CreateShipment(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	if getUserRoleFromContext(ctx) != "admin" {
+		helpers.WriteErrorResponse(w, http.StatusForbidden, "forbidden", "Admin access required")
+		return
+	}
+	adminID := getUserIDFromContext(ctx)
+
+	orderID, err := uuid.Parse(mux.Vars(r)["orderId"])
+	if err != nil {
+		helpers.WriteErrorResponse(w, http.StatusBadRequest, "invalid_request", "Invalid order ID format")
+		return
+	}
+
+	var req types.CreateShipmentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		helpers.WriteErrorResponse(w, http.StatusBadRequest, "invalid_request", "Invalid request body")
+		return
+	}
+	if req.Quantity <= 0 {
+		helpers.WriteErrorResponse(w, http.StatusBadRequest, "invalid_request", "Quantity must be greater than 0")
+		return
+	}
+
+	order, err := sc.orderService.GetOrderByID(ctx, orderID)
+	if err != nil {
+		helpers.WriteErrorResponse(w, http.StatusNotFound, "not_found", "Order not found")
+		return
+	}
+
+	shipment, err := sc.orderService.ShipOrder(ctx, order.ID, toShipment(req.Quantity, req.ShipmentDetails), adminID)
+	if err != nil {
+		writeFulfillmentError(w, err, "Failed to ship order: ")
+		return
+	}
+	helpers.WriteJSONResponse(w, http.StatusCreated, toShipmentResponse(shipment))
+}
+
+// GetShipments handles GET /api/v1/orders/{orderId}/shipments - An order's shipments, oldest first (owner or admin)
+func (sc *ShipmentController) GetShipments(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID := getUserIDFromContext(ctx)
+	if userID == 0 {
+		helpers.WriteErrorResponse(w, http.StatusUnauthorized, "unauthorized", "User ID not found in context")
+		return
+	}
+
+	orderID, err := uuid.Parse(mux.Vars(r)["orderId"])
+	if err != nil {
+		helpers.WriteErrorResponse(w, http.StatusBadRequest, "invalid_request", "Invalid order ID format")
+		return
+	}
+
+	// Verify order belongs to user (admin can access any order)
+	order, err := sc.orderService.GetOrderByID(ctx, orderID)
+	if err != nil {
+		helpers.WriteErrorResponse(w, http.StatusNotFound, "not_found", "Order not found")
+		return
+	}
+	if getUserRoleFromContext(ctx) != "admin" && order.UserID != userID {
+		helpers.WriteErrorResponse(w, http.StatusForbidden, "forbidden", "You don't have access to this order")
+		return
+	}
+
+	shipments, err := sc.orderService.GetShipments(ctx, orderID)
+	if err != nil {
+		helpers.WriteErrorResponse(w, http.StatusInternalServerError, "internal_error", "Failed to fetch shipments")
+		return
+	}
+
+	responses := make([]types.ShipmentResponse, len(shipments))
+	for i, shipment := range shipments {
+		responses[i] = toShipmentResponse(shipment)
+	}
+	helpers.WriteJSONResponse(w, http.StatusOK, responses)
+}
+
+// TrackEvent handles POST /api/v1/shipments/tracking - Carrier tracking events (no JWT; verified by signature)
+// Redelivered events are acknowledged without being applied again.
+func (sc *ShipmentController) TrackEvent(w http.ResponseWriter, r *http.Request) {
+	payload, err := io.ReadAll(io.LimitReader(r.Body, maxWebhookBytes))
+	if err != nil {
+		helpers.WriteErrorResponse(w, http.StatusBadRequest, "invalid_request", "Failed to read request body")
+		return
+	}
+
+	shipment, err := sc.trackingService.HandleEvent(r.Context(), payload, r.Header.Get(TrackingSignatureHeader))
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrInvalidTrackingEvent):
+			helpers.WriteErrorResponse(w, http.StatusBadRequest, "invalid_tracking_event", err.Error())
+		case errors.Is(err, services.ErrShipmentNotFound):
+			helpers.WriteErrorResponse(w, http.StatusNotFound, "not_found", err.Error())
+		default:
+			helpers.WriteErrorResponse(w, http.StatusInternalServerError, "internal_error", "Failed to process tracking event: "+err.Error())
+		}
+		return
+	}
+	helpers.WriteJSONResponse(w, http.StatusOK, map[string]string{
+		"message": "Tracking event processed",
+		"status":  string(shipment.Status),
+	})
+}
+
+// toShipment builds the shipment of quantity units described by details
+func toShipment(quantity int, details types.ShipmentDetails) *model.Shipment {
+	shipment := &model.Shipment{
+		Quantity:       quantity,
+		Carrier:        details.Carrier,
+		ServiceLevel:   details.ServiceLevel,
+		TrackingNumber: details.TrackingNumber,
+		LabelReference: details.LabelReference,
+	}
+	if details.ShippedAt != nil {
+		shipment.ShippedAt = *details.ShippedAt
+	}
+	return shipment
+}
+
+// toShipmentResponse converts a shipment and its tracking events to its API representation
+func toShipmentResponse(shipment *model.Shipment) types.ShipmentResponse {
+	events := make([]types.TrackingEventResponse, len(shipment.Events))
+	for i, event := range shipment.Events {
+		events[i] = types.TrackingEventResponse{
+			Status:      string(event.Status),
+			Description: event.Description,
+			Location:    event.Location,
+			OccurredAt:  event.OccurredAt,
+		}
+	}
+	return types.ShipmentResponse{
+		ID:             shipment.ID.String(),
+		OrderID:        shipment.OrderID.String(),
+		Quantity:       shipment.Quantity,
+		Carrier:        shipment.Carrier,
+		ServiceLevel:   shipment.ServiceLevel,
+		TrackingNumber: shipment.TrackingNumber,
+		LabelReference: shipment.LabelReference,
+		Status:         string(shipment.Status),
+		ShippedBy:      shipment.ShippedBy,
+		ShippedAt:      shipment.ShippedAt,
+		DeliveredAt:    shipment.DeliveredAt,
+		Events:         events,
+		CreatedAt:      shipment.CreatedAt,
+	}
+}
@@ -0,0 +1,269 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"oms/server/api/v1/helpers"
+	"oms/server/api/v1/types"
+	"oms/server/core/model"
+	"oms/server/core/services"
+)
+
+// ShippingController handles shipping quotes and admin management of the shipping zones
+type ShippingController struct {
+	shippingService services.ShippingService
+	orderService    services.OrderService
+}
+
+// NewShippingController creates a new ShippingController
+func NewShippingController(shippingService services.ShippingService, orderService services.OrderService) *ShippingController {
+	return &ShippingController{
+		shippingService: shippingService,
+		orderService:    orderService,
+	}
+}
+
+// Quote handles POST /api/v1/shipping/quote - Quote the shipping options for items and a destination (public)
+func (sc *ShippingController) Quote(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var req types.ShippingQuoteRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		helpers.WriteErrorResponse(w, http.StatusBadRequest, "invalid_request", "Invalid request body")
+		return
+	}
+	if len(req.Items) == 0 {
+		helpers.WriteErrorResponse(w, http.StatusBadRequest, "invalid_request", "At least one item is required")
+		return
+	}
+
+	items := make([]model.OrderItem, len(req.Items))
+	for i, item := range req.Items {
+		if item.Quantity <= 0 {
+			helpers.WriteErrorResponse(w, http.StatusBadRequest, "invalid_request", "Quantity must be greater than 0")
+			return
+		}
+		productID, err := uuid.Parse(item.ProductID)
+		if err != nil {
+			helpers.WriteErrorResponse(w, http.StatusBadRequest, "invalid_request", "Invalid product ID format")
+			return
+		}
+		var variantID *uuid.UUID
+		if item.VariantID != "" {
+			parsed, err := uuid.Parse(item.VariantID)
+			if err != nil {
+				helpers.WriteErrorResponse(w, http.StatusBadRequest, "invalid_request", "Invalid variant ID format")
+				return
+			}
+			variantID = &parsed
+		}
+		items[i] = model.OrderItem{ProductID: productID, VariantID: variantID, Quantity: item.Quantity}
+	}
+	metadata := model.JSONB{}
+	if req.ShippingAddress != nil {
+		metadata = model.JSONB(req.ShippingAddress)
+	}
+
+	options, err := sc.orderService.QuoteShipping(ctx, items, metadata)
+	if err != nil {
+		writeOrderError(w, err, "Failed to quote shipping: ")
+		return
+	}
+
+	responses := make([]types.ShippingOptionResponse, len(options))
+	for i, option := range options {
+		responses[i] = types.ShippingOptionResponse{
+			Code:          option.Code,
+			Name:          option.Name,
+			Carrier:       option.Carrier,
+			ServiceLevel:  option.ServiceLevel,
+			Amount:        option.Amount,
+			EstimatedDays: option.EstimatedDays,
+		}
+	}
+	helpers.WriteJSONResponse(w, http.StatusOK, types.ShippingQuoteResponse{Options: responses})
+}
+
+// GetZones handles GET /api/v1/admin/shipping-zones - List shipping zones and their rates (admin only)
+func (sc *ShippingController) GetZones(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	// Verify admin role
+	role := getUserRoleFromContext(ctx)
+	if role != "admin" {
+		helpers.WriteErrorResponse(w, http.StatusForbidden, "forbidden", "Admin access required")
+		return
+	}
+
+	zones, err := sc.shippingService.ListZones(ctx)
+	if err != nil {
+		helpers.WriteErrorResponse(w, http.StatusInternalServerError, "internal_error", "Failed to fetch shipping zones")
+		return
+	}
+
+	responses := make([]types.ShippingZoneResponse, len(zones))
+	for i, zone := range zones {
+		responses[i] = toShippingZoneResponse(zone)
+	}
+	helpers.WriteJSONResponse(w, http.StatusOK, responses)
+}
+
+// CreateZone handles POST /api/v1/admin/shipping-zones - Create a shipping zone with its rates (admin only)
+func (sc *ShippingController) CreateZone(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	// Verify admin role
+	role := getUserRoleFromContext(ctx)
+	if role != "admin" {
+		helpers.WriteErrorResponse(w, http.StatusForbidden, "forbidden", "Admin access required")
+		return
+	}
+
+	var req types.ShippingZoneRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		helpers.WriteErrorResponse(w, http.StatusBadRequest, "invalid_request", "Invalid request body")
+		return
+	}
+
+	zone := shippingZoneFromRequest(req)
+	if err := sc.shippingService.CreateZone(ctx, zone); err != nil {
+		writeShippingZoneError(w, err)
+		return
+	}
+
+	helpers.WriteJSONResponse(w, http.StatusCreated, toShippingZoneResponse(zone))
+}
+
+// UpdateZone handles PUT /api/v1/admin/shipping-zones/{zoneId} - Replace a shipping zone and its rates (admin only)
+func (sc *ShippingController) UpdateZone(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	// Verify admin role
+	role := getUserRoleFromContext(ctx)
+	if role != "admin" {
+		helpers.WriteErrorResponse(w, http.StatusForbidden, "forbidden", "Admin access required")
+		return
+	}
+
+	zoneID, err := uuid.Parse(mux.Vars(r)["zoneId"])
+	if err != nil {
+		helpers.WriteErrorResponse(w, http.StatusBadRequest, "invalid_request", "Invalid shipping zone ID format")
+		return
+	}
+
+	var req types.ShippingZoneRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		helpers.WriteErrorResponse(w, http.StatusBadRequest, "invalid_request", "Invalid request body")
+		return
+	}
+
+	zone := shippingZoneFromRequest(req)
+	zone.ID = zoneID
+	if err := sc.shippingService.UpdateZone(ctx, zone); err != nil {
+		writeShippingZoneError(w, err)
+		return
+	}
+
+	helpers.WriteJSONResponse(w, http.StatusOK, toShippingZoneResponse(zone))
+}
+
+// DeleteZone handles DELETE /api/v1/admin/shipping-zones/{zoneId} - Remove a shipping zone (admin only)
+func (sc *ShippingController) DeleteZone(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	// Verify admin role
+	role := getUserRoleFromContext(ctx)
+	if role != "admin" {
+		helpers.WriteErrorResponse(w, http.StatusForbidden, "forbidden", "Admin access required")
+		return
+	}
+
+	zoneID, err := uuid.Parse(mux.Vars(r)["zoneId"])
+	if err != nil {
+		helpers.WriteErrorResponse(w, http.StatusBadRequest, "invalid_request", "Invalid shipping zone ID format")
+		return
+	}
+
+	if err := sc.shippingService.DeleteZone(ctx, zoneID); err != nil {
+		writeShippingZoneError(w, err)
+		return
+	}
+
+	helpers.WriteJSONResponse(w, http.StatusOK, map[string]string{
+		"message": "Shipping zone deleted successfully",
+		"zone_id": zoneID.String(),
+	})
+}
+
+// shippingZoneFromRequest converts a shipping zone request to a model
+func shippingZoneFromRequest(req types.ShippingZoneRequest) *model.ShippingZone {
+	zone := &model.ShippingZone{
+		Name:           req.Name,
+		Country:        req.Country,
+		PostcodePrefix: req.PostcodePrefix,
+		Rates:          make([]model.ShippingRate, len(req.Rates)),
+	}
+	for i, rate := range req.Rates {
+		zone.Rates[i] = model.ShippingRate{
+			Code:           rate.Code,
+			Name:           rate.Name,
+			Carrier:        rate.Carrier,
+			ServiceLevel:   rate.ServiceLevel,
+			MinWeightGrams: rate.MinWeightGrams,
+			MaxWeightGrams: rate.MaxWeightGrams,
+			Price:          rate.Price,
+			EstimatedDays:  rate.EstimatedDays,
+		}
+		if rate.FreeOver != nil {
+			zone.Rates[i].FreeOver = *rate.FreeOver
+		}
+	}
+	return zone
+}
+
+// writeShippingZoneError maps shipping service errors to HTTP responses
+func writeShippingZoneError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, services.ErrShippingZoneNotFound):
+		helpers.WriteErrorResponse(w, http.StatusNotFound, "not_found", err.Error())
+	case errors.Is(err, services.ErrInvalidShippingZone):
+		helpers.WriteErrorResponse(w, http.StatusBadRequest, "invalid_request", err.Error())
+	default:
+		helpers.WriteErrorResponse(w, http.StatusInternalServerError, "internal_error", err.Error())
+	}
+}
+
+// toShippingZoneResponse converts a shipping zone to its API representation
+func toShippingZoneResponse(zone *model.ShippingZone) types.ShippingZoneResponse {
+	rates := make([]types.ShippingRateResponse, len(zone.Rates))
+	for i, rate := range zone.Rates {
+		rates[i] = types.ShippingRateResponse{
+			ID:             rate.ID.String(),
+			Code:           rate.Code,
+			Name:           rate.Name,
+			Carrier:        rate.Carrier,
+			ServiceLevel:   rate.ServiceLevel,
+			MinWeightGrams: rate.MinWeightGrams,
+			MaxWeightGrams: rate.MaxWeightGrams,
+			Price:          rate.Price,
+			EstimatedDays:  rate.EstimatedDays,
+		}
+		if !rate.FreeOver.IsZero() {
+			freeOver := rate.FreeOver
+			rates[i].FreeOver = &freeOver
+		}
+	}
+	return types.ShippingZoneResponse{
+		ID:             zone.ID.String(),
+		Name:           zone.Name,
+		Country:        zone.Country,
+		PostcodePrefix: zone.PostcodePrefix,
+		Rates:          rates,
+		CreatedAt:      zone.CreatedAt,
+		UpdatedAt:      zone.UpdatedAt,
+	}
+}
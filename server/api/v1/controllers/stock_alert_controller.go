@@ -0,0 +1,230 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"oms/server/api/v1/helpers"
+	"oms/server/api/v1/types"
+	"oms/server/core/model"
+	"oms/server/core/services"
+)
+
+const (
+	defaultStockAlertLimit = 100
+	maxStockAlertLimit     = 1000
+)
+
+// StockAlertController handles low-stock reporting and alerts
+type StockAlertController struct {
+	stockAlertService services.StockAlertService
+}
+
+// NewStockAlertController creates a new StockAlertController
+func NewStockAlertController(stockAlertService services.StockAlertService) *StockAlertController {
+	return &StockAlertController{
+		stockAlertService: stockAlertService,
+	}
+}
+
+// GetLowStock handles GET /api/v1/admin/inventory/low-stock - List the stock units at or below their reorder point (admin only)
+// The furthest below their reorder point come first, with how many to order to reach their target level.
+func (sc *StockAlertController) GetLowStock(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	// Verify admin role
+	role := getUserRoleFromContext(ctx)
+	if role != "admin" {
+		helpers.WriteErrorResponse(w, http.StatusForbidden, "forbidden", "Admin access required")
+		return
+	}
+
+	items, err := sc.stockAlertService.ListLowStock(ctx)
+	if err != nil {
+		helpers.WriteErrorResponse(w, http.StatusInternalServerError, "internal_error", "Failed to fetch low stock")
+		return
+	}
+
+	responses := make([]types.LowStockItemResponse, len(items))
+	for i, item := range items {
+		responses[i] = toLowStockItemResponse(item)
+	}
+	helpers.WriteJSONResponse(w, http.StatusOK, responses)
+}
+
+// GetStockAlerts handles GET /api/v1/admin/stock-alerts - List stock alerts, newest first (admin only)
+// Query parameters:
+//   - status: open, acknowledged or resolved, or several separated by commas; all by default
+//   - product_id: the stock unit, a product or variant ID
+//   - limit: at most this many alerts (default 100, max 1000)
+func (sc *StockAlertController) GetStockAlerts(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	// Verify admin role
+	role := getUserRoleFromContext(ctx)
+	if role != "admin" {
+		helpers.WriteErrorResponse(w, http.StatusForbidden, "forbidden", "Admin access required")
+		return
+	}
+
+	filter, err := parseStockAlertFilter(r)
+	if err != nil {
+		helpers.WriteErrorResponse(w, http.StatusBadRequest, "invalid_request", err.Error())
+		return
+	}
+
+	alerts, err := sc.stockAlertService.ListAlerts(ctx, filter)
+	if err != nil {
+		helpers.WriteErrorResponse(w, http.StatusInternalServerError, "internal_error", "Failed to fetch stock alerts")
+		return
+	}
+
+	responses := make([]types.StockAlertResponse, len(alerts))
+	for i, alert := range alerts {
+		responses[i] = toStockAlertResponse(alert)
+	}
+	helpers.WriteJSONResponse(w, http.StatusOK, responses)
+}
+
+// AcknowledgeStockAlert handles POST /api/v1/admin/stock-alerts/{alertId}/acknowledge - Mark an open alert as being dealt with (admin only)
+func (sc *StockAlertController) AcknowledgeStockAlert(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	// Verify admin role
+	role := getUserRoleFromContext(ctx)
+	if role != "admin" {
+		helpers.WriteErrorResponse(w, http.StatusForbidden, "forbidden", "Admin access required")
+		return
+	}
+
+	alertID, err := uuid.Parse(mux.Vars(r)["alertId"])
+	if err != nil {
+		helpers.WriteErrorResponse(w, http.StatusBadRequest, "invalid_request", "Invalid alert ID format")
+		return
+	}
+
+	alert, err := sc.stockAlertService.AcknowledgeAlert(ctx, alertID, getUserIDFromContext(ctx))
+	if err != nil {
+		writeStockAlertError(w, err)
+		return
+	}
+	helpers.WriteJSONResponse(w, http.StatusOK, toStockAlertResponse(alert))
+}
+
+// ResolveStockAlert handles POST /api/v1/admin/stock-alerts/{alertId}/resolve - Close an alert by hand (admin only)
+// Alerts resolve on their own once stock is back above the reorder point; one resolved by hand while
+// stock is still low is opened again on the next inventory change.
+func (sc *StockAlertController) ResolveStockAlert(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	// Verify admin role
+	role := getUserRoleFromContext(ctx)
+	if role != "admin" {
+		helpers.WriteErrorResponse(w, http.StatusForbidden, "forbidden", "Admin access required")
+		return
+	}
+
+	alertID, err := uuid.Parse(mux.Vars(r)["alertId"])
+	if err != nil {
+		helpers.WriteErrorResponse(w, http.StatusBadRequest, "invalid_request", "Invalid alert ID format")
+		return
+	}
+
+	alert, err := sc.stockAlertService.ResolveAlert(ctx, alertID)
+	if err != nil {
+		writeStockAlertError(w, err)
+		return
+	}
+	helpers.WriteJSONResponse(w, http.StatusOK, toStockAlertResponse(alert))
+}
+
+// parseStockAlertFilter reads the stock alert filters from the query string
+func parseStockAlertFilter(r *http.Request) (model.StockAlertFilter, error) {
+	params := r.URL.Query()
+	filter := model.StockAlertFilter{Limit: defaultStockAlertLimit}
+
+	if value := params.Get("status"); value != "" {
+		for _, part := range strings.Split(value, ",") {
+			status := model.StockAlertStatus(strings.ToLower(strings.TrimSpace(part)))
+			if !status.IsValid() {
+				return filter, errors.New("status must be open, acknowledged or resolved")
+			}
+			filter.Statuses = append(filter.Statuses, status)
+		}
+	}
+	if value := params.Get("product_id"); value != "" {
+		productID, err := uuid.Parse(value)
+		if err != nil {
+			return filter, errors.New("invalid product ID format")
+		}
+		filter.ProductID = &productID
+	}
+	if value := params.Get("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit < 1 {
+			return filter, errors.New("limit must be a positive integer")
+		}
+		if limit > maxStockAlertLimit {
+			limit = maxStockAlertLimit
+		}
+		filter.Limit = limit
+	}
+	return filter, nil
+}
+
+// writeStockAlertError maps stock alert service errors to HTTP responses
+func writeStockAlertError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, services.ErrStockAlertNotFound):
+		helpers.WriteErrorResponse(w, http.StatusNotFound, "not_found", "Stock alert not found")
+	case errors.Is(err, services.ErrInvalidStockAlertTransition):
+		helpers.WriteErrorResponse(w, http.StatusConflict, "invalid_transition", err.Error())
+	default:
+		helpers.WriteErrorResponse(w, http.StatusInternalServerError, "internal_error", "Failed to update stock alert")
+	}
+}
+
+// toStockAlertResponse converts a stock alert to its API representation
+func toStockAlertResponse(alert *model.StockAlert) types.StockAlertResponse {
+	return types.StockAlertResponse{
+		ID:             alert.ID.String(),
+		StockUnitID:    alert.ProductID.String(),
+		Status:         string(alert.Status),
+		Quantity:       alert.Quantity,
+		ReorderPoint:   alert.ReorderPoint,
+		TargetLevel:    alert.TargetLevel,
+		AcknowledgedBy: alert.AcknowledgedBy,
+		CreatedAt:      alert.CreatedAt,
+		AcknowledgedAt: alert.AcknowledgedAt,
+		ResolvedAt:     alert.ResolvedAt,
+	}
+}
+
+// toLowStockItemResponse converts a low stock item to its API representation
+func toLowStockItemResponse(item *model.LowStockItem) types.LowStockItemResponse {
+	response := types.LowStockItemResponse{
+		StockUnitID:     item.StockUnitID.String(),
+		ProductID:       item.ProductID.String(),
+		SKU:             item.SKU,
+		Name:            item.Name,
+		Quantity:        item.Quantity,
+		ReorderPoint:    item.ReorderPoint,
+		TargetLevel:     item.TargetLevel,
+		ReorderQuantity: item.ReorderQuantity(),
+		BinLocation:     item.BinLocation,
+	}
+	if item.VariantID != nil {
+		response.VariantID = item.VariantID.String()
+	}
+	if item.AlertID != nil {
+		response.AlertID = item.AlertID.String()
+	}
+	if item.AlertStatus != nil {
+		response.AlertStatus = string(*item.AlertStatus)
+	}
+	return response
+}
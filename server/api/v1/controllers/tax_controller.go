@@ -0,0 +1,176 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"oms/server/api/v1/helpers"
+	"oms/server/api/v1/types"
+	"oms/server/core/model"
+	"oms/server/core/services"
+)
+
+// TaxController handles admin management of the tax rule table
+type TaxController struct {
+	taxService services.TaxService
+}
+
+// NewTaxController creates a new TaxController
+func NewTaxController(taxService services.TaxService) *TaxController {
+	return &TaxController{
+		taxService: taxService,
+	}
+}
+
+// GetRules handles GET /api/v1/admin/tax-rules - List tax rules (admin only)
+func (tc *TaxController) GetRules(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	// Verify admin role
+	role := getUserRoleFromContext(ctx)
+	if role != "admin" {
+		helpers.WriteErrorResponse(w, http.StatusForbidden, "forbidden", "Admin access required")
+		return
+	}
+
+	rules, err := tc.taxService.ListRules(ctx)
+	if err != nil {
+		helpers.WriteErrorResponse(w, http.StatusInternalServerError, "internal_error", "Failed to fetch tax rules")
+		return
+	}
+
+	responses := make([]types.TaxRuleResponse, len(rules))
+	for i, rule := range rules {
+		responses[i] = toTaxRuleResponse(rule)
+	}
+	helpers.WriteJSONResponse(w, http.StatusOK, responses)
+}
+
+// CreateRule handles POST /api/v1/admin/tax-rules - Create a tax rule (admin only)
+func (tc *TaxController) CreateRule(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	// Verify admin role
+	role := getUserRoleFromContext(ctx)
+	if role != "admin" {
+		helpers.WriteErrorResponse(w, http.StatusForbidden, "forbidden", "Admin access required")
+		return
+	}
+
+	var req types.TaxRuleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		helpers.WriteErrorResponse(w, http.StatusBadRequest, "invalid_request", "Invalid request body")
+		return
+	}
+
+	rule := taxRuleFromRequest(req)
+	if err := tc.taxService.CreateRule(ctx, rule); err != nil {
+		writeTaxRuleError(w, err)
+		return
+	}
+
+	helpers.WriteJSONResponse(w, http.StatusCreated, toTaxRuleResponse(rule))
+}
+
+// UpdateRule handles PUT /api/v1/admin/tax-rules/{ruleId} - Replace a tax rule (admin only)
+func (tc *TaxController) UpdateRule(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	// Verify admin role
+	role := getUserRoleFromContext(ctx)
+	if role != "admin" {
+		helpers.WriteErrorResponse(w, http.StatusForbidden, "forbidden", "Admin access required")
+		return
+	}
+
+	ruleID, err := uuid.Parse(mux.Vars(r)["ruleId"])
+	if err != nil {
+		helpers.WriteErrorResponse(w, http.StatusBadRequest, "invalid_request", "Invalid tax rule ID format")
+		return
+	}
+
+	var req types.TaxRuleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		helpers.WriteErrorResponse(w, http.StatusBadRequest, "invalid_request", "Invalid request body")
+		return
+	}
+
+	rule := taxRuleFromRequest(req)
+	rule.ID = ruleID
+	if err := tc.taxService.UpdateRule(ctx, rule); err != nil {
+		writeTaxRuleError(w, err)
+		return
+	}
+
+	helpers.WriteJSONResponse(w, http.StatusOK, toTaxRuleResponse(rule))
+}
+
+// DeleteRule handles DELETE /api/v1/admin/tax-rules/{ruleId} - Remove a tax rule (admin only)
+func (tc *TaxController) DeleteRule(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	// Verify admin role
+	role := getUserRoleFromContext(ctx)
+	if role != "admin" {
+		helpers.WriteErrorResponse(w, http.StatusForbidden, "forbidden", "Admin access required")
+		return
+	}
+
+	ruleID, err := uuid.Parse(mux.Vars(r)["ruleId"])
+	if err != nil {
+		helpers.WriteErrorResponse(w, http.StatusBadRequest, "invalid_request", "Invalid tax rule ID format")
+		return
+	}
+
+	if err := tc.taxService.DeleteRule(ctx, ruleID); err != nil {
+		writeTaxRuleError(w, err)
+		return
+	}
+
+	helpers.WriteJSONResponse(w, http.StatusOK, map[string]string{
+		"message": "Tax rule deleted successfully",
+		"rule_id": ruleID.String(),
+	})
+}
+
+// taxRuleFromRequest converts a tax rule request to a model
+func taxRuleFromRequest(req types.TaxRuleRequest) *model.TaxRule {
+	return &model.TaxRule{
+		Name:      req.Name,
+		Country:   req.Country,
+		Region:    req.Region,
+		TaxClass:  req.TaxClass,
+		Rate:      req.Rate,
+		Inclusive: req.Inclusive,
+	}
+}
+
+// writeTaxRuleError maps tax service errors to HTTP responses
+func writeTaxRuleError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, services.ErrTaxRuleNotFound):
+		helpers.WriteErrorResponse(w, http.StatusNotFound, "not_found", err.Error())
+	case errors.Is(err, services.ErrInvalidTaxRule):
+		helpers.WriteErrorResponse(w, http.StatusBadRequest, "invalid_request", err.Error())
+	default:
+		helpers.WriteErrorResponse(w, http.StatusInternalServerError, "internal_error", err.Error())
+	}
+}
+
+// toTaxRuleResponse converts a tax rule to its API representation
+func toTaxRuleResponse(rule *model.TaxRule) types.TaxRuleResponse {
+	return types.TaxRuleResponse{
+		ID:        rule.ID.String(),
+		Name:      rule.Name,
+		Country:   rule.Country,
+		Region:    rule.Region,
+		TaxClass:  rule.TaxClass,
+		Rate:      rule.Rate,
+		Inclusive: rule.Inclusive,
+		CreatedAt: rule.CreatedAt,
+		UpdatedAt: rule.UpdatedAt,
+	}
+}
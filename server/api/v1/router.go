@@ -7,8 +7,11 @@ import (
 	"gorm.io/gorm"
 	"oms/server/api/v1/controllers"
 	"oms/server/api/v1/helpers"
+	"oms/server/core/audit"
+	"oms/server/core/health"
 	"oms/server/core/services"
 	"oms/server/core/types"
+	"oms/server/core/worker"
 	"oms/server/middleware"
 )
 
@@ -29,22 +32,172 @@ func SetupRouterWithStores(orderService services.OrderService, inventoryStore ty
 
 // SetupRouterWithStoresAndDB configures and returns the API v1 router with all stores and database
 func SetupRouterWithStoresAndDB(orderService services.OrderService, inventoryStore types.InventoryStore, userStore types.UserStore, productStore types.ProductStore, db *gorm.DB) *mux.Router {
+	return SetupRouterWithDependencies(Dependencies{
+		OrderService:   orderService,
+		InventoryStore: inventoryStore,
+		UserStore:      userStore,
+		ProductStore:   productStore,
+		DB:             db,
+	})
+}
+
+// Dependencies holds everything the API v1 router wires into its controllers.
+// Nil fields disable (or degrade) the routes that need them.
+type Dependencies struct {
+	OrderService       services.OrderService
+	InventoryStore     types.InventoryStore
+	UserStore          types.UserStore
+	ProductStore       types.ProductStore
+	VariantStore       types.ProductVariantStore
+	CategoryStore      types.CategoryStore
+	SchemaService      services.MetadataSchemaService // Validates product metadata and shipping addresses; nil disables validation
+	PromotionService   services.PromotionService      // Admin promotion management; nil disables the routes
+	TaxService         services.TaxService            // Admin tax rule management; nil disables the routes
+	CartService        services.CartService           // Shopping cart and checkout; nil disables the routes
+	PaymentService     services.PaymentService        // Order payments and provider webhooks; nil disables the routes
+	ReturnService      services.ReturnService         // Returns of delivered orders; nil disables the routes
+	TrackingService    services.TrackingService       // Carrier tracking events; nil disables the route
+	ShippingService    services.ShippingService       // Admin shipping zone management; nil disables the routes
+	FulfillmentService services.FulfillmentService    // Pick lists and packing slips; nil disables the routes
+	AnalyticsService   services.AnalyticsService      // Admin sales analytics; nil disables the routes
+	AuditLog           audit.Log                      // Admin changes; stores wrapped with the audit package record into it. Nil disables the route
+	ExportService      services.ExportService         // Admin order exports; nil disables the routes
+	ImportService      services.ImportService         // Admin product imports; nil disables the route
+	StockAlertService  services.StockAlertService     // Low-stock report and alerts; nil disables the routes
+	DB                 *gorm.DB
+	Health             *health.Checker
+	Workers            *worker.Group
+	RateLimiter        *middleware.RateLimiter
+	CORS               *middleware.CORSPolicy // Defaults to middleware.DefaultCORSPolicy
+}
+
+// SetupRouterWithDependencies configures and returns the API v1 router
+func SetupRouterWithDependencies(deps Dependencies) *mux.Router {
+	orderService := deps.OrderService
+	inventoryStore := deps.InventoryStore
+	userStore := deps.UserStore
+	productStore := deps.ProductStore
+	variantStore := deps.VariantStore
+	categoryStore := deps.CategoryStore
+	db := deps.DB
+
 	router := mux.NewRouter().PathPrefix("/api/v1").Subrouter()
 
 	// Apply middleware (CORS must be first)
-	router.Use(middleware.CORSMiddleware)
+	corsPolicy := middleware.DefaultCORSPolicy()
+	if deps.CORS != nil {
+		corsPolicy = *deps.CORS
+	}
+	router.Use(middleware.NewCORS(corsPolicy, router).Middleware)
+	router.Use(middleware.RequestIDMiddleware)
 	router.Use(middleware.LoggingMiddleware)
 	router.Use(middleware.PanicRecoveryMiddleware)
+	if deps.RateLimiter != nil {
+		router.Use(deps.RateLimiter.Middleware)
+	}
 	router.Use(middleware.AuthMiddleware) // JWT authentication
 
 	// Initialize controllers
 	authController := controllers.NewAuthController(userStore)
 	orderController := controllers.NewOrderController(orderService)
+	shipmentController := controllers.NewShipmentController(orderService, deps.TrackingService)
+	healthController := controllers.NewHealthController(deps.Health, deps.Workers)
 	
+	// Initialize product controller if product store is available
+	var productController *controllers.ProductController
+	if productStore != nil {
+		productController = controllers.NewProductController(services.NewProductService(productStore, variantStore, categoryStore))
+	}
+
+	// Initialize category controller if category and product stores are available
+	var categoryController *controllers.CategoryController
+	if categoryStore != nil && productStore != nil {
+		categoryController = controllers.NewCategoryController(services.NewCategoryService(categoryStore, productStore))
+	}
+
 	// Initialize admin controller if stores are available
 	var adminController *controllers.AdminController
 	if productStore != nil && inventoryStore != nil {
-		adminController = controllers.NewAdminController(productStore, inventoryStore)
+		var variantService services.VariantService
+		if variantStore != nil {
+			variantService = services.NewVariantService(productStore, variantStore, inventoryStore)
+		}
+		adminController = controllers.NewAdminController(productStore, inventoryStore, variantStore, variantService, deps.SchemaService)
+	}
+
+	// Initialize metadata schema controller if the schema service is available
+	var schemaController *controllers.MetadataSchemaController
+	if deps.SchemaService != nil {
+		schemaController = controllers.NewMetadataSchemaController(deps.SchemaService)
+	}
+	
+	// Initialize promotion controller if the promotion service is available
+	var promotionController *controllers.PromotionController
+	if deps.PromotionService != nil {
+		promotionController = controllers.NewPromotionController(deps.PromotionService)
+	}
+	
+	// Initialize tax controller if the tax service is available
+	var taxController *controllers.TaxController
+	if deps.TaxService != nil {
+		taxController = controllers.NewTaxController(deps.TaxService)
+	}
+	
+	// Shipping quotes come from the order service; zones are managed only with the shipping service
+	shippingController := controllers.NewShippingController(deps.ShippingService, orderService)
+	
+	// Initialize fulfillment controller if the fulfillment service is available
+	var fulfillmentController *controllers.FulfillmentController
+	if deps.FulfillmentService != nil {
+		fulfillmentController = controllers.NewFulfillmentController(deps.FulfillmentService)
+	}
+	
+	// Initialize analytics controller if the analytics service is available
+	var analyticsController *controllers.AnalyticsController
+	if deps.AnalyticsService != nil {
+		analyticsController = controllers.NewAnalyticsController(deps.AnalyticsService)
+	}
+	
+	// Initialize audit controller if the audit log is available
+	var auditController *controllers.AuditController
+	if deps.AuditLog != nil {
+		auditController = controllers.NewAuditController(deps.AuditLog)
+	}
+	
+	// Initialize export controller if the export service is available
+	var exportController *controllers.ExportController
+	if deps.ExportService != nil {
+		exportController = controllers.NewExportController(deps.ExportService)
+	}
+	
+	// Initialize import controller if the import service is available
+	var importController *controllers.ImportController
+	if deps.ImportService != nil {
+		importController = controllers.NewImportController(deps.ImportService)
+	}
+	
+	// Initialize stock alert controller if the stock alert service is available
+	var stockAlertController *controllers.StockAlertController
+	if deps.StockAlertService != nil {
+		stockAlertController = controllers.NewStockAlertController(deps.StockAlertService)
+	}
+	
+	// Initialize cart controller if the cart service is available
+	var cartController *controllers.CartController
+	if deps.CartService != nil {
+		cartController = controllers.NewCartController(deps.CartService)
+	}
+	
+	// Initialize payment controller if the payment service is available
+	var paymentController *controllers.PaymentController
+	if deps.PaymentService != nil {
+		paymentController = controllers.NewPaymentController(orderService, deps.PaymentService)
+	}
+	
+	// Initialize return controller if the return service is available
+	var returnController *controllers.ReturnController
+	if deps.ReturnService != nil {
+		returnController = controllers.NewReturnController(orderService, deps.ReturnService)
 	}
 	
 	// Initialize metrics controller if database is available
@@ -60,65 +213,63 @@ func SetupRouterWithStoresAndDB(orderService services.OrderService, inventorySto
 	// Order routes (require authentication)
 	router.HandleFunc("/orders", orderController.CreateOrder).Methods("POST")
 	router.HandleFunc("/orders", orderController.GetOrders).Methods("GET")
+	router.HandleFunc("/orders/quote", orderController.QuoteOrder).Methods("POST")
 	router.HandleFunc("/orders/{orderId}", orderController.UpdateOrderStatus).Methods("PATCH")
 	router.HandleFunc("/orders/{orderId}/history", orderController.GetOrderHistory).Methods("GET")
+	router.HandleFunc("/orders/{orderId}/cancellations", orderController.CancelOrderItems).Methods("POST")
+	router.HandleFunc("/orders/{orderId}/shipments", shipmentController.CreateShipment).Methods("POST")
+	router.HandleFunc("/orders/{orderId}/shipments", shipmentController.GetShipments).Methods("GET")
 	
+	// Shipping quote route (public, so visitors can compare options before signing in)
+	router.HandleFunc("/shipping/quote", shippingController.Quote).Methods("POST")
+	
+	// Tracking route (carriers are verified by the event's signature instead of a JWT)
+	if deps.TrackingService != nil {
+		router.HandleFunc("/shipments/tracking", shipmentController.TrackEvent).Methods("POST")
+	}
+	
+	// Cart routes (anonymous visitors send X-Cart-Token; checkout requires authentication)
+	if cartController != nil {
+		router.HandleFunc("/cart", cartController.GetCart).Methods("GET")
+		router.HandleFunc("/cart/lines", cartController.AddLine).Methods("POST")
+		router.HandleFunc("/cart/lines/{lineId}", cartController.UpdateLine).Methods("PUT")
+		router.HandleFunc("/cart/lines/{lineId}", cartController.RemoveLine).Methods("DELETE")
+		router.HandleFunc("/cart/checkout", cartController.Checkout).Methods("POST")
+	}
+
+	// Payment routes (the webhook is verified by the provider's signature instead of a JWT)
+	if paymentController != nil {
+		router.HandleFunc("/orders/{orderId}/payments", paymentController.GetOrderPayments).Methods("GET")
+		router.HandleFunc("/payments/webhook", paymentController.Webhook).Methods("POST")
+	}
+
+	// Return routes (customers request returns of their orders; admins move them along)
+	if returnController != nil {
+		router.HandleFunc("/orders/{orderId}/returns", returnController.CreateReturn).Methods("POST")
+		router.HandleFunc("/orders/{orderId}/returns", returnController.GetReturns).Methods("GET")
+		router.HandleFunc("/orders/{orderId}/returns/{returnId}", returnController.UpdateReturn).Methods("PATCH")
+	}
+
 	// Product routes (public, no auth required for GET)
-	router.HandleFunc("/products", func(w http.ResponseWriter, r *http.Request) {
-		ctx := r.Context()
-		
-		// If product store is available, fetch real products from database
-		if productStore != nil {
-			products, err := productStore.GetAll(ctx)
-			if err != nil {
-				helpers.WriteErrorResponse(w, http.StatusInternalServerError, "internal_error", "Failed to fetch products: "+err.Error())
-				return
-			}
-			
-			// Convert to response format with inventory
-			productResponses := make([]map[string]interface{}, len(products))
-			for i, product := range products {
-				// Handle metadata conversion safely
-				metadata := map[string]interface{}{}
-				if product.Metadata != nil {
-					metadata = map[string]interface{}(product.Metadata)
-				}
-				
-				productResponses[i] = map[string]interface{}{
-					"id":       product.ID.String(),
-					"sku":      product.SKU,
-					"name":     product.Name,
-					"price":    product.Price,
-					"metadata": metadata,
-				}
-				
-				// Fetch inventory if inventory store is available
-				if inventoryStore != nil {
-					inv, err := inventoryStore.GetByProductID(ctx, product.ID)
-					if err == nil {
-						productResponses[i]["inventory"] = inv.Quantity
-					} else {
-						productResponses[i]["inventory"] = 0
-					}
-				} else {
-					productResponses[i]["inventory"] = 0
-				}
-			}
-			
-			helpers.WriteJSONResponse(w, http.StatusOK, productResponses)
-			return
-		}
-		
-		// Fallback to empty array if no product store
-		helpers.WriteJSONResponse(w, http.StatusOK, []interface{}{})
-	}).Methods("GET")
-	router.HandleFunc("/products/{productId}", func(w http.ResponseWriter, r *http.Request) {
-		vars := mux.Vars(r)
-		helpers.WriteJSONResponse(w, http.StatusOK, map[string]interface{}{
-			"id":   vars["productId"],
-			"name": "Placeholder Product",
-		})
-	}).Methods("GET")
+	if productController != nil {
+		router.HandleFunc("/products", productController.GetProducts).Methods("GET")
+		router.HandleFunc("/products/{productId}", productController.GetProduct).Methods("GET")
+	} else {
+		// Fallback to empty catalog if no product store
+		router.HandleFunc("/products", func(w http.ResponseWriter, r *http.Request) {
+			helpers.WriteJSONResponse(w, http.StatusOK, []interface{}{})
+		}).Methods("GET")
+	}
+
+	// Category routes (tree is public, changes require admin role)
+	if categoryController != nil {
+		router.HandleFunc("/categories", categoryController.GetCategories).Methods("GET")
+		router.HandleFunc("/admin/categories", categoryController.CreateCategory).Methods("POST")
+		router.HandleFunc("/admin/categories/{categoryId}", categoryController.UpdateCategory).Methods("PUT")
+		router.HandleFunc("/admin/categories/{categoryId}", categoryController.DeleteCategory).Methods("DELETE")
+		router.HandleFunc("/admin/categories/{categoryId}/move", categoryController.MoveCategory).Methods("POST")
+		router.HandleFunc("/admin/products/{productId}/categories", categoryController.SetProductCategories).Methods("PUT")
+	}
 
 	// Admin routes (require admin role)
 	if adminController != nil {
@@ -126,6 +277,85 @@ func SetupRouterWithStoresAndDB(orderService services.OrderService, inventorySto
 		router.HandleFunc("/admin/products/{productId}", adminController.UpdateProduct).Methods("PUT")
 		router.HandleFunc("/admin/products/{productId}", adminController.DeleteProduct).Methods("DELETE")
 		router.HandleFunc("/admin/inventory", adminController.UpdateInventory).Methods("PUT")
+		router.HandleFunc("/admin/inventory/reorder-point", adminController.UpdateReorderPoint).Methods("PUT")
+		if variantStore != nil {
+			router.HandleFunc("/admin/products/{productId}/variants", adminController.GenerateVariants).Methods("POST")
+			router.HandleFunc("/admin/variants/{variantId}", adminController.UpdateVariant).Methods("PUT")
+			router.HandleFunc("/admin/variants/{variantId}", adminController.DeleteVariant).Methods("DELETE")
+		}
+	}
+
+	// Metadata schema routes (require admin role)
+	if schemaController != nil {
+		router.HandleFunc("/admin/schemas", schemaController.GetSchemas).Methods("GET")
+		router.HandleFunc("/admin/schemas", schemaController.CreateSchema).Methods("POST")
+		router.HandleFunc("/admin/schemas/validate", schemaController.ValidateMetadata).Methods("POST")
+		router.HandleFunc("/admin/schemas/{schemaId}", schemaController.UpdateSchema).Methods("PUT")
+		router.HandleFunc("/admin/schemas/{schemaId}", schemaController.DeleteSchema).Methods("DELETE")
+	}
+
+	// Promotion routes (require admin role)
+	if promotionController != nil {
+		router.HandleFunc("/admin/promotions", promotionController.GetPromotions).Methods("GET")
+		router.HandleFunc("/admin/promotions", promotionController.CreatePromotion).Methods("POST")
+		router.HandleFunc("/admin/promotions/{promotionId}", promotionController.UpdatePromotion).Methods("PUT")
+		router.HandleFunc("/admin/promotions/{promotionId}", promotionController.DeletePromotion).Methods("DELETE")
+	}
+
+	// Tax rule routes (require admin role)
+	if taxController != nil {
+		router.HandleFunc("/admin/tax-rules", taxController.GetRules).Methods("GET")
+		router.HandleFunc("/admin/tax-rules", taxController.CreateRule).Methods("POST")
+		router.HandleFunc("/admin/tax-rules/{ruleId}", taxController.UpdateRule).Methods("PUT")
+		router.HandleFunc("/admin/tax-rules/{ruleId}", taxController.DeleteRule).Methods("DELETE")
+	}
+
+	// Shipping zone routes (require admin role)
+	if deps.ShippingService != nil {
+		router.HandleFunc("/admin/shipping-zones", shippingController.GetZones).Methods("GET")
+		router.HandleFunc("/admin/shipping-zones", shippingController.CreateZone).Methods("POST")
+		router.HandleFunc("/admin/shipping-zones/{zoneId}", shippingController.UpdateZone).Methods("PUT")
+		router.HandleFunc("/admin/shipping-zones/{zoneId}", shippingController.DeleteZone).Methods("DELETE")
+	}
+
+	// Warehouse document routes (require admin role)
+	if fulfillmentController != nil {
+		router.HandleFunc("/admin/pick-lists", fulfillmentController.CreatePickList).Methods("POST")
+		router.HandleFunc("/orders/{orderId}/packing-slip", fulfillmentController.GetPackingSlip).Methods("GET")
+	}
+
+	// Sales analytics routes (require admin role)
+	if analyticsController != nil {
+		router.HandleFunc("/admin/analytics/sales", analyticsController.GetSales).Methods("GET")
+		router.HandleFunc("/admin/analytics/top-products", analyticsController.GetTopProducts).Methods("GET")
+		router.HandleFunc("/admin/analytics/funnel", analyticsController.GetFunnel).Methods("GET")
+		router.HandleFunc("/admin/analytics/fulfillment", analyticsController.GetFulfillmentTimes).Methods("GET")
+	}
+
+	// Audit log route (require admin role); ?format=csv downloads it
+	if auditController != nil {
+		router.HandleFunc("/admin/audit", auditController.GetAuditLog).Methods("GET")
+	}
+
+	// Order export routes (require admin role): GET streams the export, POST runs it in the background
+	if exportController != nil {
+		router.HandleFunc("/admin/exports/orders", exportController.ExportOrders).Methods("GET")
+		router.HandleFunc("/admin/exports/orders", exportController.StartOrderExport).Methods("POST")
+		router.HandleFunc("/admin/exports/{exportId}", exportController.GetExport).Methods("GET")
+		router.HandleFunc("/admin/exports/{exportId}/download", exportController.DownloadExport).Methods("GET")
+	}
+
+	// Product import route (requires admin role)
+	if importController != nil {
+		router.HandleFunc("/admin/imports/products", importController.ImportProducts).Methods("POST")
+	}
+
+	// Low-stock routes (require admin role)
+	if stockAlertController != nil {
+		router.HandleFunc("/admin/inventory/low-stock", stockAlertController.GetLowStock).Methods("GET")
+		router.HandleFunc("/admin/stock-alerts", stockAlertController.GetStockAlerts).Methods("GET")
+		router.HandleFunc("/admin/stock-alerts/{alertId}/acknowledge", stockAlertController.AcknowledgeStockAlert).Methods("POST")
+		router.HandleFunc("/admin/stock-alerts/{alertId}/resolve", stockAlertController.ResolveStockAlert).Methods("POST")
 	}
 
 	// Metrics routes (require admin role)
@@ -146,11 +376,17 @@ func SetupRouterWithStoresAndDB(orderService services.OrderService, inventorySto
 		}).Methods("GET")
 	}
 
-	// Health check endpoint (no auth required)
-	router.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
-		w.WriteHeader(http.StatusOK)
-		w.Write([]byte("OK"))
-	}).Methods("GET")
+	// Health check endpoints (no auth required)
+	// /health is kept as an alias of the liveness probe for existing monitors
+	router.HandleFunc("/health", healthController.Live).Methods("GET")
+	router.HandleFunc("/health/live", healthController.Live).Methods("GET")
+	router.HandleFunc("/health/ready", healthController.Ready).Methods("GET")
+
+	// Preflight catch-all (must be registered last): mux only runs middleware for
+	// matched routes, so this lets the CORS middleware answer every OPTIONS request
+	router.PathPrefix("/").Methods("OPTIONS").HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
 
 	return router
 }
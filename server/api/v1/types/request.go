@@ -1,5 +1,11 @@
 package types
 
+import (
+	"time"
+
+	"oms/server/core/money"
+)
+
 // LoginRequest represents the request body for login
 type LoginRequest struct {
 	Username string `json:"username" binding:"required"`
@@ -15,34 +21,242 @@ type SignupRequest struct {
 // CreateOrderRequest represents the request body for creating an order
 type CreateOrderRequest struct {
 	ProductID       string                 `json:"product_id" binding:"required"` // UUID as string
+	VariantID       string                 `json:"variant_id"`                    // UUID as string; required for products with variants
 	Quantity        int                    `json:"quantity" binding:"required,min=1"`
 	ShippingAddress map[string]interface{} `json:"shipping_address"` // Shipping address metadata
+	CouponCodes     []string               `json:"coupon_codes"`     // Case-insensitive; automatic promotions apply without one
+	ShippingOption  string                 `json:"shipping_option"`  // Code of a quoted shipping option; omit for the cheapest
+	PaymentMethod   string                 `json:"payment_method"`   // Payment provider token for the card or wallet to charge
 }
 
 // UpdateOrderStatusRequest represents the request body for updating order status
 type UpdateOrderStatusRequest struct {
-	CurrentStatus string `json:"current_status" binding:"required"`
+	CurrentStatus string           `json:"current_status" binding:"required"`
+	Shipment      *ShipmentDetails `json:"shipment,omitempty"` // Required to ship an order; it ships every open unit
+	Reason        string           `json:"reason"`             // Required to cancel: customer_request, out_of_stock, address_issue, duplicate_order, fraud_suspected or other
+	Note          string           `json:"note"`
+}
+
+// ShipmentDetails describes how a shipment was sent
+type ShipmentDetails struct {
+	Carrier        string     `json:"carrier" binding:"required"` // Carrier code, such as "ups"
+	ServiceLevel   string     `json:"service_level"`              // Such as "ground" or "express"
+	TrackingNumber string     `json:"tracking_number" binding:"required"`
+	LabelReference string     `json:"label_reference"`
+	ShippedAt      *time.Time `json:"shipped_at"` // Defaults to now
+}
+
+// CancelOrderItemsRequest represents the request body for cancelling part of an order
+type CancelOrderItemsRequest struct {
+	Quantity int    `json:"quantity" binding:"required,min=1"`
+	Reason   string `json:"reason" binding:"required"` // A cancellation reason, as for UpdateOrderStatusRequest
+	Note     string `json:"note"`
+}
+
+// CreateShipmentRequest represents the request body for shipping part or all of an order (admin)
+type CreateShipmentRequest struct {
+	Quantity int `json:"quantity" binding:"required,min=1"` // At most the order's open units
+	ShipmentDetails
 }
 
 // CreateProductRequest represents the request body for creating a product (admin only)
 type CreateProductRequest struct {
 	SKU      string                 `json:"sku" binding:"required"`
 	Name     string                 `json:"name" binding:"required"`
-	Price    float64                `json:"price" binding:"required,min=0"`
+	Price    money.Money            `json:"price" binding:"required"` // "1299.99 USD"; a bare amount is in the default currency
 	Metadata map[string]interface{} `json:"metadata"`
+	TaxClass string                 `json:"tax_class"` // Defaults to "standard"
 }
 
 // UpdateProductRequest represents the request body for updating a product (admin only)
 type UpdateProductRequest struct {
 	SKU      string                 `json:"sku"`
 	Name     string                 `json:"name"`
-	Price    float64                `json:"price"`
+	Price    *money.Money           `json:"price"` // Omit to keep the current price
 	Metadata map[string]interface{} `json:"metadata"`
+	TaxClass string                 `json:"tax_class"` // Omit to keep the current tax class
 }
 
 // UpdateInventoryRequest represents the request body for updating inventory (admin only)
 type UpdateInventoryRequest struct {
+	ProductID   string  `json:"product_id" binding:"required"` // UUID as string
+	VariantID   string  `json:"variant_id"`                    // UUID as string; set to stock a single variant
+	Quantity    int     `json:"quantity" binding:"required,min=0"`
+	BinLocation *string `json:"bin_location"` // Where the stock unit is shelved; left unchanged when omitted
+}
+
+// UpdateReorderPointRequest represents the request body for setting a stock unit's reorder point and
+// target level (admin only). Null or omitted values clear them.
+type UpdateReorderPointRequest struct {
+	ProductID    string `json:"product_id" binding:"required"` // UUID as string
+	VariantID    string `json:"variant_id"`                    // UUID as string; set for a single variant's stock
+	ReorderPoint *int   `json:"reorder_point"`                 // Stock is low at or below this; at least 0
+	TargetLevel  *int   `json:"target_level"`                  // What to restock to; above the reorder point
+}
+
+// GenerateVariantsRequest represents the request body for generating a product's variant matrix (admin only)
+type GenerateVariantsRequest struct {
+	Options []ProductOptionRequest `json:"options" binding:"required"`
+}
+
+// ProductOptionRequest is one option axis, e.g. {"name": "Size", "values": ["S", "M", "L"]}
+type ProductOptionRequest struct {
+	Name   string   `json:"name"`
+	Values []string `json:"values"`
+}
+
+// UpdateVariantRequest represents the request body for updating a variant (admin only)
+type UpdateVariantRequest struct {
+	SKU        string       `json:"sku"`
+	Price      *money.Money `json:"price"`       // Price override, in the product's currency
+	ResetPrice bool         `json:"reset_price"` // Remove the override so the product price applies
+}
+
+// CreateCategoryRequest represents the request body for creating a category (admin only)
+type CreateCategoryRequest struct {
+	Name      string `json:"name" binding:"required"`
+	Slug      string `json:"slug"`      // Derived from the name when empty
+	ParentID  string `json:"parent_id"` // UUID as string; empty for a root category
+	SortOrder int    `json:"sort_order"`
+}
+
+// UpdateCategoryRequest represents the request body for renaming a category (admin only)
+type UpdateCategoryRequest struct {
+	Name string `json:"name"`
+	Slug string `json:"slug"`
+}
+
+// MoveCategoryRequest represents the request body for moving or reparenting a category (admin only)
+type MoveCategoryRequest struct {
+	ParentID *string `json:"parent_id"` // Omit to keep the parent, "" to move to the root level
+	Position *int    `json:"position"`  // Index among the new siblings; omit to append
+}
+
+// SetProductCategoriesRequest represents the request body for assigning a product to categories (admin only)
+type SetProductCategoriesRequest struct {
+	CategoryIDs []string `json:"category_ids"` // UUIDs as strings; replaces the current assignment
+}
+
+// MetadataSchemaRequest represents the request body for registering or replacing a metadata schema (admin only)
+type MetadataSchemaRequest struct {
+	Name        string                 `json:"name" binding:"required"`
+	Target      string                 `json:"target" binding:"required"` // "product" or "shipping_address"
+	CategoryID  string                 `json:"category_id"`               // Product schemas only; UUID as string
+	ProductType string                 `json:"product_type"`              // Product schemas only; matches metadata "type"
+	Schema      map[string]interface{} `json:"schema" binding:"required"`
+}
+
+// ValidateMetadataRequest represents the request body for a dry-run metadata validation (admin only).
+// With Schema set the document is checked against that draft only; otherwise against the registered
+// schemas for Target, scoped to ProductID's categories when given.
+type ValidateMetadataRequest struct {
+	Target    string                 `json:"target"`
+	ProductID string                 `json:"product_id"`
+	Schema    map[string]interface{} `json:"schema"`
+	Document  map[string]interface{} `json:"document"`
+}
+
+// PromotionRequest represents the request body for creating or replacing a promotion (admin only)
+type PromotionRequest struct {
+	Name         string       `json:"name" binding:"required"`
+	Code         string       `json:"code"`                    // Coupon code; omit for an automatic promotion
+	Type         string       `json:"type" binding:"required"` // "percentage", "fixed_amount", "free_shipping" or "buy_x_get_y"
+	Active       *bool        `json:"active"`                  // Defaults to true
+	Priority     int          `json:"priority"`
+	PercentOff   string       `json:"percent_off"` // "percentage": e.g. "15" or "12.5"
+	AmountOff    *money.Money `json:"amount_off"`  // "fixed_amount": e.g. "10.00 USD"
+	BuyQuantity  int          `json:"buy_quantity"`
+	GetQuantity  int          `json:"get_quantity"`
+	MinSubtotal  *money.Money `json:"min_subtotal"` // Order subtotal required, e.g. "100.00 USD"
+	ProductIDs   []string     `json:"product_ids"`  // UUIDs as strings
+	CategoryIDs  []string     `json:"category_ids"` // UUIDs as strings; include subcategories
+	UsageLimit   int          `json:"usage_limit"`  // 0 for unlimited
+	PerUserLimit int          `json:"per_user_limit"`
+	StartsAt     *time.Time   `json:"starts_at"`
+	EndsAt       *time.Time   `json:"ends_at"`
+}
+
+// TaxRuleRequest represents the request body for creating or replacing a tax rule (admin only)
+type TaxRuleRequest struct {
+	Name      string `json:"name" binding:"required"`
+	Country   string `json:"country" binding:"required"` // ISO 3166-1 alpha-2, e.g. "US"
+	Region    string `json:"region"`                     // State or province code; omit for the whole country
+	TaxClass  string `json:"tax_class"`                  // Omit for every tax class
+	Rate      string `json:"rate" binding:"required"`    // Percentage, e.g. "8.25"
+	Inclusive bool   `json:"inclusive"`                  // Prices already contain the tax
+}
+
+// ShippingZoneRequest represents the request body for creating or replacing a shipping zone and its rates (admin only)
+type ShippingZoneRequest struct {
+	Name           string                `json:"name" binding:"required"`
+	Country        string                `json:"country" binding:"required"` // ISO 3166-1 alpha-2, e.g. "GB"
+	PostcodePrefix string                `json:"postcode_prefix"`            // e.g. "BT"; omit for the whole country
+	Rates          []ShippingRateRequest `json:"rates"`
+}
+
+// ShippingRateRequest represents one weight band of a shipping option in a ShippingZoneRequest
+type ShippingRateRequest struct {
+	Code           string       `json:"code" binding:"required"` // Option code customers choose, e.g. "express"
+	Name           string       `json:"name" binding:"required"`
+	Carrier        string       `json:"carrier"`
+	ServiceLevel   string       `json:"service_level"`
+	MinWeightGrams int          `json:"min_weight_grams"`
+	MaxWeightGrams int          `json:"max_weight_grams"`         // Exclusive; omit for no limit
+	Price          money.Money  `json:"price" binding:"required"` // e.g. "4.99 GBP"
+	FreeOver       *money.Money `json:"free_over"`                // Merchandise value that ships free, e.g. "50.00 GBP"
+	EstimatedDays  int          `json:"estimated_days"`
+}
+
+// ShippingQuoteRequest represents the request body for quoting shipping options
+type ShippingQuoteRequest struct {
+	Items           []ShippingQuoteItem    `json:"items" binding:"required"`
+	ShippingAddress map[string]interface{} `json:"shipping_address"` // Needs "country"; "postcode" narrows the zone
+}
+
+// ShippingQuoteItem is one product, or variant, and quantity in a ShippingQuoteRequest
+type ShippingQuoteItem struct {
 	ProductID string `json:"product_id" binding:"required"` // UUID as string
-	Quantity  int    `json:"quantity" binding:"required,min=0"`
+	VariantID string `json:"variant_id"`                    // UUID as string; required for products with variants
+	Quantity  int    `json:"quantity" binding:"required,min=1"`
+}
+
+// PickListRequest represents the request body for generating a pick list (admin only)
+type PickListRequest struct {
+	OrderIDs     []string `json:"order_ids"`     // UUIDs as strings; every ORDERED and PAID order when empty
+	StartPicking bool     `json:"start_picking"` // Move the orders to PICKING
 }
 
+// AddCartLineRequest represents the request body for adding a product to the cart
+type AddCartLineRequest struct {
+	ProductID string `json:"product_id" binding:"required"` // UUID as string
+	VariantID string `json:"variant_id"`                    // UUID as string; required for products with variants
+	Quantity  int    `json:"quantity" binding:"required,min=1"`
+}
+
+// UpdateCartLineRequest represents the request body for changing a cart line's quantity
+type UpdateCartLineRequest struct {
+	Quantity int `json:"quantity" binding:"required,min=1"`
+}
+
+// CheckoutRequest represents the request body for checking out the cart
+type CheckoutRequest struct {
+	ShippingAddress map[string]interface{} `json:"shipping_address"` // Shipping address metadata, used for every order
+	ShippingOption  string                 `json:"shipping_option"`  // Code of a quoted shipping option; omit for the cheapest
+	PaymentMethod   string                 `json:"payment_method"`   // Payment provider token, charged separately for each order
+}
+
+// CreateReturnRequest represents the request body for returning some or all of a delivered order
+type CreateReturnRequest struct {
+	Quantity int    `json:"quantity" binding:"required,min=1"`
+	Reason   string `json:"reason" binding:"required"` // damaged, defective, wrong_item, not_as_described, no_longer_needed or other
+	Note     string `json:"note"`
+}
+
+// UpdateReturnRequest represents the request body for moving a return along (admin).
+// The quantities are required for "received" and must add up to the returned quantity.
+type UpdateReturnRequest struct {
+	Status            string `json:"status" binding:"required"` // approved, rejected, received or refunded (retries a failed refund)
+	Note              string `json:"note"`
+	RestockedQuantity int    `json:"restocked_quantity"`
+	DamagedQuantity   int    `json:"damaged_quantity"` // Written off instead of restocked
+}
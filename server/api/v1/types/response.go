@@ -1,6 +1,10 @@
 package types
 
-import "time"
+import (
+	"time"
+
+	"oms/server/core/money"
+)
 
 // LoginResponse represents the response for login
 type LoginResponse struct {
@@ -17,50 +21,153 @@ type SignupResponse struct {
 
 // CreateOrderResponse represents the response for order creation
 type CreateOrderResponse struct {
-	OrderID       string `json:"order_id"`
-	CurrentStatus string `json:"current_status"`
-	Message       string `json:"message"`
+	OrderID       string      `json:"order_id"`
+	CurrentStatus string      `json:"current_status"`
+	Total         money.Money `json:"total"`
+	Message       string      `json:"message"`
 }
 
 // UpdateOrderStatusResponse represents the response for order status update
 type UpdateOrderStatusResponse struct {
-	OrderID       string    `json:"order_id"`
-	PreviousStatus string   `json:"previous_status"`
-	CurrentStatus string    `json:"current_status"`
-	UpdatedBy     int       `json:"updated_by"`
-	UpdatedAt     *time.Time `json:"updated_at"`
+	OrderID        string     `json:"order_id"`
+	PreviousStatus string     `json:"previous_status"`
+	CurrentStatus  string     `json:"current_status"`
+	UpdatedBy      int        `json:"updated_by"`
+	UpdatedAt      *time.Time `json:"updated_at"`
 }
 
 // OrderResponse represents an order in the response
 type OrderResponse struct {
-	ID            string                 `json:"id"`
-	UserID        int                    `json:"user_id"`
-	ProductID     string                 `json:"product_id"`
-	Quantity      int                    `json:"quantity"`
-	CurrentStatus string                 `json:"current_status"`
-	Metadata      map[string]interface{} `json:"metadata"`
-	CreatedAt     time.Time              `json:"created_at"`
-	UpdatedAt     time.Time              `json:"updated_at"`
+	ID                string                  `json:"id"`
+	UserID            int                     `json:"user_id"`
+	ProductID         string                  `json:"product_id"`
+	VariantID         string                  `json:"variant_id,omitempty"`
+	Quantity          int                     `json:"quantity"`
+	CancelledQuantity int                     `json:"cancelled_quantity"`
+	ShippedQuantity   int                     `json:"shipped_quantity"`
+	CurrentStatus     string                  `json:"current_status"`
+	Metadata          map[string]interface{}  `json:"metadata"`
+	CheckoutID        string                  `json:"checkout_id,omitempty"`
+	SKU               string                  `json:"sku"`          // Snapshot at order time
+	ProductName       string                  `json:"product_name"` // Snapshot at order time
+	UnitPrice         money.Money             `json:"unit_price"`   // Snapshot at order time
+	Subtotal          money.Money             `json:"subtotal"`
+	Discount          money.Money             `json:"discount"`
+	Shipping          money.Money             `json:"shipping"`
+	Tax               money.Money             `json:"tax"`
+	Total             money.Money             `json:"total"`
+	PriceEstimated    bool                    `json:"price_estimated"` // Snapshot was backfilled from a later catalog price
+	Discounts         []OrderDiscountResponse `json:"discounts,omitempty"`
+	TaxIncluded       money.Money             `json:"tax_included"` // Tax contained in the prices, not added to the total
+	TaxLines          []OrderTaxLineResponse  `json:"tax_lines,omitempty"`
+	ShippingOption    *OrderShippingResponse  `json:"shipping_option,omitempty"` // Omitted when shipping was not quoted
+	CreatedAt         time.Time               `json:"created_at"`
+	UpdatedAt         time.Time               `json:"updated_at"`
+}
+
+// OrderDiscountResponse represents a discount line granted by a promotion
+type OrderDiscountResponse struct {
+	PromotionID  string      `json:"promotion_id"`
+	Code         string      `json:"code,omitempty"`
+	Description  string      `json:"description"`
+	Amount       money.Money `json:"amount"`
+	FreeShipping bool        `json:"free_shipping"`
+}
+
+// OrderQuoteResponse represents the price of an order that has not been placed
+type OrderQuoteResponse struct {
+	ProductID      string                  `json:"product_id"`
+	VariantID      string                  `json:"variant_id,omitempty"`
+	Quantity       int                     `json:"quantity"`
+	SKU            string                  `json:"sku"`
+	ProductName    string                  `json:"product_name"`
+	UnitPrice      money.Money             `json:"unit_price"`
+	Subtotal       money.Money             `json:"subtotal"`
+	Discount       money.Money             `json:"discount"`
+	Shipping       money.Money             `json:"shipping"`
+	Tax            money.Money             `json:"tax"`
+	TaxIncluded    money.Money             `json:"tax_included"`
+	Total          money.Money             `json:"total"`
+	Discounts      []OrderDiscountResponse `json:"discounts"`
+	TaxLines       []OrderTaxLineResponse  `json:"tax_lines"`
+	ShippingOption *OrderShippingResponse  `json:"shipping_option,omitempty"`
+}
+
+// OrderShippingResponse represents the shipping option an order is charged for
+type OrderShippingResponse struct {
+	Code         string `json:"code"`
+	Name         string `json:"name"`
+	Carrier      string `json:"carrier,omitempty"`
+	ServiceLevel string `json:"service_level,omitempty"`
+}
+
+// OrderTaxLineResponse represents a tax charged on part of an order
+type OrderTaxLineResponse struct {
+	Name      string      `json:"name"`
+	TaxClass  string      `json:"tax_class"`
+	Rate      string      `json:"rate"` // Percentage
+	Inclusive bool        `json:"inclusive"`
+	Taxable   money.Money `json:"taxable"`
+	Amount    money.Money `json:"amount"`
 }
 
 // OrderHistoryResponse represents an order state change in history
 type OrderHistoryResponse struct {
-	OrderID        string    `json:"order_id"`
-	PreviousStatus string    `json:"previous_status"`
-	NewStatus      string    `json:"new_status"`
-	UpdatedBy      int       `json:"updated_by"`
-	UpdatedAt      time.Time `json:"updated_at"`
+	OrderID        string            `json:"order_id"`
+	PreviousStatus string            `json:"previous_status"`
+	NewStatus      string            `json:"new_status"`
+	UpdatedBy      int               `json:"updated_by"` // User ID, 0 when the actor is not a user
+	Actor          ActorResponse     `json:"actor"`
+	ReasonCode     string            `json:"reason_code,omitempty"` // For cancellations and returns, e.g. "customer_request" or "defective"
+	Note           string            `json:"note,omitempty"`        // What changed, e.g. "Shipped 2 of 5 units via ups 1Z999"
+	Shipment       *ShipmentResponse `json:"shipment,omitempty"`    // The shipment that shipped or delivered the order, with its tracking
+	RequestID      string            `json:"request_id,omitempty"`  // The API request that made the change
+	ClientIP       string            `json:"client_ip,omitempty"`
+	UpdatedAt      time.Time         `json:"updated_at"`
+}
+
+// ActorResponse identifies who made a change
+type ActorResponse struct {
+	Type string `json:"type"` // user, system, api_key or integration
+	ID   string `json:"id"`   // User ID, job name, API key ID, or integration such as "carrier:ups"
 }
 
 // ProductResponse represents a product in the response
 type ProductResponse struct {
-	ID        string                 `json:"id"`
-	SKU       string                 `json:"sku"`
-	Name      string                 `json:"name"`
-	Price     float64                `json:"price"`
-	Metadata  map[string]interface{} `json:"metadata"`
-	CreatedAt time.Time              `json:"created_at"`
-	UpdatedAt time.Time              `json:"updated_at"`
+	ID        string                   `json:"id"`
+	SKU       string                   `json:"sku"`
+	Name      string                   `json:"name"`
+	Price     money.Money              `json:"price"`               // "1299.99 USD"
+	Inventory *int                     `json:"inventory,omitempty"` // Stock quantity, when joined; summed over variants
+	Metadata  map[string]interface{}   `json:"metadata"`
+	TaxClass  string                   `json:"tax_class"`
+	Options   []ProductOptionResponse  `json:"options,omitempty"`
+	Variants  []ProductVariantResponse `json:"variants,omitempty"`
+	CreatedAt time.Time                `json:"created_at"`
+	UpdatedAt time.Time                `json:"updated_at"`
+}
+
+// ProductOptionResponse represents one option axis of a product
+type ProductOptionResponse struct {
+	Name   string   `json:"name"`
+	Values []string `json:"values"`
+}
+
+// ProductVariantResponse represents a product variant in the response
+type ProductVariantResponse struct {
+	ID            string            `json:"id"`
+	SKU           string            `json:"sku"`
+	Options       map[string]string `json:"options"`
+	Price         money.Money       `json:"price"`          // Effective price
+	PriceOverride bool              `json:"price_override"` // Whether the price differs from the product price
+	Inventory     *int              `json:"inventory,omitempty"`
+}
+
+// GenerateVariantsResponse represents the response for generating a variant matrix
+type GenerateVariantsResponse struct {
+	ProductID string                   `json:"product_id"`
+	Variants  []ProductVariantResponse `json:"variants"`
+	Message   string                   `json:"message"`
 }
 
 // InventoryResponse represents inventory in the response
@@ -77,9 +184,36 @@ type CreateProductResponse struct {
 
 // UpdateInventoryResponse represents the response for inventory update
 type UpdateInventoryResponse struct {
-	ProductID string `json:"product_id"`
-	Quantity  int    `json:"quantity"`
-	Message   string `json:"message"`
+	ProductID   string `json:"product_id"`
+	VariantID   string `json:"variant_id,omitempty"`
+	Quantity    int    `json:"quantity"`
+	BinLocation string `json:"bin_location,omitempty"`
+	Message     string `json:"message"`
+}
+
+// UpdateReorderPointResponse represents the response for a reorder point update
+type UpdateReorderPointResponse struct {
+	ProductID    string `json:"product_id"`
+	VariantID    string `json:"variant_id,omitempty"`
+	ReorderPoint *int   `json:"reorder_point"`
+	TargetLevel  *int   `json:"target_level"`
+	Message      string `json:"message"`
+}
+
+// CategoryResponse represents a category, with its subcategories when rendered as a tree
+type CategoryResponse struct {
+	ID        string             `json:"id"`
+	ParentID  string             `json:"parent_id,omitempty"`
+	Name      string             `json:"name"`
+	Slug      string             `json:"slug"`
+	SortOrder int                `json:"sort_order"`
+	Children  []CategoryResponse `json:"children,omitempty"`
+}
+
+// ProductCategoriesResponse represents the categories a product is assigned to
+type ProductCategoriesResponse struct {
+	ProductID  string             `json:"product_id"`
+	Categories []CategoryResponse `json:"categories"`
 }
 
 // ErrorResponse represents an error response
@@ -88,3 +222,399 @@ type ErrorResponse struct {
 	Message string `json:"message,omitempty"`
 }
 
+// FieldErrorResponse is a single field-level validation error
+type FieldErrorResponse struct {
+	Schema  string `json:"schema"`
+	Path    string `json:"path"` // JSON Pointer, e.g. "/postcode"
+	Message string `json:"message"`
+}
+
+// ValidationErrorResponse represents a metadata validation failure
+type ValidationErrorResponse struct {
+	Error   string               `json:"error"`
+	Message string               `json:"message"`
+	Errors  []FieldErrorResponse `json:"errors"`
+}
+
+// MetadataSchemaResponse represents a registered metadata schema
+type MetadataSchemaResponse struct {
+	ID          string                 `json:"id"`
+	Name        string                 `json:"name"`
+	Target      string                 `json:"target"`
+	CategoryID  string                 `json:"category_id,omitempty"`
+	ProductType string                 `json:"product_type,omitempty"`
+	Schema      map[string]interface{} `json:"schema"`
+	CreatedAt   time.Time              `json:"created_at"`
+	UpdatedAt   time.Time              `json:"updated_at"`
+}
+
+// ValidateMetadataResponse represents the result of a dry-run metadata validation
+type ValidateMetadataResponse struct {
+	Valid  bool                 `json:"valid"`
+	Errors []FieldErrorResponse `json:"errors"`
+}
+
+// PromotionResponse represents a promotion
+type PromotionResponse struct {
+	ID              string      `json:"id"`
+	Name            string      `json:"name"`
+	Code            string      `json:"code,omitempty"`
+	Type            string      `json:"type"`
+	Active          bool        `json:"active"`
+	Priority        int         `json:"priority"`
+	PercentOff      string      `json:"percent_off,omitempty"`
+	AmountOff       money.Money `json:"amount_off"`
+	BuyQuantity     int         `json:"buy_quantity,omitempty"`
+	GetQuantity     int         `json:"get_quantity,omitempty"`
+	MinSubtotal     money.Money `json:"min_subtotal"`
+	ProductIDs      []string    `json:"product_ids"`
+	CategoryIDs     []string    `json:"category_ids"`
+	UsageLimit      int         `json:"usage_limit"`
+	PerUserLimit    int         `json:"per_user_limit"`
+	RedemptionCount int         `json:"redemption_count"`
+	StartsAt        *time.Time  `json:"starts_at,omitempty"`
+	EndsAt          *time.Time  `json:"ends_at,omitempty"`
+	CreatedAt       time.Time   `json:"created_at"`
+	UpdatedAt       time.Time   `json:"updated_at"`
+}
+
+// TaxRuleResponse represents a tax rule
+type TaxRuleResponse struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	Country   string    `json:"country"`
+	Region    string    `json:"region,omitempty"`
+	TaxClass  string    `json:"tax_class,omitempty"`
+	Rate      string    `json:"rate"`
+	Inclusive bool      `json:"inclusive"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// ShippingZoneResponse represents a shipping zone and its rates
+type ShippingZoneResponse struct {
+	ID             string                 `json:"id"`
+	Name           string                 `json:"name"`
+	Country        string                 `json:"country"`
+	PostcodePrefix string                 `json:"postcode_prefix,omitempty"`
+	Rates          []ShippingRateResponse `json:"rates"`
+	CreatedAt      time.Time              `json:"created_at"`
+	UpdatedAt      time.Time              `json:"updated_at"`
+}
+
+// ShippingRateResponse represents one weight band of a shipping option
+type ShippingRateResponse struct {
+	ID             string       `json:"id"`
+	Code           string       `json:"code"`
+	Name           string       `json:"name"`
+	Carrier        string       `json:"carrier,omitempty"`
+	ServiceLevel   string       `json:"service_level,omitempty"`
+	MinWeightGrams int          `json:"min_weight_grams"`
+	MaxWeightGrams int          `json:"max_weight_grams,omitempty"` // Omitted when there is no limit
+	Price          money.Money  `json:"price"`
+	FreeOver       *money.Money `json:"free_over,omitempty"`
+	EstimatedDays  int          `json:"estimated_days,omitempty"`
+}
+
+// ShippingOptionResponse represents a quoted way to ship an order
+type ShippingOptionResponse struct {
+	Code          string      `json:"code"`
+	Name          string      `json:"name"`
+	Carrier       string      `json:"carrier,omitempty"`
+	ServiceLevel  string      `json:"service_level,omitempty"`
+	Amount        money.Money `json:"amount"`
+	EstimatedDays int         `json:"estimated_days,omitempty"`
+}
+
+// ShippingQuoteResponse represents the shipping options for a destination, cheapest first
+type ShippingQuoteResponse struct {
+	Options []ShippingOptionResponse `json:"options"`
+}
+
+// PickListResponse represents a batch of orders' units to pick, in walking order
+type PickListResponse struct {
+	ID          string                 `json:"id"`
+	GeneratedAt time.Time              `json:"generated_at"`
+	Lines       []PickListLineResponse `json:"lines"`
+	OrderIDs    []string               `json:"order_ids"`
+	TotalUnits  int                    `json:"total_units"`
+}
+
+// PickListLineResponse represents the units of one stock unit to pick and the orders they go to
+type PickListLineResponse struct {
+	BinLocation string                  `json:"bin_location"`
+	ProductID   string                  `json:"product_id"`
+	VariantID   string                  `json:"variant_id,omitempty"`
+	SKU         string                  `json:"sku"`
+	ProductName string                  `json:"product_name"`
+	Quantity    int                     `json:"quantity"`
+	Orders      []PickListOrderResponse `json:"orders"`
+}
+
+// PickListOrderResponse represents the units of a pick list line that go to one order
+type PickListOrderResponse struct {
+	OrderID  string `json:"order_id"`
+	Quantity int    `json:"quantity"`
+}
+
+// CartResponse represents a cart priced from the current catalog
+type CartResponse struct {
+	ID        string             `json:"id,omitempty"`    // Empty until something is added
+	Token     string             `json:"token,omitempty"` // Anonymous carts only; send it back as X-Cart-Token
+	Lines     []CartLineResponse `json:"lines"`
+	Subtotal  money.Money        `json:"subtotal"`
+	Valid     bool               `json:"valid"` // Every line can be checked out as it is
+	ExpiresAt *time.Time         `json:"expires_at,omitempty"`
+}
+
+// CartLineResponse represents a cart line with its current price and stock
+type CartLineResponse struct {
+	ID          string      `json:"id"`
+	ProductID   string      `json:"product_id"`
+	VariantID   string      `json:"variant_id,omitempty"`
+	SKU         string      `json:"sku"`
+	ProductName string      `json:"product_name"`
+	Quantity    int         `json:"quantity"`
+	UnitPrice   money.Money `json:"unit_price"` // Current catalog price
+	LineTotal   money.Money `json:"line_total"`
+	Available   int         `json:"available"`       // Current stock
+	Issue       string      `json:"issue,omitempty"` // Why the line cannot be checked out: "unavailable", "variant_required", "insufficient_stock" or "currency_mismatch"
+}
+
+// CheckoutResponse represents the orders placed from a cart
+type CheckoutResponse struct {
+	CheckoutID string          `json:"checkout_id"`
+	Orders     []OrderResponse `json:"orders"`
+	Total      money.Money     `json:"total"` // Sum of the order totals
+	Message    string          `json:"message"`
+}
+
+// PaymentResponse represents an order payment and how its status changed
+type PaymentResponse struct {
+	ID            string                   `json:"id"`
+	OrderID       string                   `json:"order_id"`
+	Provider      string                   `json:"provider"`
+	Reference     string                   `json:"reference,omitempty"` // The provider's ID for the payment
+	Status        string                   `json:"status"`
+	Amount        money.Money              `json:"amount"`
+	Captured      money.Money              `json:"captured"`
+	Refunded      money.Money              `json:"refunded"`
+	FailureReason string                   `json:"failure_reason,omitempty"`
+	History       []PaymentHistoryResponse `json:"history"`
+	CreatedAt     time.Time                `json:"created_at"`
+	UpdatedAt     time.Time                `json:"updated_at"`
+}
+
+// PaymentHistoryResponse represents one payment status change
+type PaymentHistoryResponse struct {
+	PreviousStatus string      `json:"previous_status,omitempty"` // Empty for the initial status
+	NewStatus      string      `json:"new_status"`
+	Amount         money.Money `json:"amount"`
+	Message        string      `json:"message,omitempty"`
+	CreatedAt      time.Time   `json:"created_at"`
+}
+
+// ReturnResponse represents a return request of an order
+type ReturnResponse struct {
+	ID                string      `json:"id"`
+	OrderID           string      `json:"order_id"`
+	UserID            int         `json:"user_id"`
+	Quantity          int         `json:"quantity"`
+	Reason            string      `json:"reason"`
+	Note              string      `json:"note,omitempty"`
+	Status            string      `json:"status"`
+	RestockedQuantity int         `json:"restocked_quantity"`
+	DamagedQuantity   int         `json:"damaged_quantity"`
+	RefundAmount      money.Money `json:"refund_amount"` // Set when the goods are received
+	ResolutionNote    string      `json:"resolution_note,omitempty"`
+	HandledBy         int         `json:"handled_by,omitempty"`
+	CreatedAt         time.Time   `json:"created_at"`
+	UpdatedAt         time.Time   `json:"updated_at"`
+}
+
+// ShipmentResponse represents one shipment of an order with its tracking
+type ShipmentResponse struct {
+	ID             string                  `json:"id"`
+	OrderID        string                  `json:"order_id"`
+	Quantity       int                     `json:"quantity"`
+	Carrier        string                  `json:"carrier"`
+	ServiceLevel   string                  `json:"service_level,omitempty"`
+	TrackingNumber string                  `json:"tracking_number"`
+	LabelReference string                  `json:"label_reference,omitempty"`
+	Status         string                  `json:"status"` // shipped, in_transit, out_for_delivery, delivered or exception
+	ShippedBy      int                     `json:"shipped_by"`
+	ShippedAt      time.Time               `json:"shipped_at"`
+	DeliveredAt    *time.Time              `json:"delivered_at,omitempty"`
+	Events         []TrackingEventResponse `json:"events"` // In the order they occurred
+	CreatedAt      time.Time               `json:"created_at"`
+}
+
+// TrackingEventResponse represents one tracking event reported by a carrier
+type TrackingEventResponse struct {
+	Status      string    `json:"status"`
+	Description string    `json:"description,omitempty"`
+	Location    string    `json:"location,omitempty"`
+	OccurredAt  time.Time `json:"occurred_at"`
+}
+
+// AuditEntryResponse represents one admin change in the audit log
+type AuditEntryResponse struct {
+	ID         string                 `json:"id"`
+	Actor      ActorResponse          `json:"actor"`
+	Action     string                 `json:"action"`      // create, update or delete
+	EntityType string                 `json:"entity_type"` // Such as "product", "inventory" or "promotion"
+	EntityID   string                 `json:"entity_id"`
+	Before     map[string]interface{} `json:"before,omitempty"` // Omitted for creations
+	After      map[string]interface{} `json:"after,omitempty"`  // Omitted for deletions
+	Changes    map[string]interface{} `json:"changes"`          // Each changed field as { "before": ..., "after": ... }
+	RequestID  string                 `json:"request_id,omitempty"`
+	ClientIP   string                 `json:"client_ip,omitempty"`
+	CreatedAt  time.Time              `json:"created_at"`
+}
+
+// ExportJobResponse represents a background export; DownloadURL is set once it completes
+type ExportJobResponse struct {
+	ID          string                 `json:"id"`
+	Kind        string                 `json:"kind"`   // orders
+	Status      string                 `json:"status"` // queued, running, completed or failed
+	Format      string                 `json:"format"` // csv or ndjson
+	Params      map[string]interface{} `json:"params"` // The filter, columns and inclusions the export was started with
+	RequestedBy int                    `json:"requested_by"`
+	RowCount    int64                  `json:"row_count"`
+	Size        int64                  `json:"size"` // Of the file, in bytes
+	Error       string                 `json:"error,omitempty"`
+	DownloadURL string                 `json:"download_url,omitempty"`
+	CreatedAt   time.Time              `json:"created_at"`
+	StartedAt   *time.Time             `json:"started_at,omitempty"`
+	CompletedAt *time.Time             `json:"completed_at,omitempty"`
+	ExpiresAt   *time.Time             `json:"expires_at,omitempty"` // When the file is deleted
+}
+
+// SalesReportResponse reports the orders, units and revenue of each period in a range
+type SalesReportResponse struct {
+	Interval string                `json:"interval"`  // day, week or month
+	TimeZone string                `json:"time_zone"` // IANA time zone the periods are in, e.g. "Europe/London"
+	From     time.Time             `json:"from"`
+	To       time.Time             `json:"to"` // Exclusive
+	Periods  []SalesPeriodResponse `json:"periods"`
+	Totals   SalesResponse         `json:"totals"`
+}
+
+// SalesResponse is what sold, net of cancellations
+type SalesResponse struct {
+	Orders  int64         `json:"orders"`
+	Units   int64         `json:"units"`
+	Revenue []money.Money `json:"revenue"` // One amount per currency, e.g. ["1250.00 USD", "80.00 EUR"]
+}
+
+// SalesPeriodResponse is what sold in one period
+type SalesPeriodResponse struct {
+	Start time.Time `json:"start"`
+	SalesResponse
+}
+
+// ProductSalesResponse is what one product sold
+type ProductSalesResponse struct {
+	ProductID string      `json:"product_id"`
+	SKU       string      `json:"sku"`
+	Name      string      `json:"name"`
+	Orders    int64       `json:"orders"`
+	Units     int64       `json:"units"`
+	Revenue   money.Money `json:"revenue"`
+}
+
+// OrderFunnelResponse counts the orders placed in a range by the steps they reached
+type OrderFunnelResponse struct {
+	From             time.Time        `json:"from"`
+	To               time.Time        `json:"to"`
+	Placed           int64            `json:"placed"`
+	Paid             int64            `json:"paid"`
+	Picking          int64            `json:"picking"`
+	Shipped          int64            `json:"shipped"` // Shipped in full
+	Delivered        int64            `json:"delivered"`
+	Cancelled        int64            `json:"cancelled"`
+	CancellationRate float64          `json:"cancellation_rate"` // Cancelled / placed, from 0 to 1
+	Statuses         map[string]int64 `json:"statuses"`          // By current status
+}
+
+// FulfillmentTimesResponse reports how long the orders placed in a range took to fulfill
+type FulfillmentTimesResponse struct {
+	From               time.Time           `json:"from"`
+	To                 time.Time           `json:"to"`
+	OrderedToShipped   FulfillmentStepTime `json:"ordered_to_shipped"`
+	ShippedToDelivered FulfillmentStepTime `json:"shipped_to_delivered"`
+}
+
+// FulfillmentStepTime is the average duration of one fulfillment step
+type FulfillmentStepTime struct {
+	AverageSeconds float64 `json:"average_seconds"`
+	AverageHours   float64 `json:"average_hours"`
+	Orders         int64   `json:"orders"` // Orders that completed the step
+}
+
+// ImportReportResponse reports the outcome of an import. Created and Updated count the rows saved,
+// or that would have been for dry runs and atomic imports that saved nothing.
+type ImportReportResponse struct {
+	DryRun        bool                  `json:"dry_run"`
+	Atomic        bool                  `json:"atomic"`
+	Saved         bool                  `json:"saved"` // Whether any row was saved
+	Rows          int                   `json:"rows"`
+	Created       int                   `json:"created"`
+	Updated       int                   `json:"updated"`
+	Unchanged     int                   `json:"unchanged"` // Rows matching what is saved already, which are skipped
+	Failed        int                   `json:"failed"`
+	Errors        []ImportErrorResponse `json:"errors"`                   // The first 1000
+	ErrorsOmitted int                   `json:"errors_omitted,omitempty"` // Errors beyond the first 1000
+	Batches       []ImportBatchResponse `json:"batches"`
+}
+
+// ImportErrorResponse is why a row of an import failed; line 0 is the file as a whole
+type ImportErrorResponse struct {
+	Line    int    `json:"line"`
+	SKU     string `json:"sku,omitempty"`
+	Field   string `json:"field,omitempty"` // The column at fault; metadata keys are meta.<key>
+	Message string `json:"message"`
+}
+
+// ImportBatchResponse summarizes one batch of an import
+type ImportBatchResponse struct {
+	Number    int  `json:"number"`
+	FirstLine int  `json:"first_line"`
+	LastLine  int  `json:"last_line"`
+	Created   int  `json:"created"`
+	Updated   int  `json:"updated"`
+	Unchanged int  `json:"unchanged"`
+	Failed    int  `json:"failed"`
+	Saved     bool `json:"saved"` // Whether its valid rows are saved
+}
+
+// StockAlertResponse represents a low-stock alert. Quantity, reorder point and target level are as
+// they were when it opened.
+type StockAlertResponse struct {
+	ID             string     `json:"id"`
+	StockUnitID    string     `json:"stock_unit_id"` // The product or variant stocked
+	Status         string     `json:"status"`        // open, acknowledged or resolved
+	Quantity       int        `json:"quantity"`
+	ReorderPoint   int        `json:"reorder_point"`
+	TargetLevel    *int       `json:"target_level,omitempty"`
+	AcknowledgedBy *int       `json:"acknowledged_by,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+	AcknowledgedAt *time.Time `json:"acknowledged_at,omitempty"`
+	ResolvedAt     *time.Time `json:"resolved_at,omitempty"`
+}
+
+// LowStockItemResponse represents a stock unit at or below its reorder point
+type LowStockItemResponse struct {
+	StockUnitID     string `json:"stock_unit_id"`
+	ProductID       string `json:"product_id"`
+	VariantID       string `json:"variant_id,omitempty"`
+	SKU             string `json:"sku"`
+	Name            string `json:"name"`
+	Quantity        int    `json:"quantity"`
+	ReorderPoint    int    `json:"reorder_point"`
+	TargetLevel     *int   `json:"target_level,omitempty"`
+	ReorderQuantity int    `json:"reorder_quantity"` // Units to order to reach the target level, 0 without one
+	BinLocation     string `json:"bin_location,omitempty"`
+	AlertID         string `json:"alert_id,omitempty"`     // The alert that isn't resolved yet
+	AlertStatus     string `json:"alert_status,omitempty"` // open or acknowledged
+}
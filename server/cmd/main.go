@@ -1,34 +1,82 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"flag"
 	"fmt"
 	"log"
 	"net/http"
 	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"syscall"
+	"time"
+	_ "time/tzdata" // Analytics time zones work without the host's zoneinfo
 
 	"oms/server/api/v1"
 	"oms/server/config"
 	"oms/server/database"
 	"oms/server/datastore"
+	"oms/server/core/audit"
+	"oms/server/core/auth"
 	"oms/server/core/fsm"
+	"oms/server/core/health"
 	"oms/server/core/model"
+	"oms/server/core/money"
+	"oms/server/core/notify"
+	"oms/server/core/payment"
+	"oms/server/core/scheduler"
 	"oms/server/core/services"
+	"oms/server/core/shipping"
+	"oms/server/core/stock"
+	"oms/server/core/tax"
+	"oms/server/core/types"
+	"oms/server/core/worker"
+	"oms/server/logging"
+	"oms/server/middleware"
 	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
 
 func main() {
 	apiFlag := flag.Bool("api", false, "Start the API server")
+	workerFlag := flag.Bool("worker", false, "Start the background job scheduler")
 	migrateFlag := flag.Bool("migrate", false, "Run database migrations")
-	port := flag.String("port", "8080", "Port to run the API server on")
+	checkMetadataFlag := flag.Bool("check-metadata", false, "Report products and orders whose metadata violates the registered schemas")
+	backfillPricesFlag := flag.Bool("backfill-order-prices", false, "Snapshot current catalog prices onto orders placed before price snapshots, flagged as estimated")
+	importProductsFlag := flag.String("import-products", "", "Create or update products and their stock by SKU from a CSV or NDJSON file (- reads standard input)")
+	importFormatFlag := flag.String("import-format", "", "Format of the -import-products file, csv or ndjson (default: from the file extension, else csv)")
+	importDryRunFlag := flag.Bool("import-dry-run", false, "Check every row of the -import-products file and report what would change, saving nothing")
+	importAtomicFlag := flag.Bool("import-atomic", false, "Save every row of the -import-products file or, when any is invalid, none")
+	configFile := flag.String("config", "", "Path to a YAML config file (overrides CONFIG_FILE)")
+	port := flag.String("port", "", "Port to run the API server on (overrides SERVER_PORT)")
 	flag.Parse()
 
-	// Load configuration
-	cfg, err := config.Load()
+	// Load configuration: defaults < config file < environment < flags
+	overrides := map[string]string{}
+	flag.Visit(func(f *flag.Flag) {
+		if f.Name == "port" {
+			overrides["server.port"] = *port
+		}
+	})
+	configManager, err := config.NewManager(config.Options{File: *configFile, Flags: overrides})
 	if err != nil {
 		log.Fatalf("Failed to load configuration: %v", err)
 	}
+	cfg := configManager.Current()
+
+	if err := logging.Setup(cfg.Logging.Level, cfg.Logging.Format); err != nil {
+		log.Fatalf("Failed to configure logging: %v", err)
+	}
+	if cfg.JWT.Secret != "" {
+		auth.SetSecret(cfg.JWT.Secret)
+	} else {
+		log.Println("Warning: JWT_SECRET is not set, using the insecure development secret")
+	}
+	auth.SetTokenTTL(cfg.JWT.Expiry)
 
 	// Connect to database
 	db, err := database.Connect(cfg)
@@ -41,8 +89,32 @@ func main() {
 		return
 	}
 
+	if *backfillPricesFlag {
+		backfillOrderPrices(db)
+		return
+	}
+
+	if *checkMetadataFlag {
+		checkMetadata(db)
+		return
+	}
+
+	if *importProductsFlag != "" {
+		importProducts(cfg, db, *importProductsFlag, model.ImportOptions{
+			Format: model.FileFormat(strings.ToLower(*importFormatFlag)),
+			DryRun: *importDryRunFlag,
+			Atomic: *importAtomicFlag,
+		})
+		return
+	}
+
 	if *apiFlag {
-		startAPIServer(*port, db)
+		startAPIServer(configManager, db)
+		return
+	}
+
+	if *workerFlag {
+		startWorker(configManager, db)
 		return
 	}
 
@@ -64,6 +136,142 @@ func runMigrations(db *gorm.DB) {
 	seedDummyProducts(db)
 }
 
+// backfillOrderPrices snapshots orders that predate price snapshots from the current catalog.
+// The result is only an estimate of what was charged, so those orders are flagged as such.
+func backfillOrderPrices(db *gorm.DB) {
+	orderService := services.NewOrderService(
+		datastore.NewOrderStore(db),
+		datastore.NewInventoryStore(db),
+		datastore.NewProductStore(db),
+		datastore.NewProductVariantStore(db),
+		datastore.NewOrderStateLogStore(db),
+		fsm.NewValidator(),
+		nil,
+		nil,
+		nil,
+		nil,
+		nil,
+		nil,
+	)
+
+	updated, skipped, err := orderService.BackfillPriceSnapshots(context.Background())
+	if err != nil {
+		log.Fatalf("Backfill failed after %d orders: %v", updated, err)
+	}
+	log.Printf("✅ Backfilled estimated prices on %d orders", updated)
+	if skipped > 0 {
+		log.Printf("Warning: %d orders reference deleted products and were left without a snapshot", skipped)
+	}
+}
+
+// checkMetadata prints every stored row that violates the current metadata schemas
+// and exits non-zero if there are any, so it can gate a schema rollout
+func checkMetadata(db *gorm.DB) {
+	schemaService := services.NewMetadataSchemaService(
+		datastore.NewMetadataSchemaStore(db),
+		datastore.NewProductStore(db),
+		datastore.NewCategoryStore(db),
+		datastore.NewOrderStore(db),
+	)
+
+	reports, err := schemaService.Report(context.Background())
+	if err != nil {
+		log.Fatalf("Metadata check failed: %v", err)
+	}
+	if len(reports) == 0 {
+		log.Println("✅ All product and order metadata matches the registered schemas")
+		return
+	}
+
+	violations := 0
+	for _, report := range reports {
+		for _, violation := range report.Violations {
+			path := violation.Path
+			if path == "" {
+				path = "/"
+			}
+			fmt.Printf("%s %s: [%s] %s: %s\n", report.Target, report.ID, violation.Schema, path, violation.Message)
+			violations++
+		}
+	}
+	fmt.Printf("%d violations in %d rows\n", violations, len(reports))
+	os.Exit(1)
+}
+
+// importProducts imports a product file as the API's product import does, printing each batch as it
+// is done and exiting non-zero when any row failed. The changes are audited as the "import" job's.
+func importProducts(cfg *config.Config, db *gorm.DB, path string, opts model.ImportOptions) {
+	input := os.Stdin
+	if path != "-" {
+		file, err := os.Open(path)
+		if err != nil {
+			log.Fatalf("Failed to open the import file: %v", err)
+		}
+		defer file.Close()
+		input = file
+	}
+	if opts.Format == "" {
+		opts.Format = model.FormatCSV
+		switch strings.ToLower(filepath.Ext(path)) {
+		case ".ndjson", ".jsonl":
+			opts.Format = model.FormatNDJSON
+		}
+	}
+	opts.Progress = func(batch model.ImportBatch) {
+		fmt.Printf("Batch %d, lines %d-%d: %d created, %d updated, %d unchanged, %d failed\n",
+			batch.Number, batch.FirstLine, batch.LastLine, batch.Created, batch.Updated, batch.Unchanged, batch.Failed)
+	}
+
+	auditLog := audit.NewLog(datastore.NewAuditStore(db))
+	stockAlertService := newStockAlertService(cfg, db)
+	importService := services.NewImportService(
+		audit.NewProductImportStore(stock.NewProductImportStore(datastore.NewProductImportStore(db), stockAlertService), auditLog),
+		datastore.NewProductVariantStore(db),
+		services.NewMetadataSchemaService(
+			datastore.NewMetadataSchemaStore(db),
+			datastore.NewProductStore(db),
+			datastore.NewCategoryStore(db),
+			datastore.NewOrderStore(db),
+		),
+	)
+
+	ctx := audit.WithActor(context.Background(), model.SystemActor("import"), true)
+	report, err := importService.ImportProducts(ctx, input, opts)
+	if err != nil {
+		log.Fatalf("Import failed: %v", err)
+	}
+	if err := stockAlertService.Wait(context.Background()); err != nil {
+		log.Printf("Warning: %v", err)
+	}
+	for _, rowErr := range report.Errors {
+		fmt.Printf("%s\n", importErrorLine(rowErr))
+	}
+	if report.ErrorsOmitted > 0 {
+		fmt.Printf("... and %d more errors\n", report.ErrorsOmitted)
+	}
+
+	switch {
+	case report.DryRun:
+		log.Printf("Dry run of %d rows: %d would be created, %d updated, %d unchanged, %d failed", report.Rows, report.Created, report.Updated, report.Unchanged, report.Failed)
+	case report.Atomic && report.Failed > 0:
+		log.Printf("Nothing saved: %d of %d rows failed", report.Failed, report.Rows)
+	default:
+		log.Printf("✅ Imported %d rows: %d created, %d updated, %d unchanged, %d failed", report.Rows, report.Created, report.Updated, report.Unchanged, report.Failed)
+	}
+	if report.Failed > 0 {
+		os.Exit(1)
+	}
+}
+
+// importErrorLine formats a failed import row for the terminal
+func importErrorLine(err model.ImportError) string {
+	line := err.Error()
+	if err.SKU != "" {
+		line += " (SKU " + err.SKU + ")"
+	}
+	return line
+}
+
 func seedAdminUser(db *gorm.DB) {
 	var adminUser model.User
 	result := db.Where("username = ?", "admin").First(&adminUser)
@@ -98,35 +306,35 @@ func seedDummyProducts(db *gorm.DB) {
 			ID:       uuid.MustParse("550e8400-e29b-41d4-a716-446655440000"),
 			SKU:      "PROD-001",
 			Name:     "Laptop Computer",
-			Price:    1299.99,
+			Price:    money.MustParse("1299.99 USD"),
 			Metadata: model.JSONB{"brand": "TechCorp", "color": "Silver"},
 		},
 		{
 			ID:       uuid.MustParse("550e8400-e29b-41d4-a716-446655440001"),
 			SKU:      "PROD-002",
 			Name:     "Wireless Mouse",
-			Price:    29.99,
+			Price:    money.MustParse("29.99 USD"),
 			Metadata: model.JSONB{"brand": "TechCorp", "color": "Black"},
 		},
 		{
 			ID:       uuid.MustParse("550e8400-e29b-41d4-a716-446655440002"),
 			SKU:      "PROD-003",
 			Name:     "Mechanical Keyboard",
-			Price:    149.99,
+			Price:    money.MustParse("149.99 USD"),
 			Metadata: model.JSONB{"brand": "TechCorp", "color": "RGB"},
 		},
 		{
 			ID:       uuid.MustParse("550e8400-e29b-41d4-a716-446655440003"),
 			SKU:      "PROD-004",
 			Name:     "USB-C Hub",
-			Price:    79.99,
+			Price:    money.MustParse("79.99 USD"),
 			Metadata: model.JSONB{"brand": "TechCorp", "ports": 7},
 		},
 		{
 			ID:       uuid.MustParse("550e8400-e29b-41d4-a716-446655440004"),
 			SKU:      "PROD-005",
 			Name:     "Monitor Stand",
-			Price:    89.99,
+			Price:    money.MustParse("89.99 USD"),
 			Metadata: model.JSONB{"brand": "TechCorp", "material": "Aluminum"},
 		},
 	}
@@ -144,8 +352,9 @@ func seedDummyProducts(db *gorm.DB) {
 				createdCount++
 				// Create inventory entry with 0 stock
 				inventory := model.Inventory{
-					ProductID: product.ID,
-					Quantity:  0,
+					StockUnitID: product.ID,
+					ProductID:   product.ID,
+					Quantity:    0,
 				}
 				if err := db.Create(&inventory).Error; err != nil {
 					log.Printf("Warning: Failed to create inventory for product %s: %v", product.SKU, err)
@@ -161,40 +370,407 @@ func seedDummyProducts(db *gorm.DB) {
 	}
 }
 
-func startAPIServer(port string, db *gorm.DB) {
+// newPaymentService creates the payment service for the configured provider. Payments are
+// authorized when an order is placed; "none" places orders without payment and returns nil.
+func newPaymentService(cfg *config.Config, db *gorm.DB) services.PaymentService {
+	if cfg.Payment.Provider != payment.FakeProviderName {
+		return nil
+	}
+	if cfg.Payment.WebhookSecret == "" {
+		log.Printf("Warning: PAYMENT_WEBHOOK_SECRET is not set; payment webhooks are signed with an empty key")
+	}
+	return services.NewPaymentService(
+		datastore.NewPaymentStore(db),
+		payment.NewFakeProvider(cfg.Payment.WebhookSecret),
+	)
+}
+
+// newStockAlertService creates the service that opens low-stock alerts as inventory changes. New
+// alerts are logged, and sent to the webhook and email recipients that are configured. Alerts admins
+// acknowledge and resolve are recorded in the audit log.
+func newStockAlertService(cfg *config.Config, db *gorm.DB) services.StockAlertService {
+	notifiers := []types.StockAlertNotifier{notify.NewLogNotifier()}
+	if cfg.StockAlerts.WebhookURL != "" {
+		notifiers = append(notifiers, notify.NewWebhookNotifier(cfg.StockAlerts.WebhookURL, cfg.StockAlerts.WebhookSecret))
+	}
+	if len(cfg.StockAlerts.EmailTo) > 0 {
+		notifiers = append(notifiers, notify.NewEmailNotifier(notify.EmailConfig{
+			From:     cfg.StockAlerts.EmailFrom,
+			To:       cfg.StockAlerts.EmailTo,
+			Addr:     cfg.StockAlerts.SMTPAddr,
+			User:     cfg.StockAlerts.SMTPUser,
+			Password: cfg.StockAlerts.SMTPPassword,
+		}))
+	}
+	alertStore := audit.NewStockAlertStore(datastore.NewStockAlertStore(db), audit.NewLog(datastore.NewAuditStore(db)))
+	return services.NewStockAlertService(alertStore, notify.Multi(notifiers...))
+}
+
+func startAPIServer(configManager *config.Manager, db *gorm.DB) {
+	cfg := configManager.Current()
+	port := cfg.Server.Port
 	fmt.Printf("Starting API server on port %s...\n", port)
 	
 	// Seed admin user and products if they don't exist (idempotent)
 	seedAdminUser(db)
 	seedDummyProducts(db)
 	
-	// Initialize real database stores
-	orderStore := datastore.NewOrderStore(db)
-	inventoryStore := datastore.NewInventoryStore(db)
+	// Initialize real database stores. Stores of admin-managed data, orders, shipments and returns
+	// record the changes admins make through them in the audit log, so every endpoint built on
+	// them is audited. Stock changes are watched for low-stock alerts, whichever service makes them.
+	auditLog := audit.NewLog(datastore.NewAuditStore(db))
+	orderStore := audit.NewOrderStore(datastore.NewOrderStore(db), auditLog)
+	stockAlertService := newStockAlertService(cfg, db)
+	inventoryStore := audit.NewInventoryStore(stock.NewInventoryStore(datastore.NewInventoryStore(db), stockAlertService), auditLog)
 	orderStateLogStore := datastore.NewOrderStateLogStore(db)
-	userStore := datastore.NewUserStore(db)
-	productStore := datastore.NewProductStore(db)
+	userStore := audit.NewUserStore(datastore.NewUserStore(db), auditLog)
+	productStore := audit.NewProductStore(datastore.NewProductStore(db), auditLog)
+	variantStore := audit.NewProductVariantStore(datastore.NewProductVariantStore(db), auditLog)
+	categoryStore := audit.NewCategoryStore(datastore.NewCategoryStore(db), auditLog)
 	fsmValidator := fsm.NewValidator()
+	schemaService := services.NewMetadataSchemaService(
+		audit.NewMetadataSchemaStore(datastore.NewMetadataSchemaStore(db), auditLog),
+		productStore,
+		categoryStore,
+		orderStore,
+	)
+	promotionService := services.NewPromotionService(audit.NewPromotionStore(datastore.NewPromotionStore(db), auditLog), categoryStore)
+	taxRuleStore := audit.NewTaxRuleStore(datastore.NewTaxRuleStore(db), auditLog)
+	taxService := services.NewTaxService(taxRuleStore)
+	shippingZoneStore := audit.NewShippingZoneStore(datastore.NewShippingZoneStore(db), auditLog)
+	shippingService := services.NewShippingService(shippingZoneStore)
+	
+	paymentService := newPaymentService(cfg, db)
 	
+	shipmentStore := audit.NewShipmentStore(datastore.NewShipmentStore(db), auditLog)
 	orderService := services.NewOrderService(
 		orderStore,
 		inventoryStore,
+		productStore,
+		variantStore,
+		orderStateLogStore,
+		fsmValidator,
+		schemaService,
+		promotionService,
+		tax.NewRuleTable(taxRuleStore),
+		paymentService,
+		shipmentStore,
+		shipping.NewTableRate(shippingZoneStore),
+	)
+	// Carriers post tracking events signed with TRACKING_WEBHOOK_SECRET; without it they are not accepted
+	var trackingService services.TrackingService
+	if cfg.Tracking.WebhookSecret != "" {
+		trackingService = services.NewTrackingService(shipmentStore, orderService, cfg.Tracking.WebhookSecret)
+	} else {
+		log.Printf("Warning: TRACKING_WEBHOOK_SECRET is not set; carrier tracking events are disabled")
+	}
+	fulfillmentService := services.NewFulfillmentService(orderStore, inventoryStore, orderService)
+	returnService := services.NewReturnService(
+		audit.NewReturnRequestStore(datastore.NewReturnRequestStore(db), auditLog),
+		inventoryStore,
+		orderService,
+		paymentService,
+	)
+	cartService := services.NewCartService(
+		datastore.NewCartStore(db),
+		productStore,
+		variantStore,
+		inventoryStore,
+		orderService,
+		cfg.Cart.Expiry,
+	)
+
+	// Background workers share one lifecycle and are stopped on shutdown
+	workers := worker.NewGroup()
+
+	// Log level and rate limits are hot-reloaded on SIGHUP or config file change
+	rateLimiter := middleware.NewRateLimiter(cfg.RateLimit.Enabled, cfg.RateLimit.RequestsPerSecond, cfg.RateLimit.Burst)
+	configManager.OnReload(func(cfg *config.Config) {
+		if err := logging.SetLevel(cfg.Logging.Level); err != nil {
+			log.Printf("Warning: %v", err)
+		}
+		rateLimiter.SetLimits(cfg.RateLimit.Enabled, cfg.RateLimit.RequestsPerSecond, cfg.RateLimit.Burst)
+	})
+	workers.Go("config-watcher", configManager.Watch)
+
+	// Large order exports are written to files in the background by whichever API instance claims
+	// them first, so every instance must share the exports directory
+	hostname, _ := os.Hostname()
+	exportService := services.NewExportService(
+		orderStore,
 		orderStateLogStore,
+		datastore.NewExportJobStore(db),
 		fsmValidator,
+		cfg.Exports.Dir,
+		cfg.Exports.Retention,
+		fmt.Sprintf("%s:%d", hostname, os.Getpid()),
 	)
+	workers.Go("exports", exportService.Run)
+
+	// Readiness checks: the instance only receives traffic while all of these pass
+	var draining atomic.Bool
+	checker := health.NewChecker()
+	checker.Register("server", func(ctx context.Context) error {
+		if draining.Load() {
+			return errors.New("shutting down")
+		}
+		return nil
+	})
+	checker.Register("database", func(ctx context.Context) error {
+		return database.Ping(ctx, db)
+	})
+	migrations := database.NewMigrationStatus(db, time.Minute)
+	checker.Register("migrations", func(ctx context.Context) error {
+		pending, err := migrations.Pending(ctx)
+		if err != nil {
+			return err
+		}
+		if len(pending) > 0 {
+			return fmt.Errorf("pending migrations: %s", strings.Join(pending, ", "))
+		}
+		return nil
+	})
+	checker.Register("workers", func(ctx context.Context) error {
+		return workers.Healthy()
+	})
+	
+	corsPolicy := middleware.CORSPolicy{
+		AllowedOrigins:   cfg.CORS.AllowedOrigins,
+		AllowedHeaders:   cfg.CORS.AllowedHeaders,
+		ExposedHeaders:   cfg.CORS.ExposedHeaders,
+		AllowCredentials: cfg.CORS.AllowCredentials,
+		MaxAge:           cfg.CORS.MaxAge,
+	}
 	
 	// Setup router with all stores including product store and database for admin features and metrics
-	router := v1.SetupRouterWithStoresAndDB(orderService, inventoryStore, userStore, productStore, db)
+	router := v1.SetupRouterWithDependencies(v1.Dependencies{
+		OrderService:       orderService,
+		InventoryStore:     inventoryStore,
+		UserStore:          userStore,
+		ProductStore:       productStore,
+		VariantStore:       variantStore,
+		CategoryStore:      categoryStore,
+		SchemaService:      schemaService,
+		PromotionService:   promotionService,
+		TaxService:         taxService,
+		CartService:        cartService,
+		PaymentService:     paymentService,
+		ReturnService:      returnService,
+		TrackingService:    trackingService,
+		ShippingService:    shippingService,
+		FulfillmentService: fulfillmentService,
+		AnalyticsService:   services.NewAnalyticsService(datastore.NewAnalyticsStore(db)),
+		AuditLog:           auditLog,
+		ExportService:      exportService,
+		ImportService:      services.NewImportService(audit.NewProductImportStore(stock.NewProductImportStore(datastore.NewProductImportStore(db), stockAlertService), auditLog), variantStore, schemaService),
+		StockAlertService:  stockAlertService,
+		DB:                 db,
+		Health:             checker,
+		Workers:            workers,
+		RateLimiter:        rateLimiter,
+		CORS:               &corsPolicy,
+	})
 	
 	// Start server - bind to all interfaces to ensure browser connectivity
-	serverAddr := "0.0.0.0:" + port
+	server := &http.Server{
+		Addr:              "0.0.0.0:" + port,
+		Handler:           router,
+		ReadTimeout:       cfg.Server.ReadTimeout,
+		ReadHeaderTimeout: cfg.Server.ReadHeaderTimeout,
+		WriteTimeout:      cfg.Server.WriteTimeout,
+		IdleTimeout:       cfg.Server.IdleTimeout,
+	}
 	fmt.Printf("Server listening on http://localhost:%s\n", port)
-	fmt.Printf("Health check: http://localhost:%s/api/v1/health\n", port)
+	fmt.Printf("Health check: http://localhost:%s/api/v1/health/ready\n", port)
 	fmt.Printf("Products: http://localhost:%s/api/v1/products\n", port)
 	fmt.Printf("✅ Connected to PostgreSQL database\n")
-	
-	if err := http.ListenAndServe(serverAddr, router); err != nil {
-		log.Fatalf("Failed to start server: %v", err)
+
+	serverErr := make(chan error, 1)
+	go func() {
+		serverErr <- server.ListenAndServe()
+	}()
+
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, syscall.SIGINT, syscall.SIGTERM)
+
+	select {
+	case err := <-serverErr:
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatalf("Failed to start server: %v", err)
+		}
+	case sig := <-stop:
+		log.Printf("Received %s, shutting down (timeout %s)...", sig, cfg.Server.ShutdownTimeout)
+	}
+
+	// Fail readiness first so load balancers stop routing new requests here
+	draining.Store(true)
+
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout)
+	defer cancel()
+
+	// Drain in-flight requests, then stop workers, then close the pool they all use
+	if err := server.Shutdown(ctx); err != nil {
+		log.Printf("Warning: HTTP server did not drain cleanly: %v", err)
+	}
+	if err := stockAlertService.Wait(ctx); err != nil {
+		log.Printf("Warning: %v", err)
+	}
+	if err := workers.Stop(ctx); err != nil {
+		log.Printf("Warning: %v", err)
 	}
+	if err := database.Close(db); err != nil {
+		log.Printf("Warning: Failed to close database connections: %v", err)
+	}
+	log.Println("✅ Server stopped")
 }
 
+// startWorker runs the background job scheduler until SIGINT or SIGTERM. Any number of workers
+// can run side by side: each scheduled run of a job happens on one of them.
+func startWorker(configManager *config.Manager, db *gorm.DB) {
+	cfg := configManager.Current()
+	fmt.Println("Starting background worker...")
+
+	// Cancelling goes through the order service, so payments are voided, stock is restored and
+	// promotion redemptions are released as they are for any cancellation. Restored stock can
+	// resolve low-stock alerts.
+	orderStore := datastore.NewOrderStore(db)
+	stockAlertService := newStockAlertService(cfg, db)
+	inventoryStore := stock.NewInventoryStore(datastore.NewInventoryStore(db), stockAlertService)
+	productStore := datastore.NewProductStore(db)
+	variantStore := datastore.NewProductVariantStore(db)
+	shipmentStore := datastore.NewShipmentStore(db)
+	paymentService := newPaymentService(cfg, db)
+	orderService := services.NewOrderService(
+		orderStore,
+		inventoryStore,
+		productStore,
+		variantStore,
+		datastore.NewOrderStateLogStore(db),
+		fsm.NewValidator(),
+		nil,
+		services.NewPromotionService(datastore.NewPromotionStore(db), datastore.NewCategoryStore(db)),
+		nil,
+		paymentService,
+		shipmentStore,
+		nil,
+	)
+	cartService := services.NewCartService(
+		datastore.NewCartStore(db),
+		productStore,
+		variantStore,
+		inventoryStore,
+		orderService,
+		cfg.Cart.Expiry,
+	)
+	jobRunStore := datastore.NewJobRunStore(db)
+
+	hostname, _ := os.Hostname()
+	jobs := scheduler.New(jobRunStore, datastore.NewAdvisoryLocker(db), fmt.Sprintf("%s:%d", hostname, os.Getpid()))
+
+	// Orders left ORDERED with their payment pending or failed hold their stock until cancelled.
+	// Without payments, ORDERED orders are waiting to ship, so there is nothing to cancel.
+	switch {
+	case cfg.Jobs.StaleOrderAge <= 0:
+		log.Println("Warning: jobs.stale_order_age is 0; stale orders are not cancelled")
+	case paymentService == nil:
+		log.Println("payment.provider is none; stale orders are not cancelled")
+	default:
+		schedule, err := scheduler.ParseSchedule(cfg.Jobs.StaleOrderSchedule)
+		if err != nil {
+			log.Fatalf("Invalid jobs.stale_order_schedule: %v", err)
+		}
+		maxAge := cfg.Jobs.StaleOrderAge
+		jobs.Add(scheduler.Job{
+			Name:     "cancel-stale-orders",
+			Schedule: schedule,
+			Run: func(ctx context.Context) (string, error) {
+				cancelled, err := orderService.CancelStaleOrders(ctx, time.Now().Add(-maxAge))
+				return fmt.Sprintf("cancelled %d orders ORDERED for more than %s", cancelled, maxAge), err
+			},
+		})
+	}
+
+	// Abandoned carts and old job runs are deleted; carts never reserved stock. Payment webhooks
+	// and tracking events are remembered so redeliveries apply once; past the event retention
+	// payment history entries keep everything but their event ID, and delivered shipments, which no
+	// later event can change, lose their tracking events.
+	paymentStore := datastore.NewPaymentStore(db)
+	purgeSchedule, err := scheduler.ParseSchedule("@every " + cfg.Cart.PurgeInterval.String())
+	if err != nil {
+		log.Fatalf("Invalid cart.purge_interval: %v", err)
+	}
+	retention, eventRetention := cfg.Jobs.RunRetention, cfg.Jobs.EventRetention
+	jobs.Add(scheduler.Job{
+		Name:     "purge-expired",
+		Schedule: purgeSchedule,
+		Run: func(ctx context.Context) (string, error) {
+			carts, err := cartService.PurgeExpired(ctx)
+			if err != nil {
+				return "", fmt.Errorf("failed to purge expired carts: %w", err)
+			}
+			runs, err := jobRunStore.DeleteFinishedBefore(ctx, time.Now().Add(-retention))
+			if err != nil {
+				return "", fmt.Errorf("purged %d carts, then failed to purge job runs: %w", carts, err)
+			}
+			summary := fmt.Sprintf("purged %d expired carts and %d job runs older than %s", carts, runs, retention)
+			eventsBefore := time.Now().Add(-eventRetention)
+			payments, err := paymentStore.ForgetEventsBefore(ctx, eventsBefore)
+			if err != nil {
+				return summary, fmt.Errorf("failed to purge payment webhook events: %w", err)
+			}
+			tracking, err := shipmentStore.DeleteEventsDeliveredBefore(ctx, eventsBefore)
+			if err != nil {
+				return summary, fmt.Errorf("failed to purge tracking events: %w", err)
+			}
+			return fmt.Sprintf("%s, %d payment webhook events and %d tracking events older than %s", summary, payments, tracking, eventRetention), nil
+		},
+	})
+
+	// Sales are rolled up for analytics after each UTC day ends; analytics read orders directly
+	// for days not rolled up yet
+	if cfg.Jobs.SalesRollupSchedule != "" {
+		schedule, err := scheduler.ParseSchedule(cfg.Jobs.SalesRollupSchedule)
+		if err != nil {
+			log.Fatalf("Invalid jobs.sales_rollup_schedule: %v", err)
+		}
+		analyticsService := services.NewAnalyticsService(datastore.NewAnalyticsStore(db))
+		lookback := cfg.Jobs.SalesRollupLookback
+		jobs.Add(scheduler.Job{
+			Name:     "rollup-sales",
+			Schedule: schedule,
+			Run: func(ctx context.Context) (string, error) {
+				rows, err := analyticsService.RollUp(ctx, time.Now(), lookback)
+				return fmt.Sprintf("wrote %d hourly sales rollups, recomputing the last %s", rows, lookback), err
+			},
+		})
+	}
+
+	workers := worker.NewGroup()
+	configManager.OnReload(func(cfg *config.Config) {
+		if err := logging.SetLevel(cfg.Logging.Level); err != nil {
+			log.Printf("Warning: %v", err)
+		}
+	})
+	workers.Go("config-watcher", configManager.Watch)
+	workers.Go("scheduler", jobs.Run)
+	fmt.Printf("✅ Worker %s:%d running\n", hostname, os.Getpid())
+
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, syscall.SIGINT, syscall.SIGTERM)
+	sig := <-stop
+	log.Printf("Received %s, stopping jobs (timeout %s)...", sig, cfg.Server.ShutdownTimeout)
+
+	// Jobs in progress get the shutdown timeout to return; their runs are recorded either way
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout)
+	defer cancel()
+	if err := workers.Stop(ctx); err != nil {
+		log.Printf("Warning: %v", err)
+	}
+	if err := stockAlertService.Wait(ctx); err != nil {
+		log.Printf("Warning: %v", err)
+	}
+	if err := database.Close(db); err != nil {
+		log.Printf("Warning: Failed to close database connections: %v", err)
+	}
+	log.Println("✅ Worker stopped")
+}
@@ -1,83 +1,496 @@
 package config
 
 import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
 	"github.com/spf13/viper"
+	"oms/server/core/scheduler"
 )
 
 // Config holds all configuration for the application
 type Config struct {
-	Database DatabaseConfig
-	Server   ServerConfig
-	JWT      JWTConfig
-	Logging  LoggingConfig
+	Database    DatabaseConfig    `mapstructure:"database"`
+	Server      ServerConfig      `mapstructure:"server"`
+	JWT         JWTConfig         `mapstructure:"jwt"`
+	Logging     LoggingConfig     `mapstructure:"logging"`
+	CORS        CORSConfig        `mapstructure:"cors"`
+	RateLimit   RateLimitConfig   `mapstructure:"rate_limit"`
+	Cart        CartConfig        `mapstructure:"cart"`
+	Payment     PaymentConfig     `mapstructure:"payment"`
+	Tracking    TrackingConfig    `mapstructure:"tracking"`
+	Jobs        JobsConfig        `mapstructure:"jobs"`
+	Exports     ExportsConfig     `mapstructure:"exports"`
+	StockAlerts StockAlertsConfig `mapstructure:"stock_alerts"`
 }
 
 // DatabaseConfig holds database configuration
 type DatabaseConfig struct {
-	Host     string
-	Port     int
-	User     string
-	Password string
-	Name     string
-	SSLMode  string
+	Host            string        `mapstructure:"host"`
+	Port            int           `mapstructure:"port"`
+	User            string        `mapstructure:"user"`
+	Password        string        `mapstructure:"password"`
+	Name            string        `mapstructure:"name"`
+	SSLMode         string        `mapstructure:"ssl_mode"`
+	MaxOpenConns    int           `mapstructure:"max_open_conns"`
+	MaxIdleConns    int           `mapstructure:"max_idle_conns"`
+	ConnMaxLifetime time.Duration `mapstructure:"conn_max_lifetime"`
+	ConnMaxIdleTime time.Duration `mapstructure:"conn_max_idle_time"`
 }
 
 // ServerConfig holds server configuration
 type ServerConfig struct {
-	Port string
+	Environment       string        `mapstructure:"environment"` // development or production
+	Port              string        `mapstructure:"port"`
+	ReadTimeout       time.Duration `mapstructure:"read_timeout"`
+	ReadHeaderTimeout time.Duration `mapstructure:"read_header_timeout"`
+	WriteTimeout      time.Duration `mapstructure:"write_timeout"`
+	IdleTimeout       time.Duration `mapstructure:"idle_timeout"`
+	ShutdownTimeout   time.Duration `mapstructure:"shutdown_timeout"` // How long to drain in-flight requests on SIGTERM
 }
 
 // JWTConfig holds JWT configuration
 type JWTConfig struct {
-	Secret string
-	Expiry string
+	Secret string        `mapstructure:"secret"`
+	Expiry time.Duration `mapstructure:"expiry"` // Access token TTL
 }
 
 // LoggingConfig holds logging configuration
 type LoggingConfig struct {
-	Level string
-}
-
-// Load loads configuration from environment variables and config files
-func Load() (*Config, error) {
-	viper.SetConfigName(".env")
-	viper.SetConfigType("env")
-	viper.AddConfigPath(".")
-	viper.AutomaticEnv()
-
-	// Set defaults
-	viper.SetDefault("DB_HOST", "localhost")
-	viper.SetDefault("DB_PORT", 5432)
-	viper.SetDefault("DB_USER", "postgres")
-	viper.SetDefault("DB_PASSWORD", "postgres")
-	viper.SetDefault("DB_NAME", "oms_db")
-	viper.SetDefault("DB_SSLMODE", "disable")
-	viper.SetDefault("SERVER_PORT", "8080")
-	viper.SetDefault("LOG_LEVEL", "info")
-
-	if err := viper.ReadInConfig(); err != nil {
-		// Config file not found; use defaults and env vars
-	}
-
-	return &Config{
-		Database: DatabaseConfig{
-			Host:     viper.GetString("DB_HOST"),
-			Port:     viper.GetInt("DB_PORT"),
-			User:     viper.GetString("DB_USER"),
-			Password: viper.GetString("DB_PASSWORD"),
-			Name:     viper.GetString("DB_NAME"),
-			SSLMode:  viper.GetString("DB_SSLMODE"),
-		},
-		Server: ServerConfig{
-			Port: viper.GetString("SERVER_PORT"),
-		},
-		JWT: JWTConfig{
-			Secret: viper.GetString("JWT_SECRET"),
-			Expiry: viper.GetString("JWT_EXPIRY"),
-		},
-		Logging: LoggingConfig{
-			Level: viper.GetString("LOG_LEVEL"),
-		},
-	}, nil
+	Level  string `mapstructure:"level"`  // debug, info, warn or error (hot-reloadable)
+	Format string `mapstructure:"format"` // text or json
+}
+
+// CORSConfig holds cross-origin resource sharing configuration
+type CORSConfig struct {
+	AllowedOrigins   []string      `mapstructure:"allowed_origins"` // Exact origins, "*" or wildcard subdomains (https://*.example.com)
+	AllowedHeaders   []string      `mapstructure:"allowed_headers"`
+	ExposedHeaders   []string      `mapstructure:"exposed_headers"`
+	AllowCredentials bool          `mapstructure:"allow_credentials"`
+	MaxAge           time.Duration `mapstructure:"max_age"`
+}
+
+// RateLimitConfig holds per-client request rate limiting configuration (hot-reloadable)
+type RateLimitConfig struct {
+	Enabled           bool    `mapstructure:"enabled"`
+	RequestsPerSecond float64 `mapstructure:"requests_per_second"`
+	Burst             int     `mapstructure:"burst"`
+}
+
+// CartConfig holds shopping cart configuration
+type CartConfig struct {
+	Expiry        time.Duration `mapstructure:"expiry"`         // Carts not changed for this long are abandoned
+	PurgeInterval time.Duration `mapstructure:"purge_interval"` // How often the worker deletes abandoned carts
+}
+
+// PaymentConfig holds payment provider configuration
+type PaymentConfig struct {
+	Provider      string `mapstructure:"provider"`       // "fake" for local use, or "none" to take orders without payment
+	WebhookSecret string `mapstructure:"webhook_secret"` // Key provider webhooks are signed with
+}
+
+// TrackingConfig holds carrier tracking configuration
+type TrackingConfig struct {
+	WebhookSecret string `mapstructure:"webhook_secret"` // Key tracking events are signed with; empty disables them
+}
+
+// JobsConfig holds the schedules of the background jobs run by the --worker process
+type JobsConfig struct {
+	StaleOrderAge       time.Duration `mapstructure:"stale_order_age"`       // ORDERED orders older than this are cancelled; 0 disables the job
+	StaleOrderSchedule  string        `mapstructure:"stale_order_schedule"`  // Cron expression or @every <duration>
+	RunRetention        time.Duration `mapstructure:"run_retention"`         // How long job run history is kept
+	EventRetention      time.Duration `mapstructure:"event_retention"`       // How long processed payment webhooks and tracking events are remembered
+	SalesRollupSchedule string        `mapstructure:"sales_rollup_schedule"` // When sales are rolled up for analytics; empty disables rollups
+	SalesRollupLookback time.Duration `mapstructure:"sales_rollup_lookback"` // Rolled up days this recent are recomputed, to reflect later cancellations
+}
+
+// ExportsConfig holds the configuration of the export files written in the background by the API
+type ExportsConfig struct {
+	Dir       string        `mapstructure:"dir"`       // Where export files are written; shared by every API instance
+	Retention time.Duration `mapstructure:"retention"` // How long export files can be downloaded before they are deleted
+}
+
+// StockAlertsConfig holds where new low-stock alerts are sent besides the log
+type StockAlertsConfig struct {
+	WebhookURL    string   `mapstructure:"webhook_url"`    // Posted each new alert as JSON; empty disables the webhook
+	WebhookSecret string   `mapstructure:"webhook_secret"` // Key the webhook's X-Signature is an HMAC-SHA256 with; empty sends it unsigned
+	EmailTo       []string `mapstructure:"email_to"`       // Mailed each new alert; empty disables email
+	EmailFrom     string   `mapstructure:"email_from"`
+	SMTPAddr      string   `mapstructure:"smtp_addr"` // host:port of the mail server
+	SMTPUser      string   `mapstructure:"smtp_user"` // Empty sends without authenticating
+	SMTPPassword  string   `mapstructure:"smtp_password"`
+}
+
+// Options controls where Load reads configuration from.
+// Sources are layered: defaults, then the YAML file, then environment, then Flags.
+type Options struct {
+	File  string            // Optional YAML file; falls back to the CONFIG_FILE environment variable
+	Flags map[string]string // Command-line overrides keyed by setting key (e.g. "server.port")
+}
+
+// setting describes one configuration key, its environment variable and default
+type setting struct {
+	key string
+	env string
+	def interface{}
+}
+
+// settings is the single source of truth for every supported key.
+// Every environment variable can also be read from a file by appending _FILE
+// to its name (e.g. DB_PASSWORD_FILE), which is how secrets are mounted.
+var settings = []setting{
+	{key: "server.environment", env: "APP_ENV", def: "development"},
+	{key: "server.port", env: "SERVER_PORT", def: "8080"},
+	{key: "server.read_timeout", env: "SERVER_READ_TIMEOUT", def: "15s"},
+	{key: "server.read_header_timeout", env: "SERVER_READ_HEADER_TIMEOUT", def: "5s"},
+	{key: "server.write_timeout", env: "SERVER_WRITE_TIMEOUT", def: "30s"},
+	{key: "server.idle_timeout", env: "SERVER_IDLE_TIMEOUT", def: "60s"},
+	{key: "server.shutdown_timeout", env: "SERVER_SHUTDOWN_TIMEOUT", def: "30s"},
+
+	{key: "database.host", env: "DB_HOST", def: "localhost"},
+	{key: "database.port", env: "DB_PORT", def: 5432},
+	{key: "database.user", env: "DB_USER", def: "postgres"},
+	{key: "database.password", env: "DB_PASSWORD", def: "postgres"},
+	{key: "database.name", env: "DB_NAME", def: "oms_db"},
+	{key: "database.ssl_mode", env: "DB_SSLMODE", def: "disable"},
+	{key: "database.max_open_conns", env: "DB_MAX_OPEN_CONNS", def: 100},
+	{key: "database.max_idle_conns", env: "DB_MAX_IDLE_CONNS", def: 10},
+	{key: "database.conn_max_lifetime", env: "DB_CONN_MAX_LIFETIME", def: "1h"},
+	{key: "database.conn_max_idle_time", env: "DB_CONN_MAX_IDLE_TIME", def: "10m"},
+
+	{key: "jwt.secret", env: "JWT_SECRET", def: ""},
+	{key: "jwt.expiry", env: "JWT_EXPIRY", def: "24h"},
+
+	{key: "logging.level", env: "LOG_LEVEL", def: "info"},
+	{key: "logging.format", env: "LOG_FORMAT", def: "text"},
+
+	{key: "cors.allowed_origins", env: "CORS_ALLOWED_ORIGINS", def: []string{"*"}},
+	{key: "cors.allowed_headers", env: "CORS_ALLOWED_HEADERS", def: []string{"Content-Type", "Authorization", "X-Cart-Token", "X-Request-ID"}},
+	{key: "cors.exposed_headers", env: "CORS_EXPOSED_HEADERS", def: []string{"X-Next-Cursor", "X-Cart-Token", "X-Request-ID"}},
+	{key: "cors.allow_credentials", env: "CORS_ALLOW_CREDENTIALS", def: false},
+	{key: "cors.max_age", env: "CORS_MAX_AGE", def: "1h"},
+
+	{key: "rate_limit.enabled", env: "RATE_LIMIT_ENABLED", def: true},
+	{key: "rate_limit.requests_per_second", env: "RATE_LIMIT_RPS", def: 20.0},
+	{key: "rate_limit.burst", env: "RATE_LIMIT_BURST", def: 40},
+
+	{key: "cart.expiry", env: "CART_EXPIRY", def: "168h"},
+	{key: "cart.purge_interval", env: "CART_PURGE_INTERVAL", def: "1h"},
+
+	{key: "payment.provider", env: "PAYMENT_PROVIDER", def: "fake"},
+	{key: "payment.webhook_secret", env: "PAYMENT_WEBHOOK_SECRET", def: ""},
+
+	{key: "tracking.webhook_secret", env: "TRACKING_WEBHOOK_SECRET", def: ""},
+
+	{key: "jobs.stale_order_age", env: "JOBS_STALE_ORDER_AGE", def: "72h"},
+	{key: "jobs.stale_order_schedule", env: "JOBS_STALE_ORDER_SCHEDULE", def: "*/15 * * * *"},
+	{key: "jobs.run_retention", env: "JOBS_RUN_RETENTION", def: "720h"},
+	{key: "jobs.event_retention", env: "JOBS_EVENT_RETENTION", def: "2160h"},
+	{key: "jobs.sales_rollup_schedule", env: "JOBS_SALES_ROLLUP_SCHEDULE", def: "30 0 * * *"},
+	{key: "jobs.sales_rollup_lookback", env: "JOBS_SALES_ROLLUP_LOOKBACK", def: "168h"},
+
+	{key: "exports.dir", env: "EXPORTS_DIR", def: "exports"},
+	{key: "exports.retention", env: "EXPORTS_RETENTION", def: "72h"},
+
+	{key: "stock_alerts.webhook_url", env: "STOCK_ALERTS_WEBHOOK_URL", def: ""},
+	{key: "stock_alerts.webhook_secret", env: "STOCK_ALERTS_WEBHOOK_SECRET", def: ""},
+	{key: "stock_alerts.email_to", env: "STOCK_ALERTS_EMAIL_TO", def: []string{}},
+	{key: "stock_alerts.email_from", env: "STOCK_ALERTS_EMAIL_FROM", def: ""},
+	{key: "stock_alerts.smtp_addr", env: "STOCK_ALERTS_SMTP_ADDR", def: ""},
+	{key: "stock_alerts.smtp_user", env: "STOCK_ALERTS_SMTP_USER", def: ""},
+	{key: "stock_alerts.smtp_password", env: "STOCK_ALERTS_SMTP_PASSWORD", def: ""},
 }
 
+// Load loads configuration from defaults, an optional YAML file, environment
+// variables (including a legacy .env file) and flag overrides, then validates it
+func Load(opts Options) (*Config, error) {
+	v := viper.New()
+	for _, s := range settings {
+		v.SetDefault(s.key, s.def)
+	}
+
+	env, err := newEnvSource()
+	if err != nil {
+		return nil, err
+	}
+
+	file := opts.File
+	if file == "" {
+		file, _ = env.lookup("CONFIG_FILE")
+	}
+	if file != "" {
+		v.SetConfigFile(file)
+		v.SetConfigType("yaml")
+		if err := v.ReadInConfig(); err != nil {
+			return nil, fmt.Errorf("failed to read config file %s: %w", file, err)
+		}
+	}
+
+	for _, s := range settings {
+		value, ok, err := env.resolve(s.env)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			v.Set(s.key, value)
+		}
+	}
+
+	for key, value := range opts.Flags {
+		if !isKnownKey(key) {
+			return nil, fmt.Errorf("unknown configuration key for flag override: %s", key)
+		}
+		v.Set(key, value)
+	}
+
+	var cfg Config
+	if err := v.Unmarshal(&cfg); err != nil {
+		return nil, fmt.Errorf("failed to decode configuration: %w", err)
+	}
+	cfg.CORS.AllowedOrigins = splitList(cfg.CORS.AllowedOrigins)
+	cfg.CORS.AllowedHeaders = splitList(cfg.CORS.AllowedHeaders)
+	cfg.CORS.ExposedHeaders = splitList(cfg.CORS.ExposedHeaders)
+	cfg.StockAlerts.EmailTo = splitList(cfg.StockAlerts.EmailTo)
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return &cfg, nil
+}
+
+// Validate checks the configuration and reports every problem at once
+func (c *Config) Validate() error {
+	var errs []error
+	fail := func(key, format string, args ...interface{}) {
+		errs = append(errs, fmt.Errorf("%s: %s", key, fmt.Sprintf(format, args...)))
+	}
+
+	if c.Server.Environment != "development" && c.Server.Environment != "production" {
+		fail("server.environment", "must be development or production, got %q", c.Server.Environment)
+	}
+	if port, err := strconv.Atoi(c.Server.Port); err != nil || port < 1 || port > 65535 {
+		fail("server.port", "must be a port number between 1 and 65535, got %q", c.Server.Port)
+	}
+	for _, d := range []struct {
+		key   string
+		value time.Duration
+	}{
+		{"server.read_timeout", c.Server.ReadTimeout},
+		{"server.read_header_timeout", c.Server.ReadHeaderTimeout},
+		{"server.write_timeout", c.Server.WriteTimeout},
+		{"server.idle_timeout", c.Server.IdleTimeout},
+		{"server.shutdown_timeout", c.Server.ShutdownTimeout},
+		{"jwt.expiry", c.JWT.Expiry},
+		{"cart.expiry", c.Cart.Expiry},
+		{"cart.purge_interval", c.Cart.PurgeInterval},
+		{"jobs.run_retention", c.Jobs.RunRetention},
+		{"jobs.event_retention", c.Jobs.EventRetention},
+		{"exports.retention", c.Exports.Retention},
+	} {
+		if d.value <= 0 {
+			fail(d.key, "must be a positive duration, got %s", d.value)
+		}
+	}
+
+	if c.Jobs.StaleOrderAge < 0 {
+		fail("jobs.stale_order_age", "cannot be negative (use 0 to disable)")
+	}
+	if _, err := scheduler.ParseSchedule(c.Jobs.StaleOrderSchedule); err != nil {
+		fail("jobs.stale_order_schedule", "%v", err)
+	}
+	if c.Jobs.SalesRollupSchedule != "" {
+		if _, err := scheduler.ParseSchedule(c.Jobs.SalesRollupSchedule); err != nil {
+			fail("jobs.sales_rollup_schedule", "%v", err)
+		}
+	}
+	if c.Jobs.SalesRollupLookback < 0 {
+		fail("jobs.sales_rollup_lookback", "cannot be negative")
+	}
+	if c.Exports.Dir == "" {
+		fail("exports.dir", "is required")
+	}
+	if url := c.StockAlerts.WebhookURL; url != "" && !strings.HasPrefix(url, "http://") && !strings.HasPrefix(url, "https://") {
+		fail("stock_alerts.webhook_url", "must start with http:// or https://, got %q", url)
+	}
+	if len(c.StockAlerts.EmailTo) > 0 {
+		if c.StockAlerts.EmailFrom == "" {
+			fail("stock_alerts.email_from", "is required to email alerts")
+		}
+		if c.StockAlerts.SMTPAddr == "" {
+			fail("stock_alerts.smtp_addr", "is required to email alerts")
+		}
+	}
+
+	if c.Database.Host == "" {
+		fail("database.host", "is required")
+	}
+	if c.Database.Port <= 0 || c.Database.Port > 65535 {
+		fail("database.port", "must be between 1 and 65535, got %d", c.Database.Port)
+	}
+	if c.Database.Name == "" {
+		fail("database.name", "is required")
+	}
+	switch c.Database.SSLMode {
+	case "disable", "allow", "prefer", "require", "verify-ca", "verify-full":
+	default:
+		fail("database.ssl_mode", "unsupported value %q", c.Database.SSLMode)
+	}
+	if c.Database.MaxOpenConns <= 0 {
+		fail("database.max_open_conns", "must be greater than 0, got %d", c.Database.MaxOpenConns)
+	}
+	if c.Database.MaxIdleConns < 0 || c.Database.MaxIdleConns > c.Database.MaxOpenConns {
+		fail("database.max_idle_conns", "must be between 0 and max_open_conns (%d), got %d", c.Database.MaxOpenConns, c.Database.MaxIdleConns)
+	}
+	if c.Database.ConnMaxLifetime < 0 || c.Database.ConnMaxIdleTime < 0 {
+		fail("database.conn_max_lifetime", "durations cannot be negative")
+	}
+
+	if c.Server.Environment == "production" && len(c.JWT.Secret) < 32 {
+		fail("jwt.secret", "must be at least 32 characters in production (set JWT_SECRET or JWT_SECRET_FILE)")
+	}
+
+	switch c.Payment.Provider {
+	case "none":
+	case "fake":
+		if c.Server.Environment == "production" {
+			fail("payment.provider", "the fake provider approves every payment and cannot be used in production")
+		}
+	default:
+		fail("payment.provider", "must be fake or none, got %q", c.Payment.Provider)
+	}
+
+	switch c.Logging.Level {
+	case "debug", "info", "warn", "error":
+	default:
+		fail("logging.level", "must be one of debug, info, warn, error, got %q", c.Logging.Level)
+	}
+	if c.Logging.Format != "text" && c.Logging.Format != "json" {
+		fail("logging.format", "must be text or json, got %q", c.Logging.Format)
+	}
+
+	if len(c.CORS.AllowedOrigins) == 0 {
+		fail("cors.allowed_origins", "must list at least one origin (use * to allow any)")
+	}
+	for _, origin := range c.CORS.AllowedOrigins {
+		if origin == "*" {
+			if c.CORS.AllowCredentials {
+				fail("cors.allowed_origins", "* cannot be combined with allow_credentials; list the origins explicitly")
+			}
+			continue
+		}
+		if !strings.HasPrefix(origin, "http://") && !strings.HasPrefix(origin, "https://") {
+			fail("cors.allowed_origins", "origin %q must start with http:// or https://", origin)
+		} else if strings.Count(origin, "*") > 1 || (strings.Contains(origin, "*") && !strings.Contains(origin, "://*.")) {
+			fail("cors.allowed_origins", "origin %q may only use a wildcard as the leftmost subdomain (https://*.example.com)", origin)
+		}
+	}
+	if c.CORS.MaxAge < 0 {
+		fail("cors.max_age", "cannot be negative")
+	}
+
+	if c.RateLimit.Enabled {
+		if c.RateLimit.RequestsPerSecond <= 0 {
+			fail("rate_limit.requests_per_second", "must be greater than 0, got %v", c.RateLimit.RequestsPerSecond)
+		}
+		if c.RateLimit.Burst < 1 {
+			fail("rate_limit.burst", "must be at least 1, got %d", c.RateLimit.Burst)
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("invalid configuration: %w", errors.Join(errs...))
+	}
+	return nil
+}
+
+// envSource resolves environment variables, falling back to a local .env file
+type envSource struct {
+	dotenv *viper.Viper
+}
+
+func newEnvSource() (*envSource, error) {
+	dotenv := viper.New()
+	dotenv.SetConfigFile(".env")
+	dotenv.SetConfigType("env")
+	if err := dotenv.ReadInConfig(); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("failed to read .env file: %w", err)
+	}
+	return &envSource{dotenv: dotenv}, nil
+}
+
+// lookup returns the raw value of an environment variable
+func (e *envSource) lookup(name string) (string, bool) {
+	if value, ok := os.LookupEnv(name); ok {
+		return value, true
+	}
+	if e.dotenv.IsSet(name) {
+		return e.dotenv.GetString(name), true
+	}
+	return "", false
+}
+
+// resolve returns the value of name, or the trimmed contents of the file named by name_FILE
+func (e *envSource) resolve(name string) (string, bool, error) {
+	if value, ok := e.lookup(name); ok {
+		return value, true, nil
+	}
+	path, ok := e.lookup(name + "_FILE")
+	if !ok {
+		return "", false, nil
+	}
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		return "", false, fmt.Errorf("failed to read %s_FILE: %w", name, err)
+	}
+	return strings.TrimSpace(string(contents)), true, nil
+}
+
+// Redacted returns a copy of the configuration with secrets masked, safe for logging
+func (c *Config) Redacted() Config {
+	redacted := *c
+	if redacted.Database.Password != "" {
+		redacted.Database.Password = "******"
+	}
+	if redacted.JWT.Secret != "" {
+		redacted.JWT.Secret = "******"
+	}
+	if redacted.Payment.WebhookSecret != "" {
+		redacted.Payment.WebhookSecret = "******"
+	}
+	if redacted.Tracking.WebhookSecret != "" {
+		redacted.Tracking.WebhookSecret = "******"
+	}
+	if redacted.StockAlerts.WebhookSecret != "" {
+		redacted.StockAlerts.WebhookSecret = "******"
+	}
+	if redacted.StockAlerts.SMTPPassword != "" {
+		redacted.StockAlerts.SMTPPassword = "******"
+	}
+	return redacted
+}
+
+func isKnownKey(key string) bool {
+	for _, s := range settings {
+		if s.key == key {
+			return true
+		}
+	}
+	return false
+}
+
+// splitList normalises list values that may arrive as one comma-separated string
+func splitList(values []string) []string {
+	var out []string
+	for _, value := range values {
+		for _, part := range strings.Split(value, ",") {
+			if part = strings.TrimSpace(part); part != "" {
+				out = append(out, part)
+			}
+		}
+	}
+	return out
+}
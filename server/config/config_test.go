@@ -0,0 +1,222 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// isolate unsets every variable Load reads and moves to an empty directory, so neither the
+// environment nor a .env file next to the package leaks into the test
+func isolate(t *testing.T) {
+	t.Helper()
+	names := []string{"CONFIG_FILE", "CONFIG_FILE_FILE"}
+	for _, s := range settings {
+		names = append(names, s.env, s.env+"_FILE")
+	}
+	for _, name := range names {
+		t.Setenv(name, "") // Restores the original value after the test
+		os.Unsetenv(name)
+	}
+
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(t.TempDir()); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chdir(wd) })
+}
+
+func writeFile(t *testing.T, name, contents string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(contents), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadDefaults(t *testing.T) {
+	isolate(t)
+
+	cfg, err := Load(Options{})
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if cfg.Server.Environment != "development" || cfg.Server.Port != "8080" {
+		t.Errorf("server = %s on %s, want development on 8080", cfg.Server.Environment, cfg.Server.Port)
+	}
+	if cfg.Jobs.StaleOrderAge != 72*time.Hour {
+		t.Errorf("jobs.stale_order_age = %s, want 72h", cfg.Jobs.StaleOrderAge)
+	}
+	if got := strings.Join(cfg.CORS.AllowedOrigins, ","); got != "*" {
+		t.Errorf("cors.allowed_origins = %q, want *", got)
+	}
+}
+
+func TestLoadPrecedence(t *testing.T) {
+	file := `
+server:
+  port: "9000"
+logging:
+  level: debug
+jobs:
+  stale_order_age: 24h
+`
+	tests := []struct {
+		name     string
+		env      map[string]string
+		flags    map[string]string
+		wantPort string
+	}{
+		{"file over defaults", nil, nil, "9000"},
+		{"environment over file", map[string]string{"SERVER_PORT": "9100"}, nil, "9100"},
+		{"flags over environment", map[string]string{"SERVER_PORT": "9100"}, map[string]string{"server.port": "9200"}, "9200"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			isolate(t)
+			for name, value := range tt.env {
+				t.Setenv(name, value)
+			}
+
+			cfg, err := Load(Options{File: writeFile(t, "config.yaml", file), Flags: tt.flags})
+			if err != nil {
+				t.Fatalf("Load: %v", err)
+			}
+			if cfg.Server.Port != tt.wantPort {
+				t.Errorf("server.port = %q, want %q", cfg.Server.Port, tt.wantPort)
+			}
+			// Keys no later layer sets keep the file's value, and keys nothing sets keep the default
+			if cfg.Logging.Level != "debug" || cfg.Jobs.StaleOrderAge != 24*time.Hour {
+				t.Errorf("file values = %s, %s, want debug, 24h", cfg.Logging.Level, cfg.Jobs.StaleOrderAge)
+			}
+			if cfg.Logging.Format != "text" {
+				t.Errorf("logging.format = %q, want the default text", cfg.Logging.Format)
+			}
+		})
+	}
+}
+
+func TestLoadFileFromEnvironment(t *testing.T) {
+	isolate(t)
+	t.Setenv("CONFIG_FILE", writeFile(t, "config.yaml", "server:\n  port: \"9300\"\n"))
+
+	cfg, err := Load(Options{})
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if cfg.Server.Port != "9300" {
+		t.Errorf("server.port = %q, want 9300 from CONFIG_FILE", cfg.Server.Port)
+	}
+
+	if _, err := Load(Options{File: filepath.Join(t.TempDir(), "missing.yaml")}); err == nil {
+		t.Error("Load with a missing config file succeeded, want an error")
+	}
+}
+
+func TestLoadSecretFiles(t *testing.T) {
+	t.Run("read from the file", func(t *testing.T) {
+		isolate(t)
+		t.Setenv("DB_PASSWORD_FILE", writeFile(t, "db_password", "  s3cret\n"))
+
+		cfg, err := Load(Options{})
+		if err != nil {
+			t.Fatalf("Load: %v", err)
+		}
+		if cfg.Database.Password != "s3cret" {
+			t.Errorf("database.password = %q, want the trimmed file contents", cfg.Database.Password)
+		}
+	})
+
+	t.Run("variable over file", func(t *testing.T) {
+		isolate(t)
+		t.Setenv("DB_PASSWORD", "direct")
+		t.Setenv("DB_PASSWORD_FILE", writeFile(t, "db_password", "from-file"))
+
+		cfg, err := Load(Options{})
+		if err != nil {
+			t.Fatalf("Load: %v", err)
+		}
+		if cfg.Database.Password != "direct" {
+			t.Errorf("database.password = %q, want the variable's value", cfg.Database.Password)
+		}
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		isolate(t)
+		t.Setenv("JWT_SECRET_FILE", filepath.Join(t.TempDir(), "missing"))
+
+		_, err := Load(Options{})
+		if err == nil || !strings.Contains(err.Error(), "JWT_SECRET_FILE") {
+			t.Errorf("Load error = %v, want one naming JWT_SECRET_FILE", err)
+		}
+	})
+}
+
+func TestLoadUnknownFlag(t *testing.T) {
+	isolate(t)
+
+	if _, err := Load(Options{Flags: map[string]string{"server.prot": "9000"}}); err == nil {
+		t.Error("Load with an unknown flag key succeeded, want an error")
+	}
+}
+
+func TestValidateEnvironmentAndProvider(t *testing.T) {
+	secret := strings.Repeat("x", 32)
+	tests := []struct {
+		name        string
+		environment string
+		provider    string
+		jwtSecret   string
+		wantKey     string // Empty when the configuration is valid
+	}{
+		{"fake provider in development", "development", "fake", "", ""},
+		{"no provider in development", "development", "none", "", ""},
+		{"no provider in production", "production", "none", secret, ""},
+		{"fake provider in production", "production", "fake", secret, "payment.provider"},
+		{"unknown provider", "development", "stripe", "", "payment.provider"},
+		{"unknown environment", "staging", "none", "", "server.environment"},
+		{"short secret in production", "production", "none", "short", "jwt.secret"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			isolate(t)
+			t.Setenv("APP_ENV", tt.environment)
+			t.Setenv("PAYMENT_PROVIDER", tt.provider)
+			t.Setenv("JWT_SECRET", tt.jwtSecret)
+
+			_, err := Load(Options{})
+			if tt.wantKey == "" {
+				if err != nil {
+					t.Errorf("Load: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantKey+":") {
+				t.Errorf("Load error = %v, want one for %s", err, tt.wantKey)
+			}
+		})
+	}
+}
+
+func TestValidateReportsEveryProblem(t *testing.T) {
+	isolate(t)
+	t.Setenv("SERVER_PORT", "0")
+	t.Setenv("LOG_LEVEL", "loud")
+	t.Setenv("JOBS_STALE_ORDER_SCHEDULE", "@yearly")
+
+	_, err := Load(Options{})
+	if err == nil {
+		t.Fatal("Load succeeded, want an error")
+	}
+	for _, key := range []string{"server.port", "logging.level", "jobs.stale_order_schedule"} {
+		if !strings.Contains(err.Error(), key+":") {
+			t.Errorf("Load error = %v, want it to report %s", err, key)
+		}
+	}
+}
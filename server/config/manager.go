@@ -0,0 +1,137 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"syscall"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// Manager holds the live configuration and hot-reloads its safe fields
+// (log level and rate limits) on SIGHUP or when the config file changes.
+// Every other field requires a restart; changes to them are logged and ignored.
+type Manager struct {
+	opts Options
+
+	mu        sync.RWMutex
+	current   *Config
+	listeners []func(cfg *Config)
+}
+
+// NewManager loads and validates the initial configuration
+func NewManager(opts Options) (*Manager, error) {
+	if opts.File == "" {
+		opts.File = os.Getenv("CONFIG_FILE")
+	}
+	cfg, err := Load(opts)
+	if err != nil {
+		return nil, err
+	}
+	return &Manager{opts: opts, current: cfg}, nil
+}
+
+// Current returns the live configuration. Callers must not modify it.
+func (m *Manager) Current() *Config {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.current
+}
+
+// OnReload registers fn to be called with the new configuration after every successful reload
+func (m *Manager) OnReload(fn func(cfg *Config)) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.listeners = append(m.listeners, fn)
+}
+
+// Reload re-reads every source and applies the hot-reloadable fields.
+// On error the previous configuration stays in effect.
+func (m *Manager) Reload() error {
+	fresh, err := Load(m.opts)
+	if err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	next := *m.current
+	next.Logging.Level = fresh.Logging.Level
+	next.RateLimit = fresh.RateLimit
+	if !reflect.DeepEqual(withoutReloadable(&next), withoutReloadable(fresh)) {
+		log.Println("Warning: configuration changes other than logging.level and rate_limit require a restart and were not applied")
+	}
+	m.current = &next
+	listeners := append([]func(cfg *Config){}, m.listeners...)
+	m.mu.Unlock()
+
+	for _, listener := range listeners {
+		listener(&next)
+	}
+	return nil
+}
+
+// Watch reloads the configuration on SIGHUP and on writes to the config file
+// until ctx is cancelled. It is meant to run as a background worker.
+func (m *Manager) Watch(ctx context.Context) error {
+	hangup := make(chan os.Signal, 1)
+	signal.Notify(hangup, syscall.SIGHUP)
+	defer signal.Stop(hangup)
+
+	var events chan fsnotify.Event
+	var watchErrors chan error
+	var file string
+	if m.opts.File != "" {
+		watcher, err := fsnotify.NewWatcher()
+		if err != nil {
+			return fmt.Errorf("failed to create config file watcher: %w", err)
+		}
+		defer watcher.Close()
+
+		// Watch the directory rather than the file so atomic renames by
+		// editors and mounted ConfigMaps are picked up too
+		file = filepath.Clean(m.opts.File)
+		if err := watcher.Add(filepath.Dir(file)); err != nil {
+			return fmt.Errorf("failed to watch config file %s: %w", file, err)
+		}
+		events = watcher.Events
+		watchErrors = watcher.Errors
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-hangup:
+			m.reload("SIGHUP")
+		case event := <-events:
+			if filepath.Clean(event.Name) == file && event.Op&(fsnotify.Write|fsnotify.Create) != 0 {
+				m.reload("config file change")
+			}
+		case err := <-watchErrors:
+			log.Printf("Warning: config file watcher error: %v", err)
+		}
+	}
+}
+
+// reload runs Reload and logs the outcome
+func (m *Manager) reload(trigger string) {
+	if err := m.Reload(); err != nil {
+		log.Printf("Warning: configuration reload after %s failed, keeping previous settings: %v", trigger, err)
+		return
+	}
+	log.Printf("✅ Configuration reloaded after %s", trigger)
+}
+
+// withoutReloadable returns a copy of cfg with the hot-reloadable fields cleared
+func withoutReloadable(cfg *Config) Config {
+	stripped := *cfg
+	stripped.Logging.Level = ""
+	stripped.RateLimit = RateLimitConfig{}
+	return stripped
+}
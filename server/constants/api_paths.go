@@ -8,5 +8,7 @@ const (
 	OrdersPath      = "/orders"
 	OrderByIDPath   = "/orders/{orderId}"
 	HealthCheckPath = "/health"
+	LivenessPath    = "/health/live"
+	ReadinessPath   = "/health/ready"
 )
 
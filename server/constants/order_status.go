@@ -4,9 +4,15 @@ import "oms/server/core/model"
 
 // OrderStatus constants
 var (
-	OrderStatusOrdered   = model.OrderStatusOrdered
-	OrderStatusShipped   = model.OrderStatusShipped
-	OrderStatusDelivered = model.OrderStatusDelivered
-	OrderStatusCancelled = model.OrderStatusCancelled
-)
+	OrderStatusOrdered          = model.OrderStatusOrdered
+	OrderStatusPaid             = model.OrderStatusPaid
+	OrderStatusPicking          = model.OrderStatusPicking
+	OrderStatusPartiallyShipped = model.OrderStatusPartiallyShipped
+	OrderStatusShipped          = model.OrderStatusShipped
+	OrderStatusDelivered        = model.OrderStatusDelivered
+	OrderStatusCancelled        = model.OrderStatusCancelled
 
+	OrderStatusReturnRequested   = model.OrderStatusReturnRequested
+	OrderStatusPartiallyReturned = model.OrderStatusPartiallyReturned
+	OrderStatusReturned          = model.OrderStatusReturned
+)
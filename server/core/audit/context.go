@@ -0,0 +1,55 @@
+// Package audit records the changes admins make, and carries what audit trails record about the
+// request behind a change, from the HTTP middleware down to the services that record it.
+package audit
+
+import (
+	"context"
+
+	"oms/server/core/model"
+)
+
+// Request identifies the API request behind a change
+type Request struct {
+	ID       string // The X-Request-ID the request came with, or one generated for it
+	ClientIP string
+}
+
+type requestKey struct{}
+
+// WithRequest returns a copy of ctx carrying req
+func WithRequest(ctx context.Context, req Request) context.Context {
+	return context.WithValue(ctx, requestKey{}, req)
+}
+
+// RequestFrom returns the request ctx carries. Changes made outside an API request, such as by
+// background jobs, have none and get the zero Request.
+func RequestFrom(ctx context.Context) Request {
+	req, _ := ctx.Value(requestKey{}).(Request)
+	return req
+}
+
+type actorKey struct{}
+
+// principal is the signed-in actor behind a request
+type principal struct {
+	actor model.Actor
+	admin bool
+}
+
+// WithActor returns a copy of ctx carrying the actor making the request; admin marks actors whose
+// changes are recorded in the audit log
+func WithActor(ctx context.Context, actor model.Actor, admin bool) context.Context {
+	return context.WithValue(ctx, actorKey{}, principal{actor: actor, admin: admin})
+}
+
+// ActorFrom returns the actor ctx carries, if any, and whether it is an admin
+func ActorFrom(ctx context.Context) (actor model.Actor, admin bool, ok bool) {
+	p, ok := ctx.Value(actorKey{}).(principal)
+	return p.actor, p.admin, ok
+}
+
+// Audited reports whether changes made with ctx are recorded: those made by an admin
+func Audited(ctx context.Context) bool {
+	_, admin, _ := ActorFrom(ctx)
+	return admin
+}
@@ -0,0 +1,122 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"reflect"
+
+	"oms/server/core/model"
+	"oms/server/core/types"
+)
+
+// Entity types recorded in the audit log
+const (
+	EntityProduct           = "product"
+	EntityProductCategories = "product_categories" // A product's category assignments
+	EntityInventory         = "inventory"          // A stock unit: a product, or a variant of one
+	EntityVariant           = "variant"
+	EntityCategory          = "category"
+	EntityMetadataSchema    = "metadata_schema"
+	EntityPromotion         = "promotion"
+	EntityTaxRule           = "tax_rule"
+	EntityShippingZone      = "shipping_zone"
+	EntityUser              = "user"
+	EntityOrder             = "order"
+	EntityShipment          = "shipment"
+	EntityReturn            = "return_request"
+	EntityStockAlert        = "stock_alert"
+)
+
+// ignoredFields change on every save and are left out of an entry's changes
+var ignoredFields = map[string]bool{"updated_at": true}
+
+// Log records the changes admins make and looks them up
+type Log interface {
+	// Record saves a change to an entity made by the admin in ctx; changes made by anyone else, or
+	// outside a request, are not recorded. before is nil for creations and after for deletions.
+	// Failures are logged rather than failing the change, which has already been made.
+	Record(ctx context.Context, action model.AuditAction, entityType, entityID string, before, after interface{})
+	// Search retrieves the entries matching filter, newest first
+	Search(ctx context.Context, filter model.AuditFilter) ([]*model.AuditEntry, error)
+	// Export calls fn for every entry matching filter, newest first, without holding them all in memory
+	Export(ctx context.Context, filter model.AuditFilter, fn func(entry *model.AuditEntry) error) error
+}
+
+// auditLog implements Log
+type auditLog struct {
+	store types.AuditStore
+}
+
+// NewLog creates a new Log
+func NewLog(store types.AuditStore) Log {
+	return &auditLog{store: store}
+}
+
+// Record snapshots both sides of the change as JSON and saves them with the fields that differ
+func (l *auditLog) Record(ctx context.Context, action model.AuditAction, entityType, entityID string, before, after interface{}) {
+	actor, admin, _ := ActorFrom(ctx)
+	if !admin {
+		return
+	}
+	request := RequestFrom(ctx)
+	entry := &model.AuditEntry{
+		ActorType:  actor.Type,
+		ActorID:    actor.ID,
+		Action:     action,
+		EntityType: entityType,
+		EntityID:   entityID,
+		Before:     snapshot(before),
+		After:      snapshot(after),
+		RequestID:  request.ID,
+		ClientIP:   request.ClientIP,
+	}
+	entry.Changes = changes(entry.Before, entry.After)
+	if err := l.store.Create(ctx, entry); err != nil {
+		log.Printf("Warning: failed to record %s of %s %s by %s in the audit log: %v", action, entityType, entityID, actor, err)
+	}
+}
+
+// Search retrieves audit entries
+func (l *auditLog) Search(ctx context.Context, filter model.AuditFilter) ([]*model.AuditEntry, error) {
+	return l.store.Search(ctx, filter)
+}
+
+// Export streams audit entries
+func (l *auditLog) Export(ctx context.Context, filter model.AuditFilter, fn func(entry *model.AuditEntry) error) error {
+	return l.store.Each(ctx, filter, fn)
+}
+
+// snapshot converts an entity to its JSON fields, or nil when there is none
+func snapshot(entity interface{}) model.JSONB {
+	if entity == nil {
+		return nil
+	}
+	data, err := json.Marshal(entity)
+	if err != nil {
+		log.Printf("Warning: failed to snapshot %T for the audit log: %v", entity, err)
+		return nil
+	}
+	var fields model.JSONB
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil
+	}
+	return fields
+}
+
+// changes lists the fields whose values differ between before and after as {"before": ..., "after": ...};
+// for a creation or deletion that is every field
+func changes(before, after model.JSONB) model.JSONB {
+	diff := model.JSONB{}
+	for field, value := range after {
+		if !ignoredFields[field] && !reflect.DeepEqual(before[field], value) {
+			diff[field] = map[string]interface{}{"before": before[field], "after": value}
+		}
+	}
+	for field, value := range before {
+		if _, ok := after[field]; !ok && !ignoredFields[field] {
+			diff[field] = map[string]interface{}{"before": value, "after": nil}
+		}
+	}
+	return diff
+}
@@ -0,0 +1,582 @@
+package audit
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/google/uuid"
+	"oms/server/core/model"
+	"oms/server/core/types"
+)
+
+// The stores below record the admin changes made through them in a Log. Reads pass through to the
+// wrapped store; the state before a change is only loaded when the change will be recorded.
+//
+// They wrap the stores rather than the services: the product, inventory and user endpoints use
+// their stores directly, and one service call can change many entities, such as generating a
+// product's variants or moving a category among its siblings. Each entity changed is recorded,
+// whichever service or endpoint changed it.
+
+// load fetches the state before a change, or nil when ctx isn't audited or it can't be found
+func load[T any](ctx context.Context, get func() (*T, error)) *T {
+	if !Audited(ctx) {
+		return nil
+	}
+	current, err := get()
+	if err != nil {
+		return nil
+	}
+	return current
+}
+
+// record saves a change to the log, treating a missing entity as absent rather than a typed nil
+func record[T any](ctx context.Context, log Log, action model.AuditAction, entityType, entityID string, before, after *T) {
+	var b, a interface{}
+	if before != nil {
+		b = before
+	}
+	if after != nil {
+		a = after
+	}
+	log.Record(ctx, action, entityType, entityID, b, a)
+}
+
+// productStore records product changes
+type productStore struct {
+	types.ProductStore
+	log Log
+}
+
+// NewProductStore wraps store to record admin changes to products in log
+func NewProductStore(store types.ProductStore, log Log) types.ProductStore {
+	return &productStore{ProductStore: store, log: log}
+}
+
+func (s *productStore) Create(ctx context.Context, product *model.Product) error {
+	if err := s.ProductStore.Create(ctx, product); err != nil {
+		return err
+	}
+	record[model.Product](ctx, s.log, model.AuditCreate, EntityProduct, product.ID.String(), nil, product)
+	return nil
+}
+
+func (s *productStore) Update(ctx context.Context, productID uuid.UUID, product *model.Product) error {
+	before := load(ctx, func() (*model.Product, error) { return s.ProductStore.GetByID(ctx, productID) })
+	if err := s.ProductStore.Update(ctx, productID, product); err != nil {
+		return err
+	}
+	after := load(ctx, func() (*model.Product, error) { return s.ProductStore.GetByID(ctx, productID) })
+	record(ctx, s.log, model.AuditUpdate, EntityProduct, productID.String(), before, after)
+	return nil
+}
+
+func (s *productStore) Delete(ctx context.Context, productID uuid.UUID) error {
+	before := load(ctx, func() (*model.Product, error) { return s.ProductStore.GetByID(ctx, productID) })
+	if err := s.ProductStore.Delete(ctx, productID); err != nil {
+		return err
+	}
+	record(ctx, s.log, model.AuditDelete, EntityProduct, productID.String(), before, nil)
+	return nil
+}
+
+// inventoryStore records admin stock changes; decrements and increments made by orders aren't
+// admin changes and pass through
+type inventoryStore struct {
+	types.InventoryStore
+	log Log
+}
+
+// NewInventoryStore wraps store to record admin changes to stock levels, bin locations and reorder points in log
+func NewInventoryStore(store types.InventoryStore, log Log) types.InventoryStore {
+	return &inventoryStore{InventoryStore: store, log: log}
+}
+
+func (s *inventoryStore) UpdateQuantity(ctx context.Context, productID uuid.UUID, quantity int) error {
+	return s.update(ctx, productID, func() error { return s.InventoryStore.UpdateQuantity(ctx, productID, quantity) })
+}
+
+func (s *inventoryStore) UpdateBinLocation(ctx context.Context, productID uuid.UUID, binLocation string) error {
+	return s.update(ctx, productID, func() error { return s.InventoryStore.UpdateBinLocation(ctx, productID, binLocation) })
+}
+
+func (s *inventoryStore) UpdateReorderPoint(ctx context.Context, productID uuid.UUID, reorderPoint, targetLevel *int) error {
+	return s.update(ctx, productID, func() error {
+		return s.InventoryStore.UpdateReorderPoint(ctx, productID, reorderPoint, targetLevel)
+	})
+}
+
+// update records a change to a stock unit, which creates it if it had no inventory row yet
+func (s *inventoryStore) update(ctx context.Context, productID uuid.UUID, apply func() error) error {
+	get := func() (*model.Inventory, error) { return s.InventoryStore.GetByProductID(ctx, productID) }
+	before := load(ctx, get)
+	if err := apply(); err != nil {
+		return err
+	}
+	action := model.AuditUpdate
+	if before == nil {
+		action = model.AuditCreate
+	}
+	record(ctx, s.log, action, EntityInventory, productID.String(), before, load(ctx, get))
+	return nil
+}
+
+// variantStore records variant changes
+type variantStore struct {
+	types.ProductVariantStore
+	log Log
+}
+
+// NewProductVariantStore wraps store to record admin changes to variants in log
+func NewProductVariantStore(store types.ProductVariantStore, log Log) types.ProductVariantStore {
+	return &variantStore{ProductVariantStore: store, log: log}
+}
+
+func (s *variantStore) Create(ctx context.Context, variant *model.ProductVariant) error {
+	if err := s.ProductVariantStore.Create(ctx, variant); err != nil {
+		return err
+	}
+	record[model.ProductVariant](ctx, s.log, model.AuditCreate, EntityVariant, variant.ID.String(), nil, variant)
+	return nil
+}
+
+func (s *variantStore) Update(ctx context.Context, variant *model.ProductVariant) error {
+	get := func() (*model.ProductVariant, error) { return s.ProductVariantStore.GetByID(ctx, variant.ID) }
+	before := load(ctx, get)
+	if err := s.ProductVariantStore.Update(ctx, variant); err != nil {
+		return err
+	}
+	record(ctx, s.log, model.AuditUpdate, EntityVariant, variant.ID.String(), before, load(ctx, get))
+	return nil
+}
+
+func (s *variantStore) Delete(ctx context.Context, variantID uuid.UUID) error {
+	before := load(ctx, func() (*model.ProductVariant, error) { return s.ProductVariantStore.GetByID(ctx, variantID) })
+	if err := s.ProductVariantStore.Delete(ctx, variantID); err != nil {
+		return err
+	}
+	record(ctx, s.log, model.AuditDelete, EntityVariant, variantID.String(), before, nil)
+	return nil
+}
+
+// categoryStore records category changes and product category assignments
+type categoryStore struct {
+	types.CategoryStore
+	log Log
+}
+
+// NewCategoryStore wraps store to record admin changes to categories and their products in log
+func NewCategoryStore(store types.CategoryStore, log Log) types.CategoryStore {
+	return &categoryStore{CategoryStore: store, log: log}
+}
+
+func (s *categoryStore) Create(ctx context.Context, category *model.Category) error {
+	if err := s.CategoryStore.Create(ctx, category); err != nil {
+		return err
+	}
+	record[model.Category](ctx, s.log, model.AuditCreate, EntityCategory, category.ID.String(), nil, category)
+	return nil
+}
+
+func (s *categoryStore) Update(ctx context.Context, category *model.Category) error {
+	get := func() (*model.Category, error) { return s.CategoryStore.GetByID(ctx, category.ID) }
+	before := load(ctx, get)
+	if err := s.CategoryStore.Update(ctx, category); err != nil {
+		return err
+	}
+	record(ctx, s.log, model.AuditUpdate, EntityCategory, category.ID.String(), before, load(ctx, get))
+	return nil
+}
+
+func (s *categoryStore) Delete(ctx context.Context, categoryID uuid.UUID) error {
+	before := load(ctx, func() (*model.Category, error) { return s.CategoryStore.GetByID(ctx, categoryID) })
+	if err := s.CategoryStore.Delete(ctx, categoryID); err != nil {
+		return err
+	}
+	record(ctx, s.log, model.AuditDelete, EntityCategory, categoryID.String(), before, nil)
+	return nil
+}
+
+// productCategories is what the log records of a product's category assignments
+type productCategories struct {
+	CategoryIDs []uuid.UUID `json:"category_ids"`
+}
+
+func (s *categoryStore) SetProductCategories(ctx context.Context, productID uuid.UUID, categoryIDs []uuid.UUID) error {
+	before := load(ctx, func() (*productCategories, error) {
+		categories, err := s.CategoryStore.GetByProductID(ctx, productID)
+		if err != nil {
+			return nil, err
+		}
+		assigned := &productCategories{CategoryIDs: []uuid.UUID{}}
+		for _, category := range categories {
+			assigned.CategoryIDs = append(assigned.CategoryIDs, category.ID)
+		}
+		return assigned, nil
+	})
+	if err := s.CategoryStore.SetProductCategories(ctx, productID, categoryIDs); err != nil {
+		return err
+	}
+	after := &productCategories{CategoryIDs: append([]uuid.UUID{}, categoryIDs...)}
+	record(ctx, s.log, model.AuditUpdate, EntityProductCategories, productID.String(), before, after)
+	return nil
+}
+
+// metadataSchemaStore records metadata schema changes
+type metadataSchemaStore struct {
+	types.MetadataSchemaStore
+	log Log
+}
+
+// NewMetadataSchemaStore wraps store to record admin changes to metadata schemas in log
+func NewMetadataSchemaStore(store types.MetadataSchemaStore, log Log) types.MetadataSchemaStore {
+	return &metadataSchemaStore{MetadataSchemaStore: store, log: log}
+}
+
+func (s *metadataSchemaStore) Create(ctx context.Context, schema *model.MetadataSchema) error {
+	if err := s.MetadataSchemaStore.Create(ctx, schema); err != nil {
+		return err
+	}
+	record[model.MetadataSchema](ctx, s.log, model.AuditCreate, EntityMetadataSchema, schema.ID.String(), nil, schema)
+	return nil
+}
+
+func (s *metadataSchemaStore) Update(ctx context.Context, schema *model.MetadataSchema) error {
+	get := func() (*model.MetadataSchema, error) { return s.MetadataSchemaStore.GetByID(ctx, schema.ID) }
+	before := load(ctx, get)
+	if err := s.MetadataSchemaStore.Update(ctx, schema); err != nil {
+		return err
+	}
+	record(ctx, s.log, model.AuditUpdate, EntityMetadataSchema, schema.ID.String(), before, load(ctx, get))
+	return nil
+}
+
+func (s *metadataSchemaStore) Delete(ctx context.Context, schemaID uuid.UUID) error {
+	before := load(ctx, func() (*model.MetadataSchema, error) { return s.MetadataSchemaStore.GetByID(ctx, schemaID) })
+	if err := s.MetadataSchemaStore.Delete(ctx, schemaID); err != nil {
+		return err
+	}
+	record(ctx, s.log, model.AuditDelete, EntityMetadataSchema, schemaID.String(), before, nil)
+	return nil
+}
+
+// promotionStore records promotion changes; redemptions made by orders pass through
+type promotionStore struct {
+	types.PromotionStore
+	log Log
+}
+
+// NewPromotionStore wraps store to record admin changes to promotions in log
+func NewPromotionStore(store types.PromotionStore, log Log) types.PromotionStore {
+	return &promotionStore{PromotionStore: store, log: log}
+}
+
+func (s *promotionStore) Create(ctx context.Context, promotion *model.Promotion) error {
+	if err := s.PromotionStore.Create(ctx, promotion); err != nil {
+		return err
+	}
+	record[model.Promotion](ctx, s.log, model.AuditCreate, EntityPromotion, promotion.ID.String(), nil, promotion)
+	return nil
+}
+
+func (s *promotionStore) Update(ctx context.Context, promotion *model.Promotion) error {
+	get := func() (*model.Promotion, error) { return s.PromotionStore.GetByID(ctx, promotion.ID) }
+	before := load(ctx, get)
+	if err := s.PromotionStore.Update(ctx, promotion); err != nil {
+		return err
+	}
+	record(ctx, s.log, model.AuditUpdate, EntityPromotion, promotion.ID.String(), before, load(ctx, get))
+	return nil
+}
+
+func (s *promotionStore) Delete(ctx context.Context, promotionID uuid.UUID) error {
+	before := load(ctx, func() (*model.Promotion, error) { return s.PromotionStore.GetByID(ctx, promotionID) })
+	if err := s.PromotionStore.Delete(ctx, promotionID); err != nil {
+		return err
+	}
+	record(ctx, s.log, model.AuditDelete, EntityPromotion, promotionID.String(), before, nil)
+	return nil
+}
+
+// taxRuleStore records tax rule changes
+type taxRuleStore struct {
+	types.TaxRuleStore
+	log Log
+}
+
+// NewTaxRuleStore wraps store to record admin changes to tax rules in log
+func NewTaxRuleStore(store types.TaxRuleStore, log Log) types.TaxRuleStore {
+	return &taxRuleStore{TaxRuleStore: store, log: log}
+}
+
+func (s *taxRuleStore) Create(ctx context.Context, rule *model.TaxRule) error {
+	if err := s.TaxRuleStore.Create(ctx, rule); err != nil {
+		return err
+	}
+	record[model.TaxRule](ctx, s.log, model.AuditCreate, EntityTaxRule, rule.ID.String(), nil, rule)
+	return nil
+}
+
+func (s *taxRuleStore) Update(ctx context.Context, rule *model.TaxRule) error {
+	get := func() (*model.TaxRule, error) { return s.TaxRuleStore.GetByID(ctx, rule.ID) }
+	before := load(ctx, get)
+	if err := s.TaxRuleStore.Update(ctx, rule); err != nil {
+		return err
+	}
+	record(ctx, s.log, model.AuditUpdate, EntityTaxRule, rule.ID.String(), before, load(ctx, get))
+	return nil
+}
+
+func (s *taxRuleStore) Delete(ctx context.Context, ruleID uuid.UUID) error {
+	before := load(ctx, func() (*model.TaxRule, error) { return s.TaxRuleStore.GetByID(ctx, ruleID) })
+	if err := s.TaxRuleStore.Delete(ctx, ruleID); err != nil {
+		return err
+	}
+	record(ctx, s.log, model.AuditDelete, EntityTaxRule, ruleID.String(), before, nil)
+	return nil
+}
+
+// shippingZoneStore records shipping zone changes, including their rates
+type shippingZoneStore struct {
+	types.ShippingZoneStore
+	log Log
+}
+
+// NewShippingZoneStore wraps store to record admin changes to shipping zones in log
+func NewShippingZoneStore(store types.ShippingZoneStore, log Log) types.ShippingZoneStore {
+	return &shippingZoneStore{ShippingZoneStore: store, log: log}
+}
+
+func (s *shippingZoneStore) Create(ctx context.Context, zone *model.ShippingZone) error {
+	if err := s.ShippingZoneStore.Create(ctx, zone); err != nil {
+		return err
+	}
+	record[model.ShippingZone](ctx, s.log, model.AuditCreate, EntityShippingZone, zone.ID.String(), nil, zone)
+	return nil
+}
+
+func (s *shippingZoneStore) Update(ctx context.Context, zone *model.ShippingZone) error {
+	get := func() (*model.ShippingZone, error) { return s.ShippingZoneStore.GetByID(ctx, zone.ID) }
+	before := load(ctx, get)
+	if err := s.ShippingZoneStore.Update(ctx, zone); err != nil {
+		return err
+	}
+	record(ctx, s.log, model.AuditUpdate, EntityShippingZone, zone.ID.String(), before, load(ctx, get))
+	return nil
+}
+
+func (s *shippingZoneStore) Delete(ctx context.Context, zoneID uuid.UUID) error {
+	before := load(ctx, func() (*model.ShippingZone, error) { return s.ShippingZoneStore.GetByID(ctx, zoneID) })
+	if err := s.ShippingZoneStore.Delete(ctx, zoneID); err != nil {
+		return err
+	}
+	record(ctx, s.log, model.AuditDelete, EntityShippingZone, zoneID.String(), before, nil)
+	return nil
+}
+
+// userStore records accounts created by admins; sign-ups have no actor and pass through
+type userStore struct {
+	types.UserStore
+	log Log
+}
+
+// NewUserStore wraps store to record users created by admins in log. Passwords are never
+// serialized, so they don't reach the log.
+func NewUserStore(store types.UserStore, log Log) types.UserStore {
+	return &userStore{UserStore: store, log: log}
+}
+
+func (s *userStore) Create(ctx context.Context, user *model.User) error {
+	if err := s.UserStore.Create(ctx, user); err != nil {
+		return err
+	}
+	record[model.User](ctx, s.log, model.AuditCreate, EntityUser, strconv.Itoa(user.ID), nil, user)
+	return nil
+}
+
+// productImportStore records the products and stock an import creates or updates
+type productImportStore struct {
+	types.ProductImportStore
+	log Log
+}
+
+// NewProductImportStore wraps store to record admin imports in log, an entry for each product and
+// stock unit they change
+func NewProductImportStore(store types.ProductImportStore, log Log) types.ProductImportStore {
+	return &productImportStore{ProductImportStore: store, log: log}
+}
+
+// importState is what products and stock units looked like before an import
+type importState struct {
+	products  map[string]*model.Product      // By SKU
+	inventory map[uuid.UUID]*model.Inventory // By product ID
+}
+
+// Apply records a row's product as created when it is new or was deleted, and its stock unit as
+// created when it had no inventory row. The state after a row is what the row saved.
+func (s *productImportStore) Apply(ctx context.Context, rows []*model.ProductImportRow) error {
+	before := load(ctx, func() (*importState, error) {
+		var skus []string
+		var productIDs []uuid.UUID
+		for _, row := range rows {
+			if !row.New {
+				skus = append(skus, row.Product.SKU)
+				productIDs = append(productIDs, row.Product.ID)
+			}
+		}
+		products, err := s.ProductImportStore.GetBySKUs(ctx, skus)
+		if err != nil {
+			return nil, err
+		}
+		inventory, err := s.ProductImportStore.GetInventory(ctx, productIDs)
+		if err != nil {
+			return nil, err
+		}
+		state := &importState{products: map[string]*model.Product{}, inventory: map[uuid.UUID]*model.Inventory{}}
+		for _, product := range products {
+			state.products[product.SKU] = product
+		}
+		for _, stock := range inventory {
+			state.inventory[stock.StockUnitID] = stock
+		}
+		return state, nil
+	})
+	if err := s.ProductImportStore.Apply(ctx, rows); err != nil {
+		return err
+	}
+	if before == nil {
+		return nil
+	}
+
+	for _, row := range rows {
+		product := before.products[row.Product.SKU]
+		action := model.AuditUpdate
+		if product == nil || product.DeletedAt.Valid {
+			action = model.AuditCreate
+		}
+		record(ctx, s.log, action, EntityProduct, row.Product.ID.String(), product, row.Product)
+
+		if !row.New && row.Quantity == nil && row.BinLocation == nil {
+			continue
+		}
+		stock := before.inventory[row.Product.ID]
+		after := &model.Inventory{StockUnitID: row.Product.ID, ProductID: row.Product.ID}
+		action = model.AuditCreate
+		if stock != nil {
+			*after = *stock
+			action = model.AuditUpdate
+		}
+		if row.Quantity != nil {
+			after.Quantity = *row.Quantity
+		}
+		if row.BinLocation != nil {
+			after.BinLocation = *row.BinLocation
+		}
+		record(ctx, s.log, action, EntityInventory, row.Product.ID.String(), stock, after)
+	}
+	return nil
+}
+
+// orderStore records the status changes and cancelled units admins make to orders. Orders admins
+// place as customers, and the changes payments, carriers and jobs make, are not recorded.
+type orderStore struct {
+	types.OrderStore
+	log Log
+}
+
+// NewOrderStore wraps store to record admin changes to order statuses and quantities in log
+func NewOrderStore(store types.OrderStore, log Log) types.OrderStore {
+	return &orderStore{OrderStore: store, log: log}
+}
+
+func (s *orderStore) UpdateStatus(ctx context.Context, orderID uuid.UUID, previous, status model.OrderStatus) (bool, error) {
+	return s.update(ctx, orderID, func() (bool, error) { return s.OrderStore.UpdateStatus(ctx, orderID, previous, status) })
+}
+
+func (s *orderStore) CancelQuantity(ctx context.Context, orderID uuid.UUID, quantity int) (bool, error) {
+	return s.update(ctx, orderID, func() (bool, error) { return s.OrderStore.CancelQuantity(ctx, orderID, quantity) })
+}
+
+// update records a change to an order when apply reports it was made
+func (s *orderStore) update(ctx context.Context, orderID uuid.UUID, apply func() (bool, error)) (bool, error) {
+	get := func() (*model.Order, error) { return s.OrderStore.GetByID(ctx, orderID) }
+	before := load(ctx, get)
+	applied, err := apply()
+	if err != nil || !applied {
+		return applied, err
+	}
+	record(ctx, s.log, model.AuditUpdate, EntityOrder, orderID.String(), before, load(ctx, get))
+	return true, nil
+}
+
+// shipmentStore records the shipments admins create; tracking events from carriers pass through
+type shipmentStore struct {
+	types.ShipmentStore
+	log Log
+}
+
+// NewShipmentStore wraps store to record the shipments admins create in log
+func NewShipmentStore(store types.ShipmentStore, log Log) types.ShipmentStore {
+	return &shipmentStore{ShipmentStore: store, log: log}
+}
+
+func (s *shipmentStore) Create(ctx context.Context, shipment *model.Shipment) (bool, error) {
+	created, err := s.ShipmentStore.Create(ctx, shipment)
+	if err != nil || !created {
+		return created, err
+	}
+	record[model.Shipment](ctx, s.log, model.AuditCreate, EntityShipment, shipment.ID.String(), nil, shipment)
+	return true, nil
+}
+
+// returnRequestStore records the returns admins open and move along
+type returnRequestStore struct {
+	types.ReturnRequestStore
+	log Log
+}
+
+// NewReturnRequestStore wraps store to record admin changes to return requests in log
+func NewReturnRequestStore(store types.ReturnRequestStore, log Log) types.ReturnRequestStore {
+	return &returnRequestStore{ReturnRequestStore: store, log: log}
+}
+
+func (s *returnRequestStore) Create(ctx context.Context, request *model.ReturnRequest) (bool, error) {
+	created, err := s.ReturnRequestStore.Create(ctx, request)
+	if err != nil || !created {
+		return created, err
+	}
+	record[model.ReturnRequest](ctx, s.log, model.AuditCreate, EntityReturn, request.ID.String(), nil, request)
+	return true, nil
+}
+
+func (s *returnRequestStore) UpdateStatus(ctx context.Context, request *model.ReturnRequest, previous model.ReturnStatus) (bool, error) {
+	get := func() (*model.ReturnRequest, error) { return s.ReturnRequestStore.GetByID(ctx, request.ID) }
+	before := load(ctx, get)
+	applied, err := s.ReturnRequestStore.UpdateStatus(ctx, request, previous)
+	if err != nil || !applied {
+		return applied, err
+	}
+	record(ctx, s.log, model.AuditUpdate, EntityReturn, request.ID.String(), before, load(ctx, get))
+	return true, nil
+}
+
+// stockAlertStore records the alerts admins acknowledge and resolve; alerts opened and resolved
+// as stock changes pass through
+type stockAlertStore struct {
+	types.StockAlertStore
+	log Log
+}
+
+// NewStockAlertStore wraps store to record admin changes to stock alerts in log
+func NewStockAlertStore(store types.StockAlertStore, log Log) types.StockAlertStore {
+	return &stockAlertStore{StockAlertStore: store, log: log}
+}
+
+func (s *stockAlertStore) Update(ctx context.Context, alert *model.StockAlert) error {
+	get := func() (*model.StockAlert, error) { return s.StockAlertStore.GetByID(ctx, alert.ID) }
+	before := load(ctx, get)
+	if err := s.StockAlertStore.Update(ctx, alert); err != nil {
+		return err
+	}
+	record(ctx, s.log, model.AuditUpdate, EntityStockAlert, alert.ID.String(), before, load(ctx, get))
+	return nil
+}
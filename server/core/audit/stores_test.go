@@ -0,0 +1,267 @@
+package audit_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"oms/server/core/audit"
+	"oms/server/core/fake"
+	"oms/server/core/fsm"
+	"oms/server/core/model"
+	"oms/server/core/money"
+	"oms/server/core/services"
+	"oms/server/core/types"
+)
+
+// entryStore keeps the audit entries recorded
+type entryStore struct {
+	types.AuditStore
+	entries []*model.AuditEntry
+}
+
+func (s *entryStore) Create(ctx context.Context, entry *model.AuditEntry) error {
+	s.entries = append(s.entries, entry)
+	return nil
+}
+
+type shipmentStore struct{ types.ShipmentStore }
+
+func (s *shipmentStore) Create(ctx context.Context, shipment *model.Shipment) (bool, error) {
+	shipment.ID = uuid.New()
+	return true, nil
+}
+
+type promotionStore struct {
+	types.PromotionStore
+	m map[uuid.UUID]model.Promotion
+}
+
+func (s *promotionStore) GetByID(ctx context.Context, promotionID uuid.UUID) (*model.Promotion, error) {
+	promotion, ok := s.m[promotionID]
+	if !ok {
+		return nil, errors.New("promotion not found")
+	}
+	return &promotion, nil
+}
+
+func (s *promotionStore) Update(ctx context.Context, promotion *model.Promotion) error {
+	s.m[promotion.ID] = *promotion
+	return nil
+}
+
+// importStore has one product, MUG, which has no inventory row
+type importStore struct {
+	types.ProductImportStore
+	mug *model.Product
+}
+
+func (s *importStore) GetBySKUs(ctx context.Context, skus []string) ([]*model.Product, error) {
+	return []*model.Product{s.mug}, nil
+}
+
+func (s *importStore) GetInventory(ctx context.Context, productIDs []uuid.UUID) ([]*model.Inventory, error) {
+	return nil, nil
+}
+
+func (s *importStore) Apply(ctx context.Context, rows []*model.ProductImportRow) error {
+	return nil
+}
+
+type returnStore struct {
+	types.ReturnRequestStore
+	m map[uuid.UUID]model.ReturnRequest
+}
+
+func (s *returnStore) GetByID(ctx context.Context, returnID uuid.UUID) (*model.ReturnRequest, error) {
+	request, ok := s.m[returnID]
+	if !ok {
+		return nil, errors.New("return not found")
+	}
+	return &request, nil
+}
+
+func (s *returnStore) UpdateStatus(ctx context.Context, request *model.ReturnRequest, previous model.ReturnStatus) (bool, error) {
+	if s.m[request.ID].Status != previous {
+		return false, nil
+	}
+	s.m[request.ID] = *request
+	return true, nil
+}
+
+type alertStore struct {
+	types.StockAlertStore
+	m map[uuid.UUID]model.StockAlert
+}
+
+func (s *alertStore) GetByID(ctx context.Context, alertID uuid.UUID) (*model.StockAlert, error) {
+	alert, ok := s.m[alertID]
+	if !ok {
+		return nil, errors.New("alert not found")
+	}
+	return &alert, nil
+}
+
+func (s *alertStore) Update(ctx context.Context, alert *model.StockAlert) error {
+	s.m[alert.ID] = *alert
+	return nil
+}
+
+func admin() context.Context {
+	return audit.WithActor(context.Background(), model.UserActor(1), true)
+}
+
+func TestAdminChangesAreRecorded(t *testing.T) {
+	entries := &entryStore{}
+	log := audit.NewLog(entries)
+
+	orderStore := audit.NewOrderStore(&fake.OrderStoreFake{}, log)
+	order := &model.Order{ProductID: uuid.New(), Quantity: 3, CurrentStatus: model.OrderStatusPaid}
+	if err := orderStore.Create(context.Background(), order); err != nil {
+		t.Fatal(err)
+	}
+	promotion := model.Promotion{ID: uuid.New(), Name: "Spring sale", Type: model.PromotionTypePercentage, PercentOff: "10", Active: true}
+	promotions := audit.NewPromotionStore(&promotionStore{m: map[uuid.UUID]model.Promotion{promotion.ID: promotion}}, log)
+	mug := &model.Product{ID: uuid.New(), SKU: "MUG", Name: "Mug", Price: money.New(1250, "USD")}
+	imports := audit.NewProductImportStore(&importStore{mug: mug}, log)
+	request := model.ReturnRequest{ID: uuid.New(), OrderID: order.ID, Quantity: 1, Status: model.ReturnStatusRequested}
+	returns := audit.NewReturnRequestStore(&returnStore{m: map[uuid.UUID]model.ReturnRequest{request.ID: request}}, log)
+	alert := model.StockAlert{ID: uuid.New(), ProductID: uuid.New(), Status: model.StockAlertOpen, ReorderPoint: 5}
+	alerts := audit.NewStockAlertStore(&alertStore{m: map[uuid.UUID]model.StockAlert{alert.ID: alert}}, log)
+	stockUnitID := uuid.New()
+	inventory := audit.NewInventoryStore(&fake.InventoryStoreFake{}, log)
+	var shipment model.Shipment
+
+	tests := []struct {
+		name        string
+		change      func(ctx context.Context) error
+		wantType    string
+		wantID      func() string
+		wantAction  model.AuditAction
+		wantChanged string // A field the entry records as changed
+	}{
+		{
+			name: "order status override",
+			change: func(ctx context.Context) error {
+				_, err := orderStore.UpdateStatus(ctx, order.ID, model.OrderStatusPaid, model.OrderStatusPicking)
+				return err
+			},
+			wantType: audit.EntityOrder, wantID: order.ID.String, wantAction: model.AuditUpdate, wantChanged: "current_status",
+		},
+		{
+			name: "order units cancelled",
+			change: func(ctx context.Context) error {
+				_, err := orderStore.CancelQuantity(ctx, order.ID, 1)
+				return err
+			},
+			wantType: audit.EntityOrder, wantID: order.ID.String, wantAction: model.AuditUpdate, wantChanged: "cancelled_quantity",
+		},
+		{
+			name: "shipment",
+			change: func(ctx context.Context) error {
+				shipment = model.Shipment{OrderID: order.ID, Quantity: 1, Carrier: "ups", TrackingNumber: "1Z999"}
+				_, err := audit.NewShipmentStore(&shipmentStore{}, log).Create(ctx, &shipment)
+				return err
+			},
+			wantType: audit.EntityShipment, wantID: func() string { return shipment.ID.String() }, wantAction: model.AuditCreate, wantChanged: "tracking_number",
+		},
+		{
+			name: "promotion",
+			change: func(ctx context.Context) error {
+				updated := promotion
+				updated.Active = false
+				return promotions.Update(ctx, &updated)
+			},
+			wantType: audit.EntityPromotion, wantID: promotion.ID.String, wantAction: model.AuditUpdate, wantChanged: "active",
+		},
+		{
+			name: "import apply",
+			change: func(ctx context.Context) error {
+				renamed := *mug
+				renamed.Name = "Coffee mug"
+				return imports.Apply(ctx, []*model.ProductImportRow{{Line: 2, Product: &renamed}})
+			},
+			wantType: audit.EntityProduct, wantID: mug.ID.String, wantAction: model.AuditUpdate, wantChanged: "name",
+		},
+		{
+			name: "return moved along",
+			change: func(ctx context.Context) error {
+				approved := request
+				approved.Status = model.ReturnStatusApproved
+				_, err := returns.UpdateStatus(ctx, &approved, model.ReturnStatusRequested)
+				return err
+			},
+			wantType: audit.EntityReturn, wantID: request.ID.String, wantAction: model.AuditUpdate, wantChanged: "status",
+		},
+		{
+			name: "stock alert acknowledged",
+			change: func(ctx context.Context) error {
+				acknowledged := alert
+				acknowledged.Status = model.StockAlertAcknowledged
+				return alerts.Update(ctx, &acknowledged)
+			},
+			wantType: audit.EntityStockAlert, wantID: alert.ID.String, wantAction: model.AuditUpdate, wantChanged: "status",
+		},
+		{
+			name: "stock level",
+			change: func(ctx context.Context) error {
+				return inventory.UpdateQuantity(ctx, stockUnitID, 8)
+			},
+			wantType: audit.EntityInventory, wantID: stockUnitID.String, wantAction: model.AuditUpdate, wantChanged: "quantity",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entries.entries = nil
+			if err := tt.change(admin()); err != nil {
+				t.Fatalf("change failed: %v", err)
+			}
+			if len(entries.entries) != 1 {
+				t.Fatalf("got %d audit entries, want 1", len(entries.entries))
+			}
+			entry := entries.entries[0]
+			if entry.EntityType != tt.wantType || entry.EntityID != tt.wantID() || entry.Action != tt.wantAction {
+				t.Errorf("entry = %s of %s %s, want %s of %s %s", entry.Action, entry.EntityType, entry.EntityID, tt.wantAction, tt.wantType, tt.wantID())
+			}
+			if entry.ActorType != model.ActorUser || entry.ActorID != "1" {
+				t.Errorf("entry actor = %s %s, want user 1", entry.ActorType, entry.ActorID)
+			}
+			if _, ok := entry.Changes[tt.wantChanged]; !ok {
+				t.Errorf("entry changes = %v, want %s among them", entry.Changes, tt.wantChanged)
+			}
+		})
+	}
+}
+
+func TestAdminStatusOverrideThroughOrderService(t *testing.T) {
+	entries := &entryStore{}
+	orderStore := audit.NewOrderStore(&fake.OrderStoreFake{}, audit.NewLog(entries))
+	service := services.NewOrderService(orderStore, &fake.InventoryStoreFake{}, nil, nil, &fake.OrderStateLogStoreFake{},
+		fsm.NewValidator(), nil, nil, nil, nil, nil, nil)
+	order := &model.Order{ProductID: uuid.New(), Quantity: 2, CurrentStatus: model.OrderStatusOrdered}
+	if err := orderStore.Create(context.Background(), order); err != nil {
+		t.Fatal(err)
+	}
+	change := model.StatusChange{Actor: model.UserActor(1), Reason: string(model.CancellationOutOfStock)}
+
+	// Changes made by users who aren't admins are in the order history only
+	user := audit.WithActor(context.Background(), model.UserActor(2), false)
+	if _, err := service.StartPicking(user, order.ID, 2, ""); err != nil {
+		t.Fatalf("StartPicking: %v", err)
+	}
+	if len(entries.entries) != 0 {
+		t.Fatalf("got %d audit entries for a change no admin made, want none", len(entries.entries))
+	}
+
+	if _, err := service.UpdateOrderStatus(admin(), order.ID, model.OrderStatusCancelled, change); err != nil {
+		t.Fatalf("UpdateOrderStatus: %v", err)
+	}
+	if len(entries.entries) != 1 {
+		t.Fatalf("got %d audit entries, want the admin's cancellation", len(entries.entries))
+	}
+	status, _ := entries.entries[0].Changes["current_status"].(map[string]interface{})
+	if status["before"] != string(model.OrderStatusPicking) || status["after"] != string(model.OrderStatusCancelled) {
+		t.Errorf("recorded status change = %v, want PICKING to CANCELLED", entries.entries[0].Changes["current_status"])
+	}
+}
@@ -7,7 +7,10 @@ import (
 	"github.com/golang-jwt/jwt/v5"
 )
 
-var jwtSecret = []byte("your-secret-key-change-in-production") // TODO: Load from config
+var jwtSecret = []byte("your-secret-key-change-in-production") // Overridden from config via SetSecret
+
+// tokenTTL is how long issued tokens stay valid
+var tokenTTL = 24 * time.Hour
 
 // Claims represents JWT claims
 type Claims struct {
@@ -18,7 +21,7 @@ type Claims struct {
 
 // GenerateToken generates a JWT token for a user
 func GenerateToken(userID int, role string) (string, error) {
-	expirationTime := time.Now().Add(24 * time.Hour) // Token expires in 24 hours
+	expirationTime := time.Now().Add(tokenTTL)
 
 	claims := &Claims{
 		UserID: userID,
@@ -65,3 +68,8 @@ func SetSecret(secret string) {
 	jwtSecret = []byte(secret)
 }
 
+
+// SetTokenTTL sets how long newly issued tokens stay valid (should be called from config)
+func SetTokenTTL(ttl time.Duration) {
+	tokenTTL = ttl
+}
@@ -0,0 +1,139 @@
+package document
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+
+	"oms/server/core/model"
+)
+
+// printStyle lays documents out for A4 paper; the screen view only adds a margin around the page
+const printStyle = `
+	@page { size: A4; margin: 15mm; }
+	body { font-family: Helvetica, Arial, sans-serif; font-size: 11pt; color: #000; margin: 2em; }
+	@media print { body { margin: 0; } }
+	h1 { font-size: 18pt; margin: 0 0 0.5em; }
+	table { width: 100%; border-collapse: collapse; margin-top: 1em; }
+	th, td { border-bottom: 1px solid #999; padding: 0.4em; text-align: left; vertical-align: top; }
+	th.num, td.num { text-align: right; }
+	.meta { color: #444; font-size: 9pt; }
+	.address { margin-top: 1em; line-height: 1.4; }
+	.check { width: 1.2em; height: 1.2em; border: 1px solid #000; display: inline-block; }
+`
+
+var packingSlipTemplate = template.Must(template.New("packing-slip").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Packing slip {{.OrderID}}</title>
+<style>` + printStyle + `</style>
+</head>
+<body>
+<h1>Packing slip</h1>
+<div class="meta">Order {{.OrderID}}{{if .CheckoutID}} &middot; Checkout {{.CheckoutID}}{{end}} &middot; Placed {{.PlacedAt.Format "2006-01-02"}}</div>
+<div class="address"><strong>Ship to</strong><br>{{range .ShipTo}}{{.}}<br>{{else}}No shipping address<br>{{end}}</div>
+{{if .ShippingOption}}<div class="meta">Shipping: {{.ShippingOption}}</div>{{end}}
+<table>
+<tr><th>SKU</th><th>Product</th><th class="num">Ordered</th><th class="num">Shipped before</th><th class="num">In this box</th><th></th></tr>
+<tr><td>{{.SKU}}</td><td>{{.ProductName}}</td><td class="num">{{.Ordered}}</td><td class="num">{{.ShippedBefore}}</td><td class="num"><strong>{{.ToPack}}</strong></td><td><span class="check"></span></td></tr>
+</table>
+<p class="meta">Generated {{.GeneratedAt.Format "2006-01-02 15:04 MST"}}</p>
+</body>
+</html>
+`))
+
+var pickListTemplate = template.Must(template.New("pick-list").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Pick list {{.ID}}</title>
+<style>` + printStyle + `</style>
+</head>
+<body>
+<h1>Pick list</h1>
+<div class="meta">{{.ID}} &middot; {{len .OrderIDs}} orders &middot; {{.TotalUnits}} units &middot; Generated {{.GeneratedAt.Format "2006-01-02 15:04 MST"}}</div>
+<table>
+<tr><th>Bin</th><th>SKU</th><th>Product</th><th class="num">Quantity</th><th>Orders</th><th></th></tr>
+{{range .Lines}}<tr><td>{{if .BinLocation}}{{.BinLocation}}{{else}}&mdash;{{end}}</td><td>{{.SKU}}</td><td>{{.ProductName}}</td><td class="num"><strong>{{.Quantity}}</strong></td><td class="meta">{{range .Orders}}{{.Quantity}} &times; {{.OrderID}}<br>{{end}}</td><td><span class="check"></span></td></tr>
+{{end}}</table>
+</body>
+</html>
+`))
+
+// RenderPackingSlipHTML writes a packing slip as a printable HTML page
+func RenderPackingSlipHTML(w io.Writer, slip *model.PackingSlip) error {
+	return packingSlipTemplate.Execute(w, slip)
+}
+
+// RenderPickListHTML writes a pick list as a printable HTML page
+func RenderPickListHTML(w io.Writer, list *model.PickList) error {
+	return pickListTemplate.Execute(w, list)
+}
+
+// RenderPackingSlipPDF renders a packing slip as a one-page A4 PDF
+func RenderPackingSlipPDF(slip *model.PackingSlip) []byte {
+	const left, right = 50.0, PageWidth - 50
+	pdf := NewPDF()
+	pdf.AddPage()
+
+	pdf.Text(left, 70, 20, true, "Packing slip")
+	meta := fmt.Sprintf("Order %s", slip.OrderID)
+	if slip.CheckoutID != nil {
+		meta += fmt.Sprintf("  -  Checkout %s", *slip.CheckoutID)
+	}
+	pdf.Text(left, 92, 9, false, meta)
+	pdf.Text(left, 106, 9, false, "Placed "+slip.PlacedAt.Format("2006-01-02"))
+
+	y := 140.0
+	pdf.Text(left, y, 11, true, "Ship to")
+	shipTo := slip.ShipTo
+	if len(shipTo) == 0 {
+		shipTo = []string{"No shipping address"}
+	}
+	for _, line := range shipTo {
+		y += 15
+		pdf.Text(left, y, 11, false, line)
+	}
+	if slip.ShippingOption != "" {
+		y += 22
+		pdf.Text(left, y, 9, false, "Shipping: "+slip.ShippingOption)
+	}
+
+	// One row for the order's item, with a box to tick once packed
+	columns := []struct {
+		x     float64
+		title string
+		value string
+		width int // Characters that fit before the next column
+	}{
+		{left, "SKU", slip.SKU, 16},
+		{left + 90, "Product", slip.ProductName, 36},
+		{left + 290, "Ordered", fmt.Sprint(slip.Ordered), 8},
+		{left + 345, "Shipped before", fmt.Sprint(slip.ShippedBefore), 8},
+		{left + 425, "In this box", fmt.Sprint(slip.ToPack), 8},
+	}
+	y += 40
+	for _, column := range columns {
+		pdf.Text(column.x, y, 10, true, column.title)
+	}
+	pdf.Line(left, y+6, right, y+6)
+	y += 22
+	for i, column := range columns {
+		pdf.Text(column.x, y, 10, i == len(columns)-1, truncate(column.value, column.width))
+	}
+	pdf.Rect(right-12, y-10, 12, 12)
+	pdf.Line(left, y+8, right, y+8)
+
+	pdf.Text(left, PageHeight-40, 8, false, "Generated "+slip.GeneratedAt.Format("2006-01-02 15:04 MST"))
+	return pdf.Bytes()
+}
+
+// truncate shortens s to at most n characters so it fits its column
+func truncate(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	return string(runes[:n-3]) + "..."
+}
@@ -0,0 +1,117 @@
+package document
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// A4 page size in points
+const (
+	PageWidth  = 595.0
+	PageHeight = 842.0
+)
+
+// PDF writes simple text documents as PDF 1.4 using the standard Helvetica fonts, which every
+// reader has, so nothing needs to be embedded. Text is encoded as WinAnsi; characters outside
+// Latin-1 are replaced with "?". Coordinates are in points from the top left of the page.
+type PDF struct {
+	pages []*bytes.Buffer
+}
+
+// NewPDF creates an empty document; AddPage starts its first page
+func NewPDF() *PDF {
+	return &PDF{}
+}
+
+// AddPage starts a new page; drawing goes to the last page added
+func (p *PDF) AddPage() {
+	p.pages = append(p.pages, &bytes.Buffer{})
+}
+
+// Text draws s with its baseline at (x, y) in Helvetica, or Helvetica-Bold when bold
+func (p *PDF) Text(x, y, size float64, bold bool, s string) {
+	font := "F1"
+	if bold {
+		font = "F2"
+	}
+	fmt.Fprintf(p.page(), "BT /%s %.1f Tf %.2f %.2f Td (%s) Tj ET\n", font, size, x, PageHeight-y, escapeText(s))
+}
+
+// Line draws a thin line from (x1, y1) to (x2, y2)
+func (p *PDF) Line(x1, y1, x2, y2 float64) {
+	fmt.Fprintf(p.page(), "0.5 w %.2f %.2f m %.2f %.2f l S\n", x1, PageHeight-y1, x2, PageHeight-y2)
+}
+
+// Rect draws the outline of a rectangle whose top left corner is (x, y)
+func (p *PDF) Rect(x, y, width, height float64) {
+	fmt.Fprintf(p.page(), "0.5 w %.2f %.2f %.2f %.2f re S\n", x, PageHeight-y-height, width, height)
+}
+
+// Bytes renders the document
+func (p *PDF) Bytes() []byte {
+	if len(p.pages) == 0 {
+		p.AddPage()
+	}
+
+	// Objects 1-4 are the catalog, the page tree and the two fonts; each page adds a page and a content object
+	objects := []string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"", // Page tree, once the page objects are numbered
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>",
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>",
+	}
+	kids := make([]string, len(p.pages))
+	for i, content := range p.pages {
+		pageNumber := len(objects) + 1
+		kids[i] = fmt.Sprintf("%d 0 R", pageNumber)
+		objects = append(objects,
+			fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %.0f %.0f] /Resources << /Font << /F1 3 0 R /F2 4 0 R >> >> /Contents %d 0 R >>",
+				PageWidth, PageHeight, pageNumber+1),
+			fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", content.Len(), content.String()),
+		)
+	}
+	objects[1] = fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(p.pages))
+
+	var out bytes.Buffer
+	out.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+	offsets := make([]int, len(objects))
+	for i, object := range objects {
+		offsets[i] = out.Len()
+		fmt.Fprintf(&out, "%d 0 obj\n%s\nendobj\n", i+1, object)
+	}
+	xref := out.Len()
+	fmt.Fprintf(&out, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&out, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&out, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xref)
+	return out.Bytes()
+}
+
+// page returns the page being drawn, starting the first one if needed
+func (p *PDF) page() *bytes.Buffer {
+	if len(p.pages) == 0 {
+		p.AddPage()
+	}
+	return p.pages[len(p.pages)-1]
+}
+
+// escapeText encodes s as a WinAnsi PDF string body
+func escapeText(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case r == '(' || r == ')' || r == '\\':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r == '\t':
+			b.WriteByte(' ')
+		case r < 0x20 || (r >= 0x7f && r < 0xa0) || r > 0xff:
+			b.WriteByte('?')
+		default:
+			b.WriteByte(byte(r))
+		}
+	}
+	return b.String()
+}
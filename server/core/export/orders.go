@@ -0,0 +1,312 @@
+// Package export writes orders to CSV and NDJSON files a row at a time, so exports of any size
+// stream without being held in memory
+package export
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"oms/server/core/model"
+)
+
+// ErrInvalidExport is returned for an unknown format or column
+var ErrInvalidExport = errors.New("invalid export")
+
+// productPrefix starts the names of the columns describing the ordered product as it is now in the
+// catalog, rather than as it was snapshotted on the order
+const productPrefix = "product."
+
+// historyColumn holds each order's state log, as a JSON array in CSV files
+const historyColumn = "history"
+
+// orderColumn is one exported field of an order. Values are strings, ints, bools, JSONB or nil.
+type orderColumn struct {
+	name  string
+	value func(o *model.Order) interface{}
+}
+
+// orderColumns are the columns an order export can have, in their default order. Amounts are
+// decimals in the order's currency.
+var orderColumns = []orderColumn{
+	{"id", func(o *model.Order) interface{} { return o.ID.String() }},
+	{"user_id", func(o *model.Order) interface{} { return o.UserID }},
+	{"status", func(o *model.Order) interface{} { return string(o.CurrentStatus) }},
+	{"product_id", func(o *model.Order) interface{} { return o.ProductID.String() }},
+	{"variant_id", func(o *model.Order) interface{} { return optionalID(o.VariantID) }},
+	{"sku", func(o *model.Order) interface{} { return o.SKU }},
+	{"product_name", func(o *model.Order) interface{} { return o.ProductName }},
+	{"quantity", func(o *model.Order) interface{} { return o.Quantity }},
+	{"cancelled_quantity", func(o *model.Order) interface{} { return o.CancelledQuantity }},
+	{"shipped_quantity", func(o *model.Order) interface{} { return o.ShippedQuantity }},
+	{"currency", func(o *model.Order) interface{} { return o.Total.Currency }},
+	{"unit_price", func(o *model.Order) interface{} { return o.UnitPrice.Decimal() }},
+	{"subtotal", func(o *model.Order) interface{} { return o.Subtotal.Decimal() }},
+	{"discount", func(o *model.Order) interface{} { return o.Discount.Decimal() }},
+	{"shipping", func(o *model.Order) interface{} { return o.Shipping.Decimal() }},
+	{"tax", func(o *model.Order) interface{} { return o.Tax.Decimal() }},
+	{"tax_included", func(o *model.Order) interface{} { return o.TaxIncluded.Decimal() }},
+	{"total", func(o *model.Order) interface{} { return o.Total.Decimal() }},
+	{"price_estimated", func(o *model.Order) interface{} { return o.PriceEstimated }},
+	{"shipping_option", func(o *model.Order) interface{} { return o.ShippingOption }},
+	{"shipping_carrier", func(o *model.Order) interface{} { return o.ShippingCarrier }},
+	{"checkout_id", func(o *model.Order) interface{} { return optionalID(o.CheckoutID) }},
+	{"metadata", func(o *model.Order) interface{} { return o.Metadata }},
+	{"created_at", func(o *model.Order) interface{} { return formatTime(o.CreatedAt) }},
+	{"updated_at", func(o *model.Order) interface{} { return formatTime(o.UpdatedAt) }},
+
+	// The product as it is now; empty when it no longer exists
+	{"product.sku", productValue(func(p *model.Product) interface{} { return p.SKU })},
+	{"product.name", productValue(func(p *model.Product) interface{} { return p.Name })},
+	{"product.price", productValue(func(p *model.Product) interface{} { return p.Price.Decimal() })},
+	{"product.currency", productValue(func(p *model.Product) interface{} { return p.Price.Currency })},
+	{"product.tax_class", productValue(func(p *model.Product) interface{} { return p.TaxClass })},
+	{"product.metadata", productValue(func(p *model.Product) interface{} { return p.Metadata })},
+	{"product.deleted", productValue(func(p *model.Product) interface{} { return p.DeletedAt.Valid })},
+}
+
+// OrderColumns returns the names of the columns an order export can have, in their default order.
+// Those starting with "product." need the product details.
+func OrderColumns() []string {
+	names := make([]string, len(orderColumns))
+	for i, column := range orderColumns {
+		names[i] = column.name
+	}
+	return names
+}
+
+// OrderRow is an exported order and, when the export includes it, its history
+type OrderRow struct {
+	Order   *model.Order
+	History []*model.OrderStateLog
+}
+
+// historyEntry is an exported state log entry
+type historyEntry struct {
+	PreviousStatus string `json:"previous_status"`
+	NewStatus      string `json:"new_status"`
+	ActorType      string `json:"actor_type"`
+	ActorID        string `json:"actor_id"`
+	ReasonCode     string `json:"reason_code,omitempty"`
+	Note           string `json:"note,omitempty"`
+	At             string `json:"at"`
+}
+
+// OrderWriter writes orders in an export's format and columns
+type OrderWriter struct {
+	format  model.FileFormat
+	columns []orderColumn
+	history bool
+	out     *bufio.Writer
+	csv     *csv.Writer
+}
+
+// NewOrderWriter checks the export's format and columns and starts writing to w; CSV files
+// start with a header row. Call Flush once every row is written.
+func NewOrderWriter(w io.Writer, export model.OrderExport) (*OrderWriter, error) {
+	columns, err := selectColumns(export)
+	if err != nil {
+		return nil, err
+	}
+	writer := &OrderWriter{format: export.Format, columns: columns, history: export.IncludeHistory, out: bufio.NewWriter(w)}
+	if export.Format == model.FormatCSV {
+		writer.csv = csv.NewWriter(writer.out)
+		header := make([]string, 0, len(columns)+1)
+		for _, column := range columns {
+			header = append(header, column.name)
+		}
+		if writer.history {
+			header = append(header, historyColumn)
+		}
+		if err := writer.csv.Write(header); err != nil {
+			return nil, err
+		}
+	}
+	return writer, nil
+}
+
+// CheckOrderExport checks the export's format and columns without writing anything
+func CheckOrderExport(export model.OrderExport) error {
+	_, err := selectColumns(export)
+	return err
+}
+
+// ContentType returns the media type of files in format
+func ContentType(format model.FileFormat) string {
+	if format == model.FormatNDJSON {
+		return "application/x-ndjson"
+	}
+	return "text/csv; charset=utf-8"
+}
+
+// Write writes one order
+func (w *OrderWriter) Write(row OrderRow) error {
+	if w.format == model.FormatCSV {
+		return w.writeCSV(row)
+	}
+	return w.writeNDJSON(row)
+}
+
+// Flush writes any buffered rows to the underlying writer
+func (w *OrderWriter) Flush() error {
+	if w.csv != nil {
+		w.csv.Flush()
+		if err := w.csv.Error(); err != nil {
+			return err
+		}
+	}
+	return w.out.Flush()
+}
+
+// writeCSV writes the row's values as text, with JSON for metadata and history
+func (w *OrderWriter) writeCSV(row OrderRow) error {
+	record := make([]string, 0, len(w.columns)+1)
+	for _, column := range w.columns {
+		switch value := column.value(row.Order).(type) {
+		case nil:
+			record = append(record, "")
+		case string:
+			record = append(record, value)
+		case int:
+			record = append(record, strconv.Itoa(value))
+		case bool:
+			record = append(record, strconv.FormatBool(value))
+		default:
+			data, err := json.Marshal(value)
+			if err != nil {
+				return err
+			}
+			record = append(record, string(data))
+		}
+	}
+	if w.history {
+		data, err := json.Marshal(historyEntries(row.History))
+		if err != nil {
+			return err
+		}
+		record = append(record, string(data))
+	}
+	return w.csv.Write(record)
+}
+
+// writeNDJSON writes the row as one JSON object with its keys in column order
+func (w *OrderWriter) writeNDJSON(row OrderRow) error {
+	var line bytes.Buffer
+	line.WriteByte('{')
+	field := func(name string, value interface{}) error {
+		if line.Len() > 1 {
+			line.WriteByte(',')
+		}
+		key, err := json.Marshal(name)
+		if err != nil {
+			return err
+		}
+		data, err := json.Marshal(value)
+		if err != nil {
+			return err
+		}
+		line.Write(key)
+		line.WriteByte(':')
+		line.Write(data)
+		return nil
+	}
+	for _, column := range w.columns {
+		if err := field(column.name, column.value(row.Order)); err != nil {
+			return err
+		}
+	}
+	if w.history {
+		if err := field(historyColumn, historyEntries(row.History)); err != nil {
+			return err
+		}
+	}
+	line.WriteString("}\n")
+	_, err := w.out.Write(line.Bytes())
+	return err
+}
+
+// selectColumns returns the export's columns: those it names, or every column it can have
+func selectColumns(export model.OrderExport) ([]orderColumn, error) {
+	if !export.Format.IsValid() {
+		return nil, fmt.Errorf("%w: format must be csv or ndjson", ErrInvalidExport)
+	}
+	if len(export.Columns) == 0 {
+		var columns []orderColumn
+		for _, column := range orderColumns {
+			if export.IncludeProduct || !strings.HasPrefix(column.name, productPrefix) {
+				columns = append(columns, column)
+			}
+		}
+		return columns, nil
+	}
+
+	byName := make(map[string]orderColumn, len(orderColumns))
+	for _, column := range orderColumns {
+		byName[column.name] = column
+	}
+	columns := make([]orderColumn, 0, len(export.Columns))
+	seen := map[string]bool{}
+	for _, name := range export.Columns {
+		column, ok := byName[name]
+		if !ok {
+			return nil, fmt.Errorf("%w: unknown column %q", ErrInvalidExport, name)
+		}
+		if strings.HasPrefix(name, productPrefix) && !export.IncludeProduct {
+			return nil, fmt.Errorf("%w: column %q needs the product details", ErrInvalidExport, name)
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("%w: column %q is selected twice", ErrInvalidExport, name)
+		}
+		seen[name] = true
+		columns = append(columns, column)
+	}
+	return columns, nil
+}
+
+// historyEntries converts state log entries to their exported form
+func historyEntries(logs []*model.OrderStateLog) []historyEntry {
+	entries := make([]historyEntry, len(logs))
+	for i, log := range logs {
+		entries[i] = historyEntry{
+			PreviousStatus: string(log.PreviousStatus),
+			NewStatus:      string(log.NewStatus),
+			ActorType:      string(log.ActorType),
+			ActorID:        log.ActorID,
+			ReasonCode:     log.ReasonCode,
+			Note:           log.Note,
+			At:             formatTime(log.UpdatedAt),
+		}
+	}
+	return entries
+}
+
+// productValue reads a column from the order's product, or nil when it was not found
+func productValue(value func(p *model.Product) interface{}) func(o *model.Order) interface{} {
+	return func(o *model.Order) interface{} {
+		if o.Product.ID == uuid.Nil {
+			return nil
+		}
+		return value(&o.Product)
+	}
+}
+
+// optionalID formats an optional ID, nil when unset
+func optionalID(id *uuid.UUID) interface{} {
+	if id == nil {
+		return nil
+	}
+	return id.String()
+}
+
+// formatTime formats a time in UTC as RFC 3339
+func formatTime(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
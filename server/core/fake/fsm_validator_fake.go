@@ -10,6 +10,8 @@ type FSMValidatorFake struct {
 	ValidateTransitionFunc        func(currentStatus, newStatus model.OrderStatus) error
 	IsValidStatusFunc             func(status model.OrderStatus) bool
 	RequiresInventoryRestoreFunc  func(status model.OrderStatus) bool
+	RequiresPaymentCaptureFunc    func(status model.OrderStatus) bool
+	RequiresPaymentReleaseFunc    func(status model.OrderStatus) bool
 }
 
 // ValidateTransition implements types.FSMValidator
@@ -36,6 +38,22 @@ func (f *FSMValidatorFake) RequiresInventoryRestore(status model.OrderStatus) bo
 	return false
 }
 
+// RequiresPaymentCapture implements types.FSMValidator
+func (f *FSMValidatorFake) RequiresPaymentCapture(status model.OrderStatus) bool {
+	if f.RequiresPaymentCaptureFunc != nil {
+		return f.RequiresPaymentCaptureFunc(status)
+	}
+	return false
+}
+
+// RequiresPaymentRelease implements types.FSMValidator
+func (f *FSMValidatorFake) RequiresPaymentRelease(status model.OrderStatus) bool {
+	if f.RequiresPaymentReleaseFunc != nil {
+		return f.RequiresPaymentReleaseFunc(status)
+	}
+	return false
+}
+
 // Ensure FSMValidatorFake implements types.FSMValidator
 var _ types.FSMValidator = (*FSMValidatorFake)(nil)
 
@@ -16,6 +16,7 @@ type InventoryStoreFake struct {
 	LockForUpdateFunc      func(ctx context.Context, productID uuid.UUID) (*model.Inventory, error)
 	DecrementQuantityFunc  func(ctx context.Context, productID uuid.UUID, quantity int) error
 	IncrementQuantityFunc  func(ctx context.Context, productID uuid.UUID, quantity int) error
+	UpdateQuantityFunc     func(ctx context.Context, productID uuid.UUID, quantity int) error
 }
 
 // inventoryMap maintains inventory state for fake store with mutex protection for race conditions
@@ -58,7 +59,7 @@ func (f *InventoryStoreFake) GetByProductID(ctx context.Context, productID uuid.
 		inv, exists = inventoryMap.m[productID]
 		if !exists {
 			defaultQty := getDefaultQuantity(productID)
-			inv = &model.Inventory{ProductID: productID, Quantity: defaultQty}
+			inv = &model.Inventory{StockUnitID: productID, Quantity: defaultQty}
 			inventoryMap.m[productID] = inv
 		}
 		inventoryMap.Unlock()
@@ -88,7 +89,7 @@ func (f *InventoryStoreFake) LockForUpdate(ctx context.Context, productID uuid.U
 	if !exists {
 		// Initialize with default quantity based on product ID
 		defaultQty := getDefaultQuantity(productID)
-		inv = &model.Inventory{ProductID: productID, Quantity: defaultQty}
+		inv = &model.Inventory{StockUnitID: productID, Quantity: defaultQty}
 		inventoryMap.m[productID] = inv
 	}
 	// Return a copy to prevent external modification
@@ -117,7 +118,7 @@ func (f *InventoryStoreFake) DecrementQuantity(ctx context.Context, productID uu
 	if !exists {
 		// This shouldn't happen if LockForUpdate was called first, but handle it
 		defaultQty := getDefaultQuantity(productID)
-		inv = &model.Inventory{ProductID: productID, Quantity: defaultQty}
+		inv = &model.Inventory{StockUnitID: productID, Quantity: defaultQty}
 		inventoryMap.m[productID] = inv
 	}
 	
@@ -156,7 +157,7 @@ func (f *InventoryStoreFake) IncrementQuantity(ctx context.Context, productID uu
 	
 	inv, exists := inventoryMap.m[productID]
 	if !exists {
-		inv = &model.Inventory{ProductID: productID, Quantity: 0}
+		inv = &model.Inventory{StockUnitID: productID, Quantity: 0}
 		inventoryMap.m[productID] = inv
 	}
 	
@@ -165,6 +166,57 @@ func (f *InventoryStoreFake) IncrementQuantity(ctx context.Context, productID uu
 	return nil
 }
 
+// UpdateQuantity implements types.InventoryStore
+// Sets the inventory quantity for a product (admin only)
+func (f *InventoryStoreFake) UpdateQuantity(ctx context.Context, productID uuid.UUID, quantity int) error {
+	if f.UpdateQuantityFunc != nil {
+		return f.UpdateQuantityFunc(ctx, productID, quantity)
+	}
+	if quantity < 0 {
+		return fmt.Errorf("quantity cannot be negative")
+	}
+
+	productLock := getProductLock(productID)
+	productLock.Lock()
+	defer productLock.Unlock()
+
+	inventoryMap.Lock()
+	defer inventoryMap.Unlock()
+	if inv, exists := inventoryMap.m[productID]; exists {
+		inv.Quantity = quantity
+		return nil
+	}
+	inventoryMap.m[productID] = &model.Inventory{StockUnitID: productID, Quantity: quantity}
+	return nil
+}
+
+// UpdateBinLocation implements types.InventoryStore
+func (f *InventoryStoreFake) UpdateBinLocation(ctx context.Context, productID uuid.UUID, binLocation string) error {
+	inventoryMap.Lock()
+	defer inventoryMap.Unlock()
+	inv, exists := inventoryMap.m[productID]
+	if !exists {
+		inv = &model.Inventory{StockUnitID: productID, Quantity: getDefaultQuantity(productID)}
+		inventoryMap.m[productID] = inv
+	}
+	inv.BinLocation = binLocation
+	return nil
+}
+
+// UpdateReorderPoint implements types.InventoryStore
+func (f *InventoryStoreFake) UpdateReorderPoint(ctx context.Context, productID uuid.UUID, reorderPoint, targetLevel *int) error {
+	inventoryMap.Lock()
+	defer inventoryMap.Unlock()
+	inv, exists := inventoryMap.m[productID]
+	if !exists {
+		inv = &model.Inventory{StockUnitID: productID, Quantity: getDefaultQuantity(productID)}
+		inventoryMap.m[productID] = inv
+	}
+	inv.ReorderPoint = reorderPoint
+	inv.TargetLevel = targetLevel
+	return nil
+}
+
 // getDefaultQuantity returns default inventory quantity for a product
 // This matches the initial values shown in the products endpoint
 func getDefaultQuantity(productID uuid.UUID) int {
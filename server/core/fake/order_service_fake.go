@@ -2,6 +2,7 @@ package fake
 
 import (
 	"context"
+	"time"
 
 	"github.com/google/uuid"
 	"oms/server/core/model"
@@ -10,12 +11,23 @@ import (
 
 // OrderServiceFake is a fake implementation of OrderService for testing
 type OrderServiceFake struct {
-	CreateOrderFunc        func(ctx context.Context, userID int, productID uuid.UUID, quantity int, metadata model.JSONB) (*model.Order, error)
-	UpdateOrderStatusFunc  func(ctx context.Context, orderID uuid.UUID, newStatus model.OrderStatus, updatedBy int) (*model.Order, error)
+	CreateOrderFunc        func(ctx context.Context, userID int, productID uuid.UUID, variantID *uuid.UUID, quantity int, metadata model.JSONB, couponCodes []string, shippingOption, paymentMethod string) (*model.Order, error)
+	CreateOrdersFunc       func(ctx context.Context, userID int, items []model.OrderItem, metadata model.JSONB, shippingOption, paymentMethod string) ([]*model.Order, error)
+	PreviewOrderFunc       func(ctx context.Context, userID int, productID uuid.UUID, variantID *uuid.UUID, quantity int, metadata model.JSONB, couponCodes []string, shippingOption string) (*model.Order, error)
+	UpdateOrderStatusFunc  func(ctx context.Context, orderID uuid.UUID, newStatus model.OrderStatus, change model.StatusChange) (*model.Order, error)
 	GetOrderByIDFunc       func(ctx context.Context, orderID uuid.UUID) (*model.Order, error)
 	GetOrdersByUserIDFunc  func(ctx context.Context, userID int) ([]*model.Order, error)
 	GetAllOrdersFunc       func(ctx context.Context) ([]*model.Order, error)
-	GetOrderHistoryFunc    func(ctx context.Context, orderID uuid.UUID) ([]*model.OrderStateLog, error)
+	ListOrdersFunc         func(ctx context.Context, filter model.OrderFilter) ([]*model.Order, error)
+	GetOrderHistoryFunc    func(ctx context.Context, orderID uuid.UUID, filter model.OrderHistoryFilter) ([]*model.OrderStateLog, error)
+	HandlePaymentWebhookFunc func(ctx context.Context, payload []byte, signature string) error
+	ShipOrderFunc          func(ctx context.Context, orderID uuid.UUID, shipment *model.Shipment, shippedBy int) (*model.Shipment, error)
+	DeliverShipmentFunc    func(ctx context.Context, shipment *model.Shipment) (*model.Order, error)
+	CancelQuantityFunc     func(ctx context.Context, orderID uuid.UUID, quantity int, change model.StatusChange) (*model.Order, error)
+	StartPickingFunc       func(ctx context.Context, orderID uuid.UUID, pickedBy int, note string) (*model.Order, error)
+	CancelStaleOrdersFunc  func(ctx context.Context, cutoff time.Time) (int, error)
+	GetShipmentsFunc       func(ctx context.Context, orderID uuid.UUID) ([]*model.Shipment, error)
+	QuoteShippingFunc      func(ctx context.Context, items []model.OrderItem, metadata model.JSONB) ([]model.ShippingOption, error)
 }
 
 // NewOrderServiceFake creates a new fake OrderService
@@ -24,21 +36,85 @@ func NewOrderServiceFake() services.OrderService {
 }
 
 // CreateOrder implements services.OrderService
-func (f *OrderServiceFake) CreateOrder(ctx context.Context, userID int, productID uuid.UUID, quantity int, metadata model.JSONB) (*model.Order, error) {
+func (f *OrderServiceFake) CreateOrder(ctx context.Context, userID int, productID uuid.UUID, variantID *uuid.UUID, quantity int, metadata model.JSONB, couponCodes []string, shippingOption, paymentMethod string) (*model.Order, error) {
 	if f.CreateOrderFunc != nil {
-		return f.CreateOrderFunc(ctx, userID, productID, quantity, metadata)
+		return f.CreateOrderFunc(ctx, userID, productID, variantID, quantity, metadata, couponCodes, shippingOption, paymentMethod)
 	}
 	return nil, nil
 }
 
+// CreateOrders implements services.OrderService
+func (f *OrderServiceFake) CreateOrders(ctx context.Context, userID int, items []model.OrderItem, metadata model.JSONB, shippingOption, paymentMethod string) ([]*model.Order, error) {
+	if f.CreateOrdersFunc != nil {
+		return f.CreateOrdersFunc(ctx, userID, items, metadata, shippingOption, paymentMethod)
+	}
+	return []*model.Order{}, nil
+}
+
+// PreviewOrder implements services.OrderService
+func (f *OrderServiceFake) PreviewOrder(ctx context.Context, userID int, productID uuid.UUID, variantID *uuid.UUID, quantity int, metadata model.JSONB, couponCodes []string, shippingOption string) (*model.Order, error) {
+	if f.PreviewOrderFunc != nil {
+		return f.PreviewOrderFunc(ctx, userID, productID, variantID, quantity, metadata, couponCodes, shippingOption)
+	}
+	return nil, nil
+}
+
+// QuoteShipping implements services.OrderService
+func (f *OrderServiceFake) QuoteShipping(ctx context.Context, items []model.OrderItem, metadata model.JSONB) ([]model.ShippingOption, error) {
+	if f.QuoteShippingFunc != nil {
+		return f.QuoteShippingFunc(ctx, items, metadata)
+	}
+	return []model.ShippingOption{}, nil
+}
+
 // UpdateOrderStatus implements services.OrderService
-func (f *OrderServiceFake) UpdateOrderStatus(ctx context.Context, orderID uuid.UUID, newStatus model.OrderStatus, updatedBy int) (*model.Order, error) {
+func (f *OrderServiceFake) UpdateOrderStatus(ctx context.Context, orderID uuid.UUID, newStatus model.OrderStatus, change model.StatusChange) (*model.Order, error) {
 	if f.UpdateOrderStatusFunc != nil {
-		return f.UpdateOrderStatusFunc(ctx, orderID, newStatus, updatedBy)
+		return f.UpdateOrderStatusFunc(ctx, orderID, newStatus, change)
 	}
 	return nil, nil
 }
 
+// ShipOrder implements services.OrderService
+func (f *OrderServiceFake) ShipOrder(ctx context.Context, orderID uuid.UUID, shipment *model.Shipment, shippedBy int) (*model.Shipment, error) {
+	if f.ShipOrderFunc != nil {
+		return f.ShipOrderFunc(ctx, orderID, shipment, shippedBy)
+	}
+	return nil, nil
+}
+
+// DeliverShipment implements services.OrderService
+func (f *OrderServiceFake) DeliverShipment(ctx context.Context, shipment *model.Shipment) (*model.Order, error) {
+	if f.DeliverShipmentFunc != nil {
+		return f.DeliverShipmentFunc(ctx, shipment)
+	}
+	return nil, nil
+}
+
+// CancelQuantity implements services.OrderService
+func (f *OrderServiceFake) CancelQuantity(ctx context.Context, orderID uuid.UUID, quantity int, change model.StatusChange) (*model.Order, error) {
+	if f.CancelQuantityFunc != nil {
+		return f.CancelQuantityFunc(ctx, orderID, quantity, change)
+	}
+	return nil, nil
+}
+
+// StartPicking implements services.OrderService
+func (f *OrderServiceFake) StartPicking(ctx context.Context, orderID uuid.UUID, pickedBy int, note string) (*model.Order, error) {
+	if f.StartPickingFunc != nil {
+		return f.StartPickingFunc(ctx, orderID, pickedBy, note)
+	}
+	return nil, nil
+}
+
+// GetShipments implements services.OrderService
+func (f *OrderServiceFake) GetShipments(ctx context.Context, orderID uuid.UUID) ([]*model.Shipment, error) {
+	if f.GetShipmentsFunc != nil {
+		return f.GetShipmentsFunc(ctx, orderID)
+	}
+	return []*model.Shipment{}, nil
+}
+
 // GetOrderByID implements services.OrderService
 func (f *OrderServiceFake) GetOrderByID(ctx context.Context, orderID uuid.UUID) (*model.Order, error) {
 	if f.GetOrderByIDFunc != nil {
@@ -63,14 +139,42 @@ func (f *OrderServiceFake) GetAllOrders(ctx context.Context) ([]*model.Order, er
 	return []*model.Order{}, nil
 }
 
+// ListOrders implements services.OrderService
+func (f *OrderServiceFake) ListOrders(ctx context.Context, filter model.OrderFilter) ([]*model.Order, error) {
+	if f.ListOrdersFunc != nil {
+		return f.ListOrdersFunc(ctx, filter)
+	}
+	return []*model.Order{}, nil
+}
+
 // GetOrderHistory implements services.OrderService
-func (f *OrderServiceFake) GetOrderHistory(ctx context.Context, orderID uuid.UUID) ([]*model.OrderStateLog, error) {
+func (f *OrderServiceFake) GetOrderHistory(ctx context.Context, orderID uuid.UUID, filter model.OrderHistoryFilter) ([]*model.OrderStateLog, error) {
 	if f.GetOrderHistoryFunc != nil {
-		return f.GetOrderHistoryFunc(ctx, orderID)
+		return f.GetOrderHistoryFunc(ctx, orderID, filter)
 	}
 	return []*model.OrderStateLog{}, nil
 }
 
+// HandlePaymentWebhook implements services.OrderService
+func (f *OrderServiceFake) HandlePaymentWebhook(ctx context.Context, payload []byte, signature string) error {
+	if f.HandlePaymentWebhookFunc != nil {
+		return f.HandlePaymentWebhookFunc(ctx, payload, signature)
+	}
+	return nil
+}
+
+// BackfillPriceSnapshots implements services.OrderService
+func (f *OrderServiceFake) BackfillPriceSnapshots(ctx context.Context) (int, int, error) {
+	return 0, 0, nil
+}
+
+// CancelStaleOrders implements services.OrderService
+func (f *OrderServiceFake) CancelStaleOrders(ctx context.Context, cutoff time.Time) (int, error) {
+	if f.CancelStaleOrdersFunc != nil {
+		return f.CancelStaleOrdersFunc(ctx, cutoff)
+	}
+	return 0, nil
+}
+
 // Ensure OrderServiceFake implements services.OrderService
 var _ services.OrderService = (*OrderServiceFake)(nil)
-
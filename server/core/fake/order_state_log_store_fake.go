@@ -2,6 +2,7 @@ package fake
 
 import (
 	"context"
+	"sort"
 
 	"github.com/google/uuid"
 	"oms/server/core/model"
@@ -11,7 +12,7 @@ import (
 // OrderStateLogStoreFake is a fake implementation of OrderStateLogStore for testing
 type OrderStateLogStoreFake struct {
 	CreateFunc    func(ctx context.Context, log *model.OrderStateLog) error
-	GetByOrderIDFunc func(ctx context.Context, orderID uuid.UUID) ([]*model.OrderStateLog, error)
+	GetByOrderIDFunc func(ctx context.Context, orderID uuid.UUID, filter model.OrderHistoryFilter) ([]*model.OrderStateLog, error)
 }
 
 var orderStateLogs = make(map[uuid.UUID][]*model.OrderStateLog)
@@ -26,17 +27,35 @@ func (f *OrderStateLogStoreFake) Create(ctx context.Context, log *model.OrderSta
 }
 
 // GetByOrderID implements types.OrderStateLogStore
-func (f *OrderStateLogStoreFake) GetByOrderID(ctx context.Context, orderID uuid.UUID) ([]*model.OrderStateLog, error) {
+func (f *OrderStateLogStoreFake) GetByOrderID(ctx context.Context, orderID uuid.UUID, filter model.OrderHistoryFilter) ([]*model.OrderStateLog, error) {
 	if f.GetByOrderIDFunc != nil {
-		return f.GetByOrderIDFunc(ctx, orderID)
+		return f.GetByOrderIDFunc(ctx, orderID, filter)
 	}
-	logs, exists := orderStateLogs[orderID]
-	if !exists {
-		return []*model.OrderStateLog{}, nil
+	logs := []*model.OrderStateLog{}
+	for _, log := range orderStateLogs[orderID] {
+		if filter.Reason != "" && log.ReasonCode != filter.Reason {
+			continue
+		}
+		if filter.ActorType != "" && log.ActorType != filter.ActorType {
+			continue
+		}
+		logs = append(logs, log)
 	}
 	return logs, nil
 }
 
+// GetByOrderIDs implements types.OrderStateLogStore
+func (f *OrderStateLogStoreFake) GetByOrderIDs(ctx context.Context, orderIDs []uuid.UUID) ([]*model.OrderStateLog, error) {
+	logs := []*model.OrderStateLog{}
+	for _, orderID := range orderIDs {
+		logs = append(logs, orderStateLogs[orderID]...)
+	}
+	sort.SliceStable(logs, func(i, j int) bool {
+		return logs[i].UpdatedAt.Before(logs[j].UpdatedAt)
+	})
+	return logs, nil
+}
+
 // Ensure OrderStateLogStoreFake implements types.OrderStateLogStore
 var _ types.OrderStateLogStore = (*OrderStateLogStoreFake)(nil)
 
@@ -3,6 +3,7 @@ package fake
 import (
 	"context"
 	"fmt"
+	"sort"
 	"sync"
 	"time"
 
@@ -16,7 +17,7 @@ type OrderStoreFake struct {
 	CreateFunc      func(ctx context.Context, order *model.Order) error
 	GetByIDFunc     func(ctx context.Context, orderID uuid.UUID) (*model.Order, error)
 	GetByUserIDFunc func(ctx context.Context, userID int) ([]*model.Order, error)
-	UpdateStatusFunc func(ctx context.Context, orderID uuid.UUID, status model.OrderStatus) error
+	UpdateStatusFunc func(ctx context.Context, orderID uuid.UUID, previous, status model.OrderStatus) (bool, error)
 }
 
 var orders = struct {
@@ -41,6 +42,16 @@ func (f *OrderStoreFake) Create(ctx context.Context, order *model.Order) error {
 	return nil
 }
 
+// CreateAll implements types.OrderStore
+func (f *OrderStoreFake) CreateAll(ctx context.Context, newOrders []*model.Order) error {
+	for _, order := range newOrders {
+		if err := f.Create(ctx, order); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // GetByID implements types.OrderStore
 func (f *OrderStoreFake) GetByID(ctx context.Context, orderID uuid.UUID) (*model.Order, error) {
 	if f.GetByIDFunc != nil {
@@ -86,22 +97,134 @@ func (f *OrderStoreFake) GetAll(ctx context.Context) ([]*model.Order, error) {
 	return allOrders, nil
 }
 
+// Search implements types.OrderStore
+func (f *OrderStoreFake) Search(ctx context.Context, filter model.OrderFilter) ([]*model.Order, error) {
+	matching := matchingOrders(filter)
+	sort.Slice(matching, func(i, j int) bool {
+		return matching[i].CreatedAt.After(matching[j].CreatedAt)
+	})
+	return matching, nil
+}
+
+// Each implements types.OrderStore
+func (f *OrderStoreFake) Each(ctx context.Context, filter model.OrderFilter, batchSize int, withProduct bool, fn func(orders []*model.Order) error) error {
+	matching := matchingOrders(filter)
+	sort.Slice(matching, func(i, j int) bool {
+		return matching[i].CreatedAt.Before(matching[j].CreatedAt)
+	})
+	for start := 0; start < len(matching); start += batchSize {
+		end := start + batchSize
+		if end > len(matching) {
+			end = len(matching)
+		}
+		if err := fn(matching[start:end]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// matchingOrders returns copies of the orders matching filter
+func matchingOrders(filter model.OrderFilter) []*model.Order {
+	orders.RLock()
+	defer orders.RUnlock()
+	var matching []*model.Order
+	for _, order := range orders.m {
+		if filter.Matches(order) {
+			copiedOrder := *order
+			matching = append(matching, &copiedOrder)
+		}
+	}
+	return matching
+}
+
+// GetByStatus implements types.OrderStore
+func (f *OrderStoreFake) GetByStatus(ctx context.Context, statuses ...model.OrderStatus) ([]*model.Order, error) {
+	orders.RLock()
+	defer orders.RUnlock()
+	var matching []*model.Order
+	for _, order := range orders.m {
+		for _, status := range statuses {
+			if order.CurrentStatus == status {
+				copiedOrder := *order
+				matching = append(matching, &copiedOrder)
+				break
+			}
+		}
+	}
+	sort.Slice(matching, func(i, j int) bool {
+		return matching[i].CreatedAt.Before(matching[j].CreatedAt)
+	})
+	return matching, nil
+}
+
 // UpdateStatus implements types.OrderStore
-func (f *OrderStoreFake) UpdateStatus(ctx context.Context, orderID uuid.UUID, status model.OrderStatus) error {
+func (f *OrderStoreFake) UpdateStatus(ctx context.Context, orderID uuid.UUID, previous, status model.OrderStatus) (bool, error) {
 	if f.UpdateStatusFunc != nil {
-		return f.UpdateStatusFunc(ctx, orderID, status)
+		return f.UpdateStatusFunc(ctx, orderID, previous, status)
 	}
 	orders.Lock()
 	defer orders.Unlock()
 	order, exists := orders.m[orderID]
-	if !exists {
-		return fmt.Errorf("order not found")
+	if !exists || order.CurrentStatus != previous {
+		return false, nil
 	}
 	order.CurrentStatus = status
 	order.UpdatedAt = time.Now()
+	return true, nil
+}
+
+// GetWithoutPriceSnapshot implements types.OrderStore
+func (f *OrderStoreFake) GetWithoutPriceSnapshot(ctx context.Context) ([]*model.Order, error) {
+	orders.RLock()
+	defer orders.RUnlock()
+	var pending []*model.Order
+	for _, order := range orders.m {
+		if !order.HasPriceSnapshot() {
+			copiedOrder := *order
+			pending = append(pending, &copiedOrder)
+		}
+	}
+	return pending, nil
+}
+
+// UpdatePricing implements types.OrderStore
+func (f *OrderStoreFake) UpdatePricing(ctx context.Context, order *model.Order) error {
+	orders.Lock()
+	defer orders.Unlock()
+	existing, exists := orders.m[order.ID]
+	if !exists {
+		return fmt.Errorf("order not found")
+	}
+	existing.SKU = order.SKU
+	existing.ProductName = order.ProductName
+	existing.UnitPrice = order.UnitPrice
+	existing.PriceEstimated = order.PriceEstimated
+	existing.Subtotal = order.Subtotal
+	existing.Discount = order.Discount
+	existing.Shipping = order.Shipping
+	existing.Tax = order.Tax
+	existing.TaxIncluded = order.TaxIncluded
+	existing.Total = order.Total
+	existing.UpdatedAt = time.Now()
 	return nil
 }
 
+// CancelQuantity implements types.OrderStore
+func (f *OrderStoreFake) CancelQuantity(ctx context.Context, orderID uuid.UUID, quantity int) (bool, error) {
+	orders.Lock()
+	defer orders.Unlock()
+	existing, exists := orders.m[orderID]
+	if !exists {
+		return false, fmt.Errorf("order not found")
+	}
+	if quantity > existing.OpenQuantity() {
+		return false, nil
+	}
+	existing.CancelledQuantity += quantity
+	existing.UpdatedAt = time.Now()
+	return true, nil
+}
+
 // Ensure OrderStoreFake implements types.OrderStore
 var _ types.OrderStore = (*OrderStoreFake)(nil)
-
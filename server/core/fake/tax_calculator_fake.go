@@ -0,0 +1,24 @@
+package fake
+
+import (
+	"context"
+
+	"oms/server/core/model"
+	"oms/server/core/types"
+)
+
+// TaxCalculatorFake is a fake implementation of TaxCalculator for testing
+type TaxCalculatorFake struct {
+	CalculateFunc func(ctx context.Context, lines []model.TaxableLine, address model.TaxAddress) ([]model.OrderTaxLine, error)
+}
+
+// Calculate implements types.TaxCalculator. Without CalculateFunc nothing is taxed.
+func (f *TaxCalculatorFake) Calculate(ctx context.Context, lines []model.TaxableLine, address model.TaxAddress) ([]model.OrderTaxLine, error) {
+	if f.CalculateFunc != nil {
+		return f.CalculateFunc(ctx, lines, address)
+	}
+	return []model.OrderTaxLine{}, nil
+}
+
+// Ensure TaxCalculatorFake implements types.TaxCalculator
+var _ types.TaxCalculator = (*TaxCalculatorFake)(nil)
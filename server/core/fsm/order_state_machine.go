@@ -6,17 +6,51 @@ import (
 	"oms/server/core/model"
 )
 
-// StateTransition defines allowed transitions for order states
+// StateTransition defines allowed transitions for order states.
+// ORDERED may ship directly when the order was placed without payment.
+// Orders waiting to ship go to PICKING when they are put on a pick list, which is optional.
+// An order shipped in several shipments is PARTIALLY_SHIPPED until the units not
+// cancelled have all shipped; it can no longer be cancelled as a whole.
+// Delivered orders may be returned in one or more return requests; a rejected
+// request puts the order back where it was.
 var StateTransition = map[model.OrderStatus][]model.OrderStatus{
 	model.OrderStatusOrdered: {
+		model.OrderStatusPaid,
+		model.OrderStatusPicking,
+		model.OrderStatusPartiallyShipped,
 		model.OrderStatusShipped,
 		model.OrderStatusCancelled,
 	},
+	model.OrderStatusPaid: {
+		model.OrderStatusPicking,
+		model.OrderStatusPartiallyShipped,
+		model.OrderStatusShipped,
+		model.OrderStatusCancelled,
+	},
+	model.OrderStatusPicking: {
+		model.OrderStatusPartiallyShipped,
+		model.OrderStatusShipped,
+		model.OrderStatusCancelled,
+	},
+	model.OrderStatusPartiallyShipped: {
+		model.OrderStatusShipped,
+	},
 	model.OrderStatusShipped: {
 		model.OrderStatusDelivered,
 	},
 	model.OrderStatusDelivered: {
-		// No transitions allowed from DELIVERED
+		model.OrderStatusReturnRequested,
+	},
+	model.OrderStatusReturnRequested: {
+		model.OrderStatusDelivered,
+		model.OrderStatusPartiallyReturned,
+		model.OrderStatusReturned,
+	},
+	model.OrderStatusPartiallyReturned: {
+		model.OrderStatusReturnRequested,
+	},
+	model.OrderStatusReturned: {
+		// No transitions allowed from RETURNED
 	},
 	model.OrderStatusCancelled: {
 		// No transitions allowed from CANCELLED
@@ -56,3 +90,14 @@ func RequiresInventoryRestore(status model.OrderStatus) bool {
 	return status == model.OrderStatusCancelled
 }
 
+
+// RequiresPaymentCapture checks if transitioning to this status requires the order's payment to be captured.
+// The payment is captured with the first shipment.
+func RequiresPaymentCapture(status model.OrderStatus) bool {
+	return status == model.OrderStatusShipped || status == model.OrderStatusPartiallyShipped
+}
+
+// RequiresPaymentRelease checks if transitioning to this status requires the order's payment to be voided or refunded
+func RequiresPaymentRelease(status model.OrderStatus) bool {
+	return status == model.OrderStatusCancelled
+}
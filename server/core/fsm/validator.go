@@ -28,6 +28,16 @@ func (v *validator) RequiresInventoryRestore(status model.OrderStatus) bool {
 	return RequiresInventoryRestore(status)
 }
 
+// RequiresPaymentCapture implements types.FSMValidator
+func (v *validator) RequiresPaymentCapture(status model.OrderStatus) bool {
+	return RequiresPaymentCapture(status)
+}
+
+// RequiresPaymentRelease implements types.FSMValidator
+func (v *validator) RequiresPaymentRelease(status model.OrderStatus) bool {
+	return RequiresPaymentRelease(status)
+}
+
 // Ensure validator implements types.FSMValidator
 var _ types.FSMValidator = (*validator)(nil)
 
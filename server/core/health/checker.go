@@ -0,0 +1,98 @@
+package health
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Status is the outcome of a health check
+type Status string
+
+const (
+	StatusUp   Status = "up"
+	StatusDown Status = "down"
+)
+
+// CheckFunc reports whether a dependency is healthy by returning a nil error
+type CheckFunc func(ctx context.Context) error
+
+// CheckResult is the outcome of a single named check
+type CheckResult struct {
+	Status    Status `json:"status"`
+	Error     string `json:"error,omitempty"`
+	LatencyMS int64  `json:"latency_ms"`
+}
+
+// Report is the aggregated outcome of all registered checks
+type Report struct {
+	Status    Status                 `json:"status"`
+	Checks    map[string]CheckResult `json:"checks"`
+	CheckedAt time.Time              `json:"checked_at"`
+}
+
+// Checker runs a set of named readiness checks
+type Checker struct {
+	mu     sync.RWMutex
+	names  []string
+	checks map[string]CheckFunc
+}
+
+// NewChecker creates a new Checker with no registered checks
+func NewChecker() *Checker {
+	return &Checker{checks: make(map[string]CheckFunc)}
+}
+
+// Register adds a named check, replacing any existing check with the same name
+func (c *Checker) Register(name string, check CheckFunc) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, exists := c.checks[name]; !exists {
+		c.names = append(c.names, name)
+	}
+	c.checks[name] = check
+}
+
+// Run executes all checks concurrently and aggregates the results.
+// The report is down if any single check fails.
+func (c *Checker) Run(ctx context.Context) Report {
+	c.mu.RLock()
+	names := append([]string(nil), c.names...)
+	checks := make(map[string]CheckFunc, len(c.checks))
+	for name, check := range c.checks {
+		checks[name] = check
+	}
+	c.mu.RUnlock()
+
+	report := Report{
+		Status:    StatusUp,
+		Checks:    make(map[string]CheckResult, len(names)),
+		CheckedAt: time.Now(),
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, name := range names {
+		wg.Add(1)
+		go func(name string, check CheckFunc) {
+			defer wg.Done()
+			start := time.Now()
+			err := check(ctx)
+			result := CheckResult{Status: StatusUp, LatencyMS: time.Since(start).Milliseconds()}
+			if err != nil {
+				result.Status = StatusDown
+				result.Error = err.Error()
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			report.Checks[name] = result
+			if result.Status == StatusDown {
+				report.Status = StatusDown
+			}
+		}(name, checks[name])
+	}
+	wg.Wait()
+
+	return report
+}
@@ -0,0 +1,298 @@
+// Package imports reads product catalogs from CSV and NDJSON files a row at a time, so imports of
+// any size stream without being held in memory
+package imports
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"oms/server/core/model"
+)
+
+// ErrInvalidImport is returned for an unknown format, or a file that can't be read at all
+var ErrInvalidImport = errors.New("invalid import")
+
+// metaPrefix starts the names of CSV columns holding a metadata key
+const metaPrefix = "meta."
+
+// maxLineSize is the longest NDJSON line read
+const maxLineSize = 1 << 20
+
+// productColumns are the CSV columns besides the metadata ones
+var productColumns = map[string]bool{
+	"sku": true, "name": true, "price": true, "currency": true, "tax_class": true,
+	"quantity": true, "bin_location": true,
+}
+
+// ProductRecord is one row of a product import. Nil fields, empty CSV cells and null or missing
+// NDJSON fields, leave the product's value as it is. Metadata holds the keys the row sets, merged
+// into the product's metadata; a nil value removes the key. Errors lists what is wrong with the
+// row as read.
+type ProductRecord struct {
+	Line        int
+	SKU         string
+	Name        *string
+	Price       *string // An amount, in the Currency, or with a currency code such as "12.99 USD"
+	Currency    *string
+	TaxClass    *string
+	Quantity    *int
+	BinLocation *string
+	Metadata    map[string]interface{}
+	Errors      []model.ImportError
+}
+
+// fail records an error with the row; the SKU is filled in once the whole row is read
+func (r *ProductRecord) fail(field, format string, args ...interface{}) {
+	r.Errors = append(r.Errors, model.ImportError{Line: r.Line, Field: field, Message: fmt.Sprintf(format, args...)})
+}
+
+// setSKU names the row's SKU in its errors
+func (r *ProductRecord) setSKU() {
+	for i := range r.Errors {
+		r.Errors[i].SKU = r.SKU
+	}
+}
+
+// ProductReader reads the rows of a product import
+type ProductReader struct {
+	format model.FileFormat
+
+	// CSV
+	csv    *csv.Reader
+	header []string
+
+	// NDJSON
+	lines *bufio.Scanner
+	line  int
+}
+
+// NewProductReader starts reading a file in format from r. CSV files start with a header row naming
+// their columns: sku, which is required, name, price, currency, tax_class, quantity, bin_location,
+// and meta.<key> for each metadata key.
+func NewProductReader(r io.Reader, format model.FileFormat) (*ProductReader, error) {
+	switch format {
+	case model.FormatCSV:
+		reader := &ProductReader{format: format, csv: csv.NewReader(r)}
+		reader.csv.ReuseRecord = false
+		header, err := reader.csv.Read()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil, fmt.Errorf("%w: the file is empty", ErrInvalidImport)
+			}
+			return nil, fmt.Errorf("%w: %v", ErrInvalidImport, err)
+		}
+		if err := reader.readHeader(header); err != nil {
+			return nil, err
+		}
+		return reader, nil
+	case model.FormatNDJSON:
+		lines := bufio.NewScanner(r)
+		lines.Buffer(make([]byte, 0, 64*1024), maxLineSize)
+		return &ProductReader{format: format, lines: lines}, nil
+	default:
+		return nil, fmt.Errorf("%w: format must be csv or ndjson", ErrInvalidImport)
+	}
+}
+
+// readHeader checks the CSV header's column names
+func (r *ProductReader) readHeader(header []string) error {
+	seen := map[string]bool{}
+	for i, column := range header {
+		column = strings.TrimSpace(column)
+		if i == 0 {
+			column = strings.TrimPrefix(column, "\ufeff") // Spreadsheet exports often start with a byte order mark
+		}
+		name := strings.ToLower(column)
+		switch {
+		case productColumns[name]:
+		case strings.HasPrefix(name, metaPrefix) && len(name) > len(metaPrefix):
+			// Metadata keys keep their case
+			name = metaPrefix + column[len(metaPrefix):]
+		default:
+			return fmt.Errorf("%w: unknown column %q", ErrInvalidImport, column)
+		}
+		if seen[name] {
+			return fmt.Errorf("%w: column %q appears twice", ErrInvalidImport, name)
+		}
+		seen[name] = true
+		r.header = append(r.header, name)
+	}
+	if !seen["sku"] {
+		return fmt.Errorf("%w: the sku column is required", ErrInvalidImport)
+	}
+	return nil
+}
+
+// Read reads the next row, returning io.EOF at the end of the file. Rows that can't be read are
+// returned with their Errors set; an error stops the import at a line where the rest of the file
+// can't be told apart, such as an unterminated quote.
+func (r *ProductReader) Read() (*ProductRecord, error) {
+	if r.format == model.FormatCSV {
+		return r.readCSV()
+	}
+	return r.readNDJSON()
+}
+
+// readCSV reads a CSV row, trimming the cells' surrounding spaces
+func (r *ProductReader) readCSV() (*ProductRecord, error) {
+	cells, err := r.csv.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, io.EOF
+	}
+	record := &ProductRecord{}
+	if len(cells) > 0 {
+		record.Line, _ = r.csv.FieldPos(0)
+	}
+	if err != nil {
+		var parseErr *csv.ParseError
+		if !errors.As(err, &parseErr) || !errors.Is(err, csv.ErrFieldCount) {
+			return nil, err
+		}
+		record.Line = parseErr.StartLine
+		record.fail("", "has %d cells, the header has %d", len(cells), len(r.header))
+		return record, nil
+	}
+
+	for i, cell := range cells {
+		value := strings.TrimSpace(cell)
+		if value == "" {
+			continue
+		}
+		switch name := r.header[i]; name {
+		case "sku":
+			record.SKU = value
+		case "name":
+			record.Name = &value
+		case "price":
+			record.Price = &value
+		case "currency":
+			record.Currency = &value
+		case "tax_class":
+			record.TaxClass = &value
+		case "bin_location":
+			record.BinLocation = &value
+		case "quantity":
+			quantity, err := strconv.Atoi(value)
+			if err != nil {
+				record.fail(name, "must be a whole number, got %q", value)
+				continue
+			}
+			record.Quantity = &quantity
+		default:
+			if record.Metadata == nil {
+				record.Metadata = map[string]interface{}{}
+			}
+			record.Metadata[strings.TrimPrefix(name, metaPrefix)] = metadataCell(value)
+		}
+	}
+	record.setSKU()
+	return record, nil
+}
+
+// metadataCell reads a metadata cell: JSON, such as 12, true or ["a","b"], when it parses as JSON,
+// and text otherwise. null removes the key.
+func metadataCell(value string) interface{} {
+	var decoded interface{}
+	if err := json.Unmarshal([]byte(value), &decoded); err == nil {
+		return decoded
+	}
+	return value
+}
+
+// readNDJSON reads the next non-blank line as a JSON object with the CSV columns as fields, except
+// metadata, which is an object
+func (r *ProductReader) readNDJSON() (*ProductRecord, error) {
+	var line []byte
+	for len(line) == 0 {
+		if !r.lines.Scan() {
+			if err := r.lines.Err(); err != nil {
+				if errors.Is(err, bufio.ErrTooLong) {
+					return nil, fmt.Errorf("line %d is longer than %d bytes", r.line+1, maxLineSize)
+				}
+				return nil, err
+			}
+			return nil, io.EOF
+		}
+		r.line++
+		line = bytes.TrimSpace(r.lines.Bytes())
+	}
+
+	record := &ProductRecord{Line: r.line}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(line, &fields); err != nil {
+		record.fail("", "is not a JSON object: %v", err)
+		return record, nil
+	}
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names) // So errors come in the same order every time
+	for _, name := range names {
+		raw := fields[name]
+		if string(raw) == "null" {
+			continue
+		}
+		switch name {
+		case "sku":
+			if sku := r.stringField(record, name, raw); sku != nil {
+				record.SKU = *sku
+			}
+		case "name":
+			record.Name = r.stringField(record, name, raw)
+		case "price":
+			// Prices may be numbers as well as the API's "12.99 USD" strings
+			if raw[0] != '"' {
+				if _, err := strconv.ParseFloat(string(raw), 64); err == nil {
+					price := string(raw)
+					record.Price = &price
+					continue
+				}
+			}
+			record.Price = r.stringField(record, name, raw)
+		case "currency":
+			record.Currency = r.stringField(record, name, raw)
+		case "tax_class":
+			record.TaxClass = r.stringField(record, name, raw)
+		case "bin_location":
+			record.BinLocation = r.stringField(record, name, raw)
+		case "quantity":
+			var quantity int
+			if err := json.Unmarshal(raw, &quantity); err != nil {
+				record.fail(name, "must be a whole number, got %s", raw)
+				continue
+			}
+			record.Quantity = &quantity
+		case "metadata":
+			if err := json.Unmarshal(raw, &record.Metadata); err != nil {
+				record.fail(name, "must be an object, got %s", raw)
+			}
+		default:
+			record.fail(name, "is not a product field")
+		}
+	}
+	record.setSKU()
+	return record, nil
+}
+
+// stringField reads a string field, trimmed, or nil when it is empty or not a string
+func (r *ProductReader) stringField(record *ProductRecord, name string, raw json.RawMessage) *string {
+	var value string
+	if err := json.Unmarshal(raw, &value); err != nil {
+		record.fail(name, "must be a string, got %s", raw)
+		return nil
+	}
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil
+	}
+	return &value
+}
@@ -0,0 +1,591 @@
+// Package jsonschema validates decoded JSON documents against a practical
+// subset of JSON Schema (draft 2020-12 keywords without references).
+//
+// Supported: type, enum, const, properties, required, additionalProperties,
+// minProperties, maxProperties, items, minItems, maxItems, uniqueItems,
+// minLength, maxLength, pattern, format (email, date, date-time, uri),
+// minimum, maximum, exclusiveMinimum, exclusiveMaximum, multipleOf,
+// allOf, anyOf, oneOf and not. Annotations such as title and description
+// are ignored; any other keyword is rejected by Compile so a schema never
+// silently validates less than its author intended.
+package jsonschema
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/mail"
+	"net/url"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// ValidationError is a single violation, located by a JSON Pointer into the document
+type ValidationError struct {
+	Path    string `json:"path"` // e.g. "/address/postcode"; "" is the document root
+	Message string `json:"message"`
+}
+
+// Error implements the error interface
+func (e ValidationError) Error() string {
+	path := e.Path
+	if path == "" {
+		path = "/"
+	}
+	return path + ": " + e.Message
+}
+
+// SchemaError reports an invalid or unsupported keyword, located by a JSON Pointer into the schema
+type SchemaError struct {
+	Path    string
+	Message string
+}
+
+// Error implements the error interface
+func (e *SchemaError) Error() string {
+	return e.Path + ": " + e.Message
+}
+
+// Schema is a compiled JSON Schema
+type Schema struct {
+	alwaysFalse bool // The boolean schema false
+
+	types    []string
+	enum     []interface{}
+	constVal interface{}
+	hasConst bool
+
+	properties           map[string]*Schema
+	required             []string
+	additionalProperties *Schema
+	minProperties        *int
+	maxProperties        *int
+
+	items       *Schema
+	minItems    *int
+	maxItems    *int
+	uniqueItems bool
+
+	minLength *int
+	maxLength *int
+	pattern   *regexp.Regexp
+	format    string
+
+	minimum          *float64
+	maximum          *float64
+	exclusiveMinimum *float64
+	exclusiveMaximum *float64
+	multipleOf       *float64
+
+	allOf []*Schema
+	anyOf []*Schema
+	oneOf []*Schema
+	not   *Schema
+}
+
+// annotations are keywords that carry no validation semantics
+var annotations = map[string]bool{
+	"$schema": true, "$id": true, "$comment": true, "title": true, "description": true,
+	"default": true, "examples": true, "deprecated": true, "readOnly": true, "writeOnly": true,
+}
+
+// validTypes are the JSON Schema type names
+var validTypes = map[string]bool{
+	"null": true, "boolean": true, "object": true, "array": true, "number": true, "integer": true, "string": true,
+}
+
+// Parse decodes and compiles a schema from JSON text
+func Parse(data []byte) (*Schema, error) {
+	var raw interface{}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("schema is not valid JSON: %w", err)
+	}
+	return Compile(raw)
+}
+
+// Compile builds a Schema from a decoded JSON value (an object or a boolean)
+func Compile(raw interface{}) (*Schema, error) {
+	return compile(raw, "")
+}
+
+func compile(raw interface{}, at string) (*Schema, error) {
+	switch value := raw.(type) {
+	case bool:
+		return &Schema{alwaysFalse: !value}, nil
+	case map[string]interface{}:
+		return compileObject(value, at)
+	default:
+		return nil, &SchemaError{Path: pointerOrRoot(at), Message: "schema must be an object or a boolean"}
+	}
+}
+
+func compileObject(raw map[string]interface{}, at string) (*Schema, error) {
+	s := &Schema{}
+	keywords := make([]string, 0, len(raw))
+	for keyword := range raw {
+		keywords = append(keywords, keyword)
+	}
+	sort.Strings(keywords)
+
+	for _, keyword := range keywords {
+		value := raw[keyword]
+		here := at + "/" + escapePointer(keyword)
+		var err error
+		switch keyword {
+		case "type":
+			s.types, err = compileTypes(value)
+		case "enum":
+			values, ok := value.([]interface{})
+			if !ok || len(values) == 0 {
+				err = fmt.Errorf("must be a non-empty array")
+			}
+			s.enum = values
+		case "const":
+			s.constVal, s.hasConst = value, true
+		case "properties":
+			props, ok := value.(map[string]interface{})
+			if !ok {
+				err = fmt.Errorf("must be an object")
+				break
+			}
+			s.properties = make(map[string]*Schema, len(props))
+			for name, sub := range props {
+				if s.properties[name], err = compile(sub, here+"/"+escapePointer(name)); err != nil {
+					return nil, err
+				}
+			}
+		case "required":
+			s.required, err = compileStrings(value)
+		case "additionalProperties":
+			s.additionalProperties, err = compile(value, here)
+		case "items":
+			s.items, err = compile(value, here)
+		case "allOf", "anyOf", "oneOf":
+			var subs []*Schema
+			subs, err = compileList(value, here)
+			switch keyword {
+			case "allOf":
+				s.allOf = subs
+			case "anyOf":
+				s.anyOf = subs
+			default:
+				s.oneOf = subs
+			}
+		case "not":
+			s.not, err = compile(value, here)
+		case "minProperties":
+			s.minProperties, err = compileCount(value)
+		case "maxProperties":
+			s.maxProperties, err = compileCount(value)
+		case "minItems":
+			s.minItems, err = compileCount(value)
+		case "maxItems":
+			s.maxItems, err = compileCount(value)
+		case "minLength":
+			s.minLength, err = compileCount(value)
+		case "maxLength":
+			s.maxLength, err = compileCount(value)
+		case "uniqueItems":
+			unique, ok := value.(bool)
+			if !ok {
+				err = fmt.Errorf("must be a boolean")
+			}
+			s.uniqueItems = unique
+		case "pattern":
+			pattern, ok := value.(string)
+			if !ok {
+				err = fmt.Errorf("must be a string")
+				break
+			}
+			s.pattern, err = regexp.Compile(pattern)
+		case "format":
+			format, ok := value.(string)
+			if !ok {
+				err = fmt.Errorf("must be a string")
+			}
+			s.format = format
+		case "minimum":
+			s.minimum, err = compileNumber(value)
+		case "maximum":
+			s.maximum, err = compileNumber(value)
+		case "exclusiveMinimum":
+			s.exclusiveMinimum, err = compileNumber(value)
+		case "exclusiveMaximum":
+			s.exclusiveMaximum, err = compileNumber(value)
+		case "multipleOf":
+			s.multipleOf, err = compileNumber(value)
+			if err == nil && *s.multipleOf <= 0 {
+				err = fmt.Errorf("must be greater than 0")
+			}
+		default:
+			if !annotations[keyword] {
+				err = fmt.Errorf("unsupported keyword")
+			}
+		}
+		if err != nil {
+			if located, ok := err.(*SchemaError); ok {
+				return nil, located
+			}
+			return nil, &SchemaError{Path: here, Message: err.Error()}
+		}
+	}
+	return s, nil
+}
+
+// Validate checks doc against the schema and returns every violation found, in a stable order
+func (s *Schema) Validate(doc interface{}) []ValidationError {
+	return s.validate(normalize(doc), "")
+}
+
+func (s *Schema) validate(value interface{}, path string) []ValidationError {
+	if s.alwaysFalse {
+		return []ValidationError{{Path: path, Message: "is not allowed"}}
+	}
+
+	var errs []ValidationError
+	fail := func(format string, args ...interface{}) {
+		errs = append(errs, ValidationError{Path: path, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if len(s.types) > 0 && !matchesAnyType(value, s.types) {
+		fail("must be %s, got %s", strings.Join(s.types, " or "), typeOf(value))
+		return errs
+	}
+	if s.enum != nil && !containsValue(s.enum, value) {
+		fail("must be one of %s", formatValues(s.enum))
+	}
+	if s.hasConst && !reflect.DeepEqual(normalize(s.constVal), value) {
+		fail("must be %s", formatValues([]interface{}{s.constVal}))
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		errs = append(errs, s.validateObject(v, path)...)
+	case []interface{}:
+		errs = append(errs, s.validateArray(v, path)...)
+	case string:
+		errs = append(errs, s.validateString(v, path)...)
+	case float64:
+		errs = append(errs, s.validateNumber(v, path)...)
+	}
+
+	for _, sub := range s.allOf {
+		errs = append(errs, sub.validate(value, path)...)
+	}
+	if len(s.anyOf) > 0 {
+		matched := false
+		for _, sub := range s.anyOf {
+			if len(sub.validate(value, path)) == 0 {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			fail("must match at least one of the allowed schemas")
+		}
+	}
+	if len(s.oneOf) > 0 {
+		matches := 0
+		for _, sub := range s.oneOf {
+			if len(sub.validate(value, path)) == 0 {
+				matches++
+			}
+		}
+		if matches != 1 {
+			fail("must match exactly one of the allowed schemas, matched %d", matches)
+		}
+	}
+	if s.not != nil && len(s.not.validate(value, path)) == 0 {
+		fail("must not match the excluded schema")
+	}
+	return errs
+}
+
+func (s *Schema) validateObject(object map[string]interface{}, path string) []ValidationError {
+	var errs []ValidationError
+	for _, name := range s.required {
+		if _, ok := object[name]; !ok {
+			errs = append(errs, ValidationError{Path: path + "/" + escapePointer(name), Message: "is required"})
+		}
+	}
+	if s.minProperties != nil && len(object) < *s.minProperties {
+		errs = append(errs, ValidationError{Path: path, Message: fmt.Sprintf("must have at least %d properties", *s.minProperties)})
+	}
+	if s.maxProperties != nil && len(object) > *s.maxProperties {
+		errs = append(errs, ValidationError{Path: path, Message: fmt.Sprintf("must have at most %d properties", *s.maxProperties)})
+	}
+
+	names := make([]string, 0, len(object))
+	for name := range object {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		here := path + "/" + escapePointer(name)
+		if sub, ok := s.properties[name]; ok {
+			errs = append(errs, sub.validate(object[name], here)...)
+		} else if s.additionalProperties != nil {
+			if s.additionalProperties.alwaysFalse {
+				errs = append(errs, ValidationError{Path: here, Message: "is not an allowed property"})
+			} else {
+				errs = append(errs, s.additionalProperties.validate(object[name], here)...)
+			}
+		}
+	}
+	return errs
+}
+
+func (s *Schema) validateArray(array []interface{}, path string) []ValidationError {
+	var errs []ValidationError
+	if s.minItems != nil && len(array) < *s.minItems {
+		errs = append(errs, ValidationError{Path: path, Message: fmt.Sprintf("must have at least %d items", *s.minItems)})
+	}
+	if s.maxItems != nil && len(array) > *s.maxItems {
+		errs = append(errs, ValidationError{Path: path, Message: fmt.Sprintf("must have at most %d items", *s.maxItems)})
+	}
+	if s.uniqueItems {
+		for i := range array {
+			for j := 0; j < i; j++ {
+				if reflect.DeepEqual(array[i], array[j]) {
+					errs = append(errs, ValidationError{Path: path + "/" + strconv.Itoa(i), Message: fmt.Sprintf("duplicates item %d", j)})
+					break
+				}
+			}
+		}
+	}
+	if s.items != nil {
+		for i, item := range array {
+			errs = append(errs, s.items.validate(item, path+"/"+strconv.Itoa(i))...)
+		}
+	}
+	return errs
+}
+
+func (s *Schema) validateString(value string, path string) []ValidationError {
+	var errs []ValidationError
+	length := utf8.RuneCountInString(value)
+	if s.minLength != nil && length < *s.minLength {
+		errs = append(errs, ValidationError{Path: path, Message: fmt.Sprintf("must be at least %d characters", *s.minLength)})
+	}
+	if s.maxLength != nil && length > *s.maxLength {
+		errs = append(errs, ValidationError{Path: path, Message: fmt.Sprintf("must be at most %d characters", *s.maxLength)})
+	}
+	if s.pattern != nil && !s.pattern.MatchString(value) {
+		errs = append(errs, ValidationError{Path: path, Message: fmt.Sprintf("must match pattern %q", s.pattern.String())})
+	}
+	if s.format != "" && !matchesFormat(s.format, value) {
+		errs = append(errs, ValidationError{Path: path, Message: fmt.Sprintf("must be a valid %s", s.format)})
+	}
+	return errs
+}
+
+func (s *Schema) validateNumber(value float64, path string) []ValidationError {
+	var errs []ValidationError
+	if s.minimum != nil && value < *s.minimum {
+		errs = append(errs, ValidationError{Path: path, Message: fmt.Sprintf("must be >= %v", *s.minimum)})
+	}
+	if s.maximum != nil && value > *s.maximum {
+		errs = append(errs, ValidationError{Path: path, Message: fmt.Sprintf("must be <= %v", *s.maximum)})
+	}
+	if s.exclusiveMinimum != nil && value <= *s.exclusiveMinimum {
+		errs = append(errs, ValidationError{Path: path, Message: fmt.Sprintf("must be > %v", *s.exclusiveMinimum)})
+	}
+	if s.exclusiveMaximum != nil && value >= *s.exclusiveMaximum {
+		errs = append(errs, ValidationError{Path: path, Message: fmt.Sprintf("must be < %v", *s.exclusiveMaximum)})
+	}
+	if s.multipleOf != nil {
+		quotient := value / *s.multipleOf
+		if math.Abs(quotient-math.Round(quotient)) > 1e-9 {
+			errs = append(errs, ValidationError{Path: path, Message: fmt.Sprintf("must be a multiple of %v", *s.multipleOf)})
+		}
+	}
+	return errs
+}
+
+// matchesFormat checks the formats we know; unknown formats are annotations and always pass
+func matchesFormat(format, value string) bool {
+	switch format {
+	case "email":
+		address, err := mail.ParseAddress(value)
+		return err == nil && address.Address == value
+	case "date":
+		_, err := time.Parse("2006-01-02", value)
+		return err == nil
+	case "date-time":
+		_, err := time.Parse(time.RFC3339, value)
+		return err == nil
+	case "uri":
+		parsed, err := url.Parse(value)
+		return err == nil && parsed.Scheme != ""
+	default:
+		return true
+	}
+}
+
+func matchesAnyType(value interface{}, types []string) bool {
+	actual := typeOf(value)
+	for _, expected := range types {
+		if expected == actual || (expected == "number" && actual == "integer") {
+			return true
+		}
+	}
+	return false
+}
+
+// typeOf returns the JSON Schema type of a normalized value; whole numbers are integers
+func typeOf(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case map[string]interface{}:
+		return "object"
+	case []interface{}:
+		return "array"
+	case float64:
+		if v == math.Trunc(v) && !math.IsInf(v, 0) {
+			return "integer"
+		}
+		return "number"
+	case string:
+		return "string"
+	default:
+		return fmt.Sprintf("%T", value)
+	}
+}
+
+// normalize converts Go values built in code (ints, typed maps) to the shapes json.Unmarshal produces
+func normalize(value interface{}) interface{} {
+	switch v := value.(type) {
+	case nil, bool, string, float64:
+		return v
+	case map[string]interface{}:
+		normalized := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			normalized[key] = normalize(item)
+		}
+		return normalized
+	case []interface{}:
+		normalized := make([]interface{}, len(v))
+		for i, item := range v {
+			normalized[i] = normalize(item)
+		}
+		return normalized
+	default:
+		// Round-trip anything else (ints, named map types, structs) through JSON
+		data, err := json.Marshal(v)
+		if err != nil {
+			return v
+		}
+		var decoded interface{}
+		if err := json.Unmarshal(data, &decoded); err != nil {
+			return v
+		}
+		return decoded
+	}
+}
+
+func containsValue(values []interface{}, value interface{}) bool {
+	for _, candidate := range values {
+		if reflect.DeepEqual(normalize(candidate), value) {
+			return true
+		}
+	}
+	return false
+}
+
+func formatValues(values []interface{}) string {
+	parts := make([]string, len(values))
+	for i, value := range values {
+		data, _ := json.Marshal(value)
+		parts[i] = string(data)
+	}
+	return strings.Join(parts, ", ")
+}
+
+func compileTypes(value interface{}) ([]string, error) {
+	var types []string
+	switch v := value.(type) {
+	case string:
+		types = []string{v}
+	case []interface{}:
+		var err error
+		if types, err = compileStrings(v); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("must be a string or an array of strings")
+	}
+	for _, t := range types {
+		if !validTypes[t] {
+			return nil, fmt.Errorf("unknown type %q", t)
+		}
+	}
+	return types, nil
+}
+
+func compileStrings(value interface{}) ([]string, error) {
+	items, ok := value.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("must be an array of strings")
+	}
+	strs := make([]string, len(items))
+	for i, item := range items {
+		str, ok := item.(string)
+		if !ok {
+			return nil, fmt.Errorf("must be an array of strings")
+		}
+		strs[i] = str
+	}
+	return strs, nil
+}
+
+func compileList(value interface{}, at string) ([]*Schema, error) {
+	items, ok := value.([]interface{})
+	if !ok || len(items) == 0 {
+		return nil, fmt.Errorf("must be a non-empty array of schemas")
+	}
+	schemas := make([]*Schema, len(items))
+	for i, item := range items {
+		var err error
+		if schemas[i], err = compile(item, at+"/"+strconv.Itoa(i)); err != nil {
+			return nil, err
+		}
+	}
+	return schemas, nil
+}
+
+func compileCount(value interface{}) (*int, error) {
+	number, ok := value.(float64)
+	if !ok || number < 0 || number != math.Trunc(number) {
+		return nil, fmt.Errorf("must be a non-negative integer")
+	}
+	count := int(number)
+	return &count, nil
+}
+
+func compileNumber(value interface{}) (*float64, error) {
+	number, ok := value.(float64)
+	if !ok {
+		return nil, fmt.Errorf("must be a number")
+	}
+	return &number, nil
+}
+
+// escapePointer escapes a property name for use in a JSON Pointer (RFC 6901)
+func escapePointer(name string) string {
+	return strings.NewReplacer("~", "~0", "/", "~1").Replace(name)
+}
+
+func pointerOrRoot(path string) string {
+	if path == "" {
+		return "/"
+	}
+	return path
+}
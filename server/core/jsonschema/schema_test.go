@@ -0,0 +1,129 @@
+package jsonschema_test
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+
+	"oms/server/core/jsonschema"
+)
+
+func mustParse(t *testing.T, schema string) *jsonschema.Schema {
+	t.Helper()
+	s, err := jsonschema.Parse([]byte(schema))
+	if err != nil {
+		t.Fatalf("Parse(%s): %v", schema, err)
+	}
+	return s
+}
+
+func decode(t *testing.T, doc string) interface{} {
+	t.Helper()
+	var value interface{}
+	if err := json.Unmarshal([]byte(doc), &value); err != nil {
+		t.Fatalf("invalid test document %s: %v", doc, err)
+	}
+	return value
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		schema   string
+		wantPath string // Empty when the schema is valid
+	}{
+		{"annotations are ignored", `{"title": "x", "description": "y", "$schema": "z"}`, ""},
+		{"boolean schema", `false`, ""},
+		{"not an object", `[]`, "/"},
+		{"unsupported keyword", `{"$ref": "#/defs/x"}`, "/$ref"},
+		{"unknown type", `{"type": "float"}`, "/type"},
+		{"type list with non-string", `{"type": ["string", 1]}`, "/type"},
+		{"empty enum", `{"enum": []}`, "/enum"},
+		{"negative count", `{"minLength": -1}`, "/minLength"},
+		{"fractional count", `{"maxItems": 1.5}`, "/maxItems"},
+		{"zero multipleOf", `{"multipleOf": 0}`, "/multipleOf"},
+		{"invalid pattern", `{"pattern": "("}`, "/pattern"},
+		{"empty anyOf", `{"anyOf": []}`, "/anyOf"},
+		{"nested property", `{"properties": {"a": {"properties": {"b/c": {"type": 1}}}}}`, "/properties/a/properties/b~1c/type"},
+		{"nested in array items", `{"items": {"items": {"minItems": "2"}}}`, "/items/items/minItems"},
+		{"nested in allOf", `{"allOf": [{"type": "string"}, {"bogus": true}]}`, "/allOf/1/bogus"},
+		{"invalid JSON", `{`, "json"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := jsonschema.Parse([]byte(tt.schema))
+			switch {
+			case tt.wantPath == "":
+				if err != nil {
+					t.Fatalf("Parse: %v", err)
+				}
+			case tt.wantPath == "json":
+				if err == nil {
+					t.Fatal("Parse succeeded, want an error")
+				}
+			default:
+				var schemaErr *jsonschema.SchemaError
+				if !errors.As(err, &schemaErr) {
+					t.Fatalf("Parse error = %v, want a SchemaError at %s", err, tt.wantPath)
+				}
+				if schemaErr.Path != tt.wantPath {
+					t.Errorf("SchemaError path = %q, want %q (%v)", schemaErr.Path, tt.wantPath, err)
+				}
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		schema string
+		doc    string
+		want   []string // Error paths, in order
+	}{
+		{"valid object", `{"type": "object", "properties": {"n": {"type": "integer"}}, "required": ["n"]}`, `{"n": 3}`, nil},
+		{"wrong root type", `{"type": "object"}`, `[]`, []string{""}},
+		{"missing required", `{"required": ["a", "b"]}`, `{"a": 1}`, []string{"/b"}},
+		{"integer is a number", `{"type": "number"}`, `2`, nil},
+		{"fraction is not an integer", `{"type": "integer"}`, `2.5`, []string{""}},
+		{"additional properties", `{"properties": {"a": {}}, "additionalProperties": false}`, `{"a": 1, "b": 2, "c": 3}`, []string{"/b", "/c"}},
+		{"nested array items", `{"type": "array", "items": {"type": "array", "items": {"type": "integer"}}}`, `[[1, 2], [3, "x"], ["y"]]`, []string{"/1/1", "/2/0"}},
+		{"objects in nested arrays", `{"items": {"items": {"required": ["id"]}}}`, `[[{"id": 1}], [{}, {"id": 2}]]`, []string{"/1/0/id"}},
+		{"unique items", `{"uniqueItems": true}`, `[1, 2, 1, [3], [3]]`, []string{"/2", "/4"}},
+		{"array length", `{"minItems": 2, "maxItems": 3}`, `[1]`, []string{""}},
+		{"escaped property path", `{"properties": {"a/b": {"type": "string"}}}`, `{"a/b": 1}`, []string{"/a~1b"}},
+		{"string length counts characters", `{"maxLength": 2}`, `"éé"`, nil},
+		{"pattern", `{"pattern": "^[A-Z]{3}$"}`, `"usd"`, []string{""}},
+		{"format date", `{"format": "date"}`, `"2025-02-30"`, []string{""}},
+		{"unknown format passes", `{"format": "hostname"}`, `"not a host"`, nil},
+		{"exclusive bounds", `{"exclusiveMinimum": 0, "exclusiveMaximum": 10}`, `10`, []string{""}},
+		{"multipleOf with decimals", `{"multipleOf": 0.01}`, `19.99`, nil},
+		{"enum", `{"enum": ["S", "M", "L"]}`, `"XL"`, []string{""}},
+		{"oneOf matching two", `{"oneOf": [{"type": "integer"}, {"minimum": 0}]}`, `5`, []string{""}},
+		{"anyOf", `{"anyOf": [{"type": "string"}, {"type": "null"}]}`, `null`, nil},
+		{"not", `{"not": {"type": "string"}}`, `"x"`, []string{""}},
+		{"false schema in property", `{"properties": {"legacy": false}}`, `{"legacy": 1}`, []string{"/legacy"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs := mustParse(t, tt.schema).Validate(decode(t, tt.doc))
+			var got []string
+			for _, err := range errs {
+				got = append(got, err.Path)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Validate(%s) error paths = %q, want %q (%v)", tt.doc, got, tt.want, errs)
+			}
+		})
+	}
+}
+
+func TestValidateGoValues(t *testing.T) {
+	s := mustParse(t, `{"properties": {"count": {"type": "integer", "minimum": 1}, "tags": {"items": {"type": "string"}}}}`)
+	doc := map[string]interface{}{"count": 0, "tags": []string{"a", "b"}}
+	errs := s.Validate(doc)
+	if len(errs) != 1 || errs[0].Path != "/count" {
+		t.Errorf("Validate(%v) = %v, want one error at /count", doc, errs)
+	}
+}
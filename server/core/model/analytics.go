@@ -0,0 +1,148 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"oms/server/core/money"
+)
+
+// AnalyticsInterval is the length of the periods sales are reported in
+type AnalyticsInterval string
+
+const (
+	IntervalDay   AnalyticsInterval = "day"
+	IntervalWeek  AnalyticsInterval = "week" // Starting on Monday
+	IntervalMonth AnalyticsInterval = "month"
+)
+
+// IsValid reports whether i is a known interval
+func (i AnalyticsInterval) IsValid() bool {
+	switch i {
+	case IntervalDay, IntervalWeek, IntervalMonth:
+		return true
+	}
+	return false
+}
+
+// Start returns the start of the period containing t, in t's location
+func (i AnalyticsInterval) Start(t time.Time) time.Time {
+	year, month, day := t.Date()
+	switch i {
+	case IntervalMonth:
+		return time.Date(year, month, 1, 0, 0, 0, 0, t.Location())
+	case IntervalWeek:
+		midnight := time.Date(year, month, day, 0, 0, 0, 0, t.Location())
+		return midnight.AddDate(0, 0, -((int(midnight.Weekday()) + 6) % 7))
+	default:
+		return time.Date(year, month, day, 0, 0, 0, 0, t.Location())
+	}
+}
+
+// Next returns the start of the period after the one starting at start
+func (i AnalyticsInterval) Next(start time.Time) time.Time {
+	switch i {
+	case IntervalMonth:
+		return start.AddDate(0, 1, 0)
+	case IntervalWeek:
+		return start.AddDate(0, 0, 7)
+	default:
+		return start.AddDate(0, 0, 1)
+	}
+}
+
+// AnalyticsRange selects the orders placed from From (inclusive) to To (exclusive). Periods and
+// dates are those of Location.
+type AnalyticsRange struct {
+	From     time.Time
+	To       time.Time
+	Location *time.Location
+}
+
+// Sales are what the orders of a period or product sold, net of cancelled units and orders.
+// Revenue is the orders' totals, including shipping and tax, less the share of cancelled units.
+type Sales struct {
+	Orders  int64
+	Units   int64
+	Revenue []money.Money // One per currency, by currency code
+}
+
+// SalesPoint is what sold in one currency in the period starting at PeriodStart
+type SalesPoint struct {
+	PeriodStart time.Time
+	Currency    string
+	Orders      int64
+	Units       int64
+	Revenue     int64 // Minor units of Currency
+}
+
+// SalesPeriod is what sold in the period starting at Start; periods without orders are reported too
+type SalesPeriod struct {
+	Start time.Time
+	Sales
+}
+
+// ProductSales is what one product sold in one currency
+type ProductSales struct {
+	ProductID uuid.UUID
+	SKU       string
+	Name      string
+	Orders    int64
+	Units     int64
+	Revenue   money.Money
+}
+
+// OrderFunnel counts the orders placed in a range by the furthest steps they reached, whatever
+// happened later: an order that shipped and was then returned counts as paid, shipped and delivered
+type OrderFunnel struct {
+	Placed    int64
+	Paid      int64
+	Picking   int64
+	Shipped   int64 // Shipped in full
+	Delivered int64
+	Statuses  map[OrderStatus]int64 // By current status
+}
+
+// CancellationRate returns the share of placed orders that were cancelled, from 0 to 1
+func (f *OrderFunnel) CancellationRate() float64 {
+	if f.Placed == 0 {
+		return 0
+	}
+	return float64(f.Statuses[OrderStatusCancelled]) / float64(f.Placed)
+}
+
+// FulfillmentTimes are the average times the orders placed in a range took from ORDERED to
+// SHIPPED, and from SHIPPED to DELIVERED, over the orders that reached each step
+type FulfillmentTimes struct {
+	ToShipped       time.Duration
+	ShippedOrders   int64
+	ToDelivered     time.Duration
+	DeliveredOrders int64
+}
+
+// SalesRollup is what one product sold in one currency in one UTC hour, precomputed from orders
+// by the nightly rollup job so reports over long ranges don't scan every order
+type SalesRollup struct {
+	Hour      time.Time `gorm:"type:timestamp;primaryKey;autoIncrement:false"` // UTC
+	ProductID uuid.UUID `gorm:"type:uuid;primaryKey"`
+	Currency  string    `gorm:"type:varchar(3);primaryKey"`
+	Orders    int64     `gorm:"not null"`
+	Units     int64     `gorm:"not null"`
+	Revenue   int64     `gorm:"not null"` // Minor units of Currency
+}
+
+// TableName specifies the table name for SalesRollup
+func (SalesRollup) TableName() string {
+	return "sales_rollups"
+}
+
+// SalesRollupDay marks a UTC day whose hours are rolled up in sales_rollups
+type SalesRollupDay struct {
+	Day        time.Time `gorm:"type:date;primaryKey"`
+	RolledUpAt time.Time `gorm:"not null"`
+}
+
+// TableName specifies the table name for SalesRollupDay
+func (SalesRollupDay) TableName() string {
+	return "sales_rollup_days"
+}
@@ -0,0 +1,50 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AuditAction is the kind of change an audit entry records
+type AuditAction string
+
+const (
+	AuditCreate AuditAction = "create"
+	AuditUpdate AuditAction = "update"
+	AuditDelete AuditAction = "delete"
+)
+
+// AuditEntry records one change an admin made to the catalog, inventory, pricing rules or users, with
+// the entity as it was before and after. Changes lists each field that changed as
+// {"before": ..., "after": ...}.
+type AuditEntry struct {
+	ID         uuid.UUID   `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	ActorType  ActorType   `gorm:"type:varchar(20);not null;index:idx_audit_entries_actor" json:"actor_type"`
+	ActorID    string      `gorm:"type:varchar(100);not null;index:idx_audit_entries_actor" json:"actor_id"`
+	Action     AuditAction `gorm:"type:varchar(20);not null" json:"action"`
+	EntityType string      `gorm:"type:varchar(50);not null;index:idx_audit_entries_entity" json:"entity_type"` // Such as "product" or "inventory"
+	EntityID   string      `gorm:"type:varchar(100);not null;index:idx_audit_entries_entity" json:"entity_id"`
+	Before     JSONB       `gorm:"type:jsonb" json:"before,omitempty"` // Empty for creations
+	After      JSONB       `gorm:"type:jsonb" json:"after,omitempty"`  // Empty for deletions
+	Changes    JSONB       `gorm:"type:jsonb;not null;default:'{}'" json:"changes"`
+	RequestID  string      `gorm:"type:varchar(64);not null;default:''" json:"request_id,omitempty"`
+	ClientIP   string      `gorm:"type:varchar(45);not null;default:''" json:"client_ip,omitempty"`
+	CreatedAt  time.Time   `gorm:"autoCreateTime;index" json:"created_at"`
+}
+
+// TableName specifies the table name for AuditEntry
+func (AuditEntry) TableName() string {
+	return "audit_entries"
+}
+
+// AuditFilter narrows the audit log; empty fields match every entry
+type AuditFilter struct {
+	EntityType string
+	EntityID   string
+	ActorType  ActorType
+	ActorID    string
+	From       *time.Time // Inclusive
+	To         *time.Time // Exclusive
+	Limit      int        // At most this many entries, newest first; 0 for all
+}
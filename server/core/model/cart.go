@@ -0,0 +1,120 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"oms/server/core/money"
+)
+
+// Cart is a saved basket of products. Signed-in customers have one cart, keyed by UserID;
+// anonymous visitors have carts addressed by Token until they sign in and it is merged.
+type Cart struct {
+	ID        uuid.UUID  `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	UserID    *int       `gorm:"uniqueIndex" json:"user_id,omitempty"`  // Nil for anonymous carts
+	Token     *string    `gorm:"type:varchar(64);uniqueIndex" json:"-"` // Nil for user carts
+	ExpiresAt time.Time  `gorm:"not null;index" json:"expires_at"`      // Pushed back on every change
+	Lines     []CartLine `gorm:"foreignKey:CartID" json:"lines"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+}
+
+// TableName specifies the table name for Cart
+func (Cart) TableName() string {
+	return "carts"
+}
+
+// Expired reports whether the cart was abandoned for longer than its expiry allows
+func (c *Cart) Expired(now time.Time) bool {
+	return !c.ExpiresAt.After(now)
+}
+
+// Line returns the line holding a product and variant, or nil
+func (c *Cart) Line(productID uuid.UUID, variantID *uuid.UUID) *CartLine {
+	for i := range c.Lines {
+		if c.Lines[i].ProductID == productID && sameVariant(c.Lines[i].VariantID, variantID) {
+			return &c.Lines[i]
+		}
+	}
+	return nil
+}
+
+// CartLine is a product, or one of its variants, in a cart. Prices are not stored:
+// a cart is always priced from the current catalog.
+type CartLine struct {
+	ID        uuid.UUID  `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	CartID    uuid.UUID  `gorm:"type:uuid;not null;index" json:"cart_id"`
+	ProductID uuid.UUID  `gorm:"type:uuid;not null" json:"product_id"`
+	VariantID *uuid.UUID `gorm:"type:uuid" json:"variant_id,omitempty"`
+	Quantity  int        `gorm:"not null" json:"quantity"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+}
+
+// TableName specifies the table name for CartLine
+func (CartLine) TableName() string {
+	return "cart_lines"
+}
+
+// StockUnitID returns the inventory key the line draws stock from: the variant when set, otherwise the product
+func (l *CartLine) StockUnitID() uuid.UUID {
+	if l.VariantID != nil {
+		return *l.VariantID
+	}
+	return l.ProductID
+}
+
+// CartLineIssue explains why a cart line cannot be checked out as it is
+type CartLineIssue string
+
+const (
+	CartLineIssueUnavailable       CartLineIssue = "unavailable"        // Product or variant no longer exists
+	CartLineIssueVariantRequired   CartLineIssue = "variant_required"   // Product gained variants since the line was added
+	CartLineIssueInsufficientStock CartLineIssue = "insufficient_stock" // Available is below Quantity
+	CartLineIssueCurrencyMismatch  CartLineIssue = "currency_mismatch"  // Priced in another currency than the rest of the cart
+)
+
+// PricedCartLine is a cart line with its current catalog price and stock
+type PricedCartLine struct {
+	CartLine
+	SKU         string
+	ProductName string
+	UnitPrice   money.Money
+	LineTotal   money.Money
+	Available   int
+	Issue       CartLineIssue // Empty when the line can be checked out
+}
+
+// PricedCart is a cart validated against the current catalog and inventory
+type PricedCart struct {
+	Cart     *Cart // Nil when the customer has no cart yet
+	Lines    []PricedCartLine
+	Subtotal money.Money // Sum of the line totals in the cart currency
+}
+
+// Valid reports whether the cart has lines and all of them can be checked out
+func (c *PricedCart) Valid() bool {
+	if len(c.Lines) == 0 {
+		return false
+	}
+	for _, line := range c.Lines {
+		if line.Issue != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// OrderItem is one product, or variant, and quantity to place an order for
+type OrderItem struct {
+	ProductID uuid.UUID
+	VariantID *uuid.UUID
+	Quantity  int
+}
+
+func sameVariant(a, b *uuid.UUID) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
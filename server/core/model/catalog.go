@@ -0,0 +1,61 @@
+package model
+
+import (
+	"github.com/google/uuid"
+	"oms/server/core/money"
+)
+
+// StockStatus filters catalog products by availability
+type StockStatus string
+
+const (
+	StockStatusInStock    StockStatus = "in_stock"
+	StockStatusOutOfStock StockStatus = "out_of_stock"
+)
+
+// ProductSortField is a column the catalog can be sorted by
+type ProductSortField string
+
+const (
+	ProductSortCreatedAt ProductSortField = "created_at"
+	ProductSortName      ProductSortField = "name"
+	ProductSortPrice     ProductSortField = "price" // By amount in minor units; combine with a price bound to compare a single currency
+	ProductSortStock     ProductSortField = "stock"
+)
+
+// ProductQuery describes a catalog search
+type ProductQuery struct {
+	Search      string            // Case-insensitive match on name or SKU
+	MinPrice    *money.Money      // Inclusive; also restricts results to its currency
+	MaxPrice    *money.Money      // Inclusive; also restricts results to its currency
+	StockStatus StockStatus       // Empty means any
+	Metadata    map[string]string // Metadata key -> value; an empty value only requires the key to exist
+	Category    string            // Category slug or ID; matches products in the category or any descendant
+	CategoryIDs []uuid.UUID       // Resolved from Category by the service
+	SortBy      ProductSortField  // Defaults to created_at
+	SortDesc    bool
+	Cursor      string // Opaque cursor returned as NextCursor by the previous page
+	Limit       int
+}
+
+// ProductCursor is the decoded keyset position of the last product on a page
+type ProductCursor struct {
+	SortBy ProductSortField `json:"s"`
+	Desc   bool             `json:"d,omitempty"`
+	Value  string           `json:"v"` // Sort column value of the last row
+	ID     uuid.UUID        `json:"id"`
+}
+
+// ProductWithStock is a product joined with its inventory quantity.
+// For products with variants, Quantity is the sum over all variants.
+type ProductWithStock struct {
+	Product  `gorm:"embedded"`
+	Quantity int                        `gorm:"column:quantity" json:"quantity"`
+	Variants []*ProductVariantWithStock `gorm:"-" json:"variants,omitempty"`
+}
+
+// ProductPage is one page of catalog search results
+type ProductPage struct {
+	Products   []*ProductWithStock
+	NextCursor string // Empty on the last page
+}
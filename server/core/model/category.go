@@ -0,0 +1,41 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Category is a node in the catalog taxonomy. Root categories have no parent;
+// siblings are ordered by SortOrder.
+type Category struct {
+	ID        uuid.UUID  `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	ParentID  *uuid.UUID `gorm:"type:uuid;index" json:"parent_id,omitempty"`
+	Name      string     `gorm:"type:varchar(255);not null" json:"name"`
+	Slug      string     `gorm:"type:varchar(255);unique;not null" json:"slug"`
+	SortOrder int        `gorm:"not null;default:0" json:"sort_order"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+}
+
+// TableName specifies the table name for Category
+func (Category) TableName() string {
+	return "categories"
+}
+
+// ProductCategory associates a product with a category (many-to-many)
+type ProductCategory struct {
+	ProductID  uuid.UUID `gorm:"type:uuid;primary_key" json:"product_id"`
+	CategoryID uuid.UUID `gorm:"type:uuid;primary_key;index" json:"category_id"`
+}
+
+// TableName specifies the table name for ProductCategory
+func (ProductCategory) TableName() string {
+	return "product_categories"
+}
+
+// CategoryNode is a category with its children, used to render the tree
+type CategoryNode struct {
+	Category
+	Children []*CategoryNode `json:"children"`
+}
@@ -0,0 +1,105 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// FileFormat is the format of an export or import file
+type FileFormat string
+
+const (
+	FormatCSV    FileFormat = "csv"
+	FormatNDJSON FileFormat = "ndjson" // One JSON object per line
+)
+
+// IsValid reports whether f is a known file format
+func (f FileFormat) IsValid() bool {
+	return f == FormatCSV || f == FormatNDJSON
+}
+
+// ExportStatus is where an export job is in its lifecycle
+type ExportStatus string
+
+const (
+	ExportQueued    ExportStatus = "queued"
+	ExportRunning   ExportStatus = "running"
+	ExportCompleted ExportStatus = "completed" // The file can be downloaded until the job expires
+	ExportFailed    ExportStatus = "failed"
+)
+
+// ExportKindOrders is the kind of the jobs exporting orders
+const ExportKindOrders = "orders"
+
+// OrderExport describes an export of the orders matching Filter. Columns selects and orders the
+// columns, all of them when empty; product columns need IncludeProduct. IncludeHistory adds each
+// order's state log.
+type OrderExport struct {
+	Filter         OrderFilter `json:"filter"`
+	Format         FileFormat  `json:"format"`
+	Columns        []string    `json:"columns,omitempty"`
+	IncludeProduct bool        `json:"include_product,omitempty"`
+	IncludeHistory bool        `json:"include_history,omitempty"`
+}
+
+// ExportJob is an export written to a file in the background, for ranges too large to download
+// while the request waits. Params holds the export's description, such as an OrderExport.
+type ExportJob struct {
+	ID          uuid.UUID    `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	Kind        string       `gorm:"type:varchar(50);not null" json:"kind"`
+	Status      ExportStatus `gorm:"type:varchar(20);not null;index" json:"status"`
+	Format      FileFormat   `gorm:"type:varchar(10);not null" json:"format"`
+	Params      JSONB        `gorm:"type:jsonb;not null" json:"params"`
+	RequestedBy int          `gorm:"not null" json:"requested_by"`
+	Instance    string       `gorm:"type:varchar(255);not null;default:''" json:"instance,omitempty"` // The API instance running or that ran the job, as hostname:pid
+	RowCount    int64        `gorm:"not null;default:0" json:"row_count"`
+	Size        int64        `gorm:"not null;default:0" json:"size"` // Of the file, in bytes
+	Error       string       `gorm:"type:text;not null;default:''" json:"error,omitempty"`
+	CreatedAt   time.Time    `gorm:"autoCreateTime" json:"created_at"`
+	StartedAt   *time.Time   `json:"started_at,omitempty"`
+	CompletedAt *time.Time   `json:"completed_at,omitempty"`
+	ExpiresAt   *time.Time   `gorm:"index" json:"expires_at,omitempty"` // When the file is deleted, once completed or failed
+}
+
+// TableName specifies the table name for ExportJob
+func (ExportJob) TableName() string {
+	return "export_jobs"
+}
+
+// NewOrderExportJob creates a queued job for the order export
+func NewOrderExportJob(export OrderExport, requestedBy int) (*ExportJob, error) {
+	data, err := json.Marshal(export)
+	if err != nil {
+		return nil, err
+	}
+	var params JSONB
+	if err := json.Unmarshal(data, &params); err != nil {
+		return nil, err
+	}
+	return &ExportJob{
+		ID:          uuid.New(),
+		Kind:        ExportKindOrders,
+		Status:      ExportQueued,
+		Format:      export.Format,
+		Params:      params,
+		RequestedBy: requestedBy,
+	}, nil
+}
+
+// OrderExport returns the order export the job runs
+func (j *ExportJob) OrderExport() (OrderExport, error) {
+	var export OrderExport
+	data, err := json.Marshal(j.Params)
+	if err != nil {
+		return export, err
+	}
+	err = json.Unmarshal(data, &export)
+	return export, err
+}
+
+// FileName is the name of the job's file in the exports directory, such as orders-<id>.csv
+func (j *ExportJob) FileName() string {
+	return j.Kind + "-" + j.ID.String() + "." + string(j.Format)
+}
@@ -0,0 +1,106 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PickList is what the warehouse collects for a batch of orders: one line per stock unit, in
+// walking order by bin location, with the orders each unit goes to. It is generated on demand
+// and not stored; orders put on it may be moved to PICKING.
+type PickList struct {
+	ID          uuid.UUID      `json:"id"` // Referenced in the history of orders moved to PICKING
+	GeneratedAt time.Time      `json:"generated_at"`
+	Lines       []PickListLine `json:"lines"`
+	OrderIDs    []uuid.UUID    `json:"order_ids"`
+	TotalUnits  int            `json:"total_units"`
+}
+
+// PickListLine is the quantity of one stock unit to pick and the orders it is split between
+type PickListLine struct {
+	BinLocation string          `json:"bin_location"` // Empty when the stock unit has none
+	ProductID   uuid.UUID       `json:"product_id"`
+	VariantID   *uuid.UUID      `json:"variant_id,omitempty"`
+	SKU         string          `json:"sku"`
+	ProductName string          `json:"product_name"`
+	Quantity    int             `json:"quantity"`
+	Orders      []PickListOrder `json:"orders"`
+}
+
+// PickListOrder is the part of a pick list line that goes to one order
+type PickListOrder struct {
+	OrderID  uuid.UUID `json:"order_id"`
+	Quantity int       `json:"quantity"`
+}
+
+// PackingSlip is the document packed with an order's next shipment: what the customer ordered,
+// what was already sent and what is in this box
+type PackingSlip struct {
+	OrderID        uuid.UUID
+	CheckoutID     *uuid.UUID
+	PlacedAt       time.Time
+	ShipTo         []string // Address lines from the order's shipping address
+	ShippingOption string   // Name of the shipping option the order was charged for
+	SKU            string
+	ProductName    string
+	Ordered        int // Units not cancelled
+	ShippedBefore  int
+	ToPack         int
+	GeneratedAt    time.Time
+}
+
+// NewPackingSlip builds the packing slip for an order's open units
+func NewPackingSlip(order *Order) *PackingSlip {
+	return &PackingSlip{
+		OrderID:        order.ID,
+		CheckoutID:     order.CheckoutID,
+		PlacedAt:       order.CreatedAt,
+		ShipTo:         AddressLines(order.Metadata),
+		ShippingOption: order.ShippingOptionName,
+		SKU:            order.SKU,
+		ProductName:    order.ProductName,
+		Ordered:        order.ActiveQuantity(),
+		ShippedBefore:  order.ShippedQuantity,
+		ToPack:         order.OpenQuantity(),
+		GeneratedAt:    time.Now(),
+	}
+}
+
+// AddressLines formats a shipping address from order metadata as the lines of a label:
+// name, street, "city, state zip" and country. Missing parts are left out.
+func AddressLines(metadata JSONB) []string {
+	field := func(keys ...string) string {
+		for _, key := range keys {
+			if value, ok := metadata[key]; ok && value != nil {
+				if text := strings.TrimSpace(fmt.Sprint(value)); text != "" {
+					return text
+				}
+			}
+		}
+		return ""
+	}
+
+	var lines []string
+	for _, line := range []string{field("name", "recipient"), field("street", "address", "line1"), field("line2")} {
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+	locality := field("city")
+	if region := strings.TrimSpace(field("state", "region") + " " + field("postcode", "postal_code", "zip_code", "zip")); region != "" {
+		if locality != "" {
+			locality += ", "
+		}
+		locality += region
+	}
+	if locality != "" {
+		lines = append(lines, locality)
+	}
+	if country := field("country"); country != "" {
+		lines = append(lines, country)
+	}
+	return lines
+}
@@ -4,14 +4,24 @@ import (
 	"github.com/google/uuid"
 )
 
-// Inventory represents inventory/stock for a product
+// Inventory represents stock for a stock unit: a product without variants,
+// or a single variant. StockUnitID is the variant ID when VariantID is set, otherwise the product ID.
 type Inventory struct {
-	ProductID uuid.UUID `gorm:"type:uuid;primary_key" json:"product_id"`
-	Quantity  int       `gorm:"not null" json:"quantity"`
+	StockUnitID uuid.UUID  `gorm:"type:uuid;primary_key" json:"stock_unit_id"`
+	ProductID   uuid.UUID  `gorm:"type:uuid;not null;index" json:"product_id"`        // The product, or the variant's product
+	VariantID   *uuid.UUID `gorm:"type:uuid;uniqueIndex" json:"variant_id,omitempty"` // Nil for a product without variants
+	Quantity    int        `gorm:"not null" json:"quantity"`
+	BinLocation string     `gorm:"type:varchar(50);not null;default:''" json:"bin_location,omitempty"` // Where the stock unit is shelved, e.g. "A-03-2"
+	// ReorderPoint is the stock at or below which the unit is low and a stock alert opens; nil disables alerts
+	ReorderPoint *int `json:"reorder_point,omitempty"`
+	// TargetLevel is the stock to reorder up to, when set; the suggested reorder quantity is the difference
+	TargetLevel *int `json:"target_level,omitempty"`
+
+	Product *Product        `gorm:"foreignKey:ProductID" json:"-"`
+	Variant *ProductVariant `gorm:"foreignKey:VariantID" json:"-"`
 }
 
 // TableName specifies the table name for Inventory
 func (Inventory) TableName() string {
 	return "inventory"
 }
-
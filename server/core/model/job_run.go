@@ -0,0 +1,35 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// JobRunStatus represents the outcome of a scheduled job run
+type JobRunStatus string
+
+const (
+	JobRunRunning   JobRunStatus = "running"
+	JobRunSucceeded JobRunStatus = "succeeded"
+	JobRunFailed    JobRunStatus = "failed"
+)
+
+// JobRun is one run of a scheduled background job. Each job runs at most once per scheduled
+// time, whichever worker replica claims it first.
+type JobRun struct {
+	ID           uuid.UUID    `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	Job          string       `gorm:"type:varchar(100);not null;uniqueIndex:idx_job_runs_slot" json:"job"`
+	ScheduledFor time.Time    `gorm:"not null;uniqueIndex:idx_job_runs_slot" json:"scheduled_for"`
+	Status       JobRunStatus `gorm:"type:varchar(20);not null;index" json:"status"`
+	Result       string       `gorm:"type:text;not null;default:''" json:"result,omitempty"` // Summary of what the job did
+	Error        string       `gorm:"type:text;not null;default:''" json:"error,omitempty"`
+	Instance     string       `gorm:"type:varchar(255);not null;default:''" json:"instance"` // Host and process that ran it
+	StartedAt    time.Time    `gorm:"not null" json:"started_at"`
+	FinishedAt   *time.Time   `json:"finished_at,omitempty"`
+}
+
+// TableName specifies the table name for JobRun
+func (JobRun) TableName() string {
+	return "job_runs"
+}
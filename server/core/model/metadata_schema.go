@@ -0,0 +1,48 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// SchemaTarget is the kind of metadata a MetadataSchema validates
+type SchemaTarget string
+
+const (
+	SchemaTargetProduct         SchemaTarget = "product"          // Product.Metadata
+	SchemaTargetShippingAddress SchemaTarget = "shipping_address" // Order.Metadata
+)
+
+// MetadataSchema is an admin-registered JSON Schema for product metadata or shipping addresses.
+// A product schema without CategoryID or ProductType applies to every product; scoped schemas
+// apply in addition to it. Every applicable schema must pass.
+type MetadataSchema struct {
+	ID          uuid.UUID    `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	Name        string       `gorm:"type:varchar(255);unique;not null" json:"name"`
+	Target      SchemaTarget `gorm:"type:varchar(50);not null;index" json:"target"`
+	CategoryID  *uuid.UUID   `gorm:"type:uuid" json:"category_id,omitempty"`          // Product schemas: products in this category or its subcategories
+	ProductType string       `gorm:"type:varchar(100)" json:"product_type,omitempty"` // Product schemas: products whose metadata "type" equals this
+	Schema      JSONB        `gorm:"type:jsonb;not null" json:"schema"`
+	CreatedAt   time.Time    `json:"created_at"`
+	UpdatedAt   time.Time    `json:"updated_at"`
+}
+
+// TableName specifies the table name for MetadataSchema
+func (MetadataSchema) TableName() string {
+	return "metadata_schemas"
+}
+
+// SchemaViolation is one field-level validation error and the schema that reported it
+type SchemaViolation struct {
+	Schema  string `json:"schema"`
+	Path    string `json:"path"` // JSON Pointer into the metadata, e.g. "/postcode"
+	Message string `json:"message"`
+}
+
+// MetadataViolationReport lists the violations of one stored row
+type MetadataViolationReport struct {
+	Target     SchemaTarget      `json:"target"`
+	ID         uuid.UUID         `json:"id"` // Product or order ID
+	Violations []SchemaViolation `json:"violations"`
+}
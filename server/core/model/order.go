@@ -1,19 +1,30 @@
 package model
 
 import (
+	"fmt"
+	"math/big"
 	"time"
 
 	"github.com/google/uuid"
+	"oms/server/core/money"
 )
 
 // OrderStatus represents the possible states of an order
 type OrderStatus string
 
 const (
-	OrderStatusOrdered   OrderStatus = "ORDERED"
-	OrderStatusShipped   OrderStatus = "SHIPPED"
-	OrderStatusDelivered OrderStatus = "DELIVERED"
-	OrderStatusCancelled OrderStatus = "CANCELLED"
+	OrderStatusOrdered          OrderStatus = "ORDERED"
+	OrderStatusPaid             OrderStatus = "PAID"              // Payment authorized; captured when the order ships
+	OrderStatusPicking          OrderStatus = "PICKING"           // On a pick list in the warehouse
+	OrderStatusPartiallyShipped OrderStatus = "PARTIALLY_SHIPPED" // Some units shipped, the rest still to ship or cancel
+	OrderStatusShipped          OrderStatus = "SHIPPED"           // Every unit not cancelled has shipped
+	OrderStatusDelivered        OrderStatus = "DELIVERED"
+	OrderStatusCancelled        OrderStatus = "CANCELLED"
+
+	// Returns of delivered orders, see ReturnRequest
+	OrderStatusReturnRequested   OrderStatus = "RETURN_REQUESTED"   // A return is open
+	OrderStatusPartiallyReturned OrderStatus = "PARTIALLY_RETURNED" // Some units came back; more may be returned
+	OrderStatusReturned          OrderStatus = "RETURNED"           // Every unit came back
 )
 
 // Order represents an order in the system
@@ -21,9 +32,41 @@ type Order struct {
 	ID           uuid.UUID  `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
 	UserID       int        `gorm:"not null" json:"user_id"`
 	ProductID    uuid.UUID  `gorm:"type:uuid;not null" json:"product_id"`
+	VariantID    *uuid.UUID `gorm:"type:uuid;index" json:"variant_id,omitempty"` // Set when the product has variants
 	Quantity     int        `gorm:"not null" json:"quantity"`
 	CurrentStatus OrderStatus `gorm:"type:varchar(50);not null;default:'ORDERED'" json:"current_status"`
 	Metadata     JSONB      `gorm:"type:jsonb" json:"metadata"` // For shipping address and other order details
+	CheckoutID   *uuid.UUID `gorm:"type:uuid;index" json:"checkout_id,omitempty"` // Shared by the orders placed from one cart checkout
+
+	// Of Quantity, the units cancelled before they shipped and those sent in shipments; the rest are open
+	CancelledQuantity int `gorm:"not null;default:0" json:"cancelled_quantity"`
+	ShippedQuantity   int `gorm:"not null;default:0" json:"shipped_quantity"`
+
+	// Snapshot of the product at order time, so later catalog edits do not change the order
+	SKU            string      `gorm:"type:varchar(255);not null;default:''" json:"sku"`
+	ProductName    string      `gorm:"type:varchar(255);not null;default:''" json:"product_name"`
+	UnitPrice      money.Money `gorm:"embedded;embeddedPrefix:unit_price_" json:"unit_price"`
+	PriceEstimated bool        `gorm:"not null;default:false" json:"price_estimated"` // Snapshot was backfilled from a later catalog price
+
+	// Totals, all in the unit price currency: Total = Subtotal - Discount + Shipping + Tax.
+	// Tax is charged on top of prices; TaxIncluded is contained in them and not added again.
+	Subtotal    money.Money `gorm:"embedded;embeddedPrefix:subtotal_" json:"subtotal"`
+	Discount    money.Money `gorm:"embedded;embeddedPrefix:discount_" json:"discount"`
+	Shipping    money.Money `gorm:"embedded;embeddedPrefix:shipping_" json:"shipping"`
+	Tax         money.Money `gorm:"embedded;embeddedPrefix:tax_" json:"tax"`
+	TaxIncluded money.Money `gorm:"embedded;embeddedPrefix:tax_included_" json:"tax_included"`
+	Total       money.Money `gorm:"embedded;embeddedPrefix:total_" json:"total"`
+
+	// Shipping option chosen at checkout, quoted by the shipping rate provider; Shipping is its cost,
+	// shared by the orders of one checkout in proportion to their subtotals
+	ShippingOption       string `gorm:"type:varchar(50);not null;default:''" json:"shipping_option,omitempty"`
+	ShippingOptionName   string `gorm:"type:varchar(255);not null;default:''" json:"shipping_option_name,omitempty"`
+	ShippingCarrier      string `gorm:"type:varchar(100);not null;default:''" json:"shipping_carrier,omitempty"`
+	ShippingServiceLevel string `gorm:"type:varchar(50);not null;default:''" json:"shipping_service_level,omitempty"`
+
+	Discounts []OrderDiscount `gorm:"foreignKey:OrderID" json:"discounts,omitempty"` // Promotion lines making up Discount
+	TaxLines  []OrderTaxLine  `gorm:"foreignKey:OrderID" json:"tax_lines,omitempty"` // Make up Tax and TaxIncluded
+
 	CreatedAt    time.Time  `json:"created_at"`
 	UpdatedAt    time.Time  `json:"updated_at"`
 	Product      Product    `gorm:"foreignKey:ProductID" json:"product,omitempty"`
@@ -34,3 +77,124 @@ func (Order) TableName() string {
 	return "orders"
 }
 
+
+// StockUnitID returns the inventory key the order draws stock from: the variant when set, otherwise the product
+func (o *Order) StockUnitID() uuid.UUID {
+	if o.VariantID != nil {
+		return *o.VariantID
+	}
+	return o.ProductID
+}
+
+// ActiveQuantity returns the units that were not cancelled
+func (o *Order) ActiveQuantity() int {
+	return o.Quantity - o.CancelledQuantity
+}
+
+// OpenQuantity returns the units still to ship or cancel
+func (o *Order) OpenQuantity() int {
+	return o.Quantity - o.CancelledQuantity - o.ShippedQuantity
+}
+
+// ShareOf returns the part of the order total paid for units, following the first before units.
+// Shares are rounded down and the last unit takes the remainder, so the shares of all units add up
+// to the total exactly. Cancelled units come first, then returned ones.
+func (o *Order) ShareOf(before, units int) (money.Money, error) {
+	share := func(n int) money.Money {
+		return o.Total.MulRat(big.NewRat(int64(n), int64(o.Quantity)), money.RoundDown)
+	}
+	return share(before + units).Sub(share(before))
+}
+
+// HasPriceSnapshot reports whether the order carries a product snapshot; orders
+// placed before snapshots existed have none until they are backfilled
+func (o *Order) HasPriceSnapshot() bool {
+	return o.SKU != ""
+}
+
+// SetPriceSnapshot records the ordered product's SKU, name and unit price and recomputes the totals
+func (o *Order) SetPriceSnapshot(sku, name string, unitPrice money.Money) error {
+	o.SKU = sku
+	o.ProductName = name
+	o.UnitPrice = unitPrice
+	return o.CalculateTotals()
+}
+
+// CalculateTotals derives Subtotal from UnitPrice and Quantity and Total from the
+// subtotal and adjustments. Zero adjustments take the unit price currency; any
+// adjustment in another currency is rejected.
+func (o *Order) CalculateTotals() error {
+	currency := o.UnitPrice.Currency
+	for _, adjustment := range []*money.Money{&o.Discount, &o.Shipping, &o.Tax, &o.TaxIncluded} {
+		if adjustment.IsZero() {
+			*adjustment = money.Zero(currency)
+		}
+	}
+
+	o.Subtotal = o.UnitPrice.Mul(int64(o.Quantity))
+	total, err := o.Subtotal.Sub(o.Discount)
+	if err == nil {
+		total, err = money.Sum(total, o.Shipping, o.Tax)
+	}
+	if err != nil {
+		return fmt.Errorf("order totals: %w", err)
+	}
+	o.Total = total
+	return nil
+}
+
+// SetTaxLines records the order's tax lines, splits them into Tax and TaxIncluded and recomputes the totals
+func (o *Order) SetTaxLines(lines []OrderTaxLine) error {
+	tax, included := money.Zero(o.UnitPrice.Currency), money.Zero(o.UnitPrice.Currency)
+	for i := range lines {
+		lines[i].OrderID = o.ID
+		var err error
+		if lines[i].Inclusive {
+			included, err = included.Add(lines[i].Amount)
+		} else {
+			tax, err = tax.Add(lines[i].Amount)
+		}
+		if err != nil {
+			return fmt.Errorf("order tax: %w", err)
+		}
+	}
+	o.TaxLines = lines
+	o.Tax = tax
+	o.TaxIncluded = included
+	return o.CalculateTotals()
+}
+
+// OrderFilter narrows a list of orders; empty fields match every order. Orders are matched by when
+// they were placed, from From (inclusive) to To (exclusive).
+type OrderFilter struct {
+	UserID    int           `json:"user_id,omitempty"`
+	Statuses  []OrderStatus `json:"statuses,omitempty"` // Any of these current statuses
+	ProductID *uuid.UUID    `json:"product_id,omitempty"`
+	From      *time.Time    `json:"from,omitempty"`
+	To        *time.Time    `json:"to,omitempty"`
+}
+
+// Matches reports whether the order passes the filter
+func (f OrderFilter) Matches(o *Order) bool {
+	if f.UserID != 0 && o.UserID != f.UserID {
+		return false
+	}
+	if f.ProductID != nil && o.ProductID != *f.ProductID {
+		return false
+	}
+	if f.From != nil && o.CreatedAt.Before(*f.From) {
+		return false
+	}
+	if f.To != nil && !o.CreatedAt.Before(*f.To) {
+		return false
+	}
+	if len(f.Statuses) == 0 {
+		return true
+	}
+	for _, status := range f.Statuses {
+		if o.CurrentStatus == status {
+			return true
+		}
+	}
+	return false
+}
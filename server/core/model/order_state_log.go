@@ -1,24 +1,136 @@
 package model
 
 import (
+	"strconv"
 	"time"
 
 	"github.com/google/uuid"
 )
 
-// OrderStateLog represents an audit trail of order status changes
+// SystemUserID is the UpdatedBy of status changes that no user made, such as when a payment is settled
+const SystemUserID = 0
+
+// ActorType is the kind of actor that changed an order
+type ActorType string
+
+const (
+	ActorUser        ActorType = "user"        // A signed-in customer or admin; the ID is their user ID
+	ActorSystem      ActorType = "system"      // A background job; the ID is the job's name
+	ActorAPIKey      ActorType = "api_key"     // A client authenticated with an API key; the ID is the key's ID
+	ActorIntegration ActorType = "integration" // A payment provider or carrier, through its webhook; the ID names it
+)
+
+// IsValid reports whether t is a known actor type
+func (t ActorType) IsValid() bool {
+	switch t {
+	case ActorUser, ActorSystem, ActorAPIKey, ActorIntegration:
+		return true
+	}
+	return false
+}
+
+// Actor identifies who made a change
+type Actor struct {
+	Type ActorType
+	ID   string
+}
+
+// UserActor is the actor for a signed-in user
+func UserActor(userID int) Actor {
+	return Actor{Type: ActorUser, ID: strconv.Itoa(userID)}
+}
+
+// SystemActor is the actor for a background job
+func SystemActor(job string) Actor {
+	return Actor{Type: ActorSystem, ID: job}
+}
+
+// IntegrationActor is the actor for an external service, such as "carrier:ups"
+func IntegrationActor(name string) Actor {
+	return Actor{Type: ActorIntegration, ID: name}
+}
+
+// UserID returns the actor's user ID, or SystemUserID when the actor is not a user
+func (a Actor) UserID() int {
+	if a.Type != ActorUser {
+		return SystemUserID
+	}
+	userID, err := strconv.Atoi(a.ID)
+	if err != nil {
+		return SystemUserID
+	}
+	return userID
+}
+
+// String formats the actor as type:id, e.g. "user:42" or "system:cancel-stale-orders"
+func (a Actor) String() string {
+	return string(a.Type) + ":" + a.ID
+}
+
+// CancellationReason is the reason code recorded when an order, or some of its units, is cancelled
+type CancellationReason string
+
+const (
+	CancellationCustomerRequest CancellationReason = "customer_request"
+	CancellationPaymentDeclined CancellationReason = "payment_declined"
+	CancellationPaymentTimeout  CancellationReason = "payment_timeout" // Left ORDERED until the stale order job cancelled it
+	CancellationOutOfStock      CancellationReason = "out_of_stock"
+	CancellationAddressIssue    CancellationReason = "address_issue"
+	CancellationDuplicateOrder  CancellationReason = "duplicate_order"
+	CancellationFraudSuspected  CancellationReason = "fraud_suspected"
+	CancellationOther           CancellationReason = "other"
+)
+
+// IsValid reports whether r is a known cancellation reason
+func (r CancellationReason) IsValid() bool {
+	switch r {
+	case CancellationCustomerRequest, CancellationPaymentDeclined, CancellationPaymentTimeout, CancellationOutOfStock,
+		CancellationAddressIssue, CancellationDuplicateOrder, CancellationFraudSuspected, CancellationOther:
+		return true
+	}
+	return false
+}
+
+// StatusChange says who changes an order and why. Reason is a CancellationReason for
+// cancellations and the return's ReturnReason for return steps; Note is free text.
+type StatusChange struct {
+	Actor  Actor
+	Reason string
+	Note   string
+}
+
+// OrderStateLog represents an audit trail of order status changes. Partial shipments and
+// cancellations are recorded too, even when they leave the status as it was; shipping and
+// delivery steps reference their shipment, whose tracking the order history shows.
 type OrderStateLog struct {
-	ID            uuid.UUID   `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
-	OrderID       uuid.UUID   `gorm:"type:uuid;not null" json:"order_id"`
+	ID             uuid.UUID   `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	OrderID        uuid.UUID   `gorm:"type:uuid;not null" json:"order_id"`
 	PreviousStatus OrderStatus `gorm:"type:varchar(50)" json:"previous_status"`
-	NewStatus     OrderStatus `gorm:"type:varchar(50);not null" json:"new_status"`
-	UpdatedBy     int         `gorm:"not null" json:"updated_by"` // User ID who made the change
-	UpdatedAt     time.Time   `gorm:"autoCreateTime" json:"updated_at"`
-	Order         Order       `gorm:"foreignKey:OrderID" json:"order,omitempty"`
+	NewStatus      OrderStatus `gorm:"type:varchar(50);not null" json:"new_status"`
+	UpdatedBy      int         `gorm:"not null" json:"updated_by"` // User ID who made the change, SystemUserID for other actors
+	ActorType      ActorType   `gorm:"type:varchar(20);not null;default:'user'" json:"actor_type"`
+	ActorID        string      `gorm:"type:varchar(100);not null;default:''" json:"actor_id"`
+	ReasonCode     string      `gorm:"type:varchar(50);not null;default:'';index" json:"reason_code,omitempty"` // Why, for cancellations and returns
+	Note           string      `gorm:"type:text;not null;default:''" json:"note,omitempty"`                     // What changed, for steps such as a partial shipment, and any note given
+	ShipmentID     *uuid.UUID  `gorm:"type:uuid" json:"shipment_id,omitempty"`                                  // The shipment that shipped or delivered the order, if any
+	RequestID      string      `gorm:"type:varchar(64);not null;default:''" json:"request_id,omitempty"`        // The API request that made the change
+	ClientIP       string      `gorm:"type:varchar(45);not null;default:''" json:"client_ip,omitempty"`
+	UpdatedAt      time.Time   `gorm:"autoCreateTime" json:"updated_at"`
+	Order          Order       `gorm:"foreignKey:OrderID" json:"order,omitempty"`
+}
+
+// Actor returns who made the change
+func (l *OrderStateLog) Actor() Actor {
+	return Actor{Type: l.ActorType, ID: l.ActorID}
+}
+
+// OrderHistoryFilter narrows an order's history; empty fields match every entry
+type OrderHistoryFilter struct {
+	Reason    string
+	ActorType ActorType
 }
 
 // TableName specifies the table name for OrderStateLog
 func (OrderStateLog) TableName() string {
 	return "order_state_logs"
 }
-
@@ -0,0 +1,117 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"oms/server/core/money"
+)
+
+// PaymentStatus represents the possible states of a payment
+type PaymentStatus string
+
+const (
+	PaymentStatusPending           PaymentStatus = "pending"    // Authorization outcome not known yet; settled by a provider webhook
+	PaymentStatusAuthorized        PaymentStatus = "authorized" // Funds held, not yet taken
+	PaymentStatusDeclined          PaymentStatus = "declined"
+	PaymentStatusCaptured          PaymentStatus = "captured"
+	PaymentStatusVoided            PaymentStatus = "voided" // Authorization released without taking funds
+	PaymentStatusPartiallyRefunded PaymentStatus = "partially_refunded"
+	PaymentStatusRefunded          PaymentStatus = "refunded"
+	PaymentStatusFailed            PaymentStatus = "failed"
+)
+
+// paymentTransitions lists the statuses each payment status may move to
+var paymentTransitions = map[PaymentStatus][]PaymentStatus{
+	PaymentStatusPending:           {PaymentStatusAuthorized, PaymentStatusDeclined, PaymentStatusVoided, PaymentStatusFailed},
+	PaymentStatusAuthorized:        {PaymentStatusCaptured, PaymentStatusVoided, PaymentStatusFailed},
+	PaymentStatusCaptured:          {PaymentStatusPartiallyRefunded, PaymentStatusRefunded},
+	PaymentStatusPartiallyRefunded: {PaymentStatusPartiallyRefunded, PaymentStatusRefunded},
+}
+
+// CanBecome reports whether a payment in this status may move to next.
+// Partial refunds may follow one another; any other repeated status is not a change.
+func (s PaymentStatus) CanBecome(next PaymentStatus) bool {
+	for _, allowed := range paymentTransitions[s] {
+		if allowed == next {
+			return true
+		}
+	}
+	return false
+}
+
+// IsValid reports whether s is a known payment status
+func (s PaymentStatus) IsValid() bool {
+	switch s {
+	case PaymentStatusPending, PaymentStatusAuthorized, PaymentStatusDeclined, PaymentStatusCaptured,
+		PaymentStatusVoided, PaymentStatusPartiallyRefunded, PaymentStatusRefunded, PaymentStatusFailed:
+		return true
+	}
+	return false
+}
+
+// Payment is the charge for an order at a payment provider. It is authorized when the order
+// is placed, captured when it ships, and voided or refunded when it is cancelled.
+type Payment struct {
+	ID            uuid.UUID         `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	OrderID       uuid.UUID         `gorm:"type:uuid;not null;index" json:"order_id"`
+	Provider      string            `gorm:"type:varchar(50);not null;uniqueIndex:idx_payments_provider_reference" json:"provider"`
+	Reference     *string           `gorm:"type:varchar(255);uniqueIndex:idx_payments_provider_reference" json:"reference,omitempty"` // The provider's ID for the payment
+	Method        string            `gorm:"type:varchar(255);not null;default:''" json:"method"`                                      // Provider token for the card or wallet used
+	Status        PaymentStatus     `gorm:"type:varchar(50);not null" json:"status"`
+	Amount        money.Money       `gorm:"embedded" json:"amount"` // Authorized amount, the order total
+	Captured      money.Money       `gorm:"embedded;embeddedPrefix:captured_" json:"captured"`
+	Refunded      money.Money       `gorm:"embedded;embeddedPrefix:refunded_" json:"refunded"`
+	FailureReason string            `gorm:"type:varchar(255);not null;default:''" json:"failure_reason,omitempty"`
+	History       []PaymentStateLog `gorm:"foreignKey:PaymentID" json:"history,omitempty"`
+	CreatedAt     time.Time         `json:"created_at"`
+	UpdatedAt     time.Time         `json:"updated_at"`
+}
+
+// TableName specifies the table name for Payment
+func (Payment) TableName() string {
+	return "payments"
+}
+
+// PaymentStateLog records one status change of a payment. Changes reported by a provider
+// webhook carry its event ID, which is unique so a redelivered event is applied only once.
+type PaymentStateLog struct {
+	ID             uuid.UUID     `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	PaymentID      uuid.UUID     `gorm:"type:uuid;not null;index" json:"payment_id"`
+	PreviousStatus PaymentStatus `gorm:"type:varchar(50)" json:"previous_status"`
+	NewStatus      PaymentStatus `gorm:"type:varchar(50);not null" json:"new_status"`
+	Amount         money.Money   `gorm:"embedded" json:"amount"` // Amount authorized, captured or refunded by this change
+	EventID        *string       `gorm:"type:varchar(255);uniqueIndex" json:"event_id,omitempty"`
+	Message        string        `gorm:"type:varchar(255);not null;default:''" json:"message,omitempty"`
+	CreatedAt      time.Time     `json:"created_at"`
+}
+
+// TableName specifies the table name for PaymentStateLog
+func (PaymentStateLog) TableName() string {
+	return "payment_state_logs"
+}
+
+// PaymentRequest asks a provider to authorize a charge for an order
+type PaymentRequest struct {
+	PaymentID uuid.UUID // Echoed back in webhooks, and the idempotency key for retries
+	OrderID   uuid.UUID
+	Amount    money.Money
+	Method    string
+}
+
+// PaymentResult is a provider's answer to a payment operation
+type PaymentResult struct {
+	Reference string
+	Status    PaymentStatus
+	Message   string // Reason for a decline or failure
+}
+
+// PaymentEvent is a verified webhook notification from a provider about one of its payments
+type PaymentEvent struct {
+	ID        string    // Provider event ID, the same on every redelivery
+	PaymentID uuid.UUID // Our payment ID, when the provider echoes it
+	Reference string
+	Status    PaymentStatus
+	Amount    money.Money
+	Message   string
+}
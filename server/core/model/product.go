@@ -7,6 +7,7 @@ import (
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"oms/server/core/money"
 )
 
 // Product represents a product in the system
@@ -14,8 +15,10 @@ type Product struct {
 	ID        uuid.UUID      `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
 	SKU       string         `gorm:"type:varchar(255);unique;not null" json:"sku"`
 	Name      string         `gorm:"type:varchar(255);not null" json:"name"`
-	Price     float64        `gorm:"type:decimal(10,2);not null" json:"price"`
+	Price     money.Money    `gorm:"embedded;embeddedPrefix:price_" json:"price"` // Columns price_amount (minor units) and price_currency
 	Metadata  JSONB          `gorm:"type:jsonb" json:"metadata"` // For attributes like Color, Size, Weight
+	Options   ProductOptions `gorm:"type:jsonb" json:"options,omitempty"` // Option axes of the variant matrix; empty for products without variants
+	TaxClass  string         `gorm:"type:varchar(50);not null;default:'standard'" json:"tax_class"` // Selects the tax rules that apply, e.g. "standard" or "reduced"
 	CreatedAt time.Time      `json:"created_at"`
 	UpdatedAt time.Time      `json:"updated_at"`
 	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
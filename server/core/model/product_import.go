@@ -0,0 +1,75 @@
+package model
+
+import "fmt"
+
+// ProductImportRow is a product an import creates or updates, as it will be saved. Product has the
+// ID of the product with its SKU, including a deleted one, which the import restores; New is set
+// when there is none and Product gets a new ID. Quantity and BinLocation are nil when the file
+// leaves the product's stock as it is.
+type ProductImportRow struct {
+	Line        int // Of the file, counting the CSV header
+	Product     *Product
+	New         bool
+	Quantity    *int
+	BinLocation *string
+}
+
+// ImportOptions controls how an import is applied
+type ImportOptions struct {
+	Format FileFormat
+	// DryRun validates every row and reports what the import would do without saving anything
+	DryRun bool
+	// Atomic saves every row in one transaction, and nothing when any row is invalid. Otherwise each
+	// batch is saved in its own transaction and invalid rows are skipped.
+	Atomic bool
+	// BatchSize is how many rows are validated, and saved when not atomic, at a time
+	BatchSize int
+	// Progress, when set, is called after each batch
+	Progress func(batch ImportBatch) `json:"-"`
+}
+
+// ImportError is a row an import skipped and why. Line 0 is the file as a whole.
+type ImportError struct {
+	Line    int    `json:"line"`
+	SKU     string `json:"sku,omitempty"`
+	Field   string `json:"field,omitempty"` // The column, or metadata key as "meta.<key>", at fault
+	Message string `json:"message"`
+}
+
+// Error implements error
+func (e *ImportError) Error() string {
+	if e.Field != "" {
+		return fmt.Sprintf("line %d: %s: %s", e.Line, e.Field, e.Message)
+	}
+	return fmt.Sprintf("line %d: %s", e.Line, e.Message)
+}
+
+// ImportBatch summarizes one batch of an import
+type ImportBatch struct {
+	Number    int  `json:"number"` // From 1
+	FirstLine int  `json:"first_line"`
+	LastLine  int  `json:"last_line"`
+	Created   int  `json:"created"`
+	Updated   int  `json:"updated"`
+	Unchanged int  `json:"unchanged"`
+	Failed    int  `json:"failed"`
+	Saved     bool `json:"saved"` // Whether the batch's valid rows are saved: not for dry runs, atomic imports until the end, or batches that failed to save
+}
+
+// ImportReport is the outcome of an import. Created and Updated count the rows that were saved, or
+// would have been for dry runs and atomic imports that saved nothing.
+type ImportReport struct {
+	DryRun  bool `json:"dry_run"`
+	Atomic  bool `json:"atomic"`
+	Saved   bool `json:"saved"` // Whether any row was saved
+	Rows    int  `json:"rows"`
+	Created int  `json:"created"`
+	Updated int  `json:"updated"`
+	// Unchanged counts the rows that match what is saved already, which are skipped
+	Unchanged int `json:"unchanged"`
+	Failed    int `json:"failed"`
+	// Errors lists the first failed rows' errors; ErrorsOmitted counts the rest
+	Errors        []ImportError `json:"errors"`
+	ErrorsOmitted int           `json:"errors_omitted,omitempty"`
+	Batches       []ImportBatch `json:"batches"`
+}
@@ -0,0 +1,129 @@
+package model
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"oms/server/core/money"
+)
+
+// PromotionType is the kind of benefit a promotion grants
+type PromotionType string
+
+const (
+	PromotionTypePercentage   PromotionType = "percentage"    // PercentOff of eligible items
+	PromotionTypeFixedAmount  PromotionType = "fixed_amount"  // AmountOff eligible items
+	PromotionTypeFreeShipping PromotionType = "free_shipping" // Waives the shipping cost
+	PromotionTypeBuyXGetY     PromotionType = "buy_x_get_y"   // Of every BuyQuantity+GetQuantity eligible units, GetQuantity are free
+)
+
+// Promotion is a discount rule. Promotions with a Code are coupons that customers enter;
+// promotions without one are applied automatically whenever their conditions hold.
+// "Spend over N" promotions are percentage or fixed amount promotions with a MinSubtotal.
+type Promotion struct {
+	ID       uuid.UUID     `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	Name     string        `gorm:"type:varchar(255);not null" json:"name"`
+	Code     *string       `gorm:"type:varchar(64);uniqueIndex" json:"code,omitempty"` // Upper case; nil for automatic promotions
+	Type     PromotionType `gorm:"type:varchar(50);not null" json:"type"`
+	Active   bool          `gorm:"not null;default:true" json:"active"`
+	Priority int           `gorm:"not null;default:0" json:"priority"` // Applicable promotions are evaluated in ascending priority
+
+	PercentOff   string      `gorm:"type:varchar(16)" json:"percent_off,omitempty"` // Decimal percentage, e.g. "12.5"
+	AmountOff    money.Money `gorm:"embedded;embeddedPrefix:amount_off_" json:"amount_off"`
+	BuyQuantity  int         `gorm:"not null;default:0" json:"buy_quantity,omitempty"`
+	GetQuantity  int         `gorm:"not null;default:0" json:"get_quantity,omitempty"`
+	MinSubtotal  money.Money `gorm:"embedded;embeddedPrefix:min_subtotal_" json:"min_subtotal"` // Eligible subtotal required; zero for none
+	ProductIDs   UUIDList    `gorm:"type:jsonb" json:"product_ids,omitempty"`                   // Eligible products; empty with no CategoryIDs means every product
+	CategoryIDs  UUIDList    `gorm:"type:jsonb" json:"category_ids,omitempty"`                  // Eligible categories, including their subcategories
+	UsageLimit   int         `gorm:"not null;default:0" json:"usage_limit"`                     // Total redemptions allowed; 0 for unlimited
+	PerUserLimit int         `gorm:"not null;default:0" json:"per_user_limit"`                  // Redemptions allowed per user; 0 for unlimited
+	Redemptions  int         `gorm:"column:redemption_count;not null;default:0" json:"redemption_count"`
+	StartsAt     *time.Time  `json:"starts_at,omitempty"`
+	EndsAt       *time.Time  `json:"ends_at,omitempty"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// TableName specifies the table name for Promotion
+func (Promotion) TableName() string {
+	return "promotions"
+}
+
+// IsAutomatic reports whether the promotion applies without a coupon code
+func (p *Promotion) IsAutomatic() bool {
+	return p.Code == nil
+}
+
+// ActiveAt reports whether the promotion is enabled and within its validity window at t
+func (p *Promotion) ActiveAt(t time.Time) bool {
+	if !p.Active {
+		return false
+	}
+	if p.StartsAt != nil && t.Before(*p.StartsAt) {
+		return false
+	}
+	if p.EndsAt != nil && !t.Before(*p.EndsAt) {
+		return false
+	}
+	return true
+}
+
+// PromotionRedemption records one use of a promotion by an order
+type PromotionRedemption struct {
+	ID          uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	PromotionID uuid.UUID `gorm:"type:uuid;not null;index" json:"promotion_id"`
+	OrderID     uuid.UUID `gorm:"type:uuid;not null;index" json:"order_id"`
+	UserID      int       `gorm:"not null;index" json:"user_id"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// TableName specifies the table name for PromotionRedemption
+func (PromotionRedemption) TableName() string {
+	return "promotion_redemptions"
+}
+
+// OrderDiscount is a discount line recorded on an order by the promotion that granted it
+type OrderDiscount struct {
+	ID           uuid.UUID   `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	OrderID      uuid.UUID   `gorm:"type:uuid;not null;index" json:"order_id"`
+	PromotionID  uuid.UUID   `gorm:"type:uuid;not null" json:"promotion_id"`
+	Code         string      `gorm:"type:varchar(64)" json:"code,omitempty"` // Coupon code, empty for automatic promotions
+	Description  string      `gorm:"type:varchar(255);not null" json:"description"`
+	Amount       money.Money `gorm:"embedded" json:"amount"`
+	FreeShipping bool        `gorm:"not null;default:false" json:"free_shipping"` // The amount waives shipping rather than merchandise
+	CreatedAt    time.Time   `json:"created_at"`
+}
+
+// TableName specifies the table name for OrderDiscount
+func (OrderDiscount) TableName() string {
+	return "order_discounts"
+}
+
+// UUIDList is a list of UUIDs stored as a jsonb array
+type UUIDList []uuid.UUID
+
+// Scan implements the sql.Scanner interface for UUIDList
+func (l *UUIDList) Scan(value interface{}) error {
+	return scanJSON(value, l)
+}
+
+// Value implements the driver.Valuer interface for UUIDList
+func (l UUIDList) Value() (driver.Value, error) {
+	if l == nil {
+		return nil, nil
+	}
+	return json.Marshal(l)
+}
+
+// Contains reports whether id is in the list
+func (l UUIDList) Contains(id uuid.UUID) bool {
+	for _, candidate := range l {
+		if candidate == id {
+			return true
+		}
+	}
+	return false
+}
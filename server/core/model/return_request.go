@@ -0,0 +1,90 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"oms/server/core/money"
+)
+
+// ReturnStatus represents the possible states of a return request
+type ReturnStatus string
+
+const (
+	ReturnStatusRequested ReturnStatus = "requested" // Opened by the customer, awaiting review
+	ReturnStatusApproved  ReturnStatus = "approved"  // The customer may send the goods back
+	ReturnStatusRejected  ReturnStatus = "rejected"
+	ReturnStatusReceived  ReturnStatus = "received" // Goods are back and restocked or written off; refund not issued yet
+	ReturnStatusRefunded  ReturnStatus = "refunded"
+)
+
+// returnTransitions lists the statuses each return status may move to.
+// Refunds are claimed by moving to refunded first; one that fails is put back to received to be retried.
+var returnTransitions = map[ReturnStatus][]ReturnStatus{
+	ReturnStatusRequested: {ReturnStatusApproved, ReturnStatusRejected},
+	ReturnStatusApproved:  {ReturnStatusReceived, ReturnStatusRejected},
+	ReturnStatusReceived:  {ReturnStatusRefunded},
+}
+
+// CanBecome reports whether a return in this status may move to next
+func (s ReturnStatus) CanBecome(next ReturnStatus) bool {
+	for _, allowed := range returnTransitions[s] {
+		if allowed == next {
+			return true
+		}
+	}
+	return false
+}
+
+// IsOpen reports whether a return in this status is still waiting on the goods.
+// An order has at most one open return at a time.
+func (s ReturnStatus) IsOpen() bool {
+	return s == ReturnStatusRequested || s == ReturnStatusApproved
+}
+
+// ReturnReason is the customer's reason for returning goods
+type ReturnReason string
+
+const (
+	ReturnReasonDamaged        ReturnReason = "damaged"
+	ReturnReasonDefective      ReturnReason = "defective"
+	ReturnReasonWrongItem      ReturnReason = "wrong_item"
+	ReturnReasonNotAsDescribed ReturnReason = "not_as_described"
+	ReturnReasonNoLongerNeeded ReturnReason = "no_longer_needed"
+	ReturnReasonOther          ReturnReason = "other"
+)
+
+// IsValid reports whether r is a known return reason
+func (r ReturnReason) IsValid() bool {
+	switch r {
+	case ReturnReasonDamaged, ReturnReasonDefective, ReturnReasonWrongItem,
+		ReturnReasonNotAsDescribed, ReturnReasonNoLongerNeeded, ReturnReasonOther:
+		return true
+	}
+	return false
+}
+
+// ReturnRequest is a customer's request to send back some or all of a delivered order.
+// Once received, each unit is either restocked or written off as damaged, and the
+// order's proportional share of its total is refunded.
+type ReturnRequest struct {
+	ID                uuid.UUID    `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	OrderID           uuid.UUID    `gorm:"type:uuid;not null;index" json:"order_id"`
+	UserID            int          `gorm:"not null" json:"user_id"`
+	Quantity          int          `gorm:"not null" json:"quantity"`
+	Reason            ReturnReason `gorm:"type:varchar(50);not null" json:"reason"`
+	Note              string       `gorm:"type:text;not null;default:''" json:"note,omitempty"` // The customer's description
+	Status            ReturnStatus `gorm:"type:varchar(50);not null;default:'requested'" json:"status"`
+	RestockedQuantity int          `gorm:"not null;default:0" json:"restocked_quantity"`
+	DamagedQuantity   int          `gorm:"not null;default:0" json:"damaged_quantity"`                     // Written off instead of restocked
+	RefundAmount      money.Money  `gorm:"embedded;embeddedPrefix:refund_" json:"refund_amount"`           // Set when the goods are received
+	ResolutionNote    string       `gorm:"type:text;not null;default:''" json:"resolution_note,omitempty"` // The admin's note, such as why it was rejected
+	HandledBy         int          `gorm:"not null;default:0" json:"handled_by"`                           // Admin who last changed the status
+	CreatedAt         time.Time    `json:"created_at"`
+	UpdatedAt         time.Time    `json:"updated_at"`
+}
+
+// TableName specifies the table name for ReturnRequest
+func (ReturnRequest) TableName() string {
+	return "return_requests"
+}
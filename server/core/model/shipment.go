@@ -0,0 +1,76 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// TrackingStatus is where a shipment is according to its carrier
+type TrackingStatus string
+
+const (
+	TrackingStatusShipped        TrackingStatus = "shipped" // Handed to the carrier; no tracking event received yet
+	TrackingStatusInTransit      TrackingStatus = "in_transit"
+	TrackingStatusOutForDelivery TrackingStatus = "out_for_delivery"
+	TrackingStatusDelivered      TrackingStatus = "delivered"
+	TrackingStatusException      TrackingStatus = "exception" // Delayed, undeliverable or damaged; see the event description
+)
+
+// IsValid reports whether s is a status a carrier may report
+func (s TrackingStatus) IsValid() bool {
+	switch s {
+	case TrackingStatusInTransit, TrackingStatusOutForDelivery, TrackingStatusDelivered, TrackingStatusException:
+		return true
+	}
+	return false
+}
+
+// Shipment is one parcel of an order. An order may ship in several shipments, each carrying
+// part of its quantity; the order is SHIPPED once every unit not cancelled has shipped, and
+// DELIVERED once its carriers report every shipment delivered.
+type Shipment struct {
+	ID             uuid.UUID       `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	OrderID        uuid.UUID       `gorm:"type:uuid;not null;index" json:"order_id"`
+	Quantity       int             `gorm:"not null" json:"quantity"`
+	Carrier        string          `gorm:"type:varchar(100);not null;index:idx_shipments_tracking" json:"carrier"` // Lower-case carrier code, such as "ups"
+	ServiceLevel   string          `gorm:"type:varchar(50);not null;default:''" json:"service_level,omitempty"`    // Such as "ground" or "express"
+	TrackingNumber string          `gorm:"type:varchar(255);not null;index:idx_shipments_tracking" json:"tracking_number"`
+	LabelReference string          `gorm:"type:varchar(255);not null;default:''" json:"label_reference,omitempty"` // The carrier's ID for the printed label
+	Status         TrackingStatus  `gorm:"type:varchar(50);not null;default:'shipped'" json:"status"`
+	ShippedBy      int             `gorm:"not null" json:"shipped_by"` // User ID who recorded the shipment
+	ShippedAt      time.Time       `gorm:"not null" json:"shipped_at"`
+	DeliveredAt    *time.Time      `json:"delivered_at,omitempty"`
+	TrackedAt      *time.Time      `json:"tracked_at,omitempty"` // When the latest tracking event applied occurred
+	Events         []TrackingEvent `gorm:"foreignKey:ShipmentID" json:"events,omitempty"`
+	CreatedAt      time.Time       `json:"created_at"`
+	UpdatedAt      time.Time       `json:"updated_at"`
+}
+
+// TableName specifies the table name for Shipment
+func (Shipment) TableName() string {
+	return "shipments"
+}
+
+// IsDelivered reports whether the carrier has reported the shipment delivered
+func (s *Shipment) IsDelivered() bool {
+	return s.DeliveredAt != nil
+}
+
+// TrackingEvent is one scan of a shipment reported by its carrier. EventID is the carrier's ID
+// for the event and is unique per shipment, so a redelivered event is recorded only once.
+type TrackingEvent struct {
+	ID          uuid.UUID      `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	ShipmentID  uuid.UUID      `gorm:"type:uuid;not null;uniqueIndex:idx_tracking_events_shipment_event" json:"shipment_id"`
+	EventID     string         `gorm:"type:varchar(255);not null;uniqueIndex:idx_tracking_events_shipment_event" json:"event_id"`
+	Status      TrackingStatus `gorm:"type:varchar(50);not null" json:"status"`
+	Description string         `gorm:"type:varchar(255);not null;default:''" json:"description,omitempty"`
+	Location    string         `gorm:"type:varchar(255);not null;default:''" json:"location,omitempty"`
+	OccurredAt  time.Time      `gorm:"not null" json:"occurred_at"`
+	CreatedAt   time.Time      `json:"created_at"`
+}
+
+// TableName specifies the table name for TrackingEvent
+func (TrackingEvent) TableName() string {
+	return "tracking_events"
+}
@@ -0,0 +1,136 @@
+package model
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"oms/server/core/money"
+)
+
+// ShippingZone is a destination of the table-rate shipping provider: a country, or the postcodes
+// of a country that start with PostcodePrefix. The zone with the longest matching prefix wins.
+type ShippingZone struct {
+	ID             uuid.UUID      `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	Name           string         `gorm:"type:varchar(255);not null" json:"name"`
+	Country        string         `gorm:"type:varchar(2);not null;uniqueIndex:idx_shipping_zones_scope" json:"country"`                     // ISO 3166-1 alpha-2, upper case
+	PostcodePrefix string         `gorm:"type:varchar(16);not null;default:'';uniqueIndex:idx_shipping_zones_scope" json:"postcode_prefix"` // Upper case without spaces; empty for the whole country
+	Rates          []ShippingRate `gorm:"foreignKey:ZoneID" json:"rates"`
+	CreatedAt      time.Time      `json:"created_at"`
+	UpdatedAt      time.Time      `json:"updated_at"`
+}
+
+// TableName specifies the table name for ShippingZone
+func (ShippingZone) TableName() string {
+	return "shipping_zones"
+}
+
+// ShippingRate prices one shipping option of a zone for a weight band. Parcels from MinWeightGrams
+// up to but not including MaxWeightGrams (no limit when zero) pay Price, or nothing once the
+// merchandise reaches FreeOver. An option may have several rates, one per band.
+type ShippingRate struct {
+	ID             uuid.UUID   `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	ZoneID         uuid.UUID   `gorm:"type:uuid;not null;index" json:"zone_id"`
+	Code           string      `gorm:"type:varchar(50);not null" json:"code"` // Option code customers choose, e.g. "standard"
+	Name           string      `gorm:"type:varchar(255);not null" json:"name"`
+	Carrier        string      `gorm:"type:varchar(100);not null;default:''" json:"carrier,omitempty"`
+	ServiceLevel   string      `gorm:"type:varchar(50);not null;default:''" json:"service_level,omitempty"`
+	MinWeightGrams int         `gorm:"not null;default:0" json:"min_weight_grams"`
+	MaxWeightGrams int         `gorm:"not null;default:0" json:"max_weight_grams"`
+	Price          money.Money `gorm:"embedded;embeddedPrefix:price_" json:"price"`
+	FreeOver       money.Money `gorm:"embedded;embeddedPrefix:free_over_" json:"free_over"` // Merchandise value that ships free; zero for none
+	EstimatedDays  int         `gorm:"not null;default:0" json:"estimated_days,omitempty"`
+	CreatedAt      time.Time   `json:"created_at"`
+}
+
+// TableName specifies the table name for ShippingRate
+func (ShippingRate) TableName() string {
+	return "shipping_rates"
+}
+
+// Covers reports whether a parcel of weightGrams falls in the rate's weight band
+func (r *ShippingRate) Covers(weightGrams int) bool {
+	return weightGrams >= r.MinWeightGrams && (r.MaxWeightGrams == 0 || weightGrams < r.MaxWeightGrams)
+}
+
+// ShippingAddress is the destination that decides which shipping zone applies
+type ShippingAddress struct {
+	Country  string
+	Postcode string
+}
+
+// ShippingAddressFromMetadata reads the destination from an order's shipping address metadata:
+// "country", and "postcode", "postal_code", "zip_code" or "zip"
+func ShippingAddressFromMetadata(metadata JSONB) ShippingAddress {
+	field := func(keys ...string) string {
+		for _, key := range keys {
+			if value, ok := metadata[key]; ok && value != nil {
+				return strings.ToUpper(strings.TrimSpace(fmt.Sprint(value)))
+			}
+		}
+		return ""
+	}
+	return ShippingAddress{
+		Country:  field("country"),
+		Postcode: NormalizePostcode(field("postcode", "postal_code", "zip_code", "zip")),
+	}
+}
+
+// NormalizePostcode upper-cases a postcode and drops its spaces, so "sw1a 1aa" matches prefix "SW1A"
+func NormalizePostcode(postcode string) string {
+	return strings.ToUpper(strings.ReplaceAll(strings.TrimSpace(postcode), " ", ""))
+}
+
+// ShippableLine is a quantity of one product to be shipped, with the parcel size of one unit
+type ShippableLine struct {
+	ProductID   uuid.UUID
+	Quantity    int
+	WeightGrams int     // Of one unit; zero when the product does not say
+	LengthCm    float64 // Dimensions of one unit; zero when the product does not say
+	WidthCm     float64
+	HeightCm    float64
+	Value       money.Money // Merchandise value of the line, for free-shipping thresholds
+}
+
+// NewShippableLine reads the parcel size of one unit from the product's metadata: "weight_grams",
+// and "dimensions_cm" with "length", "width" and "height"
+func NewShippableLine(product *Product, quantity int, value money.Money) ShippableLine {
+	line := ShippableLine{ProductID: product.ID, Quantity: quantity, Value: value}
+	line.WeightGrams = int(metadataNumber(product.Metadata["weight_grams"]))
+	if dimensions, ok := product.Metadata["dimensions_cm"].(map[string]interface{}); ok {
+		line.LengthCm = metadataNumber(dimensions["length"])
+		line.WidthCm = metadataNumber(dimensions["width"])
+		line.HeightCm = metadataNumber(dimensions["height"])
+	}
+	return line
+}
+
+// metadataNumber reads a non-negative number from a metadata value, which may also be a numeric string
+func metadataNumber(value interface{}) float64 {
+	var number float64
+	switch v := value.(type) {
+	case float64:
+		number = v
+	case int:
+		number = float64(v)
+	case string:
+		number, _ = strconv.ParseFloat(strings.TrimSpace(v), 64)
+	}
+	if number < 0 {
+		return 0
+	}
+	return number
+}
+
+// ShippingOption is a quoted way to ship an order and its cost
+type ShippingOption struct {
+	RateID        *uuid.UUID  `json:"rate_id,omitempty"` // Nil when the provider is not rate based
+	Code          string      `json:"code"`
+	Name          string      `json:"name"`
+	Carrier       string      `json:"carrier,omitempty"`
+	ServiceLevel  string      `json:"service_level,omitempty"`
+	Amount        money.Money `json:"amount"`
+	EstimatedDays int         `json:"estimated_days,omitempty"`
+}
@@ -0,0 +1,79 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// StockAlertStatus is where a stock alert is in its lifecycle
+type StockAlertStatus string
+
+const (
+	StockAlertOpen         StockAlertStatus = "open"
+	StockAlertAcknowledged StockAlertStatus = "acknowledged" // Someone is on it, e.g. a purchase order is out
+	StockAlertResolved     StockAlertStatus = "resolved"     // Stock went back above the reorder point, or an admin closed it
+)
+
+// IsValid reports whether s is a known stock alert status
+func (s StockAlertStatus) IsValid() bool {
+	switch s {
+	case StockAlertOpen, StockAlertAcknowledged, StockAlertResolved:
+		return true
+	}
+	return false
+}
+
+// StockAlert is opened when a stock unit's quantity falls to its reorder point, and resolved once it
+// is restocked above it. A stock unit has at most one alert that isn't resolved; ProductID is the
+// stock unit, the product or the variant, as in Inventory. Quantity, ReorderPoint and TargetLevel
+// are as they were when the alert opened.
+type StockAlert struct {
+	ID             uuid.UUID        `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	ProductID      uuid.UUID        `gorm:"type:uuid;not null;uniqueIndex:idx_stock_alerts_unresolved,where:status <> 'resolved'" json:"product_id"`
+	Status         StockAlertStatus `gorm:"type:varchar(20);not null;index:idx_stock_alerts_status_created_at,priority:1" json:"status"`
+	Quantity       int              `gorm:"not null" json:"quantity"`
+	ReorderPoint   int              `gorm:"not null" json:"reorder_point"`
+	TargetLevel    *int             `json:"target_level,omitempty"`
+	AcknowledgedBy *int             `json:"acknowledged_by,omitempty"` // User ID of the admin
+	CreatedAt      time.Time        `gorm:"autoCreateTime;index:idx_stock_alerts_status_created_at,priority:2" json:"created_at"`
+	AcknowledgedAt *time.Time       `json:"acknowledged_at,omitempty"`
+	ResolvedAt     *time.Time       `json:"resolved_at,omitempty"`
+}
+
+// TableName specifies the table name for StockAlert
+func (StockAlert) TableName() string {
+	return "stock_alerts"
+}
+
+// StockAlertFilter narrows a stock alert listing; zero fields match everything
+type StockAlertFilter struct {
+	Statuses  []StockAlertStatus
+	ProductID *uuid.UUID // The stock unit
+	Limit     int
+}
+
+// LowStockItem is a stock unit at or below its reorder point, with the product or variant it
+// stocks. VariantID is set for a variant's stock; Name is its product's.
+type LowStockItem struct {
+	StockUnitID  uuid.UUID  `gorm:"column:stock_unit_id" json:"stock_unit_id"`
+	ProductID    uuid.UUID  `gorm:"column:product_id" json:"product_id"`
+	VariantID    *uuid.UUID `gorm:"column:variant_id" json:"variant_id,omitempty"`
+	SKU          string     `gorm:"column:sku" json:"sku"`
+	Name         string     `gorm:"column:name" json:"name"`
+	Quantity     int        `gorm:"column:quantity" json:"quantity"`
+	ReorderPoint int        `gorm:"column:reorder_point" json:"reorder_point"`
+	TargetLevel  *int       `gorm:"column:target_level" json:"target_level,omitempty"`
+	BinLocation  string     `gorm:"column:bin_location" json:"bin_location,omitempty"`
+	// The alert that isn't resolved yet, if any
+	AlertID     *uuid.UUID        `gorm:"column:alert_id" json:"alert_id,omitempty"`
+	AlertStatus *StockAlertStatus `gorm:"column:alert_status" json:"alert_status,omitempty"`
+}
+
+// ReorderQuantity is how many units to order to reach the target level, 0 without one
+func (i *LowStockItem) ReorderQuantity() int {
+	if i.TargetLevel == nil || *i.TargetLevel <= i.Quantity {
+		return 0
+	}
+	return *i.TargetLevel - i.Quantity
+}
@@ -0,0 +1,80 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"oms/server/core/money"
+)
+
+const (
+	DefaultTaxClass  = "standard" // Tax class of products that do not set one
+	ShippingTaxClass = "shipping" // Tax class of the shipping charge
+)
+
+// TaxRule is a tax rate for a destination and tax class. A rule without Region covers the
+// whole country and a rule without TaxClass covers every class; the most specific rule wins.
+type TaxRule struct {
+	ID        uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	Name      string    `gorm:"type:varchar(255);not null" json:"name"`                                             // Shown on the tax line, e.g. "CA sales tax"
+	Country   string    `gorm:"type:varchar(2);not null;uniqueIndex:idx_tax_rules_scope" json:"country"`            // ISO 3166-1 alpha-2, upper case
+	Region    string    `gorm:"type:varchar(64);not null;default:'';uniqueIndex:idx_tax_rules_scope" json:"region"` // State or province code, upper case
+	TaxClass  string    `gorm:"type:varchar(50);not null;default:'';uniqueIndex:idx_tax_rules_scope" json:"tax_class"`
+	Rate      string    `gorm:"type:varchar(16);not null" json:"rate"`   // Percentage, e.g. "8.25"
+	Inclusive bool      `gorm:"not null;default:false" json:"inclusive"` // Prices already contain the tax, as with VAT
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// TableName specifies the table name for TaxRule
+func (TaxRule) TableName() string {
+	return "tax_rules"
+}
+
+// TaxAddress is the destination that decides which tax rules apply
+type TaxAddress struct {
+	Country string
+	Region  string
+}
+
+// TaxAddressFromMetadata reads the destination from an order's shipping address metadata:
+// "country", and "state" or "region"
+func TaxAddressFromMetadata(metadata JSONB) TaxAddress {
+	field := func(keys ...string) string {
+		for _, key := range keys {
+			if value, ok := metadata[key]; ok && value != nil {
+				return strings.ToUpper(strings.TrimSpace(fmt.Sprint(value)))
+			}
+		}
+		return ""
+	}
+	return TaxAddress{Country: field("country"), Region: field("state", "region")}
+}
+
+// TaxableLine is an amount to be taxed, net of discounts
+type TaxableLine struct {
+	ProductID *uuid.UUID // Nil for the shipping charge
+	TaxClass  string
+	Amount    money.Money
+}
+
+// OrderTaxLine is a tax charged on part of an order by the rule that applied to it
+type OrderTaxLine struct {
+	ID        uuid.UUID   `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	OrderID   uuid.UUID   `gorm:"type:uuid;not null;index" json:"order_id"`
+	RuleID    *uuid.UUID  `gorm:"type:uuid" json:"rule_id,omitempty"` // Nil when the calculator is not rule based
+	Name      string      `gorm:"type:varchar(255);not null" json:"name"`
+	TaxClass  string      `gorm:"type:varchar(50);not null" json:"tax_class"`
+	Rate      string      `gorm:"type:varchar(16);not null" json:"rate"` // Percentage, e.g. "8.25"
+	Inclusive bool        `gorm:"not null;default:false" json:"inclusive"`
+	Taxable   money.Money `gorm:"embedded;embeddedPrefix:taxable_" json:"taxable"` // Amount the rate was applied to
+	Amount    money.Money `gorm:"embedded" json:"amount"`
+	CreatedAt time.Time   `json:"created_at"`
+}
+
+// TableName specifies the table name for OrderTaxLine
+func (OrderTaxLine) TableName() string {
+	return "order_tax_lines"
+}
@@ -0,0 +1,118 @@
+package model
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"oms/server/core/money"
+)
+
+// ProductOption is one option axis of a product, e.g. Size with values S, M and L
+type ProductOption struct {
+	Name   string   `json:"name"`
+	Values []string `json:"values"`
+}
+
+// ProductOptions is the ordered list of option axes stored as jsonb
+type ProductOptions []ProductOption
+
+// Scan implements the sql.Scanner interface for ProductOptions
+func (o *ProductOptions) Scan(value interface{}) error {
+	return scanJSON(value, o)
+}
+
+// Value implements the driver.Valuer interface for ProductOptions
+func (o ProductOptions) Value() (driver.Value, error) {
+	if len(o) == 0 {
+		return nil, nil
+	}
+	return json.Marshal(o)
+}
+
+// VariantOptions maps each option name to the value chosen for a variant, e.g. Size=M, Color=Red
+type VariantOptions map[string]string
+
+// Scan implements the sql.Scanner interface for VariantOptions
+func (o *VariantOptions) Scan(value interface{}) error {
+	return scanJSON(value, o)
+}
+
+// Value implements the driver.Valuer interface for VariantOptions
+func (o VariantOptions) Value() (driver.Value, error) {
+	if o == nil {
+		return json.Marshal(map[string]string{})
+	}
+	return json.Marshal(map[string]string(o))
+}
+
+// Key returns a canonical representation of the option combination,
+// used to match generated variants against existing ones
+func (o VariantOptions) Key() string {
+	names := make([]string, 0, len(o))
+	for name := range o {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	parts := make([]string, len(names))
+	for i, name := range names {
+		parts[i] = fmt.Sprintf("%s=%s", name, o[name])
+	}
+	return strings.Join(parts, ";")
+}
+
+// ProductVariant is a sellable combination of a product's options with its own SKU.
+// Variants have their own inventory row, keyed by the variant ID.
+type ProductVariant struct {
+	ID        uuid.UUID      `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	ProductID uuid.UUID      `gorm:"type:uuid;not null;index" json:"product_id"`
+	SKU       string         `gorm:"type:varchar(255);unique;not null" json:"sku"`
+	Options   VariantOptions `gorm:"type:jsonb;not null" json:"options"`
+	Price     *money.Money   `gorm:"type:varchar(32)" json:"price,omitempty"` // Overrides the product price when set; same currency as the product
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+// TableName specifies the table name for ProductVariant
+func (ProductVariant) TableName() string {
+	return "product_variants"
+}
+
+// EffectivePrice returns the variant's price override, or productPrice when it has none
+func (v *ProductVariant) EffectivePrice(productPrice money.Money) money.Money {
+	if v.Price != nil {
+		return *v.Price
+	}
+	return productPrice
+}
+
+// ProductVariantWithStock is a variant joined with its inventory quantity
+type ProductVariantWithStock struct {
+	ProductVariant `gorm:"embedded"`
+	Quantity       int `gorm:"column:quantity" json:"quantity"`
+}
+
+// scanJSON decodes a json/jsonb column into dest
+func scanJSON(value interface{}, dest interface{}) error {
+	var bytes []byte
+	switch v := value.(type) {
+	case nil:
+		return nil
+	case []byte:
+		bytes = v
+	case string:
+		bytes = []byte(v)
+	default:
+		return fmt.Errorf("unsupported type %T for json column", value)
+	}
+	if len(bytes) == 0 {
+		return nil
+	}
+	return json.Unmarshal(bytes, dest)
+}
@@ -0,0 +1,45 @@
+package money
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DefaultCurrency is assumed for amounts given without a currency code,
+// including every price stored before currencies were introduced
+const DefaultCurrency = "USD"
+
+// currencyExponents maps supported ISO 4217 codes to their number of minor unit digits
+var currencyExponents = map[string]int{
+	"AUD": 2, "BRL": 2, "CAD": 2, "CHF": 2, "CNY": 2, "CZK": 2, "DKK": 2, "EUR": 2,
+	"GBP": 2, "HKD": 2, "INR": 2, "MXN": 2, "NOK": 2, "NZD": 2, "PLN": 2, "SEK": 2,
+	"SGD": 2, "USD": 2, "ZAR": 2,
+	"JPY": 0, "KRW": 0, "ISK": 0, "CLP": 0, "VND": 0,
+	"BHD": 3, "JOD": 3, "KWD": 3, "OMR": 3, "TND": 3,
+}
+
+// NormalizeCurrency upper-cases code and checks that it is a supported ISO 4217 currency
+func NormalizeCurrency(code string) (string, error) {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	if _, ok := currencyExponents[code]; !ok {
+		return "", fmt.Errorf("%w: %q", ErrUnknownCurrency, code)
+	}
+	return code, nil
+}
+
+// Exponent returns the number of minor unit digits of a supported currency, e.g. 2 for USD and 0 for JPY
+func Exponent(currency string) int {
+	if exponent, ok := currencyExponents[currency]; ok {
+		return exponent
+	}
+	return 2
+}
+
+// pow10 returns 10^n for small non-negative n
+func pow10(n int) int64 {
+	result := int64(1)
+	for i := 0; i < n; i++ {
+		result *= 10
+	}
+	return result
+}
@@ -0,0 +1,402 @@
+// Package money provides an exact monetary amount type: an integer number of
+// minor units (e.g. cents) together with an ISO 4217 currency code.
+package money
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+var (
+	// ErrCurrencyMismatch is returned when combining amounts in different currencies
+	ErrCurrencyMismatch = errors.New("currency mismatch")
+	// ErrUnknownCurrency is returned for currency codes that are not supported
+	ErrUnknownCurrency = errors.New("unknown currency")
+	// ErrInvalidAmount is returned when an amount cannot be parsed exactly
+	ErrInvalidAmount = errors.New("invalid amount")
+)
+
+// RoundingMode selects how fractional minor units are rounded
+type RoundingMode int
+
+const (
+	RoundHalfUp   RoundingMode = iota // Half away from zero: 0.125 -> 0.13
+	RoundHalfEven                     // Banker's rounding: 0.125 -> 0.12, 0.135 -> 0.14
+	RoundDown                         // Toward zero
+	RoundUp                           // Away from zero
+)
+
+// Money is an amount in minor units of Currency. The zero value has no currency
+// and acts as zero in any currency for arithmetic.
+//
+// Stored as two columns when embedded in a model (`gorm:"embedded;embeddedPrefix:price_"`),
+// or as a single "12.99 USD" text column through its Scanner/Valuer otherwise.
+type Money struct {
+	Amount   int64  `gorm:"column:amount;not null;default:0"`
+	Currency string `gorm:"column:currency;type:varchar(3);not null;default:'USD'"`
+}
+
+// New returns amount minor units of currency
+func New(amount int64, currency string) Money {
+	return Money{Amount: amount, Currency: currency}
+}
+
+// Zero returns zero in currency
+func Zero(currency string) Money {
+	return Money{Currency: currency}
+}
+
+// Parse parses "12.99 USD" (or "USD 12.99"). A bare amount such as "12.99" is in DefaultCurrency.
+// Amounts with more decimals than the currency has minor units are rejected rather than rounded.
+func Parse(value string) (Money, error) {
+	fields := strings.Fields(value)
+	switch len(fields) {
+	case 1:
+		return ParseAmount(fields[0], DefaultCurrency)
+	case 2:
+		if _, err := strconv.ParseFloat(fields[0], 64); err == nil {
+			return ParseAmount(fields[0], fields[1])
+		}
+		return ParseAmount(fields[1], fields[0])
+	default:
+		return Money{}, fmt.Errorf("%w: %q", ErrInvalidAmount, value)
+	}
+}
+
+// ParseAmount parses a decimal amount such as "12.99" or "-3" in currency
+func ParseAmount(amount, currency string) (Money, error) {
+	currency, err := NormalizeCurrency(currency)
+	if err != nil {
+		return Money{}, err
+	}
+	exponent := Exponent(currency)
+
+	digits := strings.TrimSpace(amount)
+	negative := strings.HasPrefix(digits, "-")
+	digits = strings.TrimPrefix(strings.TrimPrefix(digits, "-"), "+")
+	whole, fraction, _ := strings.Cut(digits, ".")
+	if whole == "" && fraction == "" || !isDigits(whole) || !isDigits(fraction) {
+		return Money{}, fmt.Errorf("%w: %q", ErrInvalidAmount, amount)
+	}
+	fraction = strings.TrimRight(fraction, "0")
+	if len(fraction) > exponent {
+		return Money{}, fmt.Errorf("%w: %q has more than %d decimal places for %s", ErrInvalidAmount, amount, exponent, currency)
+	}
+
+	minor, ok := new(big.Int).SetString(whole+fraction+strings.Repeat("0", exponent-len(fraction)), 10)
+	if !ok || !minor.IsInt64() {
+		return Money{}, fmt.Errorf("%w: %q is out of range", ErrInvalidAmount, amount)
+	}
+	m := Money{Amount: minor.Int64(), Currency: currency}
+	if negative {
+		m.Amount = -m.Amount
+	}
+	return m, nil
+}
+
+// FromFloat converts a float amount in major units, rounding half away from zero.
+// Only meant for legacy inputs; use Parse for anything that must be exact.
+func FromFloat(amount float64, currency string) (Money, error) {
+	currency, err := NormalizeCurrency(currency)
+	if err != nil {
+		return Money{}, err
+	}
+	minor := math.Round(amount * float64(pow10(Exponent(currency))))
+	if math.IsNaN(minor) || math.Abs(minor) > math.MaxInt64/2 {
+		return Money{}, fmt.Errorf("%w: %v", ErrInvalidAmount, amount)
+	}
+	return Money{Amount: int64(minor), Currency: currency}, nil
+}
+
+// MustParse is like Parse but panics on error; for constants and seed data
+func MustParse(value string) Money {
+	m, err := Parse(value)
+	if err != nil {
+		panic(err)
+	}
+	return m
+}
+
+// IsZero reports whether the amount is zero
+func (m Money) IsZero() bool {
+	return m.Amount == 0
+}
+
+// IsNegative reports whether the amount is below zero
+func (m Money) IsNegative() bool {
+	return m.Amount < 0
+}
+
+// Negate returns the amount with its sign flipped
+func (m Money) Negate() Money {
+	return Money{Amount: -m.Amount, Currency: m.Currency}
+}
+
+// Add returns m + other, failing with ErrCurrencyMismatch for different currencies
+func (m Money) Add(other Money) (Money, error) {
+	currency, err := commonCurrency(m, other)
+	if err != nil {
+		return Money{}, err
+	}
+	return Money{Amount: m.Amount + other.Amount, Currency: currency}, nil
+}
+
+// Sub returns m - other, failing with ErrCurrencyMismatch for different currencies
+func (m Money) Sub(other Money) (Money, error) {
+	currency, err := commonCurrency(m, other)
+	if err != nil {
+		return Money{}, err
+	}
+	return Money{Amount: m.Amount - other.Amount, Currency: currency}, nil
+}
+
+// Cmp compares m and other: -1 if m < other, 0 if equal, +1 if m > other
+func (m Money) Cmp(other Money) (int, error) {
+	if _, err := commonCurrency(m, other); err != nil {
+		return 0, err
+	}
+	switch {
+	case m.Amount < other.Amount:
+		return -1, nil
+	case m.Amount > other.Amount:
+		return 1, nil
+	}
+	return 0, nil
+}
+
+// Mul returns m multiplied by a whole quantity
+func (m Money) Mul(quantity int64) Money {
+	return Money{Amount: m.Amount * quantity, Currency: m.Currency}
+}
+
+// MulRate returns m multiplied by an exact decimal rate such as "0.0825", rounded with mode
+func (m Money) MulRate(rate string, mode RoundingMode) (Money, error) {
+	r, ok := new(big.Rat).SetString(strings.TrimSpace(rate))
+	if !ok {
+		return Money{}, fmt.Errorf("%w: rate %q", ErrInvalidAmount, rate)
+	}
+	return m.MulRat(r, mode), nil
+}
+
+// MulRat returns m multiplied by r, rounded to whole minor units with mode
+func (m Money) MulRat(r *big.Rat, mode RoundingMode) Money {
+	product := new(big.Rat).Mul(new(big.Rat).SetInt64(m.Amount), r)
+	return Money{Amount: round(product, mode), Currency: m.Currency}
+}
+
+// Allocate splits m into parts proportional to ratios without losing minor units:
+// the remainder is handed out one unit at a time to the earliest parts.
+// Allocate(1, 1, 1) of 10.00 gives 3.34, 3.33, 3.33.
+func (m Money) Allocate(ratios ...int64) ([]Money, error) {
+	var total int64
+	for _, ratio := range ratios {
+		if ratio < 0 {
+			return nil, fmt.Errorf("%w: negative allocation ratio", ErrInvalidAmount)
+		}
+		total += ratio
+	}
+	if total == 0 {
+		return nil, fmt.Errorf("%w: allocation ratios sum to zero", ErrInvalidAmount)
+	}
+
+	parts := make([]Money, len(ratios))
+	remainder := m.Amount
+	for i, ratio := range ratios {
+		share := new(big.Int).Mul(big.NewInt(m.Amount), big.NewInt(ratio))
+		share.Quo(share, big.NewInt(total))
+		parts[i] = Money{Amount: share.Int64(), Currency: m.Currency}
+		remainder -= parts[i].Amount
+	}
+
+	step := int64(1)
+	if remainder < 0 {
+		step = -1
+	}
+	for i := 0; remainder != 0; i = (i + 1) % len(parts) {
+		if ratios[i] == 0 {
+			continue
+		}
+		parts[i].Amount += step
+		remainder -= step
+	}
+	return parts, nil
+}
+
+// Split divides m into n parts that differ by at most one minor unit
+func (m Money) Split(n int) ([]Money, error) {
+	if n <= 0 {
+		return nil, fmt.Errorf("%w: cannot split into %d parts", ErrInvalidAmount, n)
+	}
+	ratios := make([]int64, n)
+	for i := range ratios {
+		ratios[i] = 1
+	}
+	return m.Allocate(ratios...)
+}
+
+// Decimal formats the amount in major units without the currency, e.g. "1299.99"
+func (m Money) Decimal() string {
+	exponent := Exponent(m.currencyOrDefault())
+	sign := ""
+	amount := m.Amount
+	if amount < 0 {
+		sign = "-"
+	}
+	digits := new(big.Int).Abs(big.NewInt(amount)).String()
+	if exponent == 0 {
+		return sign + digits
+	}
+	if len(digits) <= exponent {
+		digits = strings.Repeat("0", exponent-len(digits)+1) + digits
+	}
+	return sign + digits[:len(digits)-exponent] + "." + digits[len(digits)-exponent:]
+}
+
+// String formats the amount with its currency, e.g. "1299.99 USD"
+func (m Money) String() string {
+	return m.Decimal() + " " + m.currencyOrDefault()
+}
+
+// MarshalJSON encodes the amount as a string such as "1299.99 USD"
+func (m Money) MarshalJSON() ([]byte, error) {
+	return json.Marshal(m.String())
+}
+
+// UnmarshalJSON accepts "1299.99 USD", a bare "1299.99" in DefaultCurrency,
+// or a legacy JSON number in DefaultCurrency
+func (m *Money) UnmarshalJSON(data []byte) error {
+	var text string
+	if err := json.Unmarshal(data, &text); err == nil {
+		parsed, err := Parse(text)
+		if err != nil {
+			return err
+		}
+		*m = parsed
+		return nil
+	}
+
+	var number json.Number
+	if err := json.Unmarshal(data, &number); err != nil {
+		return fmt.Errorf("%w: %s", ErrInvalidAmount, data)
+	}
+	parsed, err := ParseAmount(number.String(), DefaultCurrency)
+	if err != nil {
+		return err
+	}
+	*m = parsed
+	return nil
+}
+
+// Value implements the driver.Valuer interface, storing "1299.99 USD"
+func (m Money) Value() (driver.Value, error) {
+	return m.String(), nil
+}
+
+// GormDataType declares the single-column type for GORM. It also stops GORM from
+// collapsing embedded Money fields into one column because Money is a Valuer.
+func (Money) GormDataType() string {
+	return "varchar(32)"
+}
+
+// Scan implements the sql.Scanner interface for text columns written by Value
+func (m *Money) Scan(value interface{}) error {
+	var text string
+	switch v := value.(type) {
+	case nil:
+		*m = Money{}
+		return nil
+	case []byte:
+		text = string(v)
+	case string:
+		text = v
+	default:
+		return fmt.Errorf("%w: cannot scan %T", ErrInvalidAmount, value)
+	}
+	parsed, err := Parse(text)
+	if err != nil {
+		return err
+	}
+	*m = parsed
+	return nil
+}
+
+// Sum adds amounts that share a currency; the sum of nothing is zero in DefaultCurrency
+func Sum(amounts ...Money) (Money, error) {
+	total := Money{}
+	for _, amount := range amounts {
+		var err error
+		if total, err = total.Add(amount); err != nil {
+			return Money{}, err
+		}
+	}
+	if total.Currency == "" {
+		total.Currency = DefaultCurrency
+	}
+	return total, nil
+}
+
+// currencyOrDefault returns the currency, treating the zero value as DefaultCurrency
+func (m Money) currencyOrDefault() string {
+	if m.Currency == "" {
+		return DefaultCurrency
+	}
+	return m.Currency
+}
+
+// commonCurrency returns the currency two amounts share; the zero value matches any currency
+func commonCurrency(a, b Money) (string, error) {
+	switch {
+	case a.Currency == b.Currency:
+		return a.Currency, nil
+	case a.Currency == "" && a.Amount == 0:
+		return b.Currency, nil
+	case b.Currency == "" && b.Amount == 0:
+		return a.Currency, nil
+	}
+	return "", fmt.Errorf("%w: %s and %s", ErrCurrencyMismatch, a.currencyOrDefault(), b.currencyOrDefault())
+}
+
+// round rounds a rational number of minor units to an integer with mode
+func round(r *big.Rat, mode RoundingMode) int64 {
+	quotient, remainder := new(big.Int).QuoRem(r.Num(), r.Denom(), new(big.Int))
+	if remainder.Sign() == 0 {
+		return quotient.Int64()
+	}
+
+	sign := int64(r.Sign())
+	// Compare twice the remainder against the denominator to find which half we are in
+	twice := new(big.Int).Abs(new(big.Int).Mul(remainder, big.NewInt(2)))
+	half := twice.Cmp(r.Denom())
+
+	awayFromZero := false
+	switch mode {
+	case RoundUp:
+		awayFromZero = true
+	case RoundDown:
+		awayFromZero = false
+	case RoundHalfUp:
+		awayFromZero = half >= 0
+	case RoundHalfEven:
+		awayFromZero = half > 0 || (half == 0 && new(big.Int).Abs(quotient).Bit(0) == 1)
+	}
+	if awayFromZero {
+		return quotient.Int64() + sign
+	}
+	return quotient.Int64()
+}
+
+// isDigits reports whether s consists only of ASCII digits (the empty string included)
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,280 @@
+package money_test
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+
+	"oms/server/core/money"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    money.Money
+		wantErr error
+	}{
+		{"12.99 USD", money.New(1299, "USD"), nil},
+		{"USD 12.99", money.New(1299, "USD"), nil},
+		{"12.99", money.New(1299, "USD"), nil},
+		{"12.5 eur", money.New(1250, "EUR"), nil},
+		{"-3 GBP", money.New(-300, "GBP"), nil},
+		{"12.990 USD", money.New(1299, "USD"), nil},
+		{".5 USD", money.New(50, "USD"), nil},
+		{"1500 JPY", money.New(1500, "JPY"), nil},
+		{"1.234 KWD", money.New(1234, "KWD"), nil},
+		{"12.999 USD", money.Money{}, money.ErrInvalidAmount},
+		{"1.5 JPY", money.Money{}, money.ErrInvalidAmount},
+		{"1e3 USD", money.Money{}, money.ErrInvalidAmount},
+		{"USD .", money.Money{}, money.ErrInvalidAmount},
+		{"99999999999999999999 USD", money.Money{}, money.ErrInvalidAmount},
+		{"12.99 XYZ", money.Money{}, money.ErrUnknownCurrency},
+		{"1 2 3", money.Money{}, money.ErrInvalidAmount},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := money.Parse(tt.input)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Parse(%q) error = %v, want %v", tt.input, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Parse(%q) = %#v, want %#v", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAllocate(t *testing.T) {
+	tests := []struct {
+		name    string
+		amount  money.Money
+		ratios  []int64
+		want    []int64
+		wantErr bool
+	}{
+		{"even thirds", money.New(1000, "USD"), []int64{1, 1, 1}, []int64{334, 333, 333}, false},
+		{"remainder to earliest parts", money.New(1001, "USD"), []int64{1, 1, 1, 1}, []int64{251, 250, 250, 250}, false},
+		{"uneven ratios", money.New(100, "USD"), []int64{1, 2}, []int64{34, 66}, false},
+		{"zero ratio gets nothing", money.New(5, "USD"), []int64{0, 1, 1}, []int64{0, 3, 2}, false},
+		{"negative amount", money.New(-1000, "USD"), []int64{1, 1, 1}, []int64{-334, -333, -333}, false},
+		{"fewer units than parts", money.New(2, "JPY"), []int64{1, 1, 1}, []int64{1, 1, 0}, false},
+		{"zero amount", money.Zero("USD"), []int64{3, 1}, []int64{0, 0}, false},
+		{"negative ratio", money.New(100, "USD"), []int64{1, -1}, nil, true},
+		{"ratios sum to zero", money.New(100, "USD"), []int64{0, 0}, nil, true},
+		{"no ratios", money.New(100, "USD"), nil, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parts, err := tt.amount.Allocate(tt.ratios...)
+			if tt.wantErr {
+				if !errors.Is(err, money.ErrInvalidAmount) {
+					t.Fatalf("Allocate(%v) error = %v, want ErrInvalidAmount", tt.ratios, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Allocate(%v): %v", tt.ratios, err)
+			}
+			got := make([]int64, len(parts))
+			for i, part := range parts {
+				got[i] = part.Amount
+				if part.Currency != tt.amount.Currency {
+					t.Errorf("part %d currency = %q, want %q", i, part.Currency, tt.amount.Currency)
+				}
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Allocate(%v) of %s = %v, want %v", tt.ratios, tt.amount, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSplit(t *testing.T) {
+	parts, err := money.New(100, "USD").Split(3)
+	if err != nil {
+		t.Fatalf("Split(3): %v", err)
+	}
+	total, err := money.Sum(parts...)
+	if err != nil || total != money.New(100, "USD") {
+		t.Errorf("Split(3) parts sum to %v (%v), want 1.00 USD", total, err)
+	}
+	if _, err := money.New(100, "USD").Split(0); !errors.Is(err, money.ErrInvalidAmount) {
+		t.Errorf("Split(0) error = %v, want ErrInvalidAmount", err)
+	}
+}
+
+func TestMulRate(t *testing.T) {
+	tests := []struct {
+		name   string
+		amount int64
+		rate   string
+		mode   money.RoundingMode
+		want   int64
+	}{
+		{"exact", 1000, "0.08", money.RoundHalfUp, 80},
+		{"half up", 125, "0.1", money.RoundHalfUp, 13},
+		{"half up negative", -125, "0.1", money.RoundHalfUp, -13},
+		{"half even down to even", 125, "0.1", money.RoundHalfEven, 12},
+		{"half even up to even", 135, "0.1", money.RoundHalfEven, 14},
+		{"half even negative", -125, "0.1", money.RoundHalfEven, -12},
+		{"half even above half", 1001, "0.0825", money.RoundHalfEven, 83},
+		{"down", 1999, "0.5", money.RoundDown, 999},
+		{"down negative", -1999, "0.5", money.RoundDown, -999},
+		{"up", 1001, "0.1", money.RoundUp, 101},
+		{"up negative", -1001, "0.1", money.RoundUp, -101},
+		{"fraction rate", 1000, "1/3", money.RoundHalfUp, 333},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := money.New(tt.amount, "USD").MulRate(tt.rate, tt.mode)
+			if err != nil {
+				t.Fatalf("MulRate(%q): %v", tt.rate, err)
+			}
+			if got.Amount != tt.want || got.Currency != "USD" {
+				t.Errorf("%d * %s = %v, want %d USD minor units", tt.amount, tt.rate, got, tt.want)
+			}
+		})
+	}
+
+	if _, err := money.New(100, "USD").MulRate("eight percent", money.RoundHalfUp); !errors.Is(err, money.ErrInvalidAmount) {
+		t.Errorf("MulRate with an invalid rate error = %v, want ErrInvalidAmount", err)
+	}
+}
+
+func TestCurrencyMismatch(t *testing.T) {
+	usd, eur := money.New(100, "USD"), money.New(100, "EUR")
+	tests := []struct {
+		name string
+		op   func() error
+	}{
+		{"Add", func() error { _, err := usd.Add(eur); return err }},
+		{"Sub", func() error { _, err := usd.Sub(eur); return err }},
+		{"Cmp", func() error { _, err := usd.Cmp(eur); return err }},
+		{"Sum", func() error { _, err := money.Sum(usd, money.New(5, "USD"), eur); return err }},
+		{"nonzero without currency", func() error { _, err := eur.Add(money.Money{Amount: 1}); return err }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.op(); !errors.Is(err, money.ErrCurrencyMismatch) {
+				t.Errorf("%s error = %v, want ErrCurrencyMismatch", tt.name, err)
+			}
+		})
+	}
+}
+
+func TestArithmetic(t *testing.T) {
+	sum, err := money.New(100, "EUR").Add(money.Money{})
+	if err != nil || sum != money.New(100, "EUR") {
+		t.Errorf("adding the zero value = %v (%v), want 1.00 EUR", sum, err)
+	}
+	diff, err := money.New(100, "EUR").Sub(money.New(250, "EUR"))
+	if err != nil || diff != money.New(-150, "EUR") {
+		t.Errorf("Sub = %v (%v), want -1.50 EUR", diff, err)
+	}
+	if cmp, err := money.New(1, "USD").Cmp(money.New(2, "USD")); err != nil || cmp != -1 {
+		t.Errorf("Cmp = %d (%v), want -1", cmp, err)
+	}
+	if total, err := money.Sum(); err != nil || total != money.Zero(money.DefaultCurrency) {
+		t.Errorf("Sum() = %#v (%v), want zero in %s", total, err, money.DefaultCurrency)
+	}
+}
+
+func TestString(t *testing.T) {
+	tests := []struct {
+		amount money.Money
+		want   string
+	}{
+		{money.New(129999, "USD"), "1299.99 USD"},
+		{money.New(5, "USD"), "0.05 USD"},
+		{money.New(-5, "EUR"), "-0.05 EUR"},
+		{money.New(1500, "JPY"), "1500 JPY"},
+		{money.New(1, "KWD"), "0.001 KWD"},
+		{money.Money{}, "0.00 USD"},
+	}
+	for _, tt := range tests {
+		if got := tt.amount.String(); got != tt.want {
+			t.Errorf("String(%#v) = %q, want %q", tt.amount, got, tt.want)
+		}
+	}
+}
+
+func TestJSON(t *testing.T) {
+	roundTrips := []money.Money{
+		money.New(129999, "USD"),
+		money.New(-5, "EUR"),
+		money.New(1500, "JPY"),
+		money.New(1234, "KWD"),
+	}
+	for _, amount := range roundTrips {
+		data, err := json.Marshal(amount)
+		if err != nil {
+			t.Fatalf("Marshal(%v): %v", amount, err)
+		}
+		var got money.Money
+		if err := json.Unmarshal(data, &got); err != nil {
+			t.Fatalf("Unmarshal(%s): %v", data, err)
+		}
+		if got != amount {
+			t.Errorf("round trip of %v through %s = %v", amount, data, got)
+		}
+	}
+
+	tests := []struct {
+		input   string
+		want    money.Money
+		wantErr bool
+	}{
+		{`"12.99 EUR"`, money.New(1299, "EUR"), false},
+		{`"12.99"`, money.New(1299, "USD"), false},
+		{`12.99`, money.New(1299, "USD"), false},
+		{`12.999`, money.Money{}, true},
+		{`"12.99 XYZ"`, money.Money{}, true},
+		{`true`, money.Money{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			var got money.Money
+			err := json.Unmarshal([]byte(tt.input), &got)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Unmarshal(%s) error = %v, want error %v", tt.input, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Unmarshal(%s) = %#v, want %#v", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScanValue(t *testing.T) {
+	value, err := money.New(1299, "EUR").Value()
+	if err != nil || value != "12.99 EUR" {
+		t.Errorf("Value() = %v (%v), want \"12.99 EUR\"", value, err)
+	}
+
+	tests := []struct {
+		name    string
+		src     interface{}
+		want    money.Money
+		wantErr bool
+	}{
+		{"string", "12.99 EUR", money.New(1299, "EUR"), false},
+		{"bytes", []byte("1500 JPY"), money.New(1500, "JPY"), false},
+		{"bare amount", "3.50", money.New(350, "USD"), false},
+		{"null", nil, money.Money{}, false},
+		{"unsupported type", int64(1299), money.Money{}, true},
+		{"invalid text", "twelve", money.Money{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := money.New(1, "GBP")
+			err := got.Scan(tt.src)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Scan(%v) error = %v, want error %v", tt.src, err, tt.wantErr)
+			}
+			if !tt.wantErr && got != tt.want {
+				t.Errorf("Scan(%v) = %#v, want %#v", tt.src, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,180 @@
+// Package notify sends new low-stock alerts to the people and systems that restock: the log, email
+// and a webhook
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/smtp"
+	"strings"
+	"time"
+
+	"oms/server/core/model"
+	"oms/server/core/types"
+)
+
+// StockAlertOpenedEvent is the event of the webhook for a new alert
+const StockAlertOpenedEvent = "stock_alert.opened"
+
+// SignatureHeader carries the webhook body's hex HMAC-SHA256, when it is signed
+const SignatureHeader = "X-Signature"
+
+// webhookTimeout bounds each webhook call
+const webhookTimeout = 10 * time.Second
+
+// describe summarizes a new alert in one line
+func describe(item *model.LowStockItem) string {
+	summary := fmt.Sprintf("%s (%s) is low on stock: %d left, reorder point %d", item.SKU, item.Name, item.Quantity, item.ReorderPoint)
+	if reorder := item.ReorderQuantity(); reorder > 0 {
+		summary += fmt.Sprintf(", order %d to reach %d", reorder, *item.TargetLevel)
+	}
+	return summary
+}
+
+// logNotifier writes new alerts to the log
+type logNotifier struct{}
+
+// NewLogNotifier creates a StockAlertNotifier that writes to the log
+func NewLogNotifier() types.StockAlertNotifier {
+	return logNotifier{}
+}
+
+func (logNotifier) Notify(ctx context.Context, alert *model.StockAlert, item *model.LowStockItem) error {
+	log.Printf("Stock alert %s: %s", alert.ID, describe(item))
+	return nil
+}
+
+// webhookNotifier posts new alerts to a URL
+type webhookNotifier struct {
+	url    string
+	secret []byte
+	client *http.Client
+}
+
+// NewWebhookNotifier creates a StockAlertNotifier that posts each new alert to url as
+// {"event": "stock_alert.opened", "alert": ..., "item": ...}. With a secret the body is signed
+// with the hex HMAC-SHA256 keyed by it in the X-Signature header. Responses other than 2xx fail.
+func NewWebhookNotifier(url, secret string) types.StockAlertNotifier {
+	return &webhookNotifier{
+		url:    url,
+		secret: []byte(secret),
+		client: &http.Client{Timeout: webhookTimeout},
+	}
+}
+
+func (n *webhookNotifier) Notify(ctx context.Context, alert *model.StockAlert, item *model.LowStockItem) error {
+	body, err := json.Marshal(map[string]interface{}{
+		"event": StockAlertOpenedEvent,
+		"alert": alert,
+		"item":  item,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to encode webhook: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if len(n.secret) > 0 {
+		mac := hmac.New(sha256.New, n.secret)
+		mac.Write(body)
+		req.Header.Set(SignatureHeader, hex.EncodeToString(mac.Sum(nil)))
+	}
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("webhook failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned %s", resp.Status)
+	}
+	return nil
+}
+
+// EmailConfig is where and how alert emails are sent
+type EmailConfig struct {
+	From     string
+	To       []string
+	Addr     string // host:port of the SMTP server
+	User     string // Empty sends without authenticating
+	Password string
+}
+
+// emailNotifier mails new alerts
+type emailNotifier struct {
+	cfg EmailConfig
+}
+
+// NewEmailNotifier creates a StockAlertNotifier that sends an email per new alert. The server is
+// authenticated with PLAIN when a user is set, which net/smtp only allows over TLS or to localhost.
+func NewEmailNotifier(cfg EmailConfig) types.StockAlertNotifier {
+	return &emailNotifier{cfg: cfg}
+}
+
+func (n *emailNotifier) Notify(ctx context.Context, alert *model.StockAlert, item *model.LowStockItem) error {
+	var auth smtp.Auth
+	if n.cfg.User != "" {
+		host := n.cfg.Addr
+		if i := strings.LastIndex(host, ":"); i >= 0 {
+			host = host[:i]
+		}
+		auth = smtp.PlainAuth("", n.cfg.User, n.cfg.Password, host)
+	}
+
+	var msg bytes.Buffer
+	fmt.Fprintf(&msg, "From: %s\r\n", n.cfg.From)
+	fmt.Fprintf(&msg, "To: %s\r\n", strings.Join(n.cfg.To, ", "))
+	fmt.Fprintf(&msg, "Subject: Low stock: %s\r\n", item.SKU)
+	fmt.Fprintf(&msg, "Date: %s\r\n", alert.CreatedAt.Format(time.RFC1123Z))
+	msg.WriteString("Content-Type: text/plain; charset=UTF-8\r\n\r\n")
+	fmt.Fprintf(&msg, "%s.\r\n\r\nAlert %s", describe(item), alert.ID)
+	if item.BinLocation != "" {
+		fmt.Fprintf(&msg, ", shelved at %s", item.BinLocation)
+	}
+	msg.WriteString(".\r\n")
+
+	// net/smtp takes no context, so a slow server is only abandoned, not interrupted
+	done := make(chan error, 1)
+	go func() {
+		done <- smtp.SendMail(n.cfg.Addr, auth, n.cfg.From, n.cfg.To, msg.Bytes())
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			return fmt.Errorf("failed to send email: %w", err)
+		}
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("failed to send email: %w", ctx.Err())
+	}
+}
+
+// multiNotifier tells several notifiers
+type multiNotifier []types.StockAlertNotifier
+
+// Multi creates a StockAlertNotifier that tells each of notifiers in turn, failing with all of
+// their errors
+func Multi(notifiers ...types.StockAlertNotifier) types.StockAlertNotifier {
+	return multiNotifier(notifiers)
+}
+
+func (m multiNotifier) Notify(ctx context.Context, alert *model.StockAlert, item *model.LowStockItem) error {
+	var errs []error
+	for _, notifier := range m {
+		if err := notifier.Notify(ctx, alert, item); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,173 @@
+package payment
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"oms/server/core/model"
+	"oms/server/core/money"
+	"oms/server/core/types"
+)
+
+// ErrInvalidSignature is returned for webhooks whose signature does not match the payload
+var ErrInvalidSignature = errors.New("invalid webhook signature")
+
+// Payment methods the fake provider recognizes. Any other method, including none, is approved.
+const (
+	FakeMethodDecline        = "fake_decline"         // Authorization is declined
+	FakeMethodTimeout        = "fake_timeout"         // Authorization times out; settle it with a signed webhook
+	FakeMethodCaptureDecline = "fake_capture_decline" // Authorization succeeds, capture is declined
+	FakeMethodError          = "fake_error"           // Every call fails in transit
+)
+
+// FakeProviderName identifies payments made with the fake provider
+const FakeProviderName = "fake"
+
+// fakeProvider implements types.PaymentProvider without a gateway. It is deterministic: the
+// outcome depends only on the payment method, and references are derived from the payment ID.
+type fakeProvider struct {
+	webhookSecret []byte
+}
+
+// NewFakeProvider creates a PaymentProvider for local development and demos. Webhooks are
+// verified with an HMAC-SHA256 of the body keyed by webhookSecret, see Sign.
+func NewFakeProvider(webhookSecret string) types.PaymentProvider {
+	return &fakeProvider{webhookSecret: []byte(webhookSecret)}
+}
+
+// Name implements types.PaymentProvider
+func (p *fakeProvider) Name() string {
+	return FakeProviderName
+}
+
+// Authorize implements types.PaymentProvider
+func (p *fakeProvider) Authorize(ctx context.Context, req model.PaymentRequest) (model.PaymentResult, error) {
+	reference := "fake_" + strings.ReplaceAll(req.PaymentID.String(), "-", "")
+	switch req.Method {
+	case FakeMethodError:
+		return model.PaymentResult{}, errors.New("fake provider unavailable")
+	case FakeMethodTimeout:
+		return model.PaymentResult{}, fmt.Errorf("fake provider: authorizing %s: %w", reference, context.DeadlineExceeded)
+	case FakeMethodDecline:
+		return model.PaymentResult{Reference: reference, Status: model.PaymentStatusDeclined, Message: "card declined"}, nil
+	}
+	return model.PaymentResult{Reference: reference, Status: model.PaymentStatusAuthorized}, nil
+}
+
+// Capture implements types.PaymentProvider
+func (p *fakeProvider) Capture(ctx context.Context, payment *model.Payment, amount money.Money) (model.PaymentResult, error) {
+	switch payment.Method {
+	case FakeMethodError:
+		return model.PaymentResult{}, errors.New("fake provider unavailable")
+	case FakeMethodCaptureDecline:
+		return model.PaymentResult{Reference: p.reference(payment), Status: model.PaymentStatusFailed, Message: "capture declined"}, nil
+	}
+	if cmp, err := amount.Cmp(payment.Amount); err != nil || cmp > 0 {
+		return model.PaymentResult{}, fmt.Errorf("cannot capture %s of a %s authorization", amount, payment.Amount)
+	}
+	return model.PaymentResult{Reference: p.reference(payment), Status: model.PaymentStatusCaptured}, nil
+}
+
+// Void implements types.PaymentProvider
+func (p *fakeProvider) Void(ctx context.Context, payment *model.Payment) (model.PaymentResult, error) {
+	if payment.Method == FakeMethodError {
+		return model.PaymentResult{}, errors.New("fake provider unavailable")
+	}
+	return model.PaymentResult{Reference: p.reference(payment), Status: model.PaymentStatusVoided}, nil
+}
+
+// Refund implements types.PaymentProvider
+func (p *fakeProvider) Refund(ctx context.Context, payment *model.Payment, amount money.Money) (model.PaymentResult, error) {
+	if payment.Method == FakeMethodError {
+		return model.PaymentResult{}, errors.New("fake provider unavailable")
+	}
+	refunded, err := payment.Refunded.Add(amount)
+	if err != nil {
+		return model.PaymentResult{}, err
+	}
+	cmp, err := refunded.Cmp(payment.Captured)
+	if err != nil {
+		return model.PaymentResult{}, err
+	}
+	if cmp > 0 {
+		return model.PaymentResult{}, fmt.Errorf("cannot refund %s of a %s capture", refunded, payment.Captured)
+	}
+	status := model.PaymentStatusPartiallyRefunded
+	if cmp == 0 {
+		status = model.PaymentStatusRefunded
+	}
+	return model.PaymentResult{Reference: p.reference(payment), Status: status}, nil
+}
+
+// fakeWebhook is the body of a fake provider webhook
+type fakeWebhook struct {
+	ID        string      `json:"id"`
+	PaymentID string      `json:"payment_id"`
+	Reference string      `json:"reference"`
+	Status    string      `json:"status"`
+	Amount    money.Money `json:"amount"`
+	Message   string      `json:"message"`
+}
+
+// ParseWebhook implements types.PaymentProvider. The signature is the hex HMAC-SHA256 of the body.
+func (p *fakeProvider) ParseWebhook(payload []byte, signature string) (*model.PaymentEvent, error) {
+	expected := Sign(string(p.webhookSecret), payload)
+	if !hmac.Equal([]byte(strings.ToLower(strings.TrimSpace(signature))), []byte(expected)) {
+		return nil, ErrInvalidSignature
+	}
+
+	var body fakeWebhook
+	if err := json.Unmarshal(payload, &body); err != nil {
+		return nil, fmt.Errorf("invalid webhook body: %w", err)
+	}
+	if body.ID == "" {
+		return nil, errors.New("webhook event id is required")
+	}
+	status := model.PaymentStatus(body.Status)
+	if !status.IsValid() {
+		return nil, fmt.Errorf("unknown payment status %q", body.Status)
+	}
+	event := &model.PaymentEvent{
+		ID:        body.ID,
+		Reference: body.Reference,
+		Status:    status,
+		Amount:    body.Amount,
+		Message:   body.Message,
+	}
+	if body.PaymentID != "" {
+		paymentID, err := uuid.Parse(body.PaymentID)
+		if err != nil {
+			return nil, fmt.Errorf("invalid payment_id: %w", err)
+		}
+		event.PaymentID = paymentID
+	}
+	if event.PaymentID == uuid.Nil && event.Reference == "" {
+		return nil, errors.New("webhook must identify the payment by payment_id or reference")
+	}
+	return event, nil
+}
+
+// reference returns the payment's reference, which payments left pending by a timeout lack
+func (p *fakeProvider) reference(payment *model.Payment) string {
+	if payment.Reference != nil {
+		return *payment.Reference
+	}
+	return "fake_" + strings.ReplaceAll(payment.ID.String(), "-", "")
+}
+
+// Sign returns the hex HMAC-SHA256 of payload keyed by secret, the signature of a fake provider webhook
+func Sign(secret string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Ensure fakeProvider implements types.PaymentProvider
+var _ types.PaymentProvider = (*fakeProvider)(nil)
@@ -0,0 +1,176 @@
+package scheduler
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schedule decides when a job runs next
+type Schedule interface {
+	// Next returns the first run time after t
+	Next(t time.Time) time.Time
+}
+
+// ParseSchedule parses a cron expression with the five standard fields (minute, hour, day of
+// month, month, day of week; each *, a value, a range a-b, a step */n or a-b/n, or a list of
+// those), one of @hourly, @daily, @midnight and @weekly, or "@every <duration>". Cron times
+// are in UTC, and @every intervals are aligned to the Unix epoch, so every replica computes
+// the same run times.
+func ParseSchedule(spec string) (Schedule, error) {
+	spec = strings.TrimSpace(spec)
+	switch spec {
+	case "@hourly":
+		spec = "0 * * * *"
+	case "@daily", "@midnight":
+		spec = "0 0 * * *"
+	case "@weekly":
+		spec = "0 0 * * 0"
+	}
+	if rest, ok := strings.CutPrefix(spec, "@every "); ok {
+		interval, err := time.ParseDuration(strings.TrimSpace(rest))
+		if err != nil {
+			return nil, fmt.Errorf("invalid schedule %q: %w", spec, err)
+		}
+		if interval < time.Second {
+			return nil, fmt.Errorf("invalid schedule %q: interval must be at least 1s", spec)
+		}
+		return every(interval), nil
+	}
+
+	fields := strings.Fields(spec)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("invalid schedule %q: expected 5 fields, @every <duration> or a descriptor", spec)
+	}
+	var c cron
+	var err error
+	if c.minute, err = parseField(fields[0], 0, 59); err != nil {
+		return nil, fmt.Errorf("invalid schedule %q: minute: %w", spec, err)
+	}
+	if c.hour, err = parseField(fields[1], 0, 23); err != nil {
+		return nil, fmt.Errorf("invalid schedule %q: hour: %w", spec, err)
+	}
+	if c.dom, err = parseField(fields[2], 1, 31); err != nil {
+		return nil, fmt.Errorf("invalid schedule %q: day of month: %w", spec, err)
+	}
+	if c.month, err = parseField(fields[3], 1, 12); err != nil {
+		return nil, fmt.Errorf("invalid schedule %q: month: %w", spec, err)
+	}
+	if c.dow, err = parseField(fields[4], 0, 7); err != nil {
+		return nil, fmt.Errorf("invalid schedule %q: day of week: %w", spec, err)
+	}
+	// Sunday is 0 or 7
+	if c.dow&(1<<7) != 0 {
+		c.dow |= 1
+	}
+	c.domAny = fields[2] == "*"
+	c.dowAny = fields[4] == "*"
+	return c, nil
+}
+
+// every runs at fixed intervals
+type every time.Duration
+
+// Next implements Schedule
+func (e every) Next(t time.Time) time.Time {
+	interval := time.Duration(e)
+	return t.Truncate(interval).Add(interval)
+}
+
+// cron holds each field's allowed values as a bit set
+type cron struct {
+	minute, hour, dom, month, dow uint64
+	domAny, dowAny                bool
+}
+
+// Next implements Schedule. It gives up, returning the zero time, if nothing matches within five
+// years, which only happens for impossible dates such as 30 February.
+func (c cron) Next(t time.Time) time.Time {
+	t = t.UTC().Truncate(time.Minute).Add(time.Minute)
+	limit := t.AddDate(5, 0, 0)
+	for t.Before(limit) {
+		switch {
+		case c.month&(1<<uint(t.Month())) == 0:
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, time.UTC)
+		case !c.dayMatches(t):
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, time.UTC)
+		case c.hour&(1<<uint(t.Hour())) == 0:
+			t = t.Truncate(time.Hour).Add(time.Hour)
+		case c.minute&(1<<uint(t.Minute())) == 0:
+			t = t.Add(time.Minute)
+		default:
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// dayMatches applies cron's day rule: when both the day of month and the day of week are
+// restricted, a day matching either runs
+func (c cron) dayMatches(t time.Time) bool {
+	dom := c.dom&(1<<uint(t.Day())) != 0
+	dow := c.dow&(1<<uint(t.Weekday())) != 0
+	if c.domAny || c.dowAny {
+		return dom && dow
+	}
+	return dom || dow
+}
+
+// parseField parses one cron field into a bit set of the values it allows
+func parseField(field string, min, max int) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(field, ",") {
+		rangePart, stepPart, hasStep := strings.Cut(part, "/")
+		step := 1
+		if hasStep {
+			n, err := strconv.Atoi(stepPart)
+			if err != nil || n < 1 {
+				return 0, fmt.Errorf("invalid step %q", stepPart)
+			}
+			step = n
+		}
+
+		low, high := min, max
+		switch {
+		case rangePart == "*":
+		case strings.Contains(rangePart, "-"):
+			from, to, _ := strings.Cut(rangePart, "-")
+			var err error
+			if low, err = parseValue(from, min, max); err != nil {
+				return 0, err
+			}
+			if high, err = parseValue(to, min, max); err != nil {
+				return 0, err
+			}
+			if low > high {
+				return 0, fmt.Errorf("invalid range %q", rangePart)
+			}
+		default:
+			value, err := parseValue(rangePart, min, max)
+			if err != nil {
+				return 0, err
+			}
+			low = value
+			if !hasStep {
+				high = value
+			}
+		}
+		for value := low; value <= high; value += step {
+			bits |= 1 << uint(value)
+		}
+	}
+	return bits, nil
+}
+
+// parseValue parses a single cron value within its field's bounds
+func parseValue(s string, min, max int) (int, error) {
+	value, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid value %q", s)
+	}
+	if value < min || value > max {
+		return 0, fmt.Errorf("value %d out of range %d-%d", value, min, max)
+	}
+	return value, nil
+}
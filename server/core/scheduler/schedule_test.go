@@ -0,0 +1,93 @@
+package scheduler_test
+
+import (
+	"testing"
+	"time"
+
+	"oms/server/core/scheduler"
+)
+
+func TestParseScheduleErrors(t *testing.T) {
+	tests := []string{
+		"",
+		"@yearly",
+		"@every",
+		"@every 0s",
+		"@every -5m",
+		"@every 500ms",
+		"@every soon",
+		"* * * *",
+		"* * * * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"*/0 * * * *",
+		"*/-1 * * * *",
+		"5-1 * * * *",
+		"a * * * *",
+		"1,,2 * * * *",
+	}
+	for _, spec := range tests {
+		t.Run(spec, func(t *testing.T) {
+			if _, err := scheduler.ParseSchedule(spec); err == nil {
+				t.Errorf("ParseSchedule(%q) succeeded, want an error", spec)
+			}
+		})
+	}
+}
+
+func TestScheduleNext(t *testing.T) {
+	// A Wednesday
+	from := time.Date(2025, 1, 15, 10, 7, 30, 0, time.UTC)
+	tests := []struct {
+		spec string
+		want time.Time
+	}{
+		{"@every 1s", time.Date(2025, 1, 15, 10, 7, 31, 0, time.UTC)},
+		{"@every 15m", time.Date(2025, 1, 15, 10, 15, 0, 0, time.UTC)},
+		{" @every  1h ", time.Date(2025, 1, 15, 11, 0, 0, 0, time.UTC)},
+		{"@hourly", time.Date(2025, 1, 15, 11, 0, 0, 0, time.UTC)},
+		{"@daily", time.Date(2025, 1, 16, 0, 0, 0, 0, time.UTC)},
+		{"@midnight", time.Date(2025, 1, 16, 0, 0, 0, 0, time.UTC)},
+		{"@weekly", time.Date(2025, 1, 19, 0, 0, 0, 0, time.UTC)},
+		{"* * * * *", time.Date(2025, 1, 15, 10, 8, 0, 0, time.UTC)},
+		{"*/5 * * * *", time.Date(2025, 1, 15, 10, 10, 0, 0, time.UTC)},
+		{"0,30 9-17 * * *", time.Date(2025, 1, 15, 10, 30, 0, 0, time.UTC)},
+		{"10-50/20 * * * *", time.Date(2025, 1, 15, 10, 10, 0, 0, time.UTC)},
+		{"5/20 * * * *", time.Date(2025, 1, 15, 10, 25, 0, 0, time.UTC)},
+		{"30 2 * * *", time.Date(2025, 1, 16, 2, 30, 0, 0, time.UTC)},
+		{"0 0 1 * *", time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 0 * 3 *", time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 0 * * 7", time.Date(2025, 1, 19, 0, 0, 0, 0, time.UTC)},
+		{"0 0 * * 1-5", time.Date(2025, 1, 16, 0, 0, 0, 0, time.UTC)},
+		// With both day fields restricted, either one matching is enough
+		{"0 0 20 * 5", time.Date(2025, 1, 17, 0, 0, 0, 0, time.UTC)},
+		{"0 0 29 2 *", time.Date(2028, 2, 29, 0, 0, 0, 0, time.UTC)},
+		{"0 0 30 2 *", time.Time{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			schedule, err := scheduler.ParseSchedule(tt.spec)
+			if err != nil {
+				t.Fatalf("ParseSchedule(%q): %v", tt.spec, err)
+			}
+			if got := schedule.Next(from); !got.Equal(tt.want) {
+				t.Errorf("ParseSchedule(%q).Next(%s) = %s, want %s", tt.spec, from, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCronNextUsesUTC(t *testing.T) {
+	schedule, err := scheduler.ParseSchedule("0 12 * * *")
+	if err != nil {
+		t.Fatalf("ParseSchedule: %v", err)
+	}
+	from := time.Date(2025, 1, 15, 15, 0, 0, 0, time.FixedZone("UTC+2", 2*60*60))
+	want := time.Date(2025, 1, 16, 12, 0, 0, 0, time.UTC)
+	if got := schedule.Next(from); !got.Equal(want) {
+		t.Errorf("Next(%s) = %s, want %s", from, got, want)
+	}
+}
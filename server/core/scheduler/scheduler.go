@@ -0,0 +1,136 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"oms/server/core/model"
+	"oms/server/core/types"
+)
+
+// Job is a named task run on a schedule. Run returns a short summary of what it did.
+type Job struct {
+	Name     string
+	Schedule Schedule
+	Run      func(ctx context.Context) (string, error)
+}
+
+// Scheduler runs jobs on their schedules. Several replicas can run the same scheduler: for each
+// scheduled time, the replica holding the job's lock runs it and records it in the run history,
+// and the others skip it.
+type Scheduler struct {
+	runs     types.JobRunStore
+	locker   types.JobLocker
+	instance string
+	jobs     []Job
+}
+
+// New creates a Scheduler that records runs in runs and elects the replica running each job with
+// locker. instance names this replica in the run history.
+func New(runs types.JobRunStore, locker types.JobLocker, instance string) *Scheduler {
+	return &Scheduler{runs: runs, locker: locker, instance: instance}
+}
+
+// Add registers a job; jobs must be added before Run
+func (s *Scheduler) Add(job Job) {
+	s.jobs = append(s.jobs, job)
+}
+
+// Run runs every job on its schedule until ctx is cancelled, waiting for runs in progress to
+// return. It fits worker.Group.Go.
+func (s *Scheduler) Run(ctx context.Context) error {
+	var wg sync.WaitGroup
+	for _, job := range s.jobs {
+		log.Printf("Scheduled job %s, next run at %s", job.Name, job.Schedule.Next(time.Now()).Format(time.RFC3339))
+		wg.Add(1)
+		go func(job Job) {
+			defer wg.Done()
+			s.loop(ctx, job)
+		}(job)
+	}
+	wg.Wait()
+	return nil
+}
+
+// loop waits for each of the job's scheduled times and runs it. Times missed while a run was
+// still going are skipped rather than run late.
+func (s *Scheduler) loop(ctx context.Context, job Job) {
+	for {
+		next := job.Schedule.Next(time.Now())
+		if next.IsZero() {
+			log.Printf("Warning: job %s has no upcoming run time", job.Name)
+			return
+		}
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+		if _, err := s.RunOnce(ctx, job, next); err != nil {
+			log.Printf("Warning: job %s: %v", job.Name, err)
+		}
+	}
+}
+
+// RunOnce runs the job for scheduledFor if this replica wins the job's lock and no replica has
+// run it for that time yet. It returns the recorded run, or nil when the run was left to another
+// replica. A job that fails, or panics, is recorded as failed.
+func (s *Scheduler) RunOnce(ctx context.Context, job Job, scheduledFor time.Time) (*model.JobRun, error) {
+	release, acquired, err := s.locker.TryLock(ctx, "job:"+job.Name)
+	if err != nil {
+		return nil, err
+	}
+	if !acquired {
+		return nil, nil
+	}
+	defer release()
+
+	run := &model.JobRun{
+		Job:          job.Name,
+		ScheduledFor: scheduledFor.UTC(),
+		Status:       model.JobRunRunning,
+		Instance:     s.instance,
+		StartedAt:    time.Now(),
+	}
+	started, err := s.runs.Start(ctx, run)
+	if err != nil {
+		return nil, fmt.Errorf("failed to record run: %w", err)
+	}
+	if !started {
+		return nil, nil
+	}
+
+	result, err := runJob(ctx, job)
+	finishedAt := time.Now()
+	run.FinishedAt = &finishedAt
+	run.Result = result
+	run.Status = model.JobRunSucceeded
+	if err != nil {
+		run.Status = model.JobRunFailed
+		run.Error = err.Error()
+		log.Printf("Job %s failed after %s: %v", job.Name, finishedAt.Sub(run.StartedAt).Round(time.Millisecond), err)
+	} else {
+		log.Printf("Job %s finished in %s: %s", job.Name, finishedAt.Sub(run.StartedAt).Round(time.Millisecond), result)
+	}
+
+	// The outcome is saved even when shutdown cancelled the job
+	if err := s.runs.Finish(context.Background(), run); err != nil {
+		return run, fmt.Errorf("failed to record the outcome of run %s: %w", run.ID, err)
+	}
+	return run, nil
+}
+
+// runJob runs the job, turning a panic into an error
+func runJob(ctx context.Context, job Job) (result string, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return job.Run(ctx)
+}
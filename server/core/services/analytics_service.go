@@ -0,0 +1,145 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"oms/server/core/model"
+	"oms/server/core/money"
+	"oms/server/core/types"
+)
+
+// maxSalesPeriods bounds how many periods one sales report covers
+const maxSalesPeriods = 1000
+
+// ErrInvalidAnalyticsQuery is returned when an analytics range, interval or limit is unusable
+var ErrInvalidAnalyticsQuery = errors.New("invalid analytics query")
+
+// AnalyticsService defines the interface for sales analytics over the orders placed in a range
+type AnalyticsService interface {
+	// Sales reports the orders, units and revenue of each period in the range, including periods
+	// without orders, and their totals
+	Sales(ctx context.Context, r model.AnalyticsRange, interval model.AnalyticsInterval) ([]*model.SalesPeriod, *model.Sales, error)
+	// TopProducts returns up to limit products by units sold
+	TopProducts(ctx context.Context, r model.AnalyticsRange, limit int) ([]*model.ProductSales, error)
+	// Funnel counts orders by the steps they reached and by their current status
+	Funnel(ctx context.Context, r model.AnalyticsRange) (*model.OrderFunnel, error)
+	// FulfillmentTimes averages how long orders took to ship and to be delivered
+	FulfillmentTimes(ctx context.Context, r model.AnalyticsRange) (*model.FulfillmentTimes, error)
+	// RollUp precomputes the sales of the UTC days before now's, recomputing the last lookback of
+	// them so later cancellations are reflected. It returns the number of rollup rows written.
+	RollUp(ctx context.Context, now time.Time, lookback time.Duration) (int64, error)
+}
+
+// analyticsService implements AnalyticsService
+type analyticsService struct {
+	store types.AnalyticsStore
+}
+
+// NewAnalyticsService creates a new AnalyticsService
+func NewAnalyticsService(store types.AnalyticsStore) AnalyticsService {
+	return &analyticsService{store: store}
+}
+
+// Sales lays the store's per-currency sums out on every period of the range
+func (s *analyticsService) Sales(ctx context.Context, r model.AnalyticsRange, interval model.AnalyticsInterval) ([]*model.SalesPeriod, *model.Sales, error) {
+	if err := checkAnalyticsRange(r); err != nil {
+		return nil, nil, err
+	}
+	if !interval.IsValid() {
+		return nil, nil, fmt.Errorf("%w: interval must be day, week or month", ErrInvalidAnalyticsQuery)
+	}
+
+	var periods []*model.SalesPeriod
+	byStart := map[int64]*model.SalesPeriod{}
+	for start := interval.Start(r.From.In(r.Location)); start.Before(r.To); start = interval.Next(start) {
+		if len(periods) == maxSalesPeriods {
+			return nil, nil, fmt.Errorf("%w: the range spans more than %d %ss", ErrInvalidAnalyticsQuery, maxSalesPeriods, interval)
+		}
+		period := &model.SalesPeriod{Start: start, Sales: model.Sales{Revenue: []money.Money{}}}
+		periods = append(periods, period)
+		byStart[start.Unix()] = period
+	}
+
+	points, err := s.store.Sales(ctx, r, interval)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to aggregate sales: %w", err)
+	}
+	totals := &model.Sales{Revenue: []money.Money{}}
+	revenue := map[string]int64{}
+	for _, point := range points {
+		period, ok := byStart[point.PeriodStart.Unix()]
+		if !ok {
+			continue
+		}
+		period.Orders += point.Orders
+		period.Units += point.Units
+		period.Revenue = append(period.Revenue, money.New(point.Revenue, point.Currency))
+		totals.Orders += point.Orders
+		totals.Units += point.Units
+		revenue[point.Currency] += point.Revenue
+	}
+	for currency, amount := range revenue {
+		totals.Revenue = append(totals.Revenue, money.New(amount, currency))
+	}
+	sort.Slice(totals.Revenue, func(i, j int) bool { return totals.Revenue[i].Currency < totals.Revenue[j].Currency })
+	return periods, totals, nil
+}
+
+// TopProducts returns the best selling products
+func (s *analyticsService) TopProducts(ctx context.Context, r model.AnalyticsRange, limit int) ([]*model.ProductSales, error) {
+	if err := checkAnalyticsRange(r); err != nil {
+		return nil, err
+	}
+	if limit < 1 {
+		return nil, fmt.Errorf("%w: limit must be positive", ErrInvalidAnalyticsQuery)
+	}
+	return s.store.TopProducts(ctx, r, limit)
+}
+
+// Funnel counts orders through the lifecycle
+func (s *analyticsService) Funnel(ctx context.Context, r model.AnalyticsRange) (*model.OrderFunnel, error) {
+	if err := checkAnalyticsRange(r); err != nil {
+		return nil, err
+	}
+	return s.store.Funnel(ctx, r)
+}
+
+// FulfillmentTimes averages fulfillment times
+func (s *analyticsService) FulfillmentTimes(ctx context.Context, r model.AnalyticsRange) (*model.FulfillmentTimes, error) {
+	if err := checkAnalyticsRange(r); err != nil {
+		return nil, err
+	}
+	return s.store.FulfillmentTimes(ctx, r)
+}
+
+// RollUp continues from the last rolled up day, or starts from the first order, so the rolled up
+// days stay contiguous even after the job didn't run for a while
+func (s *analyticsService) RollUp(ctx context.Context, now time.Time, lookback time.Duration) (int64, error) {
+	through, err := s.store.RolledUpThrough(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read rollup progress: %w", err)
+	}
+	to := now.UTC().Truncate(24 * time.Hour)
+	from := through
+	if !from.IsZero() {
+		if recompute := to.Add(-lookback); recompute.Before(from) {
+			from = recompute
+		}
+	}
+	return s.store.RollUp(ctx, from, to)
+}
+
+// checkAnalyticsRange checks that r is a non-empty range in a time zone
+func checkAnalyticsRange(r model.AnalyticsRange) error {
+	if r.Location == nil {
+		return fmt.Errorf("%w: time zone is required", ErrInvalidAnalyticsQuery)
+	}
+	if !r.From.Before(r.To) {
+		return fmt.Errorf("%w: from must be before to", ErrInvalidAnalyticsQuery)
+	}
+	return nil
+}
@@ -0,0 +1,402 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"oms/server/core/model"
+	"oms/server/core/money"
+	"oms/server/core/types"
+)
+
+var (
+	// ErrInvalidCartLine is returned when a cart line cannot be added or changed as requested
+	ErrInvalidCartLine = errors.New("invalid cart line")
+	// ErrCartLineNotFound is returned when a referenced line is not in the caller's cart
+	ErrCartLineNotFound = errors.New("cart line not found")
+	// ErrEmptyCart is returned when checking out a cart without lines
+	ErrEmptyCart = errors.New("cart is empty")
+	// ErrCartNotReady is returned when checking out a cart with lines that fail price or stock validation
+	ErrCartNotReady = errors.New("cart cannot be checked out")
+)
+
+// CartOwner identifies whose cart a request is for
+type CartOwner struct {
+	UserID int    // Signed-in customer; 0 for anonymous visitors
+	Token  string // Anonymous cart token; the cart is merged into the user's once they sign in
+}
+
+// CartService defines the interface for shopping cart business logic.
+// Carts are returned priced from the current catalog and validated against current stock.
+type CartService interface {
+	GetCart(ctx context.Context, owner CartOwner) (*model.PricedCart, error)
+	AddLine(ctx context.Context, owner CartOwner, productID uuid.UUID, variantID *uuid.UUID, quantity int) (*model.PricedCart, error)
+	UpdateLine(ctx context.Context, owner CartOwner, lineID uuid.UUID, quantity int) (*model.PricedCart, error)
+	RemoveLine(ctx context.Context, owner CartOwner, lineID uuid.UUID) (*model.PricedCart, error)
+	// Checkout places the signed-in owner's cart, with their anonymous cart merged in, as orders shipped
+	// with shippingOption and paid with paymentMethod, all or none, and empties it. Of concurrent
+	// checkouts of one cart, only the first places orders.
+	Checkout(ctx context.Context, owner CartOwner, metadata model.JSONB, shippingOption, paymentMethod string) ([]*model.Order, error)
+	// PurgeExpired deletes abandoned carts and returns how many were deleted
+	PurgeExpired(ctx context.Context) (int64, error)
+}
+
+// cartService implements CartService
+type cartService struct {
+	cartStore      types.CartStore
+	productStore   types.ProductStore
+	variantStore   types.ProductVariantStore
+	inventoryStore types.InventoryStore
+	orderService   OrderService
+	expiry         time.Duration
+}
+
+// NewCartService creates a new CartService. Carts expire after they have not been changed for expiry.
+func NewCartService(
+	cartStore types.CartStore,
+	productStore types.ProductStore,
+	variantStore types.ProductVariantStore,
+	inventoryStore types.InventoryStore,
+	orderService OrderService,
+	expiry time.Duration,
+) CartService {
+	return &cartService{
+		cartStore:      cartStore,
+		productStore:   productStore,
+		variantStore:   variantStore,
+		inventoryStore: inventoryStore,
+		orderService:   orderService,
+		expiry:         expiry,
+	}
+}
+
+// GetCart returns the owner's cart, or an empty one if they have none
+func (s *cartService) GetCart(ctx context.Context, owner CartOwner) (*model.PricedCart, error) {
+	cart, err := s.findCart(ctx, owner)
+	if err != nil {
+		return nil, err
+	}
+	return s.price(ctx, cart)
+}
+
+// AddLine adds a product, or one of its variants, to the owner's cart, creating the cart if needed.
+// Adding a product that is already in the cart increases its quantity.
+func (s *cartService) AddLine(ctx context.Context, owner CartOwner, productID uuid.UUID, variantID *uuid.UUID, quantity int) (*model.PricedCart, error) {
+	if quantity <= 0 {
+		return nil, fmt.Errorf("%w: quantity must be greater than 0", ErrInvalidCartLine)
+	}
+	if err := s.checkProduct(ctx, productID, variantID); err != nil {
+		return nil, err
+	}
+
+	cart, err := s.findCart(ctx, owner)
+	if err != nil {
+		return nil, err
+	}
+	if cart == nil {
+		if cart, err = s.createCart(ctx, owner); err != nil {
+			return nil, err
+		}
+	}
+
+	line := cart.Line(productID, variantID)
+	if line != nil {
+		line.Quantity += quantity
+	} else {
+		line = &model.CartLine{CartID: cart.ID, ProductID: productID, VariantID: variantID, Quantity: quantity}
+	}
+	if err := s.cartStore.SaveLine(ctx, line); err != nil {
+		return nil, fmt.Errorf("failed to save cart line: %w", err)
+	}
+	return s.touch(ctx, cart)
+}
+
+// UpdateLine sets the quantity of a line in the owner's cart
+func (s *cartService) UpdateLine(ctx context.Context, owner CartOwner, lineID uuid.UUID, quantity int) (*model.PricedCart, error) {
+	if quantity <= 0 {
+		return nil, fmt.Errorf("%w: quantity must be greater than 0; remove the line instead", ErrInvalidCartLine)
+	}
+	cart, line, err := s.findLine(ctx, owner, lineID)
+	if err != nil {
+		return nil, err
+	}
+
+	line.Quantity = quantity
+	if err := s.cartStore.SaveLine(ctx, line); err != nil {
+		return nil, fmt.Errorf("failed to save cart line: %w", err)
+	}
+	return s.touch(ctx, cart)
+}
+
+// RemoveLine removes a line from the owner's cart
+func (s *cartService) RemoveLine(ctx context.Context, owner CartOwner, lineID uuid.UUID) (*model.PricedCart, error) {
+	cart, _, err := s.findLine(ctx, owner, lineID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.cartStore.DeleteLine(ctx, cart.ID, lineID); err != nil {
+		return nil, fmt.Errorf("failed to remove cart line: %w", err)
+	}
+	return s.touch(ctx, cart)
+}
+
+// Checkout takes the lines out of the owner's cart first, so a concurrent checkout finds it empty,
+// then revalidates them and places one order per line through the order service, which reserves
+// stock with the same locking as single orders. The lines go back in the cart when they can't be
+// placed.
+func (s *cartService) Checkout(ctx context.Context, owner CartOwner, metadata model.JSONB, shippingOption, paymentMethod string) ([]*model.Order, error) {
+	cart, err := s.findCart(ctx, owner)
+	if err != nil {
+		return nil, err
+	}
+	if cart == nil {
+		return nil, ErrEmptyCart
+	}
+	lines, err := s.cartStore.TakeLines(ctx, cart.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check out cart: %w", err)
+	}
+	if len(lines) == 0 {
+		return nil, ErrEmptyCart
+	}
+
+	taken := *cart
+	taken.Lines = lines
+	priced, err := s.price(ctx, &taken)
+	if err != nil {
+		return nil, s.restoreLines(ctx, cart.ID, lines, err)
+	}
+	for _, line := range priced.Lines {
+		if line.Issue != "" {
+			return nil, s.restoreLines(ctx, cart.ID, lines, fmt.Errorf("%w: line %s: %s", ErrCartNotReady, line.ID, line.Issue))
+		}
+	}
+
+	items := make([]model.OrderItem, len(priced.Lines))
+	for i, line := range priced.Lines {
+		items[i] = model.OrderItem{ProductID: line.ProductID, VariantID: line.VariantID, Quantity: line.Quantity}
+	}
+	orders, err := s.orderService.CreateOrders(ctx, owner.UserID, items, metadata, shippingOption, paymentMethod)
+	if err != nil {
+		return nil, s.restoreLines(ctx, cart.ID, lines, err)
+	}
+	return orders, nil
+}
+
+// restoreLines puts the lines of a checkout that failed with err back in the cart and returns err
+func (s *cartService) restoreLines(ctx context.Context, cartID uuid.UUID, lines []model.CartLine, err error) error {
+	// The lines were taken, so they go back even when the request is cancelled
+	if restoreErr := s.cartStore.RestoreLines(context.WithoutCancel(ctx), cartID, lines); restoreErr != nil {
+		log.Printf("Warning: checkout of cart %s failed and its %d lines could not be put back: %v", cartID, len(lines), restoreErr)
+	}
+	return err
+}
+
+// PurgeExpired deletes carts that have not been changed within the expiry
+func (s *cartService) PurgeExpired(ctx context.Context) (int64, error) {
+	return s.cartStore.DeleteExpired(ctx, time.Now())
+}
+
+// findCart returns the owner's live cart, or nil if they have none. A signed-in user's anonymous
+// cart is merged into their own, and an expired user cart is emptied rather than replaced.
+func (s *cartService) findCart(ctx context.Context, owner CartOwner) (*model.Cart, error) {
+	now := time.Now()
+
+	var anonymous *model.Cart
+	if owner.Token != "" {
+		if cart, err := s.cartStore.GetByToken(ctx, owner.Token); err == nil && !cart.Expired(now) {
+			anonymous = cart
+		}
+	}
+	if owner.UserID <= 0 {
+		return anonymous, nil
+	}
+
+	cart, err := s.cartStore.GetByUserID(ctx, owner.UserID)
+	if err != nil {
+		cart = nil
+	} else if cart.Expired(now) && len(cart.Lines) > 0 {
+		if err := s.cartStore.ClearLines(ctx, cart.ID); err != nil {
+			return nil, fmt.Errorf("failed to empty expired cart: %w", err)
+		}
+		cart.Lines = nil
+	}
+
+	if anonymous == nil || len(anonymous.Lines) == 0 {
+		return cart, nil
+	}
+	if cart == nil {
+		if cart, err = s.createCart(ctx, owner); err != nil {
+			return nil, err
+		}
+	}
+	if err := s.cartStore.Merge(ctx, anonymous.ID, cart.ID); err != nil {
+		return nil, fmt.Errorf("failed to merge carts: %w", err)
+	}
+	if err := s.cartStore.Touch(ctx, cart.ID, now.Add(s.expiry)); err != nil {
+		return nil, fmt.Errorf("failed to update cart: %w", err)
+	}
+	return s.cartStore.GetByID(ctx, cart.ID)
+}
+
+// findLine returns the owner's cart and one of its lines
+func (s *cartService) findLine(ctx context.Context, owner CartOwner, lineID uuid.UUID) (*model.Cart, *model.CartLine, error) {
+	cart, err := s.findCart(ctx, owner)
+	if err != nil {
+		return nil, nil, err
+	}
+	if cart != nil {
+		for i := range cart.Lines {
+			if cart.Lines[i].ID == lineID {
+				return cart, &cart.Lines[i], nil
+			}
+		}
+	}
+	return nil, nil, fmt.Errorf("%w: %s", ErrCartLineNotFound, lineID)
+}
+
+// createCart creates an empty cart for a user, or an anonymous cart with a new token
+func (s *cartService) createCart(ctx context.Context, owner CartOwner) (*model.Cart, error) {
+	cart := &model.Cart{ExpiresAt: time.Now().Add(s.expiry)}
+	if owner.UserID > 0 {
+		userID := owner.UserID
+		cart.UserID = &userID
+	} else {
+		token, err := newCartToken()
+		if err != nil {
+			return nil, err
+		}
+		cart.Token = &token
+	}
+	if err := s.cartStore.Create(ctx, cart); err != nil {
+		return nil, fmt.Errorf("failed to create cart: %w", err)
+	}
+	return cart, nil
+}
+
+// touch pushes back the expiry of a cart that was just changed and returns it priced
+func (s *cartService) touch(ctx context.Context, cart *model.Cart) (*model.PricedCart, error) {
+	if err := s.cartStore.Touch(ctx, cart.ID, time.Now().Add(s.expiry)); err != nil {
+		return nil, fmt.Errorf("failed to update cart: %w", err)
+	}
+	updated, err := s.cartStore.GetByID(ctx, cart.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load cart: %w", err)
+	}
+	return s.price(ctx, updated)
+}
+
+// checkProduct checks that a product exists and that the variant belongs to it, or that it has no variants
+func (s *cartService) checkProduct(ctx context.Context, productID uuid.UUID, variantID *uuid.UUID) error {
+	if _, err := s.productStore.GetByID(ctx, productID); err != nil {
+		return fmt.Errorf("%w: %v", ErrProductNotFound, err)
+	}
+	if s.variantStore == nil {
+		if variantID != nil {
+			return fmt.Errorf("%w: variants are not supported", ErrInvalidVariant)
+		}
+		return nil
+	}
+	if variantID != nil {
+		variant, err := s.variantStore.GetByID(ctx, *variantID)
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidVariant, err)
+		}
+		if variant.ProductID != productID {
+			return fmt.Errorf("%w: variant %s does not belong to product %s", ErrInvalidVariant, *variantID, productID)
+		}
+		return nil
+	}
+	variants, err := s.variantStore.GetByProductID(ctx, productID)
+	if err != nil {
+		return fmt.Errorf("failed to load variants: %w", err)
+	}
+	if len(variants) > 0 {
+		return fmt.Errorf("%w: product %s has variants, variant_id is required", ErrInvalidVariant, productID)
+	}
+	return nil
+}
+
+// price prices each line of a cart from the current catalog and checks it against current stock.
+// The cart currency is that of its first priced line.
+func (s *cartService) price(ctx context.Context, cart *model.Cart) (*model.PricedCart, error) {
+	priced := &model.PricedCart{Cart: cart, Lines: []model.PricedCartLine{}, Subtotal: money.Zero(money.DefaultCurrency)}
+	if cart == nil {
+		return priced, nil
+	}
+
+	currency := ""
+	for _, line := range cart.Lines {
+		pricedLine := s.priceLine(ctx, line)
+		if pricedLine.Issue != model.CartLineIssueUnavailable {
+			if currency == "" {
+				currency = pricedLine.UnitPrice.Currency
+				priced.Subtotal = money.Zero(currency)
+			}
+			if pricedLine.UnitPrice.Currency != currency {
+				pricedLine.Issue = model.CartLineIssueCurrencyMismatch
+			} else {
+				subtotal, err := priced.Subtotal.Add(pricedLine.LineTotal)
+				if err != nil {
+					return nil, fmt.Errorf("cart subtotal: %w", err)
+				}
+				priced.Subtotal = subtotal
+			}
+		}
+		priced.Lines = append(priced.Lines, pricedLine)
+	}
+	return priced, nil
+}
+
+// priceLine prices one cart line and reports the first problem that keeps it from being checked out
+func (s *cartService) priceLine(ctx context.Context, line model.CartLine) model.PricedCartLine {
+	priced := model.PricedCartLine{CartLine: line}
+
+	product, err := s.productStore.GetByID(ctx, line.ProductID)
+	if err != nil {
+		priced.Issue = model.CartLineIssueUnavailable
+		return priced
+	}
+	priced.SKU, priced.ProductName, priced.UnitPrice = product.SKU, product.Name, product.Price
+
+	if line.VariantID != nil {
+		var variant *model.ProductVariant
+		if s.variantStore != nil {
+			variant, err = s.variantStore.GetByID(ctx, *line.VariantID)
+		}
+		if variant == nil || err != nil || variant.ProductID != line.ProductID {
+			priced.Issue = model.CartLineIssueUnavailable
+			return priced
+		}
+		priced.SKU, priced.UnitPrice = variant.SKU, variant.EffectivePrice(product.Price)
+	} else if s.variantStore != nil {
+		if variants, err := s.variantStore.GetByProductID(ctx, line.ProductID); err == nil && len(variants) > 0 {
+			priced.Issue = model.CartLineIssueVariantRequired
+		}
+	}
+	priced.LineTotal = priced.UnitPrice.Mul(int64(line.Quantity))
+
+	if inventory, err := s.inventoryStore.GetByProductID(ctx, line.StockUnitID()); err == nil {
+		priced.Available = inventory.Quantity
+	}
+	if priced.Issue == "" && priced.Available < line.Quantity {
+		priced.Issue = model.CartLineIssueInsufficientStock
+	}
+	return priced
+}
+
+// newCartToken returns a random token addressing an anonymous cart
+func newCartToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate cart token: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,345 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/google/uuid"
+	"oms/server/core/model"
+	"oms/server/core/types"
+)
+
+var (
+	// ErrInvalidCategory is returned when category fields fail validation
+	ErrInvalidCategory = errors.New("invalid category")
+	// ErrCategoryNotFound is returned when a referenced category does not exist
+	ErrCategoryNotFound = errors.New("category not found")
+	// ErrCategoryCycle is returned when a move would make a category its own ancestor
+	ErrCategoryCycle = errors.New("category cannot be moved under itself or one of its descendants")
+	// ErrCategoryHasChildren is returned when deleting a category that still has subcategories
+	ErrCategoryHasChildren = errors.New("category has subcategories")
+)
+
+// slugPattern is lowercase words separated by single dashes
+var slugPattern = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+
+// CategoryService defines the interface for category business logic
+type CategoryService interface {
+	GetTree(ctx context.Context) ([]*model.CategoryNode, error)
+	GetCategory(ctx context.Context, categoryID uuid.UUID) (*model.Category, error)
+	CreateCategory(ctx context.Context, name, slug string, parentID *uuid.UUID, sortOrder int) (*model.Category, error)
+	UpdateCategory(ctx context.Context, categoryID uuid.UUID, name, slug string) (*model.Category, error)
+	MoveCategory(ctx context.Context, categoryID uuid.UUID, parentID *uuid.UUID, position int) (*model.Category, error)
+	DeleteCategory(ctx context.Context, categoryID uuid.UUID) error
+	SetProductCategories(ctx context.Context, productID uuid.UUID, categoryIDs []uuid.UUID) ([]*model.Category, error)
+}
+
+// categoryService implements CategoryService
+type categoryService struct {
+	categoryStore types.CategoryStore
+	productStore  types.ProductStore
+}
+
+// NewCategoryService creates a new CategoryService
+func NewCategoryService(categoryStore types.CategoryStore, productStore types.ProductStore) CategoryService {
+	return &categoryService{
+		categoryStore: categoryStore,
+		productStore:  productStore,
+	}
+}
+
+// GetTree returns all root categories with their descendants nested, siblings in sort order
+func (s *categoryService) GetTree(ctx context.Context) ([]*model.CategoryNode, error) {
+	categories, err := s.categoryStore.GetAll(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load categories: %w", err)
+	}
+
+	nodes := make(map[uuid.UUID]*model.CategoryNode, len(categories))
+	for _, category := range categories {
+		nodes[category.ID] = &model.CategoryNode{Category: *category, Children: []*model.CategoryNode{}}
+	}
+
+	roots := []*model.CategoryNode{}
+	for _, category := range categories {
+		node := nodes[category.ID]
+		if category.ParentID == nil || nodes[*category.ParentID] == nil {
+			roots = append(roots, node)
+			continue
+		}
+		parent := nodes[*category.ParentID]
+		parent.Children = append(parent.Children, node)
+	}
+	return roots, nil
+}
+
+// GetCategory retrieves a single category
+func (s *categoryService) GetCategory(ctx context.Context, categoryID uuid.UUID) (*model.Category, error) {
+	category, err := s.categoryStore.GetByID(ctx, categoryID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrCategoryNotFound, categoryID)
+	}
+	return category, nil
+}
+
+// CreateCategory creates a category; the slug is derived from the name when empty
+func (s *categoryService) CreateCategory(ctx context.Context, name, slug string, parentID *uuid.UUID, sortOrder int) (*model.Category, error) {
+	category := &model.Category{ParentID: parentID, SortOrder: sortOrder}
+	if err := s.applyNameAndSlug(ctx, category, name, slug); err != nil {
+		return nil, err
+	}
+	if parentID != nil {
+		if _, err := s.categoryStore.GetByID(ctx, *parentID); err != nil {
+			return nil, fmt.Errorf("%w: parent %s", ErrCategoryNotFound, *parentID)
+		}
+	}
+
+	if err := s.categoryStore.Create(ctx, category); err != nil {
+		return nil, fmt.Errorf("failed to create category: %w", err)
+	}
+	return category, nil
+}
+
+// UpdateCategory renames a category; empty fields are left unchanged
+func (s *categoryService) UpdateCategory(ctx context.Context, categoryID uuid.UUID, name, slug string) (*model.Category, error) {
+	category, err := s.categoryStore.GetByID(ctx, categoryID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrCategoryNotFound, categoryID)
+	}
+	if name == "" {
+		name = category.Name
+	}
+	if slug == "" {
+		slug = category.Slug
+	}
+	if err := s.applyNameAndSlug(ctx, category, name, slug); err != nil {
+		return nil, err
+	}
+
+	if err := s.categoryStore.Update(ctx, category); err != nil {
+		return nil, fmt.Errorf("failed to update category: %w", err)
+	}
+	return category, nil
+}
+
+// MoveCategory places a category under parentID (nil for the root level) at position
+// among its new siblings; a negative position appends it. Sibling sort orders are renumbered.
+// Moving a category under itself or one of its descendants fails with ErrCategoryCycle.
+func (s *categoryService) MoveCategory(ctx context.Context, categoryID uuid.UUID, parentID *uuid.UUID, position int) (*model.Category, error) {
+	categories, err := s.categoryStore.GetAll(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load categories: %w", err)
+	}
+	byID := make(map[uuid.UUID]*model.Category, len(categories))
+	for _, category := range categories {
+		byID[category.ID] = category
+	}
+
+	category := byID[categoryID]
+	if category == nil {
+		return nil, fmt.Errorf("%w: %s", ErrCategoryNotFound, categoryID)
+	}
+	if parentID != nil {
+		if byID[*parentID] == nil {
+			return nil, fmt.Errorf("%w: parent %s", ErrCategoryNotFound, *parentID)
+		}
+		// Walk up from the new parent; reaching the moved category means a cycle
+		for ancestor := byID[*parentID]; ancestor != nil; {
+			if ancestor.ID == categoryID {
+				return nil, ErrCategoryCycle
+			}
+			if ancestor.ParentID == nil {
+				break
+			}
+			ancestor = byID[*ancestor.ParentID]
+		}
+	}
+
+	var siblings []*model.Category
+	for _, candidate := range categories {
+		if candidate.ID != categoryID && sameParent(candidate.ParentID, parentID) {
+			siblings = append(siblings, candidate)
+		}
+	}
+	sort.SliceStable(siblings, func(i, j int) bool {
+		return siblings[i].SortOrder < siblings[j].SortOrder
+	})
+	if position < 0 || position > len(siblings) {
+		position = len(siblings)
+	}
+	ordered := append(append(append([]*model.Category{}, siblings[:position]...), category), siblings[position:]...)
+
+	category.ParentID = parentID
+	for i, sibling := range ordered {
+		if sibling.SortOrder == i && sibling != category {
+			continue
+		}
+		sibling.SortOrder = i
+		if err := s.categoryStore.Update(ctx, sibling); err != nil {
+			return nil, fmt.Errorf("failed to move category: %w", err)
+		}
+	}
+	return category, nil
+}
+
+// DeleteCategory deletes a leaf category; categories with subcategories must be emptied first
+func (s *categoryService) DeleteCategory(ctx context.Context, categoryID uuid.UUID) error {
+	categories, err := s.categoryStore.GetAll(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to load categories: %w", err)
+	}
+	found := false
+	for _, category := range categories {
+		if category.ID == categoryID {
+			found = true
+		}
+		if category.ParentID != nil && *category.ParentID == categoryID {
+			return ErrCategoryHasChildren
+		}
+	}
+	if !found {
+		return fmt.Errorf("%w: %s", ErrCategoryNotFound, categoryID)
+	}
+	return s.categoryStore.Delete(ctx, categoryID)
+}
+
+// SetProductCategories replaces the categories a product belongs to
+func (s *categoryService) SetProductCategories(ctx context.Context, productID uuid.UUID, categoryIDs []uuid.UUID) ([]*model.Category, error) {
+	if _, err := s.productStore.GetByID(ctx, productID); err != nil {
+		return nil, fmt.Errorf("product not found: %w", err)
+	}
+
+	unique := make([]uuid.UUID, 0, len(categoryIDs))
+	seen := map[uuid.UUID]bool{}
+	for _, categoryID := range categoryIDs {
+		if seen[categoryID] {
+			continue
+		}
+		seen[categoryID] = true
+		if _, err := s.categoryStore.GetByID(ctx, categoryID); err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrCategoryNotFound, categoryID)
+		}
+		unique = append(unique, categoryID)
+	}
+
+	if err := s.categoryStore.SetProductCategories(ctx, productID, unique); err != nil {
+		return nil, fmt.Errorf("failed to assign categories: %w", err)
+	}
+	return s.categoryStore.GetByProductID(ctx, productID)
+}
+
+// applyNameAndSlug validates name and slug and sets them on category,
+// deriving the slug from the name when empty and rejecting slugs used by other categories
+func (s *categoryService) applyNameAndSlug(ctx context.Context, category *model.Category, name, slug string) error {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return fmt.Errorf("%w: name is required", ErrInvalidCategory)
+	}
+	slug = strings.TrimSpace(slug)
+	if slug == "" {
+		slug = slugify(name)
+	}
+	if !slugPattern.MatchString(slug) {
+		return fmt.Errorf("%w: slug must be lowercase letters, digits and single dashes", ErrInvalidCategory)
+	}
+	if existing, err := s.categoryStore.GetBySlug(ctx, slug); err == nil && existing.ID != category.ID {
+		return fmt.Errorf("%w: slug %q is already in use", ErrInvalidCategory, slug)
+	}
+	category.Name = name
+	category.Slug = slug
+	return nil
+}
+
+// resolveCategoryWithDescendants finds a category by slug or ID and returns its ID
+// together with the IDs of all its descendants
+func resolveCategoryWithDescendants(ctx context.Context, categoryStore types.CategoryStore, ref string) ([]uuid.UUID, error) {
+	categories, err := categoryStore.GetAll(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load categories: %w", err)
+	}
+
+	var root *model.Category
+	refID, refErr := uuid.Parse(ref)
+	for _, category := range categories {
+		if category.Slug == ref || (refErr == nil && category.ID == refID) {
+			root = category
+			break
+		}
+	}
+	if root == nil {
+		return nil, fmt.Errorf("%w: %s", ErrCategoryNotFound, ref)
+	}
+
+	children := map[uuid.UUID][]uuid.UUID{}
+	for _, category := range categories {
+		if category.ParentID != nil {
+			children[*category.ParentID] = append(children[*category.ParentID], category.ID)
+		}
+	}
+	ids := []uuid.UUID{root.ID}
+	for i := 0; i < len(ids); i++ {
+		ids = append(ids, children[ids[i]]...)
+	}
+	return ids, nil
+}
+
+// productCategoryScope returns the categories a product belongs to together with all their
+// ancestors, so that anything attached to a category also covers its subcategories.
+// A nil categoryStore yields an empty scope.
+func productCategoryScope(ctx context.Context, categoryStore types.CategoryStore, productID uuid.UUID) (map[uuid.UUID]bool, error) {
+	scope := map[uuid.UUID]bool{}
+	if categoryStore == nil {
+		return scope, nil
+	}
+
+	assigned, err := categoryStore.GetByProductID(ctx, productID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load product categories: %w", err)
+	}
+	if len(assigned) == 0 {
+		return scope, nil
+	}
+	all, err := categoryStore.GetAll(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load categories: %w", err)
+	}
+	parents := make(map[uuid.UUID]*uuid.UUID, len(all))
+	for _, category := range all {
+		parents[category.ID] = category.ParentID
+	}
+
+	for _, category := range assigned {
+		for id := &category.ID; id != nil && !scope[*id]; id = parents[*id] {
+			scope[*id] = true
+		}
+	}
+	return scope, nil
+}
+
+// sameParent reports whether two optional parent IDs refer to the same parent
+func sameParent(a, b *uuid.UUID) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return *a == *b
+}
+
+// slugify derives a URL slug from a name, e.g. "Men's T-Shirts" -> "men-s-t-shirts"
+func slugify(name string) string {
+	var builder strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(name) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			builder.WriteRune(r)
+			dash = false
+		} else if !dash && builder.Len() > 0 {
+			builder.WriteByte('-')
+			dash = true
+		}
+	}
+	return strings.TrimSuffix(builder.String(), "-")
+}
@@ -0,0 +1,320 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"oms/server/core/export"
+	"oms/server/core/model"
+	"oms/server/core/types"
+)
+
+const (
+	// exportBatchSize is how many orders an export reads at a time
+	exportBatchSize = 500
+	// exportPollInterval is how often Run looks for jobs queued by other instances
+	exportPollInterval = 5 * time.Second
+	// exportPurgeInterval is how often Run deletes expired jobs and their files
+	exportPurgeInterval = time.Hour
+)
+
+var (
+	// ErrInvalidExport is returned for an unknown format or column
+	ErrInvalidExport = export.ErrInvalidExport
+	// ErrExportNotFound is returned when a referenced export job does not exist
+	ErrExportNotFound = errors.New("export not found")
+	// ErrExportNotReady is returned when downloading an export that is still running or failed
+	ErrExportNotReady = errors.New("export not ready")
+	// ErrExportExpired is returned when downloading an export whose file was deleted
+	ErrExportExpired = errors.New("export expired")
+)
+
+// ExportService defines the interface for exports of orders, streamed or written to files in the background
+type ExportService interface {
+	// CheckOrderExport checks the export's filter, format and columns, returning ErrInvalidOrderFilter
+	// or ErrInvalidExport
+	CheckOrderExport(export model.OrderExport) error
+	// ExportOrders writes the orders matching the export to w, oldest first, reading them a batch at
+	// a time so exports of any size stream in constant memory. It returns how many were written;
+	// an invalid export fails before anything is written.
+	ExportOrders(ctx context.Context, w io.Writer, export model.OrderExport) (int64, error)
+	// StartOrderExport queues a job writing the export to a file, which Run picks up
+	StartOrderExport(ctx context.Context, export model.OrderExport, requestedBy int) (*model.ExportJob, error)
+	GetExportJob(ctx context.Context, jobID uuid.UUID) (*model.ExportJob, error)
+	// OpenExportFile opens the file of a completed job; the caller closes it
+	OpenExportFile(ctx context.Context, jobID uuid.UUID) (*model.ExportJob, *os.File, error)
+	// Run runs queued jobs one at a time and deletes expired ones until ctx is cancelled. Jobs left
+	// running by earlier processes on this host are failed first.
+	Run(ctx context.Context) error
+}
+
+// exportService implements ExportService
+type exportService struct {
+	orderStore   types.OrderStore
+	logStore     types.OrderStateLogStore
+	jobStore     types.ExportJobStore
+	fsmValidator types.FSMValidator
+	dir          string
+	retention    time.Duration
+	instance     string        // hostname:pid
+	wake         chan struct{} // Signals Run that a job was queued here
+}
+
+// NewExportService creates a new ExportService writing files to dir, where they are kept for
+// retention. instance names this process as hostname:pid.
+func NewExportService(
+	orderStore types.OrderStore,
+	logStore types.OrderStateLogStore,
+	jobStore types.ExportJobStore,
+	fsmValidator types.FSMValidator,
+	dir string,
+	retention time.Duration,
+	instance string,
+) ExportService {
+	return &exportService{
+		orderStore:   orderStore,
+		logStore:     logStore,
+		jobStore:     jobStore,
+		fsmValidator: fsmValidator,
+		dir:          dir,
+		retention:    retention,
+		instance:     instance,
+		wake:         make(chan struct{}, 1),
+	}
+}
+
+// CheckOrderExport checks the filter as the order list does, then the format and columns
+func (s *exportService) CheckOrderExport(spec model.OrderExport) error {
+	if err := checkOrderFilter(s.fsmValidator, spec.Filter); err != nil {
+		return err
+	}
+	return export.CheckOrderExport(spec)
+}
+
+// ExportOrders writes each batch of orders, with their history when included, as it is read
+func (s *exportService) ExportOrders(ctx context.Context, w io.Writer, spec model.OrderExport) (int64, error) {
+	if err := s.CheckOrderExport(spec); err != nil {
+		return 0, err
+	}
+	writer, err := export.NewOrderWriter(w, spec)
+	if err != nil {
+		return 0, err
+	}
+
+	var written int64
+	err = s.orderStore.Each(ctx, spec.Filter, exportBatchSize, spec.IncludeProduct, func(orders []*model.Order) error {
+		history := map[uuid.UUID][]*model.OrderStateLog{}
+		if spec.IncludeHistory {
+			orderIDs := make([]uuid.UUID, len(orders))
+			for i, order := range orders {
+				orderIDs[i] = order.ID
+			}
+			logs, err := s.logStore.GetByOrderIDs(ctx, orderIDs)
+			if err != nil {
+				return fmt.Errorf("failed to read order history: %w", err)
+			}
+			for _, entry := range logs {
+				history[entry.OrderID] = append(history[entry.OrderID], entry)
+			}
+		}
+		for _, order := range orders {
+			if err := writer.Write(export.OrderRow{Order: order, History: history[order.ID]}); err != nil {
+				return err
+			}
+			written++
+		}
+		// Send each batch on, so a download shows progress and nothing piles up in the buffer
+		return writer.Flush()
+	})
+	if err != nil {
+		return written, err
+	}
+	return written, writer.Flush()
+}
+
+// StartOrderExport records the job and wakes Run, which otherwise finds it on its next poll
+func (s *exportService) StartOrderExport(ctx context.Context, spec model.OrderExport, requestedBy int) (*model.ExportJob, error) {
+	if err := s.CheckOrderExport(spec); err != nil {
+		return nil, err
+	}
+	job, err := model.NewOrderExportJob(spec, requestedBy)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.jobStore.Create(ctx, job); err != nil {
+		return nil, fmt.Errorf("failed to queue export: %w", err)
+	}
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+	return job, nil
+}
+
+// GetExportJob retrieves an export job
+func (s *exportService) GetExportJob(ctx context.Context, jobID uuid.UUID) (*model.ExportJob, error) {
+	job, err := s.jobStore.GetByID(ctx, jobID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrExportNotFound, jobID)
+	}
+	return job, nil
+}
+
+// OpenExportFile opens a completed job's file from the exports directory
+func (s *exportService) OpenExportFile(ctx context.Context, jobID uuid.UUID) (*model.ExportJob, *os.File, error) {
+	job, err := s.GetExportJob(ctx, jobID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if job.Status != model.ExportCompleted {
+		return nil, nil, fmt.Errorf("%w: the export is %s", ErrExportNotReady, job.Status)
+	}
+	if job.ExpiresAt != nil && job.ExpiresAt.Before(time.Now()) {
+		return nil, nil, fmt.Errorf("%w: the file was deleted at %s", ErrExportExpired, job.ExpiresAt.UTC().Format(time.RFC3339))
+	}
+	file, err := os.Open(filepath.Join(s.dir, job.FileName()))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil, fmt.Errorf("%w: the file is gone from the exports directory", ErrExportExpired)
+		}
+		return nil, nil, err
+	}
+	return job, file, nil
+}
+
+// Run polls for queued jobs, since other instances may queue them, and is woken early for jobs
+// queued here
+func (s *exportService) Run(ctx context.Context) error {
+	if err := os.MkdirAll(s.dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create exports directory: %w", err)
+	}
+	host := s.instance
+	if i := strings.LastIndex(host, ":"); i >= 0 {
+		host = host[:i+1]
+	}
+	failed, err := s.jobStore.FailRunning(ctx, host, "interrupted: the API instance running it stopped", time.Now().Add(s.retention))
+	if err != nil {
+		log.Printf("Warning: failed to fail interrupted exports: %v", err)
+	} else if failed > 0 {
+		log.Printf("Failed %d exports interrupted by a restart", failed)
+	}
+
+	poll := time.NewTicker(exportPollInterval)
+	defer poll.Stop()
+	purge := time.NewTicker(exportPurgeInterval)
+	defer purge.Stop()
+	s.purgeExpired(ctx)
+	for {
+		s.runQueued(ctx)
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-s.wake:
+		case <-poll.C:
+		case <-purge.C:
+			s.purgeExpired(ctx)
+		}
+	}
+}
+
+// runQueued runs queued jobs until none is left or ctx is cancelled
+func (s *exportService) runQueued(ctx context.Context) {
+	for ctx.Err() == nil {
+		job, err := s.jobStore.Claim(ctx, s.instance)
+		if err != nil {
+			if ctx.Err() == nil {
+				log.Printf("Warning: failed to claim export job: %v", err)
+			}
+			return
+		}
+		if job == nil {
+			return
+		}
+		s.runJob(ctx, job)
+	}
+}
+
+// runJob writes the job's file and records the outcome. A job cut short by shutdown fails.
+func (s *exportService) runJob(ctx context.Context, job *model.ExportJob) {
+	rows, size, err := s.writeFile(ctx, job)
+	now := time.Now().UTC()
+	expiresAt := now.Add(s.retention)
+	job.CompletedAt = &now
+	job.ExpiresAt = &expiresAt
+	if err != nil {
+		job.Status = model.ExportFailed
+		job.Error = err.Error()
+		log.Printf("Warning: export %s failed after %d rows: %v", job.ID, rows, err)
+	} else {
+		job.Status = model.ExportCompleted
+		job.RowCount = rows
+		job.Size = size
+	}
+	if err := s.jobStore.Finish(context.WithoutCancel(ctx), job); err != nil {
+		log.Printf("Warning: failed to record the outcome of export %s: %v", job.ID, err)
+	}
+}
+
+// writeFile writes the export to a temporary file, renamed into place once complete so a
+// download never sees a partial file
+func (s *exportService) writeFile(ctx context.Context, job *model.ExportJob) (int64, int64, error) {
+	if job.Kind != model.ExportKindOrders {
+		return 0, 0, fmt.Errorf("unknown export kind %q", job.Kind)
+	}
+	spec, err := job.OrderExport()
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to read export parameters: %w", err)
+	}
+
+	file, err := os.CreateTemp(s.dir, job.FileName()+".*.tmp")
+	if err != nil {
+		return 0, 0, err
+	}
+	defer os.Remove(file.Name()) // No-op once renamed
+	rows, err := s.ExportOrders(ctx, file, spec)
+	if err == nil {
+		err = file.Sync()
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return rows, 0, err
+	}
+	info, err := os.Stat(file.Name())
+	if err != nil {
+		return rows, 0, err
+	}
+	if err := os.Rename(file.Name(), filepath.Join(s.dir, job.FileName())); err != nil {
+		return rows, 0, err
+	}
+	return rows, info.Size(), nil
+}
+
+// purgeExpired deletes expired jobs and their files
+func (s *exportService) purgeExpired(ctx context.Context) {
+	jobs, err := s.jobStore.GetExpired(ctx, time.Now())
+	if err != nil {
+		if ctx.Err() == nil {
+			log.Printf("Warning: failed to list expired exports: %v", err)
+		}
+		return
+	}
+	for _, job := range jobs {
+		if err := os.Remove(filepath.Join(s.dir, job.FileName())); err != nil && !errors.Is(err, os.ErrNotExist) {
+			log.Printf("Warning: failed to delete export file %s: %v", job.FileName(), err)
+			continue
+		}
+		if err := s.jobStore.Delete(ctx, job.ID); err != nil {
+			log.Printf("Warning: failed to delete export %s: %v", job.ID, err)
+		}
+	}
+}
@@ -0,0 +1,169 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"oms/server/core/model"
+	"oms/server/core/types"
+)
+
+var (
+	// ErrNotPickable is returned when an order selected for a pick list has nothing to pick
+	ErrNotPickable = errors.New("order not pickable")
+	// ErrOrderNotFound is returned when a referenced order does not exist
+	ErrOrderNotFound = errors.New("order not found")
+)
+
+// pickableStatuses are the statuses of orders waiting for their units to be picked
+var pickableStatuses = []model.OrderStatus{model.OrderStatusOrdered, model.OrderStatusPaid, model.OrderStatusPicking}
+
+// FulfillmentService defines the interface for the warehouse documents of orders waiting to ship
+type FulfillmentService interface {
+	// GeneratePickList batches the open units of the given orders, or of every ORDERED and PAID
+	// order when none are given, by stock unit in bin order. With startPicking the orders are
+	// moved to PICKING, noting the pick list, on behalf of pickedBy.
+	GeneratePickList(ctx context.Context, orderIDs []uuid.UUID, startPicking bool, pickedBy int) (*model.PickList, error)
+	// GetPackingSlip returns the packing slip for the order's open units
+	GetPackingSlip(ctx context.Context, orderID uuid.UUID) (*model.PackingSlip, error)
+}
+
+// fulfillmentService implements FulfillmentService
+type fulfillmentService struct {
+	orderStore     types.OrderStore
+	inventoryStore types.InventoryStore
+	orderService   OrderService
+}
+
+// NewFulfillmentService creates a new FulfillmentService
+func NewFulfillmentService(orderStore types.OrderStore, inventoryStore types.InventoryStore, orderService OrderService) FulfillmentService {
+	return &fulfillmentService{
+		orderStore:     orderStore,
+		inventoryStore: inventoryStore,
+		orderService:   orderService,
+	}
+}
+
+// GeneratePickList loads the orders to pick and groups their open units into a pick list
+func (s *fulfillmentService) GeneratePickList(ctx context.Context, orderIDs []uuid.UUID, startPicking bool, pickedBy int) (*model.PickList, error) {
+	orders, err := s.pickableOrders(ctx, orderIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	list := &model.PickList{
+		ID:          uuid.New(),
+		GeneratedAt: time.Now(),
+		Lines:       []model.PickListLine{},
+		OrderIDs:    make([]uuid.UUID, 0, len(orders)),
+	}
+	lines := map[uuid.UUID]int{} // Stock unit to its index in list.Lines
+	for _, order := range orders {
+		if order.OpenQuantity() == 0 {
+			continue
+		}
+		unitID := order.StockUnitID()
+		i, ok := lines[unitID]
+		if !ok {
+			i = len(list.Lines)
+			lines[unitID] = i
+			list.Lines = append(list.Lines, model.PickListLine{
+				BinLocation: s.binLocation(ctx, unitID),
+				ProductID:   order.ProductID,
+				VariantID:   order.VariantID,
+				SKU:         order.SKU,
+				ProductName: order.ProductName,
+			})
+		}
+		line := &list.Lines[i]
+		line.Quantity += order.OpenQuantity()
+		line.Orders = append(line.Orders, model.PickListOrder{OrderID: order.ID, Quantity: order.OpenQuantity()})
+		list.OrderIDs = append(list.OrderIDs, order.ID)
+		list.TotalUnits += order.OpenQuantity()
+	}
+
+	// Walking order: by bin, with stock units that have no bin last
+	sort.SliceStable(list.Lines, func(i, j int) bool {
+		a, b := list.Lines[i], list.Lines[j]
+		if (a.BinLocation == "") != (b.BinLocation == "") {
+			return b.BinLocation == ""
+		}
+		if a.BinLocation != b.BinLocation {
+			return a.BinLocation < b.BinLocation
+		}
+		return a.SKU < b.SKU
+	})
+
+	if startPicking {
+		note := fmt.Sprintf("Pick list %s", list.ID)
+		for _, order := range orders {
+			if _, err := s.orderService.StartPicking(ctx, order.ID, pickedBy, note); err != nil {
+				return nil, fmt.Errorf("failed to start picking order %s: %w", order.ID, err)
+			}
+		}
+	}
+	return list, nil
+}
+
+// GetPackingSlip builds the packing slip of an order that has units left to ship
+func (s *fulfillmentService) GetPackingSlip(ctx context.Context, orderID uuid.UUID) (*model.PackingSlip, error) {
+	order, err := s.orderStore.GetByID(ctx, orderID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrOrderNotFound, orderID)
+	}
+	if order.OpenQuantity() == 0 || !isPickable(order.CurrentStatus) && order.CurrentStatus != model.OrderStatusPartiallyShipped {
+		return nil, fmt.Errorf("%w: %s order %s has no units left to pack", ErrNotPickable, order.CurrentStatus, orderID)
+	}
+	return model.NewPackingSlip(order), nil
+}
+
+// pickableOrders returns the selected orders, checking each has units to pick, or every order
+// waiting to be picked when none are selected
+func (s *fulfillmentService) pickableOrders(ctx context.Context, orderIDs []uuid.UUID) ([]*model.Order, error) {
+	if len(orderIDs) == 0 {
+		return s.orderStore.GetByStatus(ctx, model.OrderStatusOrdered, model.OrderStatusPaid)
+	}
+
+	orders := make([]*model.Order, 0, len(orderIDs))
+	seen := map[uuid.UUID]bool{}
+	for _, orderID := range orderIDs {
+		if seen[orderID] {
+			continue
+		}
+		seen[orderID] = true
+		order, err := s.orderStore.GetByID(ctx, orderID)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrOrderNotFound, orderID)
+		}
+		if !isPickable(order.CurrentStatus) || order.OpenQuantity() == 0 {
+			return nil, fmt.Errorf("%w: %s order %s has no units waiting to be picked", ErrNotPickable, order.CurrentStatus, orderID)
+		}
+		orders = append(orders, order)
+	}
+	return orders, nil
+}
+
+// binLocation returns where a stock unit is shelved, or "" when it is not known
+func (s *fulfillmentService) binLocation(ctx context.Context, stockUnitID uuid.UUID) string {
+	inventory, err := s.inventoryStore.GetByProductID(ctx, stockUnitID)
+	if err != nil {
+		log.Printf("Warning: no inventory for stock unit %s on pick list: %v", stockUnitID, err)
+		return ""
+	}
+	return inventory.BinLocation
+}
+
+// isPickable reports whether orders in status wait for their units to be picked
+func isPickable(status model.OrderStatus) bool {
+	for _, pickable := range pickableStatuses {
+		if status == pickable {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,405 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+
+	"github.com/google/uuid"
+	"oms/server/core/imports"
+	"oms/server/core/model"
+	"oms/server/core/money"
+	"oms/server/core/types"
+)
+
+const (
+	// DefaultImportBatchSize is how many rows an import handles at a time unless told otherwise
+	DefaultImportBatchSize = 500
+	// MaxImportBatchSize bounds the rows handled, and the products locked by a save, at a time
+	MaxImportBatchSize = 5000
+	// maxImportErrors is how many errors an import report lists
+	maxImportErrors = 1000
+)
+
+// ErrInvalidImport is returned for an unknown format, a bad batch size, or a file that can't be read
+// at all, such as a CSV file with an unknown column
+var ErrInvalidImport = imports.ErrInvalidImport
+
+// ImportService defines the interface for bulk imports of the product catalog
+type ImportService interface {
+	// ImportProducts creates or updates the products in a CSV or NDJSON file by SKU, with their
+	// metadata and stock. Rows are checked as products created or updated one at a time are, and
+	// those that can't be saved are reported by line. A file that can't be read at all returns
+	// ErrInvalidImport; failures part way through are reported with the rows they stopped.
+	ImportProducts(ctx context.Context, r io.Reader, opts model.ImportOptions) (*model.ImportReport, error)
+}
+
+// importService implements ImportService
+type importService struct {
+	importStore   types.ProductImportStore
+	variantStore  types.ProductVariantStore
+	schemaService MetadataSchemaService // Optional; metadata isn't checked without it
+}
+
+// NewImportService creates a new ImportService
+func NewImportService(
+	importStore types.ProductImportStore,
+	variantStore types.ProductVariantStore,
+	schemaService MetadataSchemaService,
+) ImportService {
+	return &importService{
+		importStore:   importStore,
+		variantStore:  variantStore,
+		schemaService: schemaService,
+	}
+}
+
+// productImport is the state of an import in progress
+type productImport struct {
+	opts    model.ImportOptions
+	report  *model.ImportReport
+	seen    map[string]int            // The line of each SKU read so far
+	pending []*model.ProductImportRow // Rows an atomic import saves at the end
+}
+
+// fail adds errors to the report, up to maxImportErrors
+func (p *productImport) fail(errs ...model.ImportError) {
+	for _, err := range errs {
+		if len(p.report.Errors) < maxImportErrors {
+			p.report.Errors = append(p.report.Errors, err)
+		} else {
+			p.report.ErrorsOmitted++
+		}
+	}
+}
+
+// ImportProducts reads the file a batch at a time. Each batch is checked against the products with
+// its SKUs, then saved in its own transaction, or kept for the single one of an atomic import.
+func (s *importService) ImportProducts(ctx context.Context, r io.Reader, opts model.ImportOptions) (*model.ImportReport, error) {
+	if opts.BatchSize == 0 {
+		opts.BatchSize = DefaultImportBatchSize
+	}
+	if opts.BatchSize < 1 || opts.BatchSize > MaxImportBatchSize {
+		return nil, fmt.Errorf("%w: batch size must be between 1 and %d", ErrInvalidImport, MaxImportBatchSize)
+	}
+	reader, err := imports.NewProductReader(r, opts.Format)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &productImport{
+		opts: opts,
+		report: &model.ImportReport{
+			DryRun:  opts.DryRun,
+			Atomic:  opts.Atomic,
+			Errors:  []model.ImportError{},
+			Batches: []model.ImportBatch{},
+		},
+		seen: map[string]int{},
+	}
+	batch := make([]*imports.ProductRecord, 0, opts.BatchSize)
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			// The rest of the file is lost, which fails an atomic import as a whole
+			p.fail(model.ImportError{Message: "the rest of the file can't be read: " + err.Error()})
+			p.report.Rows++
+			p.report.Failed++
+			break
+		}
+		batch = append(batch, record)
+		if len(batch) == opts.BatchSize {
+			if err := s.importBatch(ctx, p, batch); err != nil {
+				return nil, err
+			}
+			batch = make([]*imports.ProductRecord, 0, opts.BatchSize)
+		}
+	}
+	if len(batch) > 0 {
+		if err := s.importBatch(ctx, p, batch); err != nil {
+			return nil, err
+		}
+	}
+
+	if opts.Atomic && !opts.DryRun && p.report.Failed == 0 && len(p.pending) > 0 {
+		if err := s.importStore.Apply(ctx, p.pending); err != nil {
+			return nil, fmt.Errorf("failed to save the import: %w", err)
+		}
+		p.report.Saved = true
+		for i := range p.report.Batches {
+			p.report.Batches[i].Saved = true
+		}
+	}
+	return p.report, nil
+}
+
+// importBatch checks a batch's rows and saves the valid ones, unless the import is a dry run or
+// atomic. A batch that fails to save fails its rows and the import goes on.
+func (s *importService) importBatch(ctx context.Context, p *productImport, records []*imports.ProductRecord) error {
+	skus := make([]string, 0, len(records))
+	for _, record := range records {
+		if record.SKU != "" {
+			skus = append(skus, record.SKU)
+		}
+	}
+	products, err := s.importStore.GetBySKUs(ctx, skus)
+	if err != nil {
+		return fmt.Errorf("failed to read products: %w", err)
+	}
+	existing := make(map[string]*model.Product, len(products))
+	productIDs := make([]uuid.UUID, 0, len(products))
+	for _, product := range products {
+		existing[product.SKU] = product
+		productIDs = append(productIDs, product.ID)
+	}
+	inventory, err := s.importStore.GetInventory(ctx, productIDs)
+	if err != nil {
+		return fmt.Errorf("failed to read inventory: %w", err)
+	}
+	stock := make(map[uuid.UUID]*model.Inventory, len(inventory))
+	for _, entry := range inventory {
+		stock[entry.StockUnitID] = entry
+	}
+
+	summary := model.ImportBatch{
+		Number:    len(p.report.Batches) + 1,
+		FirstLine: records[0].Line,
+		LastLine:  records[len(records)-1].Line,
+	}
+	var rows []*model.ProductImportRow
+	for _, record := range records {
+		row, errs, err := s.resolve(ctx, p, record, existing[record.SKU])
+		if err != nil {
+			return err
+		}
+		switch {
+		case len(errs) > 0:
+			p.fail(errs...)
+			summary.Failed++
+		case unchanged(row, existing[record.SKU], stock[row.Product.ID]):
+			summary.Unchanged++
+		default:
+			rows = append(rows, row)
+			if row.New {
+				summary.Created++
+			} else {
+				summary.Updated++
+			}
+		}
+	}
+
+	switch {
+	case p.opts.DryRun:
+	case p.opts.Atomic:
+		p.pending = append(p.pending, rows...)
+	case len(rows) == 0:
+		summary.Saved = true // Nothing to save
+	default:
+		if err := s.importStore.Apply(ctx, rows); err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			for _, row := range rows {
+				p.fail(model.ImportError{Line: row.Line, SKU: row.Product.SKU, Message: "the batch failed to save: " + err.Error()})
+			}
+			summary.Failed += len(rows)
+			summary.Created, summary.Updated = 0, 0
+			break
+		}
+		summary.Saved = true
+		p.report.Saved = true
+	}
+
+	p.report.Rows += len(records)
+	p.report.Created += summary.Created
+	p.report.Updated += summary.Updated
+	p.report.Unchanged += summary.Unchanged
+	p.report.Failed += summary.Failed
+	p.report.Batches = append(p.report.Batches, summary)
+	if p.opts.Progress != nil {
+		p.opts.Progress(summary)
+	}
+	return nil
+}
+
+// resolve checks a row and applies it to the product with its SKU, if any, returning the row to
+// save or why it can't be. Rows are held to the rules of creating and updating products one at a
+// time: new products need a name and price, prices can't be negative, and metadata must match the
+// product's schemas.
+func (s *importService) resolve(ctx context.Context, p *productImport, record *imports.ProductRecord, current *model.Product) (*model.ProductImportRow, []model.ImportError, error) {
+	errs := record.Errors
+	fail := func(field, format string, args ...interface{}) {
+		errs = append(errs, model.ImportError{Line: record.Line, SKU: record.SKU, Field: field, Message: fmt.Sprintf(format, args...)})
+	}
+	if record.SKU == "" {
+		if len(errs) == 0 {
+			fail("sku", "is required")
+		}
+		return nil, errs, nil
+	}
+	if line, ok := p.seen[record.SKU]; ok {
+		fail("sku", "is also on line %d", line)
+		return nil, errs, nil
+	}
+	p.seen[record.SKU] = record.Line
+	if len(record.SKU) > 255 {
+		fail("sku", "must be at most 255 characters")
+	}
+
+	row := &model.ProductImportRow{Line: record.Line, New: current == nil}
+	product := &model.Product{SKU: record.SKU, TaxClass: model.DefaultTaxClass, Metadata: model.JSONB{}}
+	if current != nil {
+		copied := *current
+		product = &copied
+		product.Metadata = make(model.JSONB, len(current.Metadata))
+		for key, value := range current.Metadata {
+			product.Metadata[key] = value
+		}
+	}
+	row.Product = product
+
+	if record.Name != nil {
+		if len(*record.Name) > 255 {
+			fail("name", "must be at most 255 characters")
+		}
+		product.Name = *record.Name
+	} else if current == nil {
+		fail("name", "is required for new products")
+	}
+
+	switch {
+	case record.Price != nil:
+		price, err := importPrice(*record.Price, record.Currency, current)
+		if err != nil {
+			fail("price", "%v", err)
+			break
+		}
+		if price.IsNegative() {
+			fail("price", "cannot be negative")
+			break
+		}
+		// Variant price overrides are in the product currency, so it cannot change under them
+		if current != nil && price.Currency != current.Price.Currency && len(current.Options) > 0 && s.variantStore != nil {
+			variants, err := s.variantStore.GetByProductID(ctx, current.ID)
+			if err != nil {
+				return nil, nil, fmt.Errorf("failed to fetch variants: %w", err)
+			}
+			for _, variant := range variants {
+				if variant.Price != nil {
+					fail("currency", "cannot change for a product whose variants have price overrides")
+					break
+				}
+			}
+		}
+		product.Price = price
+	case record.Currency != nil:
+		fail("currency", "can only be set with a price")
+	case current == nil:
+		fail("price", "is required for new products")
+	}
+
+	if record.TaxClass != nil {
+		product.TaxClass = strings.ToLower(*record.TaxClass)
+	}
+
+	// Products with variants are stocked per variant
+	if current != nil && len(current.Options) > 0 && (record.Quantity != nil || record.BinLocation != nil) {
+		fail("quantity", "can't be set for a product with variants, which are stocked per variant")
+	}
+	if record.Quantity != nil && *record.Quantity < 0 {
+		fail("quantity", "cannot be negative")
+	}
+	if record.BinLocation != nil && len(*record.BinLocation) > 50 {
+		fail("bin_location", "must be at most 50 characters")
+	}
+	row.Quantity = record.Quantity
+	row.BinLocation = record.BinLocation
+
+	for key, value := range record.Metadata {
+		if value == nil {
+			delete(product.Metadata, key)
+		} else {
+			product.Metadata[key] = value
+		}
+	}
+	if s.schemaService != nil && len(errs) == 0 && (current == nil || !sameMetadata(product.Metadata, current.Metadata)) {
+		var productID *uuid.UUID
+		if current != nil {
+			productID = &current.ID
+		}
+		if err := s.schemaService.ValidateProductMetadata(ctx, productID, product.Metadata); err != nil {
+			var validationErr *MetadataValidationError
+			if !errors.As(err, &validationErr) {
+				return nil, nil, fmt.Errorf("failed to validate metadata: %w", err)
+			}
+			for _, violation := range validationErr.Violations {
+				field := "metadata"
+				if violation.Path != "" && violation.Path != "/" {
+					field = "meta." + strings.TrimPrefix(violation.Path, "/")
+				}
+				fail(field, "%s (schema %s)", violation.Message, violation.Schema)
+			}
+		}
+	}
+
+	if len(errs) > 0 {
+		return nil, errs, nil
+	}
+	return row, nil, nil
+}
+
+// importPrice reads a row's price: an amount with a currency code, or a bare amount in the row's
+// currency, else the product's, else the default one
+func importPrice(value string, currency *string, current *model.Product) (money.Money, error) {
+	if len(strings.Fields(value)) > 1 {
+		price, err := money.Parse(value)
+		if err != nil {
+			return price, err
+		}
+		if currency != nil && !strings.EqualFold(*currency, price.Currency) {
+			return price, fmt.Errorf("is in %s, but the currency is %s", price.Currency, *currency)
+		}
+		return price, nil
+	}
+	code := money.DefaultCurrency
+	if currency != nil {
+		code = *currency
+	} else if current != nil && current.Price.Currency != "" {
+		code = current.Price.Currency
+	}
+	return money.ParseAmount(value, code)
+}
+
+// unchanged reports whether saving the row would change nothing: the product exists, isn't deleted,
+// and has the row's values and stock already
+func unchanged(row *model.ProductImportRow, current *model.Product, stock *model.Inventory) bool {
+	if row.New || current.DeletedAt.Valid {
+		return false
+	}
+	product := row.Product
+	if product.Name != current.Name || product.Price != current.Price || product.TaxClass != current.TaxClass ||
+		!sameMetadata(product.Metadata, current.Metadata) {
+		return false
+	}
+	if row.Quantity != nil && (stock == nil || stock.Quantity != *row.Quantity) {
+		return false
+	}
+	if row.BinLocation != nil && (stock == nil || stock.BinLocation != *row.BinLocation) {
+		return false
+	}
+	return true
+}
+
+// sameMetadata reports whether two metadata documents are equal, treating nil as empty
+func sameMetadata(a, b model.JSONB) bool {
+	if len(a) == 0 && len(b) == 0 {
+		return true
+	}
+	return reflect.DeepEqual(a, b)
+}
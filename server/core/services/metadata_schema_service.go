@@ -0,0 +1,292 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"oms/server/core/jsonschema"
+	"oms/server/core/model"
+	"oms/server/core/types"
+)
+
+// ProductTypeKey is the product metadata key that type-scoped schemas match on
+const ProductTypeKey = "type"
+
+var (
+	// ErrInvalidSchema is returned when a schema definition cannot be registered
+	ErrInvalidSchema = errors.New("invalid schema")
+	// ErrSchemaNotFound is returned when a referenced schema does not exist
+	ErrSchemaNotFound = errors.New("schema not found")
+)
+
+// MetadataValidationError is returned when metadata violates one or more registered schemas
+type MetadataValidationError struct {
+	Target     model.SchemaTarget
+	Violations []model.SchemaViolation
+}
+
+// Error implements the error interface
+func (e *MetadataValidationError) Error() string {
+	parts := make([]string, len(e.Violations))
+	for i, violation := range e.Violations {
+		parts[i] = jsonschema.ValidationError{Path: violation.Path, Message: violation.Message}.Error()
+	}
+	return fmt.Sprintf("%s metadata is invalid: %s", e.Target, strings.Join(parts, "; "))
+}
+
+// MetadataSchemaService defines the interface for metadata schema registration and validation
+type MetadataSchemaService interface {
+	ListSchemas(ctx context.Context) ([]*model.MetadataSchema, error)
+	GetSchema(ctx context.Context, schemaID uuid.UUID) (*model.MetadataSchema, error)
+	CreateSchema(ctx context.Context, schema *model.MetadataSchema) error
+	UpdateSchema(ctx context.Context, schema *model.MetadataSchema) error
+	DeleteSchema(ctx context.Context, schemaID uuid.UUID) error
+
+	// ValidateProductMetadata returns a *MetadataValidationError when metadata violates the
+	// schemas that apply to the product. productID is nil for products not created yet,
+	// which are only checked against unscoped and type-scoped schemas.
+	ValidateProductMetadata(ctx context.Context, productID *uuid.UUID, metadata model.JSONB) error
+	// ValidateShippingAddress returns a *MetadataValidationError when address violates the shipping address schemas
+	ValidateShippingAddress(ctx context.Context, address model.JSONB) error
+	// ValidateAgainst checks a document against an unregistered draft schema
+	ValidateAgainst(schema model.JSONB, document model.JSONB) ([]model.SchemaViolation, error)
+	// Report lists every stored product and order whose metadata violates the current schemas
+	Report(ctx context.Context) ([]model.MetadataViolationReport, error)
+}
+
+// metadataSchemaService implements MetadataSchemaService
+type metadataSchemaService struct {
+	schemaStore   types.MetadataSchemaStore
+	productStore  types.ProductStore
+	categoryStore types.CategoryStore
+	orderStore    types.OrderStore
+}
+
+// NewMetadataSchemaService creates a new MetadataSchemaService.
+// categoryStore may be nil, in which case category-scoped schemas never apply.
+func NewMetadataSchemaService(
+	schemaStore types.MetadataSchemaStore,
+	productStore types.ProductStore,
+	categoryStore types.CategoryStore,
+	orderStore types.OrderStore,
+) MetadataSchemaService {
+	return &metadataSchemaService{
+		schemaStore:   schemaStore,
+		productStore:  productStore,
+		categoryStore: categoryStore,
+		orderStore:    orderStore,
+	}
+}
+
+// ListSchemas retrieves all registered schemas
+func (s *metadataSchemaService) ListSchemas(ctx context.Context) ([]*model.MetadataSchema, error) {
+	return s.schemaStore.GetAll(ctx)
+}
+
+// GetSchema retrieves a registered schema
+func (s *metadataSchemaService) GetSchema(ctx context.Context, schemaID uuid.UUID) (*model.MetadataSchema, error) {
+	schema, err := s.schemaStore.GetByID(ctx, schemaID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrSchemaNotFound, schemaID)
+	}
+	return schema, nil
+}
+
+// CreateSchema validates and registers a schema
+func (s *metadataSchemaService) CreateSchema(ctx context.Context, schema *model.MetadataSchema) error {
+	if err := s.checkDefinition(ctx, schema); err != nil {
+		return err
+	}
+	if err := s.schemaStore.Create(ctx, schema); err != nil {
+		return fmt.Errorf("failed to create schema: %w", err)
+	}
+	return nil
+}
+
+// UpdateSchema validates and replaces a registered schema
+func (s *metadataSchemaService) UpdateSchema(ctx context.Context, schema *model.MetadataSchema) error {
+	existing, err := s.GetSchema(ctx, schema.ID)
+	if err != nil {
+		return err
+	}
+	schema.CreatedAt = existing.CreatedAt
+	if err := s.checkDefinition(ctx, schema); err != nil {
+		return err
+	}
+	if err := s.schemaStore.Update(ctx, schema); err != nil {
+		return fmt.Errorf("failed to update schema: %w", err)
+	}
+	return nil
+}
+
+// DeleteSchema removes a registered schema
+func (s *metadataSchemaService) DeleteSchema(ctx context.Context, schemaID uuid.UUID) error {
+	if _, err := s.GetSchema(ctx, schemaID); err != nil {
+		return err
+	}
+	return s.schemaStore.Delete(ctx, schemaID)
+}
+
+// ValidateProductMetadata checks metadata against every schema that applies to the product
+func (s *metadataSchemaService) ValidateProductMetadata(ctx context.Context, productID *uuid.UUID, metadata model.JSONB) error {
+	violations, err := s.productViolations(ctx, productID, metadata)
+	if err != nil {
+		return err
+	}
+	if len(violations) > 0 {
+		return &MetadataValidationError{Target: model.SchemaTargetProduct, Violations: violations}
+	}
+	return nil
+}
+
+// ValidateShippingAddress checks an order's shipping address against the shipping address schemas
+func (s *metadataSchemaService) ValidateShippingAddress(ctx context.Context, address model.JSONB) error {
+	schemas, err := s.schemaStore.GetByTarget(ctx, model.SchemaTargetShippingAddress)
+	if err != nil {
+		return fmt.Errorf("failed to load schemas: %w", err)
+	}
+	violations, err := validateAll(schemas, address)
+	if err != nil {
+		return err
+	}
+	if len(violations) > 0 {
+		return &MetadataValidationError{Target: model.SchemaTargetShippingAddress, Violations: violations}
+	}
+	return nil
+}
+
+// ValidateAgainst checks document against a draft schema without registering it
+func (s *metadataSchemaService) ValidateAgainst(schema model.JSONB, document model.JSONB) ([]model.SchemaViolation, error) {
+	return validateAll([]*model.MetadataSchema{{Name: "draft", Schema: schema}}, document)
+}
+
+// Report validates every stored product and order against the current schemas
+func (s *metadataSchemaService) Report(ctx context.Context) ([]model.MetadataViolationReport, error) {
+	var reports []model.MetadataViolationReport
+
+	products, err := s.productStore.GetAll(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load products: %w", err)
+	}
+	for _, product := range products {
+		productID := product.ID
+		violations, err := s.productViolations(ctx, &productID, product.Metadata)
+		if err != nil {
+			return nil, err
+		}
+		if len(violations) > 0 {
+			reports = append(reports, model.MetadataViolationReport{Target: model.SchemaTargetProduct, ID: product.ID, Violations: violations})
+		}
+	}
+
+	addressSchemas, err := s.schemaStore.GetByTarget(ctx, model.SchemaTargetShippingAddress)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load schemas: %w", err)
+	}
+	if len(addressSchemas) > 0 {
+		orders, err := s.orderStore.GetAll(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load orders: %w", err)
+		}
+		for _, order := range orders {
+			violations, err := validateAll(addressSchemas, order.Metadata)
+			if err != nil {
+				return nil, err
+			}
+			if len(violations) > 0 {
+				reports = append(reports, model.MetadataViolationReport{Target: model.SchemaTargetShippingAddress, ID: order.ID, Violations: violations})
+			}
+		}
+	}
+	return reports, nil
+}
+
+// productViolations validates metadata against the product schemas that apply to the product
+func (s *metadataSchemaService) productViolations(ctx context.Context, productID *uuid.UUID, metadata model.JSONB) ([]model.SchemaViolation, error) {
+	schemas, err := s.schemaStore.GetByTarget(ctx, model.SchemaTargetProduct)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load schemas: %w", err)
+	}
+	if len(schemas) == 0 {
+		return nil, nil
+	}
+
+	var categoryIDs map[uuid.UUID]bool
+	if productID != nil {
+		if categoryIDs, err = productCategoryScope(ctx, s.categoryStore, *productID); err != nil {
+			return nil, err
+		}
+	}
+	productType, _ := metadata[ProductTypeKey].(string)
+
+	var applicable []*model.MetadataSchema
+	for _, schema := range schemas {
+		if schema.CategoryID != nil && !categoryIDs[*schema.CategoryID] {
+			continue
+		}
+		if schema.ProductType != "" && schema.ProductType != productType {
+			continue
+		}
+		applicable = append(applicable, schema)
+	}
+	return validateAll(applicable, metadata)
+}
+
+// checkDefinition validates a schema's target, scope and document before it is stored
+func (s *metadataSchemaService) checkDefinition(ctx context.Context, schema *model.MetadataSchema) error {
+	schema.Name = strings.TrimSpace(schema.Name)
+	if schema.Name == "" {
+		return fmt.Errorf("%w: name is required", ErrInvalidSchema)
+	}
+	switch schema.Target {
+	case model.SchemaTargetProduct:
+	case model.SchemaTargetShippingAddress:
+		if schema.CategoryID != nil || schema.ProductType != "" {
+			return fmt.Errorf("%w: shipping address schemas cannot be scoped to a category or product type", ErrInvalidSchema)
+		}
+	default:
+		return fmt.Errorf("%w: target must be %q or %q", ErrInvalidSchema, model.SchemaTargetProduct, model.SchemaTargetShippingAddress)
+	}
+	if schema.CategoryID != nil {
+		if s.categoryStore == nil {
+			return fmt.Errorf("%w: categories are not available", ErrInvalidSchema)
+		}
+		if _, err := s.categoryStore.GetByID(ctx, *schema.CategoryID); err != nil {
+			return fmt.Errorf("%w: category %s not found", ErrInvalidSchema, *schema.CategoryID)
+		}
+	}
+	if len(schema.Schema) == 0 {
+		return fmt.Errorf("%w: schema document is required", ErrInvalidSchema)
+	}
+	if _, err := jsonschema.Compile(map[string]interface{}(schema.Schema)); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidSchema, err)
+	}
+	return nil
+}
+
+// validateAll checks document against each schema and collects the violations
+func validateAll(schemas []*model.MetadataSchema, document model.JSONB) ([]model.SchemaViolation, error) {
+	var violations []model.SchemaViolation
+	for _, schema := range schemas {
+		compiled, err := jsonschema.Compile(map[string]interface{}(schema.Schema))
+		if err != nil {
+			return nil, fmt.Errorf("%w: schema %q: %v", ErrInvalidSchema, schema.Name, err)
+		}
+
+		var doc interface{} = map[string]interface{}(document)
+		if document == nil {
+			doc = map[string]interface{}{}
+		}
+		for _, validationErr := range compiled.Validate(doc) {
+			violations = append(violations, model.SchemaViolation{
+				Schema:  schema.Name,
+				Path:    validationErr.Path,
+				Message: validationErr.Message,
+			})
+		}
+	}
+	return violations, nil
+}
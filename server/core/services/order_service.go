@@ -2,155 +2,963 @@ package services
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"log"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
+	"oms/server/core/audit"
 	"oms/server/core/model"
+	"oms/server/core/money"
 	"oms/server/core/types"
 )
 
+var (
+	// ErrInsufficientInventory is returned when a stock unit cannot cover the requested quantity
+	ErrInsufficientInventory = errors.New("insufficient inventory")
+	// ErrInvalidVariant is returned when an order's variant does not match its product
+	ErrInvalidVariant = errors.New("invalid variant")
+	// ErrProductNotFound is returned when an order references a product that does not exist
+	ErrProductNotFound = errors.New("product not found")
+	// ErrInvalidTransition is returned when the order FSM does not allow a status change
+	ErrInvalidTransition = errors.New("invalid transition")
+	// ErrInvalidQuantity is returned when shipping or cancelling more units than an order has open
+	ErrInvalidQuantity = errors.New("invalid quantity")
+	// ErrInvalidShipment is returned when an order is shipped without its carrier and tracking number
+	ErrInvalidShipment = errors.New("invalid shipment")
+	// ErrInvalidShippingOption is returned when an order asks for a shipping option its address is not quoted
+	ErrInvalidShippingOption = errors.New("invalid shipping option")
+	// ErrInvalidReason is returned when a cancellation has no known reason code
+	ErrInvalidReason = errors.New("invalid reason code")
+	// ErrInvalidOrderFilter is returned when an order list filter has an unknown status or an empty range
+	ErrInvalidOrderFilter = errors.New("invalid order filter")
+	// ErrStockNotRestored is returned when an order was cancelled but its units could not be put back in stock
+	ErrStockNotRestored = errors.New("stock not restored")
+)
+
+// staleOrderActor cancels orders left unpaid
+var staleOrderActor = model.SystemActor("cancel-stale-orders")
+
 // OrderService defines the interface for order business logic
 type OrderService interface {
-	CreateOrder(ctx context.Context, userID int, productID uuid.UUID, quantity int, metadata model.JSONB) (*model.Order, error)
-	UpdateOrderStatus(ctx context.Context, orderID uuid.UUID, newStatus model.OrderStatus, updatedBy int) (*model.Order, error)
+	CreateOrder(ctx context.Context, userID int, productID uuid.UUID, variantID *uuid.UUID, quantity int, metadata model.JSONB, couponCodes []string, shippingOption, paymentMethod string) (*model.Order, error)
+	// CreateOrders places one order per item for the same shipping address, all or none
+	CreateOrders(ctx context.Context, userID int, items []model.OrderItem, metadata model.JSONB, shippingOption, paymentMethod string) ([]*model.Order, error)
+	// PreviewOrder prices an order exactly as CreateOrder would, including promotions, without placing it
+	PreviewOrder(ctx context.Context, userID int, productID uuid.UUID, variantID *uuid.UUID, quantity int, metadata model.JSONB, couponCodes []string, shippingOption string) (*model.Order, error)
+	// QuoteShipping returns the ways items can be shipped to the address in metadata, cheapest first.
+	// There are none when no shipping rate provider is configured or none covers the address.
+	QuoteShipping(ctx context.Context, items []model.OrderItem, metadata model.JSONB) ([]model.ShippingOption, error)
+	// UpdateOrderStatus moves the order to newStatus on behalf of change.Actor. Cancelling needs a
+	// model.CancellationReason in change.Reason.
+	UpdateOrderStatus(ctx context.Context, orderID uuid.UUID, newStatus model.OrderStatus, change model.StatusChange) (*model.Order, error)
+	// ShipOrder records shipment, carrying Quantity of the order's open units, and moves the order to
+	// PARTIALLY_SHIPPED, or SHIPPED once every unit not cancelled has shipped. The shipment needs a
+	// carrier and tracking number. The first shipment captures the payment.
+	ShipOrder(ctx context.Context, orderID uuid.UUID, shipment *model.Shipment, shippedBy int) (*model.Shipment, error)
+	// DeliverShipment moves a SHIPPED order to DELIVERED once its carriers report every one of its
+	// shipments delivered; until then, and for orders in any other status, it does nothing
+	DeliverShipment(ctx context.Context, shipment *model.Shipment) (*model.Order, error)
+	// CancelQuantity cancels quantity of the order's open units, restoring their stock and refunding
+	// their share. change.Reason must be a model.CancellationReason.
+	CancelQuantity(ctx context.Context, orderID uuid.UUID, quantity int, change model.StatusChange) (*model.Order, error)
+	// StartPicking moves an order waiting to ship to PICKING, noting the pick list it was put on.
+	// Orders already PICKING are left as they are.
+	StartPicking(ctx context.Context, orderID uuid.UUID, pickedBy int, note string) (*model.Order, error)
+	GetShipments(ctx context.Context, orderID uuid.UUID) ([]*model.Shipment, error)
 	GetOrderByID(ctx context.Context, orderID uuid.UUID) (*model.Order, error)
 	GetOrdersByUserID(ctx context.Context, userID int) ([]*model.Order, error)
 	GetAllOrders(ctx context.Context) ([]*model.Order, error)
-	GetOrderHistory(ctx context.Context, orderID uuid.UUID) ([]*model.OrderStateLog, error)
+	ListOrders(ctx context.Context, filter model.OrderFilter) ([]*model.Order, error) // Newest first
+	// GetOrderHistory retrieves the order's history, oldest first, narrowed by filter
+	GetOrderHistory(ctx context.Context, orderID uuid.UUID, filter model.OrderHistoryFilter) ([]*model.OrderStateLog, error)
+	// HandlePaymentWebhook applies a payment provider webhook and moves the order along when a
+	// payment left pending at checkout is settled: authorized orders become PAID, declined ones are cancelled.
+	HandlePaymentWebhook(ctx context.Context, payload []byte, signature string) error
+	// BackfillPriceSnapshots snapshots orders placed before snapshots existed from the current
+	// catalog price and flags them as estimated. Orders whose product no longer exists are skipped.
+	BackfillPriceSnapshots(ctx context.Context) (updated, skipped int, err error)
+	// CancelStaleOrders cancels, on behalf of the system, ORDERED orders placed before cutoff whose
+	// payment is still pending or has failed, giving back their stock. They are logged with the
+	// payment_timeout reason. It returns how many were cancelled; without payments, none are.
+	CancelStaleOrders(ctx context.Context, cutoff time.Time) (int, error)
 }
 
 // orderService implements OrderService
 type orderService struct {
 	orderStore         types.OrderStore
 	inventoryStore     types.InventoryStore
+	productStore       types.ProductStore
+	variantStore       types.ProductVariantStore
 	orderStateLogStore types.OrderStateLogStore
 	fsmValidator       types.FSMValidator
+	schemaService      MetadataSchemaService
+	promotionService   PromotionService
+	taxCalculator      types.TaxCalculator
+	paymentService     PaymentService
+	shipmentStore      types.ShipmentStore
+	shippingRates      types.ShippingRateProvider
 }
 
 // NewOrderService creates a new OrderService
 func NewOrderService(
 	orderStore types.OrderStore,
 	inventoryStore types.InventoryStore,
+	productStore types.ProductStore,
+	variantStore types.ProductVariantStore,
 	orderStateLogStore types.OrderStateLogStore,
 	fsmValidator types.FSMValidator,
+	schemaService MetadataSchemaService,
+	promotionService PromotionService,
+	taxCalculator types.TaxCalculator,
+	paymentService PaymentService,
+	shipmentStore types.ShipmentStore,
+	shippingRates types.ShippingRateProvider,
 ) OrderService {
 	return &orderService{
 		orderStore:         orderStore,
 		inventoryStore:     inventoryStore,
+		productStore:       productStore,
+		variantStore:       variantStore,
 		orderStateLogStore: orderStateLogStore,
 		fsmValidator:       fsmValidator,
+		schemaService:      schemaService,
+		promotionService:   promotionService,
+		taxCalculator:      taxCalculator,
+		paymentService:     paymentService,
+		shipmentStore:      shipmentStore,
+		shippingRates:      shippingRates,
 	}
 }
 
 // CreateOrder creates a new order with inventory locking
-// Uses pessimistic locking (SELECT FOR UPDATE) to prevent overselling
-func (s *orderService) CreateOrder(ctx context.Context, userID int, productID uuid.UUID, quantity int, metadata model.JSONB) (*model.Order, error) {
+// Uses pessimistic locking (SELECT FOR UPDATE) to prevent overselling.
+// Products with variants must be ordered by variant; stock is drawn from the variant.
+// The product's SKU, name and effective unit price are snapshotted onto the order,
+// the promotions it qualifies for are recorded as discount lines and redeemed,
+// shipping is charged for shippingOption, or the cheapest option when it is empty,
+// and tax for the shipping address is recorded as tax lines. The total is then authorized
+// with paymentMethod and the order becomes PAID.
+func (s *orderService) CreateOrder(ctx context.Context, userID int, productID uuid.UUID, variantID *uuid.UUID, quantity int, metadata model.JSONB, couponCodes []string, shippingOption, paymentMethod string) (*model.Order, error) {
+	item := model.OrderItem{ProductID: productID, VariantID: variantID, Quantity: quantity}
+	orders, err := s.priceOrders(ctx, userID, []model.OrderItem{item}, metadata, couponCodes, shippingOption)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.placeOrders(ctx, orders, paymentMethod); err != nil {
+		return nil, err
+	}
+	return orders[0], nil
+}
+
+// CreateOrders places one order per item, each priced as CreateOrder would, for one shipping address.
+// Either all of them are placed or none, and they share a CheckoutID. The items ship together, so
+// shipping is quoted once for all of them and its cost shared by the orders. Automatic promotions
+// apply to each order; coupons are not supported. Each order is paid for separately with paymentMethod.
+func (s *orderService) CreateOrders(ctx context.Context, userID int, items []model.OrderItem, metadata model.JSONB, shippingOption, paymentMethod string) ([]*model.Order, error) {
+	if len(items) == 0 {
+		return nil, errors.New("no items to order")
+	}
+
+	orders, err := s.priceOrders(ctx, userID, items, metadata, nil, shippingOption)
+	if err != nil {
+		return nil, err
+	}
+	checkoutID := uuid.New()
+	for _, order := range orders {
+		order.CheckoutID = &checkoutID
+	}
+
+	if err := s.placeOrders(ctx, orders, paymentMethod); err != nil {
+		return nil, err
+	}
+	return orders, nil
+}
+
+// placeOrders reserves stock for priced orders, redeems their promotions, authorizes their payment
+// and saves them, all or none. Stock units are locked in a fixed order so concurrent checkouts of the
+// same products cannot deadlock. When a step fails, the payments, redemptions and stock already
+// taken are given back. Orders whose payment is authorized become PAID; a payment left pending
+// by a provider timeout keeps its order ORDERED until the provider's webhook settles it.
+func (s *orderService) placeOrders(ctx context.Context, orders []*model.Order, paymentMethod string) error {
+	var reserved, redeemed, charged []*model.Order
+	rollback := func(err error) error {
+		for _, order := range charged {
+			if cancelErr := s.paymentService.Cancel(ctx, order.ID); cancelErr != nil {
+				err = fmt.Errorf("%w (payment void also failed: %v)", err, cancelErr)
+			}
+		}
+		for _, order := range redeemed {
+			if releaseErr := s.promotionService.Release(ctx, order.ID); releaseErr != nil {
+				err = fmt.Errorf("%w (promotion release also failed: %v)", err, releaseErr)
+			}
+		}
+		for _, order := range reserved {
+			if restoreErr := s.inventoryStore.IncrementQuantity(ctx, order.StockUnitID(), order.Quantity); restoreErr != nil {
+				err = fmt.Errorf("%w (inventory restore also failed: %v)", err, restoreErr)
+			}
+		}
+		return err
+	}
+
+	byStockUnit := append([]*model.Order(nil), orders...)
+	sort.SliceStable(byStockUnit, func(i, j int) bool {
+		return byStockUnit[i].StockUnitID().String() < byStockUnit[j].StockUnitID().String()
+	})
+	for _, order := range byStockUnit {
+		stockUnitID := order.StockUnitID()
+
+		// Lock inventory row for update (pessimistic locking)
+		inventory, err := s.inventoryStore.LockForUpdate(ctx, stockUnitID)
+		if err != nil {
+			return rollback(fmt.Errorf("failed to lock inventory: %w", err))
+		}
+
+		// Check stock availability
+		if inventory.Quantity < order.Quantity {
+			return rollback(fmt.Errorf("%w: %s requested %d, available %d", ErrInsufficientInventory, order.SKU, order.Quantity, inventory.Quantity))
+		}
+
+		// Deduct inventory atomically
+		if err := s.inventoryStore.DecrementQuantity(ctx, stockUnitID, order.Quantity); err != nil {
+			return rollback(fmt.Errorf("failed to decrement inventory: %w", err))
+		}
+		reserved = append(reserved, order)
+	}
+
+	// Count the orders against their promotions' usage limits
+	if s.promotionService != nil {
+		for _, order := range orders {
+			if err := s.promotionService.Redeem(ctx, order); err != nil {
+				return rollback(err)
+			}
+			redeemed = append(redeemed, order)
+		}
+	}
+
+	// Authorize each order's total; a decline fails the whole checkout
+	paid := map[uuid.UUID]*model.Payment{}
+	if s.paymentService != nil {
+		for _, order := range orders {
+			payment, err := s.paymentService.Authorize(ctx, order, paymentMethod)
+			if err != nil {
+				return rollback(err)
+			}
+			charged = append(charged, order)
+			if payment.Status == model.PaymentStatusAuthorized {
+				paid[order.ID] = payment
+			}
+		}
+	}
+
+	// Create orders with status ORDERED and metadata
+	if err := s.orderStore.CreateAll(ctx, orders); err != nil {
+		return rollback(fmt.Errorf("failed to create order: %w", err))
+	}
+
+	// The orders are placed; an order that cannot be marked PAID keeps its authorized payment
+	for _, order := range orders {
+		payment, ok := paid[order.ID]
+		if !ok {
+			continue
+		}
+		change := model.StatusChange{Actor: model.IntegrationActor("payment:" + payment.Provider)}
+		if err := s.transition(ctx, order, model.OrderStatusPaid, change, nil); err != nil {
+			log.Printf("Warning: order %s was authorized but not marked PAID: %v", order.ID, err)
+		}
+	}
+	return nil
+}
+
+// PreviewOrder prices an order, including shipping, promotions and tax, without reserving stock, redeeming promotions or saving it
+func (s *orderService) PreviewOrder(ctx context.Context, userID int, productID uuid.UUID, variantID *uuid.UUID, quantity int, metadata model.JSONB, couponCodes []string, shippingOption string) (*model.Order, error) {
+	item := model.OrderItem{ProductID: productID, VariantID: variantID, Quantity: quantity}
+	orders, err := s.priceOrders(ctx, userID, []model.OrderItem{item}, metadata, couponCodes, shippingOption)
+	if err != nil {
+		return nil, err
+	}
+	return orders[0], nil
+}
+
+// QuoteShipping prices the items and quotes their shipping without validating the rest of the address
+func (s *orderService) QuoteShipping(ctx context.Context, items []model.OrderItem, metadata model.JSONB) ([]model.ShippingOption, error) {
+	if len(items) == 0 {
+		return nil, errors.New("no items to quote")
+	}
+	orders, products, err := s.buildOrders(ctx, 0, items, metadata)
+	if err != nil {
+		return nil, err
+	}
+	return s.quoteShipping(ctx, orders, products)
+}
+
+// priceOrders validates an order request and builds one order per item with its price snapshot,
+// its share of the shipping, its discounts and its tax. Order IDs are assigned up front so discount
+// lines and redemptions can reference them.
+func (s *orderService) priceOrders(ctx context.Context, userID int, items []model.OrderItem, metadata model.JSONB, couponCodes []string, shippingOption string) ([]*model.Order, error) {
 	// Validate inputs
 	if userID <= 0 {
 		return nil, fmt.Errorf("invalid user ID: %d", userID)
 	}
-	if quantity <= 0 {
-		return nil, fmt.Errorf("invalid quantity: %d", quantity)
+	if s.schemaService != nil {
+		if err := s.schemaService.ValidateShippingAddress(ctx, metadata); err != nil {
+			return nil, err
+		}
 	}
-
-	// Lock inventory row for update (pessimistic locking)
-	inventory, err := s.inventoryStore.LockForUpdate(ctx, productID)
+	orders, products, err := s.buildOrders(ctx, userID, items, metadata)
 	if err != nil {
-		return nil, fmt.Errorf("failed to lock inventory: %w", err)
+		return nil, err
+	}
+	if err := s.applyShipping(ctx, orders, products, shippingOption); err != nil {
+		return nil, err
+	}
+
+	for i, order := range orders {
+		if s.promotionService != nil {
+			if err := s.promotionService.ApplyPromotions(ctx, order, couponCodes); err != nil {
+				return nil, err
+			}
+		} else if len(couponCodes) > 0 {
+			return nil, fmt.Errorf("%w: coupons are not supported", ErrInvalidCoupon)
+		}
+
+		if s.taxCalculator != nil {
+			if err := s.applyTax(ctx, order, products[i]); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return orders, nil
+}
+
+// buildOrders builds an order with its price snapshot for each item, and returns the ordered products alongside
+func (s *orderService) buildOrders(ctx context.Context, userID int, items []model.OrderItem, metadata model.JSONB) ([]*model.Order, []*model.Product, error) {
+	orders := make([]*model.Order, len(items))
+	products := make([]*model.Product, len(items))
+	for i, item := range items {
+		if item.Quantity <= 0 {
+			return nil, nil, fmt.Errorf("invalid quantity: %d", item.Quantity)
+		}
+		variant, err := s.resolveVariant(ctx, item.ProductID, item.VariantID)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		order := &model.Order{
+			ID:            uuid.New(),
+			UserID:        userID,
+			ProductID:     item.ProductID,
+			VariantID:     item.VariantID,
+			Quantity:      item.Quantity,
+			CurrentStatus: model.OrderStatusOrdered,
+			Metadata:      metadata,
+		}
+		product, err := s.snapshotPrice(ctx, order, variant)
+		if err != nil {
+			return nil, nil, err
+		}
+		orders[i], products[i] = order, product
 	}
+	return orders, products, nil
+}
 
-	// Check stock availability
-	if inventory.Quantity < quantity {
-		return nil, fmt.Errorf("insufficient inventory: requested %d, available %d", quantity, inventory.Quantity)
+// quoteShipping quotes shipping the orders together to their shipping address
+func (s *orderService) quoteShipping(ctx context.Context, orders []*model.Order, products []*model.Product) ([]model.ShippingOption, error) {
+	if s.shippingRates == nil {
+		return []model.ShippingOption{}, nil
 	}
+	lines := make([]model.ShippableLine, len(orders))
+	for i, order := range orders {
+		lines[i] = model.NewShippableLine(products[i], order.Quantity, order.Subtotal)
+	}
+	options, err := s.shippingRates.Quote(ctx, lines, model.ShippingAddressFromMetadata(orders[0].Metadata))
+	if err != nil {
+		return nil, fmt.Errorf("failed to quote shipping: %w", err)
+	}
+	return options, nil
+}
 
-	// Deduct inventory atomically
-	err = s.inventoryStore.DecrementQuantity(ctx, productID, quantity)
+// applyShipping charges the orders for shippingOption, or the cheapest option when it is empty,
+// sharing its cost in proportion to their subtotals. Orders nothing is quoted for ship free.
+func (s *orderService) applyShipping(ctx context.Context, orders []*model.Order, products []*model.Product, shippingOption string) error {
+	code := strings.ToLower(strings.TrimSpace(shippingOption))
+	options, err := s.quoteShipping(ctx, orders, products)
 	if err != nil {
-		return nil, fmt.Errorf("failed to decrement inventory: %w", err)
+		return err
+	}
+	if len(options) == 0 {
+		if code != "" {
+			return fmt.Errorf("%w: no shipping options are available for this address", ErrInvalidShippingOption)
+		}
+		return nil
 	}
 
-	// Create order with status ORDERED and metadata
-	order := &model.Order{
-		UserID:        userID,
-		ProductID:     productID,
-		Quantity:      quantity,
-		CurrentStatus: model.OrderStatusOrdered,
-		Metadata:      metadata,
+	option := &options[0]
+	if code != "" {
+		option = nil
+		for i := range options {
+			if options[i].Code == code {
+				option = &options[i]
+				break
+			}
+		}
+		if option == nil {
+			return fmt.Errorf("%w: %q is not available for this address", ErrInvalidShippingOption, shippingOption)
+		}
 	}
 
-	err = s.orderStore.Create(ctx, order)
+	ratios := make([]int64, len(orders))
+	var weight int64
+	for i, order := range orders {
+		ratios[i] = order.Subtotal.Amount
+		weight += ratios[i]
+	}
+	if weight == 0 {
+		for i := range ratios {
+			ratios[i] = 1
+		}
+	}
+	shares, err := option.Amount.Allocate(ratios...)
 	if err != nil {
-		// If order creation fails, restore inventory
-		restoreErr := s.inventoryStore.IncrementQuantity(ctx, productID, quantity)
-		if restoreErr != nil {
-			// Log restore error but return original error
-			return nil, fmt.Errorf("failed to create order: %w (inventory restore also failed: %v)", err, restoreErr)
+		return fmt.Errorf("failed to share shipping: %w", err)
+	}
+	for i, order := range orders {
+		order.ShippingOption = option.Code
+		order.ShippingOptionName = option.Name
+		order.ShippingCarrier = option.Carrier
+		order.ShippingServiceLevel = option.ServiceLevel
+		order.Shipping = shares[i]
+		if err := order.CalculateTotals(); err != nil {
+			return err
 		}
-		return nil, fmt.Errorf("failed to create order: %w", err)
 	}
+	return nil
+}
 
-	return order, nil
+// applyTax taxes the order's merchandise and shipping, net of their discounts, for its shipping address
+func (s *orderService) applyTax(ctx context.Context, order *model.Order, product *model.Product) error {
+	merchandise, shipping := order.Subtotal, order.Shipping
+	for _, discount := range order.Discounts {
+		var err error
+		if discount.FreeShipping {
+			shipping, err = shipping.Sub(discount.Amount)
+		} else {
+			merchandise, err = merchandise.Sub(discount.Amount)
+		}
+		if err != nil {
+			return fmt.Errorf("order tax: %w", err)
+		}
+	}
+
+	taxClass := product.TaxClass
+	if taxClass == "" {
+		taxClass = model.DefaultTaxClass
+	}
+	lines := []model.TaxableLine{
+		{ProductID: &order.ProductID, TaxClass: taxClass, Amount: merchandise},
+		{TaxClass: model.ShippingTaxClass, Amount: shipping},
+	}
+	taxLines, err := s.taxCalculator.Calculate(ctx, lines, model.TaxAddressFromMetadata(order.Metadata))
+	if err != nil {
+		return fmt.Errorf("failed to calculate tax: %w", err)
+	}
+	return order.SetTaxLines(taxLines)
 }
 
-// UpdateOrderStatus updates the order status with FSM validation
-func (s *orderService) UpdateOrderStatus(ctx context.Context, orderID uuid.UUID, newStatus model.OrderStatus, updatedBy int) (*model.Order, error) {
+// UpdateOrderStatus updates the order status with FSM validation.
+// Orders with open units are shipped with ShipOrder, which records the shipment.
+func (s *orderService) UpdateOrderStatus(ctx context.Context, orderID uuid.UUID, newStatus model.OrderStatus, change model.StatusChange) (*model.Order, error) {
+	if newStatus == model.OrderStatusCancelled {
+		if err := validateCancellationReason(change.Reason); err != nil {
+			return nil, err
+		}
+	}
+
 	// Fetch current order
 	order, err := s.orderStore.GetByID(ctx, orderID)
 	if err != nil {
 		return nil, fmt.Errorf("order not found: %w", err)
 	}
 
-	currentStatus := order.CurrentStatus
+	if newStatus == model.OrderStatusShipped && order.OpenQuantity() > 0 && order.CurrentStatus != newStatus {
+		return nil, fmt.Errorf("%w: shipping an order requires its carrier and tracking number", ErrInvalidShipment)
+	}
+	if err := s.transition(ctx, order, newStatus, change, nil); err != nil {
+		return nil, err
+	}
 
-	// Validate transition using FSM
-	err = s.fsmValidator.ValidateTransition(currentStatus, newStatus)
+	// Fetch updated order
+	updatedOrder, err := s.orderStore.GetByID(ctx, orderID)
 	if err != nil {
-		return nil, fmt.Errorf("invalid transition from %s to %s: %w", currentStatus, newStatus, err)
+		return nil, fmt.Errorf("failed to fetch updated order: %w", err)
 	}
 
-	// Idempotency: If same status, return current order
-	if currentStatus == newStatus {
+	return updatedOrder, nil
+}
+
+// ShipOrder captures the payment if this is the first shipment, records the shipment and moves
+// the order to PARTIALLY_SHIPPED or SHIPPED. A failed capture ships nothing.
+func (s *orderService) ShipOrder(ctx context.Context, orderID uuid.UUID, shipment *model.Shipment, shippedBy int) (*model.Shipment, error) {
+	order, err := s.orderStore.GetByID(ctx, orderID)
+	if err != nil {
+		return nil, fmt.Errorf("order not found: %w", err)
+	}
+
+	// Carriers are matched by code when they report tracking events
+	shipment.Carrier = strings.ToLower(strings.TrimSpace(shipment.Carrier))
+	shipment.TrackingNumber = strings.TrimSpace(shipment.TrackingNumber)
+	if shipment.Carrier == "" || shipment.TrackingNumber == "" {
+		return nil, fmt.Errorf("%w: carrier and tracking number are required", ErrInvalidShipment)
+	}
+	quantity := shipment.Quantity
+	if quantity <= 0 || quantity > order.OpenQuantity() {
+		return nil, fmt.Errorf("%w: cannot ship %d units, %d of %d are open", ErrInvalidQuantity, quantity, order.OpenQuantity(), order.Quantity)
+	}
+	newStatus := model.OrderStatusPartiallyShipped
+	if quantity == order.OpenQuantity() {
+		newStatus = model.OrderStatusShipped
+	}
+	if err := s.validateTransition(order.CurrentStatus, newStatus); err != nil {
+		return nil, err
+	}
+	if err := s.settlePayment(ctx, order, newStatus); err != nil {
+		return nil, err
+	}
+
+	shipment.OrderID = order.ID
+	shipment.ShippedBy = shippedBy
+	created, err := s.shipmentStore.Create(ctx, shipment)
+	if err != nil {
+		return nil, fmt.Errorf("failed to save shipment: %w", err)
+	}
+	if !created {
+		return nil, fmt.Errorf("%w: the order changed while it was shipped", ErrInvalidQuantity)
+	}
+
+	// A concurrent shipment or cancellation may have closed the order's last open units
+	if current, err := s.orderStore.GetByID(ctx, order.ID); err == nil {
+		order = current
+	} else {
+		order.ShippedQuantity += quantity
+	}
+	if order.OpenQuantity() == 0 {
+		newStatus = model.OrderStatusShipped
+	}
+
+	note := fmt.Sprintf("Shipped %d of %d units via %s %s", quantity, order.ActiveQuantity(), shipment.Carrier, shipment.TrackingNumber)
+	if err := s.applyStatus(ctx, order, newStatus, model.StatusChange{Actor: model.UserActor(shippedBy), Note: note}, &shipment.ID); err != nil {
+		return nil, err
+	}
+	return shipment, nil
+}
+
+// DeliverShipment delivers the order with the shipment that completed it, on behalf of the system
+func (s *orderService) DeliverShipment(ctx context.Context, shipment *model.Shipment) (*model.Order, error) {
+	order, err := s.orderStore.GetByID(ctx, shipment.OrderID)
+	if err != nil {
+		return nil, fmt.Errorf("order not found: %w", err)
+	}
+	// Partially shipped orders wait for their last units, and delivered ones have nothing left to do
+	if order.CurrentStatus != model.OrderStatusShipped {
 		return order, nil
 	}
 
-	// Update order status
-	err = s.orderStore.UpdateStatus(ctx, orderID, newStatus)
+	shipments, err := s.shipmentStore.GetByOrderID(ctx, order.ID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to update order status: %w", err)
+		return nil, fmt.Errorf("failed to load shipments: %w", err)
+	}
+	for _, other := range shipments {
+		if !other.IsDelivered() {
+			return order, nil
+		}
+	}
+
+	change := model.StatusChange{
+		Actor: model.IntegrationActor("carrier:" + shipment.Carrier),
+		Note:  fmt.Sprintf("Delivered by %s %s", shipment.Carrier, shipment.TrackingNumber),
+	}
+	if err := s.transition(ctx, order, model.OrderStatusDelivered, change, &shipment.ID); err != nil {
+		return nil, err
+	}
+	return order, nil
+}
+
+// StartPicking moves the order to PICKING with FSM validation
+func (s *orderService) StartPicking(ctx context.Context, orderID uuid.UUID, pickedBy int, note string) (*model.Order, error) {
+	order, err := s.orderStore.GetByID(ctx, orderID)
+	if err != nil {
+		return nil, fmt.Errorf("order not found: %w", err)
+	}
+	if err := s.transition(ctx, order, model.OrderStatusPicking, model.StatusChange{Actor: model.UserActor(pickedBy), Note: note}, nil); err != nil {
+		return nil, err
+	}
+	return order, nil
+}
+
+// CancelQuantity cancels open units of an order. Their stock is restored, and once the payment
+// has been captured their share of the total is refunded; before that the capture leaves it out.
+// Cancelling every unit of an order that has not shipped cancels the order.
+func (s *orderService) CancelQuantity(ctx context.Context, orderID uuid.UUID, quantity int, change model.StatusChange) (*model.Order, error) {
+	if err := validateCancellationReason(change.Reason); err != nil {
+		return nil, err
+	}
+	order, err := s.orderStore.GetByID(ctx, orderID)
+	if err != nil {
+		return nil, fmt.Errorf("order not found: %w", err)
+	}
+	switch order.CurrentStatus {
+	case model.OrderStatusOrdered, model.OrderStatusPaid, model.OrderStatusPicking, model.OrderStatusPartiallyShipped:
+	default:
+		return nil, fmt.Errorf("%w: cannot cancel units of a %s order", ErrInvalidTransition, order.CurrentStatus)
+	}
+	if quantity <= 0 || quantity > order.OpenQuantity() {
+		return nil, fmt.Errorf("%w: cannot cancel %d units, %d of %d are open", ErrInvalidQuantity, quantity, order.OpenQuantity(), order.Quantity)
+	}
+
+	if order.ShippedQuantity == 0 && quantity == order.OpenQuantity() {
+		return s.UpdateOrderStatus(ctx, orderID, model.OrderStatusCancelled, change)
+	}
+
+	refund := money.Zero(order.Total.Currency)
+	if s.paymentService != nil && order.ShippedQuantity > 0 {
+		if refund, err = order.ShareOf(order.CancelledQuantity, quantity); err != nil {
+			return nil, err
+		}
+		_, err := s.paymentService.Refund(ctx, orderID, refund, fmt.Sprintf("%d units cancelled", quantity))
+		if errors.Is(err, ErrPaymentNotFound) {
+			refund = money.Zero(order.Total.Currency)
+		} else if err != nil {
+			return nil, err
+		}
+	}
+
+	cancelled, err := s.orderStore.CancelQuantity(ctx, orderID, quantity)
+	if err == nil && !cancelled {
+		err = fmt.Errorf("%w: the order changed while units were cancelled", ErrInvalidQuantity)
+	}
+	if err != nil {
+		if !refund.IsZero() {
+			log.Printf("Warning: refunded %s for order %s but its units were not cancelled: %v", refund, orderID, err)
+		}
+		return nil, err
+	}
+	order.CancelledQuantity += quantity
+
+	if err := s.inventoryStore.IncrementQuantity(ctx, order.StockUnitID(), quantity); err != nil {
+		log.Printf("Warning: failed to restore %d units of stock for order %s: %v", quantity, orderID, err)
+	}
+
+	change.Note = joinNotes(fmt.Sprintf("Cancelled %d of %d units", quantity, order.Quantity), change.Note)
+	if order.ShippedQuantity > 0 && order.OpenQuantity() == 0 {
+		// Everything left has shipped
+		err = s.transition(ctx, order, model.OrderStatusShipped, change, nil)
+	} else {
+		err = s.applyStatus(ctx, order, order.CurrentStatus, change, nil)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return s.orderStore.GetByID(ctx, orderID)
+}
+
+// transition moves an order to newStatus with FSM validation and records it in the order's history.
+// Payment is settled first, so an order whose capture or void fails stays where it is.
+func (s *orderService) transition(ctx context.Context, order *model.Order, newStatus model.OrderStatus, change model.StatusChange, shipmentID *uuid.UUID) error {
+	currentStatus := order.CurrentStatus
+	if err := s.validateTransition(currentStatus, newStatus); err != nil {
+		return err
+	}
+	// Idempotency: If same status, there is nothing to do
+	if currentStatus == newStatus {
+		return nil
+	}
+	if err := s.settlePayment(ctx, order, newStatus); err != nil {
+		return err
+	}
+	return s.applyStatus(ctx, order, newStatus, change, shipmentID)
+}
+
+// validateTransition checks the transition against the FSM
+func (s *orderService) validateTransition(currentStatus, newStatus model.OrderStatus) error {
+	if err := s.fsmValidator.ValidateTransition(currentStatus, newStatus); err != nil {
+		return fmt.Errorf("%w from %s to %s: %w", ErrInvalidTransition, currentStatus, newStatus, err)
+	}
+	return nil
+}
+
+// settlePayment takes the payment when the order ships, and gives it back when it is cancelled.
+// Only the share of the units that were not cancelled is captured.
+func (s *orderService) settlePayment(ctx context.Context, order *model.Order, newStatus model.OrderStatus) error {
+	if s.paymentService == nil {
+		return nil
+	}
+	if s.fsmValidator.RequiresPaymentCapture(newStatus) {
+		amount, err := order.ShareOf(order.CancelledQuantity, order.ActiveQuantity())
+		if err != nil {
+			return err
+		}
+		if err := s.paymentService.Capture(ctx, order.ID, amount); err != nil {
+			return err
+		}
+	}
+	if s.fsmValidator.RequiresPaymentRelease(newStatus) {
+		if err := s.paymentService.Cancel(ctx, order.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// applyStatus saves the order's new status and records the step in its history, with the actor,
+// reason and the API request that made it; a step that keeps the status, such as a further partial
+// shipment, is recorded with its note only. Cancelled orders give back their open units' stock
+// and their promotion redemptions; the order stays cancelled when its stock can't be restored,
+// failing with ErrStockNotRestored.
+func (s *orderService) applyStatus(ctx context.Context, order *model.Order, newStatus model.OrderStatus, change model.StatusChange, shipmentID *uuid.UUID) error {
+	orderID := order.ID
+	currentStatus := order.CurrentStatus
+
+	// Update order status, unless another change got there first
+	if newStatus != currentStatus {
+		applied, err := s.orderStore.UpdateStatus(ctx, orderID, currentStatus, newStatus)
+		if err != nil {
+			return fmt.Errorf("failed to update order status: %w", err)
+		}
+		if !applied {
+			return fmt.Errorf("%w: order %s changed while moving from %s to %s", ErrInvalidTransition, orderID, currentStatus, newStatus)
+		}
+		order.CurrentStatus = newStatus
 	}
 
 	// Create audit log entry
+	request := audit.RequestFrom(ctx)
 	stateLog := &model.OrderStateLog{
 		OrderID:        orderID,
 		PreviousStatus: currentStatus,
 		NewStatus:      newStatus,
-		UpdatedBy:      updatedBy,
+		UpdatedBy:      change.Actor.UserID(),
+		ActorType:      change.Actor.Type,
+		ActorID:        change.Actor.ID,
+		ReasonCode:     change.Reason,
+		Note:           change.Note,
+		ShipmentID:     shipmentID,
+		RequestID:      request.ID,
+		ClientIP:       request.ClientIP,
 		UpdatedAt:      time.Now(),
 	}
-	err = s.orderStateLogStore.Create(ctx, stateLog)
+	// The status is saved by now, so a missing history entry doesn't fail the update
+	if err := s.orderStateLogStore.Create(ctx, stateLog); err != nil {
+		log.Printf("Warning: order %s moved from %s to %s but its history was not recorded: %v", orderID, currentStatus, newStatus, err)
+	}
+
+	// If status is CANCELLED, restore inventory of the units not cancelled before
+	var restoreErr error
+	if newStatus != currentStatus && s.fsmValidator.RequiresInventoryRestore(newStatus) {
+		if err := s.inventoryStore.IncrementQuantity(ctx, order.StockUnitID(), order.OpenQuantity()); err != nil {
+			log.Printf("Warning: failed to restore %d units of stock for cancelled order %s: %v", order.OpenQuantity(), orderID, err)
+			restoreErr = fmt.Errorf("%w: %d units of order %s: %w", ErrStockNotRestored, order.OpenQuantity(), orderID, err)
+		}
+		// Cancelled orders no longer count against promotion usage limits
+		if s.promotionService != nil && len(order.Discounts) > 0 {
+			if err := s.promotionService.Release(ctx, orderID); err != nil {
+				log.Printf("Warning: failed to release the promotion redemptions of cancelled order %s: %v", orderID, err)
+			}
+		}
+	}
+	return restoreErr
+}
+
+// HandlePaymentWebhook lets the payment service apply the webhook, then settles orders still waiting on their payment
+func (s *orderService) HandlePaymentWebhook(ctx context.Context, payload []byte, signature string) error {
+	if s.paymentService == nil {
+		return fmt.Errorf("%w: payments are not enabled", ErrInvalidWebhook)
+	}
+	payment, err := s.paymentService.HandleWebhook(ctx, payload, signature)
+	if err != nil || payment == nil {
+		return err
+	}
+
+	var newStatus model.OrderStatus
+	switch payment.Status {
+	case model.PaymentStatusAuthorized:
+		newStatus = model.OrderStatusPaid
+	case model.PaymentStatusDeclined, model.PaymentStatusFailed:
+		newStatus = model.OrderStatusCancelled
+	default:
+		return nil
+	}
+
+	order, err := s.orderStore.GetByID(ctx, payment.OrderID)
 	if err != nil {
-		// Log error but don't fail the update
-		// TODO: Add proper logging
+		// The event is recorded, so a redelivery would not get here again
+		log.Printf("Warning: payment %s changed to %s but order %s could not be loaded: %v", payment.ID, payment.Status, payment.OrderID, err)
+		return nil
 	}
+	// An order picked while its payment was pending stays on the pick list once paid, but not once declined
+	picking := order.CurrentStatus == model.OrderStatusPicking && newStatus == model.OrderStatusCancelled
+	if order.CurrentStatus != model.OrderStatusOrdered && !picking {
+		return nil
+	}
+	change := model.StatusChange{Actor: model.IntegrationActor("payment:" + payment.Provider)}
+	if newStatus == model.OrderStatusCancelled {
+		change.Reason = string(model.CancellationPaymentDeclined)
+		change.Note = fmt.Sprintf("Payment %s %s", payment.ID, payment.Status)
+	}
+	err = s.transition(ctx, order, newStatus, change, nil)
+	if errors.Is(err, ErrStockNotRestored) {
+		// Logged already; the order is cancelled and a redelivery would not get here again
+		return nil
+	}
+	return err
+}
 
-	// If status is CANCELLED, restore inventory
-	if s.fsmValidator.RequiresInventoryRestore(newStatus) {
-		err = s.inventoryStore.IncrementQuantity(ctx, order.ProductID, order.Quantity)
+// resolveVariant checks that variantID belongs to productID, and that products
+// with variants are not ordered without one. It returns the variant, or nil when none is ordered.
+func (s *orderService) resolveVariant(ctx context.Context, productID uuid.UUID, variantID *uuid.UUID) (*model.ProductVariant, error) {
+	if s.variantStore == nil {
+		if variantID != nil {
+			return nil, fmt.Errorf("%w: variants are not supported", ErrInvalidVariant)
+		}
+		return nil, nil
+	}
+
+	if variantID != nil {
+		variant, err := s.variantStore.GetByID(ctx, *variantID)
 		if err != nil {
-			// Log error but don't fail the update
-			// TODO: Add proper logging and potentially rollback
+			return nil, fmt.Errorf("%w: %v", ErrInvalidVariant, err)
+		}
+		if variant.ProductID != productID {
+			return nil, fmt.Errorf("%w: variant %s does not belong to product %s", ErrInvalidVariant, *variantID, productID)
 		}
+		return variant, nil
 	}
 
-	// Fetch updated order
-	updatedOrder, err := s.orderStore.GetByID(ctx, orderID)
+	variants, err := s.variantStore.GetByProductID(ctx, productID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to fetch updated order: %w", err)
+		return nil, fmt.Errorf("failed to load variants: %w", err)
+	}
+	if len(variants) > 0 {
+		return nil, fmt.Errorf("%w: product %s has variants, variant_id is required", ErrInvalidVariant, productID)
 	}
+	return nil, nil
+}
 
-	return updatedOrder, nil
+// snapshotPrice copies the product's SKU and name and the variant's effective price onto the order,
+// and returns the product. Variant orders record the variant SKU.
+func (s *orderService) snapshotPrice(ctx context.Context, order *model.Order, variant *model.ProductVariant) (*model.Product, error) {
+	product, err := s.productStore.GetByID(ctx, order.ProductID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrProductNotFound, err)
+	}
+
+	sku, unitPrice := product.SKU, product.Price
+	if variant != nil {
+		sku, unitPrice = variant.SKU, variant.EffectivePrice(product.Price)
+	}
+	return product, order.SetPriceSnapshot(sku, product.Name, unitPrice)
+}
+
+// CancelStaleOrders cancels each stale order with UpdateOrderStatus, so the cancellation is
+// validated, voids the payment and is logged like any other. Orders placed without payment ship
+// from ORDERED, so only those still waiting on their payment are stale. Orders that moved on
+// meanwhile are left alone; other failures, including stock that could not be restored, are
+// reported once every stale order has been tried.
+func (s *orderService) CancelStaleOrders(ctx context.Context, cutoff time.Time) (int, error) {
+	if s.paymentService == nil {
+		return 0, nil
+	}
+	orders, err := s.orderStore.GetByStatus(ctx, model.OrderStatusOrdered)
+	if err != nil {
+		return 0, fmt.Errorf("failed to load orders: %w", err)
+	}
+
+	cancelled := 0
+	var errs []error
+	for _, order := range orders {
+		// Oldest first, so the rest are newer
+		if !order.CreatedAt.Before(cutoff) {
+			break
+		}
+		if ctx.Err() != nil {
+			errs = append(errs, ctx.Err())
+			break
+		}
+		unpaid, err := s.awaitingPayment(ctx, order.ID)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("order %s: %w", order.ID, err))
+			continue
+		}
+		if !unpaid {
+			continue
+		}
+		change := model.StatusChange{
+			Actor:  staleOrderActor,
+			Reason: string(model.CancellationPaymentTimeout),
+			Note:   fmt.Sprintf("Still ORDERED after %s", time.Since(order.CreatedAt).Truncate(time.Minute)),
+		}
+		_, err = s.UpdateOrderStatus(ctx, order.ID, model.OrderStatusCancelled, change)
+		switch {
+		case err == nil:
+			cancelled++
+		case errors.Is(err, ErrStockNotRestored):
+			// Cancelled all the same; the run fails so the stock gets corrected by hand
+			cancelled++
+			errs = append(errs, err)
+		case errors.Is(err, ErrInvalidTransition):
+		default:
+			errs = append(errs, fmt.Errorf("order %s: %w", order.ID, err))
+		}
+	}
+	return cancelled, errors.Join(errs...)
+}
+
+// awaitingPayment reports whether the order's latest payment is still pending or did not go through
+func (s *orderService) awaitingPayment(ctx context.Context, orderID uuid.UUID) (bool, error) {
+	payments, err := s.paymentService.GetPayments(ctx, orderID)
+	if err != nil {
+		return false, fmt.Errorf("failed to load payments: %w", err)
+	}
+	if len(payments) == 0 {
+		return false, nil
+	}
+	switch payments[0].Status {
+	case model.PaymentStatusPending, model.PaymentStatusDeclined, model.PaymentStatusFailed:
+		return true, nil
+	}
+	return false, nil
+}
+
+// BackfillPriceSnapshots snapshots orders without one from the current catalog and flags them as estimated
+func (s *orderService) BackfillPriceSnapshots(ctx context.Context) (int, int, error) {
+	orders, err := s.orderStore.GetWithoutPriceSnapshot(ctx)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to load orders: %w", err)
+	}
+
+	updated, skipped := 0, 0
+	for _, order := range orders {
+		// The variant may have been deleted since; the product price is the closest estimate then
+		var variant *model.ProductVariant
+		if order.VariantID != nil && s.variantStore != nil {
+			variant, _ = s.variantStore.GetByID(ctx, *order.VariantID)
+		}
+		if _, err := s.snapshotPrice(ctx, order, variant); err != nil {
+			if errors.Is(err, ErrProductNotFound) {
+				skipped++
+				continue
+			}
+			return updated, skipped, fmt.Errorf("order %s: %w", order.ID, err)
+		}
+		order.PriceEstimated = true
+		if err := s.orderStore.UpdatePricing(ctx, order); err != nil {
+			return updated, skipped, fmt.Errorf("order %s: failed to save snapshot: %w", order.ID, err)
+		}
+		updated++
+	}
+	return updated, skipped, nil
 }
 
 // GetOrderByID retrieves an order by ID
@@ -168,8 +976,50 @@ func (s *orderService) GetAllOrders(ctx context.Context) ([]*model.Order, error)
 	return s.orderStore.GetAll(ctx)
 }
 
+// ListOrders retrieves the orders matching filter
+func (s *orderService) ListOrders(ctx context.Context, filter model.OrderFilter) ([]*model.Order, error) {
+	if err := checkOrderFilter(s.fsmValidator, filter); err != nil {
+		return nil, err
+	}
+	return s.orderStore.Search(ctx, filter)
+}
+
+// checkOrderFilter checks that filter's statuses exist and its range is not empty
+func checkOrderFilter(validator types.FSMValidator, filter model.OrderFilter) error {
+	for _, status := range filter.Statuses {
+		if !validator.IsValidStatus(status) {
+			return fmt.Errorf("%w: unknown status %q", ErrInvalidOrderFilter, status)
+		}
+	}
+	if filter.From != nil && filter.To != nil && !filter.From.Before(*filter.To) {
+		return fmt.Errorf("%w: from must be before to", ErrInvalidOrderFilter)
+	}
+	return nil
+}
+
+// GetShipments retrieves an order's shipments, oldest first
+func (s *orderService) GetShipments(ctx context.Context, orderID uuid.UUID) ([]*model.Shipment, error) {
+	return s.shipmentStore.GetByOrderID(ctx, orderID)
+}
+
 // GetOrderHistory retrieves the state change history for an order
-func (s *orderService) GetOrderHistory(ctx context.Context, orderID uuid.UUID) ([]*model.OrderStateLog, error) {
-	return s.orderStateLogStore.GetByOrderID(ctx, orderID)
+func (s *orderService) GetOrderHistory(ctx context.Context, orderID uuid.UUID, filter model.OrderHistoryFilter) ([]*model.OrderStateLog, error) {
+	return s.orderStateLogStore.GetByOrderID(ctx, orderID, filter)
+}
+
+// validateCancellationReason checks a cancellation's reason code
+func validateCancellationReason(reason string) error {
+	if !model.CancellationReason(reason).IsValid() {
+		return fmt.Errorf("%w: cancelling requires a reason such as %s, got %q", ErrInvalidReason, model.CancellationCustomerRequest, reason)
+	}
+	return nil
+}
+
+// joinNotes appends a note given with a change to the note describing it
+func joinNotes(what, note string) string {
+	if note == "" {
+		return what
+	}
+	return what + ": " + note
 }
 
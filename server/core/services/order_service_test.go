@@ -0,0 +1,338 @@
+package services_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"oms/server/core/fake"
+	"oms/server/core/fsm"
+	"oms/server/core/model"
+	"oms/server/core/money"
+	"oms/server/core/services"
+)
+
+// paymentRecorder records the refunds and payment releases the order service asks for, and
+// serves payments in the given statuses
+type paymentRecorder struct {
+	services.PaymentService
+	statuses  map[uuid.UUID]model.PaymentStatus
+	refunds   []money.Money
+	cancelled bool
+}
+
+func (p *paymentRecorder) GetPayments(ctx context.Context, orderID uuid.UUID) ([]*model.Payment, error) {
+	status, ok := p.statuses[orderID]
+	if !ok {
+		return nil, nil
+	}
+	return []*model.Payment{{OrderID: orderID, Status: status}}, nil
+}
+
+func (p *paymentRecorder) Capture(ctx context.Context, orderID uuid.UUID, amount money.Money) error {
+	return nil
+}
+
+func (p *paymentRecorder) Cancel(ctx context.Context, orderID uuid.UUID) error {
+	p.cancelled = true
+	return nil
+}
+
+func (p *paymentRecorder) Refund(ctx context.Context, orderID uuid.UUID, amount money.Money, reason string) (*model.Payment, error) {
+	p.refunds = append(p.refunds, amount)
+	return &model.Payment{OrderID: orderID}, nil
+}
+
+// newCancelTest creates an order service over the fakes, with an order of 4 units totalling
+// 10.00 USD and 10 units of its product in stock
+func newCancelTest(t *testing.T, status model.OrderStatus, shipped int) (services.OrderService, *paymentRecorder, *model.Order) {
+	t.Helper()
+	ctx := context.Background()
+	orderStore, inventoryStore := &fake.OrderStoreFake{}, &fake.InventoryStoreFake{}
+	payments := &paymentRecorder{}
+	service := services.NewOrderService(orderStore, inventoryStore, nil, nil, &fake.OrderStateLogStoreFake{},
+		fsm.NewValidator(), nil, nil, nil, payments, nil, nil)
+
+	order := &model.Order{
+		ProductID:       uuid.New(),
+		Quantity:        4,
+		ShippedQuantity: shipped,
+		CurrentStatus:   status,
+		UnitPrice:       usd(250),
+		Subtotal:        usd(1000),
+		Total:           usd(1000),
+	}
+	if err := orderStore.Create(ctx, order); err != nil {
+		t.Fatal(err)
+	}
+	if err := inventoryStore.UpdateQuantity(ctx, order.ProductID, 10); err != nil {
+		t.Fatal(err)
+	}
+	return service, payments, order
+}
+
+func usd(amount int64) money.Money { return money.New(amount, "USD") }
+
+func customerRequest(note string) model.StatusChange {
+	return model.StatusChange{Actor: model.UserActor(1), Reason: string(model.CancellationCustomerRequest), Note: note}
+}
+
+func stock(t *testing.T, productID uuid.UUID) int {
+	t.Helper()
+	inventory, err := (&fake.InventoryStoreFake{}).GetByProductID(context.Background(), productID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return inventory.Quantity
+}
+
+func TestCancelQuantityPartiallyShipped(t *testing.T) {
+	ctx := context.Background()
+	service, payments, order := newCancelTest(t, model.OrderStatusPartiallyShipped, 1)
+
+	// Cancelling some of the open units keeps the order partially shipped
+	got, err := service.CancelQuantity(ctx, order.ID, 1, customerRequest("changed their mind"))
+	if err != nil {
+		t.Fatalf("CancelQuantity: %v", err)
+	}
+	if got.CurrentStatus != model.OrderStatusPartiallyShipped || got.CancelledQuantity != 1 || got.OpenQuantity() != 2 {
+		t.Errorf("order is %s with %d cancelled and %d open, want PARTIALLY_SHIPPED with 1 and 2", got.CurrentStatus, got.CancelledQuantity, got.OpenQuantity())
+	}
+	if n := stock(t, order.ProductID); n != 11 {
+		t.Errorf("stock = %d, want the cancelled unit back for 11", n)
+	}
+
+	// Cancelling the rest leaves only shipped units, so the order has shipped
+	got, err = service.CancelQuantity(ctx, order.ID, 2, customerRequest(""))
+	if err != nil {
+		t.Fatalf("CancelQuantity: %v", err)
+	}
+	if got.CurrentStatus != model.OrderStatusShipped || got.CancelledQuantity != 3 || got.OpenQuantity() != 0 {
+		t.Errorf("order is %s with %d cancelled and %d open, want SHIPPED with 3 and 0", got.CurrentStatus, got.CancelledQuantity, got.OpenQuantity())
+	}
+	if n := stock(t, order.ProductID); n != 13 {
+		t.Errorf("stock = %d, want all 3 cancelled units back for 13", n)
+	}
+
+	// Each cancellation refunds the share of its units, which the captured payment covered
+	if len(payments.refunds) != 2 || payments.refunds[0] != usd(250) || payments.refunds[1] != usd(500) {
+		t.Errorf("refunds = %v, want 2.50 USD then 5.00 USD", payments.refunds)
+	}
+	if payments.cancelled {
+		t.Error("the payment was released, but the shipped unit is still paid for")
+	}
+
+	history, err := service.GetOrderHistory(ctx, order.ID, model.OrderHistoryFilter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(history) != 2 {
+		t.Fatalf("got %d history entries, want one per cancellation", len(history))
+	}
+	if h := history[0]; h.NewStatus != model.OrderStatusPartiallyShipped || h.Note != "Cancelled 1 of 4 units: changed their mind" {
+		t.Errorf("first entry moved to %s with note %q, want PARTIALLY_SHIPPED with the cancellation", h.NewStatus, h.Note)
+	}
+	if h := history[1]; h.PreviousStatus != model.OrderStatusPartiallyShipped || h.NewStatus != model.OrderStatusShipped {
+		t.Errorf("second entry moved from %s to %s, want PARTIALLY_SHIPPED to SHIPPED", h.PreviousStatus, h.NewStatus)
+	}
+}
+
+func TestCancelQuantityNothingShipped(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("some units", func(t *testing.T) {
+		service, payments, order := newCancelTest(t, model.OrderStatusPaid, 0)
+
+		got, err := service.CancelQuantity(ctx, order.ID, 3, customerRequest(""))
+		if err != nil {
+			t.Fatalf("CancelQuantity: %v", err)
+		}
+		if got.CurrentStatus != model.OrderStatusPaid || got.ActiveQuantity() != 1 {
+			t.Errorf("order is %s with %d active units, want PAID with 1", got.CurrentStatus, got.ActiveQuantity())
+		}
+		if n := stock(t, order.ProductID); n != 13 {
+			t.Errorf("stock = %d, want 13", n)
+		}
+		// Nothing was captured yet; the first shipment captures only the units left
+		if len(payments.refunds) != 0 || payments.cancelled {
+			t.Errorf("refunds = %v and released = %t, want neither before a capture", payments.refunds, payments.cancelled)
+		}
+	})
+
+	t.Run("every unit", func(t *testing.T) {
+		service, payments, order := newCancelTest(t, model.OrderStatusPaid, 0)
+
+		got, err := service.CancelQuantity(ctx, order.ID, 4, customerRequest(""))
+		if err != nil {
+			t.Fatalf("CancelQuantity: %v", err)
+		}
+		if got.CurrentStatus != model.OrderStatusCancelled {
+			t.Errorf("order is %s, want CANCELLED", got.CurrentStatus)
+		}
+		if n := stock(t, order.ProductID); n != 14 {
+			t.Errorf("stock = %d, want all 4 units back for 14", n)
+		}
+		if len(payments.refunds) != 0 || !payments.cancelled {
+			t.Errorf("refunds = %v and released = %t, want the payment released", payments.refunds, payments.cancelled)
+		}
+	})
+}
+
+func TestCancelQuantityRejected(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   model.OrderStatus
+		quantity int
+		reason   string
+		wantErr  error
+	}{
+		{"no units", model.OrderStatusPartiallyShipped, 0, string(model.CancellationCustomerRequest), services.ErrInvalidQuantity},
+		{"more units than are open", model.OrderStatusPartiallyShipped, 4, string(model.CancellationCustomerRequest), services.ErrInvalidQuantity},
+		{"no reason", model.OrderStatusPartiallyShipped, 1, "", services.ErrInvalidReason},
+		{"shipped order", model.OrderStatusShipped, 1, string(model.CancellationCustomerRequest), services.ErrInvalidTransition},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, payments, order := newCancelTest(t, tt.status, 1)
+
+			_, err := service.CancelQuantity(context.Background(), order.ID, tt.quantity, model.StatusChange{Actor: model.UserActor(1), Reason: tt.reason})
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("CancelQuantity error = %v, want %v", err, tt.wantErr)
+			}
+			if n := stock(t, order.ProductID); n != 10 || len(payments.refunds) != 0 {
+				t.Errorf("stock = %d and refunds = %v, want both unchanged", n, payments.refunds)
+			}
+		})
+	}
+}
+
+func TestCancelStaleOrders(t *testing.T) {
+	ctx := context.Background()
+	tests := []struct {
+		name          string
+		payment       model.PaymentStatus // Empty for an order placed without payment
+		wantCancelled bool
+	}{
+		{"payment pending", model.PaymentStatusPending, true},
+		{"payment failed", model.PaymentStatusFailed, true},
+		{"payment declined", model.PaymentStatusDeclined, true},
+		{"payment authorized", model.PaymentStatusAuthorized, false},
+		{"no payment", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, payments, order := newCancelTest(t, model.OrderStatusOrdered, 0)
+			payments.statuses = map[uuid.UUID]model.PaymentStatus{}
+			if tt.payment != "" {
+				payments.statuses[order.ID] = tt.payment
+			}
+
+			if _, err := service.CancelStaleOrders(ctx, time.Now().Add(time.Minute)); err != nil {
+				t.Fatalf("CancelStaleOrders: %v", err)
+			}
+			got, err := service.GetOrderByID(ctx, order.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if cancelled := got.CurrentStatus == model.OrderStatusCancelled; cancelled != tt.wantCancelled {
+				t.Errorf("order is %s, want cancelled = %t", got.CurrentStatus, tt.wantCancelled)
+			}
+		})
+	}
+}
+
+func TestCancelStaleOrdersWithoutPayments(t *testing.T) {
+	ctx := context.Background()
+	orderStore := &fake.OrderStoreFake{}
+	service := services.NewOrderService(orderStore, &fake.InventoryStoreFake{}, nil, nil, &fake.OrderStateLogStoreFake{},
+		fsm.NewValidator(), nil, nil, nil, nil, nil, nil)
+	order := &model.Order{ProductID: uuid.New(), Quantity: 1, CurrentStatus: model.OrderStatusOrdered, Total: usd(1000)}
+	if err := orderStore.Create(ctx, order); err != nil {
+		t.Fatal(err)
+	}
+
+	// Orders placed without payment ship from ORDERED; they are not waiting on anything
+	cancelled, err := service.CancelStaleOrders(ctx, time.Now().Add(time.Minute))
+	if err != nil || cancelled != 0 {
+		t.Errorf("CancelStaleOrders = %d, %v, want none cancelled", cancelled, err)
+	}
+}
+
+func TestUpdateOrderStatusConflict(t *testing.T) {
+	ctx := context.Background()
+	_, _, order := newCancelTest(t, model.OrderStatusPaid, 0)
+
+	// Another request ships the order after the cancellation read it as PAID
+	orderStore := &fake.OrderStoreFake{
+		UpdateStatusFunc: func(ctx context.Context, orderID uuid.UUID, previous, status model.OrderStatus) (bool, error) {
+			if _, err := (&fake.OrderStoreFake{}).UpdateStatus(ctx, orderID, previous, model.OrderStatusShipped); err != nil {
+				t.Fatal(err)
+			}
+			return (&fake.OrderStoreFake{}).UpdateStatus(ctx, orderID, previous, status)
+		},
+	}
+	service := services.NewOrderService(orderStore, &fake.InventoryStoreFake{}, nil, nil, &fake.OrderStateLogStoreFake{},
+		fsm.NewValidator(), nil, nil, nil, nil, nil, nil)
+
+	_, err := service.UpdateOrderStatus(ctx, order.ID, model.OrderStatusCancelled, customerRequest(""))
+	if !errors.Is(err, services.ErrInvalidTransition) {
+		t.Errorf("UpdateOrderStatus error = %v, want ErrInvalidTransition", err)
+	}
+	got, err := service.GetOrderByID(ctx, order.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.CurrentStatus != model.OrderStatusShipped {
+		t.Errorf("order is %s, want the SHIPPED status the other request set", got.CurrentStatus)
+	}
+	if n := stock(t, order.ProductID); n != 10 {
+		t.Errorf("stock = %d, want 10 with nothing restored", n)
+	}
+	history, _ := service.GetOrderHistory(ctx, order.ID, model.OrderHistoryFilter{})
+	if len(history) != 0 {
+		t.Errorf("got %d history entries, want none for the change that lost", len(history))
+	}
+}
+
+func TestShareOfAddsUpToTheTotal(t *testing.T) {
+	tests := []struct {
+		name     string
+		total    int64
+		quantity int
+		want     []int64 // The share of each unit, in order
+	}{
+		{"divides evenly", 1000, 4, []int64{250, 250, 250, 250}},
+		{"last unit takes the remainder", 1000, 3, []int64{333, 333, 334}},
+		{"remainder spread over units", 1001, 3, []int64{333, 334, 334}},
+		{"fewer cents than units", 2, 3, []int64{0, 1, 1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			order := &model.Order{Quantity: tt.quantity, Total: usd(tt.total)}
+			sum := usd(0)
+			for unit, want := range tt.want {
+				share, err := order.ShareOf(unit, 1)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if share != usd(want) {
+					t.Errorf("share of unit %d = %s, want %s", unit+1, share, usd(want))
+				}
+				if sum, err = sum.Add(share); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if sum != order.Total {
+				t.Errorf("shares add up to %s, want the total %s", sum, order.Total)
+			}
+
+			// Shares of several units at once match the units' shares added up
+			whole, err := order.ShareOf(0, tt.quantity)
+			if err != nil || whole != order.Total {
+				t.Errorf("share of every unit = %s, %v, want %s", whole, err, order.Total)
+			}
+		})
+	}
+}
@@ -0,0 +1,322 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"oms/server/core/model"
+	"oms/server/core/money"
+	"oms/server/core/types"
+)
+
+var (
+	// ErrPaymentDeclined is returned when the provider declines to authorize an order's payment
+	ErrPaymentDeclined = errors.New("payment declined")
+	// ErrPaymentFailed is returned when the provider could not be reached or refused an operation
+	ErrPaymentFailed = errors.New("payment failed")
+	// ErrPaymentNotAuthorized is returned when an order is shipped before its payment was authorized
+	ErrPaymentNotAuthorized = errors.New("payment not authorized")
+	// ErrPaymentNotFound is returned when a webhook refers to an unknown payment
+	ErrPaymentNotFound = errors.New("payment not found")
+	// ErrInvalidWebhook is returned for webhooks that fail verification or cannot be decoded
+	ErrInvalidWebhook = errors.New("invalid payment webhook")
+)
+
+// PaymentService defines the interface for taking payment for orders through a PaymentProvider.
+// An order's current payment is its most recent one; orders placed without payment have none.
+type PaymentService interface {
+	// Authorize asks the provider to hold the order total and records the payment. A decline returns
+	// ErrPaymentDeclined. When the provider times out the payment is recorded as pending and settled
+	// later by a webhook.
+	Authorize(ctx context.Context, order *model.Order, method string) (*model.Payment, error)
+	// Capture takes amount of the authorized funds of an order, all of them when amount is zero, and
+	// releases the rest. Orders without a payment have nothing to capture.
+	Capture(ctx context.Context, orderID uuid.UUID, amount money.Money) error
+	// Cancel voids an order's authorization, or refunds what was captured
+	Cancel(ctx context.Context, orderID uuid.UUID) error
+	// Refund returns part of an order's captured funds
+	Refund(ctx context.Context, orderID uuid.UUID, amount money.Money, reason string) (*model.Payment, error)
+	// HandleWebhook verifies a provider webhook and applies its event. It returns the payment when the
+	// event changed it, and nil when the event was redelivered or is out of date.
+	HandleWebhook(ctx context.Context, payload []byte, signature string) (*model.Payment, error)
+	GetPayments(ctx context.Context, orderID uuid.UUID) ([]*model.Payment, error)
+}
+
+// paymentService implements PaymentService
+type paymentService struct {
+	paymentStore types.PaymentStore
+	provider     types.PaymentProvider
+}
+
+// NewPaymentService creates a new PaymentService
+func NewPaymentService(paymentStore types.PaymentStore, provider types.PaymentProvider) PaymentService {
+	return &paymentService{
+		paymentStore: paymentStore,
+		provider:     provider,
+	}
+}
+
+// Authorize requests an authorization for the order total and records the outcome, including declines
+func (s *paymentService) Authorize(ctx context.Context, order *model.Order, method string) (*model.Payment, error) {
+	currency := order.Total.Currency
+	payment := &model.Payment{
+		ID:       uuid.New(),
+		OrderID:  order.ID,
+		Provider: s.provider.Name(),
+		Method:   method,
+		Status:   model.PaymentStatusPending,
+		Amount:   order.Total,
+		Captured: money.Zero(currency),
+		Refunded: money.Zero(currency),
+	}
+
+	result, err := s.provider.Authorize(ctx, model.PaymentRequest{
+		PaymentID: payment.ID,
+		OrderID:   order.ID,
+		Amount:    order.Total,
+		Method:    method,
+	})
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		// The outcome is unknown; the provider's webhook settles it
+	case err != nil:
+		payment.Status = model.PaymentStatusFailed
+		payment.FailureReason = truncate(err.Error(), 255)
+	default:
+		if result.Reference != "" {
+			payment.Reference = &result.Reference
+		}
+		payment.Status = result.Status
+		payment.FailureReason = truncate(result.Message, 255)
+	}
+
+	if createErr := s.paymentStore.Create(ctx, payment); createErr != nil {
+		// Release funds held for a payment we have no record of
+		if payment.Status == model.PaymentStatusAuthorized {
+			_, _ = s.provider.Void(ctx, payment)
+		}
+		return nil, fmt.Errorf("failed to save payment: %w", createErr)
+	}
+
+	switch payment.Status {
+	case model.PaymentStatusPending, model.PaymentStatusAuthorized:
+		return payment, nil
+	case model.PaymentStatusDeclined:
+		return payment, fmt.Errorf("%w: %s", ErrPaymentDeclined, payment.FailureReason)
+	default:
+		return payment, fmt.Errorf("%w: %s", ErrPaymentFailed, payment.FailureReason)
+	}
+}
+
+// Capture captures amount, or the full authorized amount, of the order's payment
+func (s *paymentService) Capture(ctx context.Context, orderID uuid.UUID, amount money.Money) error {
+	payment, err := s.current(ctx, orderID)
+	if err != nil || payment == nil {
+		return err
+	}
+	switch payment.Status {
+	case model.PaymentStatusAuthorized:
+	case model.PaymentStatusCaptured, model.PaymentStatusPartiallyRefunded, model.PaymentStatusRefunded:
+		return nil
+	default:
+		return fmt.Errorf("%w: payment is %s", ErrPaymentNotAuthorized, payment.Status)
+	}
+
+	if amount.IsZero() {
+		amount = payment.Amount
+	}
+	result, err := s.provider.Capture(ctx, payment, amount)
+	if err != nil {
+		return fmt.Errorf("%w: capture: %v", ErrPaymentFailed, err)
+	}
+	if err := s.apply(ctx, payment, result, amount); err != nil {
+		return err
+	}
+	if payment.Status != model.PaymentStatusCaptured {
+		return fmt.Errorf("%w: capture %s: %s", ErrPaymentFailed, payment.Status, payment.FailureReason)
+	}
+	return nil
+}
+
+// Cancel voids a pending or authorized payment and refunds the rest of a captured one
+func (s *paymentService) Cancel(ctx context.Context, orderID uuid.UUID) error {
+	payment, err := s.current(ctx, orderID)
+	if err != nil || payment == nil {
+		return err
+	}
+
+	switch payment.Status {
+	case model.PaymentStatusPending, model.PaymentStatusAuthorized:
+		result, err := s.provider.Void(ctx, payment)
+		if err != nil {
+			return fmt.Errorf("%w: void: %v", ErrPaymentFailed, err)
+		}
+		return s.apply(ctx, payment, result, payment.Amount)
+	case model.PaymentStatusCaptured, model.PaymentStatusPartiallyRefunded:
+		remaining, err := payment.Captured.Sub(payment.Refunded)
+		if err != nil {
+			return err
+		}
+		_, err = s.refund(ctx, payment, remaining, "order cancelled")
+		return err
+	}
+	// Declined, failed, voided and refunded payments hold no funds
+	return nil
+}
+
+// Refund refunds part of the captured amount of the order's payment
+func (s *paymentService) Refund(ctx context.Context, orderID uuid.UUID, amount money.Money, reason string) (*model.Payment, error) {
+	payment, err := s.current(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	if payment == nil {
+		return nil, fmt.Errorf("%w: order %s has no payment", ErrPaymentNotFound, orderID)
+	}
+	if payment.Status != model.PaymentStatusCaptured && payment.Status != model.PaymentStatusPartiallyRefunded {
+		return nil, fmt.Errorf("%w: cannot refund a %s payment", ErrPaymentFailed, payment.Status)
+	}
+	return s.refund(ctx, payment, amount, reason)
+}
+
+// refund asks the provider to refund amount and records the result
+func (s *paymentService) refund(ctx context.Context, payment *model.Payment, amount money.Money, reason string) (*model.Payment, error) {
+	if amount.IsZero() {
+		return payment, nil
+	}
+	if amount.IsNegative() {
+		return nil, fmt.Errorf("%w: refund amount cannot be negative", ErrPaymentFailed)
+	}
+	result, err := s.provider.Refund(ctx, payment, amount)
+	if err != nil {
+		return nil, fmt.Errorf("%w: refund: %v", ErrPaymentFailed, err)
+	}
+	if result.Message == "" {
+		result.Message = reason
+	}
+	if err := s.apply(ctx, payment, result, amount); err != nil {
+		return nil, err
+	}
+	return payment, nil
+}
+
+// HandleWebhook verifies the webhook, finds its payment and records the reported status
+func (s *paymentService) HandleWebhook(ctx context.Context, payload []byte, signature string) (*model.Payment, error) {
+	event, err := s.provider.ParseWebhook(payload, signature)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidWebhook, err)
+	}
+
+	var payment *model.Payment
+	if event.PaymentID != uuid.Nil {
+		payment, err = s.paymentStore.GetByID(ctx, event.PaymentID)
+	} else {
+		payment, err = s.paymentStore.GetByReference(ctx, s.provider.Name(), event.Reference)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrPaymentNotFound, err)
+	}
+	if payment.Reference == nil && event.Reference != "" {
+		payment.Reference = &event.Reference
+	}
+
+	applied, err := s.record(ctx, payment, event.Status, event.Amount, event.Message, &event.ID)
+	if err != nil || !applied {
+		return nil, err
+	}
+	return payment, nil
+}
+
+// GetPayments retrieves an order's payments with their history, newest first
+func (s *paymentService) GetPayments(ctx context.Context, orderID uuid.UUID) ([]*model.Payment, error) {
+	return s.paymentStore.GetByOrderID(ctx, orderID)
+}
+
+// current returns the order's most recent payment, or nil when it has none
+func (s *paymentService) current(ctx context.Context, orderID uuid.UUID) (*model.Payment, error) {
+	payments, err := s.paymentStore.GetByOrderID(ctx, orderID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load payments: %w", err)
+	}
+	if len(payments) == 0 {
+		return nil, nil
+	}
+	return payments[0], nil
+}
+
+// apply records the result of an operation we started. Unlike webhook events, the change must
+// apply: the provider has already acted on it.
+func (s *paymentService) apply(ctx context.Context, payment *model.Payment, result model.PaymentResult, amount money.Money) error {
+	if payment.Reference == nil && result.Reference != "" {
+		payment.Reference = &result.Reference
+	}
+	previous := payment.Status
+	applied, err := s.record(ctx, payment, result.Status, amount, result.Message, nil)
+	if err != nil {
+		return err
+	}
+	if !applied {
+		return fmt.Errorf("%w: payment %s changed from %s while moving to %s", ErrPaymentFailed, payment.ID, previous, result.Status)
+	}
+	return nil
+}
+
+// record moves the payment to status, updating its captured or refunded amount, and appends the
+// change to its history. A zero amount stands for the whole authorized, or remaining captured, amount.
+// It reports false when the payment cannot make that change or changed concurrently.
+func (s *paymentService) record(ctx context.Context, payment *model.Payment, status model.PaymentStatus, amount money.Money, message string, eventID *string) (bool, error) {
+	previous := payment.Status
+	if !previous.CanBecome(status) {
+		return false, nil
+	}
+
+	var err error
+	switch status {
+	case model.PaymentStatusPartiallyRefunded, model.PaymentStatusRefunded:
+		if amount.IsZero() {
+			if amount, err = payment.Captured.Sub(payment.Refunded); err != nil {
+				return false, err
+			}
+		}
+		if payment.Refunded, err = payment.Refunded.Add(amount); err != nil {
+			return false, err
+		}
+	default:
+		if amount.IsZero() {
+			amount = payment.Amount
+		}
+	}
+	switch status {
+	case model.PaymentStatusCaptured:
+		payment.Captured = amount
+	case model.PaymentStatusDeclined, model.PaymentStatusFailed:
+		payment.FailureReason = truncate(message, 255)
+	}
+	payment.Status = status
+
+	entry := &model.PaymentStateLog{
+		PreviousStatus: previous,
+		NewStatus:      status,
+		Amount:         amount,
+		EventID:        eventID,
+		Message:        truncate(message, 255),
+	}
+	applied, err := s.paymentStore.UpdateStatus(ctx, payment, entry)
+	if err != nil {
+		return false, fmt.Errorf("failed to save payment: %w", err)
+	}
+	if applied {
+		payment.History = append(payment.History, *entry)
+	}
+	return applied, nil
+}
+
+// truncate shortens s to at most n bytes to fit its column
+func truncate(s string, n int) string {
+	if len(s) > n {
+		return s[:n]
+	}
+	return s
+}
@@ -2,39 +2,210 @@ package services
 
 import (
 	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
 
 	"github.com/google/uuid"
 	"oms/server/core/model"
 	"oms/server/core/types"
 )
 
+const (
+	// DefaultProductPageSize is used when a catalog query does not set a limit
+	DefaultProductPageSize = 50
+	// MaxProductPageSize caps the number of products returned per page
+	MaxProductPageSize = 200
+)
+
+// ErrInvalidProductQuery is returned when a catalog query cannot be executed as given
+var ErrInvalidProductQuery = errors.New("invalid product query")
+
 // ProductService defines the interface for product business logic
 type ProductService interface {
 	GetAll(ctx context.Context) ([]*model.Product, error)
 	GetByID(ctx context.Context, productID uuid.UUID) (*model.Product, error)
+	GetWithStock(ctx context.Context, productID uuid.UUID) (*model.ProductWithStock, error)
+	Search(ctx context.Context, query model.ProductQuery) (*model.ProductPage, error)
 }
 
 // productService implements ProductService
 type productService struct {
-	productStore types.ProductStore
+	productStore  types.ProductStore
+	variantStore  types.ProductVariantStore
+	categoryStore types.CategoryStore
 }
 
-// NewProductService creates a new ProductService
-func NewProductService(productStore types.ProductStore) ProductService {
+// NewProductService creates a new ProductService.
+// variantStore and categoryStore may be nil, which disables variants and category filtering.
+func NewProductService(productStore types.ProductStore, variantStore types.ProductVariantStore, categoryStore types.CategoryStore) ProductService {
 	return &productService{
-		productStore: productStore,
+		productStore:  productStore,
+		variantStore:  variantStore,
+		categoryStore: categoryStore,
 	}
 }
 
 // GetAll retrieves all products
 func (s *productService) GetAll(ctx context.Context) ([]*model.Product, error) {
-	// TODO: Call productStore.GetAll(ctx)
-	return nil, nil // Placeholder
+	return s.productStore.GetAll(ctx)
 }
 
 // GetByID retrieves a product by ID
 func (s *productService) GetByID(ctx context.Context, productID uuid.UUID) (*model.Product, error) {
-	// TODO: Call productStore.GetByID(ctx, productID)
-	return nil, nil // Placeholder
+	return s.productStore.GetByID(ctx, productID)
+}
+
+// GetWithStock retrieves a product together with its inventory quantity and variants
+func (s *productService) GetWithStock(ctx context.Context, productID uuid.UUID) (*model.ProductWithStock, error) {
+	product, err := s.productStore.GetWithStock(ctx, productID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.attachVariants(ctx, []*model.ProductWithStock{product}); err != nil {
+		return nil, err
+	}
+	return product, nil
+}
+
+// Search retrieves one page of products matching the query
+// Pagination is keyset-based: the cursor encodes the sort value and ID of the last row
+func (s *productService) Search(ctx context.Context, query model.ProductQuery) (*model.ProductPage, error) {
+	if query.SortBy == "" {
+		query.SortBy = model.ProductSortCreatedAt
+		query.SortDesc = true
+	}
+	switch query.SortBy {
+	case model.ProductSortCreatedAt, model.ProductSortName, model.ProductSortPrice, model.ProductSortStock:
+	default:
+		return nil, fmt.Errorf("%w: unsupported sort field %q", ErrInvalidProductQuery, query.SortBy)
+	}
+	if query.MinPrice != nil && query.MaxPrice != nil {
+		cmp, err := query.MinPrice.Cmp(*query.MaxPrice)
+		if err != nil {
+			return nil, fmt.Errorf("%w: min_price and max_price: %v", ErrInvalidProductQuery, err)
+		}
+		if cmp > 0 {
+			return nil, fmt.Errorf("%w: min_price cannot be greater than max_price", ErrInvalidProductQuery)
+		}
+	}
+	switch query.StockStatus {
+	case "", model.StockStatusInStock, model.StockStatusOutOfStock:
+	default:
+		return nil, fmt.Errorf("%w: unsupported stock status %q", ErrInvalidProductQuery, query.StockStatus)
+	}
+
+	if query.Category != "" {
+		if s.categoryStore == nil {
+			return nil, fmt.Errorf("%w: category filtering is not available", ErrInvalidProductQuery)
+		}
+		categoryIDs, err := resolveCategoryWithDescendants(ctx, s.categoryStore, query.Category)
+		if err != nil {
+			if errors.Is(err, ErrCategoryNotFound) {
+				return nil, fmt.Errorf("%w: unknown category %q", ErrInvalidProductQuery, query.Category)
+			}
+			return nil, err
+		}
+		query.CategoryIDs = categoryIDs
+	}
+
+	if query.Limit <= 0 {
+		query.Limit = DefaultProductPageSize
+	}
+	if query.Limit > MaxProductPageSize {
+		query.Limit = MaxProductPageSize
+	}
+
+	var after *model.ProductCursor
+	if query.Cursor != "" {
+		cursor, err := decodeProductCursor(query.Cursor)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidProductQuery, err)
+		}
+		if cursor.SortBy != query.SortBy || cursor.Desc != query.SortDesc {
+			return nil, fmt.Errorf("%w: cursor was issued for a different sort order", ErrInvalidProductQuery)
+		}
+		after = cursor
+	}
+
+	// Fetch one extra row to know whether another page follows
+	limit := query.Limit
+	query.Limit = limit + 1
+	products, err := s.productStore.Search(ctx, query, after)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search products: %w", err)
+	}
+
+	page := &model.ProductPage{Products: products}
+	if len(products) > limit {
+		page.Products = products[:limit]
+		page.NextCursor = encodeProductCursor(query.SortBy, query.SortDesc, page.Products[limit-1])
+	}
+	if err := s.attachVariants(ctx, page.Products); err != nil {
+		return nil, err
+	}
+	return page, nil
+}
+
+// attachVariants loads the variants of products with option axes in a single query
+func (s *productService) attachVariants(ctx context.Context, products []*model.ProductWithStock) error {
+	if s.variantStore == nil {
+		return nil
+	}
+
+	byID := map[uuid.UUID]*model.ProductWithStock{}
+	var productIDs []uuid.UUID
+	for _, product := range products {
+		if len(product.Options) > 0 {
+			byID[product.ID] = product
+			productIDs = append(productIDs, product.ID)
+		}
+	}
+	if len(productIDs) == 0 {
+		return nil
+	}
+
+	variants, err := s.variantStore.GetWithStockByProductIDs(ctx, productIDs)
+	if err != nil {
+		return fmt.Errorf("failed to load variants: %w", err)
+	}
+	for _, variant := range variants {
+		if product, ok := byID[variant.ProductID]; ok {
+			product.Variants = append(product.Variants, variant)
+		}
+	}
+	return nil
+}
+
+// encodeProductCursor builds the opaque cursor pointing after product
+func encodeProductCursor(sortBy model.ProductSortField, desc bool, product *model.ProductWithStock) string {
+	cursor := model.ProductCursor{SortBy: sortBy, Desc: desc, ID: product.ID}
+	switch sortBy {
+	case model.ProductSortName:
+		cursor.Value = product.Name
+	case model.ProductSortPrice:
+		cursor.Value = strconv.FormatInt(product.Price.Amount, 10)
+	case model.ProductSortStock:
+		cursor.Value = strconv.Itoa(product.Quantity)
+	default:
+		cursor.Value = product.CreatedAt.Format(time.RFC3339Nano)
+	}
+	data, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(data)
 }
 
+// decodeProductCursor parses a cursor produced by encodeProductCursor
+func decodeProductCursor(encoded string) (*model.ProductCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, errors.New("malformed cursor")
+	}
+	var cursor model.ProductCursor
+	if err := json.Unmarshal(data, &cursor); err != nil || cursor.ID == uuid.Nil {
+		return nil, errors.New("malformed cursor")
+	}
+	return &cursor, nil
+}
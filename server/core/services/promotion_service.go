@@ -0,0 +1,386 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"oms/server/core/model"
+	"oms/server/core/money"
+	"oms/server/core/types"
+)
+
+var (
+	// ErrInvalidPromotion is returned when a promotion definition cannot be saved
+	ErrInvalidPromotion = errors.New("invalid promotion")
+	// ErrPromotionNotFound is returned when a referenced promotion does not exist
+	ErrPromotionNotFound = errors.New("promotion not found")
+	// ErrInvalidCoupon is returned when a coupon code does not exist or does not apply to the order
+	ErrInvalidCoupon = errors.New("invalid coupon")
+	// ErrPromotionUnavailable is returned when a promotion reaches its usage limit while the order is placed
+	ErrPromotionUnavailable = errors.New("promotion unavailable")
+)
+
+// PromotionService defines the interface for promotion management and evaluation
+type PromotionService interface {
+	ListPromotions(ctx context.Context) ([]*model.Promotion, error)
+	CreatePromotion(ctx context.Context, promotion *model.Promotion) error
+	UpdatePromotion(ctx context.Context, promotion *model.Promotion) error
+	DeletePromotion(ctx context.Context, promotionID uuid.UUID) error
+
+	// ApplyPromotions evaluates the automatic promotions and couponCodes against a priced order,
+	// records a discount line per applied promotion and recomputes the totals. Promotions are
+	// evaluated in ascending priority, then creation time, so the result does not depend on the
+	// order the codes are given in. Each discount applies to what earlier ones left over.
+	// A coupon that does not apply fails with ErrInvalidCoupon; automatic promotions that do not
+	// apply are skipped.
+	ApplyPromotions(ctx context.Context, order *model.Order, couponCodes []string) error
+	// Redeem counts the order's discount lines against their promotions' limits atomically.
+	// It returns ErrPromotionUnavailable, and redeems nothing, if any limit has been reached since pricing.
+	Redeem(ctx context.Context, order *model.Order) error
+	// Release gives back the redemptions of an order that was not placed or was cancelled
+	Release(ctx context.Context, orderID uuid.UUID) error
+}
+
+// promotionService implements PromotionService
+type promotionService struct {
+	promotionStore types.PromotionStore
+	categoryStore  types.CategoryStore
+}
+
+// NewPromotionService creates a new PromotionService.
+// categoryStore may be nil, in which case category-scoped promotions never apply.
+func NewPromotionService(promotionStore types.PromotionStore, categoryStore types.CategoryStore) PromotionService {
+	return &promotionService{
+		promotionStore: promotionStore,
+		categoryStore:  categoryStore,
+	}
+}
+
+// ListPromotions retrieves all promotions in evaluation order
+func (s *promotionService) ListPromotions(ctx context.Context) ([]*model.Promotion, error) {
+	return s.promotionStore.GetAll(ctx)
+}
+
+// CreatePromotion validates and saves a new promotion
+func (s *promotionService) CreatePromotion(ctx context.Context, promotion *model.Promotion) error {
+	if err := s.checkDefinition(ctx, promotion); err != nil {
+		return err
+	}
+	if err := s.promotionStore.Create(ctx, promotion); err != nil {
+		return fmt.Errorf("failed to create promotion: %w", err)
+	}
+	return nil
+}
+
+// UpdatePromotion validates and replaces a promotion's rule, keeping its redemption count
+func (s *promotionService) UpdatePromotion(ctx context.Context, promotion *model.Promotion) error {
+	existing, err := s.promotionStore.GetByID(ctx, promotion.ID)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrPromotionNotFound, promotion.ID)
+	}
+	promotion.CreatedAt = existing.CreatedAt
+	promotion.Redemptions = existing.Redemptions
+	if err := s.checkDefinition(ctx, promotion); err != nil {
+		return err
+	}
+	if err := s.promotionStore.Update(ctx, promotion); err != nil {
+		return fmt.Errorf("failed to update promotion: %w", err)
+	}
+	return nil
+}
+
+// DeletePromotion removes a promotion; orders keep the discount lines it granted
+func (s *promotionService) DeletePromotion(ctx context.Context, promotionID uuid.UUID) error {
+	if _, err := s.promotionStore.GetByID(ctx, promotionID); err != nil {
+		return fmt.Errorf("%w: %s", ErrPromotionNotFound, promotionID)
+	}
+	return s.promotionStore.Delete(ctx, promotionID)
+}
+
+// checkDefinition normalizes the coupon code and checks that the fields the promotion type uses are set
+func (s *promotionService) checkDefinition(ctx context.Context, promotion *model.Promotion) error {
+	promotion.Name = strings.TrimSpace(promotion.Name)
+	if promotion.Name == "" {
+		return fmt.Errorf("%w: name is required", ErrInvalidPromotion)
+	}
+
+	if promotion.Code != nil {
+		code := normalizeCouponCode(*promotion.Code)
+		if code == "" {
+			promotion.Code = nil
+		} else {
+			promotion.Code = &code
+			if other, err := s.promotionStore.GetByCode(ctx, code); err == nil && other.ID != promotion.ID {
+				return fmt.Errorf("%w: code %s is already in use", ErrInvalidPromotion, code)
+			}
+		}
+	}
+
+	switch promotion.Type {
+	case model.PromotionTypePercentage:
+		percent, ok := new(big.Rat).SetString(strings.TrimSpace(promotion.PercentOff))
+		if !ok || percent.Sign() <= 0 || percent.Cmp(big.NewRat(100, 1)) > 0 {
+			return fmt.Errorf("%w: percent_off must be greater than 0 and at most 100", ErrInvalidPromotion)
+		}
+	case model.PromotionTypeFixedAmount:
+		if promotion.AmountOff.Currency == "" || promotion.AmountOff.IsZero() || promotion.AmountOff.IsNegative() {
+			return fmt.Errorf("%w: amount_off must be a positive amount", ErrInvalidPromotion)
+		}
+	case model.PromotionTypeBuyXGetY:
+		if promotion.BuyQuantity < 1 || promotion.GetQuantity < 1 {
+			return fmt.Errorf("%w: buy_quantity and get_quantity must be at least 1", ErrInvalidPromotion)
+		}
+	case model.PromotionTypeFreeShipping:
+	default:
+		return fmt.Errorf("%w: unknown type %q", ErrInvalidPromotion, promotion.Type)
+	}
+
+	if promotion.MinSubtotal.IsNegative() {
+		return fmt.Errorf("%w: min_subtotal must not be negative", ErrInvalidPromotion)
+	}
+	if promotion.Type == model.PromotionTypeFixedAmount && !promotion.MinSubtotal.IsZero() &&
+		promotion.MinSubtotal.Currency != promotion.AmountOff.Currency {
+		return fmt.Errorf("%w: min_subtotal and amount_off must be in the same currency", ErrInvalidPromotion)
+	}
+	if promotion.UsageLimit < 0 || promotion.PerUserLimit < 0 {
+		return fmt.Errorf("%w: usage limits must not be negative", ErrInvalidPromotion)
+	}
+	if promotion.StartsAt != nil && promotion.EndsAt != nil && !promotion.EndsAt.After(*promotion.StartsAt) {
+		return fmt.Errorf("%w: ends_at must be after starts_at", ErrInvalidPromotion)
+	}
+	return nil
+}
+
+// ApplyPromotions evaluates promotions against the order and records its discount lines
+func (s *promotionService) ApplyPromotions(ctx context.Context, order *model.Order, couponCodes []string) error {
+	now := time.Now()
+
+	automatic, err := s.promotionStore.GetAutomatic(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to load promotions: %w", err)
+	}
+	var candidates []*model.Promotion
+	for _, promotion := range automatic {
+		if promotion.ActiveAt(now) && !limitReached(promotion) {
+			candidates = append(candidates, promotion)
+		}
+	}
+	coupons, err := s.loadCoupons(ctx, order.UserID, couponCodes, now)
+	if err != nil {
+		return err
+	}
+	candidates = append(candidates, coupons...)
+	sort.SliceStable(candidates, func(i, j int) bool {
+		a, b := candidates[i], candidates[j]
+		if a.Priority != b.Priority {
+			return a.Priority < b.Priority
+		}
+		if !a.CreatedAt.Equal(b.CreatedAt) {
+			return a.CreatedAt.Before(b.CreatedAt)
+		}
+		return a.ID.String() < b.ID.String()
+	})
+
+	var scope map[uuid.UUID]bool
+	merchandise, shipping := order.Subtotal, order.Shipping
+	discounts := []model.OrderDiscount{}
+	for _, promotion := range candidates {
+		if len(promotion.CategoryIDs) > 0 && scope == nil {
+			if scope, err = productCategoryScope(ctx, s.categoryStore, order.ProductID); err != nil {
+				return err
+			}
+		}
+
+		amount, reason := evaluatePromotion(promotion, order, merchandise, shipping, scope)
+		if reason != "" {
+			if !promotion.IsAutomatic() {
+				return fmt.Errorf("%w: %s %s", ErrInvalidCoupon, *promotion.Code, reason)
+			}
+			continue
+		}
+		if amount.IsZero() && promotion.IsAutomatic() {
+			continue
+		}
+
+		line := model.OrderDiscount{
+			OrderID:      order.ID,
+			PromotionID:  promotion.ID,
+			Description:  promotion.Name,
+			Amount:       amount,
+			FreeShipping: promotion.Type == model.PromotionTypeFreeShipping,
+		}
+		if promotion.Code != nil {
+			line.Code = *promotion.Code
+		}
+		if line.FreeShipping {
+			shipping, err = shipping.Sub(amount)
+		} else {
+			merchandise, err = merchandise.Sub(amount)
+		}
+		if err != nil {
+			return fmt.Errorf("promotion %s: %w", promotion.Name, err)
+		}
+		discounts = append(discounts, line)
+	}
+
+	total := money.Zero(order.UnitPrice.Currency)
+	for _, line := range discounts {
+		if total, err = total.Add(line.Amount); err != nil {
+			return fmt.Errorf("order discount: %w", err)
+		}
+	}
+	order.Discounts = discounts
+	order.Discount = total
+	return order.CalculateTotals()
+}
+
+// loadCoupons looks up each distinct code and checks that it can be used by userID at now
+func (s *promotionService) loadCoupons(ctx context.Context, userID int, couponCodes []string, now time.Time) ([]*model.Promotion, error) {
+	seen := map[string]bool{}
+	var coupons []*model.Promotion
+	for _, raw := range couponCodes {
+		code := normalizeCouponCode(raw)
+		if code == "" || seen[code] {
+			continue
+		}
+		seen[code] = true
+
+		promotion, err := s.promotionStore.GetByCode(ctx, code)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s does not exist", ErrInvalidCoupon, code)
+		}
+		if !promotion.ActiveAt(now) {
+			return nil, fmt.Errorf("%w: %s is not active", ErrInvalidCoupon, code)
+		}
+		if limitReached(promotion) {
+			return nil, fmt.Errorf("%w: %s has reached its usage limit", ErrInvalidCoupon, code)
+		}
+		if promotion.PerUserLimit > 0 && userID > 0 {
+			used, err := s.promotionStore.CountUserRedemptions(ctx, promotion.ID, userID)
+			if err != nil {
+				return nil, fmt.Errorf("failed to count redemptions: %w", err)
+			}
+			if used >= promotion.PerUserLimit {
+				return nil, fmt.Errorf("%w: %s has already been used the maximum number of times", ErrInvalidCoupon, code)
+			}
+		}
+		coupons = append(coupons, promotion)
+	}
+	return coupons, nil
+}
+
+// Redeem records a redemption for each promotion on the order
+func (s *promotionService) Redeem(ctx context.Context, order *model.Order) error {
+	seen := map[uuid.UUID]bool{}
+	var redemptions []*model.PromotionRedemption
+	for _, line := range order.Discounts {
+		if seen[line.PromotionID] {
+			continue
+		}
+		seen[line.PromotionID] = true
+		redemptions = append(redemptions, &model.PromotionRedemption{
+			PromotionID: line.PromotionID,
+			OrderID:     order.ID,
+			UserID:      order.UserID,
+		})
+	}
+	if len(redemptions) == 0 {
+		return nil
+	}
+
+	exhausted, err := s.promotionStore.Redeem(ctx, redemptions)
+	if err != nil {
+		return fmt.Errorf("failed to redeem promotions: %w", err)
+	}
+	if exhausted != nil {
+		return fmt.Errorf("%w: %s has reached its usage limit", ErrPromotionUnavailable, exhausted.Name)
+	}
+	return nil
+}
+
+// Release gives back an order's redemptions
+func (s *promotionService) Release(ctx context.Context, orderID uuid.UUID) error {
+	return s.promotionStore.ReleaseRedemptions(ctx, orderID)
+}
+
+// evaluatePromotion returns the discount a promotion grants given the merchandise and shipping
+// amounts still undiscounted, or the reason it does not apply to the order
+func evaluatePromotion(promotion *model.Promotion, order *model.Order, merchandise, shipping money.Money, scope map[uuid.UUID]bool) (money.Money, string) {
+	zero := money.Zero(order.UnitPrice.Currency)
+	if !promotionCovers(promotion, order.ProductID, scope) {
+		return zero, "does not apply to this product"
+	}
+	if !promotion.MinSubtotal.IsZero() {
+		cmp, err := order.Subtotal.Cmp(promotion.MinSubtotal)
+		if err != nil {
+			return zero, "is not valid in " + order.UnitPrice.Currency
+		}
+		if cmp < 0 {
+			return zero, "requires a subtotal of at least " + promotion.MinSubtotal.String()
+		}
+	}
+
+	var amount money.Money
+	switch promotion.Type {
+	case model.PromotionTypePercentage:
+		percent, ok := new(big.Rat).SetString(strings.TrimSpace(promotion.PercentOff))
+		if !ok {
+			return zero, "has an invalid percentage"
+		}
+		amount = merchandise.MulRat(percent.Quo(percent, big.NewRat(100, 1)), money.RoundHalfUp)
+	case model.PromotionTypeFixedAmount:
+		if promotion.AmountOff.Currency != order.UnitPrice.Currency {
+			return zero, "is not valid in " + order.UnitPrice.Currency
+		}
+		amount = promotion.AmountOff
+	case model.PromotionTypeBuyXGetY:
+		free := order.Quantity / (promotion.BuyQuantity + promotion.GetQuantity) * promotion.GetQuantity
+		if free == 0 {
+			return zero, fmt.Sprintf("requires at least %d items", promotion.BuyQuantity+promotion.GetQuantity)
+		}
+		amount = order.UnitPrice.Mul(int64(free))
+	case model.PromotionTypeFreeShipping:
+		return shipping, ""
+	default:
+		return zero, "has an unknown type"
+	}
+
+	// A discount never takes the merchandise below zero
+	if cmp, err := amount.Cmp(merchandise); err == nil && cmp > 0 {
+		amount = merchandise
+	}
+	return amount, ""
+}
+
+// promotionCovers reports whether a promotion's product and category restrictions include the product.
+// scope is the product's categories and their ancestors.
+func promotionCovers(promotion *model.Promotion, productID uuid.UUID, scope map[uuid.UUID]bool) bool {
+	if len(promotion.ProductIDs) == 0 && len(promotion.CategoryIDs) == 0 {
+		return true
+	}
+	if promotion.ProductIDs.Contains(productID) {
+		return true
+	}
+	for _, categoryID := range promotion.CategoryIDs {
+		if scope[categoryID] {
+			return true
+		}
+	}
+	return false
+}
+
+// limitReached reports whether a promotion has used up its total redemptions
+func limitReached(promotion *model.Promotion) bool {
+	return promotion.UsageLimit > 0 && promotion.Redemptions >= promotion.UsageLimit
+}
+
+// normalizeCouponCode trims and upper-cases a coupon code so lookups are case-insensitive
+func normalizeCouponCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
@@ -0,0 +1,216 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"oms/server/core/model"
+	"oms/server/core/money"
+	"oms/server/core/types"
+)
+
+func usd(amount int64) money.Money { return money.New(amount, "USD") }
+
+func TestEvaluatePromotion(t *testing.T) {
+	productID, categoryID := uuid.New(), uuid.New()
+	// An order of 3 units at 10.00 USD
+	order := &model.Order{ProductID: productID, Quantity: 3, UnitPrice: usd(1000), Subtotal: usd(3000)}
+
+	tests := []struct {
+		name        string
+		promotion   model.Promotion
+		order       *model.Order // Defaults to order
+		merchandise money.Money  // Defaults to the order's subtotal
+		scope       map[uuid.UUID]bool
+		want        money.Money
+		wantReason  string
+	}{
+		{
+			name:      "percentage of the merchandise",
+			promotion: model.Promotion{Type: model.PromotionTypePercentage, PercentOff: "10"},
+			want:      usd(300),
+		},
+		{
+			name:        "percentage of what earlier promotions left",
+			promotion:   model.Promotion{Type: model.PromotionTypePercentage, PercentOff: "10"},
+			merchandise: usd(2500),
+			want:        usd(250),
+		},
+		{
+			name:        "percentage rounds half up",
+			promotion:   model.Promotion{Type: model.PromotionTypePercentage, PercentOff: "12.5"},
+			merchandise: usd(999), // 124.875 cents
+			want:        usd(125),
+		},
+		{
+			name:        "percentage rounds down below half",
+			promotion:   model.Promotion{Type: model.PromotionTypePercentage, PercentOff: "10"},
+			merchandise: usd(1234), // 123.4 cents
+			want:        usd(123),
+		},
+		{
+			name:        "percentage of a single cent",
+			promotion:   model.Promotion{Type: model.PromotionTypePercentage, PercentOff: "50"},
+			merchandise: usd(1),
+			want:        usd(1),
+		},
+		{
+			name:       "invalid percentage",
+			promotion:  model.Promotion{Type: model.PromotionTypePercentage, PercentOff: "ten"},
+			want:       usd(0),
+			wantReason: "has an invalid percentage",
+		},
+		{
+			name:      "fixed amount",
+			promotion: model.Promotion{Type: model.PromotionTypeFixedAmount, AmountOff: usd(500)},
+			want:      usd(500),
+		},
+		{
+			name:        "fixed amount capped at the merchandise",
+			promotion:   model.Promotion{Type: model.PromotionTypeFixedAmount, AmountOff: usd(5000)},
+			merchandise: usd(1200),
+			want:        usd(1200),
+		},
+		{
+			name:       "fixed amount in another currency",
+			promotion:  model.Promotion{Type: model.PromotionTypeFixedAmount, AmountOff: money.New(500, "EUR")},
+			want:       usd(0),
+			wantReason: "is not valid in USD",
+		},
+		{
+			name:      "minimum subtotal met exactly",
+			promotion: model.Promotion{Type: model.PromotionTypeFixedAmount, AmountOff: usd(500), MinSubtotal: usd(3000)},
+			want:      usd(500),
+		},
+		{
+			name:       "minimum subtotal not met",
+			promotion:  model.Promotion{Type: model.PromotionTypeFixedAmount, AmountOff: usd(500), MinSubtotal: usd(3001)},
+			want:       usd(0),
+			wantReason: "requires a subtotal of at least 30.01 USD",
+		},
+		{
+			name:       "minimum subtotal in another currency",
+			promotion:  model.Promotion{Type: model.PromotionTypePercentage, PercentOff: "10", MinSubtotal: money.New(1000, "EUR")},
+			want:       usd(0),
+			wantReason: "is not valid in USD",
+		},
+		{
+			name:      "buy two get one",
+			promotion: model.Promotion{Type: model.PromotionTypeBuyXGetY, BuyQuantity: 2, GetQuantity: 1},
+			order:     &model.Order{ProductID: productID, Quantity: 7, UnitPrice: usd(1000), Subtotal: usd(7000)},
+			want:      usd(2000),
+		},
+		{
+			name:       "buy two get one with too few units",
+			promotion:  model.Promotion{Type: model.PromotionTypeBuyXGetY, BuyQuantity: 2, GetQuantity: 2},
+			want:       usd(0),
+			wantReason: "requires at least 4 items",
+		},
+		{
+			name:      "free shipping",
+			promotion: model.Promotion{Type: model.PromotionTypeFreeShipping},
+			want:      usd(799),
+		},
+		{
+			name:       "other products only",
+			promotion:  model.Promotion{Type: model.PromotionTypePercentage, PercentOff: "10", ProductIDs: model.UUIDList{uuid.New()}},
+			want:       usd(0),
+			wantReason: "does not apply to this product",
+		},
+		{
+			name:      "category the product is in",
+			promotion: model.Promotion{Type: model.PromotionTypePercentage, PercentOff: "10", CategoryIDs: model.UUIDList{categoryID}},
+			scope:     map[uuid.UUID]bool{categoryID: true},
+			want:      usd(300),
+		},
+		{
+			name:       "unknown type",
+			promotion:  model.Promotion{Type: "bogus"},
+			want:       usd(0),
+			wantReason: "has an unknown type",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := tt.order
+			if o == nil {
+				o = order
+			}
+			merchandise := tt.merchandise
+			if merchandise.IsZero() {
+				merchandise = o.Subtotal
+			}
+
+			got, reason := evaluatePromotion(&tt.promotion, o, merchandise, usd(799), tt.scope)
+			if reason != tt.wantReason {
+				t.Errorf("reason = %q, want %q", reason, tt.wantReason)
+			}
+			if got != tt.want {
+				t.Errorf("discount = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+// couponStore serves one coupon and the number of times each user redeemed it
+type couponStore struct {
+	types.PromotionStore
+	coupon *model.Promotion
+	used   map[int]int
+}
+
+func (s *couponStore) GetAutomatic(ctx context.Context) ([]*model.Promotion, error) {
+	return nil, nil
+}
+
+func (s *couponStore) GetByCode(ctx context.Context, code string) (*model.Promotion, error) {
+	if s.coupon.Code == nil || *s.coupon.Code != code {
+		return nil, errors.New("promotion not found")
+	}
+	return s.coupon, nil
+}
+
+func (s *couponStore) CountUserRedemptions(ctx context.Context, promotionID uuid.UUID, userID int) (int, error) {
+	return s.used[userID], nil
+}
+
+func TestApplyPromotionsPerUserLimit(t *testing.T) {
+	code := "SAVE10"
+	store := &couponStore{
+		coupon: &model.Promotion{ID: uuid.New(), Name: "10% off", Code: &code, Type: model.PromotionTypePercentage, PercentOff: "10", Active: true, PerUserLimit: 2},
+		used:   map[int]int{1: 1, 2: 2, 3: 5},
+	}
+	service := NewPromotionService(store, nil)
+
+	tests := []struct {
+		name    string
+		userID  int
+		wantErr bool
+	}{
+		{"under the limit", 1, false},
+		{"at the limit", 2, true},
+		{"over the limit", 3, true},
+		{"first use", 4, false},
+		{"guest checkout", 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			order := &model.Order{ID: uuid.New(), UserID: tt.userID, ProductID: uuid.New(), Quantity: 2, UnitPrice: usd(1000), Subtotal: usd(2000)}
+			err := service.ApplyPromotions(context.Background(), order, []string{" save10 "})
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidCoupon) {
+					t.Errorf("ApplyPromotions error = %v, want ErrInvalidCoupon", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ApplyPromotions: %v", err)
+			}
+			if order.Discount != usd(200) || order.Total != usd(1800) {
+				t.Errorf("discount %s and total %s, want 2.00 USD off 20.00 USD", order.Discount, order.Total)
+			}
+		})
+	}
+}
@@ -0,0 +1,339 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/google/uuid"
+	"oms/server/core/model"
+	"oms/server/core/money"
+	"oms/server/core/types"
+)
+
+var (
+	// ErrInvalidReturn is returned for return requests with an invalid quantity, reason or disposition
+	ErrInvalidReturn = errors.New("invalid return request")
+	// ErrReturnNotAllowed is returned when an order cannot take a new return: it is not delivered,
+	// already has an open return, or has no units left to return
+	ErrReturnNotAllowed = errors.New("return not allowed")
+	// ErrReturnNotFound is returned when a return request does not exist or belongs to another order
+	ErrReturnNotFound = errors.New("return request not found")
+	// ErrInvalidReturnTransition is returned when a return cannot move to the requested status
+	ErrInvalidReturnTransition = errors.New("invalid return transition")
+)
+
+// ReturnService defines the interface for returns (RMA) of delivered orders. A customer requests
+// a return, an admin approves or rejects it, and once the goods are received each unit is
+// restocked or written off as damaged and the order's share of its total is refunded.
+// The order moves through RETURN_REQUESTED to PARTIALLY_RETURNED or RETURNED alongside.
+type ReturnService interface {
+	// RequestReturn opens a return of quantity units of a delivered order for userID
+	RequestReturn(ctx context.Context, orderID uuid.UUID, userID int, quantity int, reason model.ReturnReason, note string) (*model.ReturnRequest, error)
+	Approve(ctx context.Context, orderID, returnID uuid.UUID, adminID int, note string) (*model.ReturnRequest, error)
+	// Reject closes the return and puts the order back to DELIVERED or PARTIALLY_RETURNED
+	Reject(ctx context.Context, orderID, returnID uuid.UUID, adminID int, note string) (*model.ReturnRequest, error)
+	// Receive records the goods as back: restocked units are added to inventory and damaged ones are
+	// written off, together making up the returned quantity. The refund is then issued. When it fails
+	// the return stays received and the error is returned; Refund retries it.
+	Receive(ctx context.Context, orderID, returnID uuid.UUID, adminID int, restocked, damaged int, note string) (*model.ReturnRequest, error)
+	// Refund issues the refund of a received return. Without payments, or for orders placed without
+	// payment, the return stays received and the refund is settled outside the system.
+	Refund(ctx context.Context, orderID, returnID uuid.UUID, adminID int) (*model.ReturnRequest, error)
+	GetReturns(ctx context.Context, orderID uuid.UUID) ([]*model.ReturnRequest, error)
+}
+
+// returnService implements ReturnService
+type returnService struct {
+	returnStore    types.ReturnRequestStore
+	inventoryStore types.InventoryStore
+	orderService   OrderService
+	paymentService PaymentService
+}
+
+// NewReturnService creates a new ReturnService. paymentService may be nil when payments are disabled.
+func NewReturnService(
+	returnStore types.ReturnRequestStore,
+	inventoryStore types.InventoryStore,
+	orderService OrderService,
+	paymentService PaymentService,
+) ReturnService {
+	return &returnService{
+		returnStore:    returnStore,
+		inventoryStore: inventoryStore,
+		orderService:   orderService,
+		paymentService: paymentService,
+	}
+}
+
+// RequestReturn validates the request against the order and earlier returns, records it and
+// moves the order to RETURN_REQUESTED
+func (s *returnService) RequestReturn(ctx context.Context, orderID uuid.UUID, userID int, quantity int, reason model.ReturnReason, note string) (*model.ReturnRequest, error) {
+	if quantity <= 0 {
+		return nil, fmt.Errorf("%w: quantity must be greater than 0", ErrInvalidReturn)
+	}
+	if !reason.IsValid() {
+		return nil, fmt.Errorf("%w: unknown reason %q", ErrInvalidReturn, reason)
+	}
+
+	order, err := s.orderService.GetOrderByID(ctx, orderID)
+	if err != nil {
+		return nil, fmt.Errorf("order not found: %w", err)
+	}
+	if order.CurrentStatus != model.OrderStatusDelivered && order.CurrentStatus != model.OrderStatusPartiallyReturned {
+		return nil, fmt.Errorf("%w: order is %s; only delivered orders can be returned", ErrReturnNotAllowed, order.CurrentStatus)
+	}
+
+	returns, err := s.returnStore.GetByOrderID(ctx, orderID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load returns: %w", err)
+	}
+	remaining := order.ActiveQuantity()
+	for _, other := range returns {
+		if other.Status.IsOpen() {
+			return nil, fmt.Errorf("%w: return %s is still open", ErrReturnNotAllowed, other.ID)
+		}
+		if other.Status != model.ReturnStatusRejected {
+			remaining -= other.Quantity
+		}
+	}
+	if quantity > remaining {
+		return nil, fmt.Errorf("%w: only %d of %d units can still be returned", ErrReturnNotAllowed, remaining, order.ActiveQuantity())
+	}
+
+	request := &model.ReturnRequest{
+		ID:           uuid.New(),
+		OrderID:      orderID,
+		UserID:       userID,
+		Quantity:     quantity,
+		Reason:       reason,
+		Note:         note,
+		Status:       model.ReturnStatusRequested,
+		RefundAmount: money.Zero(order.Total.Currency),
+	}
+	// The store checks the same limits again with the order locked, in case of a concurrent request
+	created, err := s.returnStore.Create(ctx, request)
+	if err != nil {
+		return nil, fmt.Errorf("failed to save return request: %w", err)
+	}
+	if !created {
+		return nil, fmt.Errorf("%w: the order changed while the return was requested", ErrReturnNotAllowed)
+	}
+
+	change := model.StatusChange{
+		Actor:  model.UserActor(userID),
+		Reason: string(reason),
+		Note:   joinNotes(fmt.Sprintf("Return %s of %d units requested", request.ID, quantity), note),
+	}
+	if _, err := s.orderService.UpdateOrderStatus(ctx, orderID, model.OrderStatusReturnRequested, change); err != nil {
+		log.Printf("Warning: return %s requested but order %s could not be moved to %s: %v", request.ID, orderID, model.OrderStatusReturnRequested, err)
+	}
+	return request, nil
+}
+
+// Approve lets the customer send the goods back
+func (s *returnService) Approve(ctx context.Context, orderID, returnID uuid.UUID, adminID int, note string) (*model.ReturnRequest, error) {
+	request, err := s.getForOrder(ctx, orderID, returnID)
+	if err != nil {
+		return nil, err
+	}
+	s.handle(request, adminID, note)
+	if err := s.move(ctx, request, model.ReturnStatusApproved); err != nil {
+		return nil, err
+	}
+	return request, nil
+}
+
+// Reject closes the return without taking the goods back
+func (s *returnService) Reject(ctx context.Context, orderID, returnID uuid.UUID, adminID int, note string) (*model.ReturnRequest, error) {
+	request, err := s.getForOrder(ctx, orderID, returnID)
+	if err != nil {
+		return nil, err
+	}
+	s.handle(request, adminID, note)
+	if err := s.move(ctx, request, model.ReturnStatusRejected); err != nil {
+		return nil, err
+	}
+	s.settleOrder(ctx, request, adminID)
+	return request, nil
+}
+
+// Receive restocks or writes off the returned units, settles the order's status and issues the refund
+func (s *returnService) Receive(ctx context.Context, orderID, returnID uuid.UUID, adminID int, restocked, damaged int, note string) (*model.ReturnRequest, error) {
+	request, err := s.getForOrder(ctx, orderID, returnID)
+	if err != nil {
+		return nil, err
+	}
+	if restocked < 0 || damaged < 0 || restocked+damaged != request.Quantity {
+		return nil, fmt.Errorf("%w: restocked and damaged quantities must add up to the %d units returned", ErrInvalidReturn, request.Quantity)
+	}
+
+	order, err := s.orderService.GetOrderByID(ctx, orderID)
+	if err != nil {
+		return nil, fmt.Errorf("order not found: %w", err)
+	}
+	returns, err := s.returnStore.GetByOrderID(ctx, orderID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load returns: %w", err)
+	}
+	// Returned units take their shares after the cancelled ones, so no unit is counted twice
+	refund, err := order.ShareOf(order.CancelledQuantity+returnedQuantity(returns), request.Quantity)
+	if err != nil {
+		return nil, err
+	}
+
+	previous := *request
+	s.handle(request, adminID, note)
+	request.RestockedQuantity = restocked
+	request.DamagedQuantity = damaged
+	request.RefundAmount = refund
+	if err := s.move(ctx, request, model.ReturnStatusReceived); err != nil {
+		return nil, err
+	}
+
+	// Damaged units are written off: they are recorded on the return and never go back on sale
+	if restocked > 0 {
+		if err := s.inventoryStore.IncrementQuantity(ctx, order.StockUnitID(), restocked); err != nil {
+			if _, undoErr := s.returnStore.UpdateStatus(ctx, &previous, model.ReturnStatusReceived); undoErr != nil {
+				log.Printf("Warning: return %s could not be put back to %s after restocking failed: %v", request.ID, previous.Status, undoErr)
+			}
+			return nil, fmt.Errorf("failed to restock returned units: %w", err)
+		}
+	}
+
+	s.settleOrder(ctx, request, adminID)
+	return s.refund(ctx, request)
+}
+
+// Refund retries the refund of a received return
+func (s *returnService) Refund(ctx context.Context, orderID, returnID uuid.UUID, adminID int) (*model.ReturnRequest, error) {
+	request, err := s.getForOrder(ctx, orderID, returnID)
+	if err != nil {
+		return nil, err
+	}
+	if request.Status != model.ReturnStatusReceived {
+		return nil, fmt.Errorf("%w: cannot refund a %s return", ErrInvalidReturnTransition, request.Status)
+	}
+	request.HandledBy = adminID
+	return s.refund(ctx, request)
+}
+
+// GetReturns retrieves an order's return requests, oldest first
+func (s *returnService) GetReturns(ctx context.Context, orderID uuid.UUID) ([]*model.ReturnRequest, error) {
+	return s.returnStore.GetByOrderID(ctx, orderID)
+}
+
+// refund claims the refund by moving the return to refunded, so concurrent retries cannot refund
+// twice, then asks the payment service for it. The return is put back to received when the order
+// has no payment to refund through, or when the refund fails.
+func (s *returnService) refund(ctx context.Context, request *model.ReturnRequest) (*model.ReturnRequest, error) {
+	if s.paymentService == nil {
+		return request, nil
+	}
+	if err := s.move(ctx, request, model.ReturnStatusRefunded); err != nil {
+		return nil, err
+	}
+	if request.RefundAmount.IsZero() {
+		return request, nil
+	}
+
+	_, err := s.paymentService.Refund(ctx, request.OrderID, request.RefundAmount, "return "+request.ID.String())
+	if err == nil {
+		return request, nil
+	}
+	request.Status = model.ReturnStatusReceived
+	if _, undoErr := s.returnStore.UpdateStatus(ctx, request, model.ReturnStatusRefunded); undoErr != nil {
+		log.Printf("Warning: return %s is marked refunded but its refund failed: %v (and could not be undone: %v)", request.ID, err, undoErr)
+	}
+	if errors.Is(err, ErrPaymentNotFound) {
+		return request, nil
+	}
+	return nil, err
+}
+
+// settleOrder moves the order of a return that was just closed back out of RETURN_REQUESTED, to
+// RETURNED once every unit is back, PARTIALLY_RETURNED when some are, and DELIVERED otherwise.
+// The step is logged with the return's reason.
+func (s *returnService) settleOrder(ctx context.Context, request *model.ReturnRequest, updatedBy int) {
+	orderID := request.OrderID
+	order, err := s.orderService.GetOrderByID(ctx, orderID)
+	if err == nil && order.CurrentStatus != model.OrderStatusReturnRequested {
+		return
+	}
+	var returns []*model.ReturnRequest
+	if err == nil {
+		returns, err = s.returnStore.GetByOrderID(ctx, orderID)
+	}
+	if err != nil {
+		log.Printf("Warning: order %s could not be moved out of %s: %v", orderID, model.OrderStatusReturnRequested, err)
+		return
+	}
+
+	newStatus := model.OrderStatusDelivered
+	if returned := returnedQuantity(returns); returned >= order.ActiveQuantity() {
+		newStatus = model.OrderStatusReturned
+	} else if returned > 0 {
+		newStatus = model.OrderStatusPartiallyReturned
+	}
+	change := model.StatusChange{
+		Actor:  model.UserActor(updatedBy),
+		Reason: string(request.Reason),
+		Note:   joinNotes(fmt.Sprintf("Return %s %s", request.ID, request.Status), request.ResolutionNote),
+	}
+	if _, err := s.orderService.UpdateOrderStatus(ctx, orderID, newStatus, change); err != nil {
+		log.Printf("Warning: order %s could not be moved to %s: %v", orderID, newStatus, err)
+	}
+}
+
+// getForOrder retrieves a return request, checking it belongs to the order
+func (s *returnService) getForOrder(ctx context.Context, orderID, returnID uuid.UUID) (*model.ReturnRequest, error) {
+	request, err := s.returnStore.GetByID(ctx, returnID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrReturnNotFound, err)
+	}
+	if request.OrderID != orderID {
+		return nil, fmt.Errorf("%w: return %s is not for order %s", ErrReturnNotFound, returnID, orderID)
+	}
+	return request, nil
+}
+
+// handle records the admin acting on the return and their note, keeping any earlier note when none is given
+func (s *returnService) handle(request *model.ReturnRequest, adminID int, note string) {
+	request.HandledBy = adminID
+	if note != "" {
+		request.ResolutionNote = note
+	}
+}
+
+// move saves the return in its next status. It fails when the return cannot make that change or
+// changed concurrently, leaving request's status as it was.
+func (s *returnService) move(ctx context.Context, request *model.ReturnRequest, next model.ReturnStatus) error {
+	previous := request.Status
+	if !previous.CanBecome(next) {
+		return fmt.Errorf("%w: cannot move a %s return to %s", ErrInvalidReturnTransition, previous, next)
+	}
+	request.Status = next
+	applied, err := s.returnStore.UpdateStatus(ctx, request, previous)
+	if err != nil || !applied {
+		request.Status = previous
+	}
+	if err != nil {
+		return fmt.Errorf("failed to save return request: %w", err)
+	}
+	if !applied {
+		return fmt.Errorf("%w: return %s changed while moving to %s", ErrInvalidReturnTransition, request.ID, next)
+	}
+	return nil
+}
+
+// returnedQuantity counts the units of received and refunded returns
+func returnedQuantity(returns []*model.ReturnRequest) int {
+	returned := 0
+	for _, request := range returns {
+		if request.Status == model.ReturnStatusReceived || request.Status == model.ReturnStatusRefunded {
+			returned += request.Quantity
+		}
+	}
+	return returned
+}
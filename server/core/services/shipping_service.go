@@ -0,0 +1,148 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"oms/server/core/model"
+	"oms/server/core/types"
+)
+
+var (
+	// ErrInvalidShippingZone is returned when a shipping zone definition cannot be saved
+	ErrInvalidShippingZone = errors.New("invalid shipping zone")
+	// ErrShippingZoneNotFound is returned when a referenced shipping zone does not exist
+	ErrShippingZoneNotFound = errors.New("shipping zone not found")
+)
+
+// ShippingService defines the interface for managing the zones of the table-rate shipping provider
+type ShippingService interface {
+	ListZones(ctx context.Context) ([]*model.ShippingZone, error)
+	CreateZone(ctx context.Context, zone *model.ShippingZone) error
+	UpdateZone(ctx context.Context, zone *model.ShippingZone) error
+	DeleteZone(ctx context.Context, zoneID uuid.UUID) error
+}
+
+// shippingService implements ShippingService
+type shippingService struct {
+	zoneStore types.ShippingZoneStore
+}
+
+// NewShippingService creates a new ShippingService
+func NewShippingService(zoneStore types.ShippingZoneStore) ShippingService {
+	return &shippingService{zoneStore: zoneStore}
+}
+
+// ListZones retrieves all shipping zones with their rates
+func (s *shippingService) ListZones(ctx context.Context) ([]*model.ShippingZone, error) {
+	return s.zoneStore.GetAll(ctx)
+}
+
+// CreateZone validates and saves a new shipping zone with its rates
+func (s *shippingService) CreateZone(ctx context.Context, zone *model.ShippingZone) error {
+	if err := s.checkDefinition(ctx, zone); err != nil {
+		return err
+	}
+	if err := s.zoneStore.Create(ctx, zone); err != nil {
+		return fmt.Errorf("failed to create shipping zone: %w", err)
+	}
+	return nil
+}
+
+// UpdateZone validates and replaces a shipping zone and its rates
+func (s *shippingService) UpdateZone(ctx context.Context, zone *model.ShippingZone) error {
+	existing, err := s.zoneStore.GetByID(ctx, zone.ID)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrShippingZoneNotFound, zone.ID)
+	}
+	zone.CreatedAt = existing.CreatedAt
+	if err := s.checkDefinition(ctx, zone); err != nil {
+		return err
+	}
+	if err := s.zoneStore.Update(ctx, zone); err != nil {
+		return fmt.Errorf("failed to update shipping zone: %w", err)
+	}
+	return nil
+}
+
+// DeleteZone removes a shipping zone; orders keep the shipping option they were charged
+func (s *shippingService) DeleteZone(ctx context.Context, zoneID uuid.UUID) error {
+	if _, err := s.zoneStore.GetByID(ctx, zoneID); err != nil {
+		return fmt.Errorf("%w: %s", ErrShippingZoneNotFound, zoneID)
+	}
+	return s.zoneStore.Delete(ctx, zoneID)
+}
+
+// checkDefinition normalizes a zone's scope and rates, checks the rates' bands and prices, and
+// that no other zone has the same scope
+func (s *shippingService) checkDefinition(ctx context.Context, zone *model.ShippingZone) error {
+	zone.Name = strings.TrimSpace(zone.Name)
+	zone.Country = strings.ToUpper(strings.TrimSpace(zone.Country))
+	zone.PostcodePrefix = model.NormalizePostcode(zone.PostcodePrefix)
+
+	if zone.Name == "" {
+		return fmt.Errorf("%w: name is required", ErrInvalidShippingZone)
+	}
+	if len(zone.Country) != 2 {
+		return fmt.Errorf("%w: country must be a two-letter ISO 3166 code", ErrInvalidShippingZone)
+	}
+	for i := range zone.Rates {
+		if err := checkRate(&zone.Rates[i]); err != nil {
+			return err
+		}
+	}
+	for i := range zone.Rates {
+		for j := i + 1; j < len(zone.Rates); j++ {
+			a, b := &zone.Rates[i], &zone.Rates[j]
+			if a.Code == b.Code && bandsOverlap(a, b) {
+				return fmt.Errorf("%w: rates for %q have overlapping weight bands", ErrInvalidShippingZone, a.Code)
+			}
+		}
+	}
+
+	zones, err := s.zoneStore.GetByCountry(ctx, zone.Country)
+	if err != nil {
+		return fmt.Errorf("failed to load shipping zones: %w", err)
+	}
+	for _, other := range zones {
+		if other.ID != zone.ID && other.PostcodePrefix == zone.PostcodePrefix {
+			return fmt.Errorf("%w: zone %q already covers this country and postcode prefix", ErrInvalidShippingZone, other.Name)
+		}
+	}
+	return nil
+}
+
+// checkRate normalizes a rate's option code and checks its weight band and prices
+func checkRate(rate *model.ShippingRate) error {
+	rate.Code = strings.ToLower(strings.TrimSpace(rate.Code))
+	rate.Name = strings.TrimSpace(rate.Name)
+	rate.Carrier = strings.ToLower(strings.TrimSpace(rate.Carrier))
+	rate.ServiceLevel = strings.TrimSpace(rate.ServiceLevel)
+
+	switch {
+	case rate.Code == "" || rate.Name == "":
+		return fmt.Errorf("%w: rates need a code and a name", ErrInvalidShippingZone)
+	case rate.MinWeightGrams < 0 || rate.MaxWeightGrams < 0:
+		return fmt.Errorf("%w: rate %q weights must not be negative", ErrInvalidShippingZone, rate.Code)
+	case rate.MaxWeightGrams != 0 && rate.MaxWeightGrams <= rate.MinWeightGrams:
+		return fmt.Errorf("%w: rate %q max_weight_grams must be above min_weight_grams", ErrInvalidShippingZone, rate.Code)
+	case rate.Price.IsNegative() || rate.FreeOver.IsNegative():
+		return fmt.Errorf("%w: rate %q amounts must not be negative", ErrInvalidShippingZone, rate.Code)
+	case rate.EstimatedDays < 0:
+		return fmt.Errorf("%w: rate %q estimated_days must not be negative", ErrInvalidShippingZone, rate.Code)
+	}
+	if !rate.FreeOver.IsZero() && rate.FreeOver.Currency != rate.Price.Currency {
+		return fmt.Errorf("%w: rate %q free_over must be in %s", ErrInvalidShippingZone, rate.Code, rate.Price.Currency)
+	}
+	return nil
+}
+
+// bandsOverlap reports whether two rates' weight bands share a weight
+func bandsOverlap(a, b *model.ShippingRate) bool {
+	aBelowB := a.MaxWeightGrams != 0 && a.MaxWeightGrams <= b.MinWeightGrams
+	bBelowA := b.MaxWeightGrams != 0 && b.MaxWeightGrams <= a.MinWeightGrams
+	return !aBelowB && !bBelowA
+}
@@ -0,0 +1,182 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"oms/server/core/model"
+	"oms/server/core/stock"
+	"oms/server/core/types"
+)
+
+var (
+	// ErrStockAlertNotFound is returned when a referenced stock alert does not exist
+	ErrStockAlertNotFound = errors.New("stock alert not found")
+	// ErrInvalidStockAlertTransition is returned when an alert can't move to the requested status,
+	// such as acknowledging a resolved one
+	ErrInvalidStockAlertTransition = errors.New("invalid stock alert transition")
+)
+
+// notifyTimeout bounds how long the notifications of a new alert may take
+const notifyTimeout = 30 * time.Second
+
+// StockAlertService defines the interface for low-stock alerts. It is the stock.Watcher of the
+// inventory stores, so alerts follow every inventory change.
+type StockAlertService interface {
+	stock.Watcher
+	ListAlerts(ctx context.Context, filter model.StockAlertFilter) ([]*model.StockAlert, error)
+	AcknowledgeAlert(ctx context.Context, alertID uuid.UUID, userID int) (*model.StockAlert, error)
+	// ResolveAlert closes an alert by hand. A stock unit still at its reorder point gets a new alert
+	// on its next inventory change.
+	ResolveAlert(ctx context.Context, alertID uuid.UUID) (*model.StockAlert, error)
+	// ListLowStock lists the stock units at or below their reorder point, lowest relative to it first
+	ListLowStock(ctx context.Context) ([]*model.LowStockItem, error)
+	// Wait blocks until the notifications of the alerts opened so far are sent, or ctx is done, so
+	// they aren't lost on shutdown
+	Wait(ctx context.Context) error
+}
+
+// stockAlertService implements StockAlertService
+type stockAlertService struct {
+	alertStore types.StockAlertStore
+	notifier   types.StockAlertNotifier // Optional; new alerts are only recorded without it
+	sending    sync.WaitGroup
+}
+
+// NewStockAlertService creates a new StockAlertService
+func NewStockAlertService(alertStore types.StockAlertStore, notifier types.StockAlertNotifier) StockAlertService {
+	return &stockAlertService{
+		alertStore: alertStore,
+		notifier:   notifier,
+	}
+}
+
+// Check opens an alert for a stock unit that is at or below its reorder point and has none, and
+// resolves its alert once it is back above. The change is saved by then, so this carries on when
+// the request that made it is cancelled. Errors are logged: a stock change never fails on its alert.
+func (s *stockAlertService) Check(ctx context.Context, stockUnitID uuid.UUID) {
+	ctx = context.WithoutCancel(ctx)
+
+	items, err := s.alertStore.GetLowStock(ctx, []uuid.UUID{stockUnitID})
+	if err != nil {
+		log.Printf("Warning: failed to check stock unit %s against its reorder point: %v", stockUnitID, err)
+		return
+	}
+	if len(items) == 0 {
+		if _, err := s.alertStore.ResolveOpen(ctx, stockUnitID, time.Now()); err != nil {
+			log.Printf("Warning: failed to resolve the stock alert of stock unit %s: %v", stockUnitID, err)
+		}
+		return
+	}
+
+	item := items[0]
+	if item.AlertID != nil {
+		return
+	}
+	alert := &model.StockAlert{
+		ProductID:    stockUnitID,
+		Status:       model.StockAlertOpen,
+		Quantity:     item.Quantity,
+		ReorderPoint: item.ReorderPoint,
+		TargetLevel:  item.TargetLevel,
+	}
+	opened, err := s.alertStore.Open(ctx, alert)
+	if err != nil {
+		log.Printf("Warning: failed to open a stock alert for %s: %v", item.SKU, err)
+		return
+	}
+	if !opened || s.notifier == nil {
+		return
+	}
+	item.AlertID = &alert.ID
+	item.AlertStatus = &alert.Status
+
+	// Notifications go out in the background so they never hold up the change
+	s.sending.Add(1)
+	go func() {
+		defer s.sending.Done()
+		ctx, cancel := context.WithTimeout(ctx, notifyTimeout)
+		defer cancel()
+		if err := s.notifier.Notify(ctx, alert, item); err != nil {
+			log.Printf("Warning: failed to send the notifications of stock alert %s for %s: %v", alert.ID, item.SKU, err)
+		}
+	}()
+}
+
+// ListAlerts lists the alerts matching filter, newest first
+func (s *stockAlertService) ListAlerts(ctx context.Context, filter model.StockAlertFilter) ([]*model.StockAlert, error) {
+	return s.alertStore.Search(ctx, filter)
+}
+
+// AcknowledgeAlert marks an open alert as being dealt with by the admin userID
+func (s *stockAlertService) AcknowledgeAlert(ctx context.Context, alertID uuid.UUID, userID int) (*model.StockAlert, error) {
+	alert, err := s.getAlert(ctx, alertID)
+	if err != nil {
+		return nil, err
+	}
+	if alert.Status != model.StockAlertOpen {
+		return nil, fmt.Errorf("%w: alert is %s", ErrInvalidStockAlertTransition, alert.Status)
+	}
+
+	now := time.Now().UTC()
+	alert.Status = model.StockAlertAcknowledged
+	alert.AcknowledgedBy = &userID
+	alert.AcknowledgedAt = &now
+	if err := s.alertStore.Update(ctx, alert); err != nil {
+		return nil, fmt.Errorf("failed to acknowledge stock alert: %w", err)
+	}
+	return alert, nil
+}
+
+// ResolveAlert resolves an alert that isn't resolved yet
+func (s *stockAlertService) ResolveAlert(ctx context.Context, alertID uuid.UUID) (*model.StockAlert, error) {
+	alert, err := s.getAlert(ctx, alertID)
+	if err != nil {
+		return nil, err
+	}
+	if alert.Status == model.StockAlertResolved {
+		return nil, fmt.Errorf("%w: alert is already resolved", ErrInvalidStockAlertTransition)
+	}
+
+	now := time.Now().UTC()
+	alert.Status = model.StockAlertResolved
+	alert.ResolvedAt = &now
+	if err := s.alertStore.Update(ctx, alert); err != nil {
+		return nil, fmt.Errorf("failed to resolve stock alert: %w", err)
+	}
+	return alert, nil
+}
+
+// ListLowStock lists every stock unit at or below its reorder point
+func (s *stockAlertService) ListLowStock(ctx context.Context) ([]*model.LowStockItem, error) {
+	return s.alertStore.GetLowStock(ctx, nil)
+}
+
+// Wait waits for the notifications in progress
+func (s *stockAlertService) Wait(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		s.sending.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("stock alert notifications still sending: %w", ctx.Err())
+	}
+}
+
+// getAlert retrieves an alert, mapping a missing one to ErrStockAlertNotFound
+func (s *stockAlertService) getAlert(ctx context.Context, alertID uuid.UUID) (*model.StockAlert, error) {
+	alert, err := s.alertStore.GetByID(ctx, alertID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrStockAlertNotFound, alertID)
+	}
+	return alert, nil
+}
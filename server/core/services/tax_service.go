@@ -0,0 +1,108 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"oms/server/core/model"
+	"oms/server/core/tax"
+	"oms/server/core/types"
+)
+
+var (
+	// ErrInvalidTaxRule is returned when a tax rule definition cannot be saved
+	ErrInvalidTaxRule = errors.New("invalid tax rule")
+	// ErrTaxRuleNotFound is returned when a referenced tax rule does not exist
+	ErrTaxRuleNotFound = errors.New("tax rule not found")
+)
+
+// TaxService defines the interface for managing the rules of the rule-table tax calculator
+type TaxService interface {
+	ListRules(ctx context.Context) ([]*model.TaxRule, error)
+	CreateRule(ctx context.Context, rule *model.TaxRule) error
+	UpdateRule(ctx context.Context, rule *model.TaxRule) error
+	DeleteRule(ctx context.Context, ruleID uuid.UUID) error
+}
+
+// taxService implements TaxService
+type taxService struct {
+	ruleStore types.TaxRuleStore
+}
+
+// NewTaxService creates a new TaxService
+func NewTaxService(ruleStore types.TaxRuleStore) TaxService {
+	return &taxService{ruleStore: ruleStore}
+}
+
+// ListRules retrieves all tax rules
+func (s *taxService) ListRules(ctx context.Context) ([]*model.TaxRule, error) {
+	return s.ruleStore.GetAll(ctx)
+}
+
+// CreateRule validates and saves a new tax rule
+func (s *taxService) CreateRule(ctx context.Context, rule *model.TaxRule) error {
+	if err := s.checkDefinition(ctx, rule); err != nil {
+		return err
+	}
+	if err := s.ruleStore.Create(ctx, rule); err != nil {
+		return fmt.Errorf("failed to create tax rule: %w", err)
+	}
+	return nil
+}
+
+// UpdateRule validates and replaces a tax rule
+func (s *taxService) UpdateRule(ctx context.Context, rule *model.TaxRule) error {
+	existing, err := s.ruleStore.GetByID(ctx, rule.ID)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrTaxRuleNotFound, rule.ID)
+	}
+	rule.CreatedAt = existing.CreatedAt
+	if err := s.checkDefinition(ctx, rule); err != nil {
+		return err
+	}
+	if err := s.ruleStore.Update(ctx, rule); err != nil {
+		return fmt.Errorf("failed to update tax rule: %w", err)
+	}
+	return nil
+}
+
+// DeleteRule removes a tax rule; orders keep the tax lines it produced
+func (s *taxService) DeleteRule(ctx context.Context, ruleID uuid.UUID) error {
+	if _, err := s.ruleStore.GetByID(ctx, ruleID); err != nil {
+		return fmt.Errorf("%w: %s", ErrTaxRuleNotFound, ruleID)
+	}
+	return s.ruleStore.Delete(ctx, ruleID)
+}
+
+// checkDefinition normalizes a rule's scope and checks its rate, and that no other rule has the same scope
+func (s *taxService) checkDefinition(ctx context.Context, rule *model.TaxRule) error {
+	rule.Name = strings.TrimSpace(rule.Name)
+	rule.Country = strings.ToUpper(strings.TrimSpace(rule.Country))
+	rule.Region = strings.ToUpper(strings.TrimSpace(rule.Region))
+	rule.TaxClass = strings.ToLower(strings.TrimSpace(rule.TaxClass))
+	rule.Rate = strings.TrimSpace(rule.Rate)
+
+	if rule.Name == "" {
+		return fmt.Errorf("%w: name is required", ErrInvalidTaxRule)
+	}
+	if len(rule.Country) != 2 {
+		return fmt.Errorf("%w: country must be a two-letter ISO 3166 code", ErrInvalidTaxRule)
+	}
+	if _, err := tax.ParseRate(rule.Rate); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidTaxRule, err)
+	}
+
+	rules, err := s.ruleStore.GetByCountry(ctx, rule.Country)
+	if err != nil {
+		return fmt.Errorf("failed to load tax rules: %w", err)
+	}
+	for _, other := range rules {
+		if other.ID != rule.ID && other.Region == rule.Region && other.TaxClass == rule.TaxClass {
+			return fmt.Errorf("%w: rule %q already covers this country, region and tax class", ErrInvalidTaxRule, other.Name)
+		}
+	}
+	return nil
+}
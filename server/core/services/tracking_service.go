@@ -0,0 +1,124 @@
+package services
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"oms/server/core/model"
+	"oms/server/core/types"
+)
+
+var (
+	// ErrInvalidTrackingEvent is returned for tracking events that fail verification or cannot be decoded
+	ErrInvalidTrackingEvent = errors.New("invalid tracking event")
+	// ErrShipmentNotFound is returned for tracking events of a tracking number no shipment has
+	ErrShipmentNotFound = errors.New("shipment not found")
+)
+
+// TrackingService defines the interface for ingesting carrier tracking events
+type TrackingService interface {
+	// HandleEvent verifies a signed tracking event, records it against its shipment and delivers the
+	// order once every one of its shipments has been delivered. It returns the shipment as updated;
+	// a redelivered event is acknowledged without being applied again.
+	HandleEvent(ctx context.Context, payload []byte, signature string) (*model.Shipment, error)
+}
+
+// trackingService implements TrackingService
+type trackingService struct {
+	shipmentStore types.ShipmentStore
+	orderService  OrderService
+	secret        []byte
+}
+
+// NewTrackingService creates a new TrackingService. Events are signed with secret.
+func NewTrackingService(shipmentStore types.ShipmentStore, orderService OrderService, secret string) TrackingService {
+	return &trackingService{
+		shipmentStore: shipmentStore,
+		orderService:  orderService,
+		secret:        []byte(secret),
+	}
+}
+
+// trackingEvent is the carrier-agnostic body of a tracking event; carrier integrations translate
+// their own notifications into it
+type trackingEvent struct {
+	ID             string    `json:"id"`
+	Carrier        string    `json:"carrier"`
+	TrackingNumber string    `json:"tracking_number"`
+	Status         string    `json:"status"`
+	OccurredAt     time.Time `json:"occurred_at"`
+	Location       string    `json:"location"`
+	Description    string    `json:"description"`
+}
+
+// HandleEvent records the event and, when it delivers the shipment, tries to deliver the order
+func (s *trackingService) HandleEvent(ctx context.Context, payload []byte, signature string) (*model.Shipment, error) {
+	body, err := s.parse(payload, signature)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidTrackingEvent, err)
+	}
+
+	shipment, err := s.shipmentStore.GetByTrackingNumber(ctx, body.Carrier, body.TrackingNumber)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s %s", ErrShipmentNotFound, body.Carrier, body.TrackingNumber)
+	}
+	wasDelivered := shipment.IsDelivered()
+
+	event := &model.TrackingEvent{
+		EventID:     body.ID,
+		Status:      model.TrackingStatus(body.Status),
+		Description: body.Description,
+		Location:    body.Location,
+		OccurredAt:  body.OccurredAt,
+	}
+	recorded, err := s.shipmentStore.RecordEvent(ctx, shipment, event)
+	if err != nil {
+		return nil, fmt.Errorf("failed to record tracking event: %w", err)
+	}
+	if !recorded || wasDelivered || !shipment.IsDelivered() {
+		return shipment, nil
+	}
+
+	// The event is recorded, so a failure here is not retried by the carrier
+	if _, err := s.orderService.DeliverShipment(ctx, shipment); err != nil {
+		log.Printf("Warning: shipment %s was delivered but order %s could not be updated: %v", shipment.ID, shipment.OrderID, err)
+	}
+	return shipment, nil
+}
+
+// parse checks the signature, the hex HMAC-SHA256 of the body, and decodes the event
+func (s *trackingService) parse(payload []byte, signature string) (*trackingEvent, error) {
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write(payload)
+	expected := hex.EncodeToString(mac.Sum(nil))
+	if !hmac.Equal([]byte(strings.ToLower(strings.TrimSpace(signature))), []byte(expected)) {
+		return nil, errors.New("signature does not match")
+	}
+
+	var body trackingEvent
+	if err := json.Unmarshal(payload, &body); err != nil {
+		return nil, fmt.Errorf("invalid body: %w", err)
+	}
+	body.Carrier = strings.ToLower(strings.TrimSpace(body.Carrier))
+	body.TrackingNumber = strings.TrimSpace(body.TrackingNumber)
+	switch {
+	case body.ID == "":
+		return nil, errors.New("event id is required")
+	case body.Carrier == "" || body.TrackingNumber == "":
+		return nil, errors.New("carrier and tracking_number are required")
+	case !model.TrackingStatus(body.Status).IsValid():
+		return nil, fmt.Errorf("unknown tracking status %q", body.Status)
+	}
+	if body.OccurredAt.IsZero() {
+		body.OccurredAt = time.Now()
+	}
+	return &body, nil
+}
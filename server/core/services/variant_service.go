@@ -0,0 +1,245 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/google/uuid"
+	"oms/server/core/model"
+	"oms/server/core/money"
+	"oms/server/core/types"
+)
+
+// MaxVariantsPerProduct caps the size of a generated variant matrix
+const MaxVariantsPerProduct = 500
+
+// ErrInvalidVariantOptions is returned when option definitions cannot produce a variant matrix
+var ErrInvalidVariantOptions = errors.New("invalid variant options")
+
+// VariantService defines the interface for product variant business logic
+type VariantService interface {
+	GenerateVariants(ctx context.Context, productID uuid.UUID, options model.ProductOptions) ([]*model.ProductVariant, error)
+	UpdateVariant(ctx context.Context, variantID uuid.UUID, sku string, price *money.Money, resetPrice bool) (*model.ProductVariant, error)
+	DeleteVariant(ctx context.Context, variantID uuid.UUID) error
+}
+
+// variantService implements VariantService
+type variantService struct {
+	productStore   types.ProductStore
+	variantStore   types.ProductVariantStore
+	inventoryStore types.InventoryStore
+}
+
+// NewVariantService creates a new VariantService
+func NewVariantService(
+	productStore types.ProductStore,
+	variantStore types.ProductVariantStore,
+	inventoryStore types.InventoryStore,
+) VariantService {
+	return &variantService{
+		productStore:   productStore,
+		variantStore:   variantStore,
+		inventoryStore: inventoryStore,
+	}
+}
+
+// GenerateVariants builds the variant matrix of a product from its option definitions.
+// It is idempotent: variants whose option combination still exists are kept with their
+// SKU, price and stock, new combinations are created with zero stock, and variants for
+// combinations that are no longer defined are deleted.
+func (s *variantService) GenerateVariants(ctx context.Context, productID uuid.UUID, options model.ProductOptions) ([]*model.ProductVariant, error) {
+	options = normalizeProductOptions(options)
+	if err := validateProductOptions(options); err != nil {
+		return nil, err
+	}
+
+	product, err := s.productStore.GetByID(ctx, productID)
+	if err != nil {
+		return nil, fmt.Errorf("product not found: %w", err)
+	}
+
+	existing, err := s.variantStore.GetByProductID(ctx, productID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load variants: %w", err)
+	}
+	existingByKey := make(map[string]*model.ProductVariant, len(existing))
+	for _, variant := range existing {
+		existingByKey[variant.Options.Key()] = variant
+	}
+
+	combinations := variantCombinations(options)
+	variants := make([]*model.ProductVariant, 0, len(combinations))
+	for _, combination := range combinations {
+		key := combination.Key()
+		if variant, ok := existingByKey[key]; ok {
+			delete(existingByKey, key)
+			variants = append(variants, variant)
+			continue
+		}
+
+		variant := &model.ProductVariant{
+			ProductID: productID,
+			SKU:       variantSKU(product.SKU, options, combination),
+			Options:   combination,
+		}
+		if err := s.variantStore.Create(ctx, variant); err != nil {
+			return nil, fmt.Errorf("failed to create variant %s: %w", variant.SKU, err)
+		}
+		if err := s.inventoryStore.UpdateQuantity(ctx, variant.ID, 0); err != nil {
+			return nil, fmt.Errorf("failed to create inventory for variant %s: %w", variant.SKU, err)
+		}
+		variants = append(variants, variant)
+	}
+
+	// Combinations that are no longer defined
+	for _, variant := range existingByKey {
+		if err := s.variantStore.Delete(ctx, variant.ID); err != nil {
+			return nil, fmt.Errorf("failed to delete variant %s: %w", variant.SKU, err)
+		}
+	}
+
+	product.Options = options
+	if err := s.productStore.Update(ctx, productID, product); err != nil {
+		return nil, fmt.Errorf("failed to update product options: %w", err)
+	}
+
+	return variants, nil
+}
+
+// UpdateVariant changes a variant's SKU and price override.
+// An empty sku keeps the current one; resetPrice removes the override.
+// The override must be in the product's currency.
+func (s *variantService) UpdateVariant(ctx context.Context, variantID uuid.UUID, sku string, price *money.Money, resetPrice bool) (*model.ProductVariant, error) {
+	variant, err := s.variantStore.GetByID(ctx, variantID)
+	if err != nil {
+		return nil, err
+	}
+
+	if sku != "" {
+		variant.SKU = sku
+	}
+	if price != nil {
+		if price.IsNegative() {
+			return nil, fmt.Errorf("%w: price cannot be negative", ErrInvalidVariantOptions)
+		}
+		product, err := s.productStore.GetByID(ctx, variant.ProductID)
+		if err != nil {
+			return nil, fmt.Errorf("product not found: %w", err)
+		}
+		if price.Currency != product.Price.Currency {
+			return nil, fmt.Errorf("%w: price must be in %s like the product", ErrInvalidVariantOptions, product.Price.Currency)
+		}
+		variant.Price = price
+	}
+	if resetPrice {
+		variant.Price = nil
+	}
+
+	if err := s.variantStore.Update(ctx, variant); err != nil {
+		return nil, fmt.Errorf("failed to update variant: %w", err)
+	}
+	return variant, nil
+}
+
+// DeleteVariant deletes a single variant
+func (s *variantService) DeleteVariant(ctx context.Context, variantID uuid.UUID) error {
+	return s.variantStore.Delete(ctx, variantID)
+}
+
+// normalizeProductOptions returns a copy of options with names and values trimmed
+func normalizeProductOptions(options model.ProductOptions) model.ProductOptions {
+	normalized := make(model.ProductOptions, len(options))
+	for i, option := range options {
+		normalized[i].Name = strings.TrimSpace(option.Name)
+		normalized[i].Values = make([]string, len(option.Values))
+		for j, value := range option.Values {
+			normalized[i].Values[j] = strings.TrimSpace(value)
+		}
+	}
+	return normalized
+}
+
+// validateProductOptions checks option names and values and the resulting matrix size
+func validateProductOptions(options model.ProductOptions) error {
+	if len(options) == 0 {
+		return fmt.Errorf("%w: at least one option is required", ErrInvalidVariantOptions)
+	}
+
+	combinations := 1
+	names := map[string]bool{}
+	for _, option := range options {
+		name := option.Name
+		if name == "" {
+			return fmt.Errorf("%w: option name is required", ErrInvalidVariantOptions)
+		}
+		if names[strings.ToLower(name)] {
+			return fmt.Errorf("%w: duplicate option %q", ErrInvalidVariantOptions, name)
+		}
+		names[strings.ToLower(name)] = true
+
+		if len(option.Values) == 0 {
+			return fmt.Errorf("%w: option %q has no values", ErrInvalidVariantOptions, name)
+		}
+		values := map[string]bool{}
+		for _, value := range option.Values {
+			if value == "" {
+				return fmt.Errorf("%w: option %q has an empty value", ErrInvalidVariantOptions, name)
+			}
+			if values[value] {
+				return fmt.Errorf("%w: option %q has duplicate value %q", ErrInvalidVariantOptions, name, value)
+			}
+			values[value] = true
+		}
+
+		combinations *= len(option.Values)
+		if combinations > MaxVariantsPerProduct {
+			return fmt.Errorf("%w: options produce more than %d variants", ErrInvalidVariantOptions, MaxVariantsPerProduct)
+		}
+	}
+	return nil
+}
+
+// variantCombinations returns the cartesian product of the option values,
+// varying the last option fastest
+func variantCombinations(options model.ProductOptions) []model.VariantOptions {
+	combinations := []model.VariantOptions{{}}
+	for _, option := range options {
+		next := make([]model.VariantOptions, 0, len(combinations)*len(option.Values))
+		for _, combination := range combinations {
+			for _, value := range option.Values {
+				extended := make(model.VariantOptions, len(combination)+1)
+				for name, chosen := range combination {
+					extended[name] = chosen
+				}
+				extended[option.Name] = value
+				next = append(next, extended)
+			}
+		}
+		combinations = next
+	}
+	return combinations
+}
+
+// variantSKU derives a variant SKU from the product SKU and the chosen values,
+// e.g. TSHIRT-M-RED
+func variantSKU(productSKU string, options model.ProductOptions, combination model.VariantOptions) string {
+	parts := []string{productSKU}
+	for _, option := range options {
+		parts = append(parts, skuSegment(combination[option.Name]))
+	}
+	return strings.Join(parts, "-")
+}
+
+// skuSegment upper-cases value and replaces anything but letters and digits with dashes
+func skuSegment(value string) string {
+	segment := strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToUpper(r)
+		}
+		return '-'
+	}, value)
+	return strings.Trim(segment, "-")
+}
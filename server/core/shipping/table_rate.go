@@ -0,0 +1,127 @@
+package shipping
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"oms/server/core/model"
+	"oms/server/core/money"
+	"oms/server/core/types"
+)
+
+// VolumetricDivisor converts cubic centimetres to billable grams, the common carrier factor of 5000 cm³/kg
+const VolumetricDivisor = 5
+
+// tableRate implements types.ShippingRateProvider from the admin-managed shipping zones
+type tableRate struct {
+	zoneStore types.ShippingZoneStore
+}
+
+// NewTableRate creates a ShippingRateProvider that prices options by zone and weight band
+func NewTableRate(zoneStore types.ShippingZoneStore) types.ShippingRateProvider {
+	return &tableRate{zoneStore: zoneStore}
+}
+
+// Quote implements types.ShippingRateProvider. Destinations without a country, or that no zone
+// covers, have no options; an option whose bands do not cover the parcel's weight is left out.
+func (t *tableRate) Quote(ctx context.Context, lines []model.ShippableLine, address model.ShippingAddress) ([]model.ShippingOption, error) {
+	options := []model.ShippingOption{}
+	if address.Country == "" || len(lines) == 0 {
+		return options, nil
+	}
+	zones, err := t.zoneStore.GetByCountry(ctx, address.Country)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load shipping zones: %w", err)
+	}
+	zone := MatchZone(zones, address.Postcode)
+	if zone == nil {
+		return options, nil
+	}
+
+	weight := 0
+	values := make([]money.Money, 0, len(lines))
+	for _, line := range lines {
+		weight += BillableWeight(line)
+		values = append(values, line.Value)
+	}
+	value, err := money.Sum(values...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to total the shipment value: %w", err)
+	}
+
+	for i := range zone.Rates {
+		rate := &zone.Rates[i]
+		if !rate.Covers(weight) {
+			continue
+		}
+		amount, err := Price(rate, value)
+		if err != nil {
+			return nil, fmt.Errorf("shipping rate %s: %w", rate.Code, err)
+		}
+		rateID := rate.ID
+		options = append(options, model.ShippingOption{
+			RateID:        &rateID,
+			Code:          rate.Code,
+			Name:          rate.Name,
+			Carrier:       rate.Carrier,
+			ServiceLevel:  rate.ServiceLevel,
+			Amount:        amount,
+			EstimatedDays: rate.EstimatedDays,
+		})
+	}
+	sort.SliceStable(options, func(i, j int) bool {
+		if options[i].Amount.Amount != options[j].Amount.Amount {
+			return options[i].Amount.Amount < options[j].Amount.Amount
+		}
+		return options[i].Name < options[j].Name
+	})
+	return options, nil
+}
+
+// MatchZone returns the zone with the longest postcode prefix that postcode starts with; a
+// country-wide zone has the empty prefix and matches every postcode. It returns nil when none applies.
+func MatchZone(zones []*model.ShippingZone, postcode string) *model.ShippingZone {
+	postcode = model.NormalizePostcode(postcode)
+	var best *model.ShippingZone
+	for _, zone := range zones {
+		if !strings.HasPrefix(postcode, zone.PostcodePrefix) {
+			continue
+		}
+		if best == nil || len(zone.PostcodePrefix) > len(best.PostcodePrefix) {
+			best = zone
+		}
+	}
+	return best
+}
+
+// BillableWeight returns the grams a line is charged for: the larger of its actual and volumetric
+// weight, times its quantity
+func BillableWeight(line model.ShippableLine) int {
+	volumetric := int(math.Ceil(line.LengthCm * line.WidthCm * line.HeightCm / VolumetricDivisor))
+	unit := line.WeightGrams
+	if volumetric > unit {
+		unit = volumetric
+	}
+	return unit * line.Quantity
+}
+
+// Price returns what a rate charges for merchandise worth value: nothing once value reaches the
+// rate's free-shipping threshold, its price otherwise
+func Price(rate *model.ShippingRate, value money.Money) (money.Money, error) {
+	if !rate.FreeOver.IsZero() {
+		cmp, err := value.Cmp(rate.FreeOver)
+		if err != nil {
+			return money.Money{}, err
+		}
+		if cmp >= 0 {
+			return money.Zero(rate.Price.Currency), nil
+		}
+	}
+	return rate.Price, nil
+}
+
+// Ensure tableRate implements types.ShippingRateProvider
+var _ types.ShippingRateProvider = (*tableRate)(nil)
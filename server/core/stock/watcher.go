@@ -0,0 +1,83 @@
+// Package stock watches stock levels: the stores below tell a Watcher about every stock unit whose
+// inventory changes through them, so every service that changes stock is watched
+package stock
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"oms/server/core/model"
+	"oms/server/core/types"
+)
+
+// Watcher is told about every change to a stock unit's inventory, after it is saved. Check must not
+// fail the change, so it handles its own errors.
+type Watcher interface {
+	Check(ctx context.Context, stockUnitID uuid.UUID)
+}
+
+// inventoryStore tells a Watcher about quantity and reorder point changes
+type inventoryStore struct {
+	types.InventoryStore
+	watcher Watcher
+}
+
+// NewInventoryStore wraps store to tell watcher about the stock units whose inventory changes
+func NewInventoryStore(store types.InventoryStore, watcher Watcher) types.InventoryStore {
+	return &inventoryStore{InventoryStore: store, watcher: watcher}
+}
+
+func (s *inventoryStore) DecrementQuantity(ctx context.Context, productID uuid.UUID, quantity int) error {
+	if err := s.InventoryStore.DecrementQuantity(ctx, productID, quantity); err != nil {
+		return err
+	}
+	s.watcher.Check(ctx, productID)
+	return nil
+}
+
+func (s *inventoryStore) IncrementQuantity(ctx context.Context, productID uuid.UUID, quantity int) error {
+	if err := s.InventoryStore.IncrementQuantity(ctx, productID, quantity); err != nil {
+		return err
+	}
+	s.watcher.Check(ctx, productID)
+	return nil
+}
+
+func (s *inventoryStore) UpdateQuantity(ctx context.Context, productID uuid.UUID, quantity int) error {
+	if err := s.InventoryStore.UpdateQuantity(ctx, productID, quantity); err != nil {
+		return err
+	}
+	s.watcher.Check(ctx, productID)
+	return nil
+}
+
+func (s *inventoryStore) UpdateReorderPoint(ctx context.Context, productID uuid.UUID, reorderPoint, targetLevel *int) error {
+	if err := s.InventoryStore.UpdateReorderPoint(ctx, productID, reorderPoint, targetLevel); err != nil {
+		return err
+	}
+	s.watcher.Check(ctx, productID)
+	return nil
+}
+
+// productImportStore tells a Watcher about the stock units an import sets the quantity of
+type productImportStore struct {
+	types.ProductImportStore
+	watcher Watcher
+}
+
+// NewProductImportStore wraps store to tell watcher about the stock units whose quantity an import sets
+func NewProductImportStore(store types.ProductImportStore, watcher Watcher) types.ProductImportStore {
+	return &productImportStore{ProductImportStore: store, watcher: watcher}
+}
+
+func (s *productImportStore) Apply(ctx context.Context, rows []*model.ProductImportRow) error {
+	if err := s.ProductImportStore.Apply(ctx, rows); err != nil {
+		return err
+	}
+	for _, row := range rows {
+		if row.Quantity != nil {
+			s.watcher.Check(ctx, row.Product.ID)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,114 @@
+package tax
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"oms/server/core/model"
+	"oms/server/core/money"
+	"oms/server/core/types"
+)
+
+// ruleTable implements types.TaxCalculator from the admin-managed tax rules
+type ruleTable struct {
+	ruleStore types.TaxRuleStore
+}
+
+// NewRuleTable creates a TaxCalculator that looks up rates by country, region and tax class
+func NewRuleTable(ruleStore types.TaxRuleStore) types.TaxCalculator {
+	return &ruleTable{ruleStore: ruleStore}
+}
+
+// Calculate implements types.TaxCalculator. Orders without a destination country, and lines
+// no rule matches, are not taxed.
+func (t *ruleTable) Calculate(ctx context.Context, lines []model.TaxableLine, address model.TaxAddress) ([]model.OrderTaxLine, error) {
+	taxLines := []model.OrderTaxLine{}
+	if address.Country == "" {
+		return taxLines, nil
+	}
+	rules, err := t.ruleStore.GetByCountry(ctx, address.Country)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load tax rules: %w", err)
+	}
+
+	for _, line := range lines {
+		if line.Amount.IsZero() {
+			continue
+		}
+		rule := Match(rules, address.Region, line.TaxClass)
+		if rule == nil {
+			continue
+		}
+		amount, err := Compute(line.Amount, rule.Rate, rule.Inclusive)
+		if err != nil {
+			return nil, fmt.Errorf("tax rule %s: %w", rule.Name, err)
+		}
+		ruleID := rule.ID
+		taxLines = append(taxLines, model.OrderTaxLine{
+			RuleID:    &ruleID,
+			Name:      rule.Name,
+			TaxClass:  line.TaxClass,
+			Rate:      rule.Rate,
+			Inclusive: rule.Inclusive,
+			Taxable:   line.Amount,
+			Amount:    amount,
+		})
+	}
+	return taxLines, nil
+}
+
+// Match returns the most specific rule for a region and tax class: a regional rule beats a
+// country-wide one, and a rule for the class beats one for every class. It returns nil when none applies.
+func Match(rules []*model.TaxRule, region, taxClass string) *model.TaxRule {
+	var best *model.TaxRule
+	bestScore := -1
+	for _, rule := range rules {
+		score := 0
+		switch rule.Region {
+		case "":
+		case region:
+			score += 2
+		default:
+			continue
+		}
+		switch rule.TaxClass {
+		case "":
+		case taxClass:
+			score++
+		default:
+			continue
+		}
+		if score > bestScore {
+			best, bestScore = rule, score
+		}
+	}
+	return best
+}
+
+// Compute returns the tax on amount at a percentage rate, rounded half up to minor units.
+// For inclusive rates the amount already contains the tax, which is amount * rate / (100 + rate).
+func Compute(amount money.Money, rate string, inclusive bool) (money.Money, error) {
+	percent, err := ParseRate(rate)
+	if err != nil {
+		return money.Money{}, err
+	}
+	fraction := new(big.Rat).Quo(percent, big.NewRat(100, 1))
+	if inclusive {
+		fraction = new(big.Rat).Quo(percent, new(big.Rat).Add(percent, big.NewRat(100, 1)))
+	}
+	return amount.MulRat(fraction, money.RoundHalfUp), nil
+}
+
+// ParseRate parses a percentage rate such as "8.25"; rates must be between 0 and 100
+func ParseRate(rate string) (*big.Rat, error) {
+	percent, ok := new(big.Rat).SetString(strings.TrimSpace(rate))
+	if !ok || percent.Sign() < 0 || percent.Cmp(big.NewRat(100, 1)) > 0 {
+		return nil, fmt.Errorf("%w: tax rate %q must be a percentage between 0 and 100", money.ErrInvalidAmount, rate)
+	}
+	return percent, nil
+}
+
+// Ensure ruleTable implements types.TaxCalculator
+var _ types.TaxCalculator = (*ruleTable)(nil)
@@ -2,42 +2,272 @@ package types
 
 import (
 	"context"
+	"time"
 
 	"github.com/google/uuid"
 	"oms/server/core/model"
+	"oms/server/core/money"
 )
 
 // OrderStore defines the interface for order data access
 type OrderStore interface {
 	Create(ctx context.Context, order *model.Order) error
+	CreateAll(ctx context.Context, orders []*model.Order) error // Creates every order or none
 	GetByID(ctx context.Context, orderID uuid.UUID) (*model.Order, error)
 	GetByUserID(ctx context.Context, userID int) ([]*model.Order, error)
 	GetAll(ctx context.Context) ([]*model.Order, error)
-	UpdateStatus(ctx context.Context, orderID uuid.UUID, status model.OrderStatus) error
+	Search(ctx context.Context, filter model.OrderFilter) ([]*model.Order, error) // Newest first
+	// Each calls fn with the orders matching filter, oldest first, in batches of at most batchSize so
+	// large exports are not held in memory. Each order's product, even if since deleted, is loaded
+	// when withProduct is set. It stops at the first error fn returns.
+	Each(ctx context.Context, filter model.OrderFilter, batchSize int, withProduct bool, fn func(orders []*model.Order) error) error
+	GetByStatus(ctx context.Context, statuses ...model.OrderStatus) ([]*model.Order, error) // Oldest first
+	// UpdateStatus moves the order to status, but only while its stored status is still previous.
+	// It reports whether the change was applied.
+	UpdateStatus(ctx context.Context, orderID uuid.UUID, previous, status model.OrderStatus) (bool, error)
+	GetWithoutPriceSnapshot(ctx context.Context) ([]*model.Order, error) // Orders placed before price snapshots existed
+	UpdatePricing(ctx context.Context, order *model.Order) error         // Writes the product snapshot and totals
+	// CancelQuantity adds quantity to the order's cancelled units, checked with the order row locked
+	// against the units still open. It reports whether the units were cancelled.
+	CancelQuantity(ctx context.Context, orderID uuid.UUID, quantity int) (bool, error)
 }
 
-// InventoryStore defines the interface for inventory data access
+// InventoryStore defines the interface for inventory data access.
+// Inventory is keyed by stock unit: the product ID, or the variant ID for products with variants.
 type InventoryStore interface {
 	GetByProductID(ctx context.Context, productID uuid.UUID) (*model.Inventory, error)
 	LockForUpdate(ctx context.Context, productID uuid.UUID) (*model.Inventory, error)
 	DecrementQuantity(ctx context.Context, productID uuid.UUID, quantity int) error
 	IncrementQuantity(ctx context.Context, productID uuid.UUID, quantity int) error
 	UpdateQuantity(ctx context.Context, productID uuid.UUID, quantity int) error // Admin: Set inventory quantity
+	UpdateBinLocation(ctx context.Context, productID uuid.UUID, binLocation string) error // Admin: Set where the stock unit is shelved
+	UpdateReorderPoint(ctx context.Context, productID uuid.UUID, reorderPoint, targetLevel *int) error // Admin: Set, or with nil clear, when the stock unit is low and what to restock it to
 }
 
 // ProductStore defines the interface for product data access
 type ProductStore interface {
 	GetByID(ctx context.Context, productID uuid.UUID) (*model.Product, error)
 	GetAll(ctx context.Context) ([]*model.Product, error)
+	GetWithStock(ctx context.Context, productID uuid.UUID) (*model.ProductWithStock, error)
+	Search(ctx context.Context, query model.ProductQuery, after *model.ProductCursor) ([]*model.ProductWithStock, error) // Joins inventory; returns at most query.Limit rows after the cursor
 	Create(ctx context.Context, product *model.Product) error // Admin: Create new product
 	Update(ctx context.Context, productID uuid.UUID, product *model.Product) error // Admin: Update product
 	Delete(ctx context.Context, productID uuid.UUID) error // Admin: Delete product (soft delete)
 }
 
+// ProductVariantStore defines the interface for product variant data access
+type ProductVariantStore interface {
+	GetByID(ctx context.Context, variantID uuid.UUID) (*model.ProductVariant, error)
+	GetByProductID(ctx context.Context, productID uuid.UUID) ([]*model.ProductVariant, error)
+	GetWithStockByProductIDs(ctx context.Context, productIDs []uuid.UUID) ([]*model.ProductVariantWithStock, error) // Joins inventory for a page of products in one query
+	Create(ctx context.Context, variant *model.ProductVariant) error // Admin: Create variant
+	Update(ctx context.Context, variant *model.ProductVariant) error // Admin: Update SKU, options and price override
+	Delete(ctx context.Context, variantID uuid.UUID) error           // Admin: Delete variant (soft delete)
+}
+
+// CategoryStore defines the interface for category and product-category data access
+type CategoryStore interface {
+	GetByID(ctx context.Context, categoryID uuid.UUID) (*model.Category, error)
+	GetBySlug(ctx context.Context, slug string) (*model.Category, error)
+	GetAll(ctx context.Context) ([]*model.Category, error)
+	Create(ctx context.Context, category *model.Category) error
+	Update(ctx context.Context, category *model.Category) error
+	Delete(ctx context.Context, categoryID uuid.UUID) error // Also removes product associations
+	GetByProductID(ctx context.Context, productID uuid.UUID) ([]*model.Category, error)
+	SetProductCategories(ctx context.Context, productID uuid.UUID, categoryIDs []uuid.UUID) error // Replaces all associations
+}
+
+// MetadataSchemaStore defines the interface for metadata JSON Schema data access
+type MetadataSchemaStore interface {
+	GetByID(ctx context.Context, schemaID uuid.UUID) (*model.MetadataSchema, error)
+	GetAll(ctx context.Context) ([]*model.MetadataSchema, error)
+	GetByTarget(ctx context.Context, target model.SchemaTarget) ([]*model.MetadataSchema, error)
+	Create(ctx context.Context, schema *model.MetadataSchema) error
+	Update(ctx context.Context, schema *model.MetadataSchema) error
+	Delete(ctx context.Context, schemaID uuid.UUID) error
+}
+
+// PromotionStore defines the interface for promotion and redemption data access
+type PromotionStore interface {
+	GetByID(ctx context.Context, promotionID uuid.UUID) (*model.Promotion, error)
+	GetByCode(ctx context.Context, code string) (*model.Promotion, error)
+	GetAll(ctx context.Context) ([]*model.Promotion, error)
+	GetAutomatic(ctx context.Context) ([]*model.Promotion, error) // Active promotions without a code
+	Create(ctx context.Context, promotion *model.Promotion) error
+	Update(ctx context.Context, promotion *model.Promotion) error
+	Delete(ctx context.Context, promotionID uuid.UUID) error
+	CountUserRedemptions(ctx context.Context, promotionID uuid.UUID, userID int) (int, error)
+	// Redeem records the redemptions and increments each promotion's count in one transaction.
+	// It returns the promotion that ran out when a usage or per-user limit is reached.
+	Redeem(ctx context.Context, redemptions []*model.PromotionRedemption) (exhausted *model.Promotion, err error)
+	ReleaseRedemptions(ctx context.Context, orderID uuid.UUID) error // Undoes an order's redemptions
+}
+
+// TaxRuleStore defines the interface for tax rule data access
+type TaxRuleStore interface {
+	GetByID(ctx context.Context, ruleID uuid.UUID) (*model.TaxRule, error)
+	GetAll(ctx context.Context) ([]*model.TaxRule, error)
+	GetByCountry(ctx context.Context, country string) ([]*model.TaxRule, error)
+	Create(ctx context.Context, rule *model.TaxRule) error
+	Update(ctx context.Context, rule *model.TaxRule) error
+	Delete(ctx context.Context, ruleID uuid.UUID) error
+}
+
+// TaxCalculator computes the tax on an order's lines for its shipping address.
+// Line amounts are net of discounts; inclusive tax lines report the tax contained in the amount.
+type TaxCalculator interface {
+	Calculate(ctx context.Context, lines []model.TaxableLine, address model.TaxAddress) ([]model.OrderTaxLine, error)
+}
+
+// ShippingZoneStore defines the interface for shipping zone data access. Zones are returned with their rates.
+type ShippingZoneStore interface {
+	GetByID(ctx context.Context, zoneID uuid.UUID) (*model.ShippingZone, error)
+	GetAll(ctx context.Context) ([]*model.ShippingZone, error)
+	GetByCountry(ctx context.Context, country string) ([]*model.ShippingZone, error)
+	Create(ctx context.Context, zone *model.ShippingZone) error
+	Update(ctx context.Context, zone *model.ShippingZone) error // Replaces the zone's rates
+	Delete(ctx context.Context, zoneID uuid.UUID) error
+}
+
+// ShippingRateProvider quotes the ways an order's lines can be shipped to an address.
+// It returns no options when nothing ships there.
+type ShippingRateProvider interface {
+	Quote(ctx context.Context, lines []model.ShippableLine, address model.ShippingAddress) ([]model.ShippingOption, error)
+}
+
+// CartStore defines the interface for cart data access. Carts are returned with their lines.
+type CartStore interface {
+	GetByID(ctx context.Context, cartID uuid.UUID) (*model.Cart, error)
+	GetByUserID(ctx context.Context, userID int) (*model.Cart, error)
+	GetByToken(ctx context.Context, token string) (*model.Cart, error)
+	Create(ctx context.Context, cart *model.Cart) error
+	Touch(ctx context.Context, cartID uuid.UUID, expiresAt time.Time) error // Pushes back the expiry
+	SaveLine(ctx context.Context, line *model.CartLine) error                // Creates the line or updates its quantity
+	DeleteLine(ctx context.Context, cartID, lineID uuid.UUID) error
+	ClearLines(ctx context.Context, cartID uuid.UUID) error
+	// TakeLines removes a cart's lines and returns them, with the cart row locked so that of
+	// concurrent calls only the first gets them
+	TakeLines(ctx context.Context, cartID uuid.UUID) ([]model.CartLine, error)
+	RestoreLines(ctx context.Context, cartID uuid.UUID, lines []model.CartLine) error // Puts taken lines back, adding up quantities
+	Merge(ctx context.Context, fromCartID, intoCartID uuid.UUID) error // Moves lines across, adding up quantities, and deletes the source cart
+	DeleteExpired(ctx context.Context, now time.Time) (int64, error)
+}
+
+// PaymentStore defines the interface for payment data access. Payments are returned with their history.
+type PaymentStore interface {
+	Create(ctx context.Context, payment *model.Payment) error // Also records the initial status in the history
+	GetByID(ctx context.Context, paymentID uuid.UUID) (*model.Payment, error)
+	GetByReference(ctx context.Context, provider, reference string) (*model.Payment, error)
+	GetByOrderID(ctx context.Context, orderID uuid.UUID) ([]*model.Payment, error) // Newest first
+	// UpdateStatus saves the payment's status, reference, amounts and failure reason and appends entry
+	// to its history, but only while the stored status is still entry.PreviousStatus and entry's
+	// event has not been recorded. It reports whether the change was applied.
+	UpdateStatus(ctx context.Context, payment *model.Payment, entry *model.PaymentStateLog) (bool, error)
+	// ForgetEventsBefore clears the event IDs of history entries older than before, so their
+	// webhooks are no longer recognized as delivered. The history is kept.
+	ForgetEventsBefore(ctx context.Context, before time.Time) (int64, error)
+}
+
+// ShipmentStore defines the interface for shipment data access
+type ShipmentStore interface {
+	// Create records the shipment and adds its quantity to the order's shipped units, checked with the
+	// order row locked against the units still open. It reports whether the shipment was created.
+	Create(ctx context.Context, shipment *model.Shipment) (bool, error)
+	GetByOrderID(ctx context.Context, orderID uuid.UUID) ([]*model.Shipment, error) // Oldest first, with their tracking events
+	GetByTrackingNumber(ctx context.Context, carrier, trackingNumber string) (*model.Shipment, error)
+	// RecordEvent adds a tracking event to the shipment with the shipment row locked, and moves the
+	// shipment to the event's status unless a later event has already been applied; a delivered
+	// shipment stays delivered. shipment is refreshed from the stored row. It reports whether the
+	// event was new.
+	RecordEvent(ctx context.Context, shipment *model.Shipment, event *model.TrackingEvent) (bool, error)
+	// DeleteEventsDeliveredBefore deletes the tracking events of shipments delivered before before.
+	// The shipments keep their delivered status, which later events can't change.
+	DeleteEventsDeliveredBefore(ctx context.Context, before time.Time) (int64, error)
+}
+
+// ReturnRequestStore defines the interface for return request data access
+type ReturnRequestStore interface {
+	// Create records the return unless the order already has an open return or the quantity exceeds
+	// what is left to return, checked with the order row locked. It reports whether it was created.
+	Create(ctx context.Context, request *model.ReturnRequest) (bool, error)
+	GetByID(ctx context.Context, returnID uuid.UUID) (*model.ReturnRequest, error)
+	GetByOrderID(ctx context.Context, orderID uuid.UUID) ([]*model.ReturnRequest, error) // Oldest first
+	// UpdateStatus saves the return's status, quantities, refund amount, note and handler, but only
+	// while the stored status is still previous. It reports whether the change was applied.
+	UpdateStatus(ctx context.Context, request *model.ReturnRequest, previous model.ReturnStatus) (bool, error)
+}
+
+// PaymentProvider is a payment gateway. Declines are results; operations that fail in transit return
+// an error. An error wrapping context.DeadlineExceeded means the outcome is unknown, and the provider
+// reports it later through a webhook.
+type PaymentProvider interface {
+	Name() string
+	Authorize(ctx context.Context, req model.PaymentRequest) (model.PaymentResult, error)
+	Capture(ctx context.Context, payment *model.Payment, amount money.Money) (model.PaymentResult, error)
+	Void(ctx context.Context, payment *model.Payment) (model.PaymentResult, error)
+	Refund(ctx context.Context, payment *model.Payment, amount money.Money) (model.PaymentResult, error)
+	// ParseWebhook verifies a webhook's signature and decodes its event
+	ParseWebhook(payload []byte, signature string) (*model.PaymentEvent, error)
+}
+
 // OrderStateLogStore defines the interface for order state log data access
 type OrderStateLogStore interface {
 	Create(ctx context.Context, log *model.OrderStateLog) error
-	GetByOrderID(ctx context.Context, orderID uuid.UUID) ([]*model.OrderStateLog, error)
+	// GetByOrderID retrieves the order's history, oldest first, narrowed by filter
+	GetByOrderID(ctx context.Context, orderID uuid.UUID, filter model.OrderHistoryFilter) ([]*model.OrderStateLog, error)
+	GetByOrderIDs(ctx context.Context, orderIDs []uuid.UUID) ([]*model.OrderStateLog, error) // Every entry of the orders, oldest first
+}
+
+// AuditStore defines the interface for audit log data access
+type AuditStore interface {
+	Create(ctx context.Context, entry *model.AuditEntry) error
+	Search(ctx context.Context, filter model.AuditFilter) ([]*model.AuditEntry, error) // Newest first
+	// Each calls fn for every entry matching filter, newest first, reading them in batches so large
+	// exports are not held in memory. It stops at the first error fn returns.
+	Each(ctx context.Context, filter model.AuditFilter, fn func(entry *model.AuditEntry) error) error
+}
+
+// AnalyticsStore defines the interface for sales analytics, aggregated in the database
+type AnalyticsStore interface {
+	Sales(ctx context.Context, r model.AnalyticsRange, interval model.AnalyticsInterval) ([]*model.SalesPoint, error) // By period, then currency
+	TopProducts(ctx context.Context, r model.AnalyticsRange, limit int) ([]*model.ProductSales, error)                // Most units sold first
+	Funnel(ctx context.Context, r model.AnalyticsRange) (*model.OrderFunnel, error)
+	FulfillmentTimes(ctx context.Context, r model.AnalyticsRange) (*model.FulfillmentTimes, error)
+	// RollUp recomputes the hourly sales rollups of the UTC days from from up to to, or from the day
+	// of the first order when from is zero. It returns the number of rollup rows written.
+	RollUp(ctx context.Context, from, to time.Time) (int64, error)
+	RolledUpThrough(ctx context.Context) (time.Time, error) // End of the rolled up days; zero when there are none
+}
+
+// JobRunStore defines the interface for the run history of scheduled jobs
+type JobRunStore interface {
+	// Start records run as started. It reports false, recording nothing, when the job already has
+	// a run for the same scheduled time.
+	Start(ctx context.Context, run *model.JobRun) (bool, error)
+	Finish(ctx context.Context, run *model.JobRun) error // Saves the run's status, result and finish time
+	GetRecent(ctx context.Context, limit int) ([]*model.JobRun, error) // Newest first
+	DeleteFinishedBefore(ctx context.Context, before time.Time) (int64, error)
+}
+
+// ExportJobStore defines the interface for background export job data access
+type ExportJobStore interface {
+	Create(ctx context.Context, job *model.ExportJob) error
+	GetByID(ctx context.Context, jobID uuid.UUID) (*model.ExportJob, error)
+	// Claim marks the oldest queued job as running on instance and returns it, or nil when no job is
+	// queued. Instances claiming at the same time get different jobs.
+	Claim(ctx context.Context, instance string) (*model.ExportJob, error)
+	Finish(ctx context.Context, job *model.ExportJob) error // Saves the job's status, counts, error and completion and expiry times
+	// FailRunning fails the jobs left running by the instances whose names start with prefix, such as
+	// this host's earlier processes, and returns how many were failed
+	FailRunning(ctx context.Context, prefix, message string, expiresAt time.Time) (int64, error)
+	GetExpired(ctx context.Context, before time.Time) ([]*model.ExportJob, error)
+	Delete(ctx context.Context, jobID uuid.UUID) error
+}
+
+// JobLocker elects the one worker replica that runs a job at a time
+type JobLocker interface {
+	// TryLock takes the named lock without waiting. When acquired, release must be called to give it up.
+	TryLock(ctx context.Context, name string) (release func(), acquired bool, err error)
 }
 
 // FSMValidator defines the interface for FSM validation
@@ -45,6 +275,8 @@ type FSMValidator interface {
 	ValidateTransition(currentStatus, newStatus model.OrderStatus) error
 	IsValidStatus(status model.OrderStatus) bool
 	RequiresInventoryRestore(status model.OrderStatus) bool
+	RequiresPaymentCapture(status model.OrderStatus) bool
+	RequiresPaymentRelease(status model.OrderStatus) bool
 }
 
 // UserStore defines the interface for user data access
@@ -54,3 +286,35 @@ type UserStore interface {
 	GetByUsername(ctx context.Context, username string) (*model.User, error)
 }
 
+
+// ProductImportStore defines the interface for the data access of product imports, which create and
+// update many products and their stock at once
+type ProductImportStore interface {
+	GetBySKUs(ctx context.Context, skus []string) ([]*model.Product, error)               // Includes deleted products
+	GetInventory(ctx context.Context, productIDs []uuid.UUID) ([]*model.Inventory, error) // Only the stock units that have an inventory row
+	// Apply saves the rows in one transaction: it creates the new products with their inventory,
+	// which starts empty unless the row sets it, and updates the others, restoring deleted ones
+	Apply(ctx context.Context, rows []*model.ProductImportRow) error
+}
+
+// StockAlertStore defines the interface for low-stock alert data access
+type StockAlertStore interface {
+	// Open records the alert unless its stock unit has one that isn't resolved, and reports whether
+	// it did. Concurrent calls for the same stock unit open one alert.
+	Open(ctx context.Context, alert *model.StockAlert) (bool, error)
+	// ResolveOpen resolves the stock unit's alert that isn't resolved, if any, and returns how many it resolved
+	ResolveOpen(ctx context.Context, productID uuid.UUID, at time.Time) (int64, error)
+	GetByID(ctx context.Context, alertID uuid.UUID) (*model.StockAlert, error)
+	Search(ctx context.Context, filter model.StockAlertFilter) ([]*model.StockAlert, error) // Newest first
+	Update(ctx context.Context, alert *model.StockAlert) error                              // Saves the status, acknowledgement and resolution
+	// GetLowStock retrieves the stock units at or below their reorder point, lowest against it
+	// first, with their product or variant; only the ones in stockUnitIDs unless it is nil.
+	// Stock of deleted products and variants is left out.
+	GetLowStock(ctx context.Context, stockUnitIDs []uuid.UUID) ([]*model.LowStockItem, error)
+}
+
+// StockAlertNotifier is told about new low-stock alerts, e.g. by writing to the log, sending an
+// email or calling a webhook. item describes the stock unit as it is.
+type StockAlertNotifier interface {
+	Notify(ctx context.Context, alert *model.StockAlert, item *model.LowStockItem) error
+}
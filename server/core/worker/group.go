@@ -0,0 +1,112 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+)
+
+// State represents the lifecycle state of a background worker
+type State string
+
+const (
+	StateRunning State = "running"
+	StateStopped State = "stopped"
+	StateFailed  State = "failed"
+)
+
+// Status describes the current state of a single worker
+type Status struct {
+	Name  string `json:"name"`
+	State State  `json:"state"`
+	Error string `json:"error,omitempty"`
+}
+
+// Group runs named background workers that share a cancellation context,
+// so they can all be stopped together during shutdown
+type Group struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	mu       sync.RWMutex
+	statuses map[string]*Status
+}
+
+// NewGroup creates a new, empty worker group
+func NewGroup() *Group {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Group{
+		ctx:      ctx,
+		cancel:   cancel,
+		statuses: make(map[string]*Status),
+	}
+}
+
+// Go starts fn in its own goroutine. fn must return when ctx is cancelled.
+// A worker that returns a non-nil error before the group is stopped is marked failed.
+func (g *Group) Go(name string, fn func(ctx context.Context) error) {
+	g.mu.Lock()
+	g.statuses[name] = &Status{Name: name, State: StateRunning}
+	g.mu.Unlock()
+
+	g.wg.Add(1)
+	go func() {
+		defer g.wg.Done()
+		err := fn(g.ctx)
+
+		g.mu.Lock()
+		defer g.mu.Unlock()
+		status := g.statuses[name]
+		if err != nil && g.ctx.Err() == nil {
+			status.State = StateFailed
+			status.Error = err.Error()
+			log.Printf("Worker %s failed: %v", name, err)
+			return
+		}
+		status.State = StateStopped
+	}()
+}
+
+// Stop cancels all workers and waits for them to return or for ctx to expire
+func (g *Group) Stop(ctx context.Context) error {
+	g.cancel()
+
+	done := make(chan struct{})
+	go func() {
+		g.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("timed out waiting for workers to stop: %w", ctx.Err())
+	}
+}
+
+// Statuses returns a snapshot of all worker statuses sorted by name
+func (g *Group) Statuses() []Status {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	statuses := make([]Status, 0, len(g.statuses))
+	for _, status := range g.statuses {
+		statuses = append(statuses, *status)
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Name < statuses[j].Name })
+	return statuses
+}
+
+// Healthy returns an error if any worker has failed
+func (g *Group) Healthy() error {
+	for _, status := range g.Statuses() {
+		if status.State == StateFailed {
+			return fmt.Errorf("worker %s failed: %s", status.Name, status.Error)
+		}
+	}
+	return nil
+}
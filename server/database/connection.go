@@ -1,8 +1,10 @@
 package database
 
 import (
+	"context"
 	"fmt"
 	"log"
+	"sync"
 	"time"
 
 	"gorm.io/driver/postgres"
@@ -40,9 +42,10 @@ func Connect(cfg *config.Config) (*gorm.DB, error) {
 	}
 
 	// Set connection pool settings
-	sqlDB.SetMaxIdleConns(10)
-	sqlDB.SetMaxOpenConns(100)
-	sqlDB.SetConnMaxLifetime(time.Hour)
+	sqlDB.SetMaxIdleConns(cfg.Database.MaxIdleConns)
+	sqlDB.SetMaxOpenConns(cfg.Database.MaxOpenConns)
+	sqlDB.SetConnMaxLifetime(cfg.Database.ConnMaxLifetime)
+	sqlDB.SetConnMaxIdleTime(cfg.Database.ConnMaxIdleTime)
 
 	// Test connection
 	if err := sqlDB.Ping(); err != nil {
@@ -54,17 +57,50 @@ func Connect(cfg *config.Config) (*gorm.DB, error) {
 	return db, nil
 }
 
-// AutoMigrate runs GORM auto-migration for all models
-func AutoMigrate(db *gorm.DB) error {
-	log.Println("Running database migrations...")
-
-	err := db.AutoMigrate(
+// Models returns all models managed by auto-migration, in dependency order
+func Models() []interface{} {
+	return []interface{}{
 		&model.User{},
 		&model.Product{},
+		&model.ProductVariant{},
+		&model.Category{},
+		&model.ProductCategory{},
+		&model.MetadataSchema{},
+		&model.Promotion{},
+		&model.PromotionRedemption{},
+		&model.TaxRule{},
+		&model.ShippingZone{},
+		&model.ShippingRate{},
 		&model.Inventory{},
 		&model.Order{},
+		&model.OrderDiscount{},
+		&model.OrderTaxLine{},
+		&model.Cart{},
+		&model.CartLine{},
+		&model.Shipment{},
+		&model.TrackingEvent{},
+		&model.Payment{},
+		&model.PaymentStateLog{},
+		&model.ReturnRequest{},
 		&model.OrderStateLog{},
-	)
+		&model.JobRun{},
+		&model.AuditEntry{},
+		&model.SalesRollup{},
+		&model.SalesRollupDay{},
+		&model.ExportJob{},
+		&model.StockAlert{},
+	}
+}
+
+// AutoMigrate upgrades data kept by older schemas, then runs GORM auto-migration for all models
+func AutoMigrate(db *gorm.DB) error {
+	log.Println("Running database migrations...")
+
+	if err := runUpgrades(db); err != nil {
+		return fmt.Errorf("failed to upgrade the schema: %w", err)
+	}
+
+	err := db.AutoMigrate(Models()...)
 	if err != nil {
 		return fmt.Errorf("failed to run auto-migration: %w", err)
 	}
@@ -73,3 +109,87 @@ func AutoMigrate(db *gorm.DB) error {
 	return nil
 }
 
+
+// PendingMigrations returns the tables and columns that exist on the models
+// but not yet in the database, i.e. what AutoMigrate would still create
+func PendingMigrations(ctx context.Context, db *gorm.DB) ([]string, error) {
+	migrator := db.WithContext(ctx).Migrator()
+
+	var pending []string
+	for _, m := range Models() {
+		stmt := &gorm.Statement{DB: db}
+		if err := stmt.Parse(m); err != nil {
+			return nil, fmt.Errorf("failed to parse model schema: %w", err)
+		}
+		table := stmt.Schema.Table
+
+		if !migrator.HasTable(m) {
+			pending = append(pending, table)
+			continue
+		}
+		for _, field := range stmt.Schema.Fields {
+			if field.DBName == "" || field.IgnoreMigration {
+				continue
+			}
+			if !migrator.HasColumn(m, field.DBName) {
+				pending = append(pending, table+"."+field.DBName)
+			}
+		}
+	}
+	return pending, nil
+}
+
+// MigrationStatus caches PendingMigrations, which takes a catalog query per table and column, so
+// readiness probes can ask for it as often as they like. Once nothing is pending the result is kept,
+// since a migrated schema stays migrated; until then it is checked again after the TTL, so an
+// instance turns ready soon after the migrations are run. Errors are not cached.
+type MigrationStatus struct {
+	db  *gorm.DB
+	ttl time.Duration
+
+	mu        sync.Mutex
+	checkedAt time.Time
+	checked   bool
+	pending   []string
+}
+
+// NewMigrationStatus creates a MigrationStatus that checks db again at most once per ttl
+func NewMigrationStatus(db *gorm.DB, ttl time.Duration) *MigrationStatus {
+	return &MigrationStatus{db: db, ttl: ttl}
+}
+
+// Pending returns the pending migrations as of the last check, checking again when it is stale
+func (s *MigrationStatus) Pending(ctx context.Context) ([]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.checked && (len(s.pending) == 0 || time.Since(s.checkedAt) < s.ttl) {
+		return s.pending, nil
+	}
+	pending, err := PendingMigrations(ctx, s.db)
+	if err != nil {
+		return nil, err
+	}
+	s.pending = pending
+	s.checked = true
+	s.checkedAt = time.Now()
+	return pending, nil
+}
+
+// Ping checks that the database is reachable
+func Ping(ctx context.Context, db *gorm.DB) error {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return fmt.Errorf("failed to get database instance: %w", err)
+	}
+	return sqlDB.PingContext(ctx)
+}
+
+// Close closes the underlying connection pool
+func Close(db *gorm.DB) error {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return fmt.Errorf("failed to get database instance: %w", err)
+	}
+	return sqlDB.Close()
+}
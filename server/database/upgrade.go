@@ -0,0 +1,166 @@
+package database
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// upgrade moves data an older schema kept differently into the current models. AutoMigrate only
+// adds what is missing, so anything it can't express, such as a backfill or a column changing
+// meaning, is an upgrade. Each one checks whether the schema still needs it, which makes running
+// the migrations again harmless.
+type upgrade struct {
+	name    string
+	pending func(m gorm.Migrator) bool
+	apply   func(tx *gorm.DB) error
+}
+
+// upgrades run in order, each in a transaction, before AutoMigrate adds the rest of the schema
+var upgrades = []upgrade{
+	{
+		// Inventory rows were keyed by product_id alone, which held the variant ID for variants
+		name: "split inventory stock units into product and variant",
+		pending: func(m gorm.Migrator) bool {
+			return m.HasTable("inventory") && !m.HasColumn("inventory", "stock_unit_id")
+		},
+		apply: upgradeInventoryStockUnits,
+	},
+	{
+		// Product prices were a decimal price column, in dollars
+		name: "convert product prices to minor units",
+		pending: func(m gorm.Migrator) bool {
+			return m.HasTable("products") && m.HasColumn("products", "price")
+		},
+		apply: execAll(
+			`ALTER TABLE products ADD COLUMN IF NOT EXISTS price_amount BIGINT NOT NULL DEFAULT 0`,
+			`ALTER TABLE products ADD COLUMN IF NOT EXISTS price_currency VARCHAR(3) NOT NULL DEFAULT 'USD'`,
+			`UPDATE products SET price_amount = ROUND(price * 100)::BIGINT, price_currency = 'USD'`,
+			`ALTER TABLE products DROP COLUMN price`,
+		),
+	},
+	{
+		// Variant price overrides were decimal too; they are now stored as money text, e.g. "12.99 USD"
+		name: "convert variant prices to money",
+		pending: func(m gorm.Migrator) bool {
+			return strings.EqualFold(columnType(m, "product_variants", "price"), "numeric")
+		},
+		apply: execAll(
+			`ALTER TABLE product_variants ALTER COLUMN price TYPE VARCHAR(32)
+				USING CASE WHEN price IS NULL THEN NULL ELSE TO_CHAR(price, 'FM99999999990.00') || ' USD' END`,
+		),
+	},
+	{
+		// Orders didn't count shipped units; those that had shipped, shipped in full
+		name: "count the shipped units of orders",
+		pending: func(m gorm.Migrator) bool {
+			return m.HasTable("orders") && !m.HasColumn("orders", "shipped_quantity")
+		},
+		apply: execAll(
+			`ALTER TABLE orders ADD COLUMN shipped_quantity BIGINT NOT NULL DEFAULT 0`,
+			`UPDATE orders SET shipped_quantity = quantity
+				WHERE current_status IN ('SHIPPED', 'DELIVERED', 'RETURN_REQUESTED', 'PARTIALLY_RETURNED', 'RETURNED')`,
+		),
+	},
+	{
+		// Shipments had no ship date, which is required now, and carriers were stored as entered
+		// while tracking events match them by lower-case code
+		name: "date shipments and normalize their carriers",
+		pending: func(m gorm.Migrator) bool {
+			return m.HasTable("shipments") && !m.HasColumn("shipments", "shipped_at")
+		},
+		apply: execAll(
+			`ALTER TABLE shipments ADD COLUMN shipped_at TIMESTAMPTZ`,
+			`UPDATE shipments SET carrier = LOWER(TRIM(carrier)), shipped_at = COALESCE(created_at, CURRENT_TIMESTAMP)`,
+			`ALTER TABLE shipments ALTER COLUMN shipped_at SET NOT NULL`,
+		),
+	},
+	{
+		// Order history recorded only the user ID, 0 for changes the system made
+		name: "record who made past order status changes",
+		pending: func(m gorm.Migrator) bool {
+			return m.HasTable("order_state_logs") && !m.HasColumn("order_state_logs", "actor_id")
+		},
+		apply: execAll(
+			`ALTER TABLE order_state_logs ADD COLUMN IF NOT EXISTS actor_type VARCHAR(20) NOT NULL DEFAULT 'user'`,
+			`ALTER TABLE order_state_logs ADD COLUMN IF NOT EXISTS actor_id VARCHAR(100) NOT NULL DEFAULT ''`,
+			`UPDATE order_state_logs SET actor_id = updated_by::text WHERE updated_by <> 0`,
+			`UPDATE order_state_logs SET actor_type = 'system', actor_id = 'legacy' WHERE updated_by = 0`,
+		),
+	},
+}
+
+// runUpgrades applies the upgrades the database still needs
+func runUpgrades(db *gorm.DB) error {
+	for _, u := range upgrades {
+		if !u.pending(db.Migrator()) {
+			continue
+		}
+		log.Printf("Upgrading schema: %s", u.name)
+		if err := db.Transaction(u.apply); err != nil {
+			return fmt.Errorf("failed to %s: %w", u.name, err)
+		}
+	}
+	return nil
+}
+
+// execAll returns an upgrade that runs the statements in order
+func execAll(statements ...string) func(tx *gorm.DB) error {
+	return func(tx *gorm.DB) error {
+		for _, statement := range statements {
+			if err := tx.Exec(statement).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// columnType returns the database type of a column, or "" when the table or column doesn't exist
+func columnType(m gorm.Migrator, table, column string) string {
+	if !m.HasTable(table) {
+		return ""
+	}
+	columns, err := m.ColumnTypes(table)
+	if err != nil {
+		return ""
+	}
+	for _, c := range columns {
+		if c.Name() == column {
+			return c.DatabaseTypeName()
+		}
+	}
+	return ""
+}
+
+func upgradeInventoryStockUnits(tx *gorm.DB) error {
+	statements := []string{
+		// The constraint from the first migrations references products, which variants aren't
+		`ALTER TABLE inventory DROP CONSTRAINT IF EXISTS fk_inventory_product`,
+		`ALTER TABLE inventory RENAME COLUMN product_id TO stock_unit_id`,
+		`ALTER TABLE inventory ADD COLUMN product_id UUID, ADD COLUMN variant_id UUID`,
+	}
+	if tx.Migrator().HasTable("product_variants") {
+		statements = append(statements, `UPDATE inventory i SET product_id = v.product_id, variant_id = v.id
+			FROM product_variants v WHERE v.id = i.stock_unit_id`)
+	}
+	statements = append(statements,
+		`UPDATE inventory SET product_id = stock_unit_id WHERE product_id IS NULL`,
+		`ALTER TABLE inventory ALTER COLUMN product_id SET NOT NULL`,
+	)
+	if err := execAll(statements...)(tx); err != nil {
+		return err
+	}
+
+	// Rows of stock units that no longer exist can't satisfy the foreign keys AutoMigrate adds
+	result := tx.Exec(`DELETE FROM inventory i WHERE NOT EXISTS (SELECT 1 FROM products p WHERE p.id = i.product_id)`)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected > 0 {
+		log.Printf("Deleted %d inventory rows of products that no longer exist", result.RowsAffected)
+	}
+	return nil
+}
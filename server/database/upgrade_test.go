@@ -0,0 +1,236 @@
+package database_test
+
+import (
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+	"oms/server/core/model"
+	"oms/server/core/money"
+	"oms/server/database"
+)
+
+// testDB connects to the PostgreSQL database in TEST_DATABASE_DSN, inside a schema of its own that
+// is dropped after the test. Tests that need a database are skipped when the variable isn't set.
+func testDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	dsn := os.Getenv("TEST_DATABASE_DSN")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_DSN is not set")
+	}
+
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatalf("failed to connect: %v", err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// One connection, so the search path set below applies to every statement
+	sqlDB.SetMaxOpenConns(1)
+
+	schema := fmt.Sprintf("upgrade_test_%d", time.Now().UnixNano())
+	if err := db.Exec("CREATE SCHEMA " + schema).Error; err != nil {
+		t.Fatalf("failed to create schema: %v", err)
+	}
+	t.Cleanup(func() {
+		db.Exec("DROP SCHEMA " + schema + " CASCADE")
+		sqlDB.Close()
+	})
+	if err := db.Exec("SET search_path TO " + schema).Error; err != nil {
+		t.Fatalf("failed to use schema: %v", err)
+	}
+	return db
+}
+
+// The tables as the first release created them, before the later requests changed them
+
+type baselineProduct struct {
+	ID        uuid.UUID   `gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	SKU       string      `gorm:"type:varchar(255);unique;not null"`
+	Name      string      `gorm:"type:varchar(255);not null"`
+	Price     float64     `gorm:"type:decimal(10,2);not null"`
+	Metadata  model.JSONB `gorm:"type:jsonb"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	DeletedAt gorm.DeletedAt `gorm:"index"`
+}
+
+func (baselineProduct) TableName() string { return "products" }
+
+type baselineInventory struct {
+	ProductID uuid.UUID `gorm:"type:uuid;primary_key"`
+	Quantity  int       `gorm:"not null"`
+}
+
+func (baselineInventory) TableName() string { return "inventory" }
+
+// legacyVariant is a variant as variants were first added: priced in decimal, and stocked by an
+// inventory row whose product_id is the variant's ID
+type legacyVariant struct {
+	ID        uuid.UUID   `gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	ProductID uuid.UUID   `gorm:"type:uuid;not null;index"`
+	SKU       string      `gorm:"type:varchar(255);unique;not null"`
+	Options   model.JSONB `gorm:"type:jsonb;not null"`
+	Price     *float64    `gorm:"type:decimal(10,2)"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	DeletedAt gorm.DeletedAt `gorm:"index"`
+}
+
+func (legacyVariant) TableName() string { return "product_variants" }
+
+type baselineOrder struct {
+	ID            uuid.UUID         `gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	UserID        int               `gorm:"not null"`
+	ProductID     uuid.UUID         `gorm:"type:uuid;not null"`
+	Quantity      int               `gorm:"not null"`
+	CurrentStatus model.OrderStatus `gorm:"type:varchar(50);not null;default:'ORDERED'"`
+	Metadata      model.JSONB       `gorm:"type:jsonb"`
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+}
+
+func (baselineOrder) TableName() string { return "orders" }
+
+// legacyShipment is a shipment as partial shipments first recorded them, before tracking
+type legacyShipment struct {
+	ID             uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	OrderID        uuid.UUID `gorm:"type:uuid;not null;index"`
+	Quantity       int       `gorm:"not null"`
+	Carrier        string    `gorm:"type:varchar(100);not null"`
+	TrackingNumber string    `gorm:"type:varchar(255);not null"`
+	ShippedBy      int       `gorm:"not null"`
+	CreatedAt      time.Time
+}
+
+func (legacyShipment) TableName() string { return "shipments" }
+
+type baselineStateLog struct {
+	ID             uuid.UUID         `gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	OrderID        uuid.UUID         `gorm:"type:uuid;not null"`
+	PreviousStatus model.OrderStatus `gorm:"type:varchar(50)"`
+	NewStatus      model.OrderStatus `gorm:"type:varchar(50);not null"`
+	UpdatedBy      int               `gorm:"not null"`
+	UpdatedAt      time.Time
+}
+
+func (baselineStateLog) TableName() string { return "order_state_logs" }
+
+func mustCreate(t *testing.T, db *gorm.DB, values ...interface{}) {
+	t.Helper()
+	for _, value := range values {
+		if err := db.Create(value).Error; err != nil {
+			t.Fatalf("failed to create %T: %v", value, err)
+		}
+	}
+}
+
+func TestAutoMigrateUpgradesOldSchema(t *testing.T) {
+	db := testDB(t)
+	if err := db.AutoMigrate(&baselineProduct{}, &legacyVariant{}, &baselineInventory{}, &baselineOrder{}, &legacyShipment{}, &baselineStateLog{}); err != nil {
+		t.Fatalf("failed to create the old schema: %v", err)
+	}
+
+	plain := &baselineProduct{ID: uuid.New(), SKU: "MUG", Name: "Mug", Price: 12.5}
+	shirt := &baselineProduct{ID: uuid.New(), SKU: "TEE", Name: "T-shirt", Price: 20}
+	override := 21.5
+	variant := &legacyVariant{ID: uuid.New(), ProductID: shirt.ID, SKU: "TEE-M", Options: model.JSONB{"Size": "M"}, Price: &override}
+	unpriced := &legacyVariant{ID: uuid.New(), ProductID: shirt.ID, SKU: "TEE-L", Options: model.JSONB{"Size": "L"}}
+	mustCreate(t, db, plain, shirt, variant, unpriced,
+		&baselineInventory{ProductID: plain.ID, Quantity: 5},
+		&baselineInventory{ProductID: variant.ID, Quantity: 3},
+		&baselineInventory{ProductID: uuid.New(), Quantity: 7}, // Stock of a product deleted for good
+	)
+	open := &baselineOrder{ID: uuid.New(), UserID: 1, ProductID: plain.ID, Quantity: 2, CurrentStatus: model.OrderStatusOrdered}
+	delivered := &baselineOrder{ID: uuid.New(), UserID: 1, ProductID: plain.ID, Quantity: 3, CurrentStatus: model.OrderStatusDelivered}
+	shippedAt := time.Date(2025, 1, 6, 15, 4, 5, 0, time.UTC)
+	mustCreate(t, db, open, delivered,
+		&legacyShipment{OrderID: delivered.ID, Quantity: 3, Carrier: " UPS ", TrackingNumber: "1Z999", ShippedBy: 1, CreatedAt: shippedAt},
+		&baselineStateLog{OrderID: delivered.ID, PreviousStatus: model.OrderStatusOrdered, NewStatus: model.OrderStatusShipped, UpdatedBy: 7},
+		&baselineStateLog{OrderID: open.ID, PreviousStatus: model.OrderStatusShipped, NewStatus: model.OrderStatusDelivered, UpdatedBy: 0},
+	)
+
+	// Migrating again finds nothing left to upgrade
+	for i := 0; i < 2; i++ {
+		if err := database.AutoMigrate(db); err != nil {
+			t.Fatalf("AutoMigrate (run %d): %v", i+1, err)
+		}
+	}
+
+	if db.Migrator().HasColumn("products", "price") {
+		t.Error("products still has the decimal price column")
+	}
+	var products []*model.Product
+	if err := db.Order("sku").Find(&products).Error; err != nil {
+		t.Fatalf("failed to read products: %v", err)
+	}
+	if len(products) != 2 || products[0].Price != money.New(1250, "USD") || products[1].Price != money.New(2000, "USD") {
+		t.Errorf("products = %+v, want MUG at 12.50 USD and TEE at 20.00 USD", products)
+	}
+	// Products can be created again once the old NOT NULL price column is gone
+	mustCreate(t, db, &model.Product{SKU: "CAP", Name: "Cap", Price: money.New(999, "USD")})
+
+	var variants []*model.ProductVariant
+	if err := db.Order("sku").Find(&variants).Error; err != nil {
+		t.Fatalf("failed to read variants: %v", err)
+	}
+	if len(variants) != 2 || variants[0].Price != nil || variants[1].Price == nil || *variants[1].Price != money.New(2150, "USD") {
+		t.Errorf("variants = %+v, want TEE-L without a price and TEE-M at 21.50 USD", variants)
+	}
+
+	var inventory []*model.Inventory
+	if err := db.Order("quantity").Find(&inventory).Error; err != nil {
+		t.Fatalf("failed to read inventory: %v", err)
+	}
+	if len(inventory) != 2 {
+		t.Fatalf("got %d inventory rows, want the 2 of existing stock units", len(inventory))
+	}
+	if got := inventory[0]; got.StockUnitID != variant.ID || got.ProductID != shirt.ID || got.VariantID == nil || *got.VariantID != variant.ID || got.Quantity != 3 {
+		t.Errorf("variant inventory = %+v, want stock unit and variant %s of product %s with 3 units", got, variant.ID, shirt.ID)
+	}
+	if got := inventory[1]; got.StockUnitID != plain.ID || got.ProductID != plain.ID || got.VariantID != nil || got.Quantity != 5 {
+		t.Errorf("product inventory = %+v, want stock unit and product %s with 5 units", got, plain.ID)
+	}
+	shipped := map[uuid.UUID]int{open.ID: 0, delivered.ID: 3}
+	for id, want := range shipped {
+		var order model.Order
+		if err := db.First(&order, "id = ?", id).Error; err != nil {
+			t.Fatalf("failed to read order %s: %v", id, err)
+		}
+		if order.ShippedQuantity != want {
+			t.Errorf("%s order has %d units shipped, want %d", order.CurrentStatus, order.ShippedQuantity, want)
+		}
+	}
+
+	var shipment model.Shipment
+	if err := db.First(&shipment, "order_id = ?", delivered.ID).Error; err != nil {
+		t.Fatalf("failed to read the shipment: %v", err)
+	}
+	if shipment.Carrier != "ups" || !shipment.ShippedAt.Equal(shippedAt) || shipment.Status != model.TrackingStatusShipped {
+		t.Errorf("shipment = %s shipped at %s, %s, want ups shipped at %s, shipped", shipment.Carrier, shipment.ShippedAt, shipment.Status, shippedAt)
+	}
+
+	actors := map[uuid.UUID]model.Actor{delivered.ID: model.UserActor(7), open.ID: model.SystemActor("legacy")}
+	for orderID, want := range actors {
+		var entry model.OrderStateLog
+		if err := db.First(&entry, "order_id = ?", orderID).Error; err != nil {
+			t.Fatalf("failed to read the history of order %s: %v", orderID, err)
+		}
+		if entry.Actor() != want {
+			t.Errorf("history entry by user %d has actor %s, want %s", entry.UpdatedBy, entry.Actor(), want)
+		}
+	}
+
+	for _, constraint := range []string{"Product", "Variant"} {
+		if !db.Migrator().HasConstraint(&model.Inventory{}, constraint) {
+			t.Errorf("inventory has no foreign key for %s", constraint)
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package datastore
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"log"
+
+	"gorm.io/gorm"
+	"oms/server/core/types"
+)
+
+// advisoryLocker implements types.JobLocker with Postgres session advisory locks. A session lock
+// belongs to one connection, so each held lock keeps a connection out of the pool until released;
+// if the process dies, the connection closes and Postgres releases the lock.
+type advisoryLocker struct {
+	db *gorm.DB
+}
+
+// NewAdvisoryLocker creates a JobLocker backed by Postgres advisory locks
+func NewAdvisoryLocker(db *gorm.DB) types.JobLocker {
+	return &advisoryLocker{db: db}
+}
+
+// TryLock takes the advisory lock keyed by the hash of name, if no other session holds it
+func (l *advisoryLocker) TryLock(ctx context.Context, name string) (func(), bool, error) {
+	sqlDB, err := l.db.DB()
+	if err != nil {
+		return nil, false, err
+	}
+	conn, err := sqlDB.Conn(ctx)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to get a connection for lock %s: %w", name, err)
+	}
+
+	var acquired bool
+	if err := conn.QueryRowContext(ctx, "SELECT pg_try_advisory_lock(hashtext($1))", name).Scan(&acquired); err != nil {
+		conn.Close()
+		return nil, false, fmt.Errorf("failed to take lock %s: %w", name, err)
+	}
+	if !acquired {
+		conn.Close()
+		return nil, false, nil
+	}
+
+	release := func() {
+		// The job's context may be cancelled by now; unlocking must still happen
+		if _, err := conn.ExecContext(context.Background(), "SELECT pg_advisory_unlock(hashtext($1))", name); err != nil {
+			log.Printf("Warning: failed to release lock %s: %v", name, err)
+			// Discarding a connection whose lock state is unknown ends its session, which releases the lock
+			conn.Raw(func(interface{}) error { return driver.ErrBadConn })
+		}
+		conn.Close()
+	}
+	return release, true, nil
+}
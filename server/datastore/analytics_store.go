@@ -0,0 +1,283 @@
+package datastore
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"oms/server/core/model"
+	"oms/server/core/money"
+	"oms/server/core/types"
+)
+
+// orderSalesColumns select what an order sold: the units that weren't cancelled and their share
+// of the order total, rounded down. Fully cancelled orders are excluded by the WHERE clause.
+const orderSalesColumns = `product_id, total_currency AS currency, 1 AS orders,
+	quantity - cancelled_quantity AS units,
+	total_amount * (quantity - cancelled_quantity) / quantity AS revenue`
+
+// orderSales selects the sales of each order placed in [?, ?); at is a timestamptz
+const orderSales = `SELECT created_at AS at, ` + orderSalesColumns + `
+	FROM orders WHERE created_at >= ? AND created_at < ? AND current_status <> 'CANCELLED'`
+
+// analyticsStore implements types.AnalyticsStore. Orders are placed at instants (timestamptz) and
+// rollup hours are UTC wall times (timestamp); reports convert both to the range's time zone in
+// the database.
+type analyticsStore struct {
+	db *gorm.DB
+}
+
+// NewAnalyticsStore creates a new AnalyticsStore
+func NewAnalyticsStore(db *gorm.DB) types.AnalyticsStore {
+	return &analyticsStore{db: db}
+}
+
+// Sales sums the sales of each period and currency
+func (s *analyticsStore) Sales(ctx context.Context, r model.AnalyticsRange, interval model.AnalyticsInterval) ([]*model.SalesPoint, error) {
+	source, args, err := s.salesSource(ctx, r)
+	if err != nil {
+		return nil, err
+	}
+	var points []*model.SalesPoint
+	err = s.db.WithContext(ctx).Raw(`
+		SELECT date_trunc(?, at AT TIME ZONE ?) AS period_start, currency,
+			SUM(orders) AS orders, SUM(units) AS units, SUM(revenue) AS revenue
+		FROM (`+source+`) sales
+		GROUP BY 1, 2
+		ORDER BY 1, 2`,
+		append([]interface{}{string(interval), r.Location.String()}, args...)...,
+	).Scan(&points).Error
+	if err != nil {
+		return nil, err
+	}
+	// Periods come back as wall times in the range's time zone
+	for _, point := range points {
+		t := point.PeriodStart
+		point.PeriodStart = time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), 0, r.Location)
+	}
+	return points, nil
+}
+
+// productSalesRow is a row of the top products query
+type productSalesRow struct {
+	ProductID uuid.UUID
+	SKU       string
+	Name      string
+	Currency  string
+	Orders    int64
+	Units     int64
+	Revenue   int64
+}
+
+// TopProducts ranks products by units sold, then revenue
+func (s *analyticsStore) TopProducts(ctx context.Context, r model.AnalyticsRange, limit int) ([]*model.ProductSales, error) {
+	source, args, err := s.salesSource(ctx, r)
+	if err != nil {
+		return nil, err
+	}
+	var rows []productSalesRow
+	err = s.db.WithContext(ctx).Raw(`
+		SELECT sales.product_id, COALESCE(p.sku, '') AS sku, COALESCE(p.name, '') AS name, sales.currency,
+			SUM(sales.orders) AS orders, SUM(sales.units) AS units, SUM(sales.revenue) AS revenue
+		FROM (`+source+`) sales
+		LEFT JOIN products p ON p.id = sales.product_id
+		GROUP BY sales.product_id, p.sku, p.name, sales.currency
+		ORDER BY units DESC, revenue DESC, sales.product_id
+		LIMIT ?`,
+		append(args, limit)...,
+	).Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	products := make([]*model.ProductSales, len(rows))
+	for i, row := range rows {
+		products[i] = &model.ProductSales{
+			ProductID: row.ProductID,
+			SKU:       row.SKU,
+			Name:      row.Name,
+			Orders:    row.Orders,
+			Units:     row.Units,
+			Revenue:   money.New(row.Revenue, row.Currency),
+		}
+	}
+	return products, nil
+}
+
+// funnelRow counts the orders with one current status by the steps they reached
+type funnelRow struct {
+	CurrentStatus model.OrderStatus
+	Placed        int64
+	Paid          int64
+	Picking       int64
+	Shipped       int64
+	Delivered     int64
+}
+
+// Funnel derives the steps each order reached from its state log
+func (s *analyticsStore) Funnel(ctx context.Context, r model.AnalyticsRange) (*model.OrderFunnel, error) {
+	var rows []funnelRow
+	err := s.db.WithContext(ctx).Raw(`
+		WITH reached AS (
+			SELECT o.id, o.current_status,
+				COALESCE(BOOL_OR(l.new_status = 'PAID'), false) AS paid,
+				COALESCE(BOOL_OR(l.new_status = 'PICKING'), false) AS picking,
+				COALESCE(BOOL_OR(l.new_status = 'SHIPPED'), false) AS shipped,
+				COALESCE(BOOL_OR(l.new_status = 'DELIVERED'), false) AS delivered
+			FROM orders o
+			LEFT JOIN order_state_logs l ON l.order_id = o.id
+			WHERE o.created_at >= ? AND o.created_at < ?
+			GROUP BY o.id, o.current_status
+		)
+		SELECT current_status, COUNT(*) AS placed,
+			COUNT(*) FILTER (WHERE paid) AS paid,
+			COUNT(*) FILTER (WHERE picking) AS picking,
+			COUNT(*) FILTER (WHERE shipped) AS shipped,
+			COUNT(*) FILTER (WHERE delivered) AS delivered
+		FROM reached
+		GROUP BY current_status`,
+		r.From.UTC(), r.To.UTC(),
+	).Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	funnel := &model.OrderFunnel{Statuses: map[model.OrderStatus]int64{}}
+	for _, row := range rows {
+		funnel.Placed += row.Placed
+		funnel.Paid += row.Paid
+		funnel.Picking += row.Picking
+		funnel.Shipped += row.Shipped
+		funnel.Delivered += row.Delivered
+		funnel.Statuses[row.CurrentStatus] = row.Placed
+	}
+	return funnel, nil
+}
+
+// FulfillmentTimes averages the time to each order's first SHIPPED and DELIVERED entries
+func (s *analyticsStore) FulfillmentTimes(ctx context.Context, r model.AnalyticsRange) (*model.FulfillmentTimes, error) {
+	var row struct {
+		ToShippedSeconds   float64
+		ShippedOrders      int64
+		ToDeliveredSeconds float64
+		DeliveredOrders    int64
+	}
+	err := s.db.WithContext(ctx).Raw(`
+		WITH steps AS (
+			SELECT o.created_at,
+				MIN(l.updated_at) FILTER (WHERE l.new_status = 'SHIPPED') AS shipped_at,
+				MIN(l.updated_at) FILTER (WHERE l.new_status = 'DELIVERED') AS delivered_at
+			FROM orders o
+			JOIN order_state_logs l ON l.order_id = o.id
+			WHERE o.created_at >= ? AND o.created_at < ?
+			GROUP BY o.id, o.created_at
+		)
+		SELECT COALESCE(AVG(EXTRACT(EPOCH FROM shipped_at - created_at)), 0) AS to_shipped_seconds,
+			COUNT(shipped_at) AS shipped_orders,
+			COALESCE(AVG(EXTRACT(EPOCH FROM delivered_at - shipped_at)), 0) AS to_delivered_seconds,
+			COUNT(delivered_at) FILTER (WHERE shipped_at IS NOT NULL) AS delivered_orders
+		FROM steps`,
+		r.From.UTC(), r.To.UTC(),
+	).Scan(&row).Error
+	if err != nil {
+		return nil, err
+	}
+	return &model.FulfillmentTimes{
+		ToShipped:       time.Duration(row.ToShippedSeconds * float64(time.Second)),
+		ShippedOrders:   row.ShippedOrders,
+		ToDelivered:     time.Duration(row.ToDeliveredSeconds * float64(time.Second)),
+		DeliveredOrders: row.DeliveredOrders,
+	}, nil
+}
+
+// RollUp replaces the rollups of the days in one transaction, so reports never see them half written
+func (s *analyticsStore) RollUp(ctx context.Context, from, to time.Time) (int64, error) {
+	var written int64
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if from.IsZero() {
+			var first *time.Time
+			if err := tx.Raw("SELECT MIN(created_at) FROM orders").Scan(&first).Error; err != nil {
+				return err
+			}
+			if first == nil {
+				return nil
+			}
+			from = *first
+		}
+		from, to = from.UTC().Truncate(24*time.Hour), to.UTC().Truncate(24*time.Hour)
+		if !from.Before(to) {
+			return nil
+		}
+
+		if err := tx.Exec("DELETE FROM sales_rollups WHERE hour >= ? AND hour < ?", from, to).Error; err != nil {
+			return err
+		}
+		result := tx.Exec(`
+			INSERT INTO sales_rollups (hour, product_id, currency, orders, units, revenue)
+			SELECT date_trunc('hour', at AT TIME ZONE 'UTC'), product_id, currency, SUM(orders), SUM(units), SUM(revenue)
+			FROM (`+orderSales+`) sales
+			GROUP BY 1, 2, 3`,
+			from, to,
+		)
+		if result.Error != nil {
+			return result.Error
+		}
+		written = result.RowsAffected
+		return tx.Exec(`
+			INSERT INTO sales_rollup_days (day, rolled_up_at)
+			SELECT day, NOW() FROM generate_series(?::date, ?::date - 1, interval '1 day') AS day
+			ON CONFLICT (day) DO UPDATE SET rolled_up_at = EXCLUDED.rolled_up_at`,
+			from, to,
+		).Error
+	})
+	return written, err
+}
+
+// RolledUpThrough returns the day after the last rolled up day. The rollup job keeps the rolled
+// up days contiguous from the first order's.
+func (s *analyticsStore) RolledUpThrough(ctx context.Context) (time.Time, error) {
+	var end *time.Time
+	if err := s.db.WithContext(ctx).Raw("SELECT MAX(day) + 1 FROM sales_rollup_days").Scan(&end).Error; err != nil {
+		return time.Time{}, err
+	}
+	if end == nil {
+		return time.Time{}, nil
+	}
+	return time.Date(end.Year(), end.Month(), end.Day(), 0, 0, 0, 0, time.UTC), nil
+}
+
+// salesSource selects the sales in r from the rollups of the days rolled up and from orders after
+// them. Rollups are hourly in UTC, so they are only used when r starts and ends on the hour in a
+// time zone whose offset is whole hours; otherwise every order is read.
+func (s *analyticsStore) salesSource(ctx context.Context, r model.AnalyticsRange) (string, []interface{}, error) {
+	from, to := r.From.UTC(), r.To.UTC()
+	if !rollupAligned(r) {
+		return orderSales, []interface{}{from, to}, nil
+	}
+	split, err := s.RolledUpThrough(ctx)
+	if err != nil {
+		return "", nil, err
+	}
+	if split.After(to) {
+		split = to
+	}
+	if !split.After(from) {
+		return orderSales, []interface{}{from, to}, nil
+	}
+	// Rollup hours are UTC wall times; as instants they combine with the orders'
+	rollups := `SELECT hour AT TIME ZONE 'UTC' AS at, product_id, currency, orders, units, revenue
+		FROM sales_rollups WHERE hour >= ? AND hour < ?`
+	return rollups + " UNION ALL " + orderSales, []interface{}{from, split, split, to}, nil
+}
+
+// rollupAligned reports whether r's bounds fall on UTC hours and its periods can be built from them
+func rollupAligned(r model.AnalyticsRange) bool {
+	for _, t := range []time.Time{r.From, r.To} {
+		if !t.Equal(t.Truncate(time.Hour)) {
+			return false
+		}
+		if _, offset := t.In(r.Location).Zone(); offset%3600 != 0 {
+			return false
+		}
+	}
+	return true
+}
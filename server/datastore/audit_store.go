@@ -0,0 +1,86 @@
+package datastore
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+	"oms/server/core/model"
+	"oms/server/core/types"
+)
+
+// auditExportBatch is how many audit entries Each reads at a time
+const auditExportBatch = 500
+
+// auditStore implements types.AuditStore
+type auditStore struct {
+	db *gorm.DB
+}
+
+// NewAuditStore creates a new AuditStore
+func NewAuditStore(db *gorm.DB) types.AuditStore {
+	return &auditStore{db: db}
+}
+
+// Create records an audit entry
+func (s *auditStore) Create(ctx context.Context, entry *model.AuditEntry) error {
+	return s.db.WithContext(ctx).Create(entry).Error
+}
+
+// Search retrieves the entries matching filter, newest first
+func (s *auditStore) Search(ctx context.Context, filter model.AuditFilter) ([]*model.AuditEntry, error) {
+	var entries []*model.AuditEntry
+	query := s.filtered(ctx, filter).Order("created_at DESC, id DESC")
+	if filter.Limit > 0 {
+		query = query.Limit(filter.Limit)
+	}
+	err := query.Find(&entries).Error
+	return entries, err
+}
+
+// Each pages through the matching entries by (created_at, id), newest first
+func (s *auditStore) Each(ctx context.Context, filter model.AuditFilter, fn func(entry *model.AuditEntry) error) error {
+	var last *model.AuditEntry
+	for {
+		query := s.filtered(ctx, filter)
+		if last != nil {
+			query = query.Where("(created_at, id) < (?, ?)", last.CreatedAt, last.ID)
+		}
+		var batch []*model.AuditEntry
+		if err := query.Order("created_at DESC, id DESC").Limit(auditExportBatch).Find(&batch).Error; err != nil {
+			return err
+		}
+		for _, entry := range batch {
+			if err := fn(entry); err != nil {
+				return err
+			}
+		}
+		if len(batch) < auditExportBatch {
+			return nil
+		}
+		last = batch[len(batch)-1]
+	}
+}
+
+// filtered applies filter's conditions, but not its limit
+func (s *auditStore) filtered(ctx context.Context, filter model.AuditFilter) *gorm.DB {
+	query := s.db.WithContext(ctx).Model(&model.AuditEntry{})
+	if filter.EntityType != "" {
+		query = query.Where("entity_type = ?", filter.EntityType)
+	}
+	if filter.EntityID != "" {
+		query = query.Where("entity_id = ?", filter.EntityID)
+	}
+	if filter.ActorType != "" {
+		query = query.Where("actor_type = ?", filter.ActorType)
+	}
+	if filter.ActorID != "" {
+		query = query.Where("actor_id = ?", filter.ActorID)
+	}
+	if filter.From != nil {
+		query = query.Where("created_at >= ?", *filter.From)
+	}
+	if filter.To != nil {
+		query = query.Where("created_at < ?", *filter.To)
+	}
+	return query
+}
@@ -0,0 +1,268 @@
+package datastore
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"oms/server/core/model"
+	"oms/server/core/types"
+)
+
+// cartStore implements types.CartStore
+type cartStore struct {
+	db *gorm.DB
+}
+
+// NewCartStore creates a new CartStore
+func NewCartStore(db *gorm.DB) types.CartStore {
+	return &cartStore{db: db}
+}
+
+// withLines preloads a cart's lines in the order they were added
+func withLines(db *gorm.DB) *gorm.DB {
+	return db.Preload("Lines", func(db *gorm.DB) *gorm.DB {
+		return db.Order("created_at, id")
+	})
+}
+
+// GetByID retrieves a cart by ID
+func (s *cartStore) GetByID(ctx context.Context, cartID uuid.UUID) (*model.Cart, error) {
+	return s.first(withLines(s.db.WithContext(ctx)).Where("id = ?", cartID))
+}
+
+// GetByUserID retrieves a user's cart
+func (s *cartStore) GetByUserID(ctx context.Context, userID int) (*model.Cart, error) {
+	return s.first(withLines(s.db.WithContext(ctx)).Where("user_id = ?", userID))
+}
+
+// GetByToken retrieves an anonymous cart by its token
+func (s *cartStore) GetByToken(ctx context.Context, token string) (*model.Cart, error) {
+	return s.first(withLines(s.db.WithContext(ctx)).Where("token = ?", token))
+}
+
+func (s *cartStore) first(query *gorm.DB) (*model.Cart, error) {
+	var cart model.Cart
+	if err := query.First(&cart).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("cart not found")
+		}
+		return nil, err
+	}
+	return &cart, nil
+}
+
+// Create creates a new, empty cart
+func (s *cartStore) Create(ctx context.Context, cart *model.Cart) error {
+	if cart.ID == uuid.Nil {
+		cart.ID = uuid.New()
+	}
+	now := time.Now()
+	cart.CreatedAt = now
+	cart.UpdatedAt = now
+	return s.db.WithContext(ctx).Omit("Lines").Create(cart).Error
+}
+
+// Touch pushes back a cart's expiry
+func (s *cartStore) Touch(ctx context.Context, cartID uuid.UUID, expiresAt time.Time) error {
+	result := s.db.WithContext(ctx).
+		Model(&model.Cart{}).
+		Where("id = ?", cartID).
+		Updates(map[string]interface{}{
+			"expires_at": expiresAt,
+			"updated_at": time.Now(),
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("cart not found")
+	}
+	return nil
+}
+
+// SaveLine creates a cart line, or updates the quantity of an existing one
+func (s *cartStore) SaveLine(ctx context.Context, line *model.CartLine) error {
+	now := time.Now()
+	line.UpdatedAt = now
+	if line.ID == uuid.Nil {
+		line.ID = uuid.New()
+		line.CreatedAt = now
+		return s.db.WithContext(ctx).Create(line).Error
+	}
+
+	result := s.db.WithContext(ctx).
+		Model(&model.CartLine{}).
+		Where("id = ? AND cart_id = ?", line.ID, line.CartID).
+		Updates(map[string]interface{}{
+			"quantity":   line.Quantity,
+			"updated_at": line.UpdatedAt,
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("cart line not found")
+	}
+	return nil
+}
+
+// DeleteLine removes a line from a cart
+func (s *cartStore) DeleteLine(ctx context.Context, cartID, lineID uuid.UUID) error {
+	result := s.db.WithContext(ctx).Delete(&model.CartLine{}, "id = ? AND cart_id = ?", lineID, cartID)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("cart line not found")
+	}
+	return nil
+}
+
+// ClearLines removes every line from a cart
+func (s *cartStore) ClearLines(ctx context.Context, cartID uuid.UUID) error {
+	return s.db.WithContext(ctx).Where("cart_id = ?", cartID).Delete(&model.CartLine{}).Error
+}
+
+// TakeLines removes every line from a cart and returns them
+func (s *cartStore) TakeLines(ctx context.Context, cartID uuid.UUID) ([]model.CartLine, error) {
+	var lines []model.CartLine
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Lock the cart so a concurrent call reads the lines only once they are deleted
+		var cart model.Cart
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ?", cartID).
+			First(&cart).Error
+		if err != nil {
+			return err
+		}
+		if err := tx.Where("cart_id = ?", cartID).Order("created_at, id").Find(&lines).Error; err != nil {
+			return err
+		}
+		if len(lines) == 0 {
+			return nil
+		}
+		return tx.Where("cart_id = ?", cartID).Delete(&model.CartLine{}).Error
+	})
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, errors.New("cart not found")
+	}
+	if err != nil {
+		return nil, err
+	}
+	return lines, nil
+}
+
+// RestoreLines puts lines taken from a cart back, adding their quantities to lines for the same
+// product and variant added since
+func (s *cartStore) RestoreLines(ctx context.Context, cartID uuid.UUID, lines []model.CartLine) error {
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var cart model.Cart
+		err := withLines(tx.Clauses(clause.Locking{Strength: "UPDATE"})).
+			Where("id = ?", cartID).
+			First(&cart).Error
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		for _, line := range lines {
+			if existing := cart.Line(line.ProductID, line.VariantID); existing != nil {
+				existing.Quantity += line.Quantity
+				err := tx.Model(&model.CartLine{}).
+					Where("id = ?", existing.ID).
+					Updates(map[string]interface{}{
+						"quantity":   existing.Quantity,
+						"updated_at": now,
+					}).Error
+				if err != nil {
+					return err
+				}
+				continue
+			}
+			line.CartID = cartID
+			line.UpdatedAt = now
+			if err := tx.Create(&line).Error; err != nil {
+				return err
+			}
+			cart.Lines = append(cart.Lines, line)
+		}
+		return nil
+	})
+}
+
+// Merge moves the lines of one cart into another, adding up the quantities of lines for the
+// same product and variant, and deletes the emptied cart
+func (s *cartStore) Merge(ctx context.Context, fromCartID, intoCartID uuid.UUID) error {
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Lock the target so concurrent merges into it add up correctly
+		var into model.Cart
+		err := withLines(tx.Clauses(clause.Locking{Strength: "UPDATE"})).
+			Where("id = ?", intoCartID).
+			First(&into).Error
+		if err != nil {
+			return err
+		}
+
+		var fromLines []model.CartLine
+		if err := tx.Where("cart_id = ?", fromCartID).Find(&fromLines).Error; err != nil {
+			return err
+		}
+
+		now := time.Now()
+		for _, line := range fromLines {
+			if existing := into.Line(line.ProductID, line.VariantID); existing != nil {
+				err := tx.Model(&model.CartLine{}).
+					Where("id = ?", existing.ID).
+					Updates(map[string]interface{}{
+						"quantity":   existing.Quantity + line.Quantity,
+						"updated_at": now,
+					}).Error
+				if err != nil {
+					return err
+				}
+				if err := tx.Delete(&model.CartLine{}, "id = ?", line.ID).Error; err != nil {
+					return err
+				}
+				continue
+			}
+			err := tx.Model(&model.CartLine{}).
+				Where("id = ?", line.ID).
+				Updates(map[string]interface{}{
+					"cart_id":    intoCartID,
+					"updated_at": now,
+				}).Error
+			if err != nil {
+				return err
+			}
+		}
+
+		return tx.Delete(&model.Cart{}, "id = ?", fromCartID).Error
+	})
+}
+
+// DeleteExpired deletes carts whose expiry has passed, with their lines, and returns how many were deleted
+func (s *cartStore) DeleteExpired(ctx context.Context, now time.Time) (int64, error) {
+	var deleted int64
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Lock the expired carts so one touched meanwhile is not emptied
+		var cartIDs []uuid.UUID
+		err := tx.Model(&model.Cart{}).
+			Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("expires_at <= ?", now).
+			Pluck("id", &cartIDs).Error
+		if err != nil || len(cartIDs) == 0 {
+			return err
+		}
+		if err := tx.Where("cart_id IN ?", cartIDs).Delete(&model.CartLine{}).Error; err != nil {
+			return err
+		}
+		result := tx.Where("id IN ?", cartIDs).Delete(&model.Cart{})
+		deleted = result.RowsAffected
+		return result.Error
+	})
+	return deleted, err
+}
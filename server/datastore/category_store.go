@@ -0,0 +1,133 @@
+package datastore
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"oms/server/core/model"
+	"oms/server/core/types"
+)
+
+// categoryStore implements types.CategoryStore
+type categoryStore struct {
+	db *gorm.DB
+}
+
+// NewCategoryStore creates a new CategoryStore
+func NewCategoryStore(db *gorm.DB) types.CategoryStore {
+	return &categoryStore{db: db}
+}
+
+// GetByID retrieves a category by ID
+func (s *categoryStore) GetByID(ctx context.Context, categoryID uuid.UUID) (*model.Category, error) {
+	var category model.Category
+	err := s.db.WithContext(ctx).Where("id = ?", categoryID).First(&category).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("category not found")
+		}
+		return nil, err
+	}
+	return &category, nil
+}
+
+// GetBySlug retrieves a category by slug
+func (s *categoryStore) GetBySlug(ctx context.Context, slug string) (*model.Category, error) {
+	var category model.Category
+	err := s.db.WithContext(ctx).Where("slug = ?", slug).First(&category).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("category not found")
+		}
+		return nil, err
+	}
+	return &category, nil
+}
+
+// GetAll retrieves all categories ordered for display
+func (s *categoryStore) GetAll(ctx context.Context) ([]*model.Category, error) {
+	var categories []*model.Category
+	err := s.db.WithContext(ctx).Order("sort_order, name").Find(&categories).Error
+	return categories, err
+}
+
+// Create creates a new category
+func (s *categoryStore) Create(ctx context.Context, category *model.Category) error {
+	if category.ID == uuid.Nil {
+		category.ID = uuid.New()
+	}
+	now := time.Now()
+	category.CreatedAt = now
+	category.UpdatedAt = now
+	return s.db.WithContext(ctx).Create(category).Error
+}
+
+// Update updates a category's name, slug, parent and sort order
+func (s *categoryStore) Update(ctx context.Context, category *model.Category) error {
+	category.UpdatedAt = time.Now()
+	result := s.db.WithContext(ctx).
+		Model(&model.Category{}).
+		Where("id = ?", category.ID).
+		Updates(map[string]interface{}{
+			"parent_id":  category.ParentID,
+			"name":       category.Name,
+			"slug":       category.Slug,
+			"sort_order": category.SortOrder,
+			"updated_at": category.UpdatedAt,
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("category not found")
+	}
+	return nil
+}
+
+// Delete deletes a category and its product associations
+func (s *categoryStore) Delete(ctx context.Context, categoryID uuid.UUID) error {
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("category_id = ?", categoryID).Delete(&model.ProductCategory{}).Error; err != nil {
+			return err
+		}
+		result := tx.Delete(&model.Category{}, "id = ?", categoryID)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errors.New("category not found")
+		}
+		return nil
+	})
+}
+
+// GetByProductID retrieves the categories a product is assigned to
+func (s *categoryStore) GetByProductID(ctx context.Context, productID uuid.UUID) ([]*model.Category, error) {
+	var categories []*model.Category
+	err := s.db.WithContext(ctx).
+		Joins("JOIN product_categories pc ON pc.category_id = categories.id").
+		Where("pc.product_id = ?", productID).
+		Order("categories.sort_order, categories.name").
+		Find(&categories).Error
+	return categories, err
+}
+
+// SetProductCategories replaces the categories a product is assigned to
+func (s *categoryStore) SetProductCategories(ctx context.Context, productID uuid.UUID, categoryIDs []uuid.UUID) error {
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("product_id = ?", productID).Delete(&model.ProductCategory{}).Error; err != nil {
+			return err
+		}
+		if len(categoryIDs) == 0 {
+			return nil
+		}
+		links := make([]model.ProductCategory, len(categoryIDs))
+		for i, categoryID := range categoryIDs {
+			links[i] = model.ProductCategory{ProductID: productID, CategoryID: categoryID}
+		}
+		return tx.Create(&links).Error
+	})
+}
@@ -0,0 +1,100 @@
+package datastore
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"oms/server/core/model"
+	"oms/server/core/types"
+)
+
+// exportJobStore implements types.ExportJobStore
+type exportJobStore struct {
+	db *gorm.DB
+}
+
+// NewExportJobStore creates a new ExportJobStore
+func NewExportJobStore(db *gorm.DB) types.ExportJobStore {
+	return &exportJobStore{db: db}
+}
+
+// Create records a new job
+func (s *exportJobStore) Create(ctx context.Context, job *model.ExportJob) error {
+	return s.db.WithContext(ctx).Create(job).Error
+}
+
+// GetByID retrieves a job by ID
+func (s *exportJobStore) GetByID(ctx context.Context, jobID uuid.UUID) (*model.ExportJob, error) {
+	var job model.ExportJob
+	err := s.db.WithContext(ctx).Where("id = ?", jobID).First(&job).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("export job not found")
+		}
+		return nil, err
+	}
+	return &job, nil
+}
+
+// Claim takes the oldest queued job with FOR UPDATE SKIP LOCKED, so concurrent claims skip each
+// other's rows instead of waiting on them
+func (s *exportJobStore) Claim(ctx context.Context, instance string) (*model.ExportJob, error) {
+	var jobs []*model.ExportJob
+	err := s.db.WithContext(ctx).Raw(`
+		UPDATE export_jobs SET status = ?, instance = ?, started_at = ?
+		WHERE id = (
+			SELECT id FROM export_jobs WHERE status = ?
+			ORDER BY created_at, id
+			LIMIT 1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING *`,
+		model.ExportRunning, instance, time.Now().UTC(), model.ExportQueued,
+	).Scan(&jobs).Error
+	if err != nil || len(jobs) == 0 {
+		return nil, err
+	}
+	return jobs[0], nil
+}
+
+// Finish saves the outcome of a job
+func (s *exportJobStore) Finish(ctx context.Context, job *model.ExportJob) error {
+	return s.db.WithContext(ctx).Model(&model.ExportJob{}).
+		Where("id = ?", job.ID).
+		Updates(map[string]interface{}{
+			"status":       job.Status,
+			"row_count":    job.RowCount,
+			"size":         job.Size,
+			"error":        job.Error,
+			"completed_at": job.CompletedAt,
+			"expires_at":   job.ExpiresAt,
+		}).Error
+}
+
+// FailRunning fails the running jobs of the matching instances
+func (s *exportJobStore) FailRunning(ctx context.Context, prefix, message string, expiresAt time.Time) (int64, error) {
+	result := s.db.WithContext(ctx).Model(&model.ExportJob{}).
+		Where("status = ? AND instance LIKE ?", model.ExportRunning, prefix+"%").
+		Updates(map[string]interface{}{
+			"status":       model.ExportFailed,
+			"error":        message,
+			"completed_at": time.Now().UTC(),
+			"expires_at":   expiresAt.UTC(),
+		})
+	return result.RowsAffected, result.Error
+}
+
+// GetExpired retrieves the jobs that expired before the cutoff
+func (s *exportJobStore) GetExpired(ctx context.Context, before time.Time) ([]*model.ExportJob, error) {
+	var jobs []*model.ExportJob
+	err := s.db.WithContext(ctx).Where("expires_at < ?", before.UTC()).Order("expires_at").Find(&jobs).Error
+	return jobs, err
+}
+
+// Delete deletes a job
+func (s *exportJobStore) Delete(ctx context.Context, jobID uuid.UUID) error {
+	return s.db.WithContext(ctx).Where("id = ?", jobID).Delete(&model.ExportJob{}).Error
+}
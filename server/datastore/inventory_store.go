@@ -23,11 +23,11 @@ func NewInventoryStore(db *gorm.DB) types.InventoryStore {
 // GetByProductID retrieves inventory for a product
 func (s *inventoryStore) GetByProductID(ctx context.Context, productID uuid.UUID) (*model.Inventory, error) {
 	var inventory model.Inventory
-	err := s.db.WithContext(ctx).Where("product_id = ?", productID).First(&inventory).Error
+	err := s.db.WithContext(ctx).Where("stock_unit_id = ?", productID).First(&inventory).Error
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			// Return zero inventory if not found
-			return &model.Inventory{ProductID: productID, Quantity: 0}, nil
+			return &model.Inventory{StockUnitID: productID, Quantity: 0}, nil
 		}
 		return nil, err
 	}
@@ -44,13 +44,13 @@ func (s *inventoryStore) LockForUpdate(ctx context.Context, productID uuid.UUID)
 	// Use SELECT FOR UPDATE to lock the row
 	err := s.db.WithContext(ctx).
 		Set("gorm:query_option", "FOR UPDATE").
-		Where("product_id = ?", productID).
+		Where("stock_unit_id = ?", productID).
 		First(&inventory).Error
 	
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			// Return zero inventory if not found (caller will handle creation)
-			return &model.Inventory{ProductID: productID, Quantity: 0}, nil
+			return &model.Inventory{StockUnitID: productID, Quantity: 0}, nil
 		}
 		return nil, err
 	}
@@ -63,7 +63,7 @@ func (s *inventoryStore) LockForUpdate(ctx context.Context, productID uuid.UUID)
 func (s *inventoryStore) DecrementQuantity(ctx context.Context, productID uuid.UUID, quantity int) error {
 	result := s.db.WithContext(ctx).
 		Model(&model.Inventory{}).
-		Where("product_id = ? AND quantity >= ?", productID, quantity).
+		Where("stock_unit_id = ? AND quantity >= ?", productID, quantity).
 		Update("quantity", gorm.Expr("quantity - ?", quantity))
 	
 	if result.Error != nil {
@@ -80,7 +80,7 @@ func (s *inventoryStore) IncrementQuantity(ctx context.Context, productID uuid.U
 	// Use INSERT ... ON CONFLICT (PostgreSQL) or upsert pattern
 	result := s.db.WithContext(ctx).
 		Model(&model.Inventory{}).
-		Where("product_id = ?", productID).
+		Where("stock_unit_id = ?", productID).
 		Update("quantity", gorm.Expr("quantity + ?", quantity))
 	
 	if result.Error != nil {
@@ -89,11 +89,7 @@ func (s *inventoryStore) IncrementQuantity(ctx context.Context, productID uuid.U
 	
 	// If no rows were updated, create a new inventory entry
 	if result.RowsAffected == 0 {
-		inventory := &model.Inventory{
-			ProductID: productID,
-			Quantity:   quantity,
-		}
-		return s.db.WithContext(ctx).Create(inventory).Error
+		return s.create(ctx, &model.Inventory{StockUnitID: productID, Quantity: quantity})
 	}
 	
 	return nil
@@ -106,15 +102,10 @@ func (s *inventoryStore) UpdateQuantity(ctx context.Context, productID uuid.UUID
 	}
 	
 	// Use upsert pattern: update if exists, create if not
-	inventory := &model.Inventory{
-		ProductID: productID,
-		Quantity:  quantity,
-	}
-	
 	// Try to update first
 	result := s.db.WithContext(ctx).
 		Model(&model.Inventory{}).
-		Where("product_id = ?", productID).
+		Where("stock_unit_id = ?", productID).
 		Update("quantity", quantity)
 	
 	if result.Error != nil {
@@ -123,9 +114,57 @@ func (s *inventoryStore) UpdateQuantity(ctx context.Context, productID uuid.UUID
 	
 	// If no rows were updated, create a new inventory entry
 	if result.RowsAffected == 0 {
-		return s.db.WithContext(ctx).Create(inventory).Error
+		return s.create(ctx, &model.Inventory{StockUnitID: productID, Quantity: quantity})
 	}
 	
 	return nil
 }
 
+// UpdateBinLocation sets where a stock unit is shelved, creating its inventory row without stock if needed (admin only)
+func (s *inventoryStore) UpdateBinLocation(ctx context.Context, productID uuid.UUID, binLocation string) error {
+	result := s.db.WithContext(ctx).
+		Model(&model.Inventory{}).
+		Where("stock_unit_id = ?", productID).
+		Update("bin_location", binLocation)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return s.create(ctx, &model.Inventory{StockUnitID: productID, BinLocation: binLocation})
+	}
+	return nil
+}
+
+
+// UpdateReorderPoint sets when a stock unit is low and what to restock it to, creating its inventory row without stock if needed (admin only)
+func (s *inventoryStore) UpdateReorderPoint(ctx context.Context, productID uuid.UUID, reorderPoint, targetLevel *int) error {
+	result := s.db.WithContext(ctx).
+		Model(&model.Inventory{}).
+		Where("stock_unit_id = ?", productID).
+		Updates(map[string]interface{}{"reorder_point": reorderPoint, "target_level": targetLevel})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return s.create(ctx, &model.Inventory{StockUnitID: productID, ReorderPoint: reorderPoint, TargetLevel: targetLevel})
+	}
+	return nil
+}
+
+// create inserts the inventory row of a stock unit, filling in whether it is a variant and which
+// product it belongs to. A stock unit that is neither a product nor a variant fails the foreign key.
+func (s *inventoryStore) create(ctx context.Context, inventory *model.Inventory) error {
+	db := s.db.WithContext(ctx)
+	var variant model.ProductVariant
+	err := db.Unscoped().Select("id", "product_id").Where("id = ?", inventory.StockUnitID).Take(&variant).Error
+	switch {
+	case err == nil:
+		inventory.ProductID = variant.ProductID
+		inventory.VariantID = &variant.ID
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		inventory.ProductID = inventory.StockUnitID
+	default:
+		return err
+	}
+	return db.Create(inventory).Error
+}
@@ -0,0 +1,57 @@
+package datastore
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"oms/server/core/model"
+	"oms/server/core/types"
+)
+
+// jobRunStore implements types.JobRunStore
+type jobRunStore struct {
+	db *gorm.DB
+}
+
+// NewJobRunStore creates a new JobRunStore
+func NewJobRunStore(db *gorm.DB) types.JobRunStore {
+	return &jobRunStore{db: db}
+}
+
+// Start inserts the run unless the job already has one for the same scheduled time
+func (s *jobRunStore) Start(ctx context.Context, run *model.JobRun) (bool, error) {
+	result := s.db.WithContext(ctx).
+		Clauses(clause.OnConflict{Columns: []clause.Column{{Name: "job"}, {Name: "scheduled_for"}}, DoNothing: true}).
+		Create(run)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// Finish saves the outcome of a run
+func (s *jobRunStore) Finish(ctx context.Context, run *model.JobRun) error {
+	return s.db.WithContext(ctx).Model(&model.JobRun{}).
+		Where("id = ?", run.ID).
+		Updates(map[string]interface{}{
+			"status":      run.Status,
+			"result":      run.Result,
+			"error":       run.Error,
+			"finished_at": run.FinishedAt,
+		}).Error
+}
+
+// GetRecent retrieves the latest runs of every job
+func (s *jobRunStore) GetRecent(ctx context.Context, limit int) ([]*model.JobRun, error) {
+	var runs []*model.JobRun
+	err := s.db.WithContext(ctx).Order("started_at DESC, id").Limit(limit).Find(&runs).Error
+	return runs, err
+}
+
+// DeleteFinishedBefore deletes runs that finished before the cutoff and returns how many were deleted
+func (s *jobRunStore) DeleteFinishedBefore(ctx context.Context, before time.Time) (int64, error) {
+	result := s.db.WithContext(ctx).Where("finished_at < ?", before).Delete(&model.JobRun{})
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,95 @@
+package datastore
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"oms/server/core/model"
+	"oms/server/core/types"
+)
+
+// metadataSchemaStore implements types.MetadataSchemaStore
+type metadataSchemaStore struct {
+	db *gorm.DB
+}
+
+// NewMetadataSchemaStore creates a new MetadataSchemaStore
+func NewMetadataSchemaStore(db *gorm.DB) types.MetadataSchemaStore {
+	return &metadataSchemaStore{db: db}
+}
+
+// GetByID retrieves a schema by ID
+func (s *metadataSchemaStore) GetByID(ctx context.Context, schemaID uuid.UUID) (*model.MetadataSchema, error) {
+	var schema model.MetadataSchema
+	err := s.db.WithContext(ctx).Where("id = ?", schemaID).First(&schema).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("schema not found")
+		}
+		return nil, err
+	}
+	return &schema, nil
+}
+
+// GetAll retrieves all schemas
+func (s *metadataSchemaStore) GetAll(ctx context.Context) ([]*model.MetadataSchema, error) {
+	var schemas []*model.MetadataSchema
+	err := s.db.WithContext(ctx).Order("target, name").Find(&schemas).Error
+	return schemas, err
+}
+
+// GetByTarget retrieves all schemas for one target
+func (s *metadataSchemaStore) GetByTarget(ctx context.Context, target model.SchemaTarget) ([]*model.MetadataSchema, error) {
+	var schemas []*model.MetadataSchema
+	err := s.db.WithContext(ctx).Where("target = ?", target).Order("name").Find(&schemas).Error
+	return schemas, err
+}
+
+// Create creates a new schema
+func (s *metadataSchemaStore) Create(ctx context.Context, schema *model.MetadataSchema) error {
+	if schema.ID == uuid.Nil {
+		schema.ID = uuid.New()
+	}
+	now := time.Now()
+	schema.CreatedAt = now
+	schema.UpdatedAt = now
+	return s.db.WithContext(ctx).Create(schema).Error
+}
+
+// Update replaces a schema's name, scope and document
+func (s *metadataSchemaStore) Update(ctx context.Context, schema *model.MetadataSchema) error {
+	schema.UpdatedAt = time.Now()
+	result := s.db.WithContext(ctx).
+		Model(&model.MetadataSchema{}).
+		Where("id = ?", schema.ID).
+		Updates(map[string]interface{}{
+			"name":         schema.Name,
+			"target":       schema.Target,
+			"category_id":  schema.CategoryID,
+			"product_type": schema.ProductType,
+			"schema":       schema.Schema,
+			"updated_at":   schema.UpdatedAt,
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("schema not found")
+	}
+	return nil
+}
+
+// Delete deletes a schema
+func (s *metadataSchemaStore) Delete(ctx context.Context, schemaID uuid.UUID) error {
+	result := s.db.WithContext(ctx).Delete(&model.MetadataSchema{}, "id = ?", schemaID)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("schema not found")
+	}
+	return nil
+}
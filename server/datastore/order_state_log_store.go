@@ -24,13 +24,26 @@ func (s *orderStateLogStore) Create(ctx context.Context, log *model.OrderStateLo
 	return s.db.WithContext(ctx).Create(log).Error
 }
 
-// GetByOrderID retrieves all state logs for an order
-func (s *orderStateLogStore) GetByOrderID(ctx context.Context, orderID uuid.UUID) ([]*model.OrderStateLog, error) {
+// GetByOrderID retrieves the state logs for an order matching filter
+func (s *orderStateLogStore) GetByOrderID(ctx context.Context, orderID uuid.UUID, filter model.OrderHistoryFilter) ([]*model.OrderStateLog, error) {
 	var logs []*model.OrderStateLog
-	err := s.db.WithContext(ctx).
-		Where("order_id = ?", orderID).
-		Order("updated_at ASC").
-		Find(&logs).Error
+	query := s.db.WithContext(ctx).Where("order_id = ?", orderID)
+	if filter.Reason != "" {
+		query = query.Where("reason_code = ?", filter.Reason)
+	}
+	if filter.ActorType != "" {
+		query = query.Where("actor_type = ?", filter.ActorType)
+	}
+	err := query.Order("updated_at ASC").Find(&logs).Error
 	return logs, err
 }
 
+// GetByOrderIDs retrieves the state logs of several orders at once, oldest first
+func (s *orderStateLogStore) GetByOrderIDs(ctx context.Context, orderIDs []uuid.UUID) ([]*model.OrderStateLog, error) {
+	var logs []*model.OrderStateLog
+	if len(orderIDs) == 0 {
+		return logs, nil
+	}
+	err := s.db.WithContext(ctx).Where("order_id IN ?", orderIDs).Order("updated_at ASC, id ASC").Find(&logs).Error
+	return logs, err
+}
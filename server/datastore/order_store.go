@@ -9,8 +9,12 @@ import (
 	"oms/server/core/model"
 	"oms/server/core/types"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+// errNotEnoughOpenUnits rolls back a change that needs more open units than the order has
+var errNotEnoughOpenUnits = errors.New("not enough open units")
+
 // orderStore implements types.OrderStore
 type orderStore struct {
 	db *gorm.DB
@@ -21,7 +25,7 @@ func NewOrderStore(db *gorm.DB) types.OrderStore {
 	return &orderStore{db: db}
 }
 
-// Create creates a new order
+// Create creates a new order together with its discount and tax lines
 func (s *orderStore) Create(ctx context.Context, order *model.Order) error {
 	if order.ID == uuid.Nil {
 		order.ID = uuid.New()
@@ -32,10 +36,30 @@ func (s *orderStore) Create(ctx context.Context, order *model.Order) error {
 	return s.db.WithContext(ctx).Create(order).Error
 }
 
+// CreateAll creates several orders, with their discount and tax lines, in one transaction
+func (s *orderStore) CreateAll(ctx context.Context, orders []*model.Order) error {
+	now := time.Now()
+	for _, order := range orders {
+		if order.ID == uuid.Nil {
+			order.ID = uuid.New()
+		}
+		order.CreatedAt = now
+		order.UpdatedAt = now
+	}
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for _, order := range orders {
+			if err := tx.Create(order).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
 // GetByID retrieves an order by ID
 func (s *orderStore) GetByID(ctx context.Context, orderID uuid.UUID) (*model.Order, error) {
 	var order model.Order
-	err := s.db.WithContext(ctx).Where("id = ?", orderID).First(&order).Error
+	err := s.db.WithContext(ctx).Preload("Discounts").Preload("TaxLines").Where("id = ?", orderID).First(&order).Error
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, errors.New("order not found")
@@ -48,26 +72,130 @@ func (s *orderStore) GetByID(ctx context.Context, orderID uuid.UUID) (*model.Ord
 // GetByUserID retrieves all orders for a given user ID
 func (s *orderStore) GetByUserID(ctx context.Context, userID int) ([]*model.Order, error) {
 	var orders []*model.Order
-	err := s.db.WithContext(ctx).Where("user_id = ?", userID).Order("created_at DESC").Find(&orders).Error
+	err := s.db.WithContext(ctx).Preload("Discounts").Preload("TaxLines").Where("user_id = ?", userID).Order("created_at DESC").Find(&orders).Error
 	return orders, err
 }
 
 // GetAll retrieves all orders (for admin)
 func (s *orderStore) GetAll(ctx context.Context) ([]*model.Order, error) {
 	var orders []*model.Order
-	err := s.db.WithContext(ctx).Order("created_at DESC").Find(&orders).Error
+	err := s.db.WithContext(ctx).Preload("Discounts").Preload("TaxLines").Order("created_at DESC").Find(&orders).Error
+	return orders, err
+}
+
+// Search retrieves the orders matching filter, newest first
+func (s *orderStore) Search(ctx context.Context, filter model.OrderFilter) ([]*model.Order, error) {
+	var orders []*model.Order
+	err := s.filtered(ctx, filter).Preload("Discounts").Preload("TaxLines").Order("created_at DESC, id DESC").Find(&orders).Error
+	return orders, err
+}
+
+// Each pages through the matching orders by (created_at, id), oldest first, so orders placed
+// during an export are picked up at its end rather than shifting the pages
+func (s *orderStore) Each(ctx context.Context, filter model.OrderFilter, batchSize int, withProduct bool, fn func(orders []*model.Order) error) error {
+	var last *model.Order
+	for {
+		query := s.filtered(ctx, filter)
+		if last != nil {
+			query = query.Where("(created_at, id) > (?, ?)", last.CreatedAt, last.ID)
+		}
+		if withProduct {
+			query = query.Preload("Product", func(db *gorm.DB) *gorm.DB { return db.Unscoped() })
+		}
+		var batch []*model.Order
+		if err := query.Order("created_at, id").Limit(batchSize).Find(&batch).Error; err != nil {
+			return err
+		}
+		if len(batch) > 0 {
+			if err := fn(batch); err != nil {
+				return err
+			}
+		}
+		if len(batch) < batchSize {
+			return nil
+		}
+		last = batch[len(batch)-1]
+	}
+}
+
+// filtered applies filter's conditions
+func (s *orderStore) filtered(ctx context.Context, filter model.OrderFilter) *gorm.DB {
+	query := s.db.WithContext(ctx).Model(&model.Order{})
+	if filter.UserID != 0 {
+		query = query.Where("user_id = ?", filter.UserID)
+	}
+	if len(filter.Statuses) > 0 {
+		query = query.Where("current_status IN ?", filter.Statuses)
+	}
+	if filter.ProductID != nil {
+		query = query.Where("product_id = ?", *filter.ProductID)
+	}
+	if filter.From != nil {
+		query = query.Where("created_at >= ?", filter.From.UTC())
+	}
+	if filter.To != nil {
+		query = query.Where("created_at < ?", filter.To.UTC())
+	}
+	return query
+}
+
+// GetByStatus retrieves the orders in any of statuses, oldest first, so they are fulfilled in order
+func (s *orderStore) GetByStatus(ctx context.Context, statuses ...model.OrderStatus) ([]*model.Order, error) {
+	var orders []*model.Order
+	err := s.db.WithContext(ctx).Preload("Discounts").Preload("TaxLines").Where("current_status IN ?", statuses).Order("created_at, id").Find(&orders).Error
 	return orders, err
 }
 
-// UpdateStatus updates the order status
-func (s *orderStore) UpdateStatus(ctx context.Context, orderID uuid.UUID, status model.OrderStatus) error {
+// UpdateStatus updates the order status, conditional on its previous status, so of two
+// concurrent changes only the first applies
+func (s *orderStore) UpdateStatus(ctx context.Context, orderID uuid.UUID, previous, status model.OrderStatus) (bool, error) {
 	result := s.db.WithContext(ctx).
 		Model(&model.Order{}).
-		Where("id = ?", orderID).
+		Where("id = ? AND current_status = ?", orderID, previous).
 		Updates(map[string]interface{}{
 			"current_status": status,
 			"updated_at":     time.Now(),
 		})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+
+// GetWithoutPriceSnapshot retrieves orders that have no product snapshot yet, oldest first
+func (s *orderStore) GetWithoutPriceSnapshot(ctx context.Context) ([]*model.Order, error) {
+	var orders []*model.Order
+	err := s.db.WithContext(ctx).Where("sku = ''").Order("created_at ASC").Find(&orders).Error
+	return orders, err
+}
+
+// UpdatePricing writes an order's product snapshot and totals
+func (s *orderStore) UpdatePricing(ctx context.Context, order *model.Order) error {
+	order.UpdatedAt = time.Now()
+	result := s.db.WithContext(ctx).
+		Model(&model.Order{}).
+		Where("id = ?", order.ID).
+		Updates(map[string]interface{}{
+			"sku":                   order.SKU,
+			"product_name":          order.ProductName,
+			"unit_price_amount":     order.UnitPrice.Amount,
+			"unit_price_currency":   order.UnitPrice.Currency,
+			"price_estimated":       order.PriceEstimated,
+			"subtotal_amount":       order.Subtotal.Amount,
+			"subtotal_currency":     order.Subtotal.Currency,
+			"discount_amount":       order.Discount.Amount,
+			"discount_currency":     order.Discount.Currency,
+			"shipping_amount":       order.Shipping.Amount,
+			"shipping_currency":     order.Shipping.Currency,
+			"tax_amount":            order.Tax.Amount,
+			"tax_currency":          order.Tax.Currency,
+			"tax_included_amount":   order.TaxIncluded.Amount,
+			"tax_included_currency": order.TaxIncluded.Currency,
+			"total_amount":          order.Total.Amount,
+			"total_currency":        order.Total.Currency,
+			"updated_at":            order.UpdatedAt,
+		})
 	if result.Error != nil {
 		return result.Error
 	}
@@ -77,3 +205,42 @@ func (s *orderStore) UpdateStatus(ctx context.Context, orderID uuid.UUID, status
 	return nil
 }
 
+// CancelQuantity locks the order row (SELECT FOR UPDATE) so it is checked against concurrent
+// shipments and cancellations, then adds quantity to its cancelled units
+func (s *orderStore) CancelQuantity(ctx context.Context, orderID uuid.UUID, quantity int) (bool, error) {
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		order, err := lockOpenUnits(tx, orderID, quantity)
+		if err != nil {
+			return err
+		}
+		return tx.Model(&model.Order{}).
+			Where("id = ?", orderID).
+			Updates(map[string]interface{}{
+				"cancelled_quantity": order.CancelledQuantity + quantity,
+				"updated_at":         time.Now(),
+			}).Error
+	})
+	if errors.Is(err, errNotEnoughOpenUnits) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// lockOpenUnits locks the order row within tx and checks it has quantity units still open
+func lockOpenUnits(tx *gorm.DB, orderID uuid.UUID, quantity int) (*model.Order, error) {
+	var order model.Order
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Select("id", "quantity", "cancelled_quantity", "shipped_quantity").
+		Where("id = ?", orderID).
+		First(&order).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("order not found")
+		}
+		return nil, err
+	}
+	if quantity > order.OpenQuantity() {
+		return nil, errNotEnoughOpenUnits
+	}
+	return &order, nil
+}
@@ -0,0 +1,148 @@
+package datastore
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"oms/server/core/model"
+	"oms/server/core/types"
+)
+
+// errPaymentUnchanged rolls back a status update that lost a race or repeats a recorded event
+var errPaymentUnchanged = errors.New("payment unchanged")
+
+// paymentStore implements types.PaymentStore
+type paymentStore struct {
+	db *gorm.DB
+}
+
+// NewPaymentStore creates a new PaymentStore
+func NewPaymentStore(db *gorm.DB) types.PaymentStore {
+	return &paymentStore{db: db}
+}
+
+// withHistory preloads a payment's status changes in the order they happened
+func (s *paymentStore) withHistory(ctx context.Context) *gorm.DB {
+	return s.db.WithContext(ctx).Preload("History", func(db *gorm.DB) *gorm.DB {
+		return db.Order("created_at, id")
+	})
+}
+
+// Create creates a payment and the history entry for its initial status
+func (s *paymentStore) Create(ctx context.Context, payment *model.Payment) error {
+	if payment.ID == uuid.Nil {
+		payment.ID = uuid.New()
+	}
+	now := time.Now()
+	payment.CreatedAt = now
+	payment.UpdatedAt = now
+	payment.History = []model.PaymentStateLog{{
+		ID:        uuid.New(),
+		PaymentID: payment.ID,
+		NewStatus: payment.Status,
+		Amount:    payment.Amount,
+		Message:   payment.FailureReason,
+		CreatedAt: now,
+	}}
+	return s.db.WithContext(ctx).Create(payment).Error
+}
+
+// GetByID retrieves a payment by ID
+func (s *paymentStore) GetByID(ctx context.Context, paymentID uuid.UUID) (*model.Payment, error) {
+	var payment model.Payment
+	err := s.withHistory(ctx).Where("id = ?", paymentID).First(&payment).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("payment not found")
+		}
+		return nil, err
+	}
+	return &payment, nil
+}
+
+// GetByReference retrieves a payment by its provider and the provider's reference
+func (s *paymentStore) GetByReference(ctx context.Context, provider, reference string) (*model.Payment, error) {
+	var payment model.Payment
+	err := s.withHistory(ctx).Where("provider = ? AND reference = ?", provider, reference).First(&payment).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("payment not found")
+		}
+		return nil, err
+	}
+	return &payment, nil
+}
+
+// GetByOrderID retrieves an order's payments, newest first
+func (s *paymentStore) GetByOrderID(ctx context.Context, orderID uuid.UUID) ([]*model.Payment, error) {
+	var payments []*model.Payment
+	err := s.withHistory(ctx).
+		Where("order_id = ?", orderID).
+		Order("created_at DESC, id").
+		Find(&payments).Error
+	return payments, err
+}
+
+// UpdateStatus writes the payment's new state and history entry in one transaction. The update is
+// conditional on the previous status, so of two concurrent changes only the first applies.
+func (s *paymentStore) UpdateStatus(ctx context.Context, payment *model.Payment, entry *model.PaymentStateLog) (bool, error) {
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if entry.EventID != nil {
+			var count int64
+			if err := tx.Model(&model.PaymentStateLog{}).Where("event_id = ?", *entry.EventID).Count(&count).Error; err != nil {
+				return err
+			}
+			if count > 0 {
+				return errPaymentUnchanged
+			}
+		}
+
+		now := time.Now()
+		result := tx.Model(&model.Payment{}).
+			Where("id = ? AND status = ?", payment.ID, entry.PreviousStatus).
+			Updates(map[string]interface{}{
+				"status":            payment.Status,
+				"reference":         payment.Reference,
+				"captured_amount":   payment.Captured.Amount,
+				"captured_currency": payment.Captured.Currency,
+				"refunded_amount":   payment.Refunded.Amount,
+				"refunded_currency": payment.Refunded.Currency,
+				"failure_reason":    payment.FailureReason,
+				"updated_at":        now,
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errPaymentUnchanged
+		}
+
+		if entry.ID == uuid.Nil {
+			entry.ID = uuid.New()
+		}
+		entry.PaymentID = payment.ID
+		entry.CreatedAt = now
+		if err := tx.Create(entry).Error; err != nil {
+			return err
+		}
+		payment.UpdatedAt = now
+		return nil
+	})
+	if errors.Is(err, errPaymentUnchanged) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// ForgetEventsBefore clears the webhook event IDs of history entries recorded before the cutoff and
+// returns how many were cleared; the entries themselves are kept
+func (s *paymentStore) ForgetEventsBefore(ctx context.Context, before time.Time) (int64, error) {
+	result := s.db.WithContext(ctx).
+		Model(&model.PaymentStateLog{}).
+		Where("event_id IS NOT NULL AND created_at < ?", before).
+		Update("event_id", nil)
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,113 @@
+package datastore
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"oms/server/core/model"
+	"oms/server/core/types"
+)
+
+// productImportStore implements types.ProductImportStore
+type productImportStore struct {
+	db *gorm.DB
+}
+
+// NewProductImportStore creates a new ProductImportStore
+func NewProductImportStore(db *gorm.DB) types.ProductImportStore {
+	return &productImportStore{db: db}
+}
+
+// GetBySKUs retrieves the products with the SKUs, deleted or not
+func (s *productImportStore) GetBySKUs(ctx context.Context, skus []string) ([]*model.Product, error) {
+	var products []*model.Product
+	if len(skus) == 0 {
+		return products, nil
+	}
+	err := s.db.WithContext(ctx).Unscoped().Where("sku IN ?", skus).Find(&products).Error
+	return products, err
+}
+
+// GetInventory retrieves the inventory rows of the stock units
+func (s *productImportStore) GetInventory(ctx context.Context, productIDs []uuid.UUID) ([]*model.Inventory, error) {
+	var inventory []*model.Inventory
+	if len(productIDs) == 0 {
+		return inventory, nil
+	}
+	err := s.db.WithContext(ctx).Where("stock_unit_id IN ?", productIDs).Find(&inventory).Error
+	return inventory, err
+}
+
+// Apply saves every row or none of them
+func (s *productImportStore) Apply(ctx context.Context, rows []*model.ProductImportRow) error {
+	now := time.Now()
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for _, row := range rows {
+			product := row.Product
+			product.UpdatedAt = now
+			if row.New {
+				if product.ID == uuid.Nil {
+					product.ID = uuid.New()
+				}
+				product.CreatedAt = now
+				if err := tx.Create(product).Error; err != nil {
+					return fmt.Errorf("line %d: failed to create product %s: %w", row.Line, product.SKU, err)
+				}
+			} else {
+				result := tx.Unscoped().
+					Model(&model.Product{}).
+					Where("id = ?", product.ID).
+					Updates(map[string]interface{}{
+						"name":           product.Name,
+						"price_amount":   product.Price.Amount,
+						"price_currency": product.Price.Currency,
+						"metadata":       product.Metadata,
+						"tax_class":      product.TaxClass,
+						"updated_at":     product.UpdatedAt,
+						"deleted_at":     nil,
+					})
+				if result.Error != nil {
+					return fmt.Errorf("line %d: failed to update product %s: %w", row.Line, product.SKU, result.Error)
+				}
+				if result.RowsAffected == 0 {
+					return fmt.Errorf("line %d: product %s was deleted during the import", row.Line, product.SKU)
+				}
+				product.DeletedAt = gorm.DeletedAt{}
+			}
+
+			if err := s.saveStock(tx, row); err != nil {
+				return fmt.Errorf("line %d: failed to save the stock of product %s: %w", row.Line, product.SKU, err)
+			}
+		}
+		return nil
+	})
+}
+
+// saveStock creates or updates the row's inventory with the quantity and bin location it sets. New
+// products get an inventory row even without them, as when created one at a time.
+func (s *productImportStore) saveStock(tx *gorm.DB, row *model.ProductImportRow) error {
+	inventory := &model.Inventory{StockUnitID: row.Product.ID, ProductID: row.Product.ID}
+	var columns []string
+	if row.Quantity != nil {
+		inventory.Quantity = *row.Quantity
+		columns = append(columns, "quantity")
+	}
+	if row.BinLocation != nil {
+		inventory.BinLocation = *row.BinLocation
+		columns = append(columns, "bin_location")
+	}
+	if len(columns) == 0 {
+		if !row.New {
+			return nil
+		}
+		return tx.Clauses(clause.OnConflict{DoNothing: true}).Create(inventory).Error
+	}
+	return tx.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "stock_unit_id"}},
+		DoUpdates: clause.AssignmentColumns(columns),
+	}).Create(inventory).Error
+}
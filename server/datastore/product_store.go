@@ -3,12 +3,15 @@ package datastore
 import (
 	"context"
 	"errors"
+	"fmt"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
+	"gorm.io/gorm"
 	"oms/server/core/model"
 	"oms/server/core/types"
-	"gorm.io/gorm"
 )
 
 // productStore implements types.ProductStore
@@ -49,6 +52,118 @@ func (s *productStore) GetAll(ctx context.Context) ([]*model.Product, error) {
 	return products, nil
 }
 
+// productQuantityExpr is a product's available stock: the sum over its variants
+// when it has any, otherwise its own inventory row
+const productQuantityExpr = "COALESCE(vs.quantity, i.quantity, 0)"
+
+// productWithStockQuery selects live products joined with their inventory in a single query
+func (s *productStore) productWithStockQuery(ctx context.Context) *gorm.DB {
+	return s.db.WithContext(ctx).Unscoped().
+		Table("products AS p").
+		Select("p.*, " + productQuantityExpr + " AS quantity").
+		Joins("LEFT JOIN inventory i ON i.stock_unit_id = p.id").
+		Joins(`LEFT JOIN (
+			SELECT v.product_id, SUM(COALESCE(vi.quantity, 0)) AS quantity
+			FROM product_variants v
+			LEFT JOIN inventory vi ON vi.variant_id = v.id
+			WHERE v.deleted_at IS NULL
+			GROUP BY v.product_id
+		) vs ON vs.product_id = p.id`).
+		Where("p.deleted_at IS NULL")
+}
+
+// GetWithStock retrieves a product and its inventory quantity
+func (s *productStore) GetWithStock(ctx context.Context, productID uuid.UUID) (*model.ProductWithStock, error) {
+	var product model.ProductWithStock
+	err := s.productWithStockQuery(ctx).Where("p.id = ?", productID).Take(&product).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("product not found")
+		}
+		return nil, err
+	}
+	return &product, nil
+}
+
+// productSortColumns maps sort fields to SQL expressions and a parser for cursor values
+var productSortColumns = map[model.ProductSortField]struct {
+	column string
+	parse  func(value string) (interface{}, error)
+}{
+	model.ProductSortCreatedAt: {"p.created_at", func(v string) (interface{}, error) { return time.Parse(time.RFC3339Nano, v) }},
+	model.ProductSortName:      {"p.name", func(v string) (interface{}, error) { return v, nil }},
+	model.ProductSortPrice:     {"p.price_amount", func(v string) (interface{}, error) { return strconv.ParseInt(v, 10, 64) }},
+	model.ProductSortStock:     {productQuantityExpr, func(v string) (interface{}, error) { return strconv.Atoi(v) }},
+}
+
+// Search retrieves products matching query, joined with inventory, using keyset pagination
+func (s *productStore) Search(ctx context.Context, query model.ProductQuery, after *model.ProductCursor) ([]*model.ProductWithStock, error) {
+	sort, ok := productSortColumns[query.SortBy]
+	if !ok {
+		return nil, fmt.Errorf("unsupported sort field: %s", query.SortBy)
+	}
+
+	db := s.productWithStockQuery(ctx)
+
+	if query.Search != "" {
+		pattern := "%" + escapeLike(query.Search) + "%"
+		db = db.Where("(p.name ILIKE ? OR p.sku ILIKE ?)", pattern, pattern)
+	}
+	// A price bound also restricts results to its currency
+	if query.MinPrice != nil {
+		db = db.Where("p.price_currency = ? AND p.price_amount >= ?", query.MinPrice.Currency, query.MinPrice.Amount)
+	}
+	if query.MaxPrice != nil {
+		db = db.Where("p.price_currency = ? AND p.price_amount <= ?", query.MaxPrice.Currency, query.MaxPrice.Amount)
+	}
+	switch query.StockStatus {
+	case model.StockStatusInStock:
+		db = db.Where(productQuantityExpr + " > 0")
+	case model.StockStatusOutOfStock:
+		db = db.Where(productQuantityExpr + " = 0")
+	}
+	if len(query.CategoryIDs) > 0 {
+		db = db.Where("p.id IN (SELECT pc.product_id FROM product_categories pc WHERE pc.category_id IN ?)", query.CategoryIDs)
+	}
+	for key, value := range query.Metadata {
+		if value == "" {
+			db = db.Where("p.metadata ->> ? IS NOT NULL", key)
+		} else {
+			db = db.Where("p.metadata ->> ? = ?", key, value)
+		}
+	}
+
+	direction, comparison := "ASC", ">"
+	if query.SortDesc {
+		direction, comparison = "DESC", "<"
+	}
+	if after != nil {
+		value, err := sort.parse(after.Value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cursor: %w", err)
+		}
+		db = db.Where(fmt.Sprintf("(%s, p.id) %s (?, ?)", sort.column, comparison), value, after.ID)
+	}
+
+	var products []*model.ProductWithStock
+	err := db.
+		Order(fmt.Sprintf("%s %s, p.id %s", sort.column, direction, direction)).
+		Limit(query.Limit).
+		Find(&products).Error
+	if err != nil {
+		return nil, err
+	}
+	if products == nil {
+		products = []*model.ProductWithStock{}
+	}
+	return products, nil
+}
+
+// escapeLike escapes LIKE wildcards so user input is matched literally
+func escapeLike(value string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(value)
+}
+
 // Create creates a new product
 func (s *productStore) Create(ctx context.Context, product *model.Product) error {
 	if product.ID == uuid.Nil {
@@ -67,11 +182,14 @@ func (s *productStore) Update(ctx context.Context, productID uuid.UUID, product
 		Model(&model.Product{}).
 		Where("id = ?", productID).
 		Updates(map[string]interface{}{
-			"sku":       product.SKU,
-			"name":      product.Name,
-			"price":     product.Price,
-			"metadata":  product.Metadata,
-			"updated_at": product.UpdatedAt,
+			"sku":            product.SKU,
+			"name":           product.Name,
+			"price_amount":   product.Price.Amount,
+			"price_currency": product.Price.Currency,
+			"metadata":       product.Metadata,
+			"options":        product.Options,
+			"tax_class":      product.TaxClass,
+			"updated_at":     product.UpdatedAt,
 		})
 	if result.Error != nil {
 		return result.Error
@@ -93,4 +211,3 @@ func (s *productStore) Delete(ctx context.Context, productID uuid.UUID) error {
 	}
 	return nil
 }
-
@@ -0,0 +1,102 @@
+package datastore
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"oms/server/core/model"
+	"oms/server/core/types"
+)
+
+// productVariantStore implements types.ProductVariantStore
+type productVariantStore struct {
+	db *gorm.DB
+}
+
+// NewProductVariantStore creates a new ProductVariantStore
+func NewProductVariantStore(db *gorm.DB) types.ProductVariantStore {
+	return &productVariantStore{db: db}
+}
+
+// GetByID retrieves a variant by ID
+func (s *productVariantStore) GetByID(ctx context.Context, variantID uuid.UUID) (*model.ProductVariant, error) {
+	var variant model.ProductVariant
+	err := s.db.WithContext(ctx).Where("id = ?", variantID).First(&variant).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("variant not found")
+		}
+		return nil, err
+	}
+	return &variant, nil
+}
+
+// GetByProductID retrieves all variants of a product
+func (s *productVariantStore) GetByProductID(ctx context.Context, productID uuid.UUID) ([]*model.ProductVariant, error) {
+	var variants []*model.ProductVariant
+	err := s.db.WithContext(ctx).Where("product_id = ?", productID).Order("sku").Find(&variants).Error
+	return variants, err
+}
+
+// GetWithStockByProductIDs retrieves the variants of several products joined with their inventory
+func (s *productVariantStore) GetWithStockByProductIDs(ctx context.Context, productIDs []uuid.UUID) ([]*model.ProductVariantWithStock, error) {
+	var variants []*model.ProductVariantWithStock
+	if len(productIDs) == 0 {
+		return variants, nil
+	}
+	err := s.db.WithContext(ctx).Unscoped().
+		Table("product_variants AS v").
+		Select("v.*, COALESCE(i.quantity, 0) AS quantity").
+		Joins("LEFT JOIN inventory i ON i.variant_id = v.id").
+		Where("v.deleted_at IS NULL AND v.product_id IN ?", productIDs).
+		Order("v.product_id, v.sku").
+		Find(&variants).Error
+	return variants, err
+}
+
+// Create creates a new variant
+func (s *productVariantStore) Create(ctx context.Context, variant *model.ProductVariant) error {
+	if variant.ID == uuid.Nil {
+		variant.ID = uuid.New()
+	}
+	now := time.Now()
+	variant.CreatedAt = now
+	variant.UpdatedAt = now
+	return s.db.WithContext(ctx).Create(variant).Error
+}
+
+// Update updates a variant's SKU, options and price override
+func (s *productVariantStore) Update(ctx context.Context, variant *model.ProductVariant) error {
+	variant.UpdatedAt = time.Now()
+	result := s.db.WithContext(ctx).
+		Model(&model.ProductVariant{}).
+		Where("id = ?", variant.ID).
+		Updates(map[string]interface{}{
+			"sku":        variant.SKU,
+			"options":    variant.Options,
+			"price":      variant.Price,
+			"updated_at": variant.UpdatedAt,
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("variant not found")
+	}
+	return nil
+}
+
+// Delete deletes a variant (soft delete using GORM's DeletedAt)
+func (s *productVariantStore) Delete(ctx context.Context, variantID uuid.UUID) error {
+	result := s.db.WithContext(ctx).Delete(&model.ProductVariant{}, "id = ?", variantID)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("variant not found")
+	}
+	return nil
+}
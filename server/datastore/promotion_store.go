@@ -0,0 +1,220 @@
+package datastore
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"oms/server/core/model"
+	"oms/server/core/types"
+)
+
+// errPromotionExhausted rolls back a redemption transaction when a limit is reached
+var errPromotionExhausted = errors.New("promotion limit reached")
+
+// promotionStore implements types.PromotionStore
+type promotionStore struct {
+	db *gorm.DB
+}
+
+// NewPromotionStore creates a new PromotionStore
+func NewPromotionStore(db *gorm.DB) types.PromotionStore {
+	return &promotionStore{db: db}
+}
+
+// GetByID retrieves a promotion by ID
+func (s *promotionStore) GetByID(ctx context.Context, promotionID uuid.UUID) (*model.Promotion, error) {
+	var promotion model.Promotion
+	err := s.db.WithContext(ctx).Where("id = ?", promotionID).First(&promotion).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("promotion not found")
+		}
+		return nil, err
+	}
+	return &promotion, nil
+}
+
+// GetByCode retrieves a coupon by its upper-case code
+func (s *promotionStore) GetByCode(ctx context.Context, code string) (*model.Promotion, error) {
+	var promotion model.Promotion
+	err := s.db.WithContext(ctx).Where("code = ?", code).First(&promotion).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("promotion not found")
+		}
+		return nil, err
+	}
+	return &promotion, nil
+}
+
+// GetAll retrieves all promotions in evaluation order
+func (s *promotionStore) GetAll(ctx context.Context) ([]*model.Promotion, error) {
+	var promotions []*model.Promotion
+	err := s.db.WithContext(ctx).Order("priority, created_at, id").Find(&promotions).Error
+	return promotions, err
+}
+
+// GetAutomatic retrieves the active promotions that have no code; validity windows are checked by the caller
+func (s *promotionStore) GetAutomatic(ctx context.Context) ([]*model.Promotion, error) {
+	var promotions []*model.Promotion
+	err := s.db.WithContext(ctx).
+		Where("code IS NULL AND active").
+		Order("priority, created_at, id").
+		Find(&promotions).Error
+	return promotions, err
+}
+
+// Create creates a new promotion
+func (s *promotionStore) Create(ctx context.Context, promotion *model.Promotion) error {
+	if promotion.ID == uuid.Nil {
+		promotion.ID = uuid.New()
+	}
+	now := time.Now()
+	promotion.CreatedAt = now
+	promotion.UpdatedAt = now
+	promotion.Redemptions = 0
+	return s.db.WithContext(ctx).Create(promotion).Error
+}
+
+// Update replaces a promotion's rule; the redemption count is left untouched
+func (s *promotionStore) Update(ctx context.Context, promotion *model.Promotion) error {
+	promotion.UpdatedAt = time.Now()
+	result := s.db.WithContext(ctx).
+		Model(&model.Promotion{}).
+		Where("id = ?", promotion.ID).
+		Updates(map[string]interface{}{
+			"name":                  promotion.Name,
+			"code":                  promotion.Code,
+			"type":                  promotion.Type,
+			"active":                promotion.Active,
+			"priority":              promotion.Priority,
+			"percent_off":           promotion.PercentOff,
+			"amount_off_amount":     promotion.AmountOff.Amount,
+			"amount_off_currency":   promotion.AmountOff.Currency,
+			"buy_quantity":          promotion.BuyQuantity,
+			"get_quantity":          promotion.GetQuantity,
+			"min_subtotal_amount":   promotion.MinSubtotal.Amount,
+			"min_subtotal_currency": promotion.MinSubtotal.Currency,
+			"product_ids":           promotion.ProductIDs,
+			"category_ids":          promotion.CategoryIDs,
+			"usage_limit":           promotion.UsageLimit,
+			"per_user_limit":        promotion.PerUserLimit,
+			"starts_at":             promotion.StartsAt,
+			"ends_at":               promotion.EndsAt,
+			"updated_at":            promotion.UpdatedAt,
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("promotion not found")
+	}
+	return nil
+}
+
+// Delete deletes a promotion and its redemption records; discount lines already on orders are kept
+func (s *promotionStore) Delete(ctx context.Context, promotionID uuid.UUID) error {
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("promotion_id = ?", promotionID).Delete(&model.PromotionRedemption{}).Error; err != nil {
+			return err
+		}
+		result := tx.Delete(&model.Promotion{}, "id = ?", promotionID)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errors.New("promotion not found")
+		}
+		return nil
+	})
+}
+
+// CountUserRedemptions counts how often a user has redeemed a promotion
+func (s *promotionStore) CountUserRedemptions(ctx context.Context, promotionID uuid.UUID, userID int) (int, error) {
+	var count int64
+	err := s.db.WithContext(ctx).
+		Model(&model.PromotionRedemption{}).
+		Where("promotion_id = ? AND user_id = ?", promotionID, userID).
+		Count(&count).Error
+	return int(count), err
+}
+
+// Redeem locks each promotion row (SELECT FOR UPDATE), checks its usage and per-user limits,
+// then records the redemption and increments the count. Nothing is written if any limit is reached.
+func (s *promotionStore) Redeem(ctx context.Context, redemptions []*model.PromotionRedemption) (*model.Promotion, error) {
+	var exhausted *model.Promotion
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for _, redemption := range redemptions {
+			var promotion model.Promotion
+			err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+				Where("id = ?", redemption.PromotionID).
+				First(&promotion).Error
+			if err != nil {
+				if errors.Is(err, gorm.ErrRecordNotFound) {
+					return errors.New("promotion not found")
+				}
+				return err
+			}
+
+			if promotion.UsageLimit > 0 && promotion.Redemptions >= promotion.UsageLimit {
+				exhausted = &promotion
+				return errPromotionExhausted
+			}
+			if promotion.PerUserLimit > 0 {
+				var count int64
+				err := tx.Model(&model.PromotionRedemption{}).
+					Where("promotion_id = ? AND user_id = ?", promotion.ID, redemption.UserID).
+					Count(&count).Error
+				if err != nil {
+					return err
+				}
+				if int(count) >= promotion.PerUserLimit {
+					exhausted = &promotion
+					return errPromotionExhausted
+				}
+			}
+
+			if redemption.ID == uuid.Nil {
+				redemption.ID = uuid.New()
+			}
+			redemption.CreatedAt = time.Now()
+			if err := tx.Create(redemption).Error; err != nil {
+				return err
+			}
+			err = tx.Model(&model.Promotion{}).
+				Where("id = ?", promotion.ID).
+				UpdateColumn("redemption_count", gorm.Expr("redemption_count + 1")).Error
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if errors.Is(err, errPromotionExhausted) {
+		return exhausted, nil
+	}
+	return nil, err
+}
+
+// ReleaseRedemptions deletes an order's redemptions and decrements the promotions' counts
+func (s *promotionStore) ReleaseRedemptions(ctx context.Context, orderID uuid.UUID) error {
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var redemptions []*model.PromotionRedemption
+		if err := tx.Where("order_id = ?", orderID).Find(&redemptions).Error; err != nil {
+			return err
+		}
+		for _, redemption := range redemptions {
+			err := tx.Model(&model.Promotion{}).
+				Where("id = ? AND redemption_count > 0", redemption.PromotionID).
+				UpdateColumn("redemption_count", gorm.Expr("redemption_count - 1")).Error
+			if err != nil {
+				return err
+			}
+		}
+		return tx.Where("order_id = ?", orderID).Delete(&model.PromotionRedemption{}).Error
+	})
+}
@@ -0,0 +1,124 @@
+package datastore
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"oms/server/core/model"
+	"oms/server/core/types"
+)
+
+// errReturnNotAllowed rolls back creating a return the order cannot take
+var errReturnNotAllowed = errors.New("return not allowed")
+
+// returnRequestStore implements types.ReturnRequestStore
+type returnRequestStore struct {
+	db *gorm.DB
+}
+
+// NewReturnRequestStore creates a new ReturnRequestStore
+func NewReturnRequestStore(db *gorm.DB) types.ReturnRequestStore {
+	return &returnRequestStore{db: db}
+}
+
+// Create locks the order row (SELECT FOR UPDATE) so concurrent requests for the same order are
+// checked one at a time, then records the return if the order has no open return and enough
+// units left that are not already returned or being returned
+func (s *returnRequestStore) Create(ctx context.Context, request *model.ReturnRequest) (bool, error) {
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var order model.Order
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Select("id", "quantity", "cancelled_quantity").
+			Where("id = ?", request.OrderID).
+			First(&order).Error
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errors.New("order not found")
+			}
+			return err
+		}
+
+		var existing []model.ReturnRequest
+		err = tx.Select("status", "quantity").
+			Where("order_id = ? AND status <> ?", request.OrderID, model.ReturnStatusRejected).
+			Find(&existing).Error
+		if err != nil {
+			return err
+		}
+		taken := 0
+		for _, other := range existing {
+			if other.Status.IsOpen() {
+				return errReturnNotAllowed
+			}
+			taken += other.Quantity
+		}
+		if taken+request.Quantity > order.ActiveQuantity() {
+			return errReturnNotAllowed
+		}
+
+		if request.ID == uuid.Nil {
+			request.ID = uuid.New()
+		}
+		now := time.Now()
+		request.CreatedAt = now
+		request.UpdatedAt = now
+		return tx.Create(request).Error
+	})
+	if errors.Is(err, errReturnNotAllowed) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// GetByID retrieves a return request by ID
+func (s *returnRequestStore) GetByID(ctx context.Context, returnID uuid.UUID) (*model.ReturnRequest, error) {
+	var request model.ReturnRequest
+	err := s.db.WithContext(ctx).Where("id = ?", returnID).First(&request).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("return request not found")
+		}
+		return nil, err
+	}
+	return &request, nil
+}
+
+// GetByOrderID retrieves an order's return requests, oldest first
+func (s *returnRequestStore) GetByOrderID(ctx context.Context, orderID uuid.UUID) ([]*model.ReturnRequest, error) {
+	var requests []*model.ReturnRequest
+	err := s.db.WithContext(ctx).
+		Where("order_id = ?", orderID).
+		Order("created_at, id").
+		Find(&requests).Error
+	return requests, err
+}
+
+// UpdateStatus writes the return's new state, conditional on its previous status, so of two
+// concurrent changes only the first applies
+func (s *returnRequestStore) UpdateStatus(ctx context.Context, request *model.ReturnRequest, previous model.ReturnStatus) (bool, error) {
+	now := time.Now()
+	result := s.db.WithContext(ctx).Model(&model.ReturnRequest{}).
+		Where("id = ? AND status = ?", request.ID, previous).
+		Updates(map[string]interface{}{
+			"status":             request.Status,
+			"restocked_quantity": request.RestockedQuantity,
+			"damaged_quantity":   request.DamagedQuantity,
+			"refund_amount":      request.RefundAmount.Amount,
+			"refund_currency":    request.RefundAmount.Currency,
+			"resolution_note":    request.ResolutionNote,
+			"handled_by":         request.HandledBy,
+			"updated_at":         now,
+		})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return false, nil
+	}
+	request.UpdatedAt = now
+	return true, nil
+}
@@ -0,0 +1,169 @@
+package datastore
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"oms/server/core/model"
+	"oms/server/core/types"
+)
+
+// errEventRecorded rolls back RecordEvent for an event the shipment already has
+var errEventRecorded = errors.New("tracking event already recorded")
+
+// shipmentStore implements types.ShipmentStore
+type shipmentStore struct {
+	db *gorm.DB
+}
+
+// NewShipmentStore creates a new ShipmentStore
+func NewShipmentStore(db *gorm.DB) types.ShipmentStore {
+	return &shipmentStore{db: db}
+}
+
+// Create locks the order row (SELECT FOR UPDATE) so it is checked against concurrent shipments
+// and cancellations, then records the shipment and adds its quantity to the order's shipped units
+func (s *shipmentStore) Create(ctx context.Context, shipment *model.Shipment) (bool, error) {
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		order, err := lockOpenUnits(tx, shipment.OrderID, shipment.Quantity)
+		if err != nil {
+			return err
+		}
+
+		if shipment.ID == uuid.Nil {
+			shipment.ID = uuid.New()
+		}
+		now := time.Now()
+		shipment.CreatedAt = now
+		shipment.UpdatedAt = now
+		if shipment.ShippedAt.IsZero() {
+			shipment.ShippedAt = now
+		}
+		if shipment.Status == "" {
+			shipment.Status = model.TrackingStatusShipped
+		}
+		if err := tx.Create(shipment).Error; err != nil {
+			return err
+		}
+		return tx.Model(&model.Order{}).
+			Where("id = ?", shipment.OrderID).
+			Updates(map[string]interface{}{
+				"shipped_quantity": order.ShippedQuantity + shipment.Quantity,
+				"updated_at":       now,
+			}).Error
+	})
+	if errors.Is(err, errNotEnoughOpenUnits) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// GetByOrderID retrieves an order's shipments, oldest first, with their tracking events in the order they occurred
+func (s *shipmentStore) GetByOrderID(ctx context.Context, orderID uuid.UUID) ([]*model.Shipment, error) {
+	var shipments []*model.Shipment
+	err := s.db.WithContext(ctx).
+		Preload("Events", func(db *gorm.DB) *gorm.DB {
+			return db.Order("occurred_at, created_at")
+		}).
+		Where("order_id = ?", orderID).
+		Order("created_at, id").
+		Find(&shipments).Error
+	return shipments, err
+}
+
+// GetByTrackingNumber retrieves the shipment a carrier knows by trackingNumber
+func (s *shipmentStore) GetByTrackingNumber(ctx context.Context, carrier, trackingNumber string) (*model.Shipment, error) {
+	var shipment model.Shipment
+	err := s.db.WithContext(ctx).
+		Where("carrier = ? AND tracking_number = ?", carrier, trackingNumber).
+		Order("created_at DESC").
+		First(&shipment).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("shipment not found")
+		}
+		return nil, err
+	}
+	return &shipment, nil
+}
+
+// RecordEvent locks the shipment row (SELECT FOR UPDATE) so concurrent events for one shipment
+// are applied one at a time, records the event and brings the shipment's status up to date
+func (s *shipmentStore) RecordEvent(ctx context.Context, shipment *model.Shipment, event *model.TrackingEvent) (bool, error) {
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var current model.Shipment
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ?", shipment.ID).
+			First(&current).Error
+		if err != nil {
+			return err
+		}
+
+		var count int64
+		err = tx.Model(&model.TrackingEvent{}).
+			Where("shipment_id = ? AND event_id = ?", current.ID, event.EventID).
+			Count(&count).Error
+		if err != nil {
+			return err
+		}
+		if count > 0 {
+			*shipment = current
+			return errEventRecorded
+		}
+
+		now := time.Now()
+		if event.ID == uuid.Nil {
+			event.ID = uuid.New()
+		}
+		event.ShipmentID = current.ID
+		event.CreatedAt = now
+		if err := tx.Create(event).Error; err != nil {
+			return err
+		}
+
+		// Events may arrive out of order: only a later one moves the status, and delivery is final
+		occurredAt := event.OccurredAt
+		if event.Status == model.TrackingStatusDelivered && current.DeliveredAt == nil {
+			current.Status = event.Status
+			current.DeliveredAt = &occurredAt
+		} else if current.DeliveredAt == nil && (current.TrackedAt == nil || !occurredAt.Before(*current.TrackedAt)) {
+			current.Status = event.Status
+		}
+		if current.TrackedAt == nil || occurredAt.After(*current.TrackedAt) {
+			current.TrackedAt = &occurredAt
+		}
+		current.UpdatedAt = now
+		err = tx.Model(&model.Shipment{}).
+			Where("id = ?", current.ID).
+			Updates(map[string]interface{}{
+				"status":       current.Status,
+				"delivered_at": current.DeliveredAt,
+				"tracked_at":   current.TrackedAt,
+				"updated_at":   now,
+			}).Error
+		if err != nil {
+			return err
+		}
+		*shipment = current
+		return nil
+	})
+	if errors.Is(err, errEventRecorded) {
+		return false, nil
+	}
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return false, errors.New("shipment not found")
+	}
+	return err == nil, err
+}
+
+// DeleteEventsDeliveredBefore deletes the tracking events of shipments delivered before the cutoff
+// and returns how many were deleted
+func (s *shipmentStore) DeleteEventsDeliveredBefore(ctx context.Context, before time.Time) (int64, error) {
+	delivered := s.db.Model(&model.Shipment{}).Select("id").Where("delivered_at < ?", before)
+	result := s.db.WithContext(ctx).Where("shipment_id IN (?)", delivered).Delete(&model.TrackingEvent{})
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,124 @@
+package datastore
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"oms/server/core/model"
+	"oms/server/core/types"
+)
+
+// shippingZoneStore implements types.ShippingZoneStore
+type shippingZoneStore struct {
+	db *gorm.DB
+}
+
+// NewShippingZoneStore creates a new ShippingZoneStore
+func NewShippingZoneStore(db *gorm.DB) types.ShippingZoneStore {
+	return &shippingZoneStore{db: db}
+}
+
+// withRates preloads a zone's rates by option and weight band
+func withRates(db *gorm.DB) *gorm.DB {
+	return db.Preload("Rates", func(db *gorm.DB) *gorm.DB {
+		return db.Order("code, min_weight_grams")
+	})
+}
+
+// GetByID retrieves a shipping zone by ID
+func (s *shippingZoneStore) GetByID(ctx context.Context, zoneID uuid.UUID) (*model.ShippingZone, error) {
+	var zone model.ShippingZone
+	err := withRates(s.db.WithContext(ctx)).Where("id = ?", zoneID).First(&zone).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("shipping zone not found")
+		}
+		return nil, err
+	}
+	return &zone, nil
+}
+
+// GetAll retrieves all shipping zones
+func (s *shippingZoneStore) GetAll(ctx context.Context) ([]*model.ShippingZone, error) {
+	var zones []*model.ShippingZone
+	err := withRates(s.db.WithContext(ctx)).Order("country, postcode_prefix").Find(&zones).Error
+	return zones, err
+}
+
+// GetByCountry retrieves the shipping zones of one country, including its postcode zones
+func (s *shippingZoneStore) GetByCountry(ctx context.Context, country string) ([]*model.ShippingZone, error) {
+	var zones []*model.ShippingZone
+	err := withRates(s.db.WithContext(ctx)).Where("country = ?", country).Order("postcode_prefix").Find(&zones).Error
+	return zones, err
+}
+
+// Create creates a new shipping zone with its rates
+func (s *shippingZoneStore) Create(ctx context.Context, zone *model.ShippingZone) error {
+	if zone.ID == uuid.Nil {
+		zone.ID = uuid.New()
+	}
+	now := time.Now()
+	zone.CreatedAt = now
+	zone.UpdatedAt = now
+	prepareRates(zone, now)
+	return s.db.WithContext(ctx).Create(zone).Error
+}
+
+// Update replaces a shipping zone's scope and rates
+func (s *shippingZoneStore) Update(ctx context.Context, zone *model.ShippingZone) error {
+	now := time.Now()
+	zone.UpdatedAt = now
+	prepareRates(zone, now)
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&model.ShippingZone{}).
+			Where("id = ?", zone.ID).
+			Updates(map[string]interface{}{
+				"name":            zone.Name,
+				"country":         zone.Country,
+				"postcode_prefix": zone.PostcodePrefix,
+				"updated_at":      zone.UpdatedAt,
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errors.New("shipping zone not found")
+		}
+		if err := tx.Where("zone_id = ?", zone.ID).Delete(&model.ShippingRate{}).Error; err != nil {
+			return err
+		}
+		if len(zone.Rates) == 0 {
+			return nil
+		}
+		return tx.Create(&zone.Rates).Error
+	})
+}
+
+// Delete deletes a shipping zone and its rates; options already chosen on orders are kept
+func (s *shippingZoneStore) Delete(ctx context.Context, zoneID uuid.UUID) error {
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("zone_id = ?", zoneID).Delete(&model.ShippingRate{}).Error; err != nil {
+			return err
+		}
+		result := tx.Delete(&model.ShippingZone{}, "id = ?", zoneID)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errors.New("shipping zone not found")
+		}
+		return nil
+	})
+}
+
+// prepareRates gives a zone's rates fresh IDs under the zone
+func prepareRates(zone *model.ShippingZone, now time.Time) {
+	for i := range zone.Rates {
+		zone.Rates[i].ID = uuid.New()
+		zone.Rates[i].ZoneID = zone.ID
+		zone.Rates[i].CreatedAt = now
+	}
+}
@@ -0,0 +1,136 @@
+package datastore
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"oms/server/core/model"
+	"oms/server/core/types"
+)
+
+// stockAlertStore implements types.StockAlertStore
+type stockAlertStore struct {
+	db *gorm.DB
+}
+
+// NewStockAlertStore creates a new StockAlertStore
+func NewStockAlertStore(db *gorm.DB) types.StockAlertStore {
+	return &stockAlertStore{db: db}
+}
+
+// Open inserts the alert, leaning on the unique index over stock units' unresolved alerts to skip it
+// when there is one already
+func (s *stockAlertStore) Open(ctx context.Context, alert *model.StockAlert) (bool, error) {
+	if alert.ID == uuid.Nil {
+		alert.ID = uuid.New()
+	}
+	result := s.db.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns:     []clause.Column{{Name: "product_id"}},
+			TargetWhere: clause.Where{Exprs: []clause.Expression{clause.Neq{Column: "status", Value: model.StockAlertResolved}}},
+			DoNothing:   true,
+		}).
+		Create(alert)
+	return result.RowsAffected > 0, result.Error
+}
+
+// ResolveOpen resolves the stock unit's unresolved alert
+func (s *stockAlertStore) ResolveOpen(ctx context.Context, productID uuid.UUID, at time.Time) (int64, error) {
+	result := s.db.WithContext(ctx).Model(&model.StockAlert{}).
+		Where("product_id = ? AND status <> ?", productID, model.StockAlertResolved).
+		Updates(map[string]interface{}{
+			"status":      model.StockAlertResolved,
+			"resolved_at": at.UTC(),
+		})
+	return result.RowsAffected, result.Error
+}
+
+// GetByID retrieves an alert by ID
+func (s *stockAlertStore) GetByID(ctx context.Context, alertID uuid.UUID) (*model.StockAlert, error) {
+	var alert model.StockAlert
+	err := s.db.WithContext(ctx).Where("id = ?", alertID).First(&alert).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("stock alert not found")
+		}
+		return nil, err
+	}
+	return &alert, nil
+}
+
+// Search retrieves the alerts matching filter, newest first
+func (s *stockAlertStore) Search(ctx context.Context, filter model.StockAlertFilter) ([]*model.StockAlert, error) {
+	db := s.db.WithContext(ctx)
+	if len(filter.Statuses) > 0 {
+		db = db.Where("status IN ?", filter.Statuses)
+	}
+	if filter.ProductID != nil {
+		db = db.Where("product_id = ?", *filter.ProductID)
+	}
+	if filter.Limit > 0 {
+		db = db.Limit(filter.Limit)
+	}
+	var alerts []*model.StockAlert
+	err := db.Order("created_at DESC, id DESC").Find(&alerts).Error
+	if err != nil {
+		return nil, err
+	}
+	if alerts == nil {
+		alerts = []*model.StockAlert{}
+	}
+	return alerts, nil
+}
+
+// Update saves an alert's status, acknowledgement and resolution
+func (s *stockAlertStore) Update(ctx context.Context, alert *model.StockAlert) error {
+	result := s.db.WithContext(ctx).Model(&model.StockAlert{}).
+		Where("id = ?", alert.ID).
+		Updates(map[string]interface{}{
+			"status":          alert.Status,
+			"acknowledged_by": alert.AcknowledgedBy,
+			"acknowledged_at": alert.AcknowledgedAt,
+			"resolved_at":     alert.ResolvedAt,
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("stock alert not found")
+	}
+	return nil
+}
+
+// GetLowStock joins low inventory rows with the product or variant they stock and their unresolved alert
+func (s *stockAlertStore) GetLowStock(ctx context.Context, stockUnitIDs []uuid.UUID) ([]*model.LowStockItem, error) {
+	db := s.db.WithContext(ctx).
+		Table("inventory AS i").
+		Select(`i.stock_unit_id,
+			p.id AS product_id,
+			v.id AS variant_id,
+			COALESCE(v.sku, p.sku) AS sku,
+			p.name,
+			i.quantity, i.reorder_point, i.target_level, i.bin_location,
+			a.id AS alert_id, a.status AS alert_status`).
+		Joins("LEFT JOIN products p ON p.id = i.product_id AND p.deleted_at IS NULL").
+		Joins("LEFT JOIN product_variants v ON v.id = i.variant_id AND v.deleted_at IS NULL").
+		Joins("LEFT JOIN stock_alerts a ON a.product_id = i.stock_unit_id AND a.status <> ?", model.StockAlertResolved).
+		Where("i.reorder_point IS NOT NULL AND i.quantity <= i.reorder_point").
+		Where("p.id IS NOT NULL AND (i.variant_id IS NULL OR v.id IS NOT NULL)")
+	if stockUnitIDs != nil {
+		db = db.Where("i.stock_unit_id IN ?", stockUnitIDs)
+	}
+
+	var items []*model.LowStockItem
+	err := db.Order("i.quantity - i.reorder_point, sku").Find(&items).Error
+	if err != nil {
+		return nil, err
+	}
+	if items == nil {
+		items = []*model.LowStockItem{}
+	}
+	return items, nil
+}
@@ -0,0 +1,96 @@
+package datastore
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"oms/server/core/model"
+	"oms/server/core/types"
+)
+
+// taxRuleStore implements types.TaxRuleStore
+type taxRuleStore struct {
+	db *gorm.DB
+}
+
+// NewTaxRuleStore creates a new TaxRuleStore
+func NewTaxRuleStore(db *gorm.DB) types.TaxRuleStore {
+	return &taxRuleStore{db: db}
+}
+
+// GetByID retrieves a tax rule by ID
+func (s *taxRuleStore) GetByID(ctx context.Context, ruleID uuid.UUID) (*model.TaxRule, error) {
+	var rule model.TaxRule
+	err := s.db.WithContext(ctx).Where("id = ?", ruleID).First(&rule).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("tax rule not found")
+		}
+		return nil, err
+	}
+	return &rule, nil
+}
+
+// GetAll retrieves all tax rules
+func (s *taxRuleStore) GetAll(ctx context.Context) ([]*model.TaxRule, error) {
+	var rules []*model.TaxRule
+	err := s.db.WithContext(ctx).Order("country, region, tax_class").Find(&rules).Error
+	return rules, err
+}
+
+// GetByCountry retrieves the tax rules for one country, including its regional rules
+func (s *taxRuleStore) GetByCountry(ctx context.Context, country string) ([]*model.TaxRule, error) {
+	var rules []*model.TaxRule
+	err := s.db.WithContext(ctx).Where("country = ?", country).Order("region, tax_class").Find(&rules).Error
+	return rules, err
+}
+
+// Create creates a new tax rule
+func (s *taxRuleStore) Create(ctx context.Context, rule *model.TaxRule) error {
+	if rule.ID == uuid.Nil {
+		rule.ID = uuid.New()
+	}
+	now := time.Now()
+	rule.CreatedAt = now
+	rule.UpdatedAt = now
+	return s.db.WithContext(ctx).Create(rule).Error
+}
+
+// Update replaces a tax rule's scope and rate
+func (s *taxRuleStore) Update(ctx context.Context, rule *model.TaxRule) error {
+	rule.UpdatedAt = time.Now()
+	result := s.db.WithContext(ctx).
+		Model(&model.TaxRule{}).
+		Where("id = ?", rule.ID).
+		Updates(map[string]interface{}{
+			"name":       rule.Name,
+			"country":    rule.Country,
+			"region":     rule.Region,
+			"tax_class":  rule.TaxClass,
+			"rate":       rule.Rate,
+			"inclusive":  rule.Inclusive,
+			"updated_at": rule.UpdatedAt,
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("tax rule not found")
+	}
+	return nil
+}
+
+// Delete deletes a tax rule; tax lines already on orders are kept
+func (s *taxRuleStore) Delete(ctx context.Context, ruleID uuid.UUID) error {
+	result := s.db.WithContext(ctx).Delete(&model.TaxRule{}, "id = ?", ruleID)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("tax rule not found")
+	}
+	return nil
+}
@@ -0,0 +1,43 @@
+package logging
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+)
+
+// level is shared by every handler so it can be changed at runtime
+var level = new(slog.LevelVar)
+
+// Setup installs a structured slog logger as the process default.
+// The standard library log package is routed through it as well.
+func Setup(levelName, format string) error {
+	if err := SetLevel(levelName); err != nil {
+		return err
+	}
+
+	opts := &slog.HandlerOptions{Level: level}
+	var handler slog.Handler
+	switch format {
+	case "json":
+		handler = slog.NewJSONHandler(os.Stdout, opts)
+	case "text", "":
+		handler = slog.NewTextHandler(os.Stdout, opts)
+	default:
+		return fmt.Errorf("unsupported log format: %s", format)
+	}
+
+	slog.SetDefault(slog.New(handler))
+	return nil
+}
+
+// SetLevel changes the minimum level of the default logger
+func SetLevel(levelName string) error {
+	var l slog.Level
+	if err := l.UnmarshalText([]byte(strings.ToUpper(levelName))); err != nil {
+		return fmt.Errorf("invalid log level %q: %w", levelName, err)
+	}
+	level.Set(l)
+	return nil
+}
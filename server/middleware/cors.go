@@ -2,24 +2,251 @@ package middleware
 
 import (
 	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/mux"
+	"oms/server/api/v1/helpers"
 )
 
-// CORSMiddleware adds CORS headers to allow frontend requests
-func CORSMiddleware(next http.Handler) http.Handler {
+// CORSPolicy describes which cross-origin requests are allowed
+type CORSPolicy struct {
+	AllowedOrigins   []string // Exact origins, "*" for any, or wildcard subdomains like https://*.example.com
+	AllowedHeaders   []string // Request headers a preflight may ask for; "*" allows any
+	ExposedHeaders   []string // Response headers readable by browser scripts
+	AllowCredentials bool     // Allow cookies and Authorization; requires explicit origins
+	MaxAge           time.Duration
+}
+
+// DefaultCORSPolicy returns the permissive policy used when none is configured
+func DefaultCORSPolicy() CORSPolicy {
+	return CORSPolicy{
+		AllowedOrigins: []string{"*"},
+		AllowedHeaders: []string{"Content-Type", "Authorization", "X-Cart-Token", "X-Request-ID"},
+		ExposedHeaders: []string{"X-Next-Cursor", "X-Cart-Token", "X-Request-ID"},
+		MaxAge:         time.Hour,
+	}
+}
+
+// routeMethods is the set of methods registered for one route path
+type routeMethods struct {
+	path    *regexp.Regexp
+	methods []string
+}
+
+// CORS enforces a CORSPolicy for the routes registered on a mux router
+type CORS struct {
+	policy    CORSPolicy
+	router    *mux.Router
+	anyOrigin bool
+
+	once   sync.Once
+	routes []routeMethods
+}
+
+// NewCORS creates CORS middleware for router. Allowed methods for a preflight
+// are derived from the routes registered on router for the requested path.
+func NewCORS(policy CORSPolicy, router *mux.Router) *CORS {
+	c := &CORS{policy: policy, router: router}
+	c.policy.AllowedOrigins = make([]string, len(policy.AllowedOrigins))
+	for i, origin := range policy.AllowedOrigins {
+		if origin == "*" {
+			c.anyOrigin = true
+		}
+		c.policy.AllowedOrigins[i] = strings.ToLower(strings.TrimSuffix(origin, "/"))
+	}
+	return c
+}
+
+// Middleware applies the policy: preflight requests are answered here,
+// actual requests get CORS headers only when their origin is allowed
+func (c *CORS) Middleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		// Set CORS headers
-		w.Header().Set("Access-Control-Allow-Origin", "*")
-		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, PATCH, DELETE, OPTIONS")
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
-		w.Header().Set("Access-Control-Max-Age", "3600")
+		origin := r.Header.Get("Origin")
+		if origin != "" {
+			w.Header().Add("Vary", "Origin")
+		}
 
-		// Handle preflight requests
-		if r.Method == "OPTIONS" {
-			w.WriteHeader(http.StatusOK)
+		if r.Method == http.MethodOptions {
+			c.handlePreflight(w, r, origin)
 			return
 		}
 
+		if origin != "" && c.originAllowed(origin) {
+			c.writeOriginHeaders(w, origin)
+			if len(c.policy.ExposedHeaders) > 0 {
+				w.Header().Set("Access-Control-Expose-Headers", strings.Join(c.policy.ExposedHeaders, ", "))
+			}
+		}
+
 		next.ServeHTTP(w, r)
 	})
 }
 
+// handlePreflight answers OPTIONS requests for known routes and rejects the rest
+func (c *CORS) handlePreflight(w http.ResponseWriter, r *http.Request, origin string) {
+	methods := c.methodsFor(r.URL.Path)
+	if len(methods) == 0 {
+		helpers.WriteErrorResponse(w, http.StatusNotFound, "not_found", "No route matches this path")
+		return
+	}
+	allow := strings.Join(append(methods, http.MethodOptions), ", ")
+
+	requestedMethod := r.Header.Get("Access-Control-Request-Method")
+	if origin == "" || requestedMethod == "" {
+		// Plain OPTIONS request, not a CORS preflight
+		w.Header().Set("Allow", allow)
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	w.Header().Add("Vary", "Access-Control-Request-Method")
+	w.Header().Add("Vary", "Access-Control-Request-Headers")
+
+	if !c.originAllowed(origin) {
+		helpers.WriteErrorResponse(w, http.StatusForbidden, "cors_forbidden", "Origin is not allowed")
+		return
+	}
+	if !containsFold(methods, requestedMethod) {
+		helpers.WriteErrorResponse(w, http.StatusForbidden, "cors_forbidden", "Method "+requestedMethod+" is not allowed for this path")
+		return
+	}
+	requestedHeaders := splitHeaderList(r.Header.Get("Access-Control-Request-Headers"))
+	if !c.headersAllowed(requestedHeaders) {
+		helpers.WriteErrorResponse(w, http.StatusForbidden, "cors_forbidden", "Requested headers are not allowed")
+		return
+	}
+
+	c.writeOriginHeaders(w, origin)
+	w.Header().Set("Access-Control-Allow-Methods", allow)
+	if len(requestedHeaders) > 0 {
+		w.Header().Set("Access-Control-Allow-Headers", strings.Join(requestedHeaders, ", "))
+	}
+	if c.policy.MaxAge > 0 {
+		w.Header().Set("Access-Control-Max-Age", strconv.Itoa(int(c.policy.MaxAge.Seconds())))
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// writeOriginHeaders sets the allow-origin and credentials headers for an allowed origin
+func (c *CORS) writeOriginHeaders(w http.ResponseWriter, origin string) {
+	if c.anyOrigin && !c.policy.AllowCredentials {
+		w.Header().Set("Access-Control-Allow-Origin", "*")
+	} else {
+		w.Header().Set("Access-Control-Allow-Origin", origin)
+	}
+	if c.policy.AllowCredentials {
+		w.Header().Set("Access-Control-Allow-Credentials", "true")
+	}
+}
+
+// originAllowed reports whether origin matches the allowlist
+func (c *CORS) originAllowed(origin string) bool {
+	if c.anyOrigin {
+		return true
+	}
+	origin = strings.ToLower(origin)
+	for _, allowed := range c.policy.AllowedOrigins {
+		if allowed == origin {
+			return true
+		}
+		// Wildcard subdomain: https://*.example.com matches https://api.example.com
+		// (and deeper subdomains) but not https://example.com itself
+		if prefix, suffix, ok := strings.Cut(allowed, "*"); ok {
+			if len(origin) > len(prefix)+len(suffix) &&
+				strings.HasPrefix(origin, prefix) &&
+				strings.HasSuffix(origin, suffix) &&
+				!strings.ContainsAny(origin[len(prefix):len(origin)-len(suffix)], "/:") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// headersAllowed reports whether every requested header is in the policy
+func (c *CORS) headersAllowed(requested []string) bool {
+	if containsFold(c.policy.AllowedHeaders, "*") {
+		return true
+	}
+	for _, header := range requested {
+		if !containsFold(c.policy.AllowedHeaders, header) {
+			return false
+		}
+	}
+	return true
+}
+
+// methodsFor returns the methods registered for path across all routes
+func (c *CORS) methodsFor(path string) []string {
+	c.once.Do(c.loadRoutes)
+
+	seen := map[string]bool{}
+	var methods []string
+	for _, route := range c.routes {
+		if !route.path.MatchString(path) {
+			continue
+		}
+		for _, method := range route.methods {
+			if !seen[method] {
+				seen[method] = true
+				methods = append(methods, method)
+			}
+		}
+	}
+	sort.Strings(methods)
+	return methods
+}
+
+// loadRoutes walks the router once, after all routes have been registered
+func (c *CORS) loadRoutes() {
+	c.router.Walk(func(route *mux.Route, router *mux.Router, ancestors []*mux.Route) error {
+		pathRegexp, err := route.GetPathRegexp()
+		if err != nil {
+			return nil
+		}
+		methods, err := route.GetMethods()
+		if err != nil {
+			return nil
+		}
+		var routed []string
+		for _, method := range methods {
+			if method != http.MethodOptions {
+				routed = append(routed, method)
+			}
+		}
+		if len(routed) == 0 {
+			return nil
+		}
+		compiled, err := regexp.Compile(pathRegexp)
+		if err != nil {
+			return nil
+		}
+		c.routes = append(c.routes, routeMethods{path: compiled, methods: routed})
+		return nil
+	})
+}
+
+// splitHeaderList parses a comma-separated header list
+func splitHeaderList(value string) []string {
+	var headers []string
+	for _, header := range strings.Split(value, ",") {
+		if header = strings.TrimSpace(header); header != "" {
+			headers = append(headers, header)
+		}
+	}
+	return headers
+}
+
+func containsFold(values []string, target string) bool {
+	for _, value := range values {
+		if strings.EqualFold(value, target) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,96 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+)
+
+func TestOriginAllowed(t *testing.T) {
+	tests := []struct {
+		name    string
+		allowed []string
+		origin  string
+		want    bool
+	}{
+		{"any origin", []string{"*"}, "https://anything.test", true},
+		{"exact match", []string{"https://shop.example.com"}, "https://shop.example.com", true},
+		{"exact match ignores case", []string{"https://Shop.Example.com"}, "HTTPS://SHOP.EXAMPLE.COM", true},
+		{"exact match ignores trailing slash in policy", []string{"https://shop.example.com/"}, "https://shop.example.com", true},
+		{"other scheme", []string{"https://shop.example.com"}, "http://shop.example.com", false},
+		{"other port", []string{"https://shop.example.com"}, "https://shop.example.com:8443", false},
+		{"wildcard subdomain", []string{"https://*.example.com"}, "https://api.example.com", true},
+		{"wildcard deeper subdomain", []string{"https://*.example.com"}, "https://a.b.example.com", true},
+		{"wildcard excludes apex", []string{"https://*.example.com"}, "https://example.com", false},
+		{"wildcard excludes empty label", []string{"https://*.example.com"}, "https://.example.com", false},
+		{"wildcard excludes lookalike domain", []string{"https://*.example.com"}, "https://evil-example.com", false},
+		{"wildcard excludes suffix attack", []string{"https://*.example.com"}, "https://api.example.com.evil.test", false},
+		{"wildcard excludes other scheme", []string{"https://*.example.com"}, "http://api.example.com", false},
+		{"wildcard excludes port", []string{"https://*.example.com"}, "https://api.example.com:8443", false},
+		{"wildcard excludes userinfo and paths", []string{"https://*.example.com"}, "https://evil.test/x.example.com", false},
+		{"wildcard with port", []string{"https://*.example.com:8443"}, "https://api.example.com:8443", true},
+		{"second entry matches", []string{"https://a.test", "https://*.example.com"}, "https://api.example.com", true},
+		{"empty allowlist", nil, "https://shop.example.com", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewCORS(CORSPolicy{AllowedOrigins: tt.allowed}, mux.NewRouter())
+			if got := c.originAllowed(tt.origin); got != tt.want {
+				t.Errorf("originAllowed(%q) with %v = %v, want %v", tt.origin, tt.allowed, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPreflight(t *testing.T) {
+	router := mux.NewRouter()
+	noop := func(w http.ResponseWriter, r *http.Request) {}
+	router.HandleFunc("/orders", noop).Methods("GET", "POST")
+	router.HandleFunc("/orders/{id}", noop).Methods("PATCH")
+	c := NewCORS(CORSPolicy{
+		AllowedOrigins: []string{"https://*.example.com"},
+		AllowedHeaders: []string{"Content-Type", "Authorization"},
+	}, router)
+	handler := c.Middleware(router)
+
+	tests := []struct {
+		name       string
+		path       string
+		origin     string
+		method     string
+		headers    string
+		wantStatus int
+		wantAllow  string
+	}{
+		{"allowed", "/orders", "https://shop.example.com", "POST", "content-type", http.StatusNoContent, "GET, POST, OPTIONS"},
+		{"path variable", "/orders/42", "https://shop.example.com", "PATCH", "", http.StatusNoContent, "PATCH, OPTIONS"},
+		{"unknown path", "/nope", "https://shop.example.com", "GET", "", http.StatusNotFound, ""},
+		{"disallowed origin", "/orders", "https://evil-example.com", "GET", "", http.StatusForbidden, ""},
+		{"unregistered method", "/orders", "https://shop.example.com", "DELETE", "", http.StatusForbidden, ""},
+		{"disallowed header", "/orders", "https://shop.example.com", "GET", "X-Secret", http.StatusForbidden, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodOptions, tt.path, nil)
+			req.Header.Set("Origin", tt.origin)
+			req.Header.Set("Access-Control-Request-Method", tt.method)
+			if tt.headers != "" {
+				req.Header.Set("Access-Control-Request-Headers", tt.headers)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			if got := rec.Header().Get("Access-Control-Allow-Methods"); got != tt.wantAllow {
+				t.Errorf("Access-Control-Allow-Methods = %q, want %q", got, tt.wantAllow)
+			}
+			if tt.wantStatus == http.StatusNoContent && rec.Header().Get("Access-Control-Allow-Origin") != tt.origin {
+				t.Errorf("Access-Control-Allow-Origin = %q, want %q", rec.Header().Get("Access-Control-Allow-Origin"), tt.origin)
+			}
+		})
+	}
+}
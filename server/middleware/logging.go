@@ -1,32 +1,45 @@
 package middleware
 
 import (
+	"log/slog"
 	"net/http"
 	"time"
 
-	// TODO: Add logging library import
-	// "github.com/sirupsen/logrus"
+	"oms/server/core/audit"
 )
 
+// statusRecorder captures the status code written by downstream handlers
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer, to flush streamed responses
+// and lift their write deadline
+func (r *statusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
+
 // LoggingMiddleware logs HTTP requests
 func LoggingMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		start := time.Now()
+		recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
 
-		// TODO: Use structured logging (Logrus)
-		// logger := logrus.WithFields(logrus.Fields{
-		//     "method": r.Method,
-		//     "path":   r.URL.Path,
-		//     "ip":     r.RemoteAddr,
-		// })
-		// logger.Info("Request started")
+		next.ServeHTTP(recorder, r)
 
-		next.ServeHTTP(w, r)
-
-		duration := time.Since(start)
-		// TODO: Log response
-		// logger.WithField("duration", duration).Info("Request completed")
-		_ = duration // Placeholder to avoid unused variable
+		slog.Info("Request completed",
+			"method", r.Method,
+			"path", r.URL.Path,
+			"status", recorder.status,
+			"duration", time.Since(start),
+			"ip", clientIP(r),
+			"request_id", audit.RequestFrom(r.Context()).ID,
+		)
 	})
 }
-
@@ -1,21 +1,109 @@
 package middleware
 
 import (
+	"math"
+	"net"
 	"net/http"
+	"strconv"
+	"sync"
+	"time"
 
-	// TODO: Add rate limiting library
-	// "golang.org/x/time/rate"
+	"oms/server/api/v1/helpers"
 )
 
-// RateLimitMiddleware implements rate limiting to prevent spam
-func RateLimitMiddleware(next http.Handler) http.Handler {
+// idleBucketTTL is how long an inactive client's bucket is kept before being swept
+const idleBucketTTL = 10 * time.Minute
+
+// bucket is a token bucket for a single client
+type bucket struct {
+	tokens   float64
+	lastSeen time.Time
+}
+
+// RateLimiter implements per-client-IP token bucket rate limiting to prevent spam.
+// Limits can be changed at runtime with SetLimits (e.g. on config reload).
+type RateLimiter struct {
+	mu        sync.Mutex
+	enabled   bool
+	rate      float64 // tokens added per second
+	burst     float64 // bucket capacity
+	buckets   map[string]*bucket
+	lastSweep time.Time
+}
+
+// NewRateLimiter creates a RateLimiter allowing requestsPerSecond with the given burst
+func NewRateLimiter(enabled bool, requestsPerSecond float64, burst int) *RateLimiter {
+	rl := &RateLimiter{buckets: make(map[string]*bucket), lastSweep: time.Now()}
+	rl.SetLimits(enabled, requestsPerSecond, burst)
+	return rl
+}
+
+// SetLimits updates the limits; existing buckets are clamped to the new burst
+func (rl *RateLimiter) SetLimits(enabled bool, requestsPerSecond float64, burst int) {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+	rl.enabled = enabled
+	rl.rate = requestsPerSecond
+	rl.burst = float64(burst)
+	for _, b := range rl.buckets {
+		b.tokens = math.Min(b.tokens, rl.burst)
+	}
+}
+
+// allow takes a token from key's bucket, returning how long to wait when none is left
+func (rl *RateLimiter) allow(key string, now time.Time) (bool, time.Duration) {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	if !rl.enabled {
+		return true, 0
+	}
+
+	if now.Sub(rl.lastSweep) > time.Minute {
+		for k, b := range rl.buckets {
+			if now.Sub(b.lastSeen) > idleBucketTTL {
+				delete(rl.buckets, k)
+			}
+		}
+		rl.lastSweep = now
+	}
+
+	b, exists := rl.buckets[key]
+	if !exists {
+		b = &bucket{tokens: rl.burst, lastSeen: now}
+		rl.buckets[key] = b
+	}
+	b.tokens = math.Min(rl.burst, b.tokens+now.Sub(b.lastSeen).Seconds()*rl.rate)
+	b.lastSeen = now
+
+	if b.tokens < 1 {
+		wait := time.Duration((1 - b.tokens) / rl.rate * float64(time.Second))
+		return false, wait
+	}
+	b.tokens--
+	return true, 0
+}
+
+// Middleware limits requests per client IP
+// Returns 429 Too Many Requests with a Retry-After header if the limit is exceeded
+func (rl *RateLimiter) Middleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		// TODO: Implement rate limiting
-		// Use token bucket or sliding window algorithm
-		// Limit per IP or per user_id
-		// Return 429 Too Many Requests if limit exceeded
+		allowed, wait := rl.allow(clientIP(r), time.Now())
+		if !allowed {
+			w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+			helpers.WriteErrorResponse(w, http.StatusTooManyRequests, "rate_limited", "Too many requests, please slow down")
+			return
+		}
 
 		next.ServeHTTP(w, r)
 	})
 }
 
+// clientIP returns the remote IP of the request without the port
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
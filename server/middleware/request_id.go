@@ -0,0 +1,46 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/google/uuid"
+	"oms/server/core/audit"
+)
+
+// RequestIDHeader carries the ID that ties a request to its log lines and audit records
+const RequestIDHeader = "X-Request-ID"
+
+// maxRequestIDLength bounds request IDs taken from clients
+const maxRequestIDLength = 64
+
+// RequestIDMiddleware gives each request an ID, reusing a well-formed X-Request-ID sent by the
+// client or a proxy in front of the server, and echoes it in the response. The ID and the
+// client IP are put in the request context for audit trails.
+func RequestIDMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestID := r.Header.Get(RequestIDHeader)
+		if !validRequestID(requestID) {
+			requestID = uuid.NewString()
+		}
+		w.Header().Set(RequestIDHeader, requestID)
+
+		ctx := audit.WithRequest(r.Context(), audit.Request{ID: requestID, ClientIP: clientIP(r)})
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// validRequestID accepts IDs of letters, digits and - _ . : up to maxRequestIDLength long
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for _, c := range id {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+		case c == '-', c == '_', c == '.', c == ':':
+		default:
+			return false
+		}
+	}
+	return true
+}